@@ -0,0 +1,266 @@
+// generate creates thin Python and JavaScript clients of dtnd's REST Application Agent from its OpenAPI document,
+// see application_agent.RestOpenAPI. It is invoked by go generate within the application_agent package.
+//
+//	go run ./clients/generate -spec pkg/application_agent/rest_agent_openapi.json -out clients
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// spec is the part of an OpenAPI document required to generate the clients.
+type spec struct {
+	Info struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Paths map[string]struct {
+		Post *struct {
+			OperationID string `json:"operationId"`
+			Summary     string `json:"summary"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema schema `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"post"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// schema is a JSON schema of an OpenAPI document, either a reference or a type.
+type schema struct {
+	Ref        string            `json:"$ref"`
+	Type       string            `json:"type"`
+	Required   []string          `json:"required"`
+	Properties map[string]schema `json:"properties"`
+	Items      *schema           `json:"items"`
+}
+
+// field is a property of a request.
+type field struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// operation is an endpoint of the REST agent, POSTing a request.
+type operation struct {
+	ID      string
+	Path    string
+	Summary string
+	Fields  []field
+}
+
+// resolve a schema's reference within the document's components.
+func (s spec) resolve(sch schema) (schema, error) {
+	if sch.Ref == "" {
+		return sch, nil
+	}
+	name := strings.TrimPrefix(sch.Ref, "#/components/schemas/")
+	resolved, ok := s.Components.Schemas[name]
+	if !ok {
+		return schema{}, fmt.Errorf("unknown schema reference %s", sch.Ref)
+	}
+	return resolved, nil
+}
+
+// jsType names a schema's type for JSDoc.
+func jsType(sch schema) string {
+	switch sch.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		if sch.Items != nil {
+			return "Array<" + jsType(*sch.Items) + ">"
+		}
+		return "Array"
+	default:
+		return "Object"
+	}
+}
+
+// operations lists the document's POST endpoints, sorted by their path.
+func (s spec) operations() ([]operation, error) {
+	var ops []operation
+	for path, item := range s.Paths {
+		if item.Post == nil {
+			continue
+		}
+
+		content, ok := item.Post.RequestBody.Content["application/json"]
+		if !ok {
+			return nil, fmt.Errorf("%s has no JSON request body", path)
+		}
+		request, err := s.resolve(content.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		op := operation{ID: item.Post.OperationID, Path: path, Summary: item.Post.Summary}
+		for name, prop := range request.Properties {
+			required := false
+			for _, r := range request.Required {
+				required = required || r == name
+			}
+			op.Fields = append(op.Fields, field{Name: name, Type: jsType(prop), Required: required})
+		}
+		sort.Slice(op.Fields, func(i, j int) bool { return op.Fields[i].Name < op.Fields[j].Name })
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Path < ops[j].Path })
+	return ops, nil
+}
+
+var pythonClient = template.Must(template.New("python").Parse(`# Code generated by clients/generate from the REST agent's OpenAPI document. DO NOT EDIT.
+"""Thin client of the {{.Title}}.
+
+Each method POSTs its request, a dict of the listed fields, and returns the decoded response. A response
+reporting an error raises an AgentError.
+
+    client = RestAgentClient("http://localhost:8080")
+    uuid = client.register({"endpoint_id": "dtn://foo/bar"})["uuid"]
+"""
+
+import json
+import urllib.request
+
+
+class AgentError(Exception):
+    """Error reported by the REST agent's response."""
+
+
+class RestAgentClient:
+    def __init__(self, node_url="http://localhost:8080", timeout=None):
+        self.base_url = node_url.rstrip("/") + "/rest"
+        self.timeout = timeout
+
+    def _post(self, path, request):
+        req = urllib.request.Request(
+            self.base_url + path,
+            data=json.dumps(request).encode("utf-8"),
+            headers={"Content-Type": "application/json"},
+            method="POST",
+        )
+        with urllib.request.urlopen(req, timeout=self.timeout) as resp:
+            response = json.load(resp)
+        if response.get("error"):
+            raise AgentError(response["error"])
+        return response
+{{range .Operations}}
+    def {{.ID}}(self, request):
+        """{{.Summary}}
+
+        Request fields:{{range $i, $f := .Fields}}{{if $i}},{{end}} {{$f.Name}}{{if not $f.Required}} (optional){{end}}{{end}}.
+        """
+        return self._post("{{.Path}}", request)
+{{end}}`))
+
+var javascriptClient = template.Must(template.New("javascript").Parse(`// Code generated by clients/generate from the REST agent's OpenAPI document. DO NOT EDIT.
+
+/**
+ * Thin client of the {{.Title}}.
+ *
+ * Each method POSTs its request and resolves to the decoded response. A response reporting an error rejects with an
+ * AgentError. It requires the Fetch API, available in browsers and Node.js 18 or later.
+ *
+ *     const client = new RestAgentClient("http://localhost:8080");
+ *     const { uuid } = await client.register({ endpoint_id: "dtn://foo/bar" });
+ */
+
+/** Error reported by the REST agent's response. */
+export class AgentError extends Error {}
+
+export class RestAgentClient {
+  /** @param {string} nodeURL */
+  constructor(nodeURL = "http://localhost:8080") {
+    this.baseURL = nodeURL.replace(/\/+$/, "") + "/rest";
+  }
+
+  async _post(path, request) {
+    const resp = await fetch(this.baseURL + path, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(request),
+    });
+    const response = await resp.json();
+    if (response.error) {
+      throw new AgentError(response.error);
+    }
+    return response;
+  }
+{{range .Operations}}
+  /**
+   * {{.Summary}}
+   * @param {{"{{"}}{{range $i, $f := .Fields}}{{if $i}}, {{end}}{{$f.Name}}{{if not $f.Required}}?{{end}}: {{$f.Type}}{{end}}{{"}}"}} request
+   * @returns {Promise<Object>}
+   */
+  async {{.ID}}(request) {
+    return this._post("{{.Path}}", request);
+  }
+{{end}}}
+`))
+
+func main() {
+	specFile := flag.String("spec", "rest_agent_openapi.json", "OpenAPI document of the REST agent")
+	outDir := flag.String("out", "clients", "directory of the generated clients")
+	flag.Parse()
+
+	if err := generate(*specFile, *outDir); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "generating clients failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate both clients of the OpenAPI document below the output directory.
+func generate(specFile, outDir string) error {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return err
+	}
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	ops, err := s.operations()
+	if err != nil {
+		return err
+	}
+
+	values := struct {
+		Title      string
+		Operations []operation
+	}{s.Info.Title, ops}
+
+	for file, tmpl := range map[string]*template.Template{
+		filepath.Join("python", "dtn7_rest.py"):     pythonClient,
+		filepath.Join("javascript", "dtn7_rest.js"): javascriptClient,
+	} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, values); err != nil {
+			return err
+		}
+
+		target := filepath.Join(outDir, file)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/admin"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/identity"
+)
+
+// create a bundle from a payload, optionally signed, and store it.
+func create(args []string) {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	lifetime := flags.String("lifetime", "24h", "bundle lifetime")
+	priority := flags.String("priority", "normal", "bundle priority: bulk, normal or expedited")
+	via := flags.String("via", "", "comma-separated waypoint EIDs the bundle is source routed through")
+	keyFile := flags.String("key", "", "private key created by keygen to sign the bundle")
+	contentType := flags.String("type", "", "MIME content type of the payload, guessed from the filename if empty")
+	filename := flags.String("filename", "", "filename of the payload for the receiver")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 4 {
+		printUsage()
+		os.Exit(1)
+	}
+	source, destination, payloadFile, bundleFile := flags.Arg(0), flags.Arg(1), flags.Arg(2), flags.Arg(3)
+
+	payload, err := readInput(payloadFile)
+	if err != nil {
+		printFatal(err, "Reading payload failed")
+	}
+
+	bldr := bpv7.Builder().
+		CRC(bpv7.CRC32).
+		Source(source).
+		Destination(destination).
+		CreationTimestampNow().
+		Lifetime(*lifetime).
+		PayloadBlock(payload)
+	if *priority != bpv7.PriorityNormal.String() {
+		bldr = bldr.PriorityBlock(*priority)
+	}
+	if *via != "" {
+		bldr = bldr.SourceRouteBlock(strings.Split(*via, ","))
+	}
+	if *contentType != "" || *filename != "" {
+		if *contentType == "" {
+			*contentType = mime.TypeByExtension(filepath.Ext(*filename))
+		}
+		if *contentType == "" {
+			*contentType = "application/octet-stream"
+		}
+		bldr = bldr.ContentTypeBlock(*contentType, *filename)
+	}
+
+	bndl, err := bldr.Build()
+	if err != nil {
+		printFatal(err, "Building bundle failed")
+	}
+
+	if *keyFile != "" {
+		priv, err := loadKey(*keyFile)
+		if err != nil {
+			printFatal(err, "Loading key failed")
+		}
+		if err := bndl.Sign(priv); err != nil {
+			printFatal(err, "Signing bundle failed")
+		}
+	}
+
+	buff := new(bytes.Buffer)
+	if err := bndl.WriteBundle(buff); err != nil {
+		printFatal(err, "Serialising bundle failed")
+	}
+	if err := writeOutput(bundleFile, buff.Bytes()); err != nil {
+		printFatal(err, "Writing bundle failed")
+	}
+}
+
+// show a bundle as JSON and verify its signature, optionally by the certificate of its source node.
+func show(args []string) {
+	flags := flag.NewFlagSet("show", flag.ExitOnError)
+	certFile := flags.String("cert", "", "certificate of the source node, created by certgen, to verify the signature")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	data, err := readInput(flags.Arg(0))
+	if err != nil {
+		printFatal(err, "Reading bundle failed")
+	}
+
+	// A SignatureBlock is verified while parsing, once its type is known.
+	_ = bpv7.GetExtensionBlockManager().Register(&bpv7.SignatureBlock{})
+
+	bndl, err := bpv7.ParseBundle(bytes.NewReader(data))
+	if err != nil {
+		printFatal(err, "Parsing bundle failed")
+	}
+
+	out, err := json.MarshalIndent(bndl, "", "  ")
+	if err != nil {
+		printFatal(err, "Serialising bundle as JSON failed")
+	}
+	fmt.Println(string(out))
+
+	if block, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeSignatureBlock); err != nil {
+		fmt.Println("Bundle is not signed")
+	} else if sb := block.Value.(*bpv7.SignatureBlock); !sb.Verify(bndl) {
+		printFatal(fmt.Errorf("signature of %x is invalid", sb.PublicKey), "Verifying bundle failed")
+	} else if *certFile == "" {
+		fmt.Printf("Bundle is signed by %x\n", sb.PublicKey)
+	} else if err := verifySigner(*certFile, bndl, sb); err != nil {
+		printFatal(err, "Verifying bundle's signer failed")
+	} else {
+		fmt.Printf("Bundle is signed by %x, certified for its source node\n", sb.PublicKey)
+	}
+}
+
+// verifySigner checks if a certificate binds the bundle's source node to the public key of its signature.
+func verifySigner(certFile string, bndl bpv7.Bundle, sb *bpv7.SignatureBlock) error {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return err
+	}
+	cert, err := identity.ParseCertificate(data)
+	if err != nil {
+		return err
+	}
+
+	nodeID, err := identity.NodeID(cert)
+	if err != nil {
+		return err
+	}
+	if !nodeID.SameNode(bndl.PrimaryBlock.SourceNode) {
+		return fmt.Errorf("certificate binds %v instead of the source %v", nodeID, bndl.PrimaryBlock.SourceNode)
+	}
+	if pub, ok := cert.PublicKey.(ed25519.PublicKey); !ok || !bytes.Equal(pub, sb.PublicKey) {
+		return fmt.Errorf("certificate binds another public key than %x", sb.PublicKey)
+	}
+	if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate is only valid from %v until %v", cert.NotBefore, cert.NotAfter)
+	}
+	return nil
+}
+
+// inject a bundle into a node through its admin API.
+func inject(args []string) {
+	if len(args) != 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	endpoint, err := url.JoinPath(args[0], "admin", "bundles", "inject")
+	if err != nil {
+		printFatal(err, "Invalid node URL")
+	}
+
+	data, err := readInput(args[1])
+	if err != nil {
+		printFatal(err, "Reading bundle failed")
+	}
+
+	resp, err := http.Post(endpoint, "application/cbor", bytes.NewReader(data))
+	if err != nil {
+		printFatal(err, "Injecting bundle failed")
+	}
+	defer resp.Body.Close()
+
+	var response admin.AdminInjectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		printFatal(err, fmt.Sprintf("Parsing node's response of status %s failed", resp.Status))
+	}
+	if response.Error != "" {
+		printFatal(fmt.Errorf("%s", response.Error), "Node rejected bundle")
+	}
+	fmt.Printf("Injected bundle %s\n", response.BundleID)
+}
+
+// importBundles stores bundle files, either BPv7 or BPv6, in a node's store through its admin API.
+func importBundles(args []string) {
+	if len(args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	endpoint, err := url.JoinPath(args[0], "admin", "bundles", "import")
+	if err != nil {
+		printFatal(err, "Invalid node URL")
+	}
+
+	failed := 0
+	for _, bundleFile := range args[1:] {
+		if bundleID, err := importBundle(endpoint, bundleFile); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Importing %s failed: %v\n", bundleFile, err)
+			failed++
+		} else {
+			fmt.Printf("Imported %s as bundle %s\n", bundleFile, bundleID)
+		}
+	}
+
+	if failed > 0 {
+		printFatal(fmt.Errorf("%d of %d bundles failed", failed, len(args)-1), "Importing bundles failed")
+	}
+}
+
+// importBundle posts a single bundle file to the import endpoint and returns the bundle's ID.
+func importBundle(endpoint, bundleFile string) (string, error) {
+	data, err := readInput(bundleFile)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(endpoint, "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response admin.AdminImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("parsing node's response of status %s failed: %w", resp.Status, err)
+	}
+	if response.Error != "" {
+		return "", fmt.Errorf("%s", response.Error)
+	}
+	return response.BundleID, nil
+}
+
+// fetch a stored bundle's metadata, optionally including the bundle itself, through a node's admin API.
+func fetch(args []string) {
+	flags := flag.NewFlagSet("fetch", flag.ExitOnError)
+	payload := flags.Bool("payload", false, "also fetch the bundle itself, including its payload")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	endpoint, err := url.JoinPath(flags.Arg(0), "admin", "bundles")
+	if err != nil {
+		printFatal(err, "Invalid node URL")
+	}
+	query := url.Values{"id": {flags.Arg(1)}}
+	if *payload {
+		query.Set("payload", "true")
+	}
+
+	resp, err := http.Get(endpoint + "?" + query.Encode())
+	if err != nil {
+		printFatal(err, "Fetching bundle failed")
+	}
+	defer resp.Body.Close()
+
+	var response admin.AdminBundleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		printFatal(err, fmt.Sprintf("Parsing node's response of status %s failed", resp.Status))
+	}
+	if response.Error != "" {
+		printFatal(fmt.Errorf("%s", response.Error), "Fetching bundle failed")
+	}
+
+	out, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		printFatal(err, "Serialising bundle as JSON failed")
+	}
+	fmt.Println(string(out))
+}
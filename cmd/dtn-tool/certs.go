@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/identity"
+)
+
+// previousSuffix is appended to the file names of a rotated key and certificate, kept for the overlap period.
+const previousSuffix = ".previous"
+
+// loadOrGenerateKey reads a private key, or creates it if the file does not exist.
+func loadOrGenerateKey(filename string) (ed25519.PrivateKey, error) {
+	priv, err := loadKey(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		if priv, err = identity.GenerateKey(filename); err == nil {
+			fmt.Printf("Created key %s\n", filename)
+		}
+	}
+	return priv, err
+}
+
+// writeCertificate creates a self-signed certificate binding the node ID to the key, valid from now on.
+func writeCertificate(priv ed25519.PrivateKey, nodeID bpv7.EndpointID, validity time.Duration, certFile string) *x509.Certificate {
+	now := time.Now()
+	data, err := identity.CreateCertificate(priv, nodeID, now, now.Add(validity))
+	if err != nil {
+		printFatal(err, "Creating certificate failed")
+	}
+	if err := os.WriteFile(certFile, data, 0644); err != nil {
+		printFatal(err, "Writing certificate failed")
+	}
+
+	cert, err := identity.ParseCertificate(data)
+	if err != nil {
+		printFatal(err, "Parsing certificate failed")
+	}
+	fmt.Printf("Certificate for %v and public key %x is valid until %s\n",
+		nodeID, priv.Public(), cert.NotAfter.Format(time.RFC3339))
+	return cert
+}
+
+// certgen creates a self-signed certificate binding a node ID to a key, which is created if missing.
+func certgen(args []string) {
+	flags := flag.NewFlagSet("certgen", flag.ExitOnError)
+	validity := flags.Duration("validity", 365*24*time.Hour, "validity of the certificate")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 3 {
+		printUsage()
+		os.Exit(1)
+	}
+	keyFile, certFile := flags.Arg(0), flags.Arg(2)
+
+	nodeID, err := bpv7.NewEndpointID(flags.Arg(1))
+	if err != nil {
+		printFatal(err, "Parsing node ID failed")
+	}
+	priv, err := loadOrGenerateKey(keyFile)
+	if err != nil {
+		printFatal(err, "Loading key failed")
+	}
+
+	writeCertificate(priv, nodeID, *validity, certFile)
+}
+
+// csr creates a certificate signing request binding a node ID to a key, which is created if missing.
+func csr(args []string) {
+	if len(args) != 3 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	nodeID, err := bpv7.NewEndpointID(args[1])
+	if err != nil {
+		printFatal(err, "Parsing node ID failed")
+	}
+	priv, err := loadOrGenerateKey(args[0])
+	if err != nil {
+		printFatal(err, "Loading key failed")
+	}
+
+	data, err := identity.CreateRequest(priv, nodeID)
+	if err != nil {
+		printFatal(err, "Creating certificate signing request failed")
+	}
+	if err := writeOutput(args[2], data); err != nil {
+		printFatal(err, "Writing certificate signing request failed")
+	}
+}
+
+// certrotate replaces a key and its certificate by new ones once the certificate expires within the overlap period.
+// The previous key and certificate are kept next to the new ones, as they stay valid until the end of the overlap.
+// Meanwhile, peers learn the new certificate and bundles signed by either key are verifiable.
+func certrotate(args []string) {
+	flags := flag.NewFlagSet("certrotate", flag.ExitOnError)
+	validity := flags.Duration("validity", 365*24*time.Hour, "validity of the new certificate")
+	overlap := flags.Duration("overlap", 30*24*time.Hour, "remaining validity of the current certificate to rotate at")
+	force := flags.Bool("force", false, "rotate regardless of the current certificate's validity")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 3 {
+		printUsage()
+		os.Exit(1)
+	}
+	keyFile, certFile := flags.Arg(0), flags.Arg(2)
+	if *overlap >= *validity {
+		printFatal(fmt.Errorf("overlap of %v exceeds validity of %v", *overlap, *validity), "Invalid rotation")
+	}
+
+	nodeID, err := bpv7.NewEndpointID(flags.Arg(1))
+	if err != nil {
+		printFatal(err, "Parsing node ID failed")
+	}
+	current, err := identity.LoadCertificate(certFile, keyFile)
+	if err != nil {
+		printFatal(err, "Loading current certificate failed")
+	}
+	if currentNodeID, _ := identity.NodeID(current.Leaf); !currentNodeID.SameNode(nodeID) {
+		printFatal(fmt.Errorf("certificate binds %v", currentNodeID), "Rotating certificate failed")
+	}
+
+	expiry := current.Leaf.NotAfter
+	if remaining := time.Until(expiry); remaining > *overlap && !*force {
+		fmt.Printf("Certificate is valid until %s, rotation is due in %v\n",
+			expiry.Format(time.RFC3339), (remaining - *overlap).Round(time.Second))
+		return
+	}
+
+	for _, filename := range []string{keyFile, certFile} {
+		if err := os.Rename(filename, filename+previousSuffix); err != nil {
+			printFatal(err, "Keeping previous key and certificate failed")
+		}
+	}
+
+	priv, err := identity.GenerateKey(keyFile)
+	if err != nil {
+		printFatal(err, "Generating key failed")
+	}
+	writeCertificate(priv, nodeID, *validity, certFile)
+	fmt.Printf("Previous certificate %s remains valid until %s\n", certFile+previousSuffix, expiry.Format(time.RFC3339))
+}
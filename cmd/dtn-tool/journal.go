@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/journal"
+)
+
+// parseOptionalTime parses an RFC 3339 time, the zero time for an empty string.
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// journalSummary summarises a node's transmission journal per peer.
+func journalSummary(args []string) {
+	flags := flag.NewFlagSet("journal", flag.ExitOnError)
+	fromStr := flags.String("from", "", "only count transmissions since this RFC 3339 time")
+	toStr := flags.String("to", "", "only count transmissions before this RFC 3339 time")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	from, err := parseOptionalTime(*fromStr)
+	if err != nil {
+		printFatal(err, "Parsing from time failed")
+	}
+	to, err := parseOptionalTime(*toStr)
+	if err != nil {
+		printFatal(err, "Parsing to time failed")
+	}
+
+	data, err := readInput(flags.Arg(0))
+	if err != nil {
+		printFatal(err, "Reading journal failed")
+	}
+	entries, err := journal.ReadEntries(bytes.NewReader(data))
+	if err != nil {
+		printFatal(err, "Parsing journal failed")
+	}
+
+	for _, summary := range journal.Summarise(entries, from, to) {
+		fmt.Printf("%s\tattempts=%d\tsent=%d\tfailed=%d\texpired=%d\tsent_bytes=%d\tduration=%v\n",
+			summary.Peer, summary.Attempts, summary.Sent, summary.Failed, summary.Expired, summary.SentBytes,
+			summary.Duration)
+	}
+}
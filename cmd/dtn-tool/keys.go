@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/dtn7/dtn7-go/pkg/identity"
+)
+
+// keygen creates an ed25519 private key, stored as its hex encoded seed.
+func keygen(args []string) {
+	if len(args) != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	priv, err := identity.GenerateKey(args[0])
+	if err != nil {
+		printFatal(err, "Generating key failed")
+	}
+	fmt.Printf("Public key: %x\n", priv.Public())
+}
+
+// loadKey reads an ed25519 private key created by keygen.
+func loadKey(filename string) (ed25519.PrivateKey, error) {
+	return identity.LoadKey(filename)
+}
@@ -0,0 +1,128 @@
+// dtn-tool creates, signs and inspects bundles offline, without a running node, and injects or imports them into a node
+// later. This allows sneakernet workflows, e.g., carrying bundle files on a USB drive. Furthermore, it fetches a node's
+// stored bundles, compacts its store, snapshots it for a read-only inspection, migrates it into another layout and
+// summarises its transmission journal.
+// Finally, it bootstraps and rotates node identity certificates, binding a node ID to the key used for TLS and bundle
+// signatures.
+//
+//	dtn-tool keygen <key-file>
+//	dtn-tool certgen [-validity 8760h] <key-file> <node-id> <cert-file>
+//	dtn-tool csr <key-file> <node-id> <csr-file|->
+//	dtn-tool certrotate [-validity 8760h] [-overlap 720h] [-force] <key-file> <node-id> <cert-file>
+//	dtn-tool create [-lifetime 24h] [-priority normal] [-via eid,...] [-type content-type] [-filename name] [-key key-file] <source> <destination> <payload-file|-> <bundle-file|->
+//	dtn-tool show [-cert cert-file] <bundle-file|->
+//	dtn-tool inject <node-url> <bundle-file|->
+//	dtn-tool import <node-url> <bundle-file|->...
+//	dtn-tool fetch [-payload] <node-url> <bundle-id>
+//	dtn-tool compact <node-url>
+//	dtn-tool snapshot <node-url> <snapshot-path>
+//	dtn-tool inspect <store-path> [bundle-id]
+//	dtn-tool migrate [-shards 1] <store-path> <target-path>
+//	dtn-tool journal [-from time] [-to time] <journal-file|->
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func printUsage() {
+	_, _ = fmt.Fprintf(os.Stderr, `Usage of %s:
+  keygen <key-file>
+      Create an ed25519 private key to sign bundles.
+  certgen [-validity 8760h] <key-file> <node-id> <cert-file>
+      Create a self-signed certificate binding the node ID to the key, which is created if missing.
+  csr <key-file> <node-id> <csr-file|->
+      Create a certificate signing request for a CA, binding the node ID to the key, which is created if missing.
+  certrotate [-validity 8760h] [-overlap 720h] [-force] <key-file> <node-id> <cert-file>
+      Replace the key and certificate once it expires within the overlap, keeping the previous ones as *.previous.
+  create [-lifetime 24h] [-priority normal] [-via eid,...] [-type content-type] [-filename name] [-key key-file] <source> <destination> <payload-file|-> <bundle-file|->
+      Create a bundle, optionally signed, of the payload and store it in a file, optionally describing the payload.
+  show [-cert cert-file] <bundle-file|->
+      Print a bundle as JSON and verify its signature, if present, optionally certified for its source node.
+  inject <node-url> <bundle-file|->
+      Send a bundle to a node's admin API, e.g., http://localhost:8080, as if it was received.
+  import <node-url> <bundle-file|->...
+      Store bundle files, BPv7 or BPv6, in a node through its admin API, checked like received bundles.
+  fetch [-payload] <node-url> <bundle-id>
+      Print a stored bundle's metadata as JSON through a node's admin API, optionally including the bundle.
+  compact <node-url>
+      Reclaim the disk space of a node's store through its admin API and report it.
+  snapshot <node-url> <snapshot-path>
+      Copy a node's store through its admin API to a new path on the node's file system.
+  inspect <store-path> [bundle-id]
+      List the bundles of a store snapshot, or of a stopped node's store, or print one as JSON, read-only.
+  migrate [-shards 1] <store-path> <target-path>
+      Copy a store snapshot, or a stopped node's store, to a new path with the number of shards, verifying each bundle.
+  journal [-from time] [-to time] <journal-file|->
+      Summarise a node's transmission journal per peer, optionally bounded by RFC 3339 times.
+
+A "-" stands for stdin or stdout.
+`, os.Args[0])
+}
+
+// printFatal prints an error and exits.
+func printFatal(err error, msg string) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
+	os.Exit(1)
+}
+
+// readInput reads a whole file or stdin for "-".
+func readInput(filename string) ([]byte, error) {
+	if filename == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(filename)
+}
+
+// writeOutput writes data to a file or stdout for "-".
+func writeOutput(filename string, data []byte) error {
+	if filename == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "keygen":
+		keygen(args)
+	case "certgen":
+		certgen(args)
+	case "csr":
+		csr(args)
+	case "certrotate":
+		certrotate(args)
+	case "create":
+		create(args)
+	case "show":
+		show(args)
+	case "inject":
+		inject(args)
+	case "import":
+		importBundles(args)
+	case "fetch":
+		fetch(args)
+	case "compact":
+		compact(args)
+	case "snapshot":
+		snapshot(args)
+	case "inspect":
+		inspect(args)
+	case "migrate":
+		migrate(args)
+	case "journal":
+		journalSummary(args)
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/admin"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// compact a node's store through its admin API and print the reclaimed space.
+func compact(args []string) {
+	if len(args) != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	endpoint, err := url.JoinPath(args[0], "admin", "store", "compact")
+	if err != nil {
+		printFatal(err, "Invalid node URL")
+	}
+
+	resp, err := http.Post(endpoint, "application/json", nil)
+	if err != nil {
+		printFatal(err, "Compacting store failed")
+	}
+	defer resp.Body.Close()
+
+	var response admin.AdminCompactResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		printFatal(err, fmt.Sprintf("Parsing node's response of status %s failed", resp.Status))
+	}
+	if response.Error != "" {
+		printFatal(fmt.Errorf("%s", response.Error), "Compacting store failed")
+	}
+	fmt.Printf("Reclaimed %d bytes in %s, deleted %d expired quarantined bundles, removed %d orphaned files, "+
+		"rewrote %d value logs\n", response.ReclaimedBytes, response.Duration, response.ExpiredQuarantined,
+		response.OrphanedFiles, response.RewrittenLogs)
+}
+
+// snapshot a node's store through its admin API for a later inspect.
+func snapshot(args []string) {
+	if len(args) != 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	endpoint, err := url.JoinPath(args[0], "admin", "store", "snapshot")
+	if err != nil {
+		printFatal(err, "Invalid node URL")
+	}
+
+	request, err := json.Marshal(admin.AdminSnapshotRequest{Path: args[1]})
+	if err != nil {
+		printFatal(err, "Serialising request failed")
+	}
+
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(request))
+	if err != nil {
+		printFatal(err, "Snapshotting store failed")
+	}
+	defer resp.Body.Close()
+
+	var response admin.AdminSnapshotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		printFatal(err, fmt.Sprintf("Parsing node's response of status %s failed", resp.Status))
+	}
+	if response.Error != "" {
+		printFatal(fmt.Errorf("%s", response.Error), "Snapshotting store failed")
+	}
+	fmt.Printf("Snapshotted %d bundles of %d bytes to %s in %s\n",
+		response.Bundles, response.Bytes, response.Path, response.Duration)
+}
+
+// inspect a store snapshot, or the store of a stopped node, read-only. Without a bundle ID, all bundles are listed.
+func inspect(args []string) {
+	if len(args) != 1 && len(args) != 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	replica, err := store.OpenReplica(args[0])
+	if err != nil {
+		printFatal(err, "Opening store failed")
+	}
+	defer replica.Close()
+
+	if len(args) == 1 {
+		bds, err := replica.GetAll()
+		if err != nil {
+			printFatal(err, "Listing bundles failed")
+		}
+		sort.Slice(bds, func(i, j int) bool { return bds[i].IDString < bds[j].IDString })
+
+		for _, bd := range bds {
+			fmt.Printf("%s\tdestination=%v\tconstraints=%v\tdispatch=%t\texpires=%s\n",
+				bd.IDString, bd.Destination, bd.RetentionConstraints, bd.Dispatch, bd.Expires.Format(time.RFC3339))
+		}
+		return
+	}
+
+	bd, err := replica.LoadBundleByIDString(args[1], true)
+	if err != nil {
+		printFatal(err, "Loading bundle failed")
+	}
+
+	out, err := json.MarshalIndent(bd.Bundle, "", "  ")
+	if err != nil {
+		printFatal(err, "Serialising bundle as JSON failed")
+	}
+	fmt.Println(string(out))
+}
+
+// migrate a store snapshot, or the store of a stopped node, to a new path with another number of shards. Bundles which
+// failed their verification are listed and let the migration exit with an error.
+func migrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	shards := flags.Int("shards", 1, "number of shards of the migrated store, one for an unsharded store")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	report, err := store.Migrate(flags.Arg(0), flags.Arg(1), *shards)
+	if err != nil {
+		printFatal(err, "Migrating store failed")
+	}
+	fmt.Printf("Migrated %d bundles of %d bytes to %s in %s\n",
+		report.Bundles, report.Bytes, flags.Arg(1), report.Duration)
+
+	if len(report.Failures) == 0 {
+		return
+	}
+	for _, failure := range report.Failures {
+		_, _ = fmt.Fprintf(os.Stderr, "%s\t%v\n", failure.IDString, failure.Err)
+	}
+	printFatal(fmt.Errorf("%d bundles failed their verification", len(report.Failures)), "Migrating store incomplete")
+}
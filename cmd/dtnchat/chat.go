@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/client"
+)
+
+const (
+	// fetchInterval is the period of fetching delivered bundles.
+	fetchInterval = time.Second
+	// statusInterval is the period of querying the delivery state of sent messages.
+	statusInterval = 5 * time.Second
+)
+
+// chat registers for the endpoint, sends lines from stdin to the peer and prints messages received from the peer.
+func chat(args []string) {
+	flags := flag.NewFlagSet("chat", flag.ExitOnError)
+	nodeURL := flags.String("node", "http://localhost:8080", "URL of the node running the REST agent")
+	lifetime := flags.String("lifetime", "24h", "lifetime of sent messages")
+	priority := flags.String("priority", "normal", "priority of sent messages: bulk, normal or expedited")
+	keyFile := flags.String("key", "", "private key created by keygen")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 3 || *keyFile == "" {
+		printUsage()
+		os.Exit(1)
+	}
+	endpoint, peer, peerKeyHex := flags.Arg(0), flags.Arg(1), flags.Arg(2)
+
+	priv, err := loadKey(*keyFile)
+	if err != nil {
+		printFatal(err, "Loading key failed")
+	}
+	peerKey, err := parsePublicKey(peerKeyHex)
+	if err != nil {
+		printFatal(err, "Parsing peer's public key failed")
+	}
+	messageLifetime, err := time.ParseDuration(*lifetime)
+	if err != nil {
+		printFatal(err, "Parsing lifetime failed")
+	}
+
+	filter := &application_agent.RestDeliveryFilter{Source: peer}
+	c, err := client.Connect(*nodeURL, endpoint, filter)
+	if err != nil {
+		printFatal(err, "Registering at node failed")
+	}
+	fmt.Printf("Chatting as %s with %s, type a message and press enter\n", endpoint, peer)
+
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		receiveLoop(c, priv)
+	}()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	sent := newSentMessages()
+	go func() {
+		defer wg.Done()
+		statusLoop(c, sent, stop)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	sendOptions := client.SendOptions{
+		Destination:   peer,
+		Lifetime:      messageLifetime,
+		Priority:      *priority,
+		StatusReports: true,
+	}
+
+loop:
+	for {
+		select {
+		case <-signals:
+			break loop
+
+		case line, ok := <-lines:
+			if !ok {
+				break loop
+			} else if line == "" {
+				continue
+			}
+
+			payload, err := seal(peerKey, endpoint, peer, []byte(line))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Encrypting message failed: %v\n", err)
+				continue
+			}
+			if bndl, err := c.Send([]byte(payload), sendOptions); err != nil {
+				fmt.Fprintf(os.Stderr, "Sending message failed: %v\n", err)
+			} else {
+				sent.add(bndl.BundleID, line)
+			}
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+	if err := c.Close(); err != nil {
+		printFatal(err, "Unregistering at node failed")
+	}
+	<-received
+}
+
+// receiveLoop fetches delivered bundles and prints their decrypted messages until the client is closed.
+func receiveLoop(c *client.Client, priv *ecdh.PrivateKey) {
+	bundles, errs := c.Receive(fetchInterval)
+	for {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Fetching messages failed: %v\n", err)
+
+		case bndl, ok := <-bundles:
+			if !ok {
+				return
+			}
+
+			payload, err := unseal(priv, bndl.Source, bndl.Destination, string(bndl.Payload))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Discarding undecryptable message from %s: %v\n", bndl.Source, err)
+				continue
+			}
+			fmt.Printf("[%s] %s: %s\n", time.Now().Format("15:04:05"), bndl.Source, payload)
+		}
+	}
+}
+
+// sentMessages are the sent messages awaiting their delivery, indexed by their bundle IDs.
+type sentMessages struct {
+	mutex    sync.Mutex
+	messages map[string]string
+}
+
+func newSentMessages() *sentMessages {
+	return &sentMessages{messages: make(map[string]string)}
+}
+
+func (sent *sentMessages) add(bundleID, message string) {
+	sent.mutex.Lock()
+	defer sent.mutex.Unlock()
+	sent.messages[bundleID] = message
+}
+
+// pending returns a copy of the messages awaiting their delivery.
+func (sent *sentMessages) pending() map[string]string {
+	sent.mutex.Lock()
+	defer sent.mutex.Unlock()
+
+	pending := make(map[string]string, len(sent.messages))
+	for bundleID, message := range sent.messages {
+		pending[bundleID] = message
+	}
+	return pending
+}
+
+func (sent *sentMessages) remove(bundleID string) {
+	sent.mutex.Lock()
+	defer sent.mutex.Unlock()
+	delete(sent.messages, bundleID)
+}
+
+// statusLoop queries the delivery state of sent messages and reports their delivery or deletion until stop is closed.
+func statusLoop(c *client.Client, sent *sentMessages, stop <-chan struct{}) {
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+
+		case <-ticker.C:
+			for bundleID, message := range sent.pending() {
+				state, err := c.Status(bundleID)
+				switch {
+				case err != nil:
+					// the bundle's lifetime is exceeded or the node was restarted
+					fmt.Fprintf(os.Stderr, "Delivery of %q is unknown: %v\n", message, err)
+					sent.remove(bundleID)
+
+				case state.Delivered:
+					fmt.Printf("Delivered %q after %s\n", message, state.DeliveredAt.Sub(state.Sent).Round(time.Second))
+					sent.remove(bundleID)
+
+				case len(state.DeletedBy) > 0:
+					fmt.Fprintf(os.Stderr, "Message %q was deleted by %s\n", message, strings.Join(state.DeletedBy, ", "))
+					sent.remove(bundleID)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// messagePrefix marks an encrypted chat message within a payload, followed by the base64 encoded sealed message.
+const messagePrefix = "dtnchat1:"
+
+// keygen creates an X25519 private key and prints its public key.
+func keygen(args []string) {
+	if len(args) != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		printFatal(err, "Generating key failed")
+	}
+
+	if err := os.WriteFile(args[0], []byte(hex.EncodeToString(priv.Bytes())+"\n"), 0600); err != nil {
+		printFatal(err, "Writing key failed")
+	}
+	fmt.Printf("Public key: %x\n", priv.PublicKey().Bytes())
+}
+
+// loadKey reads an X25519 private key created by keygen.
+func loadKey(filename string) (*ecdh.PrivateKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPrivateKey(raw)
+}
+
+// parsePublicKey parses a hex encoded X25519 public key, as printed by keygen.
+func parsePublicKey(s string) (*ecdh.PublicKey, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPublicKey(raw)
+}
+
+// messageCipher derives the AES-256-GCM cipher of a message from the shared secret of its ephemeral key agreement.
+func messageCipher(shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	hash := sha256.New()
+	hash.Write([]byte(messagePrefix))
+	hash.Write(shared)
+	hash.Write(ephemeral)
+	hash.Write(recipient)
+
+	block, err := aes.NewCipher(hash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts a message for the recipient's public key. The source and destination endpoints are authenticated,
+// binding the message to this conversation.
+//
+// The sealed message is the ephemeral public key, the nonce and the ciphertext, base64 encoded after messagePrefix.
+func seal(recipient *ecdh.PublicKey, source, destination string, message []byte) (string, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := messageCipher(shared, ephemeral.PublicKey().Bytes(), recipient.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := append(ephemeral.PublicKey().Bytes(), nonce...)
+	sealed = aead.Seal(sealed, nonce, message, []byte(source+" "+destination))
+	return messagePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// unseal decrypts a message sealed for this private key.
+func unseal(priv *ecdh.PrivateKey, source, destination string, payload string) ([]byte, error) {
+	if !strings.HasPrefix(payload, messagePrefix) {
+		return nil, fmt.Errorf("payload is no chat message")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(payload, messagePrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	keySize := len(priv.PublicKey().Bytes())
+	if len(sealed) < keySize {
+		return nil, fmt.Errorf("sealed message of %d bytes is too short", len(sealed))
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(sealed[:keySize])
+	if err != nil {
+		return nil, err
+	}
+	shared, err := priv.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := messageCipher(shared, ephemeral.Bytes(), priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < keySize+aead.NonceSize() {
+		return nil, fmt.Errorf("sealed message of %d bytes is too short", len(sealed))
+	}
+	nonce, ciphertext := sealed[keySize:keySize+aead.NonceSize()], sealed[keySize+aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(source+" "+destination))
+}
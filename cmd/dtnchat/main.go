@@ -0,0 +1,49 @@
+// dtnchat is an example chat between two user endpoints, built on the REST agent of a running dtnd node. It serves as
+// a reference for the agent API: a client registers for its endpoint, sends bundles with send options, e.g., lifetime,
+// priority and a requested delivery status report, fetches delivered bundles, and is notified of its bundles' delivery.
+//
+// Messages are encrypted end-to-end between the users, so that neither relaying nodes nor the users' own nodes can
+// read them. As BPSec's Block Confidentiality Block is not implemented yet, the payload is encrypted by dtnchat itself:
+// an ephemeral X25519 key agreement with the peer's public key derives an AES-256-GCM key for each message.
+//
+//	dtnchat keygen <key-file>
+//	dtnchat chat [-node http://localhost:8080] [-lifetime 24h] [-priority normal] -key key-file <endpoint> <peer-endpoint> <peer-public-key>
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func printUsage() {
+	_, _ = fmt.Fprintf(os.Stderr, `Usage of %s:
+  keygen <key-file>
+      Create an X25519 private key to receive encrypted messages and print its public key for the peer.
+  chat [-node http://localhost:8080] [-lifetime 24h] [-priority normal] -key key-file <endpoint> <peer-endpoint> <peer-public-key>
+      Chat with a peer through a node's REST agent. Lines from stdin are sent, received messages are printed.
+`, os.Args[0])
+}
+
+// printFatal prints an error and exits.
+func printFatal(err error, msg string) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
+	os.Exit(1)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "keygen":
+		keygen(args)
+	case "chat":
+		chat(args)
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+}
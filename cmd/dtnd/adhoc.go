@@ -0,0 +1,21 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/adhoc"
+)
+
+// joinAdHocNetwork joins the configured ad-hoc network. The returned network must be left by leaveAdHocNetwork when
+// the node stops, as the interface would remain in the ad-hoc mode otherwise.
+func joinAdHocNetwork(conf adhoc.Config) (*adhoc.Network, error) {
+	return adhoc.Up(conf)
+}
+
+// leaveAdHocNetwork leaves the ad-hoc network joined by joinAdHocNetwork.
+func leaveAdHocNetwork(network *adhoc.Network) {
+	log.Info("Leaving ad-hoc network")
+	if err := network.Down(); err != nil {
+		log.WithError(err).Warn("Error leaving ad-hoc network")
+	}
+}
@@ -1,18 +1,34 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dtn7/dtn7-go/pkg/adhoc"
+	"github.com/dtn7/dtn7-go/pkg/admin"
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/emulation"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
 	"github.com/dtn7/dtn7-go/pkg/discovery"
+	"github.com/dtn7/dtn7-go/pkg/fault_injection"
+	"github.com/dtn7/dtn7-go/pkg/identity"
+	"github.com/dtn7/dtn7-go/pkg/logging"
+	"github.com/dtn7/dtn7-go/pkg/metrics"
+	"github.com/dtn7/dtn7-go/pkg/naming"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/reputation"
 	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/store"
 )
 
 type ConfigError struct {
@@ -31,36 +47,218 @@ func (e *ConfigError) Error() string {
 func (e *ConfigError) Unwrap() error { return e.cause }
 
 type config struct {
-	NodeID    bpv7.EndpointID
-	LogLevel  log.Level
-	Store     storeConfig
-	Routing   routingConfig
-	Listener  []cla.ListenerConfig
-	Agents    agentsConfig
-	Discovery []discovery.Announcement
-	Cron      cronConfig
+	NodeID       bpv7.EndpointID
+	NodeAliases  []bpv7.EndpointID
+	LogLevel     log.Level
+	LogSinks     []logging.SinkConfig
+	Profile      profile
+	Roaming      bool
+	Clockless    bool
+	Store        storeConfig
+	Routing      routingConfig
+	Listener     []cla.ListenerConfig
+	MTCP         mtcp.Timeouts
+	Peer         []peerConfig
+	PeerLiveness peerLivenessConfig
+	Agents       agentsConfig
+	Discovery    discoveryConfig
+	Dispatch     processing.DispatchSchedulerConfig
+	// MTCPCompression is parsed from the MTCP configuration block.
+	MTCPCompression bool
+	// PayloadChecksumPolicy is parsed from the Agents' configuration block.
+	PayloadChecksumPolicy processing.PayloadChecksumPolicy
+	// DuplicateWindow and DuplicatePolicy are parsed from the Agents' configuration block, deduplication is disabled
+	// for a zero window.
+	DuplicateWindow time.Duration
+	DuplicatePolicy application_agent.DuplicatePolicy
+	// Export is parsed from the Agents' configuration block and nil unless its optional Export block exists.
+	Export *exportConfig
+	// Spool is parsed from the Agents' configuration block and nil unless its optional Spool block exists.
+	Spool *application_agent.SpoolConfig
+	// AdminUsers are parsed from the REST configuration block and nil unless at least one admin API user exists.
+	AdminUsers *admin.Users
+	// FaultInjection is nil unless the optional configuration block exists.
+	FaultInjection *fault_injection.Config
+	// Emulation is nil unless the optional link emulation configuration block exists.
+	Emulation *emulation.Emulator
+	// Shaping is nil unless the optional configuration block exists.
+	Shaping *cla.ShapingConfig
+	// SendQueues is nil unless the optional configuration block exists.
+	SendQueues *cla.SendQueueConfig
+	// MTU is nil unless the optional MTU learning configuration block exists.
+	MTU *cla.MTUConfig
+	// ConnectionLimits is nil unless the optional configuration block exists.
+	ConnectionLimits *cla.ConnectionLimits
+	// Multihoming is nil unless the optional configuration block exists.
+	Multihoming *cla.MultihomingConfig
+	// Offload is nil unless the optional configuration block exists.
+	Offload *processing.OffloadConfig
+	// Signatures is nil unless the optional configuration block exists.
+	Signatures *processing.SignatureConfig
+	// PreviousNode are the rules of revealing this node in forwarded bundles, all reveal it if empty.
+	PreviousNode []processing.PreviousNodeRule
+	// Gateway are the rules of rewriting received bundles' endpoints, none are rewritten if empty.
+	Gateway []processing.GatewayRule
+	// SuspensionIdle is zero unless the optional suspension configuration block exists.
+	SuspensionIdle time.Duration
+	// Reputation is nil unless the optional configuration block exists.
+	Reputation *reputation.Config
+	// Certificate is nil unless the optional identity configuration block exists.
+	Certificate *tls.Certificate
+	// Naming is nil unless the optional configuration block exists.
+	Naming *naming.Config
+	// AdHoc is nil unless the optional configuration block exists. Its listener is part of the Listener.
+	AdHoc *adhoc.Config
+	// JournalPath is empty unless the optional journal configuration block exists.
+	JournalPath string
+	// Metrics is nil unless the optional configuration block exists.
+	Metrics *metrics.Config
+	// DiagnosticsAddress is empty unless the optional diagnostics configuration block exists.
+	DiagnosticsAddress string
 }
 
 type tomlConfig struct {
-	NodeID   string `toml:"node_id"`
-	LogLevel string `toml:"log_level"`
-	Store    storeConfig
-	Routing  tomlRoutingConfig
-	Listener []listenerTomlConfig
-	Agents   agentsConfig
-	Cron     cronTomlConfig
+	NodeID           string   `toml:"node_id"`
+	NodeAliases      []string `toml:"node_aliases"`
+	LogLevel         string   `toml:"log_level"`
+	Log              []logTomlConfig
+	Profile          string
+	Roaming          bool
+	Clockless        bool
+	Store            storeTomlConfig
+	Routing          tomlRoutingConfig
+	Listener         []listenerTomlConfig
+	MTCP             mtcpTomlConfig
+	Peer             []peerTomlConfig
+	PeerLiveness     peerLivenessTomlConfig
+	Agents           agentsConfig
+	Discovery        discoveryTomlConfig
+	Dispatch         dispatchTomlConfig
+	Cron             *cronTomlConfig
+	FaultInjection   *faultInjectionTomlConfig
+	Emulation        *emulationTomlConfig
+	Shaping          *shapingTomlConfig
+	SendQueues       *sendQueuesTomlConfig
+	MTU              *mtuTomlConfig
+	ConnectionLimits *connectionLimitsTomlConfig
+	Multihoming      *multihomingTomlConfig
+	Offload          *offloadTomlConfig
+	Signatures       *signaturesTomlConfig
+	PreviousNode     []previousNodeTomlConfig
+	Gateway          []gatewayTomlConfig
+	Suspension       *suspensionTomlConfig
+	Reputation       *reputationTomlConfig
+	Identity         *identityTomlConfig
+	Naming           *namingTomlConfig
+	AdHoc            *adHocTomlConfig
+	Journal          *journalTomlConfig
+	Metrics          *metricsTomlConfig
+	Diagnostics      *diagnosticsTomlConfig
 }
 
-type storeConfig struct {
+// logTomlConfig describes a log sink, see logging.SinkConfig.
+type logTomlConfig struct {
+	Type string
+	// Level defaults to the log level.
+	Level string
+	// Components map components to their levels.
+	Components map[string]string
+
+	Path           string
+	MaxSize        int64  `toml:"max_size"`
+	RotateInterval string `toml:"rotate_interval"`
+	MaxBackups     int    `toml:"max_backups"`
+
+	Network string
+	Address string
+	Tag     string
+}
+
+type storeTomlConfig struct {
 	Path string
+	// Capacity in bytes is reported to the routing algorithm as storage pressure, zero for unlimited.
+	Capacity int64
+	// CompactionInterval schedules the store's compaction, disabled if empty.
+	CompactionInterval string `toml:"compaction_interval"`
+	// CongestionPolicy decides on received bundles exceeding the Capacity, all are accepted if empty.
+	CongestionPolicy string `toml:"congestion_policy"`
+	// Shards partition the store by the bundles' destinations, unsharded if zero or one.
+	Shards int
+	// AlreadySentLimit bounds each bundle's already sent list, unbounded if zero.
+	AlreadySentLimit int `toml:"already_sent_limit"`
+	// AlreadySentPruning selects the entries pruned from an exceeding already sent list, "oldest" if empty.
+	AlreadySentPruning string `toml:"already_sent_pruning"`
+	// CheckIntegrity verifies all stored bundles on startup, quarantining corrupt ones.
+	CheckIntegrity bool `toml:"check_integrity"`
+}
+
+type storeConfig struct {
+	Path               string
+	Capacity           int64
+	CompactionInterval time.Duration
+	CongestionPolicy   processing.CongestionPolicy
+	Shards             int
+	AlreadySentLimit   int
+	AlreadySentPruning store.SentPruning
+	CheckIntegrity     bool
 }
 
 type tomlRoutingConfig struct {
-	Algorithm string
+	Algorithm   string
+	ContactPlan string `toml:"contact_plan"`
+	// Copies of each bundle created by the "spray_and_wait" algorithm, routing.DefaultSprayCopies if zero.
+	Copies uint64
+	// ResendOnReconnect forgets earlier transmissions to a peer when it connects again.
+	ResendOnReconnect bool `toml:"resend_on_reconnect"`
+	// ReplayWindow rejects replayed bundles and bundles created before this duration, disabled if empty.
+	ReplayWindow string `toml:"replay_window"`
+	// StatusReportHops limits status reports to this number of hops, unlimited if zero.
+	StatusReportHops uint8 `toml:"status_report_hops"`
+	// QuarantineAttempts quarantines bundles whose processing failed this often, disabled if zero.
+	QuarantineAttempts uint `toml:"quarantine_attempts"`
+	// Costs is the optional peer costs' configuration block.
+	Costs *costsTomlConfig
+	// Latency is the optional latency-aware routing's configuration block.
+	Latency *latencyTomlConfig
+	// Aging is the optional replication throttle's configuration block.
+	Aging *agingTomlConfig
+}
+
+// costsTomlConfig assigns administrative costs to peers by their node ID or their CLAs' networks.
+type costsTomlConfig struct {
+	Default   uint
+	Expensive uint
+	Priority  string
+	Peers     map[string]uint
+	Networks  map[string]uint
+}
+
+// latencyTomlConfig describes the latency-aware routing of interactive bundles.
+type latencyTomlConfig struct {
+	Priority string
+	Spread   float64
+}
+
+// agingTomlConfig describes the throttled replication of aging bundles.
+type agingTomlConfig struct {
+	Threshold float64
+	Exponent  float64
 }
 
 type routingConfig struct {
-	Algorithm routing.AlgorithmEnum
+	Algorithm          routing.AlgorithmEnum
+	ContactPlan        string
+	Copies             uint64
+	ResendOnReconnect  bool
+	ReplayWindow       time.Duration
+	StatusReportHops   uint8
+	QuarantineAttempts uint
+	// Costs is nil unless the optional peer costs' configuration block exists.
+	Costs *routing.CostConfig
+	// Latency is nil unless the optional latency-aware routing's configuration block exists.
+	Latency *routing.LatencyConfig
+	// Aging is nil unless the optional replication throttle's configuration block exists.
+	Aging *routing.AgingConfig
 }
 
 type listenerTomlConfig struct {
@@ -68,24 +266,375 @@ type listenerTomlConfig struct {
 	Address string
 }
 
+// mtcpTomlConfig describes the MTCP keepalive and timeout configuration block.
+type mtcpTomlConfig struct {
+	KeepaliveInterval string `toml:"keepalive_interval"`
+	WriteTimeout      string `toml:"write_timeout"`
+	IdleTimeout       string `toml:"idle_timeout"`
+	// Compression negotiates zstd compressed connections with peers enabling it as well.
+	Compression bool
+}
+
+// peerTomlConfig describes a statically configured peer.
+type peerTomlConfig struct {
+	Type     string
+	Address  string
+	Endpoint string `toml:"endpoint_id"`
+}
+
+type peerConfig struct {
+	Type     cla.CLAType
+	Address  string
+	Endpoint bpv7.EndpointID
+}
+
+// peerLivenessTomlConfig describes the static peers' probing configuration block.
+type peerLivenessTomlConfig struct {
+	ProbeInterval string `toml:"probe_interval"`
+	DownAfter     string `toml:"down_after"`
+}
+
+type peerLivenessConfig struct {
+	// ProbeInterval is the period for probing static peers and re-establishing lost connections.
+	ProbeInterval time.Duration
+	// DownAfter is the duration of failing probes, after which a peer is marked as down and avoided by routing.
+	DownAfter time.Duration
+}
+
+// discoveryTomlConfig describes the neighbour discovery's configuration block.
+type discoveryTomlConfig struct {
+	IPv4               bool
+	IPv6               bool
+	Interval           string
+	PeerExchange       bool   `toml:"peer_exchange"`
+	PeerExchangeMaxAge string `toml:"peer_exchange_max_age"`
+	// PeerExchangeHops floods peer lists up to this number of hops, sent only to the connected peer if zero.
+	PeerExchangeHops uint8 `toml:"peer_exchange_hops"`
+}
+
+type discoveryConfig struct {
+	IPv4          bool
+	IPv6          bool
+	Interval      time.Duration
+	Announcements []discovery.Announcement
+
+	PeerExchange       bool
+	PeerExchangeMaxAge time.Duration
+	PeerExchangeHops   uint8
+}
+
+// Enabled is true if announcements are sent via at least one IP version.
+func (dc discoveryConfig) Enabled() bool {
+	return dc.IPv4 || dc.IPv6
+}
+
 // agentsConfig describes the ApplicationAgents/Agent-configuration block.
 type agentsConfig struct {
 	REST agentsRESTConfig
+	// PayloadChecksumMismatch is either "drop" (default) or "deliver", see processing.PayloadChecksumPolicy.
+	PayloadChecksumMismatch string `toml:"payload_checksum_mismatch"`
+	// DuplicateWindow enables the send-side deduplication, DuplicatePolicy is either "coalesce" (default) or "reject".
+	DuplicateWindow string `toml:"duplicate_window"`
+	DuplicatePolicy string `toml:"duplicate_policy"`
+	Export          *agentsExportTomlConfig
+	Spool           *agentsSpoolTomlConfig
+}
+
+// agentsExportTomlConfig describes the optional ExportAgent's configuration block.
+type agentsExportTomlConfig struct {
+	Directory string
+	Endpoints []string
+}
+
+// agentsSpoolTomlConfig describes the optional spool of submitted bundles while the node cannot take them.
+type agentsSpoolTomlConfig struct {
+	Directory  string
+	MaxBundles int   `toml:"max_bundles"`
+	MaxBytes   int64 `toml:"max_bytes"`
+}
+
+type exportConfig struct {
+	Directory string
+	Endpoints []bpv7.EndpointID
 }
 
 // agentsWebserverConfig describes the nested "Webserver" configuration for agents.
 type agentsRESTConfig struct {
 	Address string
+	// DisableAdmin removes the admin API from the server, saving memory on constrained devices.
+	DisableAdmin bool `toml:"disable_admin"`
+	// MailboxLimit bounds the bundles waiting for each REST client's fetch, unbounded if zero.
+	MailboxLimit int `toml:"mailbox_limit"`
+	// AdminUsers enable authentication for the admin API.
+	AdminUsers []adminUserTomlConfig `toml:"AdminUser"`
 }
 
-type cronConfig struct {
-	Dispatch time.Duration
+// adminUserTomlConfig describes a user of the admin API.
+type adminUserTomlConfig struct {
+	Name  string
+	Role  string
+	Token string
+}
+
+// defaultDispatchInterval is used if neither the Dispatch block nor the legacy Cron block configures an interval.
+const defaultDispatchInterval = 10 * time.Second
+
+// dispatchTomlConfig describes the dispatch scheduler's configuration block.
+type dispatchTomlConfig struct {
+	Interval    string
+	Jitter      string
+	OnNewBundle bool `toml:"on_new_bundle"`
+	// OnNewPeer is true if unset, as nodes always swept for new peers before the scheduler was configurable.
+	OnNewPeer *bool `toml:"on_new_peer"`
 }
 
+// cronTomlConfig is the legacy configuration block of the dispatch interval, superseded by dispatchTomlConfig.
 type cronTomlConfig struct {
 	Dispatch string
 }
 
+// offloadTomlConfig describes the optional configuration block for offloading bundles to a depot node.
+type offloadTomlConfig struct {
+	Depot     string
+	Threshold float64
+}
+
+// signaturesTomlConfig describes the optional configuration block for verifying bundles' SignatureBlocks.
+type signaturesTomlConfig struct {
+	// Verify is either "never", "every-hop", "destination" (default) or "verifiers", see processing.SignatureRole.
+	Verify    string
+	Verifiers []string
+	Required  bool
+}
+
+// previousNodeTomlConfig describes a rule of revealing this node in forwarded bundles, see processing.PreviousNodeRule.
+type previousNodeTomlConfig struct {
+	// Policy is either "reveal", "anonymous" or "omit", see processing.PreviousNodePolicy.
+	Policy       string
+	Destinations []string
+}
+
+// gatewayTomlConfig describes a rule of rewriting received bundles' endpoints, see processing.GatewayRule.
+type gatewayTomlConfig struct {
+	From string
+	To   string
+	// Node rewrites all endpoints of the From endpoint's node instead of only this endpoint.
+	Node bool
+}
+
+// faultInjectionTomlConfig describes the optional fault injection's configuration block.
+type faultInjectionTomlConfig struct {
+	DropProbability         float64 `toml:"drop_probability"`
+	SendDelay               string  `toml:"send_delay"`
+	StoreFailureProbability float64 `toml:"store_failure_probability"`
+	DisconnectInterval      string  `toml:"disconnect_interval"`
+}
+
+// linkTomlConfig describes an emulated link.
+type linkTomlConfig struct {
+	Delay     string
+	Jitter    string
+	Loss      float64
+	Bandwidth uint64
+}
+
+// emulationTomlConfig describes the optional link emulation, whose peers override the default link.
+type emulationTomlConfig struct {
+	linkTomlConfig
+	Peers map[string]linkTomlConfig
+}
+
+// parseLinkConfig parses an emulated link, whose durations are optional.
+func parseLinkConfig(tomlConf linkTomlConfig) (linkConf emulation.LinkConfig, err error) {
+	linkConf = emulation.LinkConfig{Loss: tomlConf.Loss, Bandwidth: tomlConf.Bandwidth}
+	if tomlConf.Delay != "" {
+		if linkConf.Delay, err = time.ParseDuration(tomlConf.Delay); err != nil {
+			return
+		}
+	}
+	if tomlConf.Jitter != "" {
+		if linkConf.Jitter, err = time.ParseDuration(tomlConf.Jitter); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// shapingTomlConfig describes the optional traffic shaping's configuration block.
+type shapingTomlConfig struct {
+	Rate   uint64
+	Shares map[string]float64
+}
+
+// sendQueuesTomlConfig describes the optional bounds of the CLAs' send queues.
+type sendQueuesTomlConfig struct {
+	MaxBundles int    `toml:"max_bundles"`
+	MaxBytes   uint64 `toml:"max_bytes"`
+}
+
+// mtuTomlConfig describes the optional learning of the CLAs' MTUs.
+type mtuTomlConfig struct {
+	Failures int
+	Minimum  int
+}
+
+// connectionLimitsTomlConfig describes the optional limits of the listeners' inbound connections.
+type connectionLimitsTomlConfig struct {
+	PerListener int `toml:"per_listener"`
+	PerSource   int `toml:"per_source"`
+	Backoff     string
+}
+
+// multihomingTomlConfig describes the optional source address selection of outgoing connections. Sources map networks
+// in CIDR notation to local source addresses.
+type multihomingTomlConfig struct {
+	Interfaces bool
+	Sources    map[string]string
+}
+
+// suspensionTomlConfig describes the optional suspension of idle connections.
+type suspensionTomlConfig struct {
+	Idle string
+}
+
+// reputationTomlConfig describes the optional tracking of peer reputations.
+type reputationTomlConfig struct {
+	QuarantineThreshold float64 `toml:"quarantine_threshold"`
+	QuarantineDuration  string  `toml:"quarantine_duration"`
+}
+
+// identityTomlConfig describes the optional node identity certificate, e.g., created by dtn-tool's certgen.
+type identityTomlConfig struct {
+	Certificate string
+	Key         string
+}
+
+// namingTomlConfig describes the optional name resolution. Names are mapped statically or published by this node,
+// signed by its identity key.
+type namingTomlConfig struct {
+	Lifetime string
+	// FloodHops floods the names up to this number of hops, sent only to the connected peer if zero.
+	FloodHops uint8 `toml:"flood_hops"`
+	Static    map[string]nameTomlConfig
+	Publish   map[string]nameTomlConfig
+}
+
+// nameTomlConfig describes a name's endpoint and optionally a CLA to reach its node.
+type nameTomlConfig struct {
+	Endpoint string `toml:"endpoint_id"`
+	Type     string
+	Address  string
+}
+
+// adHocTomlConfig describes the optional ad-hoc network, to which a listener and the discovery are bound.
+type adHocTomlConfig struct {
+	Interface string
+	Mode      string
+	SSID      string
+	Frequency uint
+	Address   string
+	// Listener's type and port, bound to the network's address.
+	Listener string
+	Port     uint16
+}
+
+// journalTomlConfig describes the optional journal of all attempted transmissions.
+type journalTomlConfig struct {
+	Path string
+}
+
+// metricsTomlConfig describes the optional push of metrics to a monitoring system.
+type metricsTomlConfig struct {
+	Exporter string
+	Address  string
+	Token    string
+	Prefix   *string
+	Interval string
+}
+
+// diagnosticsTomlConfig describes the optional diagnostics listener.
+type diagnosticsTomlConfig struct {
+	Address string
+}
+
+// defaultNameLifetime is the validity of published name records, unless configured otherwise.
+const defaultNameLifetime = 24 * time.Hour
+
+// identityExpiryWarning is the remaining validity of the node identity certificate below which a warning is logged.
+const identityExpiryWarning = 30 * 24 * time.Hour
+
+// parseCostsConfig parses the peer costs, whose networks are in CIDR notation. Unless another priority is given,
+// expedited bundles may use expensive peers.
+func parseCostsConfig(tomlConf costsTomlConfig) (*routing.CostConfig, error) {
+	costConf := routing.CostConfig{
+		Default:   tomlConf.Default,
+		Peers:     make(map[bpv7.EndpointID]uint),
+		Expensive: tomlConf.Expensive,
+		Priority:  bpv7.PriorityExpedited,
+	}
+	if tomlConf.Priority != "" {
+		priority, err := bpv7.PriorityFromString(tomlConf.Priority)
+		if err != nil {
+			return nil, err
+		}
+		costConf.Priority = priority
+	}
+
+	for peer, cost := range tomlConf.Peers {
+		eid, err := bpv7.NewEndpointID(peer)
+		if err != nil {
+			return nil, err
+		}
+		costConf.Peers[eid] = cost
+	}
+
+	// TOML tables are unordered, thus more specific networks are checked first.
+	for network, cost := range tomlConf.Networks {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, err
+		}
+		costConf.Networks = append(costConf.Networks, routing.NetworkCost{Network: ipNet, Cost: cost})
+	}
+	sort.Slice(costConf.Networks, func(i, j int) bool {
+		iOnes, _ := costConf.Networks[i].Network.Mask.Size()
+		jOnes, _ := costConf.Networks[j].Network.Mask.Size()
+		return iOnes > jOnes
+	})
+
+	if err := costConf.CheckValid(); err != nil {
+		return nil, err
+	}
+	return &costConf, nil
+}
+
+// parseMultihomingConfig parses the source addresses by their networks in CIDR notation, more specific networks first.
+func parseMultihomingConfig(tomlConf multihomingTomlConfig) (*cla.MultihomingConfig, error) {
+	multihomingConf := cla.MultihomingConfig{Interfaces: tomlConf.Interfaces}
+
+	for network, source := range tomlConf.Sources {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, err
+		}
+		sourceIP := net.ParseIP(source)
+		if sourceIP == nil {
+			return nil, fmt.Errorf("invalid source address %q", source)
+		}
+		multihomingConf.Networks = append(multihomingConf.Networks, cla.SourceNetwork{Network: ipNet, Source: sourceIP})
+	}
+	sort.Slice(multihomingConf.Networks, func(i, j int) bool {
+		iOnes, _ := multihomingConf.Networks[i].Network.Mask.Size()
+		jOnes, _ := multihomingConf.Networks[j].Network.Mask.Size()
+		return iOnes > jOnes
+	})
+
+	if err := multihomingConf.CheckValid(); err != nil {
+		return nil, err
+	}
+	return &multihomingConf, nil
+}
+
 func parseListenPort(endpoint string) (port int, err error) {
 	var portStr string
 	_, portStr, err = net.SplitHostPort(endpoint)
@@ -98,7 +647,8 @@ func parseListenPort(endpoint string) (port int, err error) {
 
 func parse(filename string) (config, error) {
 	var tomlConf tomlConfig
-	if _, err := toml.DecodeFile(filename, &tomlConf); err != nil {
+	meta, err := toml.DecodeFile(filename, &tomlConf)
+	if err != nil {
 		return config{}, NewConfigError("Error parsing toml", err)
 	}
 
@@ -113,6 +663,18 @@ func parse(filename string) (config, error) {
 	}
 	conf.NodeID = nodeID
 
+	// Parse optional node ID aliases, e.g., an ipn node ID next to a dtn one
+	for _, aliasStr := range tomlConf.NodeAliases {
+		alias, err := bpv7.NewEndpointID(aliasStr)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing node alias", err)
+		}
+		if alias == bpv7.DtnNone() || alias.SameNode(nodeID) {
+			return config{}, NewConfigError(fmt.Sprintf("Node alias %v must differ from NodeID and dtn:none", alias), nil)
+		}
+		conf.NodeAliases = append(conf.NodeAliases, alias)
+	}
+
 	// Parse and set log level
 	logLevel, err := log.ParseLevel(tomlConf.LogLevel)
 	if err != nil {
@@ -120,15 +682,143 @@ func parse(filename string) (config, error) {
 	}
 	conf.LogLevel = logLevel
 
-	// Store configuration needs no parsing
-	conf.Store = tomlConf.Store
+	// Parse optional log sinks, otherwise logging to stderr
+	for _, logConf := range tomlConf.Log {
+		sinkConf := logging.SinkConfig{
+			Type:       logging.SinkType(logConf.Type),
+			Level:      logLevel,
+			Components: make(map[string]log.Level),
+			Path:       logConf.Path,
+			Rotation:   logging.RotationConfig{MaxSize: logConf.MaxSize, MaxBackups: logConf.MaxBackups},
+			Network:    logConf.Network,
+			Address:    logConf.Address,
+			Tag:        logConf.Tag,
+		}
+		if logConf.Level != "" {
+			if sinkConf.Level, err = log.ParseLevel(logConf.Level); err != nil {
+				return config{}, NewConfigError("Error parsing log sink's level", err)
+			}
+		}
+		for component, levelStr := range logConf.Components {
+			if sinkConf.Components[component], err = log.ParseLevel(levelStr); err != nil {
+				return config{}, NewConfigError(fmt.Sprintf("Error parsing log level of component %s", component), err)
+			}
+		}
+		if logConf.RotateInterval != "" {
+			if sinkConf.Rotation.Interval, err = time.ParseDuration(logConf.RotateInterval); err != nil {
+				return config{}, NewConfigError("Error parsing log rotation interval", err)
+			}
+		}
+		if err := sinkConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid log sink", err)
+		}
+		conf.LogSinks = append(conf.LogSinks, sinkConf)
+	}
+
+	// Parse resource usage profile, see profile.go
+	if conf.Profile, err = parseProfile(tomlConf.Profile); err != nil {
+		return config{}, NewConfigError("Error parsing profile", err)
+	}
+	conf.Roaming = tomlConf.Roaming
+	conf.Clockless = tomlConf.Clockless
+
+	// Parse store configuration
+	conf.Store = storeConfig{
+		Path:           tomlConf.Store.Path,
+		Capacity:       tomlConf.Store.Capacity,
+		Shards:         tomlConf.Store.Shards,
+		CheckIntegrity: tomlConf.Store.CheckIntegrity,
+	}
+	if conf.Store.Capacity < 0 {
+		return config{}, NewConfigError("Store capacity must not be negative", nil)
+	}
+	if conf.Store.Shards < 0 {
+		return config{}, NewConfigError("Store shards must not be negative", nil)
+	} else if conf.Store.Shards == 0 {
+		conf.Store.Shards = 1
+	}
+	if conf.Store.AlreadySentLimit = tomlConf.Store.AlreadySentLimit; conf.Store.AlreadySentLimit < 0 {
+		return config{}, NewConfigError("Store already sent limit must not be negative", nil)
+	}
+	if tomlConf.Store.AlreadySentPruning != "" {
+		if conf.Store.AlreadySentPruning, err = store.SentPruningFromString(tomlConf.Store.AlreadySentPruning); err != nil {
+			return config{}, NewConfigError("Error parsing store already sent pruning", err)
+		}
+		if conf.Store.AlreadySentLimit == 0 {
+			return config{}, NewConfigError("An already sent pruning requires an already sent limit", nil)
+		}
+	}
+	if tomlConf.Store.CompactionInterval != "" {
+		if conf.Store.CompactionInterval, err = time.ParseDuration(tomlConf.Store.CompactionInterval); err != nil {
+			return config{}, NewConfigError("Error parsing store compaction interval", err)
+		}
+		if conf.Store.CompactionInterval <= 0 {
+			return config{}, NewConfigError("Store compaction interval must be positive", nil)
+		}
+	}
+	if tomlConf.Store.CongestionPolicy != "" {
+		if conf.Store.CongestionPolicy, err = processing.CongestionPolicyFromString(tomlConf.Store.CongestionPolicy); err != nil {
+			return config{}, NewConfigError("Error parsing store congestion policy", err)
+		}
+		if conf.Store.Capacity == 0 {
+			return config{}, NewConfigError("A congestion policy requires a store capacity", nil)
+		}
+	}
 
 	// Parse routing configuration
 	algorithm, err := routing.AlgorithmEnumFromString(tomlConf.Routing.Algorithm)
 	if err != nil {
 		return config{}, NewConfigError("Error parsing routing Algorithm", err)
 	}
-	conf.Routing = routingConfig{Algorithm: algorithm}
+	conf.Routing = routingConfig{
+		Algorithm:          algorithm,
+		ContactPlan:        tomlConf.Routing.ContactPlan,
+		Copies:             routing.DefaultSprayCopies,
+		ResendOnReconnect:  tomlConf.Routing.ResendOnReconnect,
+		StatusReportHops:   tomlConf.Routing.StatusReportHops,
+		QuarantineAttempts: tomlConf.Routing.QuarantineAttempts,
+	}
+	if tomlConf.Routing.Copies != 0 {
+		conf.Routing.Copies = tomlConf.Routing.Copies
+	}
+	if tomlConf.Routing.ReplayWindow != "" {
+		if conf.Routing.ReplayWindow, err = time.ParseDuration(tomlConf.Routing.ReplayWindow); err != nil {
+			return config{}, NewConfigError("Error parsing routing replay window", err)
+		} else if conf.Routing.ReplayWindow <= 0 {
+			return config{}, NewConfigError("Routing replay window must be positive", nil)
+		}
+	}
+
+	if tomlConf.Routing.Costs != nil {
+		if conf.Routing.Costs, err = parseCostsConfig(*tomlConf.Routing.Costs); err != nil {
+			return config{}, NewConfigError("Invalid peer costs configuration", err)
+		}
+	}
+	if tomlConf.Routing.Latency != nil {
+		latencyConf := routing.LatencyConfig{Priority: bpv7.PriorityExpedited, Spread: tomlConf.Routing.Latency.Spread}
+		if tomlConf.Routing.Latency.Priority != "" {
+			if latencyConf.Priority, err = bpv7.PriorityFromString(tomlConf.Routing.Latency.Priority); err != nil {
+				return config{}, NewConfigError("Error parsing latency-aware routing priority", err)
+			}
+		}
+		if err := latencyConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid latency-aware routing configuration", err)
+		}
+		conf.Routing.Latency = &latencyConf
+	}
+	if tomlConf.Routing.Aging != nil {
+		agingConf := routing.AgingConfig{Threshold: 0.5, Exponent: 1}
+		if tomlConf.Routing.Aging.Threshold != 0 {
+			agingConf.Threshold = tomlConf.Routing.Aging.Threshold
+		}
+		if tomlConf.Routing.Aging.Exponent != 0 {
+			agingConf.Exponent = tomlConf.Routing.Aging.Exponent
+		}
+		if err := agingConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid replication throttle configuration", err)
+		}
+		conf.Routing.Aging = &agingConf
+	}
 
 	// Parse listener configuration
 	for _, listener := range tomlConf.Listener {
@@ -142,18 +832,529 @@ func parse(filename string) (config, error) {
 		if err != nil {
 			return config{}, NewConfigError("Error parsing listener port", err)
 		}
-		conf.Discovery = append(conf.Discovery, discovery.Announcement{Type: claType, Port: uint(port), Endpoint: nodeID})
+		conf.Discovery.Announcements = append(conf.Discovery.Announcements, discovery.Announcement{Type: claType, Port: uint(port), Endpoint: nodeID})
 	}
 
-	// Agents config needs no parsing
+	// Parse static peer configuration
+	for _, peer := range tomlConf.Peer {
+		claType, err := cla.TypeFromString(peer.Type)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing Peer Type", err)
+		}
+		peerID, err := bpv7.NewEndpointID(peer.Endpoint)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing Peer EndpointID", err)
+		}
+		conf.Peer = append(conf.Peer, peerConfig{Type: claType, Address: peer.Address, Endpoint: peerID})
+	}
+
+	// Parse static peers' liveness configuration, which defaults to probes every ten seconds
+	conf.PeerLiveness = peerLivenessConfig{ProbeInterval: 10 * time.Second, DownAfter: time.Minute}
+	if tomlConf.PeerLiveness.ProbeInterval != "" {
+		if conf.PeerLiveness.ProbeInterval, err = time.ParseDuration(tomlConf.PeerLiveness.ProbeInterval); err != nil {
+			return config{}, NewConfigError("Error parsing peer probe interval", err)
+		}
+		if conf.PeerLiveness.ProbeInterval <= 0 {
+			return config{}, NewConfigError("Peer probe interval must be positive", nil)
+		}
+	}
+	if tomlConf.PeerLiveness.DownAfter != "" {
+		if conf.PeerLiveness.DownAfter, err = time.ParseDuration(tomlConf.PeerLiveness.DownAfter); err != nil {
+			return config{}, NewConfigError("Error parsing peer down duration", err)
+		}
+	}
+
+	// Parse MTCP timeouts; unset values keep their defaults
+	conf.MTCP = mtcp.DefaultTimeouts()
+	mtcpDurations := []struct {
+		name  string
+		value string
+		field *time.Duration
+	}{
+		{"keepalive interval", tomlConf.MTCP.KeepaliveInterval, &conf.MTCP.KeepaliveInterval},
+		{"write timeout", tomlConf.MTCP.WriteTimeout, &conf.MTCP.WriteTimeout},
+		{"idle timeout", tomlConf.MTCP.IdleTimeout, &conf.MTCP.IdleTimeout},
+	}
+	for _, d := range mtcpDurations {
+		if d.value == "" {
+			continue
+		}
+		if *d.field, err = time.ParseDuration(d.value); err != nil {
+			return config{}, NewConfigError(fmt.Sprintf("Error parsing MTCP %s", d.name), err)
+		}
+	}
+	if err = conf.MTCP.CheckValid(); err != nil {
+		return config{}, NewConfigError("Invalid MTCP timeouts", err)
+	}
+	conf.MTCPCompression = tomlConf.MTCP.Compression
+
+	// Parse discovery configuration, which defaults to IPv4 announcements every two seconds
+	conf.Discovery.IPv4 = true
+	conf.Discovery.Interval = 2 * time.Second
+	if meta.IsDefined("Discovery") {
+		conf.Discovery.IPv4 = tomlConf.Discovery.IPv4
+		conf.Discovery.IPv6 = tomlConf.Discovery.IPv6
+	}
+	if tomlConf.Discovery.Interval != "" {
+		interval, err := time.ParseDuration(tomlConf.Discovery.Interval)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing discovery interval", err)
+		}
+		conf.Discovery.Interval = interval
+	}
+	conf.Discovery.PeerExchange = tomlConf.Discovery.PeerExchange
+	conf.Discovery.PeerExchangeMaxAge = time.Hour
+	if tomlConf.Discovery.PeerExchangeMaxAge != "" {
+		maxAge, err := time.ParseDuration(tomlConf.Discovery.PeerExchangeMaxAge)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing peer exchange max age", err)
+		}
+		conf.Discovery.PeerExchangeMaxAge = maxAge
+	}
+	conf.Discovery.PeerExchangeHops = tomlConf.Discovery.PeerExchangeHops
+
+	// Parse optional ad-hoc network config, adding a listener on its address and announcing it
+	if tomlConf.AdHoc != nil {
+		adHocConf := adhoc.Config{
+			Interface: tomlConf.AdHoc.Interface,
+			SSID:      tomlConf.AdHoc.SSID,
+			Frequency: tomlConf.AdHoc.Frequency,
+			Address:   tomlConf.AdHoc.Address,
+		}
+		if adHocConf.Mode, err = adhoc.ModeFromString(tomlConf.AdHoc.Mode); err != nil {
+			return config{}, NewConfigError("Error parsing ad-hoc mode", err)
+		}
+		if err := adHocConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid ad-hoc configuration", err)
+		}
+
+		claType, err := cla.TypeFromString(tomlConf.AdHoc.Listener)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing ad-hoc listener type", err)
+		}
+		if tomlConf.AdHoc.Port == 0 {
+			return config{}, NewConfigError("Ad-hoc listener port must be set", nil)
+		}
+		address := net.JoinHostPort(adHocConf.IP().String(), strconv.Itoa(int(tomlConf.AdHoc.Port)))
+		conf.Listener = append(conf.Listener, cla.ListenerConfig{Type: claType, Address: address, EndpointId: nodeID})
+		conf.Discovery.Announcements = append(conf.Discovery.Announcements, discovery.Announcement{Type: claType, Port: uint(tomlConf.AdHoc.Port), Endpoint: nodeID})
+
+		if adHocConf.IP().To4() != nil {
+			conf.Discovery.IPv4 = true
+		} else {
+			conf.Discovery.IPv6 = true
+		}
+		conf.AdHoc = &adHocConf
+	}
+
+	// Agents config needs no parsing, except for the payload checksum policy, deduplication and the export agent
 	conf.Agents = tomlConf.Agents
+	if tomlConf.Agents.REST.MailboxLimit < 0 {
+		return config{}, NewConfigError("REST mailbox limit must not be negative", nil)
+	}
+	conf.PayloadChecksumPolicy = processing.ChecksumMismatchDrop
+	if tomlConf.Agents.PayloadChecksumMismatch != "" {
+		policy, err := processing.PayloadChecksumPolicyFromString(tomlConf.Agents.PayloadChecksumMismatch)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing payload checksum policy", err)
+		}
+		conf.PayloadChecksumPolicy = policy
+	}
+	if tomlConf.Agents.DuplicateWindow != "" {
+		if conf.DuplicateWindow, err = time.ParseDuration(tomlConf.Agents.DuplicateWindow); err != nil {
+			return config{}, NewConfigError("Error parsing duplicate window", err)
+		}
+		if conf.DuplicateWindow <= 0 {
+			return config{}, NewConfigError("Duplicate window must be positive", nil)
+		}
+	}
+	if tomlConf.Agents.DuplicatePolicy != "" {
+		if conf.DuplicatePolicy, err = application_agent.DuplicatePolicyFromString(tomlConf.Agents.DuplicatePolicy); err != nil {
+			return config{}, NewConfigError("Error parsing duplicate policy", err)
+		}
+	}
+	if spoolConf := tomlConf.Agents.Spool; spoolConf != nil {
+		conf.Spool = &application_agent.SpoolConfig{
+			Directory:  spoolConf.Directory,
+			MaxBundles: spoolConf.MaxBundles,
+			MaxBytes:   spoolConf.MaxBytes,
+		}
+		if err := conf.Spool.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid spool configuration", err)
+		}
+	}
+	if exportConf := tomlConf.Agents.Export; exportConf != nil {
+		if exportConf.Directory == "" {
+			return config{}, NewConfigError("Export agent requires a directory", nil)
+		}
+		if len(exportConf.Endpoints) == 0 {
+			return config{}, NewConfigError("Export agent requires at least one endpoint", nil)
+		}
+		conf.Export = &exportConfig{Directory: exportConf.Directory}
+		for _, endpointStr := range exportConf.Endpoints {
+			endpoint, err := bpv7.NewEndpointID(endpointStr)
+			if err != nil {
+				return config{}, NewConfigError("Error parsing export agent endpoint", err)
+			}
+			conf.Export.Endpoints = append(conf.Export.Endpoints, endpoint)
+		}
+	}
+	if len(tomlConf.Agents.REST.AdminUsers) > 0 {
+		conf.AdminUsers = admin.NewUsers()
+		for _, userConf := range tomlConf.Agents.REST.AdminUsers {
+			role, err := admin.ParseRole(userConf.Role)
+			if err != nil {
+				return config{}, NewConfigError("Error parsing admin API user's role", err)
+			}
+			if err := conf.AdminUsers.Add(admin.User{Name: userConf.Name, Role: role}, userConf.Token); err != nil {
+				return config{}, NewConfigError("Invalid admin API user", err)
+			}
+		}
+	}
 
-	// Parse cron config
-	dispatchTime, err := time.ParseDuration(tomlConf.Cron.Dispatch)
-	if err != nil {
-		return config{}, NewConfigError("Error parsing dispatch period", err)
+	// Parse dispatch scheduler config, accepting the interval of the legacy Cron block
+	dispatchIntervalStr := tomlConf.Dispatch.Interval
+	if tomlConf.Cron != nil && tomlConf.Cron.Dispatch != "" {
+		if dispatchIntervalStr != "" {
+			return config{}, NewConfigError("Dispatch interval is configured by both the Dispatch and the Cron block", nil)
+		}
+		log.Warn("The Cron block's dispatch key is deprecated, configure the Dispatch block's interval instead")
+		dispatchIntervalStr = tomlConf.Cron.Dispatch
+	}
+	conf.Dispatch.Interval = defaultDispatchInterval
+	if dispatchIntervalStr != "" {
+		if conf.Dispatch.Interval, err = time.ParseDuration(dispatchIntervalStr); err != nil {
+			return config{}, NewConfigError("Error parsing dispatch interval", err)
+		}
+	}
+
+	if tomlConf.Dispatch.Jitter != "" {
+		dispatchJitter, err := time.ParseDuration(tomlConf.Dispatch.Jitter)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing dispatch jitter", err)
+		}
+		conf.Dispatch.Jitter = dispatchJitter
+	}
+
+	conf.Dispatch.OnNewBundle = tomlConf.Dispatch.OnNewBundle
+	conf.Dispatch.OnNewPeer = tomlConf.Dispatch.OnNewPeer == nil || *tomlConf.Dispatch.OnNewPeer
+	if err := conf.Dispatch.CheckValid(); err != nil {
+		return config{}, NewConfigError("Invalid dispatch configuration", err)
+	}
+
+	// Parse optional fault injection config
+	if tomlConf.FaultInjection != nil {
+		faultConf := fault_injection.Config{
+			DropProbability:         tomlConf.FaultInjection.DropProbability,
+			StoreFailureProbability: tomlConf.FaultInjection.StoreFailureProbability,
+		}
+		if tomlConf.FaultInjection.SendDelay != "" {
+			if faultConf.SendDelay, err = time.ParseDuration(tomlConf.FaultInjection.SendDelay); err != nil {
+				return config{}, NewConfigError("Error parsing fault injection send delay", err)
+			}
+		}
+		if tomlConf.FaultInjection.DisconnectInterval != "" {
+			if faultConf.DisconnectInterval, err = time.ParseDuration(tomlConf.FaultInjection.DisconnectInterval); err != nil {
+				return config{}, NewConfigError("Error parsing fault injection disconnect interval", err)
+			}
+		}
+		if err := faultConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid fault injection configuration", err)
+		}
+		conf.FaultInjection = &faultConf
+	}
+
+	// Parse optional link emulation config
+	if tomlConf.Emulation != nil {
+		defaultLink, err := parseLinkConfig(tomlConf.Emulation.linkTomlConfig)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing emulated link", err)
+		}
+		peerLinks := make(map[bpv7.EndpointID]emulation.LinkConfig, len(tomlConf.Emulation.Peers))
+		for peer, linkTomlConf := range tomlConf.Emulation.Peers {
+			eid, err := bpv7.NewEndpointID(peer)
+			if err != nil {
+				return config{}, NewConfigError("Error parsing emulated link's peer", err)
+			}
+			if peerLinks[eid], err = parseLinkConfig(linkTomlConf); err != nil {
+				return config{}, NewConfigError("Error parsing emulated link", err)
+			}
+		}
+		if conf.Emulation, err = emulation.NewEmulator(defaultLink, peerLinks); err != nil {
+			return config{}, NewConfigError("Invalid link emulation configuration", err)
+		}
+	}
+
+	// Parse optional traffic shaping config
+	if tomlConf.Shaping != nil {
+		shapingConf := cla.ShapingConfig{
+			Rate:   tomlConf.Shaping.Rate,
+			Shares: make(map[cla.TrafficClass]float64, len(tomlConf.Shaping.Shares)),
+		}
+		for className, share := range tomlConf.Shaping.Shares {
+			class, err := cla.TrafficClassFromString(className)
+			if err != nil {
+				return config{}, NewConfigError("Error parsing shaping traffic class", err)
+			}
+			shapingConf.Shares[class] = share
+		}
+		if err := shapingConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid shaping configuration", err)
+		}
+		conf.Shaping = &shapingConf
+	}
+
+	// Parse optional send queues config
+	if tomlConf.SendQueues != nil {
+		queueConf := cla.SendQueueConfig{
+			MaxBundles: tomlConf.SendQueues.MaxBundles,
+			MaxBytes:   tomlConf.SendQueues.MaxBytes,
+		}
+		if err := queueConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid send queues configuration", err)
+		}
+		conf.SendQueues = &queueConf
+	}
+
+	// Parse optional MTU learning config
+	if tomlConf.MTU != nil {
+		mtuConf := cla.MTUConfig{Failures: tomlConf.MTU.Failures, Minimum: tomlConf.MTU.Minimum}
+		if mtuConf.Failures == 0 {
+			mtuConf.Failures = 3
+		}
+		if mtuConf.Minimum == 0 {
+			mtuConf.Minimum = 1024
+		}
+		if err := mtuConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid MTU learning configuration", err)
+		}
+		conf.MTU = &mtuConf
+	}
+
+	// Parse optional connection limits config
+	if tomlConf.ConnectionLimits != nil {
+		limits := cla.ConnectionLimits{
+			PerListener: tomlConf.ConnectionLimits.PerListener,
+			PerSource:   tomlConf.ConnectionLimits.PerSource,
+			Backoff:     100 * time.Millisecond,
+		}
+		if tomlConf.ConnectionLimits.Backoff != "" {
+			if limits.Backoff, err = time.ParseDuration(tomlConf.ConnectionLimits.Backoff); err != nil {
+				return config{}, NewConfigError("Error parsing connection limits' backoff", err)
+			}
+		}
+		if err := limits.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid connection limits configuration", err)
+		}
+		conf.ConnectionLimits = &limits
+	}
+
+	// Parse optional multihoming config
+	if tomlConf.Multihoming != nil {
+		if conf.Multihoming, err = parseMultihomingConfig(*tomlConf.Multihoming); err != nil {
+			return config{}, NewConfigError("Invalid multihoming configuration", err)
+		}
+	}
+
+	// Parse optional offload config
+	if tomlConf.Offload != nil {
+		depot, err := bpv7.NewEndpointID(tomlConf.Offload.Depot)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing offload depot", err)
+		}
+		offloadConf := processing.OffloadConfig{Depot: depot, Threshold: tomlConf.Offload.Threshold}
+		if offloadConf.Threshold == 0 {
+			offloadConf.Threshold = 0.9
+		}
+		if err := offloadConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid offload configuration", err)
+		}
+		if conf.Store.Capacity == 0 {
+			return config{}, NewConfigError("Offloading requires a store capacity", nil)
+		}
+		conf.Offload = &offloadConf
+	}
+
+	// Parse optional signature verification config
+	if tomlConf.Signatures != nil {
+		signatureConf := processing.SignatureConfig{
+			Role:     processing.SignatureVerifyDestination,
+			Required: tomlConf.Signatures.Required,
+		}
+		if tomlConf.Signatures.Verify != "" {
+			if signatureConf.Role, err = processing.SignatureRoleFromString(tomlConf.Signatures.Verify); err != nil {
+				return config{}, NewConfigError("Error parsing signature verification", err)
+			}
+		}
+		for _, verifier := range tomlConf.Signatures.Verifiers {
+			pattern, err := bpv7.NewEndpointPattern(verifier)
+			if err != nil {
+				return config{}, NewConfigError("Error parsing signature verifier", err)
+			}
+			signatureConf.Verifiers = append(signatureConf.Verifiers, pattern)
+		}
+		if signatureConf.Role == processing.SignatureVerifyVerifiers && len(signatureConf.Verifiers) == 0 {
+			return config{}, NewConfigError("Signature verification by verifiers requires verifiers", nil)
+		}
+		conf.Signatures = &signatureConf
+	}
+
+	// Parse optional previous node rules
+	for _, rule := range tomlConf.PreviousNode {
+		policy, err := processing.PreviousNodePolicyFromString(rule.Policy)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing previous node policy", err)
+		}
+		previousNodeRule := processing.PreviousNodeRule{Policy: policy}
+		for _, destination := range rule.Destinations {
+			pattern, err := bpv7.NewEndpointPattern(destination)
+			if err != nil {
+				return config{}, NewConfigError("Error parsing previous node rule's destination", err)
+			}
+			previousNodeRule.Destinations = append(previousNodeRule.Destinations, pattern)
+		}
+		conf.PreviousNode = append(conf.PreviousNode, previousNodeRule)
+	}
+
+	// Parse optional gateway rules
+	for _, rule := range tomlConf.Gateway {
+		from, err := bpv7.NewEndpointID(rule.From)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing gateway rule's from endpoint", err)
+		}
+		to, err := bpv7.NewEndpointID(rule.To)
+		if err != nil {
+			return config{}, NewConfigError("Error parsing gateway rule's to endpoint", err)
+		}
+		gatewayRule := processing.GatewayRule{From: from, To: to, Node: rule.Node}
+		if err := gatewayRule.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid gateway rule", err)
+		}
+		conf.Gateway = append(conf.Gateway, gatewayRule)
+	}
+
+	// Parse optional suspension config
+	if tomlConf.Suspension != nil {
+		if conf.SuspensionIdle, err = time.ParseDuration(tomlConf.Suspension.Idle); err != nil {
+			return config{}, NewConfigError("Error parsing suspension idle duration", err)
+		}
+		if conf.SuspensionIdle <= 0 {
+			return config{}, NewConfigError("Suspension idle duration must be positive", nil)
+		}
+	}
+
+	// Parse optional reputation config
+	if tomlConf.Reputation != nil {
+		reputationConf := reputation.Config{QuarantineThreshold: tomlConf.Reputation.QuarantineThreshold}
+		if tomlConf.Reputation.QuarantineDuration != "" {
+			if reputationConf.QuarantineDuration, err = time.ParseDuration(tomlConf.Reputation.QuarantineDuration); err != nil {
+				return config{}, NewConfigError("Error parsing reputation quarantine duration", err)
+			}
+		}
+		if err := reputationConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid reputation configuration", err)
+		}
+		conf.Reputation = &reputationConf
+	}
+
+	// Parse optional identity config
+	if tomlConf.Identity != nil {
+		cert, err := identity.LoadCertificate(tomlConf.Identity.Certificate, tomlConf.Identity.Key)
+		if err != nil {
+			return config{}, NewConfigError("Error loading identity certificate", err)
+		}
+		if certNodeID, _ := identity.NodeID(cert.Leaf); !certNodeID.SameNode(conf.NodeID) {
+			return config{}, NewConfigError(fmt.Sprintf("Identity certificate binds %v instead of the node ID", certNodeID), nil)
+		}
+		if remaining := time.Until(cert.Leaf.NotAfter); remaining <= 0 {
+			return config{}, NewConfigError("Identity certificate expired", nil)
+		} else if remaining < identityExpiryWarning {
+			log.WithField("expires", cert.Leaf.NotAfter).Warn("Identity certificate expires soon, rotate it")
+		}
+		conf.Certificate = &cert
+	}
+
+	// Parse optional journal config
+	if tomlConf.Journal != nil {
+		if tomlConf.Journal.Path == "" {
+			return config{}, NewConfigError("Journal path must be set", nil)
+		}
+		conf.JournalPath = tomlConf.Journal.Path
+	}
+
+	// Parse optional diagnostics config
+	if tomlConf.Diagnostics != nil {
+		if tomlConf.Diagnostics.Address == "" {
+			return config{}, NewConfigError("Diagnostics address must be set", nil)
+		}
+		conf.DiagnosticsAddress = tomlConf.Diagnostics.Address
+	}
+
+	// Parse optional metrics config
+	if tomlConf.Metrics != nil {
+		metricsConf := metrics.Config{
+			Exporter: metrics.ExporterType(tomlConf.Metrics.Exporter),
+			Address:  tomlConf.Metrics.Address,
+			Token:    tomlConf.Metrics.Token,
+			Prefix:   "dtn7",
+			Interval: time.Minute,
+		}
+		if tomlConf.Metrics.Prefix != nil {
+			metricsConf.Prefix = *tomlConf.Metrics.Prefix
+		}
+		if tomlConf.Metrics.Interval != "" {
+			if metricsConf.Interval, err = time.ParseDuration(tomlConf.Metrics.Interval); err != nil {
+				return config{}, NewConfigError("Error parsing metrics interval", err)
+			}
+		}
+		if err := metricsConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid metrics config", err)
+		}
+		conf.Metrics = &metricsConf
+	}
+
+	// Parse optional naming config, after the identity config providing the key to publish names
+	if tomlConf.Naming != nil {
+		namingConf := naming.Config{Lifetime: defaultNameLifetime, FloodHops: tomlConf.Naming.FloodHops}
+		if tomlConf.Naming.Lifetime != "" {
+			if namingConf.Lifetime, err = time.ParseDuration(tomlConf.Naming.Lifetime); err != nil {
+				return config{}, NewConfigError("Error parsing naming lifetime", err)
+			}
+		}
+		if namingConf.Static, err = parseNames(tomlConf.Naming.Static); err != nil {
+			return config{}, NewConfigError("Error parsing static names", err)
+		}
+		if namingConf.Published, err = parseNames(tomlConf.Naming.Publish); err != nil {
+			return config{}, NewConfigError("Error parsing published names", err)
+		}
+		if len(namingConf.Published) > 0 {
+			if conf.Certificate == nil {
+				return config{}, NewConfigError("Publishing names requires an identity", nil)
+			}
+			namingConf.Key = conf.Certificate.PrivateKey.(ed25519.PrivateKey)
+		}
+		if err := namingConf.CheckValid(); err != nil {
+			return config{}, NewConfigError("Invalid naming configuration", err)
+		}
+		conf.Naming = &namingConf
 	}
-	conf.Cron.Dispatch = dispatchTime
 
 	return conf, nil
 }
+
+// parseNames creates the naming.Records of the configured names.
+func parseNames(names map[string]nameTomlConfig) (records []naming.Record, err error) {
+	for name, nameConf := range names {
+		rec := naming.Record{Name: name, Address: nameConf.Address}
+		if rec.Endpoint, err = bpv7.NewEndpointID(nameConf.Endpoint); err != nil {
+			return nil, fmt.Errorf("endpoint of %q: %v", name, err)
+		}
+		if nameConf.Type != "" {
+			if rec.Type, err = cla.TypeFromString(nameConf.Type); err != nil {
+				return nil, fmt.Errorf("CLA type of %q: %v", name, err)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
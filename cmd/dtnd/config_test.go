@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// parseString parses a configuration given as a string.
+func parseString(t *testing.T, content string) (config, error) {
+	filename := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return parse(filename)
+}
+
+// minimalConfig has only the mandatory fields, excluding any dispatch configuration.
+const minimalConfig = `
+node_id = "dtn://alice/"
+log_level = "Info"
+
+[Store]
+path = "/tmp/dtn_store"
+
+[Routing]
+algorithm = "epidemic"
+`
+
+func TestParseDispatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		interval    time.Duration
+		onNewBundle bool
+		onNewPeer   bool
+		valid       bool
+	}{
+		{"defaults", "", defaultDispatchInterval, false, true, true},
+		{"dispatch block", "[Dispatch]\ninterval = \"30s\"\non_new_bundle = true\non_new_peer = false\n", 30 * time.Second, true, false, true},
+		{"legacy cron block", "[Cron]\ndispatch = \"5s\"\n", 5 * time.Second, false, true, true},
+		{"both blocks", "[Cron]\ndispatch = \"5s\"\n[Dispatch]\ninterval = \"30s\"\n", 0, false, false, false},
+		{"invalid interval", "[Dispatch]\ninterval = \"soon\"\n", 0, false, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf, err := parseString(t, minimalConfig+test.content)
+			if !test.valid {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", conf.Dispatch)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if conf.Dispatch.Interval != test.interval ||
+				conf.Dispatch.OnNewBundle != test.onNewBundle || conf.Dispatch.OnNewPeer != test.onNewPeer {
+				t.Fatalf("Unexpected dispatch configuration %+v", conf.Dispatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/sirupsen/logrus"
+)
+
+// Demo mode starts a node without any configuration file, e.g., to let two laptops on the same network exchange
+// bundles with a single command each:
+//
+//	dtnd -demo
+//
+// The node gets a random EID, announces its MTCP listener via IPv4 multicast discovery, and forwards bundles
+// epidemically to all discovered peers. Its store lives in a temporary directory, which is removed after the node was
+// stopped by a signal and its store was closed. Applications use the REST agent on localhost.
+
+const (
+	// demoListenAddress is the demo node's MTCP listener, announced to its neighbours.
+	demoListenAddress = ":35037"
+	// demoRESTAddress is the demo node's REST agent and admin API.
+	demoRESTAddress = "localhost:8080"
+)
+
+// demoNodeID creates a random EID, e.g., "dtn://demo-1a2b3c4d/".
+func demoNodeID() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return "dtn://demo-" + hex.EncodeToString(suffix) + "/", nil
+}
+
+// demoConfig derives the demo node's configuration, storing its bundles below the directory.
+func demoConfig(directory string) (tomlConfig, error) {
+	nodeID, err := demoNodeID()
+	if err != nil {
+		return tomlConfig{}, err
+	}
+
+	return tomlConfig{
+		NodeID:    nodeID,
+		LogLevel:  "Info",
+		Store:     storeTomlConfig{Path: filepath.Join(directory, "store")},
+		Routing:   tomlRoutingConfig{Algorithm: "epidemic"},
+		Listener:  []listenerTomlConfig{{Type: "MTCP", Address: demoListenAddress}},
+		Agents:    agentsConfig{REST: agentsRESTConfig{Address: demoRESTAddress}},
+		Discovery: discoveryTomlConfig{IPv4: true, Interval: "2s"},
+		Dispatch:  dispatchTomlConfig{Interval: "10s", OnNewBundle: true},
+	}, nil
+}
+
+// startDemo writes the demo node's configuration into a new temporary directory and returns its file name and the
+// directory, which must be removed by removeDemo after the node was stopped.
+func startDemo() (configPath, directory string, err error) {
+	directory, err = os.MkdirTemp("", "dtnd-demo")
+	if err != nil {
+		return "", "", err
+	}
+
+	demoConf, err := demoConfig(directory)
+	if err != nil {
+		_ = os.RemoveAll(directory)
+		return "", "", err
+	}
+
+	configPath = filepath.Join(directory, "demo.toml")
+	f, err := os.Create(configPath)
+	if err != nil {
+		_ = os.RemoveAll(directory)
+		return "", "", err
+	}
+	err = toml.NewEncoder(f).Encode(demoConf)
+	_ = f.Close()
+	if err != nil {
+		_ = os.RemoveAll(directory)
+		return "", "", err
+	}
+
+	log.WithFields(log.Fields{
+		"endpoint": demoConf.NodeID,
+		"listen":   demoListenAddress,
+		"rest":     "http://" + demoRESTAddress + "/rest",
+		"store":    demoConf.Store.Path,
+	}).Info("Starting demo node, discovering peers on the local network")
+	return configPath, directory, nil
+}
+
+// removeDemo removes the demo node's directory, including its store, which must have been closed before.
+func removeDemo(directory string) {
+	log.WithField("directory", directory).Info("Removing demo node's store")
+	if err := os.RemoveAll(directory); err != nil {
+		log.WithError(err).Warn("Error removing demo node's store")
+	}
+}
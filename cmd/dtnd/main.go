@@ -1,32 +1,73 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
-	"github.com/go-co-op/gocron/v2"
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dtn7/dtn7-go/pkg/admin"
 	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
-	"github.com/dtn7/dtn7-go/pkg/cla/dummy_cla"
 	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
 	"github.com/dtn7/dtn7-go/pkg/cla/quicl"
+	"github.com/dtn7/dtn7-go/pkg/contact_plan"
+	"github.com/dtn7/dtn7-go/pkg/diagnostics"
 	"github.com/dtn7/dtn7-go/pkg/discovery"
+	"github.com/dtn7/dtn7-go/pkg/fault_injection"
 	"github.com/dtn7/dtn7-go/pkg/id_keeper"
+	"github.com/dtn7/dtn7-go/pkg/journal"
+	"github.com/dtn7/dtn7-go/pkg/logging"
+	"github.com/dtn7/dtn7-go/pkg/metrics"
+	"github.com/dtn7/dtn7-go/pkg/naming"
+	"github.com/dtn7/dtn7-go/pkg/node_stats"
 	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/reputation"
 	"github.com/dtn7/dtn7-go/pkg/routing"
 	"github.com/dtn7/dtn7-go/pkg/store"
 )
 
+// nodeStatsInterval between two writes of the node statistics, bounding the statistics lost if the node is killed.
+const nodeStatsInterval = time.Minute
+
+// shutdownTimeout bounds the wait for the agent web server's open requests when the node is stopped by a signal.
+const shutdownTimeout = 5 * time.Second
+
 func main() {
+	if len(os.Args) == 3 && os.Args[1] == "-multi" {
+		if err := runMultiNode(os.Args[2]); err != nil {
+			log.WithField("error", err).Fatal("Multi-node error")
+		}
+		return
+	}
+
 	if len(os.Args) != 2 {
-		log.Fatalf("Usage: %s configuration.toml", os.Args[0])
+		log.Fatalf("Usage: %s configuration.toml\n       %s -multi nodes.toml\n       %s -demo",
+			os.Args[0], os.Args[0], os.Args[0])
 	}
 
-	conf, err := parse(os.Args[1])
+	configFile := os.Args[1]
+	if configFile == "-demo" || configFile == "--demo" {
+		var (
+			directory string
+			err       error
+		)
+		if configFile, directory, err = startDemo(); err != nil {
+			log.WithField("error", err).Fatal("Demo error")
+		}
+		// removed last, after all other deferred shutdowns, e.g., closing the store within the directory
+		defer removeDemo(directory)
+	}
+
+	conf, err := parse(configFile)
 	if err != nil {
 		log.WithField("error", err).Fatal("Config error")
 	}
@@ -36,101 +77,325 @@ func main() {
 		FullTimestamp:   true,
 		TimestampFormat: "2006-01-02T15:04:05.000",
 	})
+	if len(conf.LogSinks) > 0 {
+		sinks, err := logging.Setup(conf.LogSinks)
+		if err != nil {
+			log.WithError(err).Fatal("Error setting up log sinks")
+		}
+		defer sinks.Close()
+	}
+
+	applyProfile(conf.Profile)
+	if conf.Clockless {
+		bpv7.SetClockless(true)
+		log.Info("Operating without an accurate clock, bundle lifetimes are solely based on their age")
+	}
 
 	processing.SetOwnNodeID(conf.NodeID)
+	processing.SetOwnNodeAliases(conf.NodeAliases)
+	processing.SetPayloadChecksumPolicy(conf.PayloadChecksumPolicy)
+	processing.SetResendOnReconnect(conf.Routing.ResendOnReconnect)
+	processing.SetReplayWindow(conf.Routing.ReplayWindow)
+	processing.SetStatusReportHopLimit(conf.Routing.StatusReportHops)
+	processing.SetQuarantineAttempts(conf.Routing.QuarantineAttempts)
+	if conf.Offload != nil {
+		processing.SetOffload(*conf.Offload)
+	}
+	if conf.Signatures != nil {
+		processing.SetSignatureVerification(*conf.Signatures)
+	}
+	processing.SetPreviousNodeRules(conf.PreviousNode)
+	processing.SetGatewayRules(conf.Gateway)
+	processing.SetCongestionPolicy(conf.Store.CongestionPolicy)
+
+	if err = mtcp.SetTimeouts(conf.MTCP); err != nil {
+		log.WithField("error", err).Fatal("Error configuring MTCP timeouts")
+	}
+	mtcp.SetCompression(conf.MTCPCompression)
+	if conf.Certificate != nil {
+		quicl.SetCertificate(*conf.Certificate)
+	}
+	quicl.SetAdmission(processing.AdmitBundle)
 
 	// Setup Store
+	if err = store.SetShards(conf.Store.Shards); err != nil {
+		log.WithField("error", err).Fatal("Error configuring store shards")
+	}
+	if err = store.SetAlreadySentLimit(conf.Store.AlreadySentLimit, conf.Store.AlreadySentPruning); err != nil {
+		log.WithField("error", err).Fatal("Error configuring store already sent limit")
+	}
 	err = store.InitialiseStore(conf.NodeID, conf.Store.Path)
 	if err != nil {
 		log.WithField("error", err).Fatal("Error initialising store")
 	}
 	defer store.GetStoreSingleton().Close()
+	store.GetStoreSingleton().SetCapacity(conf.Store.Capacity)
+	store.GetStoreSingleton().SetCompactionInterval(conf.Store.CompactionInterval)
+	if conf.Store.CheckIntegrity {
+		if _, err = store.GetStoreSingleton().CheckIntegrity(); err != nil {
+			log.WithField("error", err).Fatal("Error checking store integrity")
+		}
+	}
 
-	// Setup IdKeeper
-	err = id_keeper.InitializeIdKeeper()
+	// Setup IdKeeper, persisting the sequence numbers next to the store, such that a restart never reuses bundle IDs
+	err = id_keeper.InitializePersistentIdKeeper(filepath.Join(conf.Store.Path, "sequence_numbers.json"))
 	if err != nil {
 		log.WithField("error", err).Fatal("Error initialising IdKeeper")
 	}
 
+	// Setup node statistics, summed up over all runs next to the store
+	err = node_stats.InitialiseRecorder(filepath.Join(conf.Store.Path, "node_stats.json"), nodeStatsInterval)
+	if err != nil {
+		log.WithError(err).Fatal("Error initialising node statistics")
+	}
+	defer node_stats.GetRecorderSingleton().Shutdown()
+
+	// Setup contact plan, which stays empty if no file is configured
+	plan := contact_plan.NewContactPlan(nil, nil)
+	if conf.Routing.ContactPlan != "" {
+		plan, err = contact_plan.LoadContactPlan(conf.Routing.ContactPlan)
+		if err != nil {
+			log.WithError(err).Fatal("Error loading contact plan")
+		}
+		log.WithFields(log.Fields{
+			"file":     conf.Routing.ContactPlan,
+			"contacts": len(plan.Contacts()),
+		}).Info("Loaded contact plan")
+	}
+	err = contact_plan.InitialiseContactPlan(plan)
+	if err != nil {
+		log.WithError(err).Fatal("Error initialising contact plan")
+	}
+
 	// Setup routing
-	err = routing.InitialiseAlgorithm(conf.Routing.Algorithm)
+	err = routing.InitialiseAlgorithm(conf.Routing.Algorithm, conf.NodeID, conf.Routing.Copies)
 	if err != nil {
 		log.WithField("error", err).Fatal("Error initialising routing algorithm")
 	}
+	if conf.Routing.Costs != nil {
+		routing.SetPeerCosts(conf.Routing.Costs)
+	}
+	if conf.Routing.Latency != nil {
+		routing.SetLatencyRouting(conf.Routing.Latency)
+	}
+	if conf.Routing.Aging != nil {
+		routing.SetAgingThrottle(conf.Routing.Aging)
+	}
 
 	// Setup CLAs
-	err = cla.InitialiseCLAManager(processing.ReceiveBundle, processing.NewPeer, routing.GetAlgorithmSingleton().NotifyPeerDisappeared)
+	err = cla.InitialiseCLAManager(processing.ReceiveBundle)
 	if err != nil {
 		log.WithField("error", err).Fatal("Error initialising CLAs")
 	}
 	defer cla.GetManagerSingleton().Shutdown()
+	cla.GetManagerSingleton().Events().Subscribe(handlePeerEvent, cla.PeerConnected, cla.PeerDisconnected, cla.PeerMoved)
+	cla.GetManagerSingleton().Events().Subscribe(func(ev cla.Event) {
+		node_stats.Forwarded(ev.Transmission.Bytes)
+	}, cla.TransferCompleted)
+	// In the small profile, received bundles are queued to the processing workers or slow down their CLA
+	cla.GetManagerSingleton().SetSynchronousReceive(conf.Profile == smallProfile)
 
-	for _, lstConf := range conf.Listener {
-		var listener cla.ConvergenceListener
-		switch lstConf.Type {
-		case cla.Dummy:
-			listener = dummy_cla.NewDummyListener(lstConf.Address)
-		case cla.MTCP:
-			srv := mtcp.NewMTCPServer(lstConf.Address, lstConf.EndpointId, cla.GetManagerSingleton().NotifyReceive)
-			listener = srv
-			cla.GetManagerSingleton().Register(srv)
-		case cla.QUICL:
-			listener = quicl.NewQUICListener(lstConf.Address, lstConf.EndpointId, cla.GetManagerSingleton().NotifyReceive)
-		default:
-			log.WithField("Type", lstConf.Type).Fatal("Not valid convergence listener type")
-		}
-
-		err = cla.GetManagerSingleton().RegisterListener(listener)
+	if conf.Shaping != nil {
+		cla.GetManagerSingleton().SetShaping(conf.Shaping)
+	}
+	if conf.SendQueues != nil {
+		cla.GetManagerSingleton().SetSendQueues(conf.SendQueues)
+	}
+	if conf.MTU != nil {
+		cla.GetManagerSingleton().SetMTULearning(conf.MTU)
+	}
+	if conf.ConnectionLimits != nil {
+		cla.GetManagerSingleton().SetConnectionLimits(conf.ConnectionLimits)
+	}
+	if conf.Multihoming != nil {
+		cla.GetManagerSingleton().SetMultihoming(conf.Multihoming)
+	}
+	if conf.SuspensionIdle > 0 {
+		cla.GetManagerSingleton().SetSuspension(conf.SuspensionIdle)
+	}
+	if conf.JournalPath != "" {
+		transmissionJournal, err := journal.Open(conf.JournalPath)
+		if err != nil {
+			log.WithError(err).Fatal("Error opening transmission journal")
+		}
+		defer transmissionJournal.Close()
+		cla.GetManagerSingleton().Events().Subscribe(func(ev cla.Event) {
+			transmissionJournal.Record(*ev.Transmission)
+		}, cla.TransferCompleted, cla.TransferFailed)
+	}
+	if conf.Roaming {
+		cla.GetManagerSingleton().SetRoaming(true)
+	}
+
+	// Setup optional fault injection
+	if conf.FaultInjection != nil {
+		err = fault_injection.InitialiseInjector(*conf.FaultInjection, cla.GetManagerSingleton().DisconnectRandomSender)
+		if err != nil {
+			log.WithError(err).Fatal("Error initialising fault injection")
+		}
+		defer fault_injection.GetInjectorSingleton().Shutdown()
+	}
+
+	// Setup optional link emulation
+	if conf.Emulation != nil {
+		cla.GetManagerSingleton().SetLinkEmulation(conf.Emulation.Wrap)
+		log.Warn("Link emulation is active")
+	}
+
+	// Setup optional peer reputation tracking
+	if conf.Reputation != nil {
+		if err = reputation.InitialiseTracker(*conf.Reputation); err != nil {
+			log.WithError(err).Fatal("Error initialising reputation tracking")
+		}
+		defer reputation.GetTrackerSingleton().Shutdown()
+	}
+
+	// Join the optional ad-hoc network before its listener is bound to its address
+	if conf.AdHoc != nil {
+		network, err := joinAdHocNetwork(*conf.AdHoc)
 		if err != nil {
+			log.WithError(err).Fatal("Error joining ad-hoc network")
+		}
+		defer leaveAdHocNetwork(network)
+	}
+
+	listeners := make([]*listenerSubsystem, 0, len(conf.Listener))
+	for _, lstConf := range conf.Listener {
+		listener := &listenerSubsystem{conf: lstConf}
+		if err = listener.Start(); err != nil {
 			log.WithFields(log.Fields{
 				"error": err,
 				"type":  lstConf,
 			}).Fatal("Error starting convergence listener")
 		}
+		listeners = append(listeners, listener)
 	}
 
 	// Setup neighbour discovery
-	err = discovery.InitialiseManager(conf.NodeID, conf.Discovery, 2*time.Second, true, false, cla.GetManagerSingleton().NotifyReceive)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"error": err,
-		}).Fatal("Error starting discovery manager")
+	discoverySys := discoverySubsystem{nodeID: conf.NodeID, conf: conf.Discovery}
+	if conf.Discovery.Enabled() {
+		err = discoverySys.Start()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Error starting discovery manager")
+		}
+		// the discovery might have been stopped by the admin API
+		defer func() {
+			if discovery.Initialised() {
+				discovery.GetManagerSingleton().Close()
+			}
+		}()
 	}
-	defer discovery.GetManagerSingleton().Close()
 
-	s, err := gocron.NewScheduler()
+	// Setup dispatch scheduler
+	err = processing.InitialiseDispatchScheduler(conf.Dispatch)
 	if err != nil {
-		log.WithError(err).Fatal("Error initializing cron")
-	}
-	_, err = s.NewJob(
-		gocron.DurationJob(
-			conf.Cron.Dispatch,
-		),
-		gocron.NewTask(
-			processing.DispatchPending,
-		),
-	)
-	if err != nil {
-		log.WithError(err).Fatal("Error initializing dispatching cronjob")
+		log.WithError(err).Fatal("Error initialising dispatch scheduler")
 	}
-	s.Start()
-	defer s.Shutdown()
+	defer processing.GetDispatchSchedulerSingleton().Shutdown()
 
 	// Setup application agents
-	err = application_agent.InitialiseApplicationAgentManager(processing.ReceiveBundle)
+	err = application_agent.InitialiseApplicationAgentManager(processing.SendBundle)
 	if err != nil {
 		log.WithField("error", err).Fatal("Error initialising Application Agent Manager")
 	}
 	defer application_agent.GetManagerSingleton().Shutdown()
+	application_agent.GetManagerSingleton().SetDeduplication(conf.DuplicateWindow, conf.DuplicatePolicy)
+	if conf.Spool != nil {
+		if err := application_agent.GetManagerSingleton().SetSpool(*conf.Spool); err != nil {
+			log.WithError(err).Fatal("Error opening application agent spool")
+		}
+	}
+
+	// Setup optional diagnostics listener, separated from the REST server
+	if conf.DiagnosticsAddress != "" {
+		diagnosticsServer := &http.Server{
+			Addr:              conf.DiagnosticsAddress,
+			Handler:           diagnostics.Handler(),
+			ReadHeaderTimeout: 60 * time.Second,
+		}
+		go func() {
+			log.WithField("address", conf.DiagnosticsAddress).Info("Serving diagnostics")
+			if err := diagnosticsServer.ListenAndServe(); err != nil {
+				log.WithError(err).Error("Error with diagnostics server")
+			}
+		}()
+	}
+
+	// Setup optional push of metrics, collected from the components initialised above
+	if conf.Metrics != nil {
+		pusher, err := metrics.NewPusher(conf.NodeID, *conf.Metrics)
+		if err != nil {
+			log.WithError(err).Fatal("Error starting metrics push")
+		}
+		defer pusher.Close()
+	}
 
 	// TODO: make this asynchronous
 	r := mux.NewRouter()
 	restRouter := r.PathPrefix("/rest").Subrouter()
 	restAgent := application_agent.NewRestAgent(restRouter)
+	restAgent.SetMailboxLimit(conf.Agents.REST.MailboxLimit)
 	err = application_agent.GetManagerSingleton().RegisterAgent(restAgent)
 	if err != nil {
 		log.WithError(err).Fatal("Error registering REST application agent")
 	}
+	restSys := newAgentSubsystem(restAgent)
+	restRouter.Use(restSys.middleware)
+
+	var exportSys *agentSubsystem
+	if conf.Export != nil {
+		exportAgent, err := application_agent.NewExportAgent(conf.Export.Directory, conf.Export.Endpoints)
+		if err != nil {
+			log.WithError(err).Fatal("Error creating export application agent")
+		}
+		if err := application_agent.GetManagerSingleton().RegisterAgent(exportAgent); err != nil {
+			log.WithError(err).Fatal("Error registering export application agent")
+		}
+		exportSys = newAgentSubsystem(exportAgent)
+	}
+
+	// Setup optional peer exchange, knowing all static peers from the beginning
+	if conf.Discovery.PeerExchange {
+		err = discovery.InitialisePeerExchange(conf.NodeID, conf.Discovery.PeerExchangeMaxAge, cla.GetManagerSingleton().NotifyReceive)
+		if err != nil {
+			log.WithError(err).Fatal("Error initialising peer exchange")
+		}
+		discovery.GetPeerExchangeSingleton().SetScopedFlood(conf.Discovery.PeerExchangeHops)
+		for _, peer := range conf.Peer {
+			discovery.GetPeerExchangeSingleton().AddPeer(discovery.PeerInfo{Endpoint: peer.Endpoint, Type: peer.Type, Address: peer.Address})
+		}
+	}
+
+	// Setup optional name resolution, allowing REST clients to address names
+	if conf.Naming != nil {
+		if err = naming.InitialiseResolver(conf.NodeID, *conf.Naming); err != nil {
+			log.WithError(err).Fatal("Error initialising name resolution")
+		}
+		application_agent.SetNameResolver(naming.GetResolverSingleton().ResolveEndpoint)
+	}
+
+	// Connect to statically configured peers, after the peer exchange might have been set up
+	go maintainStaticPeers(conf.NodeID, conf.Peer, conf.PeerLiveness)
+
+	if !conf.Agents.REST.DisableAdmin {
+		adminRouter := r.PathPrefix("/admin").Subrouter()
+		adminAPI := admin.NewAdminAPI(adminRouter, conf.AdminUsers)
+
+		// Subsystems to be stopped and started at runtime
+		for _, listener := range listeners {
+			adminAPI.RegisterSubsystem("cla/"+listener.address, listener)
+		}
+		adminAPI.RegisterSubsystem("discovery", discoverySys)
+		adminAPI.RegisterSubsystem("agent/rest", restSys)
+		if exportSys != nil {
+			adminAPI.RegisterSubsystem("agent/export", exportSys)
+		}
+
+		// Tools for dtn7-rs networks expect the peer table at the root
+		r.HandleFunc("/peers", adminAPI.PeerTableHandler()).Methods(http.MethodGet)
+	}
 
 	httpServer := &http.Server{
 		Addr:              conf.Agents.REST.Address,
@@ -138,8 +403,22 @@ func main() {
 		ReadHeaderTimeout: 60 * time.Second,
 	}
 
+	// A signal stops the web server, such that all deferred shutdowns run in order, e.g., closing the store
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.WithField("signal", sig).Info("Stopping node")
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.WithError(err).Warn("Error stopping agent web server")
+		}
+	}()
+
 	err = httpServer.ListenAndServe()
-	if err != nil {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.WithError(err).Fatal("Error with agent web server")
 	}
 }
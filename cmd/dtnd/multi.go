@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/sirupsen/logrus"
+)
+
+// Multi-node mode starts several independent nodes, defined in a single configuration file, from one binary.
+//
+// Each node is executed as a child process of this very binary, since the store, CLA manager, routing, and agents are
+// process-wide singletons. Thus, nodes cannot share one process and are not connected by in-process CLAs. Nodes get
+// their own EID, store directory, and REST agent. Linked nodes are connected via MTCP on their configured listen
+// addresses, which should be on the loopback interface; multicast discovery is disabled for all nodes.
+//
+//	log_level = "Info"
+//	store_path = "/tmp/dtn_multi"
+//
+//	[Routing]
+//	algorithm = "epidemic"
+//
+//	[Dispatch]
+//	interval = "10s"
+//
+//	[[Node]]
+//	name = "alice"
+//	node_id = "dtn://alice/"
+//	listen = "localhost:35001"
+//	rest_address = "localhost:8081"
+//	peers = ["bob"]
+//
+//	[[Node]]
+//	name = "bob"
+//	node_id = "dtn://bob/"
+//	listen = "localhost:35002"
+//	rest_address = "localhost:8082"
+
+// multiTomlConfig describes a multi-node configuration file. Log level, routing algorithm, and dispatch interval
+// default to "Info", "epidemic", and "10s" respectively.
+type multiTomlConfig struct {
+	LogLevel  string `toml:"log_level"`
+	StorePath string `toml:"store_path"`
+	Routing   tomlRoutingConfig
+	Dispatch  dispatchTomlConfig
+	Node      []multiNodeTomlConfig
+}
+
+// multiNodeTomlConfig describes a single node within a multi-node configuration file.
+type multiNodeTomlConfig struct {
+	Name        string
+	NodeID      string `toml:"node_id"`
+	Listen      string
+	RESTAddress string `toml:"rest_address"`
+	// Peers are the names of other nodes to connect to. Links are always bidirectional.
+	Peers []string
+}
+
+// nodeConfigs derives a regular dtnd configuration for each node, indexed by the node's name.
+func (mc multiTomlConfig) nodeConfigs() (map[string]tomlConfig, error) {
+	nodes := make(map[string]multiNodeTomlConfig, len(mc.Node))
+	for _, node := range mc.Node {
+		if node.Name == "" {
+			return nil, NewConfigError("Node without a name", nil)
+		}
+		if _, exists := nodes[node.Name]; exists {
+			return nil, NewConfigError(fmt.Sprintf("Node name %s is used multiple times", node.Name), nil)
+		}
+		nodes[node.Name] = node
+	}
+
+	links := make(map[string]map[string]bool, len(nodes))
+	for name := range nodes {
+		links[name] = make(map[string]bool)
+	}
+	for _, node := range mc.Node {
+		for _, peer := range node.Peers {
+			if _, exists := nodes[peer]; !exists {
+				return nil, NewConfigError(fmt.Sprintf("Node %s has unknown peer %s", node.Name, peer), nil)
+			}
+			links[node.Name][peer] = true
+			links[peer][node.Name] = true
+		}
+	}
+
+	if mc.LogLevel == "" {
+		mc.LogLevel = "Info"
+	}
+	if mc.Routing.Algorithm == "" {
+		mc.Routing.Algorithm = "epidemic"
+	}
+	if mc.Dispatch.Interval == "" {
+		mc.Dispatch.Interval = "10s"
+	}
+
+	configs := make(map[string]tomlConfig, len(nodes))
+	for name, node := range nodes {
+		conf := tomlConfig{
+			NodeID:    node.NodeID,
+			LogLevel:  mc.LogLevel,
+			Store:     storeTomlConfig{Path: filepath.Join(mc.StorePath, name)},
+			Routing:   mc.Routing,
+			Listener:  []listenerTomlConfig{{Type: "MTCP", Address: node.Listen}},
+			Agents:    agentsConfig{REST: agentsRESTConfig{Address: node.RESTAddress}},
+			Discovery: discoveryTomlConfig{IPv4: false, IPv6: false},
+			Dispatch:  mc.Dispatch,
+		}
+
+		for peer := range links[name] {
+			conf.Peer = append(conf.Peer, peerTomlConfig{
+				Type:     "MTCP",
+				Address:  nodes[peer].Listen,
+				Endpoint: nodes[peer].NodeID,
+			})
+		}
+
+		configs[name] = conf
+	}
+
+	return configs, nil
+}
+
+// prefixOutput copies each line from a reader to a writer, prefixed by the node's name, until the reader is closed.
+// Afterwards, done is closed.
+func prefixOutput(name string, r io.Reader, w io.Writer, done chan<- struct{}) {
+	defer close(done)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fmt.Fprintf(w, "[%s] %s\n", name, scanner.Text())
+	}
+	// drain the remainder, e.g., of an overlong line, not to block the node's output
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// runMultiNode starts all nodes of a multi-node configuration and blocks until either one of them terminates or
+// this process receives a signal to stop. Afterwards, all remaining nodes are stopped.
+func runMultiNode(filename string) error {
+	var multiConf multiTomlConfig
+	if _, err := toml.DecodeFile(filename, &multiConf); err != nil {
+		return NewConfigError("Error parsing toml", err)
+	}
+
+	nodeConfigs, err := multiConf.nodeConfigs()
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	configDir, err := os.MkdirTemp("", "dtnd-multi")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(configDir)
+
+	exited := make(chan string, len(nodeConfigs))
+	processes := make(map[string]*exec.Cmd, len(nodeConfigs))
+	defer func() {
+		for name, cmd := range processes {
+			log.WithField("node", name).Info("Stopping node")
+			_ = cmd.Process.Signal(syscall.SIGTERM)
+		}
+		for range processes {
+			<-exited
+		}
+	}()
+
+	for name, nodeConf := range nodeConfigs {
+		configPath := filepath.Join(configDir, name+".toml")
+		f, err := os.Create(configPath)
+		if err != nil {
+			return err
+		}
+		err = toml.NewEncoder(f).Encode(nodeConf)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+
+		// Wait copies the node's output into the pipe until the node has terminated; thus, the pipe is only closed
+		// afterwards, unlike one of StderrPipe, which must be read completely before Wait.
+		stderrReader, stderrWriter := io.Pipe()
+		cmd := exec.Command(executable, configPath)
+		cmd.Stderr = stderrWriter
+		cmd.Stdout = os.Stdout
+
+		if err := cmd.Start(); err != nil {
+			_ = stderrWriter.Close()
+			return err
+		}
+		log.WithFields(log.Fields{
+			"node":     name,
+			"endpoint": nodeConf.NodeID,
+			"pid":      cmd.Process.Pid,
+		}).Info("Started node")
+
+		processes[name] = cmd
+		outputDone := make(chan struct{})
+		go prefixOutput(name, stderrReader, os.Stderr, outputDone)
+		go func(name string, cmd *exec.Cmd) {
+			err := cmd.Wait()
+			_ = stderrWriter.Close()
+			<-outputDone
+
+			log.WithFields(log.Fields{
+				"node":  name,
+				"error": err,
+			}).Info("Node terminated")
+			exited <- name
+		}(name, cmd)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-signals:
+		log.WithField("signal", sig).Info("Shutting down all nodes")
+	case name := <-exited:
+		delete(processes, name)
+		return fmt.Errorf("node %s terminated unexpectedly", name)
+	}
+
+	return nil
+}
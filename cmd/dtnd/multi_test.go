@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMultiNodeConfigs(t *testing.T) {
+	mc := multiTomlConfig{
+		StorePath: "/tmp/dtn_multi",
+		Node: []multiNodeTomlConfig{
+			{Name: "alice", NodeID: "dtn://alice/", Listen: "localhost:35101", Peers: []string{"bob"}},
+			{Name: "bob", NodeID: "dtn://bob/", Listen: "localhost:35102", Peers: []string{"carol"}},
+			{Name: "carol", NodeID: "dtn://carol/", Listen: "localhost:35103"},
+		},
+	}
+
+	configs, err := mc.nodeConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// links are bidirectional
+	expectedPeers := map[string][]string{
+		"alice": {"dtn://bob/"},
+		"bob":   {"dtn://alice/", "dtn://carol/"},
+		"carol": {"dtn://bob/"},
+	}
+	for name, expected := range expectedPeers {
+		conf := configs[name]
+
+		var peers []string
+		for _, peer := range conf.Peer {
+			peers = append(peers, peer.Endpoint)
+		}
+		sort.Strings(peers)
+		if strings.Join(peers, ",") != strings.Join(expected, ",") {
+			t.Errorf("Node %s has peers %v, expected %v", name, peers, expected)
+		}
+
+		if conf.Store.Path != "/tmp/dtn_multi/"+name {
+			t.Errorf("Node %s has store path %s", name, conf.Store.Path)
+		}
+		if conf.LogLevel != "Info" || conf.Routing.Algorithm != "epidemic" || conf.Dispatch.Interval != "10s" {
+			t.Errorf("Node %s misses defaults: %+v", name, conf)
+		}
+	}
+}
+
+func TestMultiNodeConfigsInvalid(t *testing.T) {
+	tests := map[string][]multiNodeTomlConfig{
+		"without name":   {{NodeID: "dtn://alice/"}},
+		"duplicate name": {{Name: "alice"}, {Name: "alice"}},
+		"unknown peer":   {{Name: "alice", Peers: []string{"mallory"}}},
+	}
+
+	for name, nodes := range tests {
+		if _, err := (multiTomlConfig{Node: nodes}).nodeConfigs(); err == nil {
+			t.Errorf("Configuration %s did not fail", name)
+		}
+	}
+}
+
+func TestMultiNodePrefixOutput(t *testing.T) {
+	r, w := io.Pipe()
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go prefixOutput("alice", r, &out, done)
+
+	_, _ = io.WriteString(w, "first line\nsecond ")
+	_, _ = io.WriteString(w, "line\n")
+	_ = w.Close()
+	<-done
+
+	if expected := "[alice] first line\n[alice] second line\n"; out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/cla/quicl"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/discovery"
+	"github.com/dtn7/dtn7-go/pkg/naming"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+)
+
+// staticPeerProbeTimeout limits a single reachability probe of a static peer.
+const staticPeerProbeTimeout = 5 * time.Second
+
+// newPeerConvergence creates a new, not yet activated, CLA to connect to a statically configured peer.
+func newPeerConvergence(nodeID bpv7.EndpointID, peer peerConfig) (cla.Convergence, error) {
+	switch peer.Type {
+	case cla.MTCP:
+		return mtcp.NewMTCPClient(peer.Address, peer.Endpoint), nil
+	case cla.QUICL:
+		return quicl.NewDialerEndpoint(peer.Address, nodeID, cla.GetManagerSingleton().NotifyReceive), nil
+	default:
+		return nil, cla.NewUnsupportedCLATypeError(peer.Type)
+	}
+}
+
+// connectStaticPeers registers a CLA for each statically configured peer.
+//
+// The CLA manager ignores peers which are already connected or currently connecting, so this function might be
+// called repeatedly to re-establish lost connections.
+func connectStaticPeers(nodeID bpv7.EndpointID, peers []peerConfig) {
+	for _, peer := range peers {
+		conv, err := newPeerConvergence(nodeID, peer)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"peer":  peer.Endpoint,
+				"error": err,
+			}).Error("Cannot connect to static peer")
+			continue
+		}
+
+		cla.GetManagerSingleton().Register(conv)
+	}
+}
+
+// probeStaticPeer checks if a static peer is reachable. This is the case if a sender to it is registered. Otherwise,
+// MTCP peers are probed by dialing their TCP address, whose handshake's duration is recorded as the peer's latency.
+func probeStaticPeer(peer peerConfig) bool {
+	if cla.GetManagerSingleton().HasSenderFor(peer.Endpoint) {
+		return true
+	}
+	if peer.Type != cla.MTCP {
+		return false
+	}
+
+	start := clock.Now()
+	conn, err := net.DialTimeout("tcp", peer.Address, staticPeerProbeTimeout)
+	if err != nil {
+		return false
+	}
+	cla.GetManagerSingleton().RecordLatency(peer.Endpoint, clock.Now().Sub(start))
+	_ = conn.Close()
+	return true
+}
+
+// probeStaticPeers probes all static peers concurrently and marks those as down, which were unreachable for longer
+// than the configured duration. The lastReachable times are updated accordingly.
+func probeStaticPeers(peers []peerConfig, lastReachable []time.Time, downAfter time.Duration) {
+	var wg sync.WaitGroup
+	wg.Add(len(peers))
+	for i := range peers {
+		go func(i int) {
+			defer wg.Done()
+
+			if probeStaticPeer(peers[i]) {
+				lastReachable[i] = clock.Now()
+			}
+			up := clock.Now().Sub(lastReachable[i]) < downAfter
+			cla.GetManagerSingleton().MarkPeer(peers[i].Endpoint, peers[i].Address, up)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// maintainStaticPeers connects to all static peers, periodically probes their reachability, and reconnects lost or
+// failed connections. This function is meant to be run in its own goroutine.
+func maintainStaticPeers(nodeID bpv7.EndpointID, peers []peerConfig, liveness peerLivenessConfig) {
+	if len(peers) == 0 {
+		return
+	}
+
+	// all peers are given the benefit of the doubt at startup
+	lastReachable := make([]time.Time, len(peers))
+	for i := range lastReachable {
+		lastReachable[i] = clock.Now()
+	}
+
+	ticker := clock.NewTicker(liveness.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		probeStaticPeers(peers, lastReachable, liveness.DownAfter)
+		connectStaticPeers(nodeID, peers)
+		<-ticker.C()
+	}
+}
+
+// peerConnected is called by the CLA manager for each new peer. If enabled, the known peers and name records are sent
+// to it.
+func peerConnected(peerID bpv7.EndpointID) {
+	processing.NewPeer(peerID)
+
+	if discovery.PeerExchangeInitialised() {
+		discovery.GetPeerExchangeSingleton().PeerConnected(peerID)
+	}
+	if naming.ResolverInitialised() {
+		naming.GetResolverSingleton().PeerConnected(peerID)
+	}
+}
+
+// handlePeerEvent passes the CLA manager's peer events on. As a single subscriber, it receives them in order.
+func handlePeerEvent(ev cla.Event) {
+	switch ev.Type {
+	case cla.PeerConnected:
+		peerConnected(ev.Peer)
+	case cla.PeerDisconnected:
+		routing.GetAlgorithmSingleton().NotifyPeerDisappeared(ev.Peer)
+	case cla.PeerMoved:
+		peerMoved(ev.Peer, ev.PreviousAddress, ev.Address)
+	}
+}
+
+// peerMoved is called by the CLA manager for each peer which roamed to a new address. If enabled, the peer exchange's
+// entries are updated, passing the new address on to other nodes.
+func peerMoved(peerID bpv7.EndpointID, oldAddress, newAddress string) {
+	if discovery.PeerExchangeInitialised() {
+		discovery.GetPeerExchangeSingleton().PeerMoved(peerID, oldAddress, newAddress)
+	}
+}
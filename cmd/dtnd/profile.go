@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// profile tunes the node's resource usage.
+type profile string
+
+const (
+	// defaultProfile favours throughput.
+	defaultProfile profile = "default"
+	// smallProfile targets constrained devices, e.g., routers with 64 MB of RAM.
+	smallProfile profile = "small"
+)
+
+const (
+	// smallProfileWorkers bounds the goroutines processing bundles, see processing.SetWorkers.
+	smallProfileWorkers = 4
+	// smallProfileMemoryLimit is the Go runtime's soft memory limit, unless GOMEMLIMIT is set.
+	smallProfileMemoryLimit = 32 << 20
+)
+
+func parseProfile(name string) (profile, error) {
+	switch p := profile(name); p {
+	case "":
+		return defaultProfile, nil
+	case defaultProfile, smallProfile:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown profile %q", name)
+	}
+}
+
+// applyProfile configures the packages according to the profile. It must be called before the store is initialised.
+func applyProfile(p profile) {
+	if p != smallProfile {
+		return
+	}
+
+	store.SetSmallFootprint(true)
+	processing.SetWorkers(smallProfileWorkers)
+	if _, ok := os.LookupEnv("GOMEMLIMIT"); !ok {
+		debug.SetMemoryLimit(smallProfileMemoryLimit)
+	}
+
+	log.WithFields(log.Fields{
+		"workers":      smallProfileWorkers,
+		"memory limit": smallProfileMemoryLimit,
+	}).Info("Using small footprint profile")
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/dummy_cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/cla/mudp"
+	"github.com/dtn7/dtn7-go/pkg/cla/quicl"
+	"github.com/dtn7/dtn7-go/pkg/cla/tcpclv3"
+	"github.com/dtn7/dtn7-go/pkg/discovery"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+)
+
+// newListener creates a configured convergence listener. Listeners which are also CLAs, e.g., MTCP servers, are
+// registered at the CLA manager.
+func newListener(lstConf cla.ListenerConfig) (cla.ConvergenceListener, error) {
+	switch lstConf.Type {
+	case cla.Dummy:
+		return dummy_cla.NewDummyListener(lstConf.Address), nil
+	case cla.MTCP:
+		srv := mtcp.NewMTCPServer(lstConf.Address, lstConf.EndpointId, cla.GetManagerSingleton().NotifyReceive)
+		cla.GetManagerSingleton().Register(srv)
+		return srv, nil
+	case cla.QUICL:
+		return quicl.NewQUICListener(lstConf.Address, lstConf.EndpointId, cla.GetManagerSingleton().NotifyReceive), nil
+	case cla.MUDP:
+		endpoint := mudp.NewMulticastEndpoint(lstConf.Address, lstConf.EndpointId, cla.GetManagerSingleton().NotifyReceive)
+		cla.GetManagerSingleton().Register(endpoint)
+		return endpoint, nil
+	case cla.TCPCLv3:
+		srv := tcpclv3.NewTCPCLv3Server(lstConf.Address, lstConf.EndpointId, cla.GetManagerSingleton().NotifyReceive)
+		srv.SetAdmission(processing.AdmitBundle)
+		cla.GetManagerSingleton().Register(srv)
+		return srv, nil
+	default:
+		return nil, fmt.Errorf("not valid convergence listener type %v", lstConf.Type)
+	}
+}
+
+// listenerSubsystem is a configured convergence listener, which is created anew on each start, as a closed listener
+// cannot be started again.
+type listenerSubsystem struct {
+	conf cla.ListenerConfig
+	// address of the latest listener, identifying it at the CLA manager
+	address string
+}
+
+func (ls *listenerSubsystem) Start() error {
+	listener, err := newListener(ls.conf)
+	if err != nil {
+		return err
+	}
+	if err := cla.GetManagerSingleton().RegisterListener(listener); err != nil {
+		return err
+	}
+
+	ls.address = listener.Address()
+	return nil
+}
+
+func (ls *listenerSubsystem) Stop() error {
+	return cla.GetManagerSingleton().UnregisterListener(ls.address)
+}
+
+func (ls *listenerSubsystem) Running() bool {
+	for _, listener := range cla.GetManagerSingleton().GetListeners() {
+		if listener.Address() == ls.address {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverySubsystem is the neighbour discovery, initialised anew on each start.
+type discoverySubsystem struct {
+	nodeID bpv7.EndpointID
+	conf   discoveryConfig
+}
+
+func (ds discoverySubsystem) Start() error {
+	return discovery.InitialiseManager(
+		ds.nodeID,
+		ds.conf.Announcements, ds.conf.Interval,
+		ds.conf.IPv4, ds.conf.IPv6,
+		cla.GetManagerSingleton().NotifyReceive)
+}
+
+func (ds discoverySubsystem) Stop() error {
+	if !discovery.Initialised() {
+		return fmt.Errorf("discovery is not running")
+	}
+	discovery.GetManagerSingleton().Close()
+	return nil
+}
+
+func (ds discoverySubsystem) Running() bool {
+	return discovery.Initialised()
+}
+
+// agentSubsystem is an application agent, which is removed from the application agent manager while stopped. Thus,
+// no bundles are delivered to its endpoints while it is stopped.
+type agentSubsystem struct {
+	agent   application_agent.ApplicationAgent
+	running atomic.Bool
+}
+
+// newAgentSubsystem for an agent which is already registered.
+func newAgentSubsystem(agent application_agent.ApplicationAgent) *agentSubsystem {
+	as := &agentSubsystem{agent: agent}
+	as.running.Store(true)
+	return as
+}
+
+func (as *agentSubsystem) Start() error {
+	if err := application_agent.GetManagerSingleton().RegisterAgent(as.agent); err != nil {
+		return err
+	}
+	as.running.Store(true)
+	return nil
+}
+
+func (as *agentSubsystem) Stop() error {
+	if err := application_agent.GetManagerSingleton().UnregisterEndpoint(as.agent); err != nil {
+		return err
+	}
+	as.running.Store(false)
+	return nil
+}
+
+func (as *agentSubsystem) Running() bool {
+	return as.running.Load()
+}
+
+// middleware refuses all HTTP requests to the agent while it is stopped.
+func (as *agentSubsystem) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !as.Running() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if _, err := w.Write([]byte(`{"error":"agent is stopped"}` + "\n")); err != nil {
+				log.WithError(err).Warn("Failed to refuse request to a stopped agent")
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
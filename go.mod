@@ -4,11 +4,13 @@ go 1.22
 
 require (
 	github.com/BurntSushi/toml v1.3.2
+	github.com/dgraph-io/badger/v4 v4.2.0
 	github.com/dtn7/cboring v0.1.5
 	github.com/go-co-op/gocron/v2 v2.2.9
 	github.com/gorilla/mux v1.8.1
 	github.com/hashicorp/go-multierror v1.1.1
 	github.com/howeyc/crc16 v0.0.0-20171223171357-2b2a61e366a6
+	github.com/klauspost/compress v1.17.7
 	github.com/quic-go/quic-go v0.42.0
 	github.com/schollz/peerdiscovery v1.7.2
 	github.com/sirupsen/logrus v1.9.3
@@ -20,7 +22,6 @@ require (
 
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
-	github.com/dgraph-io/badger/v4 v4.2.0 // indirect
 	github.com/dgraph-io/ristretto v0.1.1 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/go-task/slim-sprig v0.0.0-20230315185526-52ccab3ef572 // indirect
@@ -34,7 +35,6 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/jonboulle/clockwork v0.4.0 // indirect
-	github.com/klauspost/compress v1.17.7 // indirect
 	github.com/onsi/ginkgo/v2 v2.17.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/robfig/cron/v3 v3.0.1 // indirect
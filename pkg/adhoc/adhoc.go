@@ -0,0 +1,155 @@
+// Package adhoc brings up a wireless ad-hoc network for opportunistic field deployments, e.g., of phones and laptops
+// without any infrastructure. Nodes joining the same network can discover each other by the neighbour discovery and
+// connect by a CLA bound to the interface's address.
+//
+// Two modes are supported: an IBSS, the classic 802.11 ad-hoc mode, and an 802.11s mesh. Both are configured by the
+// iw and ip tools on Linux and require the CAP_NET_ADMIN capability. Wi-Fi Direct's peer-to-peer groups are negotiated
+// by wpa_supplicant and are out of scope. Other platforms are not supported.
+package adhoc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Mode of a wireless ad-hoc network.
+type Mode string
+
+const (
+	// IBSS is an 802.11 independent basic service set, the classic ad-hoc mode.
+	IBSS Mode = "ibss"
+
+	// Mesh is an 802.11s mesh network, whose members also forward frames for each other.
+	Mesh Mode = "mesh"
+)
+
+// ModeFromString parses a Mode, case insensitive.
+func ModeFromString(mode string) (Mode, error) {
+	switch m := Mode(strings.ToLower(mode)); m {
+	case IBSS, Mesh:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid ad-hoc mode: %v", mode)
+	}
+}
+
+// Config of an ad-hoc network. All nodes must use the same Mode, SSID, Frequency and subnet.
+type Config struct {
+	// Interface is the wireless network interface, e.g., "wlan1". It is taken over for the network's lifetime.
+	Interface string
+	Mode      Mode
+	// SSID is the network's name, the mesh ID in the Mesh mode.
+	SSID string
+	// Frequency of the channel in MHz, e.g., 2412 for channel 1.
+	Frequency uint
+	// Address of this node within the network in CIDR notation, e.g., "10.42.0.7/24".
+	Address string
+}
+
+// CheckValid checks for an interface, a mode, an SSID of up to 32 bytes, a frequency and an address.
+func (conf Config) CheckValid() error {
+	if conf.Interface == "" {
+		return fmt.Errorf("ad-hoc interface must be set")
+	}
+	if _, err := ModeFromString(string(conf.Mode)); err != nil {
+		return err
+	}
+	if l := len(conf.SSID); l == 0 || l > 32 {
+		return fmt.Errorf("ad-hoc SSID must have 1 to 32 bytes, not %d", l)
+	}
+	if conf.Frequency == 0 {
+		return fmt.Errorf("ad-hoc frequency must be set")
+	}
+	if _, _, err := net.ParseCIDR(conf.Address); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IP is this node's address within the network, without its prefix length.
+func (conf Config) IP() net.IP {
+	ip, _, _ := net.ParseCIDR(conf.Address)
+	return ip
+}
+
+// upCommands to configure the interface and join the network.
+func (conf Config) upCommands() [][]string {
+	iwType, join := "ibss", []string{"iw", "dev", conf.Interface, "ibss", "join", conf.SSID, fmt.Sprint(conf.Frequency)}
+	if conf.Mode == Mesh {
+		iwType, join = "mp", []string{"iw", "dev", conf.Interface, "mesh", "join", conf.SSID, "freq", fmt.Sprint(conf.Frequency)}
+	}
+
+	return [][]string{
+		{"ip", "link", "set", "dev", conf.Interface, "down"},
+		{"iw", "dev", conf.Interface, "set", "type", iwType},
+		{"ip", "link", "set", "dev", conf.Interface, "up"},
+		join,
+		{"ip", "addr", "add", conf.Address, "dev", conf.Interface},
+	}
+}
+
+// downCommands to leave the network and return the interface to the managed mode.
+func (conf Config) downCommands() [][]string {
+	leave := []string{"iw", "dev", conf.Interface, "ibss", "leave"}
+	if conf.Mode == Mesh {
+		leave = []string{"iw", "dev", conf.Interface, "mesh", "leave"}
+	}
+
+	return [][]string{
+		{"ip", "addr", "del", conf.Address, "dev", conf.Interface},
+		leave,
+		{"ip", "link", "set", "dev", conf.Interface, "down"},
+		{"iw", "dev", conf.Interface, "set", "type", "managed"},
+	}
+}
+
+// Network is a joined ad-hoc network.
+type Network struct {
+	conf Config
+}
+
+// Up configures the interface and joins the ad-hoc network. If a step fails, the interface is reset.
+func Up(conf Config) (*Network, error) {
+	if err := conf.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"interface": conf.Interface,
+		"mode":      conf.Mode,
+		"ssid":      conf.SSID,
+		"frequency": conf.Frequency,
+		"address":   conf.Address,
+	}).Info("Joining ad-hoc network")
+
+	for _, cmd := range conf.upCommands() {
+		if err := runCommand(cmd[0], cmd[1:]...); err != nil {
+			network := &Network{conf: conf}
+			_ = network.Down()
+			return nil, err
+		}
+	}
+	return &Network{conf: conf}, nil
+}
+
+// Down leaves the ad-hoc network. All steps are tried, the first error is returned.
+func (network *Network) Down() (err error) {
+	log.WithField("interface", network.conf.Interface).Info("Leaving ad-hoc network")
+
+	for _, cmd := range network.conf.downCommands() {
+		if cmdErr := runCommand(cmd[0], cmd[1:]...); cmdErr != nil {
+			log.WithError(cmdErr).Debug("Resetting ad-hoc interface failed")
+			if err == nil {
+				err = cmdErr
+			}
+		}
+	}
+	return
+}
+
+func (network *Network) String() string {
+	return fmt.Sprintf("adhoc://%s/%s", network.conf.Interface, network.conf.SSID)
+}
@@ -0,0 +1,88 @@
+package adhoc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordCommands replaces runCommand by a recorder, failing for commands starting with the prefix, if set.
+func recordCommands(t *testing.T, failPrefix string) *[]string {
+	var commands []string
+
+	orig := runCommand
+	t.Cleanup(func() { runCommand = orig })
+	runCommand = func(name string, args ...string) error {
+		cmd := strings.Join(append([]string{name}, args...), " ")
+		commands = append(commands, cmd)
+		if failPrefix != "" && strings.HasPrefix(cmd, failPrefix) {
+			return fmt.Errorf("%s failed", cmd)
+		}
+		return nil
+	}
+	return &commands
+}
+
+func TestUpDown(t *testing.T) {
+	commands := recordCommands(t, "")
+
+	conf := Config{Interface: "wlan1", Mode: Mesh, SSID: "dtn", Frequency: 2412, Address: "10.42.0.7/24"}
+	network, err := Up(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.Down(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"ip link set dev wlan1 down",
+		"iw dev wlan1 set type mp",
+		"ip link set dev wlan1 up",
+		"iw dev wlan1 mesh join dtn freq 2412",
+		"ip addr add 10.42.0.7/24 dev wlan1",
+		"ip addr del 10.42.0.7/24 dev wlan1",
+		"iw dev wlan1 mesh leave",
+		"ip link set dev wlan1 down",
+		"iw dev wlan1 set type managed",
+	}
+	if !reflect.DeepEqual(*commands, expected) {
+		t.Fatalf("Commands %q instead of %q", *commands, expected)
+	}
+	if ip := conf.IP().String(); ip != "10.42.0.7" {
+		t.Fatalf("IP is %s", ip)
+	}
+}
+
+func TestUpFailure(t *testing.T) {
+	commands := recordCommands(t, "iw dev wlan1 ibss join")
+
+	conf := Config{Interface: "wlan1", Mode: IBSS, SSID: "dtn", Frequency: 2412, Address: "10.42.0.7/24"}
+	if _, err := Up(conf); err == nil {
+		t.Fatal("Failed join was not reported")
+	}
+	if last := (*commands)[len(*commands)-1]; last != "iw dev wlan1 set type managed" {
+		t.Fatalf("Interface was not reset, last command was %q", last)
+	}
+}
+
+func TestConfigCheckValid(t *testing.T) {
+	valid := Config{Interface: "wlan1", Mode: IBSS, SSID: "dtn", Frequency: 2412, Address: "10.42.0.7/24"}
+	if err := valid.CheckValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []Config{
+		{Mode: IBSS, SSID: "dtn", Frequency: 2412, Address: "10.42.0.7/24"},
+		{Interface: "wlan1", Mode: "wifi-direct", SSID: "dtn", Frequency: 2412, Address: "10.42.0.7/24"},
+		{Interface: "wlan1", Mode: IBSS, SSID: strings.Repeat("x", 33), Frequency: 2412, Address: "10.42.0.7/24"},
+		{Interface: "wlan1", Mode: IBSS, SSID: "dtn", Address: "10.42.0.7/24"},
+		{Interface: "wlan1", Mode: IBSS, SSID: "dtn", Frequency: 2412, Address: "10.42.0.7"},
+	}
+	for _, conf := range invalid {
+		if err := conf.CheckValid(); err == nil {
+			t.Errorf("Invalid config %+v was accepted", conf)
+		}
+	}
+}
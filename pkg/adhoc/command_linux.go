@@ -0,0 +1,18 @@
+//go:build linux
+// +build linux
+
+package adhoc
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runCommand executes a network configuration tool. Its output becomes part of the error on failure.
+var runCommand = func(name string, args ...string) error {
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
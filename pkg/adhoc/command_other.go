@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package adhoc
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// runCommand fails, as ad-hoc networks are only configured on Linux.
+var runCommand = func(string, ...string) error {
+	return fmt.Errorf("ad-hoc networks are not supported on %s", runtime.GOOS)
+}
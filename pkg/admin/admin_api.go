@@ -0,0 +1,215 @@
+// Package admin provides a REST-like HTTP API for inspecting and managing a running node.
+//
+// All endpoints exchange JSON objects, which are described in `admin_api_messages.go` by the types with the `Admin`
+// prefix in their names. Every response contains an "error" field, which is empty on success. The only exceptions are
+// the request bodies of injected and imported bundles, which are their serialisations, and the peer table, which
+// follows the format of dtn7-rs.
+//
+// If Users are given, each request must bear a user's token, e.g., "Authorization: Bearer 0a1b...". Each endpoint
+// requires a Role: inspecting the node requires ReadOnly, changing its behaviour Operator and managing its store or
+// the users Admin. Requests without a known token are answered by 401 Unauthorized, those of a user with an
+// insufficient role by 403 Forbidden.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// AdminAPI registers the administrative endpoints on a router.
+//
+//	// Inspect the dispatch scheduler, GET /dispatch
+//	// <- {"error":"","interval":"10s","jitter":"0s","on_new_bundle":false,"on_new_peer":true}
+//
+//	// Reconfigure the dispatch scheduler, POST /dispatch
+//	// -> {"interval":"30s","jitter":"5s","on_new_bundle":true,"on_new_peer":true}
+//	// <- {"error":"","interval":"30s","jitter":"5s","on_new_bundle":true,"on_new_peer":true}
+//
+//	// Start a dispatch sweep right now, POST /dispatch/trigger
+//	// <- {"error":""}
+//
+//	// Inspect the fault injection, only available if enabled in the configuration, GET /faults
+//	// <- {"error":"","drop_probability":0,"send_delay":"0s","store_failure_probability":0,"disconnect_interval":"0s"}
+//
+//	// Reconfigure the fault injection, POST /faults
+//	// -> {"drop_probability":0.1,"send_delay":"500ms","store_failure_probability":0.01,"disconnect_interval":"1m"}
+//	// <- {"error":"","drop_probability":0.1,"send_delay":"500ms","store_failure_probability":0.01,"disconnect_interval":"1m0s"}
+//
+//	// Fetch a stored bundle's metadata by its ID, GET /bundles?id=dtn%3A%2F%2Ffoo%2F-706871330477-0
+//	// The bundle itself, including its payload, is part of the response for GET /bundles?id=...&payload=true
+//	// <- {"error":"","bundle_id":"dtn://foo/-706871330477-0","source":"dtn://foo/","destination":"dtn://bar/",
+//	//     "report_to":"dtn://foo/","priority":"normal","constraints":["dispatch pending"],"dispatch":true,
+//	//     "attempts":0,"quarantined":false,"expires":"2022-05-27T13:11:17Z","sent_to":["dtn://foo/"],"metadata":null}
+//
+//	// Download a stored bundle's payload, GET /bundles/payload?id=dtn%3A%2F%2Ffoo%2F-706871330477-0
+//	// The response is the raw payload. An interrupted download is resumed by a Range header, e.g., "bytes=1048576-",
+//	// reading only the remainder from the store.
+//
+//	// Inspect a bundle's metadata annotations, GET /bundles/metadata?id=dtn%3A%2F%2Ffoo%2F-706871330477-0
+//	// <- {"error":"","bundle_id":"dtn://foo/-706871330477-0","metadata":{"copies":"4"}}
+//
+//	// Change a bundle's metadata annotations, POST /bundles/metadata
+//	// -> {"bundle_id":"dtn://foo/-706871330477-0","set":{"class":"bulk"},"delete":["copies"]}
+//	// <- {"error":"","bundle_id":"dtn://foo/-706871330477-0","metadata":{"class":"bulk"}}
+//
+//	// Inspect the peers a bundle was already sent to, bounded by the store's limit, zero if unbounded,
+//	// GET /bundles/sent?id=dtn%3A%2F%2Ffoo%2F-706871330477-0
+//	// <- {"error":"","bundle_id":"dtn://foo/-706871330477-0","sent_to":["dtn://foo/","dtn://other/"],"limit":64}
+//
+//	// Forward bundles again, forgetting earlier transmissions to the given or all peers, POST /bundles/resend
+//	// Omitting the bundle_id affects all stored bundles.
+//	// -> {"bundle_id":"dtn://foo/-706871330477-0","peers":["dtn://other/"]}
+//	// <- {"error":"","bundles":1}
+//
+//	// Inject a bundle created offline, e.g., by dtn-tool, as if it was received, POST /bundles/inject
+//	// The request body is the CBOR serialised bundle, not a JSON object.
+//	// <- {"error":"","bundle_id":"dtn://foo/-706871330477-0"}
+//
+//	// Import a serialised bundle, e.g., captured from another implementation, into the store, POST /bundles/import
+//	// The request body is either a BPv7 or a BPv6 bundle, the latter is converted. Unlike an injected bundle, it is
+//	// checked and stored before the response, and forwarded by the next dispatch sweep.
+//	// <- {"error":"","bundle_id":"dtn://foo/-706871330477-0"}
+//
+//	// Reclaim the store's disk space, e.g., of deleted bundles, and report it, POST /store/compact
+//	// Expired quarantined bundles are deleted beforehand and the Bloom filter of known bundles is rebuilt.
+//	// <- {"error":"","expired_quarantined":0,"orphaned_files":0,"rewritten_logs":1,"known_bundles":42,"reclaimed_bytes":1048576,"duration":"1.2s"}
+//
+//	// Copy the store to a new path for read-only inspection by other processes, POST /store/snapshot
+//	// The snapshot can be opened as a store.Replica, e.g., by "dtn-tool inspect".
+//	// -> {"path":"/var/lib/dtn/snapshot"}
+//	// <- {"error":"","path":"/var/lib/dtn/snapshot","bundles":42,"bytes":1048576,"duration":"80ms"}
+//
+//	// Inspect the reachability of probed static peers, GET /peers
+//	// <- {"error":"","peers":[{"endpoint":"dtn://other/","address":"10.0.0.2:35037","up":true,
+//	//      "since":"2024-04-12T09:21:33Z","last_probe":"2024-04-12T11:02:13Z"}]}
+//
+//	// List the connected and static peers like the /peers endpoint of dtn7-rs, keyed by their node name, GET /peers/table
+//	// The same table might be served at the root's /peers by PeerTableHandler, where tools for dtn7-rs expect it.
+//	// <- {"other":{"eid":[1,"//other/"],"addr":{"Ip":"10.0.0.2"},"con_type":"Static","period":null,
+//	//      "cla_list":[["mtcp",35037]],"services":{},"last_contact":1712919733,"fails":0}}
+//
+//	// Inspect the reputations of peers, only available if enabled in the configuration, GET /peers/reputation
+//	// <- {"error":"","peers":[{"endpoint":"dtn://other/","score":0.42,"accepted":8,"malformed":11,"refused":0,
+//	//      "duplicates":3,"quarantined_until":"2024-04-12T12:02:13Z"}]}
+//
+//	// Inspect the known name records, only available if enabled in the configuration, GET /names
+//	// A single name is resolved by GET /names?name=alice
+//	// <- {"error":"","records":[{"name":"alice","endpoint":"dtn://alice-laptop/chat","type":"QUICL",
+//	//      "address":"10.0.0.3:35037","static":false,"expires":"2024-04-13T09:21:33Z","public_key":"8a5f..."}]}
+//
+//	// Inspect the registrations of local applications, e.g., REST clients, GET /registrations
+//	// A single registration is inspected by GET /registrations?id=75be76e2-..., only registrations without any request
+//	// for some duration by GET /registrations?idle=1h
+//	// <- {"error":"","registrations":[{"id":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f","endpoint":"dtn://foo/bar",
+//	//      "agent":"rest","filtered":false,"pending":12,"registered":"2024-04-12T09:21:33Z",
+//	//      "last_seen":"2024-04-12T09:25:02Z","idle":"1h37m11s"}]}
+//
+//	// Remove a stale registration, whose undelivered bundles are dropped, DELETE /registrations?id=75be76e2-...
+//	// All registrations idle for some duration are removed by DELETE /registrations?idle=1h
+//	// <- {"error":"","registrations":[{"id":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f",...}]}
+//
+//	// Inspect the delivery ratios and latencies of bundles sent by local applications, per destination, as known
+//	// from status reports, and of bundles delivered to local endpoints, GET /stats/delivery
+//	// <- {"error":"","destinations":[{"destination":"dtn://other/inbox","sent":10,"delivered":8,"delivery_ratio":0.8,
+//	//      "latency":{"buckets":[{"upper_bound":"1s","count":2},...,{"upper_bound":"+Inf","count":0}],"count":8,
+//	//      "mean":"42s"}}],"endpoints":[{"endpoint":"dtn://foo/inbox","delivered":3,"latency":{...}}]}
+//
+//	// Inspect the achieved ratios of compressed MTCP connections, GET /stats/compression
+//	// <- {"error":"","sent":{"sessions":2,"uncompressed_bytes":1048576,"compressed_bytes":262144,"ratio":4},
+//	//      "received":{"sessions":0,"uncompressed_bytes":0,"compressed_bytes":0,"ratio":0}}
+//
+//	// Inspect the node's statistics, summed up over all its runs, and its current run, GET /stats/node
+//	// <- {"error":"","forwarded":1024,"forwarded_bytes":10485760,"delivered":42,"restarts":3,"uptime":"312h5m12s",
+//	//      "first_start":"2024-03-29T08:00:00Z","started":"2024-04-12T09:21:33Z","session":"1h37m11s"}
+//
+//	// List the subsystems which might be stopped and started at runtime, e.g., CLA listeners, GET /subsystems
+//	// <- {"error":"","subsystems":[{"name":"agent/rest","running":true},{"name":"cla/mtcp://:35037","running":true},
+//	//      {"name":"discovery","running":true}]}
+//
+//	// Stop a subsystem without restarting the node, POST /subsystems/stop
+//	// A stopped CLA listener accepts no further connections, while established ones are kept.
+//	// -> {"name":"discovery"}
+//	// <- {"error":"","name":"discovery","running":false}
+//
+//	// Start a stopped subsystem again, POST /subsystems/start
+//	// -> {"name":"discovery"}
+//	// <- {"error":"","name":"discovery","running":true}
+//
+//	// Inspect the latest CLA events, e.g., connected peers and failed transfers, and their counts per type, GET /events
+//	// <- {"error":"","events":[{"type":"transfer_failed","time":"2024-04-12T09:21:33Z","peer":"dtn://other/",
+//	//      "address":"10.0.0.2:35037","bundle":"dtn://foo/-706871330477-0","bytes":1024,
+//	//      "error":"bundle lifetime exceeded before its transmission"}],
+//	//      "counts":{"peer_connected":1,"transfer_failed":1,"transfer_started":1}}
+//
+//	// List the users and their roles, only available if authentication is enabled, GET /users
+//	// <- {"error":"","users":[{"name":"root","role":"admin"},{"name":"technician","role":"read-only"}]}
+//
+//	// Add a user or replace its role and token, POST /users
+//	// Omitting the token generates a random one. Changes are lost on a restart, unlike the configured users.
+//	// -> {"name":"technician","role":"read-only"}
+//	// <- {"error":"","name":"technician","role":"read-only","token":"5f0c..."}
+//
+//	// Remove a user, DELETE /users?name=technician
+//	// The last admin user can neither be removed nor downgraded, as nobody could manage the users afterwards.
+//	// <- {"error":""}
+type AdminAPI struct {
+	router *mux.Router
+	events *eventLog
+	// users are nil if authentication is disabled.
+	users *Users
+
+	subsystemsMutex sync.Mutex
+	subsystems      map[string]Subsystem
+}
+
+// NewAdminAPI creates a new AdminAPI and registers its handlers on the given router. All requests are authenticated
+// by the users, or none if they are nil.
+func NewAdminAPI(router *mux.Router, users *Users) (api *AdminAPI) {
+	api = &AdminAPI{router: router, events: newEventLog(), users: users, subsystems: make(map[string]Subsystem)}
+
+	api.router.HandleFunc("/dispatch", api.authorize(ReadOnly, api.handleDispatchGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/dispatch", api.authorize(Operator, api.handleDispatchSet)).Methods(http.MethodPost)
+	api.router.HandleFunc("/dispatch/trigger", api.authorize(Operator, api.handleDispatchTrigger)).Methods(http.MethodPost)
+	api.router.HandleFunc("/faults", api.authorize(ReadOnly, api.handleFaultsGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/faults", api.authorize(Operator, api.handleFaultsSet)).Methods(http.MethodPost)
+	api.router.HandleFunc("/bundles", api.authorize(ReadOnly, api.handleBundleGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/bundles/payload", api.authorize(ReadOnly, api.handlePayloadGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/bundles/metadata", api.authorize(ReadOnly, api.handleMetadataGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/bundles/metadata", api.authorize(Operator, api.handleMetadataSet)).Methods(http.MethodPost)
+	api.router.HandleFunc("/bundles/sent", api.authorize(ReadOnly, api.handleSentGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/bundles/resend", api.authorize(Operator, api.handleResend)).Methods(http.MethodPost)
+	api.router.HandleFunc("/bundles/inject", api.authorize(Operator, api.handleInject)).Methods(http.MethodPost)
+	api.router.HandleFunc("/bundles/import", api.authorize(Operator, api.handleImport)).Methods(http.MethodPost)
+	api.router.HandleFunc("/store/compact", api.authorize(Admin, api.handleCompact)).Methods(http.MethodPost)
+	api.router.HandleFunc("/store/snapshot", api.authorize(Admin, api.handleSnapshot)).Methods(http.MethodPost)
+	api.router.HandleFunc("/peers", api.authorize(ReadOnly, api.handlePeersGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/peers/table", api.authorize(ReadOnly, api.handlePeerTable)).Methods(http.MethodGet)
+	api.router.HandleFunc("/peers/reputation", api.authorize(ReadOnly, api.handlePeersReputation)).Methods(http.MethodGet)
+	api.router.HandleFunc("/names", api.authorize(ReadOnly, api.handleNamesGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/registrations", api.authorize(ReadOnly, api.handleRegistrationsGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/registrations", api.authorize(Operator, api.handleRegistrationsDelete)).Methods(http.MethodDelete)
+	api.router.HandleFunc("/stats/delivery", api.authorize(ReadOnly, api.handleDeliveryStats)).Methods(http.MethodGet)
+	api.router.HandleFunc("/stats/compression", api.authorize(ReadOnly, api.handleCompressionStats)).Methods(http.MethodGet)
+	api.router.HandleFunc("/stats/node", api.authorize(ReadOnly, api.handleNodeStats)).Methods(http.MethodGet)
+	api.router.HandleFunc("/subsystems", api.authorize(ReadOnly, api.handleSubsystemsGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/subsystems/stop", api.authorize(Operator, api.handleSubsystemStop)).Methods(http.MethodPost)
+	api.router.HandleFunc("/subsystems/start", api.authorize(Operator, api.handleSubsystemStart)).Methods(http.MethodPost)
+	api.router.HandleFunc("/events", api.authorize(ReadOnly, api.handleEvents)).Methods(http.MethodGet)
+	api.router.HandleFunc("/users", api.authorize(Admin, api.handleUsersGet)).Methods(http.MethodGet)
+	api.router.HandleFunc("/users", api.authorize(Admin, api.handleUsersSet)).Methods(http.MethodPost)
+	api.router.HandleFunc("/users", api.authorize(Admin, api.handleUsersDelete)).Methods(http.MethodDelete)
+
+	return api
+}
+
+// writeResponse serialises a response object as JSON.
+func writeResponse(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.WithError(err).Warn("Failed to write admin API response")
+	}
+}
@@ -0,0 +1,361 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// AdminErrorResponse describes a JSON response which only carries an error.
+type AdminErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// AdminDispatchConfig describes the dispatch scheduler's configuration, used for both GET and POST on /dispatch.
+type AdminDispatchConfig struct {
+	Interval    string `json:"interval"`
+	Jitter      string `json:"jitter"`
+	OnNewBundle bool   `json:"on_new_bundle"`
+	OnNewPeer   bool   `json:"on_new_peer"`
+}
+
+// AdminDispatchResponse describes a JSON response for /dispatch.
+type AdminDispatchResponse struct {
+	Error string `json:"error"`
+	AdminDispatchConfig
+}
+
+// AdminFaultConfig describes the fault injection's configuration, used for both GET and POST on /faults.
+type AdminFaultConfig struct {
+	DropProbability         float64 `json:"drop_probability"`
+	SendDelay               string  `json:"send_delay"`
+	StoreFailureProbability float64 `json:"store_failure_probability"`
+	DisconnectInterval      string  `json:"disconnect_interval"`
+}
+
+// AdminFaultResponse describes a JSON response for /faults.
+type AdminFaultResponse struct {
+	Error string `json:"error"`
+	AdminFaultConfig
+}
+
+// AdminBundleResponse describes a JSON response for GET on /bundles, a stored bundle's metadata. The Bundle itself,
+// including its payload, is only part of it if requested.
+type AdminBundleResponse struct {
+	Error       string            `json:"error"`
+	BundleID    string            `json:"bundle_id"`
+	Source      string            `json:"source"`
+	Destination string            `json:"destination"`
+	ReportTo    string            `json:"report_to"`
+	Priority    string            `json:"priority"`
+	Constraints []string          `json:"constraints"`
+	Dispatch    bool              `json:"dispatch"`
+	Attempts    uint              `json:"attempts"`
+	Quarantined bool              `json:"quarantined"`
+	Expires     time.Time         `json:"expires"`
+	SentTo      []string          `json:"sent_to"`
+	Metadata    map[string]string `json:"metadata"`
+	Bundle      *bpv7.Bundle      `json:"bundle,omitempty"`
+}
+
+// AdminMetadataRequest describes a JSON request to change a bundle's metadata by POST on /bundles/metadata.
+// Keys in Set are created or overwritten, keys in Delete are removed afterwards.
+type AdminMetadataRequest struct {
+	BundleID string            `json:"bundle_id"`
+	Set      map[string]string `json:"set"`
+	Delete   []string          `json:"delete"`
+}
+
+// AdminMetadataResponse describes a JSON response for /bundles/metadata.
+type AdminMetadataResponse struct {
+	Error    string            `json:"error"`
+	BundleID string            `json:"bundle_id"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// AdminSentResponse describes a JSON response for /bundles/sent. Limit bounds the SentTo list, zero if unbounded.
+type AdminSentResponse struct {
+	Error    string   `json:"error"`
+	BundleID string   `json:"bundle_id"`
+	SentTo   []string `json:"sent_to"`
+	Limit    int      `json:"limit"`
+}
+
+// AdminPeerLiveness describes a probed peer's reachability.
+type AdminPeerLiveness struct {
+	Endpoint  string    `json:"endpoint"`
+	Address   string    `json:"address"`
+	Up        bool      `json:"up"`
+	Since     time.Time `json:"since"`
+	LastProbe time.Time `json:"last_probe"`
+}
+
+// AdminPeersResponse describes a JSON response for /peers.
+type AdminPeersResponse struct {
+	Error string              `json:"error"`
+	Peers []AdminPeerLiveness `json:"peers"`
+}
+
+// AdminPeerReputation describes a peer's reputation, based on the bundles received from it. QuarantinedUntil is
+// empty unless the peer is quarantined.
+type AdminPeerReputation struct {
+	Endpoint         string  `json:"endpoint"`
+	Score            float64 `json:"score"`
+	Accepted         uint64  `json:"accepted"`
+	Malformed        uint64  `json:"malformed"`
+	Refused          uint64  `json:"refused"`
+	Duplicates       uint64  `json:"duplicates"`
+	QuarantinedUntil string  `json:"quarantined_until,omitempty"`
+}
+
+// AdminPeerReputationsResponse describes a JSON response for /peers/reputation.
+type AdminPeerReputationsResponse struct {
+	Error string                `json:"error"`
+	Peers []AdminPeerReputation `json:"peers"`
+}
+
+// AdminResendRequest describes a JSON request to forward bundles again by POST on /bundles/resend.
+// Peers are removed from the bundles' already sent lists, all peers if Peers is empty. Without a BundleID, every
+// stored bundle is affected.
+type AdminResendRequest struct {
+	BundleID string   `json:"bundle_id"`
+	Peers    []string `json:"peers"`
+}
+
+// AdminResendResponse describes a JSON response for /bundles/resend.
+type AdminResendResponse struct {
+	Error   string `json:"error"`
+	Bundles int    `json:"bundles"`
+}
+
+// AdminInjectResponse describes a JSON response for /bundles/inject, whose request body is a CBOR serialised bundle.
+type AdminInjectResponse struct {
+	Error    string `json:"error"`
+	BundleID string `json:"bundle_id"`
+}
+
+// AdminImportResponse describes a JSON response for /bundles/import, whose request body is a serialised bundle.
+type AdminImportResponse struct {
+	Error    string `json:"error"`
+	BundleID string `json:"bundle_id"`
+}
+
+// AdminCompactResponse describes a JSON response for /store/compact, see store.CompactionReport.
+type AdminCompactResponse struct {
+	Error              string `json:"error"`
+	ExpiredQuarantined int    `json:"expired_quarantined"`
+	OrphanedFiles      int    `json:"orphaned_files"`
+	RewrittenLogs      int    `json:"rewritten_logs"`
+	KnownBundles       int    `json:"known_bundles"`
+	ReclaimedBytes     int64  `json:"reclaimed_bytes"`
+	Duration           string `json:"duration"`
+}
+
+// AdminSnapshotRequest describes a JSON request to snapshot the store by POST on /store/snapshot.
+// The Path on the node's file system must not exist yet.
+type AdminSnapshotRequest struct {
+	Path string `json:"path"`
+}
+
+// AdminSnapshotResponse describes a JSON response for /store/snapshot, see store.SnapshotReport.
+type AdminSnapshotResponse struct {
+	Error    string `json:"error"`
+	Path     string `json:"path"`
+	Bundles  int    `json:"bundles"`
+	Bytes    int64  `json:"bytes"`
+	Duration string `json:"duration"`
+}
+
+// AdminLatencyBucket counts the latencies up to its UpperBound, exceeding the previous bucket's one.
+type AdminLatencyBucket struct {
+	UpperBound string `json:"upper_bound"`
+	Count      uint64 `json:"count"`
+}
+
+// AdminLatencyHistogram describes an application_agent.LatencyHistogram.
+type AdminLatencyHistogram struct {
+	Buckets []AdminLatencyBucket `json:"buckets"`
+	Count   uint64               `json:"count"`
+	Mean    string               `json:"mean"`
+}
+
+// AdminDestinationStats describes the application_agent.DestinationStats of bundles sent to a destination.
+type AdminDestinationStats struct {
+	Destination   string                `json:"destination"`
+	Sent          uint64                `json:"sent"`
+	Delivered     uint64                `json:"delivered"`
+	DeliveryRatio float64               `json:"delivery_ratio"`
+	Latency       AdminLatencyHistogram `json:"latency"`
+}
+
+// AdminEndpointStats describes the application_agent.EndpointStats of bundles delivered to a local endpoint.
+type AdminEndpointStats struct {
+	Endpoint  string                `json:"endpoint"`
+	Delivered uint64                `json:"delivered"`
+	Latency   AdminLatencyHistogram `json:"latency"`
+}
+
+// AdminDeliveryStatsResponse describes a JSON response for /stats/delivery.
+type AdminDeliveryStatsResponse struct {
+	Error        string                  `json:"error"`
+	Destinations []AdminDestinationStats `json:"destinations"`
+	Endpoints    []AdminEndpointStats    `json:"endpoints"`
+}
+
+// AdminCompressionStats describes the mtcp.CompressionStats of one direction.
+type AdminCompressionStats struct {
+	Sessions          uint64  `json:"sessions"`
+	UncompressedBytes uint64  `json:"uncompressed_bytes"`
+	CompressedBytes   uint64  `json:"compressed_bytes"`
+	Ratio             float64 `json:"ratio"`
+}
+
+// AdminCompressionStatsResponse describes a JSON response for /stats/compression.
+type AdminCompressionStatsResponse struct {
+	Error    string                `json:"error"`
+	Sent     AdminCompressionStats `json:"sent"`
+	Received AdminCompressionStats `json:"received"`
+}
+
+// AdminNodeStatsResponse describes a JSON response for /stats/node, see node_stats.Report. The Uptime sums up all runs,
+// the Session is the current run's.
+type AdminNodeStatsResponse struct {
+	Error          string    `json:"error"`
+	Forwarded      uint64    `json:"forwarded"`
+	ForwardedBytes uint64    `json:"forwarded_bytes"`
+	Delivered      uint64    `json:"delivered"`
+	Restarts       uint64    `json:"restarts"`
+	Uptime         string    `json:"uptime"`
+	FirstStart     time.Time `json:"first_start"`
+	Started        time.Time `json:"started"`
+	Session        string    `json:"session"`
+}
+
+// AdminNameRecord describes a naming.Record. Published records carry their hex encoded public key and expiry, static
+// ones are marked as such.
+type AdminNameRecord struct {
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint"`
+	Type      string `json:"type,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Static    bool   `json:"static"`
+	Expires   string `json:"expires,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// AdminRegistration describes an application_agent.Registration, including the duration since its client's latest
+// request.
+type AdminRegistration struct {
+	ID         string    `json:"id"`
+	Endpoint   string    `json:"endpoint"`
+	Agent      string    `json:"agent"`
+	Filtered   bool      `json:"filtered"`
+	Pending    int       `json:"pending"`
+	Registered time.Time `json:"registered"`
+	LastSeen   time.Time `json:"last_seen"`
+	Idle       string    `json:"idle"`
+}
+
+// AdminRegistrationsResponse describes a JSON response for /registrations, listing either the current or the removed
+// registrations.
+type AdminRegistrationsResponse struct {
+	Error         string              `json:"error"`
+	Registrations []AdminRegistration `json:"registrations"`
+}
+
+// AdminNamesResponse describes a JSON response for /names.
+type AdminNamesResponse struct {
+	Error   string            `json:"error"`
+	Records []AdminNameRecord `json:"records"`
+}
+
+// AdminEvent describes a cla.Event. Depending on its type, the peer, addresses, bundle and error are empty.
+type AdminEvent struct {
+	Type            string `json:"type"`
+	Time            string `json:"time"`
+	Peer            string `json:"peer,omitempty"`
+	Address         string `json:"address,omitempty"`
+	PreviousAddress string `json:"previous_address,omitempty"`
+	Bundle          string `json:"bundle,omitempty"`
+	Bytes           uint64 `json:"bytes,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// AdminEventsResponse describes a JSON response for /events.
+type AdminEventsResponse struct {
+	Error  string            `json:"error"`
+	Events []AdminEvent      `json:"events"`
+	Counts map[string]uint64 `json:"counts"`
+}
+
+// AdminSubsystem describes a Subsystem by its name and state.
+type AdminSubsystem struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+}
+
+// AdminSubsystemsResponse describes a JSON response for /subsystems.
+type AdminSubsystemsResponse struct {
+	Error      string           `json:"error"`
+	Subsystems []AdminSubsystem `json:"subsystems"`
+}
+
+// AdminSubsystemRequest describes a JSON request to stop or start a Subsystem by POST on /subsystems/stop or
+// /subsystems/start.
+type AdminSubsystemRequest struct {
+	Name string `json:"name"`
+}
+
+// AdminSubsystemResponse describes a JSON response for POST on /subsystems/stop or /subsystems/start, carrying the
+// Subsystem's state afterwards.
+type AdminSubsystemResponse struct {
+	Error string `json:"error"`
+	AdminSubsystem
+}
+
+// AdminUser describes a User of the admin API by its name and role.
+type AdminUser struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// AdminUsersResponse describes a JSON response for GET on /users.
+type AdminUsersResponse struct {
+	Error string      `json:"error"`
+	Users []AdminUser `json:"users"`
+}
+
+// AdminUserRequest describes a JSON request to add or replace a user by POST on /users. A random token is generated
+// if none is given.
+type AdminUserRequest struct {
+	AdminUser
+	Token string `json:"token,omitempty"`
+}
+
+// AdminUserResponse describes a JSON response for POST on /users, carrying the user's token.
+type AdminUserResponse struct {
+	Error string `json:"error"`
+	AdminUser
+	Token string `json:"token"`
+}
+
+// AdminPeerTableEntry describes a neighbour like a DtnPeer of dtn7-rs. GET on /peers/table responds with these entries
+// by their node name, as the /peers endpoint of dtn7-rs does. Unlike all other responses, it carries no error field.
+type AdminPeerTableEntry struct {
+	// Eid is the peer's node ID as serialised by dtn7-rs, e.g., [1,"//node/"] or [2,[23,0]].
+	Eid []interface{} `json:"eid"`
+	// Addr is the peer's address, either {"Ip":"10.0.0.2"} or {"Generic":"peer.example"}.
+	Addr map[string]string `json:"addr"`
+	// ConType is either "Static" for configured peers or "Dynamic" for discovered ones.
+	ConType string `json:"con_type"`
+	// Period of the peer's announcements, always null as it is not known.
+	Period *struct{} `json:"period"`
+	// ClaList contains the peer's CLAs as pairs of their name and port, e.g., [["mtcp",16162]].
+	ClaList [][]interface{} `json:"cla_list"`
+	// Services are always empty.
+	Services map[string]string `json:"services"`
+	// LastContact is the Unix time of the latest contact, now for connected peers.
+	LastContact int64 `json:"last_contact"`
+	// Fails is one for a static peer marked as down, zero otherwise.
+	Fails uint16 `json:"fails"`
+}
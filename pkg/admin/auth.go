@@ -0,0 +1,304 @@
+package admin
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Role grants access to the admin API's endpoints. Each role includes the permissions of the lower ones.
+type Role int
+
+const (
+	// ReadOnly users may inspect the node, e.g., field technicians.
+	ReadOnly Role = iota
+	// Operator users may additionally change the node's behaviour, e.g., reconfigure the dispatch or inject bundles.
+	Operator
+	// Admin users may additionally manage the store and the users.
+	Admin
+)
+
+func (role Role) String() string {
+	switch role {
+	case ReadOnly:
+		return "read-only"
+	case Operator:
+		return "operator"
+	case Admin:
+		return "admin"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRole parses a Role from its String representation.
+func ParseRole(s string) (Role, error) {
+	for _, role := range []Role{ReadOnly, Operator, Admin} {
+		if role.String() == s {
+			return role, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown role %q", s)
+}
+
+// User of the admin API, authenticated by a bearer token.
+type User struct {
+	Name string
+	Role Role
+}
+
+// ErrLastAdmin is returned when removing or downgrading the last Admin user, which would leave nobody able to manage
+// the users.
+var ErrLastAdmin = errors.New("the last admin user must not be removed or downgraded")
+
+// Users are the users of the admin API and their tokens, of which only hashes are kept.
+type Users struct {
+	mutex  sync.Mutex
+	users  map[string]User   // name -> User
+	tokens map[string]string // hashed token -> name
+}
+
+// NewUsers creates an empty set of Users.
+func NewUsers() *Users {
+	return &Users{
+		users:  make(map[string]User),
+		tokens: make(map[string]string),
+	}
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateToken creates a random token for a new user.
+func GenerateToken() (string, error) {
+	buff := make([]byte, 24)
+	if _, err := rand.Read(buff); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buff), nil
+}
+
+// Add a user or replace a user's role and token. Each token must belong to a single user. Downgrading the last Admin
+// user results in ErrLastAdmin.
+// This method is thread-safe.
+func (users *Users) Add(user User, token string) error {
+	if user.Name == "" {
+		return fmt.Errorf("user requires a name")
+	}
+	if user.Role < ReadOnly || user.Role > Admin {
+		return fmt.Errorf("user %q has an unknown role", user.Name)
+	}
+	if len(token) < 16 {
+		return fmt.Errorf("token of user %q is shorter than 16 characters", user.Name)
+	}
+
+	users.mutex.Lock()
+	defer users.mutex.Unlock()
+
+	hash := hashToken(token)
+	if name, ok := users.tokens[hash]; ok && name != user.Name {
+		return fmt.Errorf("token of user %q is already used by another user", user.Name)
+	}
+	if user.Role < Admin && users.lastAdmin(user.Name) {
+		return ErrLastAdmin
+	}
+
+	users.remove(user.Name)
+	users.users[user.Name] = user
+	users.tokens[hash] = user.Name
+	return nil
+}
+
+// Remove a user and its token. Removing the last Admin user results in ErrLastAdmin.
+// This method is thread-safe.
+func (users *Users) Remove(name string) error {
+	users.mutex.Lock()
+	defer users.mutex.Unlock()
+
+	if users.lastAdmin(name) {
+		return ErrLastAdmin
+	}
+	if !users.remove(name) {
+		return fmt.Errorf("unknown user %q", name)
+	}
+	return nil
+}
+
+// lastAdmin checks if the named user is the only Admin user while holding the mutex.
+func (users *Users) lastAdmin(name string) bool {
+	if users.users[name].Role != Admin {
+		return false
+	}
+	for _, user := range users.users {
+		if user.Role == Admin && user.Name != name {
+			return false
+		}
+	}
+	return true
+}
+
+// remove a user while holding the mutex.
+func (users *Users) remove(name string) bool {
+	if _, ok := users.users[name]; !ok {
+		return false
+	}
+	delete(users.users, name)
+	for hash, tokenName := range users.tokens {
+		if tokenName == name {
+			delete(users.tokens, hash)
+		}
+	}
+	return true
+}
+
+// List all users, ordered by their names.
+// This method is thread-safe.
+func (users *Users) List() []User {
+	users.mutex.Lock()
+	defer users.mutex.Unlock()
+
+	list := make([]User, 0, len(users.users))
+	for _, user := range users.users {
+		list = append(list, user)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// authenticate returns the user of a token.
+// This method is thread-safe.
+func (users *Users) authenticate(token string) (User, bool) {
+	users.mutex.Lock()
+	defer users.mutex.Unlock()
+
+	name, ok := users.tokens[hashToken(token)]
+	if !ok {
+		return User{}, false
+	}
+	return users.users[name], true
+}
+
+// writeError writes an AdminErrorResponse with an HTTP status code.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(AdminErrorResponse{Error: msg}); err != nil {
+		log.WithError(err).Warn("Failed to write admin API response")
+	}
+}
+
+// authorize wraps a handler, which is only called for a request bearing the token of a user of at least the role.
+// Without any Users, authentication is disabled and all requests are passed on.
+func (api *AdminAPI) authorize(role Role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.users == nil {
+			handler(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		user, ok := api.users.authenticate(token)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unknown bearer token")
+			return
+		}
+		if user.Role < role {
+			log.WithFields(log.Fields{
+				"user":     user.Name,
+				"role":     user.Role,
+				"required": role,
+				"endpoint": r.Method + " " + r.URL.Path,
+			}).Warn("Denied admin API request of insufficient role")
+			writeError(w, http.StatusForbidden, fmt.Sprintf("%s requires the %v role", r.URL.Path, role))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// errAuthDisabled is reported if the admin API has no users.
+const errAuthDisabled = "authentication is not enabled"
+
+// handleUsersGet lists all users and their roles, called by GET /users.
+func (api *AdminAPI) handleUsersGet(w http.ResponseWriter, _ *http.Request) {
+	if api.users == nil {
+		writeResponse(w, AdminUsersResponse{Error: errAuthDisabled})
+		return
+	}
+
+	response := AdminUsersResponse{Users: make([]AdminUser, 0)}
+	for _, user := range api.users.List() {
+		response.Users = append(response.Users, AdminUser{Name: user.Name, Role: user.Role.String()})
+	}
+	writeResponse(w, response)
+}
+
+// handleUsersSet adds or replaces a user, called by POST /users. Without a token, a random one is generated.
+func (api *AdminAPI) handleUsersSet(w http.ResponseWriter, r *http.Request) {
+	if api.users == nil {
+		writeResponse(w, AdminUserResponse{Error: errAuthDisabled})
+		return
+	}
+
+	var request AdminUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeResponse(w, AdminUserResponse{Error: err.Error()})
+		return
+	}
+	role, err := ParseRole(request.Role)
+	if err != nil {
+		writeResponse(w, AdminUserResponse{Error: err.Error()})
+		return
+	}
+
+	token := request.Token
+	if token == "" {
+		if token, err = GenerateToken(); err != nil {
+			writeResponse(w, AdminUserResponse{Error: err.Error()})
+			return
+		}
+	}
+	if err := api.users.Add(User{Name: request.Name, Role: role}, token); err != nil {
+		writeResponse(w, AdminUserResponse{Error: err.Error()})
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"user": request.Name,
+		"role": role,
+	}).Info("Admin API user was set")
+	writeResponse(w, AdminUserResponse{AdminUser: AdminUser{Name: request.Name, Role: role.String()}, Token: token})
+}
+
+// handleUsersDelete removes a user, called by DELETE /users.
+func (api *AdminAPI) handleUsersDelete(w http.ResponseWriter, r *http.Request) {
+	if api.users == nil {
+		writeResponse(w, AdminErrorResponse{Error: errAuthDisabled})
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if err := api.users.Remove(name); err != nil {
+		writeResponse(w, AdminErrorResponse{Error: err.Error()})
+		return
+	}
+
+	log.WithField("user", name).Info("Admin API user was removed")
+	writeResponse(w, AdminErrorResponse{})
+}
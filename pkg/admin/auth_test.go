@@ -0,0 +1,205 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	adminToken    = "admin-token-0123456789"
+	operatorToken = "operator-token-0123456789"
+	readerToken   = "reader-token-0123456789"
+)
+
+// newAuthTestRouter serves the user management and an endpoint for each role, e.g., /operator, by an AdminAPI of
+// the users. Unlike NewAdminAPI, it requires no other singletons.
+func newAuthTestRouter(users *Users) *mux.Router {
+	api := &AdminAPI{users: users}
+	router := mux.NewRouter()
+
+	for _, role := range []Role{ReadOnly, Operator, Admin} {
+		router.HandleFunc("/"+role.String(), api.authorize(role, func(w http.ResponseWriter, _ *http.Request) {
+			writeResponse(w, AdminErrorResponse{})
+		}))
+	}
+	router.HandleFunc("/users", api.authorize(Admin, api.handleUsersGet)).Methods(http.MethodGet)
+	router.HandleFunc("/users", api.authorize(Admin, api.handleUsersSet)).Methods(http.MethodPost)
+	router.HandleFunc("/users", api.authorize(Admin, api.handleUsersDelete)).Methods(http.MethodDelete)
+
+	return router
+}
+
+func newAuthTestUsers(t *testing.T) *Users {
+	t.Helper()
+
+	users := NewUsers()
+	for _, user := range []struct {
+		user  User
+		token string
+	}{
+		{User{Name: "admin", Role: Admin}, adminToken},
+		{User{Name: "operator", Role: Operator}, operatorToken},
+		{User{Name: "reader", Role: ReadOnly}, readerToken},
+	} {
+		if err := users.Add(user.user, user.token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return users
+}
+
+// authRequest performs a request with an optional bearer token and returns the status code and the decoded body.
+func authRequest(t *testing.T, router http.Handler, method, target, token string, body, response interface{}) int {
+	t.Helper()
+
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	request := httptest.NewRequest(method, target, &reader)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if response != nil {
+		if err := json.NewDecoder(recorder.Body).Decode(response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestAuthorize(t *testing.T) {
+	router := newAuthTestRouter(newAuthTestUsers(t))
+
+	tests := []struct {
+		name     string
+		endpoint string
+		token    string
+		status   int
+	}{
+		{"missing token", "/read-only", "", http.StatusUnauthorized},
+		{"unknown token", "/read-only", "unknown-token-0123456789", http.StatusUnauthorized},
+		{"reader reads", "/read-only", readerToken, http.StatusOK},
+		{"reader operates", "/operator", readerToken, http.StatusForbidden},
+		{"reader administrates", "/admin", readerToken, http.StatusForbidden},
+		{"operator reads", "/read-only", operatorToken, http.StatusOK},
+		{"operator operates", "/operator", operatorToken, http.StatusOK},
+		{"operator administrates", "/admin", operatorToken, http.StatusForbidden},
+		{"admin reads", "/read-only", adminToken, http.StatusOK},
+		{"admin operates", "/operator", adminToken, http.StatusOK},
+		{"admin administrates", "/admin", adminToken, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var response AdminErrorResponse
+			if status := authRequest(t, router, http.MethodGet, test.endpoint, test.token, nil, &response); status != test.status {
+				t.Fatalf("Expected status %d, got %d: %s", test.status, status, response.Error)
+			}
+		})
+	}
+}
+
+func TestAuthorizeWithoutUsers(t *testing.T) {
+	router := newAuthTestRouter(nil)
+
+	if status := authRequest(t, router, http.MethodGet, "/admin", "", nil, nil); status != http.StatusOK {
+		t.Fatalf("Request without users was not passed on, got status %d", status)
+	}
+
+	var response AdminUsersResponse
+	authRequest(t, router, http.MethodGet, "/users", "", nil, &response)
+	if response.Error != errAuthDisabled {
+		t.Fatalf("Expected error %q, got %q", errAuthDisabled, response.Error)
+	}
+}
+
+func TestUsersTokenReplacement(t *testing.T) {
+	router := newAuthTestRouter(newAuthTestUsers(t))
+
+	const newToken = "new-reader-token-0123456789"
+	var response AdminUserResponse
+	request := AdminUserRequest{AdminUser: AdminUser{Name: "reader", Role: Operator.String()}, Token: newToken}
+	if authRequest(t, router, http.MethodPost, "/users", adminToken, request, &response); response.Error != "" {
+		t.Fatal(response.Error)
+	}
+
+	if status := authRequest(t, router, http.MethodGet, "/read-only", readerToken, nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("Replaced token is still accepted, got status %d", status)
+	}
+	if status := authRequest(t, router, http.MethodGet, "/operator", newToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("New token of the new role is not accepted, got status %d", status)
+	}
+
+	// a token belongs to a single user
+	request = AdminUserRequest{AdminUser: AdminUser{Name: "other", Role: ReadOnly.String()}, Token: newToken}
+	if authRequest(t, router, http.MethodPost, "/users", adminToken, request, &response); response.Error == "" {
+		t.Fatal("Token of another user was accepted")
+	}
+
+	// a generated token
+	request = AdminUserRequest{AdminUser: AdminUser{Name: "other", Role: ReadOnly.String()}}
+	if authRequest(t, router, http.MethodPost, "/users", adminToken, request, &response); response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	if status := authRequest(t, router, http.MethodGet, "/read-only", response.Token, nil, nil); status != http.StatusOK {
+		t.Fatalf("Generated token is not accepted, got status %d", status)
+	}
+}
+
+func TestUsersLastAdmin(t *testing.T) {
+	users := newAuthTestUsers(t)
+	router := newAuthTestRouter(users)
+
+	var response AdminErrorResponse
+	if authRequest(t, router, http.MethodDelete, "/users?name=admin", adminToken, nil, &response); response.Error != ErrLastAdmin.Error() {
+		t.Fatalf("Expected removing the last admin to fail, got %q", response.Error)
+	}
+
+	var userResponse AdminUserResponse
+	request := AdminUserRequest{AdminUser: AdminUser{Name: "admin", Role: Operator.String()}, Token: adminToken}
+	if authRequest(t, router, http.MethodPost, "/users", adminToken, request, &userResponse); userResponse.Error != ErrLastAdmin.Error() {
+		t.Fatalf("Expected downgrading the last admin to fail, got %q", userResponse.Error)
+	}
+
+	// with a second admin, the first one may be downgraded and the second one is the last one
+	if err := users.Add(User{Name: "operator", Role: Admin}, operatorToken); err != nil {
+		t.Fatal(err)
+	}
+	if authRequest(t, router, http.MethodPost, "/users", adminToken, request, &userResponse); userResponse.Error != "" {
+		t.Fatal(userResponse.Error)
+	}
+	if err := users.Remove("operator"); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("Expected ErrLastAdmin, got %v", err)
+	}
+
+	if authRequest(t, router, http.MethodDelete, "/users?name=reader", operatorToken, nil, &response); response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	if authRequest(t, router, http.MethodDelete, "/users?name=reader", operatorToken, nil, &response); response.Error == "" {
+		t.Fatal("Removing an unknown user succeeded")
+	}
+
+	var listResponse AdminUsersResponse
+	authRequest(t, router, http.MethodGet, "/users", operatorToken, nil, &listResponse)
+	expected := []AdminUser{{Name: "admin", Role: "operator"}, {Name: "operator", Role: "admin"}}
+	if len(listResponse.Users) != len(expected) {
+		t.Fatalf("Expected users %v, got %v", expected, listResponse.Users)
+	}
+	for i, user := range expected {
+		if listResponse.Users[i] != user {
+			t.Fatalf("Expected users %v, got %v", expected, listResponse.Users)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv6"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// handleBundleGet returns a stored bundle's metadata, and the bundle itself if the payload parameter is true,
+// called by GET /bundles?id=...&payload=true
+func (api *AdminAPI) handleBundleGet(w http.ResponseWriter, r *http.Request) {
+	response := AdminBundleResponse{BundleID: r.URL.Query().Get("id")}
+	withBundle := r.URL.Query().Get("payload") == "true"
+
+	bd, err := store.GetStoreSingleton().LoadBundleByIDString(response.BundleID, withBundle)
+	if err != nil {
+		response.Error = err.Error()
+		writeResponse(w, response)
+		return
+	}
+
+	response.Source = bd.Source.String()
+	response.Destination = bd.Destination.String()
+	response.ReportTo = bd.ReportTo.String()
+	response.Priority = bd.Priority.String()
+	response.Constraints = make([]string, len(bd.RetentionConstraints))
+	for i, constraint := range bd.RetentionConstraints {
+		response.Constraints[i] = constraint.String()
+	}
+	response.Dispatch = bd.Dispatch
+	response.Attempts = bd.Attempts
+	response.Quarantined = bd.Quarantined
+	response.Expires = bd.Expires
+	response.SentTo = make([]string, 0)
+	for _, eid := range bd.GetAlreadySent() {
+		response.SentTo = append(response.SentTo, eid.String())
+	}
+	response.Metadata = bd.GetAllMetadata()
+	response.Bundle = bd.Bundle
+
+	writeResponse(w, response)
+}
+
+// handlePayloadGet serves a stored bundle's payload, called by GET /bundles/payload?id=...
+// Only the requested byte ranges are read from the store, thus an interrupted download of a large payload is resumed
+// by a Range request for its remainder.
+func (api *AdminAPI) handlePayloadGet(w http.ResponseWriter, r *http.Request) {
+	bundleID := r.URL.Query().Get("id")
+
+	bd, err := store.GetStoreSingleton().LoadBundleDescriptorByIDString(bundleID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	payload, err := store.GetStoreSingleton().OpenPayload(bd)
+	if err != nil {
+		log.WithError(err).WithField("bundle", bundleID).Warn("Failed to open payload via admin API")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer func() { _ = payload.Close() }()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", bd.Received, payload)
+}
+
+// handleMetadataGet returns a bundle's metadata annotations, called by GET /bundles/metadata?id=...
+func (api *AdminAPI) handleMetadataGet(w http.ResponseWriter, r *http.Request) {
+	response := AdminMetadataResponse{BundleID: r.URL.Query().Get("id")}
+
+	if bd, err := store.GetStoreSingleton().LoadBundleDescriptorByIDString(response.BundleID); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Metadata = bd.GetAllMetadata()
+	}
+
+	writeResponse(w, response)
+}
+
+// handleMetadataSet changes a bundle's metadata annotations, called by POST /bundles/metadata.
+func (api *AdminAPI) handleMetadataSet(w http.ResponseWriter, r *http.Request) {
+	var (
+		request  AdminMetadataRequest
+		response AdminMetadataResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&request); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse admin metadata request")
+		response.Error = jsonErr.Error()
+		writeResponse(w, response)
+		return
+	}
+	response.BundleID = request.BundleID
+
+	bd, err := store.GetStoreSingleton().LoadBundleDescriptorByIDString(request.BundleID)
+	if err != nil {
+		response.Error = err.Error()
+		writeResponse(w, response)
+		return
+	}
+
+	for key, value := range request.Set {
+		if err = bd.SetMetadata(key, value); err != nil {
+			break
+		}
+	}
+	for _, key := range request.Delete {
+		if err != nil {
+			break
+		}
+		err = bd.DeleteMetadata(key)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": request.BundleID,
+			"error":  err,
+		}).Warn("Failed to change bundle metadata")
+		response.Error = err.Error()
+	}
+
+	response.Metadata = bd.GetAllMetadata()
+	writeResponse(w, response)
+}
+
+// handleSentGet returns the peers a bundle was already sent to, called by GET /bundles/sent?id=...
+func (api *AdminAPI) handleSentGet(w http.ResponseWriter, r *http.Request) {
+	response := AdminSentResponse{BundleID: r.URL.Query().Get("id"), Limit: store.AlreadySentLimit()}
+
+	if bd, err := store.GetStoreSingleton().LoadBundleDescriptorByIDString(response.BundleID); err != nil {
+		response.Error = err.Error()
+	} else {
+		sent := bd.GetAlreadySent()
+		response.SentTo = make([]string, len(sent))
+		for i, eid := range sent {
+			response.SentTo[i] = eid.String()
+		}
+	}
+
+	writeResponse(w, response)
+}
+
+// handleResend forgets earlier transmissions of bundles and starts a dispatch sweep to forward them again,
+// called by POST /bundles/resend.
+func (api *AdminAPI) handleResend(w http.ResponseWriter, r *http.Request) {
+	var (
+		request  AdminResendRequest
+		response AdminResendResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&request); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse admin resend request")
+		response.Error = jsonErr.Error()
+		writeResponse(w, response)
+		return
+	}
+
+	peers := make([]bpv7.EndpointID, len(request.Peers))
+	for i, peer := range request.Peers {
+		eid, err := bpv7.NewEndpointID(peer)
+		if err != nil {
+			response.Error = err.Error()
+			writeResponse(w, response)
+			return
+		}
+		peers[i] = eid
+	}
+
+	var err error
+	if request.BundleID == "" {
+		response.Bundles, err = store.GetStoreSingleton().ForgetSent(peers...)
+	} else if bd, loadErr := store.GetStoreSingleton().LoadBundleDescriptorByIDString(request.BundleID); loadErr != nil {
+		err = loadErr
+	} else if changed, removeErr := bd.RemoveAlreadySent(peers...); removeErr != nil {
+		err = removeErr
+	} else if changed {
+		response.Bundles = 1
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": request.BundleID,
+			"peers":  request.Peers,
+			"error":  err,
+		}).Warn("Failed to forget already sent peers")
+		response.Error = err.Error()
+	}
+
+	if response.Bundles > 0 {
+		log.WithFields(log.Fields{
+			"bundle":  request.BundleID,
+			"peers":   request.Peers,
+			"bundles": response.Bundles,
+		}).Info("Bundles are forwarded again via admin API")
+		processing.GetDispatchSchedulerSingleton().Trigger()
+	}
+
+	writeResponse(w, response)
+}
+
+// maxInjectSize limits the size of a bundle injected through the admin API.
+const maxInjectSize = 256 << 20
+
+// handleInject passes a CBOR serialised bundle from the request body on as if it was received, called by
+// POST /bundles/inject.
+func (api *AdminAPI) handleInject(w http.ResponseWriter, r *http.Request) {
+	var response AdminInjectResponse
+
+	bndl, err := bpv7.ParseBundle(http.MaxBytesReader(w, r.Body, maxInjectSize))
+	if err == nil {
+		err = bndl.CheckValid()
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to parse bundle to be injected via admin API")
+		response.Error = err.Error()
+		writeResponse(w, response)
+		return
+	}
+
+	response.BundleID = bndl.ID().String()
+	log.WithField("bundle", bndl.ID()).Info("Bundle is injected via admin API")
+	processing.ReceiveBundle(&bndl)
+
+	writeResponse(w, response)
+}
+
+// parseImport parses a serialised bundle of either version, converting a BPv6 bundle to BPv7.
+func parseImport(data []byte) (bpv7.Bundle, error) {
+	if !bpv6.IsBPv6(data) {
+		return bpv7.ParseBundle(bytes.NewReader(data))
+	}
+
+	bndl6, err := bpv6.ParseBundle(bytes.NewReader(data))
+	if err != nil {
+		return bpv7.Bundle{}, err
+	}
+	return bndl6.ToBPv7()
+}
+
+// handleImport stores a serialised bundle from the request body, either BPv7 or BPv6, after the same checks as a
+// received bundle, called by POST /bundles/import.
+func (api *AdminAPI) handleImport(w http.ResponseWriter, r *http.Request) {
+	var response AdminImportResponse
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInjectSize))
+	if err != nil {
+		response.Error = err.Error()
+		writeResponse(w, response)
+		return
+	}
+
+	bndl, err := parseImport(data)
+	if err != nil {
+		log.WithError(err).Warn("Failed to parse bundle to be imported via admin API")
+		response.Error = err.Error()
+		writeResponse(w, response)
+		return
+	}
+
+	response.BundleID = bndl.ID().String()
+	if _, err := processing.ImportBundle(&bndl); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bndl.ID(),
+			"error":  err,
+		}).Warn("Failed to import bundle via admin API")
+		response.Error = err.Error()
+	}
+
+	writeResponse(w, response)
+}
@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+func TestPayloadGet(t *testing.T) {
+	if err := store.InitialiseStore(bpv7.MustNewEndpointID("dtn://node/"), t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.GetStoreSingleton().Close() })
+
+	payload := bytes.Repeat([]byte("0123456789"), 100)
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetStoreSingleton().InsertBundle(&bndl); err != nil {
+		t.Fatal(err)
+	}
+
+	api := &AdminAPI{}
+	get := func(id, byteRange string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/bundles/payload?id="+url.QueryEscape(id), nil)
+		if byteRange != "" {
+			request.Header.Set("Range", byteRange)
+		}
+		recorder := httptest.NewRecorder()
+		api.handlePayloadGet(recorder, request)
+		return recorder
+	}
+
+	tests := []struct {
+		name      string
+		byteRange string
+		status    int
+		body      []byte
+	}{
+		{"whole payload", "", http.StatusOK, payload},
+		{"resumed remainder", "bytes=600-", http.StatusPartialContent, payload[600:]},
+		{"range", "bytes=10-19", http.StatusPartialContent, payload[10:20]},
+		{"unsatisfiable range", "bytes=2000-", http.StatusRequestedRangeNotSatisfiable, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := get(bndl.ID().String(), test.byteRange)
+			if recorder.Code != test.status {
+				t.Fatalf("Expected status %d, got %d", test.status, recorder.Code)
+			}
+			if test.body != nil && !bytes.Equal(recorder.Body.Bytes(), test.body) {
+				t.Fatalf("Served %d bytes differ from the expected %d", recorder.Body.Len(), len(test.body))
+			}
+		})
+	}
+
+	if recorder := get("dtn://unknown/-0-0", ""); recorder.Code != http.StatusNotFound {
+		t.Fatalf("Expected status %d for an unknown bundle, got %d", http.StatusNotFound, recorder.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/processing"
+)
+
+// dispatchConfigToAdmin converts the scheduler's configuration into its JSON representation.
+func dispatchConfigToAdmin(conf processing.DispatchSchedulerConfig) AdminDispatchConfig {
+	return AdminDispatchConfig{
+		Interval:    conf.Interval.String(),
+		Jitter:      conf.Jitter.String(),
+		OnNewBundle: conf.OnNewBundle,
+		OnNewPeer:   conf.OnNewPeer,
+	}
+}
+
+// dispatchConfigFromAdmin parses the JSON representation of a scheduler configuration.
+func dispatchConfigFromAdmin(conf AdminDispatchConfig) (processing.DispatchSchedulerConfig, error) {
+	interval, err := time.ParseDuration(conf.Interval)
+	if err != nil {
+		return processing.DispatchSchedulerConfig{}, err
+	}
+
+	var jitter time.Duration
+	if conf.Jitter != "" {
+		if jitter, err = time.ParseDuration(conf.Jitter); err != nil {
+			return processing.DispatchSchedulerConfig{}, err
+		}
+	}
+
+	return processing.DispatchSchedulerConfig{
+		Interval:    interval,
+		Jitter:      jitter,
+		OnNewBundle: conf.OnNewBundle,
+		OnNewPeer:   conf.OnNewPeer,
+	}, nil
+}
+
+// handleDispatchGet returns the dispatch scheduler's configuration, called by GET /dispatch.
+func (api *AdminAPI) handleDispatchGet(w http.ResponseWriter, _ *http.Request) {
+	conf := processing.GetDispatchSchedulerSingleton().Config()
+	writeResponse(w, AdminDispatchResponse{AdminDispatchConfig: dispatchConfigToAdmin(conf)})
+}
+
+// handleDispatchSet reconfigures the dispatch scheduler, called by POST /dispatch.
+func (api *AdminAPI) handleDispatchSet(w http.ResponseWriter, r *http.Request) {
+	var (
+		request  AdminDispatchConfig
+		response AdminDispatchResponse
+	)
+
+	scheduler := processing.GetDispatchSchedulerSingleton()
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&request); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse admin dispatch request")
+		response.Error = jsonErr.Error()
+	} else if conf, confErr := dispatchConfigFromAdmin(request); confErr != nil {
+		response.Error = confErr.Error()
+	} else if reconfErr := scheduler.Reconfigure(conf); reconfErr != nil {
+		log.WithError(reconfErr).Warn("Failed to reconfigure dispatch scheduler")
+		response.Error = reconfErr.Error()
+	}
+
+	response.AdminDispatchConfig = dispatchConfigToAdmin(scheduler.Config())
+	writeResponse(w, response)
+}
+
+// handleDispatchTrigger starts an immediate dispatch sweep, called by POST /dispatch/trigger.
+func (api *AdminAPI) handleDispatchTrigger(w http.ResponseWriter, _ *http.Request) {
+	log.Info("Dispatch sweep triggered via admin API")
+	processing.GetDispatchSchedulerSingleton().Trigger()
+	writeResponse(w, AdminErrorResponse{})
+}
@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// recentEvents is the number of the latest CLA events kept for GET /events.
+const recentEvents = 100
+
+// eventLog keeps the latest CLA events and counts all events per type.
+type eventLog struct {
+	mutex  sync.Mutex
+	events []cla.Event
+	next   int
+	counts map[cla.EventType]uint64
+}
+
+// newEventLog creates an eventLog, subscribed to all events of the CLA manager.
+func newEventLog() *eventLog {
+	el := &eventLog{
+		events: make([]cla.Event, 0, recentEvents),
+		counts: make(map[cla.EventType]uint64),
+	}
+	cla.GetManagerSingleton().Events().Subscribe(el.record)
+	return el
+}
+
+// record an event, replacing the oldest one if the log is full.
+func (el *eventLog) record(event cla.Event) {
+	el.mutex.Lock()
+	defer el.mutex.Unlock()
+
+	el.counts[event.Type]++
+	if len(el.events) < recentEvents {
+		el.events = append(el.events, event)
+	} else {
+		el.events[el.next] = event
+	}
+	el.next = (el.next + 1) % recentEvents
+}
+
+// snapshot returns the recorded events, oldest first, and the counts per type.
+func (el *eventLog) snapshot() ([]cla.Event, map[cla.EventType]uint64) {
+	el.mutex.Lock()
+	defer el.mutex.Unlock()
+
+	events := make([]cla.Event, 0, len(el.events))
+	if len(el.events) == recentEvents {
+		events = append(events, el.events[el.next:]...)
+		events = append(events, el.events[:el.next]...)
+	} else {
+		events = append(events, el.events...)
+	}
+
+	counts := make(map[cla.EventType]uint64, len(el.counts))
+	for eventType, count := range el.counts {
+		counts[eventType] = count
+	}
+	return events, counts
+}
+
+// handleEvents returns the latest CLA events and the number of events per type, called by GET /events.
+func (api *AdminAPI) handleEvents(w http.ResponseWriter, _ *http.Request) {
+	events, counts := api.events.snapshot()
+
+	response := AdminEventsResponse{
+		Events: make([]AdminEvent, 0, len(events)),
+		Counts: make(map[string]uint64, len(counts)),
+	}
+	for _, event := range events {
+		adminEvent := AdminEvent{
+			Type:            event.Type.String(),
+			Time:            event.Time.UTC().Format(time.RFC3339),
+			Address:         event.Address,
+			PreviousAddress: event.PreviousAddress,
+		}
+		if event.Type != cla.ListenerError && event.Type != cla.ProcessingPanic {
+			adminEvent.Peer = event.Peer.String()
+		}
+		if event.Transmission != nil {
+			adminEvent.Bundle = event.Transmission.Bundle.String()
+			adminEvent.Bytes = event.Transmission.Bytes
+		}
+		if event.Err != nil {
+			adminEvent.Error = event.Err.Error()
+		}
+		response.Events = append(response.Events, adminEvent)
+	}
+	for eventType, count := range counts {
+		response.Counts[eventType.String()] = count
+	}
+
+	writeResponse(w, response)
+}
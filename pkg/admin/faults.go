@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/fault_injection"
+)
+
+// errFaultInjectionDisabled is reported if fault injection was not enabled in the configuration.
+const errFaultInjectionDisabled = "fault injection is not enabled"
+
+// faultConfigToAdmin converts the fault injection's configuration into its JSON representation.
+func faultConfigToAdmin(conf fault_injection.Config) AdminFaultConfig {
+	return AdminFaultConfig{
+		DropProbability:         conf.DropProbability,
+		SendDelay:               conf.SendDelay.String(),
+		StoreFailureProbability: conf.StoreFailureProbability,
+		DisconnectInterval:      conf.DisconnectInterval.String(),
+	}
+}
+
+// faultConfigFromAdmin parses the JSON representation of a fault injection configuration.
+func faultConfigFromAdmin(conf AdminFaultConfig) (faultConf fault_injection.Config, err error) {
+	faultConf.DropProbability = conf.DropProbability
+	faultConf.StoreFailureProbability = conf.StoreFailureProbability
+
+	if conf.SendDelay != "" {
+		if faultConf.SendDelay, err = time.ParseDuration(conf.SendDelay); err != nil {
+			return
+		}
+	}
+	if conf.DisconnectInterval != "" {
+		if faultConf.DisconnectInterval, err = time.ParseDuration(conf.DisconnectInterval); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// handleFaultsGet returns the fault injection's configuration, called by GET /faults.
+func (api *AdminAPI) handleFaultsGet(w http.ResponseWriter, _ *http.Request) {
+	if !fault_injection.Initialised() {
+		writeResponse(w, AdminFaultResponse{Error: errFaultInjectionDisabled})
+		return
+	}
+
+	conf := fault_injection.GetInjectorSingleton().Config()
+	writeResponse(w, AdminFaultResponse{AdminFaultConfig: faultConfigToAdmin(conf)})
+}
+
+// handleFaultsSet reconfigures the fault injection, called by POST /faults.
+func (api *AdminAPI) handleFaultsSet(w http.ResponseWriter, r *http.Request) {
+	var (
+		request  AdminFaultConfig
+		response AdminFaultResponse
+	)
+
+	if !fault_injection.Initialised() {
+		writeResponse(w, AdminFaultResponse{Error: errFaultInjectionDisabled})
+		return
+	}
+	injector := fault_injection.GetInjectorSingleton()
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&request); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse admin fault injection request")
+		response.Error = jsonErr.Error()
+	} else if conf, confErr := faultConfigFromAdmin(request); confErr != nil {
+		response.Error = confErr.Error()
+	} else if reconfErr := injector.Reconfigure(conf); reconfErr != nil {
+		log.WithError(reconfErr).Warn("Failed to reconfigure fault injection")
+		response.Error = reconfErr.Error()
+	}
+
+	response.AdminFaultConfig = faultConfigToAdmin(injector.Config())
+	writeResponse(w, response)
+}
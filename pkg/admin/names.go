@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/naming"
+)
+
+// errNamingDisabled is reported if name resolution was not enabled in the configuration.
+const errNamingDisabled = "name resolution is not enabled"
+
+// handleNamesGet returns all known name records or resolves a single name, called by GET /names.
+func (api *AdminAPI) handleNamesGet(w http.ResponseWriter, r *http.Request) {
+	if !naming.ResolverInitialised() {
+		writeResponse(w, AdminNamesResponse{Error: errNamingDisabled})
+		return
+	}
+
+	var records []naming.Record
+	if name := r.URL.Query().Get("name"); name != "" {
+		rec, ok := naming.GetResolverSingleton().Resolve(name)
+		if !ok {
+			writeResponse(w, AdminNamesResponse{Error: fmt.Sprintf("unknown name %q", name)})
+			return
+		}
+		records = []naming.Record{rec}
+	} else {
+		records = naming.GetResolverSingleton().Records()
+	}
+
+	response := AdminNamesResponse{Records: make([]AdminNameRecord, 0, len(records))}
+	for _, rec := range records {
+		adminRecord := AdminNameRecord{
+			Name:     rec.Name,
+			Endpoint: rec.Endpoint.String(),
+			Address:  rec.Address,
+			Static:   rec.Static(),
+		}
+		if rec.Address != "" {
+			adminRecord.Type = rec.Type.String()
+		}
+		if !rec.Static() {
+			adminRecord.Expires = rec.Expires.UTC().Format(time.RFC3339)
+			adminRecord.PublicKey = hex.EncodeToString(rec.PublicKey)
+		}
+		response.Records = append(response.Records, adminRecord)
+	}
+
+	writeResponse(w, response)
+}
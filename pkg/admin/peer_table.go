@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/cla/quicl"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// PeerTableHandler serves the peer table, requiring the ReadOnly role like GET /peers. It is meant to be registered at
+// the /peers path of the HTTP server's root, where tools for dtn7-rs expect it.
+func (api *AdminAPI) PeerTableHandler() http.HandlerFunc {
+	return api.authorize(ReadOnly, api.handlePeerTable)
+}
+
+// handlePeerTable returns the peer table of connected and static peers, called by GET /peers/table.
+func (api *AdminAPI) handlePeerTable(w http.ResponseWriter, _ *http.Request) {
+	manager := cla.GetManagerSingleton()
+	now := clock.Now()
+	table := make(map[string]*AdminPeerTableEntry)
+
+	entry := func(peer bpv7.EndpointID, address string) *AdminPeerTableEntry {
+		name := peer.Authority()
+		rsPeer, ok := table[name]
+		if !ok {
+			rsPeer = &AdminPeerTableEntry{
+				Eid:      rsEndpointID(peer),
+				Addr:     rsAddress(address),
+				ConType:  "Dynamic",
+				ClaList:  make([][]interface{}, 0),
+				Services: make(map[string]string),
+			}
+			table[name] = rsPeer
+		}
+		return rsPeer
+	}
+
+	for _, liveness := range manager.GetPeerLiveness() {
+		rsPeer := entry(liveness.Endpoint, liveness.Address)
+		rsPeer.ConType = "Static"
+		if liveness.Up {
+			rsPeer.LastContact = liveness.LastProbe.Unix()
+		} else {
+			rsPeer.LastContact = liveness.Since.Unix()
+			rsPeer.Fails = 1
+		}
+	}
+
+	for _, sender := range manager.GetSenders() {
+		rsPeer := entry(sender.GetPeerEndpointID(), sender.Address())
+		rsPeer.ClaList = append(rsPeer.ClaList, []interface{}{rsClaName(sender), rsPort(sender.Address())})
+		rsPeer.LastContact = now.Unix()
+		rsPeer.Fails = 0
+	}
+
+	writeResponse(w, table)
+}
+
+// rsEndpointID serialises an EndpointID like dtn7-rs, i.e., its scheme number and its scheme specific part.
+func rsEndpointID(eid bpv7.EndpointID) []interface{} {
+	switch e := eid.EndpointType.(type) {
+	case bpv7.DtnEndpoint:
+		if e.IsDtnNone {
+			return []interface{}{1, 0}
+		}
+		return []interface{}{1, "//" + e.NodeName + "/" + e.Demux}
+	case bpv7.IpnEndpoint:
+		return []interface{}{2, []uint64{e.Node, e.Service}}
+	default:
+		return []interface{}{0, eid.String()}
+	}
+}
+
+// rsAddress describes a CLA's address by its host like dtn7-rs's PeerAddress.
+func rsAddress(address string) map[string]string {
+	host := stripScheme(address)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return map[string]string{"Ip": ip.String()}
+	}
+	return map[string]string{"Generic": host}
+}
+
+// rsClaName names a sender's CLA by its address' scheme or its type.
+func rsClaName(sender cla.ConvergenceSender) string {
+	if i := strings.Index(sender.Address(), "://"); i > 0 {
+		return sender.Address()[:i]
+	}
+	switch sender.(type) {
+	case *mtcp.MTCPClient:
+		return "mtcp"
+	case *quicl.Endpoint:
+		return "quicl"
+	default:
+		return "unknown"
+	}
+}
+
+// rsPort extracts the port of a CLA's address, nil if there is none.
+func rsPort(address string) interface{} {
+	_, portStr, err := net.SplitHostPort(stripScheme(address))
+	if err != nil {
+		return nil
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil
+	}
+	return port
+}
+
+// stripScheme removes an optional scheme and trailing slash from a CLA's address, e.g., "mtcp://10.0.0.2:35037/".
+func stripScheme(address string) string {
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	return strings.TrimSuffix(address, "/")
+}
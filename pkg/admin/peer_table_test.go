@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/testutil"
+)
+
+func TestPeerTableSerialisation(t *testing.T) {
+	endpointTests := []struct {
+		eid      string
+		expected string
+	}{
+		{"dtn://node/", `[1,"//node/"]`},
+		{"dtn://node/inbox", `[1,"//node/inbox"]`},
+		{bpv7.DtnEndpointDtnNone, `[1,0]`},
+		{"ipn:23.42", `[2,[23,42]]`},
+	}
+	for _, test := range endpointTests {
+		t.Run(test.eid, func(t *testing.T) {
+			serialised, err := json.Marshal(rsEndpointID(bpv7.MustNewEndpointID(test.eid)))
+			if err != nil {
+				t.Fatal(err)
+			} else if string(serialised) != test.expected {
+				t.Fatalf("Expected %s, got %s", test.expected, serialised)
+			}
+		})
+	}
+
+	addressTests := []struct {
+		address string
+		host    string
+		// port is the expected port, zero if there is none
+		port uint64
+	}{
+		{"mtcp://10.0.0.2:35037", `{"Ip":"10.0.0.2"}`, 35037},
+		{"10.0.0.2:35037/", `{"Ip":"10.0.0.2"}`, 35037},
+		{"quicl://[fe80::1]:35037", `{"Ip":"fe80::1"}`, 35037},
+		{"mtcp://peer.example:4556", `{"Generic":"peer.example"}`, 4556},
+		{"peer.example", `{"Generic":"peer.example"}`, 0},
+		{"mtcp://10.0.0.2:port", `{"Ip":"10.0.0.2"}`, 0},
+	}
+	for _, test := range addressTests {
+		t.Run(test.address, func(t *testing.T) {
+			serialised, err := json.Marshal(rsAddress(test.address))
+			if err != nil {
+				t.Fatal(err)
+			} else if string(serialised) != test.host {
+				t.Fatalf("Expected %s, got %s", test.host, serialised)
+			}
+
+			port := rsPort(test.address)
+			if test.port == 0 {
+				if port != nil {
+					t.Fatalf("Expected no port, got %v", port)
+				}
+			} else if port != test.port {
+				t.Fatalf("Expected port %d, got %v", test.port, port)
+			}
+		})
+	}
+}
+
+func TestPeerTable(t *testing.T) {
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm())
+
+	fakeClock := clock.NewFakeClock(time.Unix(1712919733, 0))
+	previous := clock.GetClock()
+	clock.SetClock(fakeClock)
+	t.Cleanup(func() { clock.SetClock(previous) })
+
+	manager := cla.GetManagerSingleton()
+	manager.Register(testutil.NewFakeSender("mtcp://10.0.0.2:35037", bpv7.MustNewEndpointID("dtn://connected/")))
+	manager.Register(testutil.NewFakeSender("mtcp://10.0.0.3:35037", bpv7.MustNewEndpointID("dtn://static/")))
+	for deadline := time.Now().Add(5 * time.Second); len(manager.GetSenders()) < 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Senders were not registered")
+		}
+	}
+
+	manager.MarkPeer(bpv7.MustNewEndpointID("dtn://static/"), "10.0.0.3:35037", true)
+	manager.MarkPeer(bpv7.MustNewEndpointID("dtn://down/"), "peer.example:35037", false)
+	fakeClock.Advance(time.Minute)
+
+	recorder := httptest.NewRecorder()
+	(&AdminAPI{}).handlePeerTable(recorder, httptest.NewRequest(http.MethodGet, "/peers/table", nil))
+
+	var table map[string]json.RawMessage
+	if err := json.NewDecoder(recorder.Body).Decode(&table); err != nil {
+		t.Fatal(err)
+	}
+
+	now := fakeClock.Now().Unix()
+	probed := now - 60
+	expected := map[string]string{
+		"connected": `{"eid":[1,"//connected/"],"addr":{"Ip":"10.0.0.2"},"con_type":"Dynamic","period":null,` +
+			`"cla_list":[["mtcp",35037]],"services":{},"last_contact":` + strconv.FormatInt(now, 10) + `,"fails":0}`,
+		"static": `{"eid":[1,"//static/"],"addr":{"Ip":"10.0.0.3"},"con_type":"Static","period":null,` +
+			`"cla_list":[["mtcp",35037]],"services":{},"last_contact":` + strconv.FormatInt(now, 10) + `,"fails":0}`,
+		"down": `{"eid":[1,"//down/"],"addr":{"Generic":"peer.example"},"con_type":"Static","period":null,` +
+			`"cla_list":[],"services":{},"last_contact":` + strconv.FormatInt(probed, 10) + `,"fails":1}`,
+	}
+	if len(table) != len(expected) {
+		t.Fatalf("Expected peers %v, got %v", expected, table)
+	}
+	for name, entry := range expected {
+		if got := string(table[name]); got != entry {
+			t.Fatalf("Expected peer %s as %s, got %s", name, entry, got)
+		}
+	}
+}
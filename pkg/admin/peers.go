@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/reputation"
+)
+
+// errReputationDisabled is reported if reputation tracking was not enabled in the configuration.
+const errReputationDisabled = "reputation tracking is not enabled"
+
+// handlePeersGet returns the reachability of all probed peers, called by GET /peers.
+func (api *AdminAPI) handlePeersGet(w http.ResponseWriter, _ *http.Request) {
+	response := AdminPeersResponse{Peers: make([]AdminPeerLiveness, 0)}
+
+	for _, peer := range cla.GetManagerSingleton().GetPeerLiveness() {
+		response.Peers = append(response.Peers, AdminPeerLiveness{
+			Endpoint:  peer.Endpoint.String(),
+			Address:   peer.Address,
+			Up:        peer.Up,
+			Since:     peer.Since,
+			LastProbe: peer.LastProbe,
+		})
+	}
+
+	writeResponse(w, response)
+}
+
+// handlePeersReputation returns the reputations of all observed peers, called by GET /peers/reputation.
+func (api *AdminAPI) handlePeersReputation(w http.ResponseWriter, _ *http.Request) {
+	if !reputation.Initialised() {
+		writeResponse(w, AdminPeerReputationsResponse{Error: errReputationDisabled})
+		return
+	}
+
+	response := AdminPeerReputationsResponse{Peers: make([]AdminPeerReputation, 0)}
+	for _, peer := range reputation.GetTrackerSingleton().Peers() {
+		adminPeer := AdminPeerReputation{
+			Endpoint:   peer.Peer.String(),
+			Score:      peer.Score(),
+			Accepted:   peer.Accepted,
+			Malformed:  peer.Malformed,
+			Refused:    peer.Refused,
+			Duplicates: peer.Duplicates,
+		}
+		if !peer.QuarantinedUntil.IsZero() {
+			adminPeer.QuarantinedUntil = peer.QuarantinedUntil.UTC().Format(time.RFC3339)
+		}
+		response.Peers = append(response.Peers, adminPeer)
+	}
+
+	writeResponse(w, response)
+}
@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// handleRegistrationsGet returns all, a single or the idle registrations of local applications, called by
+// GET /registrations.
+func (api *AdminAPI) handleRegistrationsGet(w http.ResponseWriter, r *http.Request) {
+	var registrations []application_agent.Registration
+	if id := r.URL.Query().Get("id"); id != "" {
+		registration, ok := application_agent.GetManagerSingleton().Registration(id)
+		if !ok {
+			writeResponse(w, AdminRegistrationsResponse{Error: fmt.Sprintf("unknown registration %q", id)})
+			return
+		}
+		registrations = []application_agent.Registration{registration}
+	} else {
+		registrations = application_agent.GetManagerSingleton().Registrations()
+	}
+
+	idle, err := parseIdle(r)
+	if err != nil {
+		writeResponse(w, AdminRegistrationsResponse{Error: err.Error()})
+		return
+	}
+
+	response := AdminRegistrationsResponse{Registrations: make([]AdminRegistration, 0, len(registrations))}
+	for _, registration := range registrations {
+		if clock.Now().Sub(registration.LastSeen) >= idle {
+			response.Registrations = append(response.Registrations, toAdminRegistration(registration))
+		}
+	}
+	writeResponse(w, response)
+}
+
+// handleRegistrationsDelete removes a single registration or all idle ones, called by DELETE /registrations.
+func (api *AdminAPI) handleRegistrationsDelete(w http.ResponseWriter, r *http.Request) {
+	manager := application_agent.GetManagerSingleton()
+	response := AdminRegistrationsResponse{Registrations: make([]AdminRegistration, 0)}
+
+	if id := r.URL.Query().Get("id"); id != "" {
+		registration, ok := manager.Registration(id)
+		if !ok || !manager.Deregister(id) {
+			writeResponse(w, AdminRegistrationsResponse{Error: fmt.Sprintf("unknown registration %q", id)})
+			return
+		}
+		response.Registrations = append(response.Registrations, toAdminRegistration(registration))
+		writeResponse(w, response)
+		return
+	}
+
+	if r.URL.Query().Get("idle") == "" {
+		writeResponse(w, AdminRegistrationsResponse{Error: "either a registration's id or an idle duration is required"})
+		return
+	}
+	idle, err := parseIdle(r)
+	if err != nil {
+		writeResponse(w, AdminRegistrationsResponse{Error: err.Error()})
+		return
+	}
+	for _, registration := range manager.DeregisterIdle(idle) {
+		response.Registrations = append(response.Registrations, toAdminRegistration(registration))
+	}
+	writeResponse(w, response)
+}
+
+// parseIdle parses the optional idle duration of a request, zero if absent.
+func parseIdle(r *http.Request) (time.Duration, error) {
+	idleStr := r.URL.Query().Get("idle")
+	if idleStr == "" {
+		return 0, nil
+	}
+
+	idle, err := time.ParseDuration(idleStr)
+	if err != nil {
+		return 0, err
+	} else if idle < 0 {
+		return 0, fmt.Errorf("idle duration %v is negative", idle)
+	}
+	return idle, nil
+}
+
+func toAdminRegistration(registration application_agent.Registration) AdminRegistration {
+	return AdminRegistration{
+		ID:         registration.ID,
+		Endpoint:   registration.Endpoint.String(),
+		Agent:      registration.Agent,
+		Filtered:   registration.Filtered,
+		Pending:    registration.Pending,
+		Registered: registration.Registered,
+		LastSeen:   registration.LastSeen,
+		Idle:       clock.Now().Sub(registration.LastSeen).Round(time.Second).String(),
+	}
+}
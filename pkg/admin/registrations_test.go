@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// registrar is an application_agent.Registrar holding fixed registrations.
+type registrar struct {
+	registrations []application_agent.Registration
+}
+
+func (r *registrar) Endpoints() []bpv7.EndpointID { return nil }
+
+func (r *registrar) Deliver(*store.BundleDescriptor) error { return nil }
+
+func (r *registrar) Shutdown() {}
+
+func (r *registrar) Registrations() []application_agent.Registration {
+	return append([]application_agent.Registration(nil), r.registrations...)
+}
+
+func (r *registrar) Deregister(id string) bool {
+	for i, registration := range r.registrations {
+		if registration.ID == id {
+			r.registrations = append(r.registrations[:i], r.registrations[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegistrations(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	previous := clock.GetClock()
+	clock.SetClock(fakeClock)
+	t.Cleanup(func() { clock.SetClock(previous) })
+
+	if err := application_agent.InitialiseApplicationAgentManager(func(*bpv7.Bundle) {}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(application_agent.GetManagerSingleton().Shutdown)
+
+	registration := func(id string, idle time.Duration) application_agent.Registration {
+		return application_agent.Registration{
+			ID:         id,
+			Endpoint:   bpv7.MustNewEndpointID("dtn://node/" + id),
+			Agent:      "rest",
+			Registered: fakeClock.Now().Add(-time.Hour),
+			LastSeen:   fakeClock.Now().Add(-idle),
+		}
+	}
+	agent := &registrar{registrations: []application_agent.Registration{
+		registration("active", 0),
+		registration("idle", 10*time.Minute),
+		registration("stale", time.Hour),
+	}}
+	if err := application_agent.GetManagerSingleton().RegisterAgent(agent); err != nil {
+		t.Fatal(err)
+	}
+
+	api := &AdminAPI{}
+	request := func(method, query string) AdminRegistrationsResponse {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest(method, "/registrations"+query, nil)
+		if method == http.MethodDelete {
+			api.handleRegistrationsDelete(recorder, r)
+		} else {
+			api.handleRegistrationsGet(recorder, r)
+		}
+
+		var response AdminRegistrationsResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	ids := func(response AdminRegistrationsResponse) (ids []string) {
+		for _, registration := range response.Registrations {
+			ids = append(ids, registration.ID)
+		}
+		return
+	}
+	expectIDs := func(response AdminRegistrationsResponse, expected ...string) {
+		t.Helper()
+
+		if response.Error != "" {
+			t.Fatalf("Unexpected error %q", response.Error)
+		}
+		if got := ids(response); len(got) != len(expected) {
+			t.Fatalf("Expected registrations %v, got %v", expected, got)
+		} else {
+			for i := range got {
+				if got[i] != expected[i] {
+					t.Fatalf("Expected registrations %v, got %v", expected, got)
+				}
+			}
+		}
+	}
+	expectError := func(response AdminRegistrationsResponse) {
+		t.Helper()
+
+		if response.Error == "" {
+			t.Fatalf("Expected an error, got registrations %v", ids(response))
+		}
+	}
+
+	expectIDs(request(http.MethodGet, ""), "active", "idle", "stale")
+	expectIDs(request(http.MethodGet, "?idle=5m"), "idle", "stale")
+	expectError(request(http.MethodGet, "?idle=-5m"))
+	expectError(request(http.MethodGet, "?idle=soon"))
+	expectError(request(http.MethodGet, "?id=unknown"))
+
+	single := request(http.MethodGet, "?id=stale")
+	expectIDs(single, "stale")
+	if got := single.Registrations[0]; got.Endpoint != "dtn://node/stale" || got.Agent != "rest" || got.Idle != "1h0m0s" {
+		t.Fatalf("Unexpected registration %+v", got)
+	}
+
+	// Removing requires either an ID or an idle duration, as a request without would remove all registrations
+	expectError(request(http.MethodDelete, ""))
+	expectError(request(http.MethodDelete, "?id=unknown"))
+
+	expectIDs(request(http.MethodDelete, "?id=stale"), "stale")
+	expectIDs(request(http.MethodDelete, "?idle=5m"), "idle")
+	expectIDs(request(http.MethodGet, ""), "active")
+}
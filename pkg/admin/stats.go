@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/node_stats"
+)
+
+// errNodeStatsDisabled is reported if node statistics were not enabled.
+const errNodeStatsDisabled = "node statistics are not enabled"
+
+// handleDeliveryStats returns the delivery ratios and latencies, called by GET /stats/delivery.
+func (api *AdminAPI) handleDeliveryStats(w http.ResponseWriter, _ *http.Request) {
+	stats := application_agent.GetManagerSingleton().DeliveryStats()
+	response := AdminDeliveryStatsResponse{
+		Destinations: make([]AdminDestinationStats, 0, len(stats.Destinations)),
+		Endpoints:    make([]AdminEndpointStats, 0, len(stats.Endpoints)),
+	}
+
+	for eid, destStats := range stats.Destinations {
+		response.Destinations = append(response.Destinations, AdminDestinationStats{
+			Destination:   eid.String(),
+			Sent:          destStats.Sent,
+			Delivered:     destStats.Delivered,
+			DeliveryRatio: destStats.DeliveryRatio(),
+			Latency:       toAdminLatencyHistogram(destStats.Latency),
+		})
+	}
+	for eid, endpointStats := range stats.Endpoints {
+		response.Endpoints = append(response.Endpoints, AdminEndpointStats{
+			Endpoint:  eid.String(),
+			Delivered: endpointStats.Delivered,
+			Latency:   toAdminLatencyHistogram(endpointStats.Latency),
+		})
+	}
+
+	sort.Slice(response.Destinations, func(i, j int) bool {
+		return response.Destinations[i].Destination < response.Destinations[j].Destination
+	})
+	sort.Slice(response.Endpoints, func(i, j int) bool {
+		return response.Endpoints[i].Endpoint < response.Endpoints[j].Endpoint
+	})
+
+	writeResponse(w, response)
+}
+
+func toAdminLatencyHistogram(histogram application_agent.LatencyHistogram) AdminLatencyHistogram {
+	adminHistogram := AdminLatencyHistogram{
+		Buckets: make([]AdminLatencyBucket, 0, len(application_agent.LatencyBounds)+1),
+		Count:   histogram.Count,
+		Mean:    histogram.Mean().String(),
+	}
+
+	for i := 0; i <= len(application_agent.LatencyBounds); i++ {
+		bucket := AdminLatencyBucket{UpperBound: "+Inf"}
+		if i < len(application_agent.LatencyBounds) {
+			bucket.UpperBound = application_agent.LatencyBounds[i].String()
+		}
+		if histogram.Counts != nil {
+			bucket.Count = histogram.Counts[i]
+		}
+		adminHistogram.Buckets = append(adminHistogram.Buckets, bucket)
+	}
+
+	return adminHistogram
+}
+
+// handleCompressionStats returns the achieved compression of MTCP connections, called by GET /stats/compression.
+func (api *AdminAPI) handleCompressionStats(w http.ResponseWriter, _ *http.Request) {
+	sent, received := mtcp.GetCompressionStats()
+	writeResponse(w, AdminCompressionStatsResponse{
+		Sent:     toAdminCompressionStats(sent),
+		Received: toAdminCompressionStats(received),
+	})
+}
+
+func toAdminCompressionStats(stats mtcp.CompressionStats) AdminCompressionStats {
+	return AdminCompressionStats{
+		Sessions:          stats.Sessions,
+		UncompressedBytes: stats.UncompressedBytes,
+		CompressedBytes:   stats.CompressedBytes,
+		Ratio:             stats.Ratio(),
+	}
+}
+
+// handleNodeStats returns the node's statistics, summed up over all its runs, called by GET /stats/node.
+func (api *AdminAPI) handleNodeStats(w http.ResponseWriter, _ *http.Request) {
+	if !node_stats.Initialised() {
+		writeResponse(w, AdminNodeStatsResponse{Error: errNodeStatsDisabled})
+		return
+	}
+
+	report := node_stats.GetRecorderSingleton().Report()
+	writeResponse(w, AdminNodeStatsResponse{
+		Forwarded:      report.Forwarded,
+		ForwardedBytes: report.ForwardedBytes,
+		Delivered:      report.Delivered,
+		Restarts:       report.Restarts,
+		Uptime:         report.Uptime.Round(time.Second).String(),
+		FirstStart:     report.FirstStart.UTC(),
+		Started:        report.Started.UTC(),
+		Session:        report.Session().Round(time.Second).String(),
+	})
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// handleCompact compacts the store and reports the reclaimed space, called by POST /store/compact.
+func (api *AdminAPI) handleCompact(w http.ResponseWriter, _ *http.Request) {
+	log.Info("Store compaction triggered via admin API")
+
+	var response AdminCompactResponse
+	report, err := store.GetStoreSingleton().Compact()
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	response.ExpiredQuarantined = report.ExpiredQuarantined
+	response.OrphanedFiles = report.OrphanedFiles
+	response.RewrittenLogs = report.RewrittenLogs
+	response.KnownBundles = report.KnownBundles
+	response.ReclaimedBytes = report.ReclaimedBytes
+	response.Duration = report.Duration.String()
+	writeResponse(w, response)
+}
+
+// handleSnapshot copies the store to a new path, called by POST /store/snapshot.
+func (api *AdminAPI) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	var (
+		request  AdminSnapshotRequest
+		response AdminSnapshotResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&request); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse admin snapshot request")
+		response.Error = jsonErr.Error()
+		writeResponse(w, response)
+		return
+	} else if request.Path == "" {
+		response.Error = "no snapshot path"
+		writeResponse(w, response)
+		return
+	}
+
+	log.WithField("path", request.Path).Info("Store snapshot triggered via admin API")
+
+	report, err := store.GetStoreSingleton().Snapshot(request.Path)
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	response.Path = request.Path
+	response.Bundles = report.Bundles
+	response.Bytes = report.Bytes
+	response.Duration = report.Duration.String()
+	writeResponse(w, response)
+}
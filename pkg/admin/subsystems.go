@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Subsystem of a node which might be stopped and started at runtime, e.g., a CLA listener or the discovery.
+type Subsystem interface {
+	// Start a stopped Subsystem.
+	Start() error
+	// Stop a running Subsystem.
+	Stop() error
+	// Running reports if the Subsystem is running.
+	Running() bool
+}
+
+// RegisterSubsystem makes a Subsystem available to be stopped and started by its name, replacing a previous one of
+// the same name.
+func (api *AdminAPI) RegisterSubsystem(name string, subsystem Subsystem) {
+	api.subsystemsMutex.Lock()
+	defer api.subsystemsMutex.Unlock()
+
+	api.subsystems[name] = subsystem
+}
+
+// handleSubsystemsGet lists all registered Subsystems by their names, called by GET /subsystems.
+func (api *AdminAPI) handleSubsystemsGet(w http.ResponseWriter, _ *http.Request) {
+	api.subsystemsMutex.Lock()
+	defer api.subsystemsMutex.Unlock()
+
+	subsystems := make([]AdminSubsystem, 0, len(api.subsystems))
+	for name, subsystem := range api.subsystems {
+		subsystems = append(subsystems, AdminSubsystem{Name: name, Running: subsystem.Running()})
+	}
+	sort.Slice(subsystems, func(i, j int) bool {
+		return subsystems[i].Name < subsystems[j].Name
+	})
+
+	writeResponse(w, AdminSubsystemsResponse{Subsystems: subsystems})
+}
+
+// handleSubsystemStop stops a running Subsystem, called by POST /subsystems/stop.
+func (api *AdminAPI) handleSubsystemStop(w http.ResponseWriter, r *http.Request) {
+	api.handleSubsystemChange(w, r, false)
+}
+
+// handleSubsystemStart starts a stopped Subsystem, called by POST /subsystems/start.
+func (api *AdminAPI) handleSubsystemStart(w http.ResponseWriter, r *http.Request) {
+	api.handleSubsystemChange(w, r, true)
+}
+
+// handleSubsystemChange brings the requested Subsystem into the running or stopped state.
+func (api *AdminAPI) handleSubsystemChange(w http.ResponseWriter, r *http.Request, start bool) {
+	var (
+		request  AdminSubsystemRequest
+		response AdminSubsystemResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&request); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse admin subsystem request")
+		response.Error = jsonErr.Error()
+		writeResponse(w, response)
+		return
+	}
+	response.Name = request.Name
+
+	// changes are serialised, e.g., to not start a listener twice
+	api.subsystemsMutex.Lock()
+	defer api.subsystemsMutex.Unlock()
+
+	subsystem, ok := api.subsystems[request.Name]
+	if !ok {
+		response.Error = fmt.Sprintf("unknown subsystem %s", request.Name)
+		writeResponse(w, response)
+		return
+	}
+
+	if running := subsystem.Running(); running == start {
+		if start {
+			response.Error = fmt.Sprintf("subsystem %s is already running", request.Name)
+		} else {
+			response.Error = fmt.Sprintf("subsystem %s is already stopped", request.Name)
+		}
+	} else {
+		var err error
+		if start {
+			err = subsystem.Start()
+		} else {
+			err = subsystem.Stop()
+		}
+
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"subsystem": request.Name,
+				"start":     start,
+			}).Warn("Failed to change subsystem")
+			response.Error = err.Error()
+		} else {
+			log.WithFields(log.Fields{
+				"subsystem": request.Name,
+				"start":     start,
+			}).Info("Changed subsystem by admin API")
+		}
+	}
+
+	response.Running = subsystem.Running()
+	writeResponse(w, response)
+}
@@ -1,7 +1,9 @@
 package application_agent
 
 import (
+	"errors"
 	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 
@@ -14,6 +16,18 @@ type Manager struct {
 	stateMutex   sync.RWMutex
 	agents       []ApplicationAgent
 	sendCallback func(bundle *bpv7.Bundle)
+	tracker      *deliveryTracker
+	dedup        *sendDeduplicator
+	stats        *deliveryStats
+
+	// refused holds the agents which refused a bundle by an ErrMailboxFull, by its IDString, see Redelivery
+	refusedMutex sync.Mutex
+	refused      map[string][]ApplicationAgent
+
+	// spool of submitted bundles while the node cannot take them, nil if disabled, see spool.go
+	spool     *spool
+	spoolStop chan struct{}
+	closing   atomic.Bool
 }
 
 var managerSingleton *Manager
@@ -22,6 +36,10 @@ func InitialiseApplicationAgentManager(sendCallback func(bundle *bpv7.Bundle)) e
 	manager := Manager{
 		agents:       make([]ApplicationAgent, 0, 10),
 		sendCallback: sendCallback,
+		tracker:      newDeliveryTracker(),
+		dedup:        newSendDeduplicator(),
+		stats:        newDeliveryStats(),
+		refused:      make(map[string][]ApplicationAgent),
 	}
 	managerSingleton = &manager
 	return nil
@@ -86,23 +104,81 @@ func (manager *Manager) UnregisterEndpoint(removeAgent ApplicationAgent) error {
 	return nil
 }
 
-func (manager *Manager) Delivery(bundleDescriptor *store.BundleDescriptor) {
+// Delivery hands a bundle over to all agents. It returns true if an agent is registered for the bundle's destination.
+// An agent refusing the bundle by an ErrMailboxFull, e.g., a REST client's full mailbox, is returned. Then, the bundle
+// is not delivered yet, but must be kept for its Redelivery.
+func (manager *Manager) Delivery(bundleDescriptor *store.BundleDescriptor) (delivered bool, err error) {
 	manager.stateMutex.RLock()
 	defer manager.stateMutex.RUnlock()
 
-	for _, agent := range manager.agents {
-		err := agent.Deliver(bundleDescriptor)
-		if err != nil {
+	return manager.deliver(bundleDescriptor, manager.agents)
+}
+
+// Redelivery hands a bundle over to the agents which refused it before, as described for Delivery. Without a record
+// of those, e.g., after a restart, all agents get the bundle.
+func (manager *Manager) Redelivery(bundleDescriptor *store.BundleDescriptor) (delivered bool, err error) {
+	manager.stateMutex.RLock()
+	defer manager.stateMutex.RUnlock()
+
+	manager.refusedMutex.Lock()
+	refused, ok := manager.refused[bundleDescriptor.IDString]
+	manager.refusedMutex.Unlock()
+
+	agents := manager.agents
+	if ok {
+		// agents unregistered meanwhile are skipped
+		agents = make([]ApplicationAgent, 0, len(refused))
+		for _, agent := range manager.agents {
+			for _, refusing := range refused {
+				if agent == refusing {
+					agents = append(agents, agent)
+				}
+			}
+		}
+	}
+	return manager.deliver(bundleDescriptor, agents)
+}
+
+// deliver hands a bundle over to the agents and records those refusing it. The caller must hold the stateMutex.
+func (manager *Manager) deliver(bundleDescriptor *store.BundleDescriptor, agents []ApplicationAgent) (delivered bool, err error) {
+	var refused []ApplicationAgent
+	for _, agent := range agents {
+		if agentErr := agent.Deliver(bundleDescriptor); errors.Is(agentErr, ErrMailboxFull) {
+			refused = append(refused, agent)
+			err = errors.Join(err, agentErr)
+		} else if agentErr != nil {
 			log.WithFields(log.Fields{
 				"bundle": bundleDescriptor.ID,
 				"agent":  agent,
-				"error":  err,
+				"error":  agentErr,
 			}).Error("Error delivering bundle")
 		}
 	}
+
+	manager.refusedMutex.Lock()
+	if len(refused) > 0 {
+		manager.refused[bundleDescriptor.IDString] = refused
+	} else {
+		delete(manager.refused, bundleDescriptor.IDString)
+	}
+	manager.refusedMutex.Unlock()
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Info("Agent refused bundle, deferring its delivery")
+		return false, err
+	}
+	return manager.countLocalDelivery(bundleDescriptor), nil
 }
 
 func (manager *Manager) Shutdown() {
+	manager.closing.Store(true)
+	if manager.spoolStop != nil {
+		close(manager.spoolStop)
+	}
+
 	manager.stateMutex.RLock()
 	defer manager.stateMutex.RUnlock()
 
@@ -119,5 +195,78 @@ func (manager *Manager) Send(bndl *bpv7.Bundle) {
 	idKeeper := id_keeper.GetIdKeeperSingleton()
 	idKeeper.Update(bndl)
 	log.WithFields(log.Fields{"bundle": bndl.ID().String()}).Debug("Application agent sent bundle")
+	manager.tracker.track(bndl)
+	manager.stats.sent(bndl.PrimaryBlock.Destination)
 	manager.sendCallback(bndl)
 }
+
+// DeliveryState returns the state of a bundle sent by an application agent, as known from status reports.
+// Bundles are only tracked until their lifetime is exceeded.
+func (manager *Manager) DeliveryState(bundleID bpv7.BundleID) (DeliveryState, bool) {
+	return manager.tracker.get(bundleID.Scrub().String())
+}
+
+// DeliveryStateByIDString returns the state of a bundle sent by an application agent, identified by its BundleID's
+// String representation.
+func (manager *Manager) DeliveryStateByIDString(idString string) (DeliveryState, bool) {
+	return manager.tracker.get(idString)
+}
+
+// ReceiveStatusReport correlates a status report with a bundle sent by an application agent.
+// All agents implementing DeliveryReportReceiver for the bundle's source endpoint get notified.
+func (manager *Manager) ReceiveStatusReport(reportingNode bpv7.EndpointID, report *bpv7.StatusReport) {
+	state, firstDelivery, deletion, ok := manager.tracker.update(reportingNode, report)
+	if !ok {
+		log.WithField("bundle", report.RefBundle).Debug("Status report references no locally sent bundle")
+		return
+	}
+
+	if firstDelivery {
+		deliveredAt, _ := state.DeliveredAt()
+		manager.stats.delivered(state.Destination, deliveredAt.Sub(state.Sent))
+	}
+
+	manager.stateMutex.RLock()
+	defer manager.stateMutex.RUnlock()
+
+	for _, agent := range manager.agents {
+		receiver, ok := agent.(DeliveryReportReceiver)
+		if ok && bagContainsEndpoint(agent.Endpoints(), []bpv7.EndpointID{state.BundleID.SourceNode}) {
+			receiver.DeliveryReport(state)
+		}
+	}
+	if deletion != nil {
+		manager.notifyDeletion(state, *deletion)
+	}
+}
+
+// ReportDeletion notifies the application agents of a bundle's deletion by this node, e.g., due to its exceeded
+// lifetime, if it was sent by a local application. All agents implementing DeletionReceiver for the bundle's source
+// endpoint get notified, unless the node's deletion was already known from its own status report.
+func (manager *Manager) ReportDeletion(node bpv7.EndpointID, bundleID bpv7.BundleID, reason bpv7.StatusReportReason) {
+	state, deletion := manager.tracker.deleted(node, bundleID, reason)
+	if deletion == nil {
+		return
+	}
+
+	manager.stateMutex.RLock()
+	defer manager.stateMutex.RUnlock()
+
+	manager.notifyDeletion(state, *deletion)
+}
+
+// notifyDeletion notifies the DeletionReceivers of the deleted bundle's source endpoint. The stateMutex must be held.
+func (manager *Manager) notifyDeletion(state DeliveryState, event StatusEvent) {
+	log.WithFields(log.Fields{
+		"bundle": state.BundleID,
+		"node":   event.Node,
+		"reason": event.Reason,
+	}).Info("Bundle sent by an application agent was deleted")
+
+	for _, agent := range manager.agents {
+		receiver, ok := agent.(DeletionReceiver)
+		if ok && bagContainsEndpoint(agent.Endpoints(), []bpv7.EndpointID{state.BundleID.SourceNode}) {
+			receiver.BundleDeleted(state, event)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package application_agent
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// DuplicatePolicy describes how to handle a bundle submitted by an application which duplicates a recent one.
+type DuplicatePolicy int
+
+const (
+	// DuplicateCoalesce drops the duplicate and reports the earlier bundle's ID to the application instead.
+	DuplicateCoalesce DuplicatePolicy = iota
+	// DuplicateReject drops the duplicate and reports a DuplicateBundleError to the application.
+	DuplicateReject
+)
+
+// DuplicatePolicyFromString parses a DuplicatePolicy, either "coalesce" or "reject".
+func DuplicatePolicyFromString(s string) (DuplicatePolicy, error) {
+	switch s {
+	case "coalesce":
+		return DuplicateCoalesce, nil
+	case "reject":
+		return DuplicateReject, nil
+	default:
+		return 0, fmt.Errorf("unknown duplicate policy %q", s)
+	}
+}
+
+func (policy DuplicatePolicy) String() string {
+	switch policy {
+	case DuplicateCoalesce:
+		return "coalesce"
+	case DuplicateReject:
+		return "reject"
+	default:
+		return "unknown"
+	}
+}
+
+// DuplicateBundleError is returned by Submit for a rejected duplicate, naming the earlier bundle.
+type DuplicateBundleError bpv7.BundleID
+
+func NewDuplicateBundleError(original bpv7.BundleID) *DuplicateBundleError {
+	err := DuplicateBundleError(original)
+	return &err
+}
+
+func (err *DuplicateBundleError) Error() string {
+	return fmt.Sprintf("bundle duplicates %v, submitted within the deduplication window", bpv7.BundleID(*err))
+}
+
+// submission is a bundle recently submitted by an application.
+type submission struct {
+	key      [sha256.Size]byte
+	bundleID bpv7.BundleID
+	at       time.Time
+}
+
+// sendDeduplicator remembers the bundles submitted by applications within a window, identified by their source,
+// destination and payload.
+type sendDeduplicator struct {
+	mutex  sync.Mutex
+	window time.Duration
+	policy DuplicatePolicy
+	seen   map[[sha256.Size]byte]submission
+	// order holds the submissions in the order they were seen, allowing to prune the oldest ones first.
+	order []submission
+}
+
+func newSendDeduplicator() *sendDeduplicator {
+	return &sendDeduplicator{seen: make(map[[sha256.Size]byte]submission)}
+}
+
+// submissionKey hashes the parts of a bundle which make up a duplicate.
+func submissionKey(bndl *bpv7.Bundle) [sha256.Size]byte {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%v\x00%v\x00", bndl.PrimaryBlock.SourceNode, bndl.PrimaryBlock.Destination)
+	if payloadBlock, err := bndl.PayloadBlock(); err == nil {
+		h.Write(payloadBlock.Value.(*bpv7.PayloadBlock).Data())
+	}
+
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// prune forgets all submissions seen before the window.
+func (dedup *sendDeduplicator) prune(now time.Time) {
+	i := 0
+	for ; i < len(dedup.order); i++ {
+		if now.Sub(dedup.order[i].at) < dedup.window {
+			break
+		}
+		if dedup.seen[dedup.order[i].key].at == dedup.order[i].at {
+			delete(dedup.seen, dedup.order[i].key)
+		}
+	}
+	dedup.order = dedup.order[i:]
+}
+
+// check records a submitted bundle and returns the earlier submission if it is a duplicate. Otherwise, the returned
+// submission is the recorded one, which must be confirmed by sent or dropped by forget.
+func (dedup *sendDeduplicator) check(bndl *bpv7.Bundle) (recorded submission, duplicate bool) {
+	dedup.mutex.Lock()
+	defer dedup.mutex.Unlock()
+
+	if dedup.window <= 0 {
+		return
+	}
+
+	now := clock.Now()
+	dedup.prune(now)
+
+	key := submissionKey(bndl)
+	if recorded, duplicate = dedup.seen[key]; duplicate {
+		return
+	}
+
+	recorded = submission{key: key, bundleID: bndl.ID(), at: now}
+	dedup.seen[key] = recorded
+	dedup.order = append(dedup.order, recorded)
+	return
+}
+
+// sent updates a recorded submission by the ID of the sent bundle, whose sequence number was assigned on sending.
+func (dedup *sendDeduplicator) sent(recorded submission, bundleID bpv7.BundleID) {
+	dedup.mutex.Lock()
+	defer dedup.mutex.Unlock()
+
+	if current, ok := dedup.seen[recorded.key]; ok && current.at == recorded.at {
+		current.bundleID = bundleID
+		dedup.seen[recorded.key] = current
+	}
+}
+
+// forget drops a recorded submission whose bundle was neither sent nor spooled, such that the application's retry is
+// not taken for a duplicate.
+func (dedup *sendDeduplicator) forget(recorded submission) {
+	dedup.mutex.Lock()
+	defer dedup.mutex.Unlock()
+
+	if current, ok := dedup.seen[recorded.key]; ok && current.at == recorded.at {
+		delete(dedup.seen, recorded.key)
+	}
+}
+
+// SetDeduplication enables the send-side deduplication of bundles submitted by applications, protecting the network
+// from buggy retry loops in clients. A bundle is a duplicate if the same source already submitted the same payload to
+// the same destination within the window. A non-positive window disables deduplication.
+// This method is thread-safe.
+func (manager *Manager) SetDeduplication(window time.Duration, policy DuplicatePolicy) {
+	manager.dedup.mutex.Lock()
+	defer manager.dedup.mutex.Unlock()
+
+	manager.dedup.window = window
+	manager.dedup.policy = policy
+	manager.dedup.seen = make(map[[sha256.Size]byte]submission)
+	manager.dedup.order = nil
+}
+
+// Submit sends a bundle created by an application, subject to the send-side deduplication. It returns the ID under
+// which the bundle is known, which is the earlier bundle's ID for a coalesced duplicate. A rejected duplicate results
+// in a DuplicateBundleError.
+//
+// If the node cannot take the bundle right now, it is spooled and sent later, see SetSpool. An ErrSpoolFull tells the
+// application to retry later.
+func (manager *Manager) Submit(bndl *bpv7.Bundle) (bundleID bpv7.BundleID, spooled bool, err error) {
+	original, duplicate := manager.dedup.check(bndl)
+	if !duplicate {
+		if spooled, err = manager.spoolSubmission(bndl); err != nil {
+			manager.dedup.forget(original)
+			return bpv7.BundleID{}, false, err
+		} else if !spooled {
+			manager.Send(bndl)
+		}
+		manager.dedup.sent(original, bndl.ID())
+		return bndl.ID(), spooled, nil
+	}
+
+	manager.dedup.mutex.Lock()
+	policy := manager.dedup.policy
+	manager.dedup.mutex.Unlock()
+
+	log.WithFields(log.Fields{
+		"bundle":   bndl.ID(),
+		"original": original.bundleID,
+		"policy":   policy,
+	}).Warn("Application submitted a duplicate bundle")
+
+	if policy == DuplicateReject {
+		return bpv7.BundleID{}, false, NewDuplicateBundleError(original.bundleID)
+	}
+	return original.bundleID, false, nil
+}
@@ -0,0 +1,184 @@
+package application_agent
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/id_keeper"
+)
+
+// dedupTestBundle builds a bundle of the source, destination and payload, whose sequence number is assigned on sending.
+func dedupTestBundle(t *testing.T, source, destination, payload string) bpv7.Bundle {
+	t.Helper()
+
+	bndl, err := bpv7.Builder().
+		Source(source).
+		Destination(destination).
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock([]byte(payload)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+func TestSubmitDeduplication(t *testing.T) {
+	if err := id_keeper.InitializeIdKeeper(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(id_keeper.ShutdownIdKeeper)
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	previous := clock.GetClock()
+	clock.SetClock(fakeClock)
+	t.Cleanup(func() { clock.SetClock(previous) })
+
+	for _, policy := range []DuplicatePolicy{DuplicateCoalesce, DuplicateReject} {
+		t.Run(policy.String(), func(t *testing.T) {
+			var sent []bpv7.BundleID
+			manager := &Manager{
+				sendCallback: func(bndl *bpv7.Bundle) { sent = append(sent, bndl.ID()) },
+				tracker:      newDeliveryTracker(),
+				dedup:        newSendDeduplicator(),
+				stats:        newDeliveryStats(),
+			}
+			manager.SetDeduplication(time.Minute, policy)
+
+			submit := func(source, destination, payload string) (bpv7.BundleID, error) {
+				bndl := dedupTestBundle(t, source, destination, payload)
+				bundleID, _, err := manager.Submit(&bndl)
+				return bundleID, err
+			}
+
+			// the sequence numbers assigned on sending differ from the submitted bundles' ones from now on
+			if _, err := submit("dtn://app/"+policy.String(), "dtn://dst/", "first"); err != nil {
+				t.Fatal(err)
+			}
+			original, err := submit("dtn://app/"+policy.String(), "dtn://dst/", "hello")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a duplicate names the sent bundle, whose sequence number was assigned on sending
+			duplicateID, err := submit("dtn://app/"+policy.String(), "dtn://dst/", "hello")
+			var duplicateErr *DuplicateBundleError
+			switch policy {
+			case DuplicateCoalesce:
+				if err != nil || duplicateID != original {
+					t.Fatalf("Expected the duplicate to be coalesced into %v, got %v, %v", original, duplicateID, err)
+				}
+			case DuplicateReject:
+				if !errors.As(err, &duplicateErr) || bpv7.BundleID(*duplicateErr) != original {
+					t.Fatalf("Expected the duplicate of %v to be rejected, got %v, %v", original, duplicateID, err)
+				}
+			}
+			if len(sent) != 2 || sent[1] != original {
+				t.Fatalf("Expected only %v to be sent, got %v", original, sent)
+			}
+
+			// another payload or destination is no duplicate
+			if _, err := submit("dtn://app/"+policy.String(), "dtn://dst/", "hello again"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := submit("dtn://app/"+policy.String(), "dtn://other/", "hello"); err != nil {
+				t.Fatal(err)
+			}
+			if len(sent) != 4 {
+				t.Fatalf("Expected 4 sent bundles, got %v", sent)
+			}
+
+			// after the window, the same submission is sent again
+			fakeClock.Advance(time.Minute)
+			if _, err := submit("dtn://app/"+policy.String(), "dtn://dst/", "hello"); err != nil {
+				t.Fatal(err)
+			}
+			if len(sent) != 5 {
+				t.Fatalf("Expected the submission to be sent after the window, got %v", sent)
+			}
+		})
+	}
+}
+
+func TestSubmitWithoutDeduplication(t *testing.T) {
+	if err := id_keeper.InitializeIdKeeper(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(id_keeper.ShutdownIdKeeper)
+
+	sent := 0
+	manager := &Manager{
+		sendCallback: func(*bpv7.Bundle) { sent++ },
+		tracker:      newDeliveryTracker(),
+		dedup:        newSendDeduplicator(),
+		stats:        newDeliveryStats(),
+	}
+	for i := 0; i < 3; i++ {
+		bndl := dedupTestBundle(t, "dtn://app/", "dtn://dst/", "hello")
+		if _, _, err := manager.Submit(&bndl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sent != 3 {
+		t.Fatalf("Expected all 3 submissions to be sent without a window, got %d", sent)
+	}
+}
+
+func TestDuplicatePolicyFromString(t *testing.T) {
+	for _, policy := range []DuplicatePolicy{DuplicateCoalesce, DuplicateReject} {
+		if parsed, err := DuplicatePolicyFromString(policy.String()); err != nil || parsed != policy {
+			t.Fatalf("Parsing %q resulted in %v, %v", policy, parsed, err)
+		}
+	}
+	if _, err := DuplicatePolicyFromString("drop"); err == nil {
+		t.Fatal("Parsed an unknown policy")
+	}
+}
+
+func TestSubmitRetryAfterSpoolFull(t *testing.T) {
+	s, err := openSpool(SpoolConfig{Directory: filepath.Join(t.TempDir(), "spool"), MaxBundles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := &Manager{dedup: newSendDeduplicator(), spool: s}
+	manager.SetDeduplication(time.Minute, DuplicateCoalesce)
+
+	// The spool is filled up by earlier submissions, which are still waiting.
+	for i := 0; i < 2; i++ {
+		bndl := spoolTestBundle(t, i)
+		if err := s.push(&bndl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bndl := spoolTestBundle(t, 2)
+	if _, _, err := manager.Submit(&bndl); !errors.Is(err, ErrSpoolFull) {
+		t.Fatalf("Expected ErrSpoolFull, got %v", err)
+	}
+
+	if err := s.pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The application's retry must not be coalesced into the bundle which was never spooled.
+	bundleID, spooled, err := manager.Submit(&bndl)
+	if err != nil {
+		t.Fatal(err)
+	} else if !spooled {
+		t.Fatalf("Retry of %v was not spooled", bundleID)
+	}
+
+	if sources := popAll(t, s); len(sources) != 2 || sources[1] != bndl.PrimaryBlock.SourceNode.String() {
+		t.Fatalf("Spool holds %v, expected the retried bundle last", sources)
+	}
+
+	// A repeated submission of the spooled bundle is still a duplicate.
+	if duplicateID, spooled, err := manager.Submit(&bndl); err != nil || spooled || duplicateID != bundleID {
+		t.Fatalf("Expected %v to be coalesced, got %v, %t, %v", bundleID, duplicateID, spooled, err)
+	}
+}
@@ -0,0 +1,76 @@
+package application_agent
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// BlockMatch requires a bundle to carry an extension block of the TypeCode, e.g., an application's metadata block.
+// If Data is not nil, the block-type-specific data must be equal.
+type BlockMatch struct {
+	TypeCode uint64
+	Data     []byte
+}
+
+// DeliveryFilter narrows the bundles delivered to an application, allowing multiple applications to share one
+// endpoint. The zero DeliveryFilter matches every bundle.
+type DeliveryFilter struct {
+	// Source must match the bundle's source EndpointID, if set, e.g., "dtn://sensor-*/**".
+	Source *bpv7.EndpointPattern
+	// MinPayloadSize and MaxPayloadSize bound the payload's length in bytes, a zero MaxPayloadSize is unlimited.
+	MinPayloadSize uint64
+	MaxPayloadSize uint64
+	// Blocks must all be present.
+	Blocks []BlockMatch
+}
+
+// Matches checks if a bundle passes this DeliveryFilter.
+func (filter DeliveryFilter) Matches(bndl *bpv7.Bundle) bool {
+	if filter.Source != nil && !filter.Source.Match(bndl.PrimaryBlock.SourceNode) {
+		return false
+	}
+
+	if filter.MinPayloadSize > 0 || filter.MaxPayloadSize > 0 {
+		payloadBlock, err := bndl.PayloadBlock()
+		if err != nil {
+			return false
+		}
+		size := uint64(len(payloadBlock.Value.(*bpv7.PayloadBlock).Data()))
+		if size < filter.MinPayloadSize || (filter.MaxPayloadSize > 0 && size > filter.MaxPayloadSize) {
+			return false
+		}
+	}
+
+	for _, match := range filter.Blocks {
+		block, err := bndl.ExtensionBlock(match.TypeCode)
+		if err != nil {
+			return false
+		}
+		if match.Data == nil {
+			continue
+		}
+		if data, err := blockData(block.Value); err != nil || !bytes.Equal(data, match.Data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockData returns an extension block's block-type-specific data, as it is serialised within its canonical block.
+func blockData(block bpv7.ExtensionBlock) ([]byte, error) {
+	var buff bytes.Buffer
+	if err := bpv7.GetExtensionBlockManager().WriteBlock(block, &buff); err != nil {
+		return nil, err
+	}
+	return cboring.ReadByteString(&buff)
+}
+
+func (filter DeliveryFilter) String() string {
+	return fmt.Sprintf("DeliveryFilter(source=%v, payload=%d-%d, blocks=%d)",
+		filter.Source, filter.MinPayloadSize, filter.MaxPayloadSize, len(filter.Blocks))
+}
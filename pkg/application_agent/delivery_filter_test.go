@@ -0,0 +1,55 @@
+package application_agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestDeliveryFilterMatches(t *testing.T) {
+	bndl, err := bpv7.Builder().
+		Source("dtn://sensor-23/temp").
+		Destination("dtn://sink/").
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("21.5")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		filter  RestDeliveryFilter
+		matches bool
+	}{
+		{"zero filter", RestDeliveryFilter{}, true},
+		{"source pattern", RestDeliveryFilter{Source: "dtn://sensor-*/**"}, true},
+		{"exact source", RestDeliveryFilter{Source: "dtn://sensor-23/temp"}, true},
+		{"other source", RestDeliveryFilter{Source: "dtn://actor-*/**"}, false},
+		{"other scheme", RestDeliveryFilter{Source: "ipn:*.*"}, false},
+		{"payload size", RestDeliveryFilter{MinPayloadSize: 1, MaxPayloadSize: 4}, true},
+		{"payload too small", RestDeliveryFilter{MinPayloadSize: 5}, false},
+		{"missing block", RestDeliveryFilter{Blocks: []struct {
+			TypeCode uint64 `json:"type_code"`
+			Data     []byte `json:"data"`
+		}{{TypeCode: 200}}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := test.filter.toDeliveryFilter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matches := filter.Matches(&bndl); matches != test.matches {
+				t.Fatalf("Expected %t, got %t for %v", test.matches, matches, filter)
+			}
+		})
+	}
+
+	if _, err := (&RestDeliveryFilter{Source: "^dtn://sensor-[0-9]+/"}).toDeliveryFilter(); err == nil {
+		t.Fatal("Regular expression was accepted as an endpoint pattern")
+	}
+}
@@ -0,0 +1,184 @@
+package application_agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// LatencyBounds are the upper bounds of a LatencyHistogram's buckets, followed by an unbounded one.
+var LatencyBounds = []time.Duration{
+	time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour,
+}
+
+// LatencyHistogram counts latencies in the buckets of LatencyBounds.
+type LatencyHistogram struct {
+	// Counts has one more element than LatencyBounds, the last one for latencies exceeding all bounds.
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+func (h *LatencyHistogram) observe(latency time.Duration) {
+	if h.Counts == nil {
+		h.Counts = make([]uint64, len(LatencyBounds)+1)
+	}
+
+	bucket := len(LatencyBounds)
+	for i, bound := range LatencyBounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	h.Counts[bucket]++
+	h.Count++
+	h.Sum += latency
+}
+
+// Mean latency, zero without any observation.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+func (h LatencyHistogram) copy() LatencyHistogram {
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
+
+// DestinationStats aggregates the bundles sent by applications to one destination. Deliveries are known from
+// status reports, thus only for bundles requesting them. The latency is the time from sending to the reported
+// delivery.
+type DestinationStats struct {
+	Sent      uint64
+	Delivered uint64
+	Latency   LatencyHistogram
+}
+
+// DeliveryRatio is the fraction of sent bundles reported as delivered.
+func (stats DestinationStats) DeliveryRatio() float64 {
+	if stats.Sent == 0 {
+		return 0
+	}
+	return float64(stats.Delivered) / float64(stats.Sent)
+}
+
+// EndpointStats aggregates the bundles delivered to one local endpoint. The latency is the time from the bundle's
+// creation to its delivery, only known for bundles from nodes with accurate clocks.
+type EndpointStats struct {
+	Delivered uint64
+	Latency   LatencyHistogram
+}
+
+// DeliveryStats are the statistics of all destinations of sent bundles and all local endpoints.
+type DeliveryStats struct {
+	Destinations map[bpv7.EndpointID]DestinationStats
+	Endpoints    map[bpv7.EndpointID]EndpointStats
+}
+
+// deliveryStats collects the DeliveryStats.
+type deliveryStats struct {
+	mutex        sync.Mutex
+	destinations map[bpv7.EndpointID]*DestinationStats
+	endpoints    map[bpv7.EndpointID]*EndpointStats
+}
+
+func newDeliveryStats() *deliveryStats {
+	return &deliveryStats{
+		destinations: make(map[bpv7.EndpointID]*DestinationStats),
+		endpoints:    make(map[bpv7.EndpointID]*EndpointStats),
+	}
+}
+
+func (ds *deliveryStats) destination(eid bpv7.EndpointID) *DestinationStats {
+	stats, ok := ds.destinations[eid]
+	if !ok {
+		stats = &DestinationStats{}
+		ds.destinations[eid] = stats
+	}
+	return stats
+}
+
+// sent counts a bundle sent by an application.
+func (ds *deliveryStats) sent(destination bpv7.EndpointID) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.destination(destination).Sent++
+}
+
+// delivered counts the first reported delivery of a sent bundle.
+func (ds *deliveryStats) delivered(destination bpv7.EndpointID, latency time.Duration) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	stats := ds.destination(destination)
+	stats.Delivered++
+	// A reported delivery time before the sending is due to clock skew between the nodes.
+	stats.Latency.observe(max(latency, 0))
+}
+
+// deliveredLocally counts a bundle delivered to a local endpoint.
+func (ds *deliveryStats) deliveredLocally(bundleDescriptor *store.BundleDescriptor) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	stats, ok := ds.endpoints[bundleDescriptor.Destination]
+	if !ok {
+		stats = &EndpointStats{}
+		ds.endpoints[bundleDescriptor.Destination] = stats
+	}
+	stats.Delivered++
+
+	if creationTimestamp := bundleDescriptor.ID.Timestamp; !creationTimestamp.IsZeroTime() {
+		if latency := clock.Now().Sub(creationTimestamp.DtnTime().Time()); latency >= 0 {
+			stats.Latency.observe(latency)
+		}
+	}
+}
+
+func (ds *deliveryStats) get() DeliveryStats {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	stats := DeliveryStats{
+		Destinations: make(map[bpv7.EndpointID]DestinationStats, len(ds.destinations)),
+		Endpoints:    make(map[bpv7.EndpointID]EndpointStats, len(ds.endpoints)),
+	}
+	for eid, destStats := range ds.destinations {
+		destCopy := *destStats
+		destCopy.Latency = destStats.Latency.copy()
+		stats.Destinations[eid] = destCopy
+	}
+	for eid, endpointStats := range ds.endpoints {
+		endpointCopy := *endpointStats
+		endpointCopy.Latency = endpointStats.Latency.copy()
+		stats.Endpoints[eid] = endpointCopy
+	}
+	return stats
+}
+
+// countLocalDelivery counts a bundle if one of the registered agents serves its destination and returns if so.
+// The caller must hold the stateMutex.
+func (manager *Manager) countLocalDelivery(bundleDescriptor *store.BundleDescriptor) bool {
+	for _, agent := range manager.agents {
+		if bagContainsEndpoint(agent.Endpoints(), []bpv7.EndpointID{bundleDescriptor.Destination}) {
+			manager.stats.deliveredLocally(bundleDescriptor)
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStats returns the delivery ratios and latencies of bundles sent by applications, per destination, and of
+// bundles delivered to local endpoints.
+// This method is thread-safe.
+func (manager *Manager) DeliveryStats() DeliveryStats {
+	return manager.stats.get()
+}
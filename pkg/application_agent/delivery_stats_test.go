@@ -0,0 +1,121 @@
+package application_agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	var histogram LatencyHistogram
+	if mean := histogram.Mean(); mean != 0 {
+		t.Fatalf("Empty histogram has mean %v", mean)
+	}
+
+	// At a bound, within the first and the fourth bucket and beyond all bounds
+	for _, latency := range []time.Duration{time.Second, 500 * time.Millisecond, 5 * time.Minute, 48 * time.Hour} {
+		histogram.observe(latency)
+	}
+
+	expected := []uint64{2, 0, 0, 1, 0, 0, 0, 1}
+	for i, count := range histogram.Counts {
+		if count != expected[i] {
+			t.Fatalf("Expected counts %v, got %v", expected, histogram.Counts)
+		}
+	}
+	if histogram.Count != 4 {
+		t.Fatalf("Expected 4 observations, got %d", histogram.Count)
+	}
+	if mean := histogram.Mean(); mean != (48*time.Hour+5*time.Minute+1500*time.Millisecond)/4 {
+		t.Fatalf("Unexpected mean %v", mean)
+	}
+
+	histogramCopy := histogram.copy()
+	histogramCopy.Counts[0]++
+	if histogram.Counts[0] != 2 {
+		t.Fatalf("Changing a copy changed the histogram")
+	}
+}
+
+func TestDestinationStatsDeliveryRatio(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats DestinationStats
+		ratio float64
+	}{
+		{"nothing sent", DestinationStats{}, 0},
+		{"nothing delivered", DestinationStats{Sent: 4}, 0},
+		{"partially delivered", DestinationStats{Sent: 4, Delivered: 1}, 0.25},
+		{"all delivered", DestinationStats{Sent: 4, Delivered: 4}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ratio := test.stats.DeliveryRatio(); ratio != test.ratio {
+				t.Fatalf("Expected ratio %v, got %v", test.ratio, ratio)
+			}
+		})
+	}
+}
+
+func TestDeliveryStats(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now().Truncate(time.Millisecond))
+	previous := clock.GetClock()
+	clock.SetClock(fakeClock)
+	t.Cleanup(func() { clock.SetClock(previous) })
+
+	dst := bpv7.MustNewEndpointID("dtn://dst/")
+	other := bpv7.MustNewEndpointID("dtn://other/")
+	local := bpv7.MustNewEndpointID("dtn://node/inbox")
+
+	stats := newDeliveryStats()
+	stats.sent(dst)
+	stats.sent(dst)
+	stats.sent(other)
+	stats.delivered(dst, 2*time.Minute)
+	// A delivery reported before the sending due to clock skew
+	stats.delivered(dst, -time.Minute)
+
+	delivery := func(timestamp bpv7.CreationTimestamp) *store.BundleDescriptor {
+		return &store.BundleDescriptor{
+			ID:          bpv7.BundleID{SourceNode: dst, Timestamp: timestamp},
+			Destination: local,
+		}
+	}
+	created := bpv7.DtnTimeFromTime(fakeClock.Now())
+	fakeClock.Advance(30 * time.Second)
+	stats.deliveredLocally(delivery(bpv7.NewCreationTimestamp(created, 0)))
+	// Neither a bundle from a node without an accurate clock nor one created in the future has a latency
+	stats.deliveredLocally(delivery(bpv7.NewCreationTimestamp(bpv7.DtnTimeEpoch, 0)))
+	stats.deliveredLocally(delivery(bpv7.NewCreationTimestamp(bpv7.DtnTimeFromTime(fakeClock.Now().Add(time.Hour)), 0)))
+
+	got := stats.get()
+
+	dstStats := got.Destinations[dst]
+	if dstStats.Sent != 2 || dstStats.Delivered != 2 {
+		t.Fatalf("Expected 2 bundles sent to and delivered at %v, got %+v", dst, dstStats)
+	}
+	if dstStats.Latency.Counts[0] != 1 || dstStats.Latency.Counts[3] != 1 || dstStats.Latency.Sum != 2*time.Minute {
+		t.Fatalf("Expected latencies of zero and two minutes, got %+v", dstStats.Latency)
+	}
+	if otherStats := got.Destinations[other]; otherStats.Sent != 1 || otherStats.Delivered != 0 || otherStats.Latency.Count != 0 {
+		t.Fatalf("Expected 1 undelivered bundle sent to %v, got %+v", other, otherStats)
+	}
+
+	localStats := got.Endpoints[local]
+	if localStats.Delivered != 3 {
+		t.Fatalf("Expected 3 bundles delivered to %v, got %d", local, localStats.Delivered)
+	}
+	if localStats.Latency.Count != 1 || localStats.Latency.Sum != 30*time.Second {
+		t.Fatalf("Expected a single latency of 30s, got %+v", localStats.Latency)
+	}
+
+	got.Destinations[dst].Latency.Counts[0]++
+	delete(got.Endpoints, local)
+	if again := stats.get(); again.Destinations[dst].Latency.Counts[0] != 1 || again.Endpoints[local].Delivered != 3 {
+		t.Fatalf("Changing the returned statistics changed the collected ones")
+	}
+}
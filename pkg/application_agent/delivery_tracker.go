@@ -0,0 +1,234 @@
+package application_agent
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// StatusEvent is a single status information, e.g., a forwarding, reported for a bundle by some node.
+type StatusEvent struct {
+	Node   bpv7.EndpointID
+	Status bpv7.StatusInformationPos
+	Reason bpv7.StatusReportReason
+	// Time is the reported time if the bundle requested it, or otherwise the time the status report was received.
+	Time time.Time
+}
+
+// DeliveryState is the state of a locally originated bundle, as known from incoming status reports.
+type DeliveryState struct {
+	BundleID    bpv7.BundleID
+	Destination bpv7.EndpointID
+	Sent        time.Time
+	Events      []StatusEvent
+
+	// expires is the time when the bundle's lifetime will be exceeded and it is no longer tracked.
+	expires time.Time
+}
+
+// nodesReporting returns all nodes which reported the status.
+func (state DeliveryState) nodesReporting(status bpv7.StatusInformationPos) (nodes []bpv7.EndpointID) {
+	for _, event := range state.Events {
+		if event.Status == status {
+			nodes = append(nodes, event.Node)
+		}
+	}
+	return
+}
+
+// ForwardedBy returns all nodes which reported forwarding the bundle.
+func (state DeliveryState) ForwardedBy() []bpv7.EndpointID {
+	return state.nodesReporting(bpv7.ForwardedBundle)
+}
+
+// DeletedBy returns all nodes which reported deleting the bundle.
+func (state DeliveryState) DeletedBy() []bpv7.EndpointID {
+	return state.nodesReporting(bpv7.DeletedBundle)
+}
+
+// deletedBy checks if the node reported deleting the bundle.
+func (state DeliveryState) deletedBy(node bpv7.EndpointID) bool {
+	for _, event := range state.Events {
+		if event.Status == bpv7.DeletedBundle && event.Node == node {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveredAt returns the time of the first reported delivery, if there was one.
+func (state DeliveryState) DeliveredAt() (deliveredAt time.Time, delivered bool) {
+	for _, event := range state.Events {
+		if event.Status == bpv7.DeliveredBundle && (!delivered || event.Time.Before(deliveredAt)) {
+			deliveredAt = event.Time
+			delivered = true
+		}
+	}
+	return
+}
+
+// DeliveryReportReceiver is an optional interface for ApplicationAgents, which are notified of every status report
+// for a bundle sent from one of their endpoints.
+type DeliveryReportReceiver interface {
+	DeliveryReport(state DeliveryState)
+}
+
+// DeletionReceiver is an optional interface for ApplicationAgents, which are notified once a bundle sent from one of
+// their endpoints is deleted undelivered by some node, either this node or another one sending a status report. The
+// event carries the deleting node and its reason, e.g., bpv7.LifetimeExpired, allowing an application to retry.
+type DeletionReceiver interface {
+	BundleDeleted(state DeliveryState, event StatusEvent)
+}
+
+// maxTrackedBundles bounds the number of tracked bundles. Beyond, the states of the earliest expiring bundles are
+// forgotten first.
+const maxTrackedBundles = 65536
+
+// deliveryTracker keeps the DeliveryState of all locally originated bundles until their lifetime is exceeded.
+type deliveryTracker struct {
+	mutex  sync.Mutex
+	states map[string]*DeliveryState // scrubbed BundleID string -> state
+	expiry expiryHeap
+	limit  int
+}
+
+func newDeliveryTracker() *deliveryTracker {
+	return &deliveryTracker{
+		states: make(map[string]*DeliveryState),
+		limit:  maxTrackedBundles,
+	}
+}
+
+// trackedExpiry is an entry of the expiryHeap, referencing the state it was pushed for.
+type trackedExpiry struct {
+	id    string
+	state *DeliveryState
+}
+
+// expiryHeap orders the tracked states by their expiry, the earliest first.
+type expiryHeap []trackedExpiry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].state.expires.Before(h[j].state.expires) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)        { *h = append(*h, x.(trackedExpiry)) }
+func (h *expiryHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// forgetEarliest removes the earliest expiring state, unless it was replaced by tracking its bundle again.
+func (tracker *deliveryTracker) forgetEarliest() {
+	entry := heap.Pop(&tracker.expiry).(trackedExpiry)
+	if tracker.states[entry.id] == entry.state {
+		delete(tracker.states, entry.id)
+	}
+}
+
+// track starts tracking a newly sent bundle and forgets expired ones, as well as the earliest expiring ones beyond
+// the limit.
+func (tracker *deliveryTracker) track(bndl *bpv7.Bundle) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	now := clock.Now()
+	for tracker.expiry.Len() > 0 && now.After(tracker.expiry[0].state.expires) {
+		tracker.forgetEarliest()
+	}
+
+	id := bndl.ID().Scrub()
+	state := &DeliveryState{
+		BundleID:    id,
+		Destination: bndl.PrimaryBlock.Destination,
+		Sent:        now,
+		expires:     now.Add(time.Duration(bndl.PrimaryBlock.Lifetime) * time.Millisecond),
+	}
+	tracker.states[id.String()] = state
+	heap.Push(&tracker.expiry, trackedExpiry{id: id.String(), state: state})
+
+	for tracker.expiry.Len() > tracker.limit {
+		tracker.forgetEarliest()
+	}
+}
+
+// update adds a status report's information to the referenced bundle's state, if it is tracked. firstDelivery
+// indicates that this report is the first one of the bundle's delivery. deletion is the report's deletion event,
+// unless the reporting node's deletion is already known.
+func (tracker *deliveryTracker) update(reportingNode bpv7.EndpointID, report *bpv7.StatusReport) (state DeliveryState, firstDelivery bool, deletion *StatusEvent, ok bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	trackedState, ok := tracker.states[report.RefBundle.Scrub().String()]
+	if !ok {
+		return
+	}
+
+	_, delivered := trackedState.DeliveredAt()
+
+	for _, sip := range report.StatusInformations() {
+		event := StatusEvent{
+			Node:   reportingNode,
+			Status: sip,
+			Reason: report.ReportReason,
+			Time:   clock.Now(),
+		}
+		if item := report.StatusInformation[sip]; item.StatusRequested {
+			event.Time = item.Time.Time()
+		}
+		if sip == bpv7.DeletedBundle {
+			if trackedState.deletedBy(reportingNode) {
+				continue
+			}
+			deletion = &event
+		}
+		trackedState.Events = append(trackedState.Events, event)
+	}
+
+	_, nowDelivered := trackedState.DeliveredAt()
+	return trackedState.copy(), !delivered && nowDelivered, deletion, true
+}
+
+// deleted adds a node's deletion of a bundle, if it is tracked and the node's deletion is not already known, e.g., from
+// a status report. The returned event is nil otherwise.
+func (tracker *deliveryTracker) deleted(node bpv7.EndpointID, bundleID bpv7.BundleID, reason bpv7.StatusReportReason) (
+	state DeliveryState, deletion *StatusEvent) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	trackedState, ok := tracker.states[bundleID.Scrub().String()]
+	if !ok || trackedState.deletedBy(node) {
+		return
+	}
+
+	event := StatusEvent{
+		Node:   node,
+		Status: bpv7.DeletedBundle,
+		Reason: reason,
+		Time:   clock.Now(),
+	}
+	trackedState.Events = append(trackedState.Events, event)
+	return trackedState.copy(), &event
+}
+
+// get returns a copy of the state of the bundle, identified by its scrubbed BundleID's string, if it is tracked.
+func (tracker *deliveryTracker) get(idString string) (state DeliveryState, ok bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	trackedState, ok := tracker.states[idString]
+	if !ok {
+		return
+	}
+	return trackedState.copy(), true
+}
+
+func (state *DeliveryState) copy() DeliveryState {
+	stateCopy := *state
+	stateCopy.Events = append([]StatusEvent(nil), state.Events...)
+	return stateCopy
+}
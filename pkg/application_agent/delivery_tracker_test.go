@@ -0,0 +1,166 @@
+package application_agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+func trackerTestBundle(t *testing.T, source string, lifetime time.Duration) bpv7.Bundle {
+	t.Helper()
+
+	bndl, err := bpv7.Builder().
+		Source(source).
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(lifetime).
+		PayloadBlock([]byte("hello")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+func TestDeliveryTrackerExpiry(t *testing.T) {
+	fc := clock.NewFakeClock(time.Now())
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	tracker := newDeliveryTracker()
+
+	short := trackerTestBundle(t, "dtn://src/short", time.Minute)
+	long := trackerTestBundle(t, "dtn://src/long", time.Hour)
+	tracker.track(&short)
+	tracker.track(&long)
+
+	fc.Advance(2 * time.Minute)
+	other := trackerTestBundle(t, "dtn://src/other", time.Hour)
+	tracker.track(&other)
+
+	if _, ok := tracker.get(short.ID().Scrub().String()); ok {
+		t.Fatal("Expired bundle is still tracked")
+	}
+	for _, bndl := range []bpv7.Bundle{long, other} {
+		if _, ok := tracker.get(bndl.ID().Scrub().String()); !ok {
+			t.Fatalf("Bundle %v is not tracked", bndl.ID())
+		}
+	}
+	if len(tracker.states) != 2 || tracker.expiry.Len() != 2 {
+		t.Fatalf("Expected 2 tracked bundles, got %d states and %d expiries", len(tracker.states), tracker.expiry.Len())
+	}
+}
+
+func TestDeliveryTrackerLimit(t *testing.T) {
+	tracker := newDeliveryTracker()
+	tracker.limit = 3
+
+	bundles := make([]bpv7.Bundle, 5)
+	for i := range bundles {
+		// the first bundles expire last, such that the later ones are forgotten first
+		bundles[i] = trackerTestBundle(t, fmt.Sprintf("dtn://src/%d", i), time.Duration(10-i)*time.Hour)
+		tracker.track(&bundles[i])
+	}
+
+	if len(tracker.states) != tracker.limit || tracker.expiry.Len() != tracker.limit {
+		t.Fatalf("Expected %d tracked bundles, got %d states and %d expiries",
+			tracker.limit, len(tracker.states), tracker.expiry.Len())
+	}
+	for i, bndl := range bundles {
+		_, ok := tracker.get(bndl.ID().Scrub().String())
+		if expected := i < tracker.limit; ok != expected {
+			t.Errorf("Bundle %d: expected tracked %t, got %t", i, expected, ok)
+		}
+	}
+}
+
+func TestDeliveryTrackerRetrack(t *testing.T) {
+	tracker := newDeliveryTracker()
+	tracker.limit = 1
+
+	bndl := trackerTestBundle(t, "dtn://src/", time.Hour)
+	tracker.track(&bndl)
+	tracker.track(&bndl)
+
+	if _, ok := tracker.get(bndl.ID().Scrub().String()); !ok {
+		t.Fatal("Bundle tracked again is no longer tracked")
+	}
+}
+
+// deletionAgent is an ApplicationAgent recording the deletions of bundles sent from its endpoint.
+type deletionAgent struct {
+	endpoint  bpv7.EndpointID
+	deletions []StatusEvent
+}
+
+func (agent *deletionAgent) Endpoints() []bpv7.EndpointID { return []bpv7.EndpointID{agent.endpoint} }
+
+func (agent *deletionAgent) Deliver(*store.BundleDescriptor) error { return nil }
+
+func (agent *deletionAgent) Shutdown() {}
+
+func (agent *deletionAgent) BundleDeleted(_ DeliveryState, event StatusEvent) {
+	agent.deletions = append(agent.deletions, event)
+}
+
+func TestReportDeletion(t *testing.T) {
+	ownNode := bpv7.MustNewEndpointID("dtn://node/")
+	otherNode := bpv7.MustNewEndpointID("dtn://other/")
+
+	sender := &deletionAgent{endpoint: bpv7.MustNewEndpointID("dtn://node/app")}
+	bystander := &deletionAgent{endpoint: bpv7.MustNewEndpointID("dtn://node/other")}
+	manager := &Manager{
+		agents:  []ApplicationAgent{sender, bystander},
+		tracker: newDeliveryTracker(),
+		stats:   newDeliveryStats(),
+	}
+
+	bndl := trackerTestBundle(t, "dtn://node/app", time.Hour)
+	manager.tracker.track(&bndl)
+
+	untracked := trackerTestBundle(t, "dtn://node/untracked", time.Hour)
+	manager.ReportDeletion(ownNode, untracked.ID(), bpv7.LifetimeExpired)
+	if len(sender.deletions) != 0 {
+		t.Fatalf("Deletion of an untracked bundle was reported: %v", sender.deletions)
+	}
+
+	manager.ReportDeletion(ownNode, bndl.ID(), bpv7.LifetimeExpired)
+	if len(sender.deletions) != 1 {
+		t.Fatalf("Expected 1 deletion, got %v", sender.deletions)
+	}
+	if event := sender.deletions[0]; event.Node != ownNode || event.Status != bpv7.DeletedBundle ||
+		event.Reason != bpv7.LifetimeExpired {
+		t.Fatalf("Unexpected deletion event %+v", event)
+	}
+	if len(bystander.deletions) != 0 {
+		t.Fatalf("Agent of another endpoint was notified: %v", bystander.deletions)
+	}
+
+	// The node's own deletion is only reported once, even if its status report arrives afterwards
+	manager.ReportDeletion(ownNode, bndl.ID(), bpv7.DepletedStorage)
+	ownReport := bpv7.NewStatusReport(bndl, bpv7.DeletedBundle, bpv7.LifetimeExpired, bpv7.DtnTimeNow())
+	manager.ReceiveStatusReport(ownNode, ownReport)
+	if len(sender.deletions) != 1 {
+		t.Fatalf("Known deletion was reported again: %v", sender.deletions)
+	}
+
+	// Another node's deletion is reported, but only once as well
+	otherReport := bpv7.NewStatusReport(bndl, bpv7.DeletedBundle, bpv7.HopLimitExceeded, bpv7.DtnTimeNow())
+	manager.ReceiveStatusReport(otherNode, otherReport)
+	manager.ReportDeletion(otherNode, bndl.ID(), bpv7.HopLimitExceeded)
+	if len(sender.deletions) != 2 {
+		t.Fatalf("Expected 2 deletions, got %v", sender.deletions)
+	}
+	if event := sender.deletions[1]; event.Node != otherNode || event.Reason != bpv7.HopLimitExceeded {
+		t.Fatalf("Unexpected deletion event %+v", event)
+	}
+
+	state, _ := manager.DeliveryState(bndl.ID())
+	if len(state.Events) != 2 {
+		t.Fatalf("Expected 2 tracked events, got %v", state.Events)
+	}
+}
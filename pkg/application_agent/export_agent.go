@@ -0,0 +1,178 @@
+package application_agent
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// ExportAgent is an Application Agent writing the payload of every bundle delivered to its endpoints into a
+// directory, e.g., for archival or offline processing pipelines.
+//
+// Each payload is stored in a file named by the hash of its bundle's ID with a ".payload" suffix, next to a JSON
+// sidecar with a ".json" suffix, described by ExportSidecar. Both files are written to temporary files first and
+// renamed afterwards, the sidecar last. Thus, a pipeline watching for new sidecars always finds a complete payload.
+type ExportAgent struct {
+	directory string
+	endpoints []bpv7.EndpointID
+}
+
+// ExportSidecar describes an exported payload's bundle, stored as a JSON sidecar next to it.
+type ExportSidecar struct {
+	BundleID       string    `json:"bundle_id"`
+	Source         string    `json:"source"`
+	Destination    string    `json:"destination"`
+	ReportTo       string    `json:"report_to"`
+	CreationTime   time.Time `json:"creation_time"`
+	SequenceNumber uint64    `json:"sequence_number"`
+	Lifetime       string    `json:"lifetime"`
+	Expires        time.Time `json:"expires"`
+	Delivered      time.Time `json:"delivered"`
+	PayloadFile    string    `json:"payload_file"`
+	PayloadSize    int       `json:"payload_size"`
+
+	// ContentType and Filename of the payload from the optional ContentTypeBlock, omitted if the block is absent.
+	ContentType string `json:"content_type,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+
+	// Traversal information of the optional extension blocks, omitted if the block is absent.
+	PreviousNode  string               `json:"previous_node,omitempty"`
+	HopCount      *bpv7.HopCountBlock  `json:"hop_count,omitempty"`
+	BundleAge     *uint64              `json:"bundle_age_ms,omitempty"`
+	TravelHistory []ExportTravelRecord `json:"travel_history,omitempty"`
+}
+
+// ExportTravelRecord is a node which forwarded an exported bundle, from its TravelHistoryBlock.
+type ExportTravelRecord struct {
+	Node string    `json:"node"`
+	Time time.Time `json:"time"`
+}
+
+// NewExportAgent creates an ExportAgent for the endpoints, storing payloads in the directory.
+func NewExportAgent(directory string, endpoints []bpv7.EndpointID) (*ExportAgent, error) {
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, err
+	}
+
+	return &ExportAgent{
+		directory: directory,
+		endpoints: endpoints,
+	}, nil
+}
+
+func (ea *ExportAgent) Endpoints() []bpv7.EndpointID {
+	return ea.endpoints
+}
+
+// Deliver exports a bundle's payload if it is addressed to one of this agent's endpoints. Bundles already exported,
+// e.g., received again from another peer, are skipped.
+func (ea *ExportAgent) Deliver(bundleDescriptor *store.BundleDescriptor) error {
+	if !bagContainsEndpoint(ea.endpoints, []bpv7.EndpointID{bundleDescriptor.Destination}) {
+		return nil
+	}
+
+	name := fmt.Sprintf("%x", sha256.Sum256([]byte(bundleDescriptor.IDString)))
+	sidecarFile := filepath.Join(ea.directory, name+".json")
+	if _, err := os.Stat(sidecarFile); err == nil {
+		log.WithField("bundle", bundleDescriptor.ID).Debug("Export Application Agent skips already exported bundle")
+		return nil
+	}
+
+	bndl, err := bundleDescriptor.Load()
+	if err != nil {
+		return err
+	}
+	payloadBlock, err := bndl.PayloadBlock()
+	if err != nil {
+		return err
+	}
+	payload := payloadBlock.Value.(*bpv7.PayloadBlock).Data()
+
+	sidecar := newExportSidecar(bndl, name+".payload", len(payload))
+	sidecarData, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(ea.directory, sidecar.PayloadFile), payload); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(sidecarFile, sidecarData); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":  bundleDescriptor.ID,
+		"sidecar": sidecarFile,
+	}).Info("Export Application Agent exported payload")
+	return nil
+}
+
+// newExportSidecar collects the metadata of a delivered bundle.
+func newExportSidecar(bndl bpv7.Bundle, payloadFile string, payloadSize int) ExportSidecar {
+	primary := bndl.PrimaryBlock
+	lifetime := time.Duration(primary.Lifetime) * time.Millisecond
+
+	sidecar := ExportSidecar{
+		BundleID:       bndl.ID().String(),
+		Source:         primary.SourceNode.String(),
+		Destination:    primary.Destination.String(),
+		ReportTo:       primary.ReportTo.String(),
+		CreationTime:   primary.CreationTimestamp.DtnTime().Time(),
+		SequenceNumber: primary.CreationTimestamp.SequenceNumber(),
+		Lifetime:       lifetime.String(),
+		Expires:        primary.CreationTimestamp.DtnTime().Time().Add(lifetime),
+		Delivered:      clock.Now(),
+		PayloadFile:    payloadFile,
+		PayloadSize:    payloadSize,
+	}
+
+	if block, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeContentTypeBlock); err == nil {
+		ctb := block.Value.(*bpv7.ContentTypeBlock)
+		sidecar.ContentType, sidecar.Filename = ctb.ContentType, ctb.Filename
+	}
+	if block, err := bndl.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
+		sidecar.PreviousNode = block.Value.(*bpv7.PreviousNodeBlock).Endpoint().String()
+	}
+	if block, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock); err == nil {
+		sidecar.HopCount = block.Value.(*bpv7.HopCountBlock)
+	}
+	if block, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeBundleAgeBlock); err == nil {
+		age := block.Value.(*bpv7.BundleAgeBlock).Age()
+		sidecar.BundleAge = &age
+	}
+	if block, err := bndl.ExtensionBlock(bpv7.ExtBlockTypeTravelHistoryBlock); err == nil {
+		for _, entry := range block.Value.(*bpv7.TravelHistoryBlock).Entries {
+			sidecar.TravelHistory = append(sidecar.TravelHistory, ExportTravelRecord{
+				Node: entry.Node.String(),
+				Time: entry.Time.Time(),
+			})
+		}
+	}
+
+	return sidecar
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and renames it afterwards.
+func writeFileAtomic(filename string, data []byte) error {
+	tmpFile := filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filename)
+}
+
+func (ea *ExportAgent) Shutdown() {}
+
+func (ea *ExportAgent) String() string {
+	return fmt.Sprintf("ExportAgent(%s)", ea.directory)
+}
@@ -0,0 +1,124 @@
+package application_agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// exportTestDescriptor wraps a bundle to the destination in a BundleDescriptor, whose Load does not need a store.
+func exportTestDescriptor(t *testing.T, source, destination string, payload []byte) *store.BundleDescriptor {
+	t.Helper()
+
+	bndl, err := bpv7.Builder().
+		Source(source).
+		Destination(destination).
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		ContentTypeBlock("text/plain", "hello.txt").
+		PreviousNodeBlock("dtn://upstream/").
+		HopCountBlock(16).
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &store.BundleDescriptor{
+		ID:          bndl.ID(),
+		IDString:    bndl.ID().String(),
+		Destination: bndl.PrimaryBlock.Destination,
+		Bundle:      &bndl,
+	}
+}
+
+// readSidecars returns the exported sidecars and fails for leftover temporary files.
+func readSidecars(t *testing.T, directory string) (sidecars []ExportSidecar) {
+	t.Helper()
+
+	files, err := os.ReadDir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".tmp") {
+			t.Fatalf("Temporary file %s was left behind", file.Name())
+		} else if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(directory, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sidecar ExportSidecar
+		if err := json.Unmarshal(data, &sidecar); err != nil {
+			t.Fatal(err)
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return
+}
+
+func TestExportAgentDeliver(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "export")
+	agent, err := NewExportAgent(directory, []bpv7.EndpointID{bpv7.MustNewEndpointID("dtn://node/export")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte("hello export")
+	bd := exportTestDescriptor(t, "dtn://src/", "dtn://node/export", payload)
+	if err := agent.Deliver(bd); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecars := readSidecars(t, directory)
+	if len(sidecars) != 1 {
+		t.Fatalf("Expected one sidecar, got %d", len(sidecars))
+	}
+	sidecar := sidecars[0]
+	if sidecar.BundleID != bd.IDString || sidecar.Source != "dtn://src/" || sidecar.Destination != "dtn://node/export" {
+		t.Fatalf("Sidecar describes another bundle: %+v", sidecar)
+	}
+	if sidecar.ContentType != "text/plain" || sidecar.Filename != "hello.txt" {
+		t.Fatalf("Sidecar lacks the content type: %+v", sidecar)
+	}
+	if sidecar.PreviousNode != "dtn://upstream/" || sidecar.HopCount == nil || sidecar.HopCount.Limit != 16 {
+		t.Fatalf("Sidecar lacks the traversal information: %+v", sidecar)
+	}
+	if sidecar.Lifetime != time.Hour.String() || sidecar.PayloadSize != len(payload) {
+		t.Fatalf("Sidecar has a wrong lifetime or payload size: %+v", sidecar)
+	}
+
+	exported, err := os.ReadFile(filepath.Join(directory, sidecar.PayloadFile))
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(exported, payload) {
+		t.Fatalf("Exported payload %q differs from %q", exported, payload)
+	}
+
+	// a bundle delivered again, e.g., received from another peer, is not exported twice
+	if err := agent.Deliver(bd); err != nil {
+		t.Fatal(err)
+	}
+	if again := readSidecars(t, directory); len(again) != 1 || !again[0].Delivered.Equal(sidecar.Delivered) {
+		t.Fatalf("Bundle was exported again: %+v", again)
+	}
+
+	// bundles for other endpoints are ignored
+	if err := agent.Deliver(exportTestDescriptor(t, "dtn://src/", "dtn://node/other", payload)); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := os.ReadDir(directory); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 2 {
+		t.Fatalf("Expected the payload and sidecar of a single bundle, got %d files", len(files))
+	}
+}
@@ -0,0 +1,42 @@
+package application_agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// NameResolver maps a human-friendly name, e.g., "alice", to its endpoint ID.
+type NameResolver func(name string) (bpv7.EndpointID, bool)
+
+// nameResolver resolves names within the REST build requests, if set.
+var nameResolver NameResolver
+
+// SetNameResolver allows applications to address names instead of endpoint IDs. This must be called before the
+// application agents are started.
+func SetNameResolver(resolver NameResolver) {
+	nameResolver = resolver
+}
+
+// resolveNames replaces the names of a build request's endpoint arguments by their endpoint IDs. As names cannot
+// contain a colon, all other values are left as they are.
+func resolveNames(args map[string]interface{}) error {
+	if nameResolver == nil {
+		return nil
+	}
+
+	for _, field := range []string{"destination", "report_to"} {
+		name, ok := args[field].(string)
+		if !ok || strings.Contains(name, ":") {
+			continue
+		}
+
+		eid, ok := nameResolver(name)
+		if !ok {
+			return fmt.Errorf("unknown name %q for %s", name, field)
+		}
+		args[field] = eid.String()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package application_agent
+
+import (
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// Registration is a client's registration for an endpoint at an ApplicationAgent, e.g., of a RestAgent.
+type Registration struct {
+	// ID identifies the registration within its agent, e.g., a RestAgent client's UUID.
+	ID       string
+	Endpoint bpv7.EndpointID
+	// Agent names the kind of the ApplicationAgent, e.g., "rest".
+	Agent string
+	// Filtered registrations only receive bundles passing their DeliveryFilter.
+	Filtered bool
+	// Pending is the number of delivered bundles not fetched by the client yet.
+	Pending    int
+	Registered time.Time
+	// LastSeen is the time of the client's latest request.
+	LastSeen time.Time
+}
+
+// Registrar is an ApplicationAgent managing the registrations of its clients. A stale registration, e.g., of a
+// crashed client, keeps swallowing the bundles delivered to its endpoint. Thus, operators might list and deregister
+// them through the Manager.
+type Registrar interface {
+	// Registrations lists all current registrations.
+	Registrations() []Registration
+
+	// Deregister removes a registration by its ID, dropping its undelivered bundles. It returns false for an unknown
+	// ID.
+	Deregister(id string) bool
+}
+
+// Registrations lists the registrations of all Registrars, ordered by their ID.
+func (manager *Manager) Registrations() []Registration {
+	manager.stateMutex.RLock()
+	defer manager.stateMutex.RUnlock()
+
+	registrations := make([]Registration, 0)
+	for _, agent := range manager.agents {
+		if registrar, ok := agent.(Registrar); ok {
+			registrations = append(registrations, registrar.Registrations()...)
+		}
+	}
+	sort.Slice(registrations, func(i, j int) bool { return registrations[i].ID < registrations[j].ID })
+	return registrations
+}
+
+// Registration looks up a registration by its ID.
+func (manager *Manager) Registration(id string) (Registration, bool) {
+	for _, registration := range manager.Registrations() {
+		if registration.ID == id {
+			return registration, true
+		}
+	}
+	return Registration{}, false
+}
+
+// Deregister forcibly removes a registration by its ID. It returns false for an unknown ID.
+func (manager *Manager) Deregister(id string) bool {
+	manager.stateMutex.RLock()
+	defer manager.stateMutex.RUnlock()
+
+	for _, agent := range manager.agents {
+		if registrar, ok := agent.(Registrar); ok && registrar.Deregister(id) {
+			log.WithField("registration", id).Info("Registration was removed")
+			return true
+		}
+	}
+	return false
+}
+
+// DeregisterIdle forcibly removes all registrations without any request for at least the idle duration and returns
+// them.
+func (manager *Manager) DeregisterIdle(idle time.Duration) []Registration {
+	removed := make([]Registration, 0)
+	for _, registration := range manager.Registrations() {
+		if clock.Now().Sub(registration.LastSeen) >= idle && manager.Deregister(registration.ID) {
+			removed = append(removed, registration)
+		}
+	}
+	return removed
+}
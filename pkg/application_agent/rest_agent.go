@@ -7,15 +7,19 @@ package application_agent
 
 import (
 	"crypto/rand"
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
 	"github.com/dtn7/dtn7-go/pkg/store"
 )
 
@@ -26,7 +30,8 @@ import (
 // a client should unregister itself.
 //
 // This is all done by HTTP POSTing JSON objects. Their structure is described in `rest_agent_messages.go` by the types
-// with the `Rest` prefix in their names.
+// with the `Rest` prefix in their names. The same messages are specified as OpenAPI document, served at
+// /openapi.json, from which clients in other languages are generated, see RestOpenAPI.
 //
 // A possible conversation follows as an example.
 //
@@ -34,6 +39,15 @@ import (
 //	// -> {"endpoint_id":"dtn://foo/bar"}
 //	// <- {"error":"","uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f"}
 //
+//	//    A client acknowledging its fetched bundles, see the following fetch, registers to do so.
+//	// -> {"endpoint_id":"dtn://foo/bar","acknowledge":true}
+//
+//	//    Multiple clients sharing an endpoint might only register for some bundles, e.g., from certain sources,
+//	//    of a payload size range, or carrying an extension block of some type code and, optionally, data.
+//	// -> {"endpoint_id":"dtn://foo/bar","filter":{"source":"dtn://sensor-*/**","min_payload_size":0,
+//	//      "max_payload_size":1024,"blocks":[{"type_code":200,"data":"oWR0eXBlZHRlbXA="}]}}
+//	// <- {"error":"","uuid":"3f5d0e62-8b1c-4c3a-a7ad-0e44b2b6f1d9"}
+//
 //	// 2. Fetching bundles for our client, POST to /fetch
 //	//    There will be to answers, one with new bundles and one without
 //	// -> {"uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f"}
@@ -51,8 +65,16 @@ import (
 //	//          {"blockNumber":1,"blockTypeCode":1,"blockControlFlags":null,"data":"S2hlbGxvIHdvcmxk"}
 //	//        ]
 //	//      }
-//	//    ]}
-//	// <- {"error":"","bundles":[]}
+//	//    ],"bundle_ids":["dtn://sender/-640104726000-0"]}
+//	// <- {"error":"","bundles":[],"bundle_ids":[]}
+//
+//	//    An acknowledging client confirms the bundles it processed by their IDs with its next fetch. Until then, they
+//	//    stay in its mailbox and are fetched again, e.g., if the previous response was lost.
+//	// -> {"uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f","ack":["dtn://sender/-640104726000-0"]}
+//
+//	//    Deletions of bundles sent by our client are part of the answer, e.g., when one's lifetime expired.
+//	// <- {"error":"","bundles":[],"deleted":[{"bundle_id":"dtn://foo/bar-702912726000-0","node":"dtn://relay/",
+//	//     "reason":"Lifetime expired","reason_code":1,"time":"2022-04-12T13:32:06Z"}]}
 //
 //	// 3. Create and dispatch a new bundle, POST to /build
 //	// -> {
@@ -62,54 +84,143 @@ import (
 //	//        "source": "dtn://foo/bar",
 //	//        "creation_timestamp_now": 1,
 //	//        "lifetime": "24h",
+//	//        "bundle_ctrl_flags": ["REQUESTED_DELIVERY_STATUS_REPORT"],
+//	//        "travel_history_block": 16,
+//	//        "priority_block": "expedited",
+//	//        "content_type_block": {"content_type": "text/plain; charset=utf-8", "filename": "hello.txt"},
 //	//        "payload_block": "hello world"
 //	//      }
 //	//    }
-//	// <- {"error":""}
+//	// <- {"error":"","bundle_id":"dtn://foo/bar-702912726000-0"}
+//
+//	//    The optional content_type_block describes the payload. A receiving client finds it within the fetched
+//	//    bundle's canonicalBlocks as {"blockTypeCode":200,"data":{"content_type":"...","filename":"..."}}.
+//
+//	//    If name resolution is enabled, the destination and report_to fields might also be names, e.g., "alice".
+//
+//	//    If send-side deduplication is configured, an identical payload to the same destination within its window
+//	//    either results in the earlier bundle_id or in an error, depending on the policy.
 //
-//	// 4. Unregister the client, POST to /unregister
+//	//    If a spool is configured and the node cannot take the bundle right now, e.g., as its store is full, the
+//	//    bundle is spooled and sent later. The spool's backlog lets clients slow down before it is full, which is
+//	//    reported as an error.
+//	// <- {"error":"","bundle_id":"dtn://foo/bar-702912726000-0","spooled":true,"spool_backlog":3}
+//
+//	// 4. Query the delivery state of a sent bundle, as reported by status reports, POST to /status
+//	//    Status reports must be requested by the bundle_ctrl_flags and reach this node if the report_to field is
+//	//    its node ID, e.g., "dtn://foo/".
+//	// -> {"uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f","bundle_id":"dtn://foo/bar-702912726000-0"}
+//	// <- {"error":"","sent":"2022-04-11T13:32:06Z","forwarded_by":["dtn://relay/"],"delivered":true,
+//	//     "delivered_at":"2022-04-11T13:35:41Z","deleted_by":[]}
+//
+//	// 5. Fetch a bundle still stored by the node, sent by or addressed to our client, POST to /bundle
+//	//    The bundle itself, including its payload, is only part of the answer if requested.
+//	// -> {"uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f","bundle_id":"dtn://foo/bar-702912726000-0","payload":false}
+//	// <- {"error":"","source":"dtn://foo/bar","destination":"dtn://dst/","report_to":"dtn://foo/bar",
+//	//     "priority":"expedited","expires":"2022-04-12T13:32:06Z"}
+//
+//	// 6. Unregister the client, POST to /unregister
 //	// -> {"uuid":"75be76e2-23fc-da0e-eeb8-4773f84a9d2f"}
 //	// <- {"error":""}
+//
+// A client which never unregisters, e.g., after a crash, keeps its registration and its undelivered bundles. Operators
+// find such idle registrations by their latest request and remove them, see Registrations and Deregister.
+//
+// Delivered bundles wait in the client's mailbox until its next /fetch, e.g., after it reconnected, bounded by
+// SetMailboxLimit. The bundles of an acknowledging client stay until it acknowledges them. Otherwise, they are removed
+// by the /fetch, unless its response cannot be written. Then, they are put back for the following /fetch.
 type RestAgent struct {
 	router *mux.Router
 
-	// map UUIDs to EIDs and received bundles
+	// map UUIDs to EIDs, optional delivery filters and received bundles
 	clients      sync.Map // uuid[string] -> bpv7.EndpointID
+	filters      sync.Map // uuid[string] -> DeliveryFilter
+	acknowledges sync.Map // uuid[string] -> struct{}, clients acknowledging their fetched bundles
+	activity     sync.Map // uuid[string] -> restActivity
 	mailboxes    map[string]map[bpv7.BundleID]bpv7.Bundle
+	deletions    map[string][]RestDeleted
+	mailboxLimit int
 	mailboxMutex sync.Mutex
 }
 
+// RestOpenAPI is the OpenAPI document specifying the RestAgent's endpoints and messages.
+//
+//go:generate go run ../../clients/generate -spec rest_agent_openapi.json -out ../../clients
+//go:embed rest_agent_openapi.json
+var RestOpenAPI []byte
+
+// restActivity is the time of a client's registration and of its latest request.
+type restActivity struct {
+	registered time.Time
+	lastSeen   time.Time
+}
+
 // NewRestAgent creates a new RESTful Application Agent.
 func NewRestAgent(router *mux.Router) (ra *RestAgent) {
 	ra = &RestAgent{
 		router:    router,
 		mailboxes: make(map[string]map[bpv7.BundleID]bpv7.Bundle),
+		deletions: make(map[string][]RestDeleted),
 	}
 
 	ra.router.HandleFunc("/register", ra.handleRegister).Methods(http.MethodPost)
 	ra.router.HandleFunc("/unregister", ra.handleUnregister).Methods(http.MethodPost)
 	ra.router.HandleFunc("/fetch", ra.handleFetch).Methods(http.MethodPost)
 	ra.router.HandleFunc("/build", ra.handleBuild).Methods(http.MethodPost)
+	ra.router.HandleFunc("/status", ra.handleStatus).Methods(http.MethodPost)
+	ra.router.HandleFunc("/bundle", ra.handleBundle).Methods(http.MethodPost)
+	ra.router.HandleFunc("/openapi.json", ra.handleOpenAPI).Methods(http.MethodGet)
 
 	return ra
 }
 
-// Deliver checks incoming BundleMessages and puts them inbox.
+// ErrMailboxFull is returned by RestAgent.Deliver if a client's mailbox cannot take the bundle, see SetMailboxLimit.
+var ErrMailboxFull = errors.New("REST client's mailbox is full")
+
+// SetMailboxLimit bounds the bundles waiting for each client's /fetch, including the unacknowledged ones. A full
+// mailbox refuses further bundles for its client by an ErrMailboxFull, while the bundle is delivered to other clients.
+// The refused bundle is kept and delivered again by a later dispatch, see Manager.Redelivery.
+// Zero, the default, is unbounded.
+func (ra *RestAgent) SetMailboxLimit(limit int) {
+	ra.mailboxMutex.Lock()
+	defer ra.mailboxMutex.Unlock()
+
+	ra.mailboxLimit = limit
+}
+
+// Deliver checks incoming BundleMessages and puts them inbox. An ErrMailboxFull names the clients whose mailboxes
+// were full.
 func (ra *RestAgent) Deliver(bundleDescriptor *store.BundleDescriptor) error {
 	var uuids []string
 	ra.clients.Range(func(k, v interface{}) bool {
 		if bundleDescriptor.Destination == v.(bpv7.EndpointID) {
 			uuids = append(uuids, k.(string))
 		}
-		return false // multiple clients might be registered for some endpoint
+		return true // multiple clients might be registered for some endpoint
 	})
 
+	if len(uuids) == 0 {
+		return nil
+	}
+
 	ra.mailboxMutex.Lock()
+	defer ra.mailboxMutex.Unlock()
+
 	bndl, err := bundleDescriptor.Load()
 	if err != nil {
 		return err
 	}
+
+	var full []string
 	for _, uuid := range uuids {
+		if filter, ok := ra.filters.Load(uuid); ok && !filter.(DeliveryFilter).Matches(&bndl) {
+			log.WithFields(log.Fields{
+				"bundle": bundleDescriptor.ID.String(),
+				"uuid":   uuid,
+			}).Debug("REST Application Agent not delivering message to a client's inbox. Message does not pass the filter.")
+			continue
+		}
+
 		mailbox, exists := ra.mailboxes[uuid]
 		if !exists {
 			mailbox = map[bpv7.BundleID]bpv7.Bundle{bundleDescriptor.ID: bndl}
@@ -120,7 +231,14 @@ func (ra *RestAgent) Deliver(bundleDescriptor *store.BundleDescriptor) error {
 			}).Debug("REST Application Agent delivering message to a client's inbox")
 		} else {
 			_, exists = mailbox[bundleDescriptor.ID]
-			if !exists {
+			if !exists && ra.mailboxLimit > 0 && len(mailbox) >= ra.mailboxLimit {
+				log.WithFields(log.Fields{
+					"bundle": bundleDescriptor.ID.String(),
+					"uuid":   uuid,
+					"limit":  ra.mailboxLimit,
+				}).Debug("REST Application Agent not delivering message to a client's inbox. Inbox is full.")
+				full = append(full, uuid)
+			} else if !exists {
 				mailbox[bundleDescriptor.ID] = bndl
 				log.WithFields(log.Fields{
 					"bundle": bundleDescriptor.ID.String(),
@@ -134,11 +252,34 @@ func (ra *RestAgent) Deliver(bundleDescriptor *store.BundleDescriptor) error {
 			}
 		}
 	}
-	ra.mailboxMutex.Unlock()
 
+	if len(full) > 0 {
+		return fmt.Errorf("%w: bundle %v for clients %v", ErrMailboxFull, bundleDescriptor.ID, full)
+	}
 	return nil
 }
 
+// BundleDeleted queues the deletion of a client's sent bundle, to be returned by its next /fetch.
+func (ra *RestAgent) BundleDeleted(state DeliveryState, event StatusEvent) {
+	deleted := RestDeleted{
+		BundleID:   state.BundleID.String(),
+		Node:       event.Node.String(),
+		Reason:     event.Reason.String(),
+		ReasonCode: uint64(event.Reason),
+		Time:       event.Time,
+	}
+
+	ra.mailboxMutex.Lock()
+	defer ra.mailboxMutex.Unlock()
+
+	ra.clients.Range(func(k, v interface{}) bool {
+		if state.BundleID.SourceNode == v.(bpv7.EndpointID) {
+			ra.deletions[k.(string)] = append(ra.deletions[k.(string)], deleted)
+		}
+		return true
+	})
+}
+
 // randomUuid to be used for authentication. UUID not compliant with RFC 4122.
 func (_ *RestAgent) randomUuid() (uuid string, err error) {
 	uuidBytes := make([]byte, 16)
@@ -149,6 +290,29 @@ func (_ *RestAgent) randomUuid() (uuid string, err error) {
 	return
 }
 
+// toDeliveryFilter parses a client's optional filter, resulting in nil if it is absent.
+func (rf *RestDeliveryFilter) toDeliveryFilter() (*DeliveryFilter, error) {
+	if rf == nil {
+		return nil, nil
+	}
+
+	filter := DeliveryFilter{MinPayloadSize: rf.MinPayloadSize, MaxPayloadSize: rf.MaxPayloadSize}
+	if rf.MaxPayloadSize > 0 && rf.MinPayloadSize > rf.MaxPayloadSize {
+		return nil, fmt.Errorf("minimum payload size %d exceeds maximum %d", rf.MinPayloadSize, rf.MaxPayloadSize)
+	}
+	if rf.Source != "" {
+		source, err := bpv7.NewEndpointPattern(rf.Source)
+		if err != nil {
+			return nil, err
+		}
+		filter.Source = &source
+	}
+	for _, block := range rf.Blocks {
+		filter.Blocks = append(filter.Blocks, BlockMatch{TypeCode: block.TypeCode, Data: block.Data})
+	}
+	return &filter, nil
+}
+
 // handleRegister processes /register POST requests.
 func (ra *RestAgent) handleRegister(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -160,9 +324,19 @@ func (ra *RestAgent) handleRegister(w http.ResponseWriter, r *http.Request) {
 		registerResponse.Error = jsonErr.Error()
 	} else if eid, eidErr := bpv7.NewEndpointID(registerRequest.EndpointId); eidErr != nil {
 		registerResponse.Error = eidErr.Error()
+	} else if filter, filterErr := registerRequest.Filter.toDeliveryFilter(); filterErr != nil {
+		registerResponse.Error = filterErr.Error()
 	} else if uuid, uuidErr := ra.randomUuid(); uuidErr != nil {
 		registerResponse.Error = uuidErr.Error()
 	} else {
+		if filter != nil {
+			ra.filters.Store(uuid, *filter)
+		}
+		if registerRequest.Acknowledge {
+			ra.acknowledges.Store(uuid, struct{}{})
+		}
+		now := clock.Now()
+		ra.activity.Store(uuid, restActivity{registered: now, lastSeen: now})
 		ra.clients.Store(uuid, eid)
 		registerResponse.UUID = uuid
 	}
@@ -189,11 +363,7 @@ func (ra *RestAgent) handleUnregister(w http.ResponseWriter, r *http.Request) {
 		log.WithError(jsonErr).Warn("Failed to parse REST unregistration request")
 	} else {
 		log.WithField("uuid", unregisterRequest.UUID).Info("Unregister REST client")
-		ra.clients.Delete(unregisterRequest.UUID)
-
-		ra.mailboxMutex.Lock()
-		delete(ra.mailboxes, unregisterRequest.UUID)
-		ra.mailboxMutex.Unlock()
+		ra.Deregister(unregisterRequest.UUID)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -207,35 +377,94 @@ func (ra *RestAgent) handleFetch(w http.ResponseWriter, r *http.Request) {
 	var (
 		fetchRequest  RestFetchRequest
 		fetchResponse RestFetchResponse
+		acknowledging bool
 	)
 
 	if jsonErr := json.NewDecoder(r.Body).Decode(&fetchRequest); jsonErr != nil {
 		log.WithError(jsonErr).Warn("Failed to parse REST fetch request")
 		fetchResponse.Error = jsonErr.Error()
-	} else if mailbox, ok := ra.mailboxes[fetchRequest.UUID]; ok {
-		log.WithField("uuid", fetchRequest.UUID).Info("REST client fetches bundles")
+	} else {
+		_, acknowledging = ra.acknowledges.Load(fetchRequest.UUID)
 
 		ra.mailboxMutex.Lock()
-		bundles := make([]bpv7.Bundle, 0, len(mailbox))
-		for _, bundle := range mailbox {
-			bundles = append(bundles, bundle)
+		mailbox := ra.mailboxes[fetchRequest.UUID]
+		if acknowledging {
+			ra.acknowledge(mailbox, fetchRequest.Ack)
 		}
 
-		fetchResponse.Bundles = bundles
+		fetchResponse.Bundles = make([]bpv7.Bundle, 0, len(mailbox))
+		fetchResponse.BundleIDs = make([]string, 0, len(mailbox))
+		for id, bundle := range mailbox {
+			fetchResponse.Bundles = append(fetchResponse.Bundles, bundle)
+			fetchResponse.BundleIDs = append(fetchResponse.BundleIDs, id.String())
+		}
+		if !acknowledging {
+			delete(ra.mailboxes, fetchRequest.UUID)
+		}
 
-		delete(ra.mailboxes, fetchRequest.UUID)
+		fetchResponse.Deleted = ra.deletions[fetchRequest.UUID]
+		delete(ra.deletions, fetchRequest.UUID)
 		ra.mailboxMutex.Unlock()
-	} else if !ok {
-		log.WithField("uuid", fetchRequest.UUID).Debug("REST client has no new bundles to fetch")
-		fetchResponse.Bundles = make([]bpv7.Bundle, 0)
+
+		ra.touch(fetchRequest.UUID)
+		log.WithFields(log.Fields{
+			"uuid":         fetchRequest.UUID,
+			"bundles":      len(fetchResponse.Bundles),
+			"acknowledged": len(fetchRequest.Ack),
+		}).Debug("REST client fetches bundles")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(fetchResponse); err != nil {
-		log.WithError(err).Warn("Failed to write REST fetch response")
+		log.WithError(err).Warn("Failed to write REST fetch response, keeping its bundles for the next fetch")
+
+		// the bundles of an acknowledging client were kept anyway, unlike its deletions
+		bundles := fetchResponse.Bundles
+		if acknowledging {
+			bundles = nil
+		}
+		ra.redeliver(fetchRequest.UUID, bundles, fetchResponse.Deleted)
 	}
 }
 
+// acknowledge removes the bundles of the acknowledged IDs from a client's mailbox. Unknown IDs, e.g., acknowledged
+// twice, are skipped.
+func (_ *RestAgent) acknowledge(mailbox map[bpv7.BundleID]bpv7.Bundle, ack []string) {
+	if len(ack) == 0 {
+		return
+	}
+
+	acknowledged := make(map[string]struct{}, len(ack))
+	for _, id := range ack {
+		acknowledged[id] = struct{}{}
+	}
+	for id := range mailbox {
+		if _, ok := acknowledged[id.String()]; ok {
+			delete(mailbox, id)
+		}
+	}
+}
+
+// redeliver puts the bundles and deletions of a failed /fetch back, unless the client was unregistered meanwhile.
+func (ra *RestAgent) redeliver(uuid string, bundles []bpv7.Bundle, deleted []RestDeleted) {
+	if _, ok := ra.clients.Load(uuid); !ok || (len(bundles) == 0 && len(deleted) == 0) {
+		return
+	}
+
+	ra.mailboxMutex.Lock()
+	defer ra.mailboxMutex.Unlock()
+
+	mailbox, exists := ra.mailboxes[uuid]
+	if !exists {
+		mailbox = make(map[bpv7.BundleID]bpv7.Bundle, len(bundles))
+		ra.mailboxes[uuid] = mailbox
+	}
+	for _, bndl := range bundles {
+		mailbox[bndl.ID()] = bndl
+	}
+	ra.deletions[uuid] = append(deleted, ra.deletions[uuid]...)
+}
+
 // handleBuild creates and dispatches a new bundle, called by /build.
 func (ra *RestAgent) handleBuild(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -246,9 +475,12 @@ func (ra *RestAgent) handleBuild(w http.ResponseWriter, r *http.Request) {
 	if jsonErr := json.NewDecoder(r.Body).Decode(&buildRequest); jsonErr != nil {
 		log.WithError(jsonErr).Warn("Failed to parse REST build request")
 		buildResponse.Error = jsonErr.Error()
-	} else if eid, ok := ra.clients.Load(buildRequest.UUID); !ok {
+	} else if eid, ok := ra.client(buildRequest.UUID); !ok {
 		log.WithField("uuid", buildRequest.UUID).Debug("REST client cannot build for unknown UUID")
 		buildResponse.Error = "Invalid UUID"
+	} else if nameErr := resolveNames(buildRequest.Args); nameErr != nil {
+		log.WithError(nameErr).WithField("uuid", buildRequest.UUID).Debug("REST client addressed an unknown name")
+		buildResponse.Error = nameErr.Error()
 	} else if b, bErr := bpv7.BuildFromMap(buildRequest.Args); bErr != nil {
 		log.WithError(bErr).WithField("uuid", buildRequest.UUID).Warn("REST client failed to build a bundle")
 		buildResponse.Error = bErr.Error()
@@ -260,12 +492,18 @@ func (ra *RestAgent) handleBuild(w http.ResponseWriter, r *http.Request) {
 			"bundle":   b.ID().String(),
 		}).Warn(msg)
 		buildResponse.Error = msg
+	} else if bundleID, spooled, sendErr := GetManagerSingleton().Submit(&b); sendErr != nil {
+		buildResponse.Error = sendErr.Error()
+		buildResponse.SpoolBacklog = GetManagerSingleton().SpoolBacklog()
 	} else {
 		log.WithFields(log.Fields{
-			"uuid":   buildRequest.UUID,
-			"bundle": b.ID().String(),
+			"uuid":    buildRequest.UUID,
+			"bundle":  bundleID.String(),
+			"spooled": spooled,
 		}).Info("REST client sent bundle")
-		GetManagerSingleton().Send(&b)
+		buildResponse.BundleID = bundleID.String()
+		buildResponse.Spooled = spooled
+		buildResponse.SpoolBacklog = GetManagerSingleton().SpoolBacklog()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -274,10 +512,145 @@ func (ra *RestAgent) handleBuild(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleStatus returns the delivery state of a bundle sent by the client, called by /status.
+func (ra *RestAgent) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var (
+		statusRequest  RestStatusRequest
+		statusResponse RestStatusResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&statusRequest); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse REST status request")
+		statusResponse.Error = jsonErr.Error()
+	} else if eid, ok := ra.client(statusRequest.UUID); !ok {
+		log.WithField("uuid", statusRequest.UUID).Debug("REST client cannot query status for unknown UUID")
+		statusResponse.Error = "Invalid UUID"
+	} else if state, ok := GetManagerSingleton().DeliveryStateByIDString(statusRequest.BundleID); !ok || state.BundleID.SourceNode != eid {
+		statusResponse.Error = "Unknown bundle"
+	} else {
+		statusResponse.Sent = state.Sent
+		statusResponse.ForwardedBy = endpointStrings(state.ForwardedBy())
+		statusResponse.DeletedBy = endpointStrings(state.DeletedBy())
+		statusResponse.DeliveredAt, statusResponse.Delivered = state.DeliveredAt()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statusResponse); err != nil {
+		log.WithError(err).Warn("Failed to write REST status response")
+	}
+}
+
+// handleBundle returns a stored bundle sent by or addressed to the client, called by /bundle.
+func (ra *RestAgent) handleBundle(w http.ResponseWriter, r *http.Request) {
+	var (
+		bundleRequest  RestBundleRequest
+		bundleResponse RestBundleResponse
+	)
+
+	if jsonErr := json.NewDecoder(r.Body).Decode(&bundleRequest); jsonErr != nil {
+		log.WithError(jsonErr).Warn("Failed to parse REST bundle request")
+		bundleResponse.Error = jsonErr.Error()
+	} else if eid, ok := ra.client(bundleRequest.UUID); !ok {
+		log.WithField("uuid", bundleRequest.UUID).Debug("REST client cannot fetch a bundle for unknown UUID")
+		bundleResponse.Error = "Invalid UUID"
+	} else if bd, err := store.GetStoreSingleton().LoadBundleByIDString(
+		bundleRequest.BundleID, bundleRequest.Payload); err != nil || (bd.Source != eid && bd.Destination != eid) {
+		log.WithField("uuid", bundleRequest.UUID).Debug("REST client requested an unknown or foreign bundle")
+		bundleResponse.Error = "Unknown bundle"
+	} else {
+		bundleResponse.Source = bd.Source.String()
+		bundleResponse.Destination = bd.Destination.String()
+		bundleResponse.ReportTo = bd.ReportTo.String()
+		bundleResponse.Priority = bd.Priority.String()
+		bundleResponse.Expires = bd.Expires
+		bundleResponse.Bundle = bd.Bundle
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(bundleResponse); err != nil {
+		log.WithError(err).Warn("Failed to write REST bundle response")
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document, called by /openapi.json.
+func (_ *RestAgent) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(RestOpenAPI); err != nil {
+		log.WithError(err).Warn("Failed to write REST OpenAPI document")
+	}
+}
+
+// client looks up a registered client's endpoint by its UUID and records its request.
+func (ra *RestAgent) client(uuid string) (bpv7.EndpointID, bool) {
+	eid, ok := ra.clients.Load(uuid)
+	if !ok {
+		return bpv7.EndpointID{}, false
+	}
+	ra.touch(uuid)
+	return eid.(bpv7.EndpointID), true
+}
+
+// touch records a registered client's request, see Registrations.
+func (ra *RestAgent) touch(uuid string) {
+	if value, ok := ra.activity.Load(uuid); ok {
+		activity := value.(restActivity)
+		activity.lastSeen = clock.Now()
+		ra.activity.Store(uuid, activity)
+	}
+}
+
+// Registrations lists all registered clients. As clients poll for their bundles, a client is idle between its
+// requests, which cannot be told apart from a gone client, except by its LastSeen time.
+func (ra *RestAgent) Registrations() (registrations []Registration) {
+	ra.mailboxMutex.Lock()
+	defer ra.mailboxMutex.Unlock()
+
+	ra.clients.Range(func(k, v interface{}) bool {
+		uuid := k.(string)
+		registration := Registration{
+			ID:       uuid,
+			Endpoint: v.(bpv7.EndpointID),
+			Agent:    "rest",
+			Pending:  len(ra.mailboxes[uuid]),
+		}
+		_, registration.Filtered = ra.filters.Load(uuid)
+		if value, ok := ra.activity.Load(uuid); ok {
+			registration.Registered = value.(restActivity).registered
+			registration.LastSeen = value.(restActivity).lastSeen
+		}
+		registrations = append(registrations, registration)
+		return true
+	})
+	return
+}
+
+// Deregister removes a client by its UUID, dropping its undelivered bundles. It returns false for an unknown UUID.
+func (ra *RestAgent) Deregister(uuid string) bool {
+	_, known := ra.clients.LoadAndDelete(uuid)
+	ra.filters.Delete(uuid)
+	ra.acknowledges.Delete(uuid)
+	ra.activity.Delete(uuid)
+
+	ra.mailboxMutex.Lock()
+	delete(ra.mailboxes, uuid)
+	delete(ra.deletions, uuid)
+	ra.mailboxMutex.Unlock()
+
+	return known
+}
+
+func endpointStrings(eids []bpv7.EndpointID) []string {
+	strs := make([]string, 0, len(eids))
+	for _, eid := range eids {
+		strs = append(strs, eid.String())
+	}
+	return strs
+}
+
 func (ra *RestAgent) Endpoints() (eids []bpv7.EndpointID) {
 	ra.clients.Range(func(_, v interface{}) bool {
 		eids = append(eids, v.(bpv7.EndpointID))
-		return false
+		return true
 	})
 	return
 }
@@ -4,11 +4,30 @@
 
 package application_agent
 
-import "github.com/dtn7/dtn7-go/pkg/bpv7"
+import (
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
 
 // RestRegisterRequest describes a JSON to be POSTed to /register.
+// The optional Filter restricts the bundles delivered to this client, see DeliveryFilter. A client setting Acknowledge
+// confirms its fetched bundles by a later RestFetchRequest's Ack, until which they are fetched again.
 type RestRegisterRequest struct {
-	EndpointId string `json:"endpoint_id"`
+	EndpointId  string              `json:"endpoint_id"`
+	Filter      *RestDeliveryFilter `json:"filter,omitempty"`
+	Acknowledge bool                `json:"acknowledge,omitempty"`
+}
+
+// RestDeliveryFilter describes a DeliveryFilter as JSON. Source is a bpv7.EndpointPattern, Data is base64 encoded.
+type RestDeliveryFilter struct {
+	Source         string `json:"source"`
+	MinPayloadSize uint64 `json:"min_payload_size"`
+	MaxPayloadSize uint64 `json:"max_payload_size"`
+	Blocks         []struct {
+		TypeCode uint64 `json:"type_code"`
+		Data     []byte `json:"data"`
+	} `json:"blocks"`
 }
 
 // RestRegisterResponse describes a JSON response for /register.
@@ -27,15 +46,29 @@ type RestUnregisterResponse struct {
 	Error string `json:"error"`
 }
 
-// RestFetchRequest describes a JSON to be POSTed to /fetch.
+// RestFetchRequest describes a JSON to be POSTed to /fetch. Ack lists the IDs of bundles of earlier fetches which the
+// client processed, removing them from its mailbox, if it registered to acknowledge them.
 type RestFetchRequest struct {
-	UUID string `json:"uuid"`
+	UUID string   `json:"uuid"`
+	Ack  []string `json:"ack,omitempty"`
 }
 
-// RestFetchResponse describes a JSON response for /fetch.
+// RestFetchResponse describes a JSON response for /fetch. BundleIDs are the IDs of the Bundles in their order, to be
+// acknowledged. Deleted lists the client's sent bundles which were deleted since the last fetch.
 type RestFetchResponse struct {
-	Error   string        `json:"error"`
-	Bundles []bpv7.Bundle `json:"bundles"`
+	Error     string        `json:"error"`
+	Bundles   []bpv7.Bundle `json:"bundles"`
+	BundleIDs []string      `json:"bundle_ids"`
+	Deleted   []RestDeleted `json:"deleted,omitempty"`
+}
+
+// RestDeleted describes the deletion of a bundle sent by the client by some node, including the reason code.
+type RestDeleted struct {
+	BundleID   string    `json:"bundle_id"`
+	Node       string    `json:"node"`
+	Reason     string    `json:"reason"`
+	ReasonCode uint64    `json:"reason_code"`
+	Time       time.Time `json:"time"`
 }
 
 // RestBuildRequest describes a JSON to be POSTed to /build.
@@ -44,7 +77,46 @@ type RestBuildRequest struct {
 	Args map[string]interface{} `json:"arguments"`
 }
 
-// RestBuildResponse describes a JSON response for /build.
+// RestBuildResponse describes a JSON response for /build. A spooled bundle is sent once the node can take it again.
+// The SpoolBacklog of waiting bundles allows clients to slow down before their submissions fail with a full spool.
 type RestBuildResponse struct {
-	Error string `json:"error"`
+	Error        string `json:"error"`
+	BundleID     string `json:"bundle_id"`
+	Spooled      bool   `json:"spooled,omitempty"`
+	SpoolBacklog int    `json:"spool_backlog,omitempty"`
+}
+
+// RestStatusRequest describes a JSON to be POSTed to /status.
+type RestStatusRequest struct {
+	UUID     string `json:"uuid"`
+	BundleID string `json:"bundle_id"`
+}
+
+// RestStatusResponse describes a JSON response for /status.
+type RestStatusResponse struct {
+	Error       string    `json:"error"`
+	Sent        time.Time `json:"sent"`
+	ForwardedBy []string  `json:"forwarded_by"`
+	Delivered   bool      `json:"delivered"`
+	DeliveredAt time.Time `json:"delivered_at"`
+	DeletedBy   []string  `json:"deleted_by"`
+}
+
+// RestBundleRequest describes a JSON to be POSTed to /bundle. The bundle itself, including its payload, is only
+// returned if Payload is set.
+type RestBundleRequest struct {
+	UUID     string `json:"uuid"`
+	BundleID string `json:"bundle_id"`
+	Payload  bool   `json:"payload"`
+}
+
+// RestBundleResponse describes a JSON response for /bundle.
+type RestBundleResponse struct {
+	Error       string       `json:"error"`
+	Source      string       `json:"source"`
+	Destination string       `json:"destination"`
+	ReportTo    string       `json:"report_to"`
+	Priority    string       `json:"priority"`
+	Expires     time.Time    `json:"expires"`
+	Bundle      *bpv7.Bundle `json:"bundle,omitempty"`
 }
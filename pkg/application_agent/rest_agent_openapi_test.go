@@ -0,0 +1,116 @@
+package application_agent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// openAPIDocument is the part of the OpenAPI document compared against the RestAgent.
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// jsonFields lists the JSON names of a message type's fields.
+func jsonFields(t reflect.Type) (fields []string) {
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return
+}
+
+func TestRestOpenAPIMessages(t *testing.T) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(RestOpenAPI, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := []interface{}{
+		RestRegisterRequest{}, RestRegisterResponse{}, RestDeliveryFilter{},
+		RestUnregisterRequest{}, RestUnregisterResponse{},
+		RestFetchRequest{}, RestFetchResponse{}, RestDeleted{},
+		RestBuildRequest{}, RestBuildResponse{},
+		RestStatusRequest{}, RestStatusResponse{},
+		RestBundleRequest{}, RestBundleResponse{},
+	}
+	for _, msg := range messages {
+		typ := reflect.TypeOf(msg)
+		sch, ok := doc.Components.Schemas[typ.Name()]
+		if !ok {
+			t.Errorf("%s has no schema", typ.Name())
+			continue
+		}
+
+		var props []string
+		for name := range sch.Properties {
+			props = append(props, name)
+		}
+		sort.Strings(props)
+
+		if fields := jsonFields(typ); !reflect.DeepEqual(fields, props) {
+			t.Errorf("%s has fields %v, its schema %v", typ.Name(), fields, props)
+		}
+	}
+}
+
+func TestRestOpenAPIPaths(t *testing.T) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(RestOpenAPI, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	NewRestAgent(router)
+
+	var routes []string
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, _ := route.GetPathTemplate()
+		methods, _ := route.GetMethods()
+		if methods[0] == http.MethodPost {
+			routes = append(routes, path)
+		}
+		return nil
+	})
+	sort.Strings(routes)
+
+	var paths []string
+	for path, item := range doc.Paths {
+		if _, ok := item["post"]; ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	if !reflect.DeepEqual(routes, paths) {
+		t.Fatalf("RestAgent serves %v, OpenAPI document specifies %v", routes, paths)
+	}
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if served, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	} else if string(served) != string(RestOpenAPI) {
+		t.Fatalf("served OpenAPI document differs")
+	}
+}
@@ -0,0 +1,210 @@
+package application_agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// restRequest POSTs a JSON request to the RestAgent and decodes its response.
+func restRequest(t *testing.T, router http.Handler, target string, request, response interface{}) {
+	t.Helper()
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(request); err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, target, &body))
+	if err := json.NewDecoder(recorder.Body).Decode(response); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// restRegister registers a client for dtn://dst/inbox and returns its UUID.
+func restRegister(t *testing.T, router http.Handler, acknowledge bool) string {
+	t.Helper()
+
+	var response RestRegisterResponse
+	restRequest(t, router, "/register", RestRegisterRequest{EndpointId: "dtn://dst/inbox", Acknowledge: acknowledge}, &response)
+	if response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	return response.UUID
+}
+
+// restFetch fetches a client's bundles, acknowledging the given IDs, and returns the sorted IDs of the fetched ones.
+func restFetch(t *testing.T, router http.Handler, uuid string, ack ...string) []string {
+	t.Helper()
+
+	// bundles cannot be unmarshalled from JSON, thus only their sources are compared to their IDs
+	var response struct {
+		Error   string `json:"error"`
+		Bundles []struct {
+			PrimaryBlock struct {
+				Source string `json:"source"`
+			} `json:"primaryBlock"`
+		} `json:"bundles"`
+		BundleIDs []string `json:"bundle_ids"`
+	}
+	restRequest(t, router, "/fetch", RestFetchRequest{UUID: uuid, Ack: ack}, &response)
+	if response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	if len(response.Bundles) != len(response.BundleIDs) {
+		t.Fatalf("Fetched %d bundles, but %d IDs", len(response.Bundles), len(response.BundleIDs))
+	}
+	for i, bndl := range response.Bundles {
+		if id := response.BundleIDs[i]; !strings.HasPrefix(id, bndl.PrimaryBlock.Source+"-") {
+			t.Fatalf("Fetched bundle from %s has ID %s", bndl.PrimaryBlock.Source, id)
+		}
+	}
+
+	sort.Strings(response.BundleIDs)
+	return response.BundleIDs
+}
+
+// restDescriptors creates descriptors of n bundles for dtn://dst/inbox, sorted by their IDs.
+func restDescriptors(t *testing.T, n int) (descriptors []*store.BundleDescriptor, ids []string) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		bndl, err := bpv7.Builder().
+			Source(fmt.Sprintf("dtn://src/%d", i)).
+			Destination("dtn://dst/inbox").
+			CreationTimestampNow().
+			Lifetime(time.Hour).
+			PayloadBlock([]byte("hello inbox")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		descriptors = append(descriptors, &store.BundleDescriptor{
+			ID:          bndl.ID(),
+			Destination: bndl.PrimaryBlock.Destination,
+			Bundle:      &bndl,
+		})
+		ids = append(ids, bndl.ID().String())
+	}
+	return
+}
+
+func assertFetched(t *testing.T, fetched, expected []string) {
+	t.Helper()
+
+	if len(fetched) != len(expected) {
+		t.Fatalf("Expected bundles %v, fetched %v", expected, fetched)
+	}
+	for i := range expected {
+		if fetched[i] != expected[i] {
+			t.Fatalf("Expected bundles %v, fetched %v", expected, fetched)
+		}
+	}
+}
+
+func TestRestAgentMailboxLimit(t *testing.T) {
+	router := mux.NewRouter()
+	ra := NewRestAgent(router)
+	ra.SetMailboxLimit(2)
+
+	full := restRegister(t, router, false)
+	descriptors, ids := restDescriptors(t, 3)
+	for _, bd := range descriptors[:2] {
+		if err := ra.Deliver(bd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// another client of the endpoint still has room
+	other := restRegister(t, router, false)
+	if err := ra.Deliver(descriptors[2]); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("Expected ErrMailboxFull, got %v", err)
+	}
+	assertFetched(t, restFetch(t, router, other), ids[2:])
+
+	// a bundle already waiting is not refused
+	if err := ra.Deliver(descriptors[0]); err != nil {
+		t.Fatalf("Delivering a waiting bundle again failed: %v", err)
+	}
+
+	assertFetched(t, restFetch(t, router, full), ids[:2])
+	if err := ra.Deliver(descriptors[2]); err != nil {
+		t.Fatalf("Delivering into a fetched mailbox failed: %v", err)
+	}
+	assertFetched(t, restFetch(t, router, full), ids[2:])
+}
+
+func TestRestAgentAcknowledgement(t *testing.T) {
+	router := mux.NewRouter()
+	ra := NewRestAgent(router)
+	ra.SetMailboxLimit(2)
+
+	uuid := restRegister(t, router, true)
+	descriptors, ids := restDescriptors(t, 3)
+	for _, bd := range descriptors[:2] {
+		if err := ra.Deliver(bd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// unacknowledged bundles are fetched again and still occupy the mailbox
+	assertFetched(t, restFetch(t, router, uuid), ids[:2])
+	assertFetched(t, restFetch(t, router, uuid), ids[:2])
+	if err := ra.Deliver(descriptors[2]); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("Expected ErrMailboxFull, got %v", err)
+	}
+
+	assertFetched(t, restFetch(t, router, uuid, ids[0]), ids[1:2])
+	if err := ra.Deliver(descriptors[2]); err != nil {
+		t.Fatalf("Delivering after an acknowledgement failed: %v", err)
+	}
+
+	// acknowledging an unknown or already acknowledged bundle is skipped
+	assertFetched(t, restFetch(t, router, uuid, ids[0], ids[1], "dtn://unknown/-0-0"), ids[2:])
+	assertFetched(t, restFetch(t, router, uuid, ids[2]), nil)
+}
+
+// failingResponseWriter fails to write any response, e.g., as the client's connection broke.
+type failingResponseWriter struct {
+	httptest.ResponseRecorder
+}
+
+func (_ *failingResponseWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestRestAgentRedelivery(t *testing.T) {
+	router := mux.NewRouter()
+	ra := NewRestAgent(router)
+
+	uuid := restRegister(t, router, false)
+	descriptors, ids := restDescriptors(t, 2)
+	for _, bd := range descriptors {
+		if err := ra.Deliver(bd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, err := json.Marshal(RestFetchRequest{UUID: uuid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra.handleFetch(&failingResponseWriter{}, httptest.NewRequest(http.MethodPost, "/fetch", bytes.NewReader(body)))
+
+	// a client which does not acknowledge its bundles gets them once, after the failed response
+	assertFetched(t, restFetch(t, router, uuid), ids)
+	assertFetched(t, restFetch(t, router, uuid), nil)
+}
@@ -0,0 +1,290 @@
+package application_agent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// spoolDrainInterval between attempts to send spooled bundles.
+const spoolDrainInterval = 5 * time.Second
+
+// spoolFileSuffix of each spooled bundle's file, named by its position in the spool.
+const spoolFileSuffix = ".bundle"
+
+// ErrSpoolFull is returned by Submit if the node cannot take a bundle right now and the spool has no room left. The
+// application should retry the submission later.
+var ErrSpoolFull = errors.New("node cannot take bundles right now and its spool is full")
+
+// SpoolConfig configures the spool of bundles submitted by applications while the node cannot take them, see
+// SetSpool.
+type SpoolConfig struct {
+	// Directory of the spooled bundles, created if missing.
+	Directory string
+	// MaxBundles and MaxBytes bound the spool, zero for unbounded.
+	MaxBundles int
+	MaxBytes   int64
+}
+
+// CheckValid checks for a directory and non-negative bounds.
+func (config SpoolConfig) CheckValid() error {
+	if config.Directory == "" {
+		return fmt.Errorf("spool requires a directory")
+	}
+	if config.MaxBundles < 0 || config.MaxBytes < 0 {
+		return fmt.Errorf("spool bounds must not be negative")
+	}
+	return nil
+}
+
+// spoolEntry is a spooled bundle's file.
+type spoolEntry struct {
+	seq  uint64
+	size int64
+}
+
+// spool persists bundles in a directory, one file each, and returns them in their submission order.
+type spool struct {
+	config SpoolConfig
+
+	mutex   sync.Mutex
+	entries []spoolEntry
+	bytes   int64
+	nextSeq uint64
+}
+
+// openSpool opens the spool's directory, recovering the bundles spooled before a restart.
+func openSpool(config SpoolConfig) (*spool, error) {
+	if err := os.MkdirAll(config.Directory, 0700); err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(config.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spool{config: config}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), spoolFileSuffix+".tmp") {
+			_ = os.Remove(filepath.Join(config.Directory, file.Name()))
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), spoolFileSuffix), 10, 64)
+		if file.IsDir() || !strings.HasSuffix(file.Name(), spoolFileSuffix) || err != nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		s.entries = append(s.entries, spoolEntry{seq: seq, size: info.Size()})
+		s.bytes += info.Size()
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(s.entries, func(i, j int) bool { return s.entries[i].seq < s.entries[j].seq })
+
+	return s, nil
+}
+
+// path of a spooled bundle's file.
+func (s *spool) path(seq uint64) string {
+	return filepath.Join(s.config.Directory, fmt.Sprintf("%020d%s", seq, spoolFileSuffix))
+}
+
+// push appends a bundle to the spool. It returns ErrSpoolFull if the bundle exceeds the spool's bounds.
+func (s *spool) push(bndl *bpv7.Bundle) error {
+	buff := new(bytes.Buffer)
+	if err := bndl.MarshalCbor(buff); err != nil {
+		return err
+	}
+	size := int64(buff.Len())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if (s.config.MaxBundles > 0 && len(s.entries) >= s.config.MaxBundles) ||
+		(s.config.MaxBytes > 0 && s.bytes+size > s.config.MaxBytes) {
+		return ErrSpoolFull
+	}
+
+	// The bundle is written to a temporary file first. Thus, a crash never leaves a partial bundle behind.
+	seq := s.nextSeq
+	tmpPath := s.path(seq) + ".tmp"
+	if err := os.WriteFile(tmpPath, buff.Bytes(), 0600); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path(seq)); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	s.nextSeq++
+	s.entries = append(s.entries, spoolEntry{seq: seq, size: size})
+	s.bytes += size
+	return nil
+}
+
+// peek returns the oldest spooled bundle without removing it.
+func (s *spool) peek() (bndl bpv7.Bundle, ok bool, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.entries) == 0 {
+		return
+	}
+
+	data, err := os.ReadFile(s.path(s.entries[0].seq))
+	if err != nil {
+		return
+	}
+	err = bndl.UnmarshalCbor(bytes.NewBuffer(data))
+	return bndl, err == nil, err
+}
+
+// pop removes the oldest spooled bundle.
+func (s *spool) pop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.entries) == 0 {
+		return nil
+	}
+
+	entry := s.entries[0]
+	if err := os.Remove(s.path(entry.seq)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.entries = s.entries[1:]
+	s.bytes -= entry.size
+	return nil
+}
+
+// len returns the number of spooled bundles.
+func (s *spool) len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.entries)
+}
+
+// SetSpool enables spooling bundles submitted by applications while the node cannot take them, i.e., while the store
+// is filled up to its capacity or the node is shutting down. Spooled bundles are persisted in the configured
+// directory and sent in their submission order once the node can take them again, also after a restart. Thus,
+// applications' data is not lost. Once the spool is full, Submit reports ErrSpoolFull as backpressure.
+// This method must be called at most once, before bundles are submitted.
+func (manager *Manager) SetSpool(config SpoolConfig) error {
+	if err := config.CheckValid(); err != nil {
+		return err
+	}
+	s, err := openSpool(config)
+	if err != nil {
+		return err
+	}
+
+	manager.spool = s
+	manager.spoolStop = make(chan struct{})
+	go manager.spoolLoop(manager.spoolStop)
+
+	if n := s.len(); n > 0 {
+		log.WithFields(log.Fields{
+			"directory": config.Directory,
+			"bundles":   n,
+		}).Info("Recovered spooled bundles")
+	}
+	return nil
+}
+
+// SpoolBacklog returns the number of spooled bundles, which are waiting to be sent.
+// This method is thread-safe.
+func (manager *Manager) SpoolBacklog() int {
+	if manager.spool == nil {
+		return 0
+	}
+	return manager.spool.len()
+}
+
+// canTake checks if the node can take a bundle of the size right now, which is not the case if the store is filled up
+// to its capacity or the node is shutting down.
+func (manager *Manager) canTake(size int64) bool {
+	if manager.closing.Load() {
+		return false
+	}
+
+	occupancy := store.GetStoreSingleton().Occupancy()
+	return occupancy.Capacity <= 0 || occupancy.Used+size <= occupancy.Capacity
+}
+
+// spoolSubmission spools a submitted bundle if the node cannot take it right now. As the spool keeps the submission
+// order, bundles are spooled while older ones are still waiting.
+func (manager *Manager) spoolSubmission(bndl *bpv7.Bundle) (spooled bool, err error) {
+	if manager.spool == nil {
+		return false, nil
+	}
+	if manager.spool.len() == 0 && manager.canTake(int64(cla.BundleSize(*bndl))) {
+		return false, nil
+	}
+
+	if err := manager.spool.push(bndl); err != nil {
+		return false, err
+	}
+	log.WithFields(log.Fields{
+		"bundle":  bndl.ID(),
+		"backlog": manager.spool.len(),
+	}).Info("Spooled bundle submitted by an application")
+	return true, nil
+}
+
+func (manager *Manager) spoolLoop(stop <-chan struct{}) {
+	ticker := clock.NewTicker(spoolDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C():
+			manager.drainSpool()
+		}
+	}
+}
+
+// drainSpool sends spooled bundles in their order as long as the node can take them.
+func (manager *Manager) drainSpool() {
+	for {
+		bndl, ok, err := manager.spool.peek()
+		if err != nil {
+			log.WithError(err).Error("Failed to read spooled bundle, dropping it")
+		} else if !ok {
+			return
+		} else if !manager.canTake(int64(cla.BundleSize(bndl))) {
+			return
+		} else {
+			log.WithField("bundle", bndl.ID()).Info("Sending spooled bundle")
+			manager.Send(&bndl)
+		}
+
+		if err := manager.spool.pop(); err != nil {
+			log.WithError(err).Error("Failed to remove spooled bundle")
+			return
+		}
+	}
+}
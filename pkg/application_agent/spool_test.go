@@ -0,0 +1,171 @@
+package application_agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func spoolTestBundle(t *testing.T, i int) bpv7.Bundle {
+	t.Helper()
+
+	bndl, err := bpv7.Builder().
+		Source(fmt.Sprintf("dtn://src/%d", i)).
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("hello spool")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+// popAll drains the spool, returning the bundles' sources in their order.
+func popAll(t *testing.T, s *spool) (sources []string) {
+	t.Helper()
+
+	for {
+		bndl, ok, err := s.peek()
+		if err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			return
+		}
+		sources = append(sources, bndl.PrimaryBlock.SourceNode.String())
+
+		if err := s.pop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSpoolRecovery(t *testing.T) {
+	config := SpoolConfig{Directory: filepath.Join(t.TempDir(), "spool")}
+
+	s, err := openSpool(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		bndl := spoolTestBundle(t, i)
+		if err := s.push(&bndl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a partial bundle of a crash while pushing, which is discarded on the next opening
+	tmpPath := s.path(s.nextSeq) + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte{0x9f}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := openSpool(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+		t.Fatalf("Temporary file was not removed: %v", err)
+	}
+	if recovered.len() != 11 || recovered.bytes != s.bytes || recovered.nextSeq != s.nextSeq {
+		t.Fatalf("Recovered %d bundles of %d bytes, next %d; expected 11 of %d bytes, next %d",
+			recovered.len(), recovered.bytes, recovered.nextSeq, s.bytes, s.nextSeq)
+	}
+
+	// the submission order is kept beyond a single digit, as the file names are padded
+	next := spoolTestBundle(t, 12)
+	if err := recovered.push(&next); err != nil {
+		t.Fatal(err)
+	}
+	sources := popAll(t, recovered)
+	if len(sources) != 12 {
+		t.Fatalf("Expected 12 bundles, got %v", sources)
+	}
+	for i, source := range sources {
+		if expected := fmt.Sprintf("dtn://src/%d", i+1); source != expected {
+			t.Fatalf("Expected %s at %d, got %s", expected, i, source)
+		}
+	}
+
+	if recovered.bytes != 0 {
+		t.Fatalf("Drained spool still counts %d bytes", recovered.bytes)
+	}
+	if files, err := os.ReadDir(config.Directory); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 0 {
+		t.Fatalf("Drained spool still has %d files", len(files))
+	}
+}
+
+func TestSpoolBounds(t *testing.T) {
+	bndl := spoolTestBundle(t, 0)
+	size := int64(cla.BundleSize(bndl))
+
+	tests := []struct {
+		name   string
+		config SpoolConfig
+		fits   int
+	}{
+		{"unbounded", SpoolConfig{}, 5},
+		{"bundles", SpoolConfig{MaxBundles: 2}, 2},
+		{"bytes", SpoolConfig{MaxBytes: 3*size + size/2}, 3},
+		{"both", SpoolConfig{MaxBundles: 3, MaxBytes: 2 * size}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.config.Directory = t.TempDir()
+			s, err := openSpool(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 5; i++ {
+				bndl := spoolTestBundle(t, i)
+				err := s.push(&bndl)
+				if i < test.fits && err != nil {
+					t.Fatalf("Bundle %d: %v", i, err)
+				} else if i >= test.fits && !errors.Is(err, ErrSpoolFull) {
+					t.Fatalf("Bundle %d: expected ErrSpoolFull, got %v", i, err)
+				}
+			}
+
+			// popping makes room for another bundle
+			if err := s.pop(); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.push(&bndl); err != nil {
+				t.Fatalf("Bundle after pop: %v", err)
+			}
+		})
+	}
+}
+
+func TestSpoolConfigCheckValid(t *testing.T) {
+	tests := []struct {
+		config SpoolConfig
+		valid  bool
+	}{
+		{SpoolConfig{Directory: "/tmp/spool"}, true},
+		{SpoolConfig{Directory: "/tmp/spool", MaxBundles: 10, MaxBytes: 1024}, true},
+		{SpoolConfig{}, false},
+		{SpoolConfig{Directory: "/tmp/spool", MaxBundles: -1}, false},
+		{SpoolConfig{Directory: "/tmp/spool", MaxBytes: -1}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.config.CheckValid(); (err == nil) != test.valid {
+			t.Errorf("Config %+v: expected valid %t, got %v", test.config, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Package bpv6 reads and writes Bundles of the Bundle Protocol Version 6, as specified in RFC 5050. Its purpose is the
+// ingestion of traffic from legacy DTN implementations, e.g., DTN2 or IBR-DTN, into a BPv7 network.
+//
+// Thus, only a subset of the protocol is supported: Bundles can be parsed, serialised and converted into BPv7 bundles.
+// The conversion fails if information would be lost, e.g., for custody transfers or unknown extension blocks, which
+// have no BPv7 equivalent.
+//
+//	bundle6, err := bpv6.ParseBundle(r)
+//	bundle7, err := bundle6.ToBPv7()
+package bpv6
@@ -0,0 +1,402 @@
+package bpv6
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Version is the Bundle Protocol version of RFC 5050, the first byte of each serialised Bundle.
+const Version = 0x06
+
+// ProcessingFlags are the bundle processing control flags of a PrimaryBlock, RFC 5050 section 4.2.
+type ProcessingFlags uint64
+
+const (
+	IsFragment                     ProcessingFlags = 0x000001
+	AdministrativeRecordPayload    ProcessingFlags = 0x000002
+	MustNotFragment                ProcessingFlags = 0x000004
+	CustodyTransferRequested       ProcessingFlags = 0x000008
+	DestinationIsSingleton         ProcessingFlags = 0x000010
+	RequestApplicationAck          ProcessingFlags = 0x000020
+	StatusRequestReception         ProcessingFlags = 0x004000
+	StatusRequestCustodyAcceptance ProcessingFlags = 0x008000
+	StatusRequestForward           ProcessingFlags = 0x010000
+	StatusRequestDelivery          ProcessingFlags = 0x020000
+	StatusRequestDeletion          ProcessingFlags = 0x040000
+
+	// priorityShift and priorityMask locate the priority in the class of service bits.
+	priorityShift = 7
+	priorityMask  = 0x3
+)
+
+// Has returns true if a given flag or mask of flags is set.
+func (pf ProcessingFlags) Has(flag ProcessingFlags) bool {
+	return (pf & flag) != 0
+}
+
+// Priority is the bundle's class of service: 0 is bulk, 1 normal, 2 expedited and 3 is reserved.
+func (pf ProcessingFlags) Priority() uint64 {
+	return (uint64(pf) >> priorityShift) & priorityMask
+}
+
+// BlockFlags are the block processing control flags of a CanonicalBlock, RFC 5050 section 4.3.
+type BlockFlags uint64
+
+const (
+	ReplicateBlock       BlockFlags = 0x01
+	StatusReportBlock    BlockFlags = 0x02
+	DeleteBundle         BlockFlags = 0x04
+	LastBlock            BlockFlags = 0x08
+	DiscardBlock         BlockFlags = 0x10
+	ForwardedUnprocessed BlockFlags = 0x20
+	HasEIDReferences     BlockFlags = 0x40
+)
+
+// Has returns true if a given flag or mask of flags is set.
+func (bf BlockFlags) Has(flag BlockFlags) bool {
+	return (bf & flag) != 0
+}
+
+// PayloadBlockType is the block type code of the Payload Block.
+const PayloadBlockType = 0x01
+
+// PrimaryBlock of a BPv6 Bundle. Endpoints are URIs like "dtn://node/app" or "ipn:1.2" and the dictionary is
+// resolved. Times are seconds since the year 2000.
+type PrimaryBlock struct {
+	Flags            ProcessingFlags
+	Destination      string
+	Source           string
+	ReportTo         string
+	Custodian        string
+	CreationTime     uint64
+	CreationSequence uint64
+	Lifetime         uint64
+	FragmentOffset   uint64
+	TotalADULength   uint64
+}
+
+// CanonicalBlock of a BPv6 Bundle, e.g., the Payload Block. EIDReferences are resolved endpoint URIs.
+type CanonicalBlock struct {
+	Type          uint8
+	Flags         BlockFlags
+	EIDReferences []string
+	Data          []byte
+}
+
+// Bundle of the Bundle Protocol Version 6.
+type Bundle struct {
+	PrimaryBlock    PrimaryBlock
+	CanonicalBlocks []CanonicalBlock
+}
+
+// PayloadBlock returns this Bundle's Payload Block.
+func (b Bundle) PayloadBlock() (*CanonicalBlock, error) {
+	for i := range b.CanonicalBlocks {
+		if b.CanonicalBlocks[i].Type == PayloadBlockType {
+			return &b.CanonicalBlocks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("bundle has no payload block")
+}
+
+// IsBPv6 checks if serialised data starts like a BPv6 Bundle. BPv7 Bundles start with a CBOR array instead.
+func IsBPv6(data []byte) bool {
+	return len(data) > 0 && data[0] == Version
+}
+
+// readBytes reads n bytes without allocating them in advance, as n might be a bogus length.
+func readBytes(r io.Reader, n uint64) ([]byte, error) {
+	buff := new(bytes.Buffer)
+	if _, err := io.CopyN(buff, r, int64(n)); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// dictionaryEntry resolves a scheme and SSP offset pair into an endpoint URI.
+func dictionaryEntry(dictionary []byte, schemeOffset, sspOffset uint64) (string, error) {
+	entry := func(offset uint64) (string, error) {
+		if offset >= uint64(len(dictionary)) {
+			return "", fmt.Errorf("dictionary offset %d exceeds dictionary of %d bytes", offset, len(dictionary))
+		}
+		end := bytes.IndexByte(dictionary[offset:], 0)
+		if end < 0 {
+			return "", fmt.Errorf("dictionary entry at offset %d is not terminated", offset)
+		}
+		return string(dictionary[offset : offset+uint64(end)]), nil
+	}
+
+	scheme, err := entry(schemeOffset)
+	if err != nil {
+		return "", err
+	}
+	ssp, err := entry(sspOffset)
+	if err != nil {
+		return "", err
+	}
+	return scheme + ":" + ssp, nil
+}
+
+// ParseBundle reads a serialised BPv6 Bundle.
+func ParseBundle(r io.Reader) (b Bundle, err error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		bufReader := bufio.NewReader(r)
+		br, r = bufReader, bufReader
+	}
+
+	if version, vErr := br.ReadByte(); vErr != nil {
+		err = vErr
+		return
+	} else if version != Version {
+		err = fmt.Errorf("expected bundle protocol version %d, got %d", Version, version)
+		return
+	}
+
+	flags, err := ReadSDNV(br)
+	if err != nil {
+		return
+	}
+	b.PrimaryBlock.Flags = ProcessingFlags(flags)
+
+	blockLength, err := ReadSDNV(br)
+	if err != nil {
+		return
+	}
+	primaryData, err := readBytes(r, blockLength)
+	if err != nil {
+		return
+	}
+
+	var dictionary []byte
+	if dictionary, err = b.PrimaryBlock.parse(bytes.NewReader(primaryData)); err != nil {
+		err = fmt.Errorf("parsing primary block failed: %v", err)
+		return
+	}
+
+	for last := false; !last; {
+		var cb CanonicalBlock
+		if cb, err = parseCanonicalBlock(r, br, dictionary); err != nil {
+			err = fmt.Errorf("parsing canonical block %d failed: %v", len(b.CanonicalBlocks), err)
+			return
+		}
+		b.CanonicalBlocks = append(b.CanonicalBlocks, cb)
+		last = cb.Flags.Has(LastBlock)
+	}
+
+	return
+}
+
+// parse the PrimaryBlock's fields following the block length and return the dictionary.
+func (pb *PrimaryBlock) parse(r *bytes.Reader) (dictionary []byte, err error) {
+	var offsets [8]uint64
+	for i := range offsets {
+		if offsets[i], err = ReadSDNV(r); err != nil {
+			return
+		}
+	}
+
+	for _, field := range []*uint64{&pb.CreationTime, &pb.CreationSequence, &pb.Lifetime} {
+		if *field, err = ReadSDNV(r); err != nil {
+			return
+		}
+	}
+
+	dictionaryLength, err := ReadSDNV(r)
+	if err != nil {
+		return
+	}
+	if dictionary, err = readBytes(r, dictionaryLength); err != nil {
+		return
+	}
+
+	for i, field := range []*string{&pb.Destination, &pb.Source, &pb.ReportTo, &pb.Custodian} {
+		if *field, err = dictionaryEntry(dictionary, offsets[2*i], offsets[2*i+1]); err != nil {
+			return
+		}
+	}
+
+	if pb.Flags.Has(IsFragment) {
+		for _, field := range []*uint64{&pb.FragmentOffset, &pb.TotalADULength} {
+			if *field, err = ReadSDNV(r); err != nil {
+				return
+			}
+		}
+	}
+
+	if r.Len() != 0 {
+		err = fmt.Errorf("primary block has %d trailing bytes", r.Len())
+	}
+	return
+}
+
+func parseCanonicalBlock(r io.Reader, br io.ByteReader, dictionary []byte) (cb CanonicalBlock, err error) {
+	if cb.Type, err = br.ReadByte(); err != nil {
+		return
+	}
+
+	flags, err := ReadSDNV(br)
+	if err != nil {
+		return
+	}
+	cb.Flags = BlockFlags(flags)
+
+	if cb.Flags.Has(HasEIDReferences) {
+		var count uint64
+		if count, err = ReadSDNV(br); err != nil {
+			return
+		}
+		for i := uint64(0); i < count; i++ {
+			var schemeOffset, sspOffset uint64
+			if schemeOffset, err = ReadSDNV(br); err != nil {
+				return
+			}
+			if sspOffset, err = ReadSDNV(br); err != nil {
+				return
+			}
+
+			var eid string
+			if eid, err = dictionaryEntry(dictionary, schemeOffset, sspOffset); err != nil {
+				return
+			}
+			cb.EIDReferences = append(cb.EIDReferences, eid)
+		}
+	}
+
+	dataLength, err := ReadSDNV(br)
+	if err != nil {
+		return
+	}
+	cb.Data, err = readBytes(r, dataLength)
+	return
+}
+
+// dictionaryBuilder creates a dictionary of null-terminated strings, each stored only once.
+type dictionaryBuilder struct {
+	buff    bytes.Buffer
+	offsets map[string]uint64
+}
+
+func (db *dictionaryBuilder) add(s string) uint64 {
+	if db.offsets == nil {
+		db.offsets = make(map[string]uint64)
+	}
+	if offset, ok := db.offsets[s]; ok {
+		return offset
+	}
+
+	offset := uint64(db.buff.Len())
+	db.buff.WriteString(s)
+	db.buff.WriteByte(0)
+	db.offsets[s] = offset
+	return offset
+}
+
+// addEndpoint adds an endpoint URI's scheme and SSP, returning both offsets.
+func (db *dictionaryBuilder) addEndpoint(eid string) (uint64, uint64, error) {
+	scheme, ssp, ok := strings.Cut(eid, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("endpoint %q has no scheme", eid)
+	}
+	return db.add(scheme), db.add(ssp), nil
+}
+
+// WriteBundle serialises this Bundle. The last CanonicalBlock is flagged as such.
+func (b Bundle) WriteBundle(w io.Writer) error {
+	if len(b.CanonicalBlocks) == 0 {
+		return fmt.Errorf("bundle has no canonical blocks")
+	}
+
+	var dictionary dictionaryBuilder
+	primary := new(bytes.Buffer)
+
+	pb := b.PrimaryBlock
+	for _, eid := range []string{pb.Destination, pb.Source, pb.ReportTo, pb.Custodian} {
+		schemeOffset, sspOffset, err := dictionary.addEndpoint(eid)
+		if err != nil {
+			return err
+		}
+		for _, offset := range []uint64{schemeOffset, sspOffset} {
+			if err := WriteSDNV(offset, primary); err != nil {
+				return err
+			}
+		}
+	}
+
+	// EIDReferences must be part of the dictionary, which precedes the canonical blocks.
+	blockReferences := make([][]uint64, len(b.CanonicalBlocks))
+	for i, cb := range b.CanonicalBlocks {
+		for _, eid := range cb.EIDReferences {
+			schemeOffset, sspOffset, err := dictionary.addEndpoint(eid)
+			if err != nil {
+				return err
+			}
+			blockReferences[i] = append(blockReferences[i], schemeOffset, sspOffset)
+		}
+	}
+
+	fields := []uint64{pb.CreationTime, pb.CreationSequence, pb.Lifetime, uint64(dictionary.buff.Len())}
+	for _, field := range fields {
+		if err := WriteSDNV(field, primary); err != nil {
+			return err
+		}
+	}
+	primary.Write(dictionary.buff.Bytes())
+	if pb.Flags.Has(IsFragment) {
+		for _, field := range []uint64{pb.FragmentOffset, pb.TotalADULength} {
+			if err := WriteSDNV(field, primary); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := w.Write([]byte{Version}); err != nil {
+		return err
+	}
+	for _, field := range []uint64{uint64(pb.Flags), uint64(primary.Len())} {
+		if err := WriteSDNV(field, w); err != nil {
+			return err
+		}
+	}
+	if _, err := w.Write(primary.Bytes()); err != nil {
+		return err
+	}
+
+	for i, cb := range b.CanonicalBlocks {
+		flags := cb.Flags &^ (LastBlock | HasEIDReferences)
+		if i == len(b.CanonicalBlocks)-1 {
+			flags |= LastBlock
+		}
+		if len(cb.EIDReferences) > 0 {
+			flags |= HasEIDReferences
+		}
+
+		if _, err := w.Write([]byte{cb.Type}); err != nil {
+			return err
+		}
+		if err := WriteSDNV(uint64(flags), w); err != nil {
+			return err
+		}
+		if len(cb.EIDReferences) > 0 {
+			if err := WriteSDNV(uint64(len(cb.EIDReferences)), w); err != nil {
+				return err
+			}
+			for _, offset := range blockReferences[i] {
+				if err := WriteSDNV(offset, w); err != nil {
+					return err
+				}
+			}
+		}
+		if err := WriteSDNV(uint64(len(cb.Data)), w); err != nil {
+			return err
+		}
+		if _, err := w.Write(cb.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package bpv6
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func testBundle() Bundle {
+	return Bundle{
+		PrimaryBlock: PrimaryBlock{
+			Flags:            DestinationIsSingleton | StatusRequestDelivery | 1<<priorityShift,
+			Destination:      "dtn://dest/app",
+			Source:           "dtn://src/app",
+			ReportTo:         "dtn://src/app",
+			Custodian:        "dtn:none",
+			CreationTime:     uint64(bpv7.DtnTimeNow()) / 1000,
+			CreationSequence: 23,
+			Lifetime:         3600,
+		},
+		CanonicalBlocks: []CanonicalBlock{
+			{Type: PayloadBlockType, Data: []byte("hello world")},
+		},
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	fragment := testBundle()
+	fragment.PrimaryBlock.Flags |= IsFragment
+	fragment.PrimaryBlock.FragmentOffset = 5
+	fragment.PrimaryBlock.TotalADULength = 42
+
+	references := testBundle()
+	references.CanonicalBlocks = append(references.CanonicalBlocks, CanonicalBlock{
+		Type:          0xc0,
+		Flags:         ReplicateBlock,
+		EIDReferences: []string{"dtn://src/app", "ipn:23.42"},
+		Data:          []byte{0x01, 0x02},
+	})
+
+	for _, b := range []Bundle{testBundle(), fragment, references} {
+		buff := new(bytes.Buffer)
+		if err := b.WriteBundle(buff); err != nil {
+			t.Fatal(err)
+		}
+		if !IsBPv6(buff.Bytes()) {
+			t.Fatalf("Serialised bundle starts with 0x%x", buff.Bytes()[0])
+		}
+
+		b2, err := ParseBundle(buff)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// WriteBundle flags the last block
+		b.CanonicalBlocks[len(b.CanonicalBlocks)-1].Flags |= LastBlock
+		for i := range b.CanonicalBlocks {
+			if len(b.CanonicalBlocks[i].EIDReferences) > 0 {
+				b.CanonicalBlocks[i].Flags |= HasEIDReferences
+			}
+		}
+		if !reflect.DeepEqual(b, b2) {
+			t.Fatalf("Bundle changed:\n%v\n%v", b, b2)
+		}
+	}
+}
+
+func TestParseBundleInvalid(t *testing.T) {
+	buff := new(bytes.Buffer)
+	if err := testBundle().WriteBundle(buff); err != nil {
+		t.Fatal(err)
+	}
+	data := buff.Bytes()
+
+	wrongVersion := append([]byte{0x07}, data[1:]...)
+	truncated := data[:len(data)-3]
+
+	for _, invalid := range [][]byte{wrongVersion, truncated, {}} {
+		if _, err := ParseBundle(bytes.NewReader(invalid)); err == nil {
+			t.Fatalf("Invalid bundle %x was parsed", invalid)
+		}
+	}
+}
+
+func TestBundleToBPv7(t *testing.T) {
+	b6 := testBundle()
+	b6.PrimaryBlock.Flags = b6.PrimaryBlock.Flags&^(priorityMask<<priorityShift) | 2<<priorityShift
+
+	creationTime := bpv7.DtnTime(b6.PrimaryBlock.CreationTime * 1000)
+
+	b7, err := b6.ToBPv7()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pb := b7.PrimaryBlock
+	if pb.Destination.String() != "dtn://dest/app" || pb.SourceNode.String() != "dtn://src/app" {
+		t.Fatalf("Endpoints differ: %v, %v", pb.Destination, pb.SourceNode)
+	}
+	if pb.BundleControlFlags != bpv7.StatusRequestDelivery {
+		t.Fatalf("Flags differ: %v", pb.BundleControlFlags)
+	}
+	if pb.Lifetime != 3600*1000 || pb.CreationTimestamp.DtnTime() != creationTime {
+		t.Fatalf("Times differ: %d, %v", pb.Lifetime, pb.CreationTimestamp)
+	}
+	if pb.CreationTimestamp.SequenceNumber() != 23 {
+		t.Fatalf("Sequence number differs: %v", pb.CreationTimestamp)
+	}
+	if b7.Priority() != bpv7.PriorityExpedited {
+		t.Fatalf("Priority differs: %v", b7.Priority())
+	}
+	if payload, err := b7.PayloadBlock(); err != nil {
+		t.Fatal(err)
+	} else if data := payload.Value.(*bpv7.PayloadBlock).Data(); !bytes.Equal(data, []byte("hello world")) {
+		t.Fatalf("Payload differs: %s", data)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&b7, buff); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bpv7.ParseBundle(buff); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBundleToBPv7Lossy(t *testing.T) {
+	tests := map[string]func(*Bundle){
+		"administrative record": func(b *Bundle) { b.PrimaryBlock.Flags |= AdministrativeRecordPayload },
+		"custody transfer":      func(b *Bundle) { b.PrimaryBlock.Flags |= CustodyTransferRequested },
+		"custody report":        func(b *Bundle) { b.PrimaryBlock.Flags |= StatusRequestCustodyAcceptance },
+		"reserved priority":     func(b *Bundle) { b.PrimaryBlock.Flags |= priorityMask << priorityShift },
+		"unknown flag":          func(b *Bundle) { b.PrimaryBlock.Flags |= 1 << 20 },
+		"custodian":             func(b *Bundle) { b.PrimaryBlock.Custodian = "dtn://custodian/" },
+		"unknown scheme":        func(b *Bundle) { b.PrimaryBlock.Destination = "foo:bar" },
+		"no payload":            func(b *Bundle) { b.CanonicalBlocks[0].Type = 0xc0 },
+		"unknown block": func(b *Bundle) {
+			b.CanonicalBlocks = append(b.CanonicalBlocks, CanonicalBlock{Type: 0xc0})
+		},
+		"references": func(b *Bundle) {
+			b.CanonicalBlocks[0].EIDReferences = []string{"dtn://src/app"}
+		},
+	}
+
+	for name, modify := range tests {
+		b := testBundle()
+		modify(&b)
+		if _, err := b.ToBPv7(); err == nil {
+			t.Fatalf("Bundle with %s was converted", name)
+		}
+	}
+
+	discardable := testBundle()
+	discardable.CanonicalBlocks = append(discardable.CanonicalBlocks, CanonicalBlock{Type: 0xc0, Flags: DiscardBlock})
+	if _, err := discardable.ToBPv7(); err != nil {
+		t.Fatalf("Bundle with discardable block was not converted: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package bpv6
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// flagMapping translates each supported BPv6 processing flag into its BPv7 counterpart. DestinationIsSingleton has
+// no counterpart, as BPv7 derives this from the endpoint itself.
+var flagMapping = map[ProcessingFlags]bpv7.BundleControlFlags{
+	IsFragment:             bpv7.IsFragment,
+	MustNotFragment:        bpv7.MustNotFragmented,
+	DestinationIsSingleton: 0,
+	RequestApplicationAck:  bpv7.RequestUserApplicationAck,
+	StatusRequestReception: bpv7.StatusRequestReception,
+	StatusRequestForward:   bpv7.StatusRequestForward,
+	StatusRequestDelivery:  bpv7.StatusRequestDelivery,
+	StatusRequestDeletion:  bpv7.StatusRequestDeletion,
+}
+
+// priorityMapping translates the BPv6 class of service into a bpv7.Priority.
+var priorityMapping = map[uint64]bpv7.Priority{
+	0: bpv7.PriorityBulk,
+	1: bpv7.PriorityNormal,
+	2: bpv7.PriorityExpedited,
+}
+
+// secondsToMilliseconds converts BPv6's seconds into BPv7's milliseconds.
+func secondsToMilliseconds(seconds uint64, field string) (uint64, error) {
+	if seconds > math.MaxUint64/1000 {
+		return 0, fmt.Errorf("%s of %d seconds exceeds the BPv7 range", field, seconds)
+	}
+	return seconds * 1000, nil
+}
+
+// convertFlags checks the processing flags for features without a BPv7 equivalent and maps the remaining ones.
+func convertFlags(flags ProcessingFlags) (bcf bpv7.BundleControlFlags, priority bpv7.Priority, err error) {
+	if flags.Has(AdministrativeRecordPayload) {
+		err = fmt.Errorf("BPv6 administrative records cannot be converted")
+		return
+	}
+	if flags.Has(CustodyTransferRequested | StatusRequestCustodyAcceptance) {
+		err = fmt.Errorf("BPv6 custody transfer has no BPv7 equivalent")
+		return
+	}
+
+	var ok bool
+	if priority, ok = priorityMapping[flags.Priority()]; !ok {
+		err = fmt.Errorf("BPv6 class of service %d is reserved", flags.Priority())
+		return
+	}
+
+	remaining := flags &^ (priorityMask << priorityShift)
+	for v6Flag, v7Flag := range flagMapping {
+		if remaining.Has(v6Flag) {
+			bcf |= v7Flag
+			remaining &^= v6Flag
+		}
+	}
+	if remaining != 0 {
+		err = fmt.Errorf("BPv6 processing flags 0x%x are unknown", uint64(remaining))
+	}
+	return
+}
+
+// convertCanonicalBlocks converts the Payload Block. Other blocks are dropped if flagged to be discarded when they
+// cannot be processed; otherwise the conversion fails, as their BPv6 semantics are lost.
+func convertCanonicalBlocks(blocks []CanonicalBlock) (payload *bpv7.PayloadBlock, err error) {
+	for i, cb := range blocks {
+		switch {
+		case len(cb.EIDReferences) > 0:
+			return nil, fmt.Errorf("BPv6 block %d of type %d has endpoint references", i, cb.Type)
+
+		case cb.Type == PayloadBlockType && payload == nil:
+			payload = bpv7.NewPayloadBlock(cb.Data)
+
+		case cb.Type == PayloadBlockType:
+			return nil, fmt.Errorf("BPv6 bundle has multiple payload blocks")
+
+		case !cb.Flags.Has(DiscardBlock):
+			return nil, fmt.Errorf("BPv6 block %d of type %d has no BPv7 equivalent", i, cb.Type)
+		}
+	}
+
+	if payload == nil {
+		return nil, fmt.Errorf("BPv6 bundle has no payload block")
+	}
+	return payload, nil
+}
+
+// ToBPv7 converts this Bundle into an equivalent BPv7 Bundle. An error is returned if the conversion would lose
+// information, e.g., for custody transfers, administrative records or unknown extension blocks.
+func (b Bundle) ToBPv7() (bndl bpv7.Bundle, err error) {
+	pb := b.PrimaryBlock
+
+	bcf, priority, err := convertFlags(pb.Flags)
+	if err != nil {
+		return
+	}
+
+	if custodian, custodianErr := bpv7.NewEndpointID(pb.Custodian); custodianErr != nil || custodian != bpv7.DtnNone() {
+		err = fmt.Errorf("BPv6 custodian %q has no BPv7 equivalent", pb.Custodian)
+		return
+	}
+
+	var endpoints [3]bpv7.EndpointID
+	for i, eid := range []string{pb.Destination, pb.Source, pb.ReportTo} {
+		if endpoints[i], err = bpv7.NewEndpointID(eid); err != nil {
+			err = fmt.Errorf("BPv6 endpoint %q cannot be converted: %v", eid, err)
+			return
+		}
+	}
+
+	creationTime, err := secondsToMilliseconds(pb.CreationTime, "creation time")
+	if err != nil {
+		return
+	}
+	lifetime, err := secondsToMilliseconds(pb.Lifetime, "lifetime")
+	if err != nil {
+		return
+	}
+
+	payload, err := convertCanonicalBlocks(b.CanonicalBlocks)
+	if err != nil {
+		return
+	}
+
+	primary := bpv7.NewPrimaryBlock(
+		bcf,
+		endpoints[0],
+		endpoints[1],
+		bpv7.NewCreationTimestamp(bpv7.DtnTime(creationTime), pb.CreationSequence),
+		lifetime)
+	primary.ReportTo = endpoints[2]
+	if primary.HasFragmentation() {
+		primary.FragmentOffset = pb.FragmentOffset
+		primary.TotalDataLength = pb.TotalADULength
+	}
+	primary.SetCRCType(bpv7.CRC32)
+
+	canonicals := []bpv7.CanonicalBlock{bpv7.NewCanonicalBlock(1, 0, payload)}
+	if priority != bpv7.PriorityNormal {
+		canonicals = append(canonicals, bpv7.NewCanonicalBlock(2, bpv7.ReplicateBlock, bpv7.NewPriorityBlock(priority)))
+	}
+
+	return bpv7.NewBundle(primary, canonicals)
+}
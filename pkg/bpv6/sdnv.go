@@ -0,0 +1,43 @@
+package bpv6
+
+import (
+	"fmt"
+	"io"
+)
+
+// maxSDNVLength is the number of bytes of the longest SDNV fitting into an uint64.
+const maxSDNVLength = 10
+
+// ReadSDNV reads a Self-Delimiting Numeric Value, as defined in RFC 5050 section 4.1.
+func ReadSDNV(r io.ByteReader) (n uint64, err error) {
+	for i := 0; i < maxSDNVLength; i++ {
+		b, bErr := r.ReadByte()
+		if bErr != nil {
+			return 0, bErr
+		}
+
+		if n > (^uint64(0))>>7 {
+			return 0, fmt.Errorf("SDNV exceeds 64 bits")
+		}
+		n = n<<7 | uint64(b&0x7f)
+
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("SDNV exceeds %d bytes", maxSDNVLength)
+}
+
+// WriteSDNV writes a Self-Delimiting Numeric Value, as defined in RFC 5050 section 4.1.
+func WriteSDNV(n uint64, w io.Writer) error {
+	var buff [maxSDNVLength]byte
+	i := len(buff) - 1
+	buff[i] = byte(n & 0x7f)
+	for n >>= 7; n > 0; n >>= 7 {
+		i--
+		buff[i] = byte(n&0x7f) | 0x80
+	}
+
+	_, err := w.Write(buff[i:])
+	return err
+}
@@ -0,0 +1,66 @@
+package bpv6
+
+import (
+	"bytes"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestSDNVRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.Uint64().Draw(t, "n")
+
+		buff := new(bytes.Buffer)
+		if err := WriteSDNV(n, buff); err != nil {
+			t.Fatal(err)
+		}
+
+		if n2, err := ReadSDNV(buff); err != nil {
+			t.Fatal(err)
+		} else if n != n2 {
+			t.Fatalf("SDNV changed from %d to %d", n, n2)
+		} else if buff.Len() != 0 {
+			t.Fatalf("SDNV left %d bytes", buff.Len())
+		}
+	})
+}
+
+func TestSDNVKnownValues(t *testing.T) {
+	// Examples of RFC 5050 section 4.1
+	tests := []struct {
+		n    uint64
+		sdnv []byte
+	}{
+		{0xabc, []byte{0x95, 0x3c}},
+		{0x1234, []byte{0xa4, 0x34}},
+		{0x4234, []byte{0x81, 0x84, 0x34}},
+		{0x7f, []byte{0x7f}},
+	}
+
+	for _, test := range tests {
+		buff := new(bytes.Buffer)
+		if err := WriteSDNV(test.n, buff); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(buff.Bytes(), test.sdnv) {
+			t.Fatalf("SDNV of 0x%x is %x, expected %x", test.n, buff.Bytes(), test.sdnv)
+		}
+	}
+}
+
+func TestSDNVOverflow(t *testing.T) {
+	tests := [][]byte{
+		// 2^64 needs 65 bits
+		{0x82, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00},
+		// Too long, even with leading zeros
+		{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01},
+		// Unterminated
+		{0x81, 0x81},
+	}
+
+	for _, test := range tests {
+		if n, err := ReadSDNV(bytes.NewReader(test)); err == nil {
+			t.Fatalf("SDNV %x was read as %d", test, n)
+		}
+	}
+}
@@ -152,6 +152,14 @@ const (
 	// BlockUnsupported is the "Block unsupported" bundle status report reason
 	// code.
 	BlockUnsupported StatusReportReason = 11
+
+	// MissingSecurityOperation is the "Missing security operation" bundle status
+	// report reason code, defined in RFC 9172.
+	MissingSecurityOperation StatusReportReason = 12
+
+	// FailedSecurityOperation is the "Failed security operation" bundle status
+	// report reason code, defined in RFC 9172.
+	FailedSecurityOperation StatusReportReason = 15
 )
 
 func (srr StatusReportReason) String() string {
@@ -192,6 +200,12 @@ func (srr StatusReportReason) String() string {
 	case BlockUnsupported:
 		return "Block unsupported"
 
+	case MissingSecurityOperation:
+		return "Missing security operation"
+
+	case FailedSecurityOperation:
+		return "Failed security operation"
+
 	default:
 		return "unknown"
 	}
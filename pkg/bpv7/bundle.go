@@ -15,6 +15,8 @@ import (
 
 	"github.com/dtn7/cboring"
 	"github.com/hashicorp/go-multierror"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
 )
 
 // Bundle represents a bundle as defined in section 4.2.1. Each Bundle contains
@@ -203,26 +205,57 @@ func (b Bundle) String() string {
 	return b.ID().String()
 }
 
+// age of this Bundle, based on the PrimaryBlock's creation time or, if it is zero, an optional Bundle Age Block. A
+// clockless node, see SetClockless, always uses the Bundle Age Block. Without one, the age is unknown.
+func (b Bundle) age() (age time.Duration, known bool) {
+	if !b.PrimaryBlock.CreationTimestamp.IsZeroTime() && !IsClockless() {
+		return clock.Now().Sub(b.PrimaryBlock.CreationTimestamp.DtnTime().Time()), true
+	}
+	if bab, err := b.ExtensionBlock(ExtBlockTypeBundleAgeBlock); err == nil {
+		return time.Duration(bab.Value.(*BundleAgeBlock).Age()) * time.Millisecond, true
+	}
+	return 0, false
+}
+
 // IsLifetimeExceeded of this Bundle by checking an optional Bundle Age Block and the PrimaryBlock's Lifetime.
+//
+// A bundle of a zero creation time without a Bundle Age Block is always exceeded. A clockless node, see SetClockless,
+// does not consider bundles of a non-zero creation time without a Bundle Age Block to be exceeded, as it cannot tell.
 func (b Bundle) IsLifetimeExceeded() bool {
-	if b.PrimaryBlock.CreationTimestamp.IsZeroTime() {
-		if bab, err := b.ExtensionBlock(ExtBlockTypeBundleAgeBlock); err != nil {
-			return true
-		} else {
-			return bab.Value.(*BundleAgeBlock).Age() > b.PrimaryBlock.Lifetime
-		}
+	age, known := b.age()
+	if !known {
+		return b.PrimaryBlock.CreationTimestamp.IsZeroTime()
+	}
+	return age > time.Duration(b.PrimaryBlock.Lifetime)*time.Millisecond
+}
+
+// RemainingLifetime of this Bundle, based on an optional Bundle Age Block or the PrimaryBlock's Lifetime. An exceeded
+// lifetime results in zero. A bundle of an unknown age, see IsLifetimeExceeded, has its whole lifetime remaining,
+// unless its creation time is zero.
+func (b Bundle) RemainingLifetime() time.Duration {
+	lifetime := time.Duration(b.PrimaryBlock.Lifetime) * time.Millisecond
+
+	age, known := b.age()
+	if !known && b.PrimaryBlock.CreationTimestamp.IsZeroTime() {
+		return 0
 	}
 
-	maxTimestamp := b.PrimaryBlock.CreationTimestamp.DtnTime().Time().Add(
-		time.Duration(b.PrimaryBlock.Lifetime) * time.Millisecond)
-	return time.Now().After(maxTimestamp)
+	if age >= lifetime {
+		return 0
+	}
+	return lifetime - age
 }
 
-// CheckValid returns an array of errors for incorrect data.
+// CheckValid returns an array of errors for incorrect data. Errors of a single block are InvalidBlockErrors.
 func (b Bundle) CheckValid() (errs error) {
 	// Check blocks for errors
 	b.forEachBlock(func(blck block) {
 		if blckErr := blck.CheckValid(); blckErr != nil {
+			if cb, ok := blck.(*CanonicalBlock); ok {
+				blckErr = NewInvalidBlockError(cb.BlockNumber, cb.Value.BlockTypeCode(), blckErr)
+			} else {
+				blckErr = NewInvalidBlockError(0, 0, blckErr)
+			}
 			errs = multierror.Append(errs, blckErr)
 		}
 	})
@@ -261,7 +294,7 @@ func (b Bundle) CheckValid() (errs error) {
 
 		// Context aware block self-check
 		if blckErr := cb.Value.CheckContextValid(&b); blckErr != nil {
-			errs = multierror.Append(errs, blckErr)
+			errs = multierror.Append(errs, NewInvalidBlockError(cb.BlockNumber, cb.Value.BlockTypeCode(), blckErr))
 		}
 	}
 
@@ -29,6 +29,10 @@ type BundleBuilder struct {
 	canonicals       []CanonicalBlock
 	canonicalCounter uint64
 	crcType          CRCType
+
+	// payloadChecksum requests a PayloadChecksumBlock, which can only be created for the final payload in Build.
+	payloadChecksum      bool
+	payloadChecksumFlags BlockControlFlags
 }
 
 // Builder creates a new BundleBuilder.
@@ -75,14 +79,42 @@ func (bldr *BundleBuilder) Build() (bndl Bundle, err error) {
 		return
 	}
 
+	// A clockless node's bundles of a zero creation time need a Bundle Age Block
+	if IsClockless() && bldr.primary.CreationTimestamp.IsZeroTime() && !bldr.hasBundleAgeBlock() {
+		bldr.BundleAgeBlock(0)
+	}
+
 	bndl, err = NewBundle(bldr.primary, bldr.canonicals)
-	if err == nil {
-		bndl.SetCRCType(bldr.crcType)
+	if err != nil {
+		return
 	}
 
+	if bldr.payloadChecksum {
+		pcb, pcbErr := NewPayloadChecksumBlock(bndl)
+		if pcbErr != nil {
+			err = pcbErr
+			return
+		}
+		if err = bndl.AddExtensionBlock(NewCanonicalBlock(0, bldr.payloadChecksumFlags, pcb)); err != nil {
+			return
+		}
+	}
+
+	bndl.SetCRCType(bldr.crcType)
+
 	return
 }
 
+// hasBundleAgeBlock checks if a Bundle Age Block was added.
+func (bldr *BundleBuilder) hasBundleAgeBlock() bool {
+	for _, cb := range bldr.canonicals {
+		if cb.Value.BlockTypeCode() == ExtBlockTypeBundleAgeBlock {
+			return true
+		}
+	}
+	return false
+}
+
 // mustBuild is like Build, but panics on an error. This method is only intended for internal testing.
 func (bldr *BundleBuilder) mustBuild() Bundle {
 	if b, err := bldr.Build(); err != nil {
@@ -205,8 +237,12 @@ func (bldr *BundleBuilder) CreationTimestampEpoch() *BundleBuilder {
 }
 
 // CreationTimestampNow sets the bundle's creation timestamp to the current
-// time, stored in its primary block.
+// time, stored in its primary block. A clockless node uses the epoch time,
+// see SetClockless.
 func (bldr *BundleBuilder) CreationTimestampNow() *BundleBuilder {
+	if IsClockless() {
+		return bldr.CreationTimestampEpoch()
+	}
 	return bldr.creationTimestamp(DtnTimeNow())
 }
 
@@ -379,6 +415,132 @@ func (bldr *BundleBuilder) HopCountBlock(args ...interface{}) *BundleBuilder {
 	return bldr.Canonical(NewHopCountBlock(uint8(limit)), flags)
 }
 
+// TravelHistoryBlock adds a travel history block to this bundle. The parameters are:
+//
+//	Limit[, BlockControlFlags]
+//
+//	where Limit is the maximum number of traversed nodes to be recorded and
+//	BlockControlFlags are _optional_ block processing control flags
+func (bldr *BundleBuilder) TravelHistoryBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	limit, chk := args[0].(int)
+	if !chk || limit < 0 {
+		bldr.err = fmt.Errorf("TravelHistoryBlock received wrong parameter type or a negative limit")
+		return bldr
+	}
+
+	flags := bldr.canonicalParseFlags(args) | ReplicateBlock
+
+	return bldr.Canonical(NewTravelHistoryBlock(uint64(limit)), flags)
+}
+
+// PriorityBlock adds a priority block to this bundle. The parameters are:
+//
+//	Priority[, BlockControlFlags]
+//
+//	where Priority is a Priority or a string, parsed by PriorityFromString, and
+//	BlockControlFlags are _optional_ block processing control flags
+func (bldr *BundleBuilder) PriorityBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	var priority Priority
+	switch p := args[0].(type) {
+	case Priority:
+		priority = p
+	case string:
+		if parsed, err := PriorityFromString(p); err != nil {
+			bldr.err = err
+			return bldr
+		} else {
+			priority = parsed
+		}
+	default:
+		bldr.err = fmt.Errorf("PriorityBlock received wrong parameter type")
+		return bldr
+	}
+
+	flags := bldr.canonicalParseFlags(args) | ReplicateBlock
+
+	return bldr.Canonical(NewPriorityBlock(priority), flags)
+}
+
+// SourceRouteBlock adds a source route block to this bundle. The parameters are:
+//
+//	Waypoints[, BlockControlFlags]
+//
+//	where Waypoints is a slice of EndpointIDs or strings, the latter parsed as URIs, and
+//	BlockControlFlags are _optional_ block processing control flags
+func (bldr *BundleBuilder) SourceRouteBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	var rawWaypoints []interface{}
+	switch w := args[0].(type) {
+	case []EndpointID:
+		for _, eid := range w {
+			rawWaypoints = append(rawWaypoints, eid)
+		}
+	case []string:
+		for _, eid := range w {
+			rawWaypoints = append(rawWaypoints, eid)
+		}
+	case []interface{}:
+		rawWaypoints = w
+	default:
+		bldr.err = fmt.Errorf("SourceRouteBlock received wrong parameter type")
+		return bldr
+	}
+
+	waypoints := make([]EndpointID, len(rawWaypoints))
+	for i, rawWaypoint := range rawWaypoints {
+		if waypoint, err := bldrParseEndpoint(rawWaypoint); err != nil {
+			bldr.err = fmt.Errorf("SourceRouteBlock waypoint %d: %v", i, err)
+			return bldr
+		} else {
+			waypoints[i] = waypoint
+		}
+	}
+
+	flags := bldr.canonicalParseFlags(args) | ReplicateBlock
+
+	return bldr.Canonical(NewSourceRouteBlock(waypoints...), flags)
+}
+
+// ContentTypeBlock adds a content type block to this bundle. The parameters are:
+//
+//	ContentType[, Filename]
+//
+//	where ContentType is the payload's MIME content type and
+//	Filename is the payload's _optional_ filename, both strings
+func (bldr *BundleBuilder) ContentTypeBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	if len(args) == 0 || len(args) > 2 {
+		bldr.err = fmt.Errorf("ContentTypeBlock received %d instead of one or two parameters", len(args))
+		return bldr
+	}
+
+	fields := make([]string, 2)
+	for i, arg := range args {
+		if field, ok := arg.(string); !ok {
+			bldr.err = fmt.Errorf("ContentTypeBlock received wrong parameter type")
+			return bldr
+		} else {
+			fields[i] = field
+		}
+	}
+
+	return bldr.Canonical(NewContentTypeBlock(fields[0], fields[1]))
+}
+
 // PayloadBlock adds a payload block to this bundle. The parameters are:
 //
 //	Data[, BlockControlFlags]
@@ -425,6 +587,32 @@ func (bldr *BundleBuilder) PreviousNodeBlock(args ...interface{}) *BundleBuilder
 	return bldr.Canonical(NewPreviousNodeBlock(eid), flags)
 }
 
+// PayloadChecksumBlock adds a payload checksum block to this bundle, calculated for the final payload. The parameters
+// are:
+//
+//	[BlockControlFlags]
+//
+//	where BlockControlFlags are _optional_ block processing control flags
+func (bldr *BundleBuilder) PayloadChecksumBlock(args ...interface{}) *BundleBuilder {
+	if bldr.err != nil {
+		return bldr
+	}
+
+	var flags BlockControlFlags
+	if len(args) == 1 {
+		if passedFlags, ok := args[0].(BlockControlFlags); !ok {
+			bldr.err = fmt.Errorf("PayloadChecksumBlock's parameter are no BlockControlFlags")
+			return bldr
+		} else {
+			flags = passedFlags
+		}
+	}
+
+	bldr.payloadChecksum = true
+	bldr.payloadChecksumFlags = flags | ReplicateBlock
+	return bldr
+}
+
 // AdministrativeRecord configures an AdministrativeRecord as the Payload. Furthermore, the AdministrativeRecordPayload
 // BundleControlFlags is set.
 func (bldr *BundleBuilder) AdministrativeRecord(ar AdministrativeRecord) *BundleBuilder {
@@ -491,6 +679,28 @@ func (bldr *BundleBuilder) StatusReport(args ...interface{}) *BundleBuilder {
 	return bldr.AdministrativeRecord(NewStatusReport(bundle, statusItem, reason, t))
 }
 
+// bldrParseBundleCtrlFlags parses BundleControlFlags or a list of their string representations, e.g., from JSON.
+func bldrParseBundleCtrlFlags(args interface{}) (BundleControlFlags, error) {
+	switch flags := args.(type) {
+	case BundleControlFlags:
+		return flags, nil
+	case []string:
+		return BundleControlFlagsFromStrings(flags)
+	case []interface{}:
+		fields := make([]string, len(flags))
+		for i, flag := range flags {
+			field, ok := flag.(string)
+			if !ok {
+				return 0, fmt.Errorf("bundle_ctrl_flags needs strings, not %T", flag)
+			}
+			fields[i] = field
+		}
+		return BundleControlFlagsFromStrings(fields)
+	default:
+		return 0, fmt.Errorf("bundle_ctrl_flags needs BundleControlFlags or a list of strings, not %T", args)
+	}
+}
+
 // BuildFromMap creates a Bundle from a map which "calls" the BundleBuilder's methods.
 //
 // This function does not use reflection or other dark magic. So it is safe to be called by unchecked data.
@@ -542,8 +752,10 @@ func BuildFromMap(m map[string]interface{}) (bndl Bundle, err error) {
 
 		// func (bldr *BundleBuilder) BundleCtrlFlags(bcf BundleControlFlags) *BundleBuilder
 		case "bundle_ctrl_flags":
-			// TODO: implement
-			err = fmt.Errorf("bundle_ctrl_flags is not yet implemented")
+			var bcf BundleControlFlags
+			if bcf, err = bldrParseBundleCtrlFlags(args); err == nil {
+				bldr.BundleCtrlFlags(bcf)
+			}
 
 		// func (bldr *BundleBuilder) Canonical(args ...interface{}) *BundleBuilder
 		case "canonical":
@@ -569,6 +781,44 @@ func BuildFromMap(m map[string]interface{}) (bndl Bundle, err error) {
 		case "previous_node_block":
 			bldr.PreviousNodeBlock(args)
 
+		// func (bldr *BundleBuilder) TravelHistoryBlock(args ...interface{}) *BundleBuilder
+		case "travel_history_block":
+			// JSON numbers are decoded as float64
+			if fArgs, ok := args.(float64); ok {
+				bldr.TravelHistoryBlock(int(fArgs))
+			} else {
+				bldr.TravelHistoryBlock(args)
+			}
+
+		// func (bldr *BundleBuilder) PriorityBlock(args ...interface{}) *BundleBuilder
+		case "priority_block":
+			bldr.PriorityBlock(args)
+
+		// func (bldr *BundleBuilder) SourceRouteBlock(args ...interface{}) *BundleBuilder
+		case "source_route_block":
+			bldr.SourceRouteBlock(args)
+
+		// func (bldr *BundleBuilder) ContentTypeBlock(args ...interface{}) *BundleBuilder
+		case "content_type_block":
+			switch ctArgs := args.(type) {
+			case string:
+				bldr.ContentTypeBlock(ctArgs)
+			case map[string]interface{}:
+				contentType, _ := ctArgs["content_type"].(string)
+				filename, _ := ctArgs["filename"].(string)
+				bldr.ContentTypeBlock(contentType, filename)
+			default:
+				err = fmt.Errorf("content_type_block needs a string or an object, not %T", args)
+			}
+
+		// func (bldr *BundleBuilder) PayloadChecksumBlock(args ...interface{}) *BundleBuilder
+		case "payload_checksum_block":
+			if enabled, ok := args.(bool); !ok {
+				err = fmt.Errorf("payload_checksum_block needs a bool, not %T", args)
+			} else if enabled {
+				bldr.PayloadChecksumBlock()
+			}
+
 		default:
 			err = fmt.Errorf("method %s is either not implemented or not existing", method)
 		}
@@ -224,6 +224,41 @@ func TestBuildFromMap(t *testing.T) {
 				mustBuild(),
 			wantErr: false,
 		},
+		{
+			name: "control flags",
+			args: map[string]interface{}{
+				"destination":              "dtn://dst/",
+				"source":                   "dtn://src/",
+				"creation_timestamp_epoch": true,
+				"lifetime":                 "24h",
+				"bundle_age_block":         23,
+				"bundle_ctrl_flags":        []string{"REQUESTED_DELIVERY_STATUS_REPORT", "MUST_NOT_BE_FRAGMENTED"},
+				"payload_block":            "hello world",
+			},
+			wantBndl: Builder().
+				Destination("dtn://dst/").
+				Source("dtn://src/").
+				CreationTimestampEpoch().
+				Lifetime("24h").
+				BundleCtrlFlags(StatusRequestDelivery | MustNotFragmented).
+				BundleAgeBlock(23).
+				PayloadBlock([]byte("hello world")).
+				mustBuild(),
+			wantErr: false,
+		},
+		{
+			name: "unknown control flag",
+			args: map[string]interface{}{
+				"destination":              "dtn://dst/",
+				"source":                   "dtn://src/",
+				"creation_timestamp_epoch": true,
+				"lifetime":                 "24h",
+				"bundle_ctrl_flags":        []string{"NOPE"},
+				"payload_block":            "hello world",
+			},
+			wantBndl: Bundle{},
+			wantErr:  true,
+		},
 		{
 			name: "illegal method",
 			args: map[string]interface{}{
@@ -267,6 +302,7 @@ func TestBuildFromMapJSON(t *testing.T) {
 		"creation_timestamp_epoch": 1,
 		"lifetime":               "24h",
 		"bundle_age_block":        23,
+		"bundle_ctrl_flags":       ["REQUESTED_DELIVERY_STATUS_REPORT"],
 		"payload_block":          "hello world"
 	}`)
 
@@ -280,6 +316,7 @@ func TestBuildFromMapJSON(t *testing.T) {
 		CreationTimestampEpoch().
 		Lifetime("24h").
 		BundleAgeBlock(23).
+		BundleCtrlFlags(StatusRequestDelivery).
 		PayloadBlock([]byte("hello world")).
 		mustBuild()
 
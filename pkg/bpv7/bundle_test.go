@@ -7,14 +7,150 @@ package bpv7
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
 )
 
+func TestBundleIsLifetimeExceeded(t *testing.T) {
+	fc := clock.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc.Advance(59 * time.Minute)
+	if bndl.IsLifetimeExceeded() {
+		t.Fatalf("Lifetime exceeded after 59 minutes")
+	}
+
+	fc.Advance(2 * time.Minute)
+	if !bndl.IsLifetimeExceeded() {
+		t.Fatalf("Lifetime not exceeded after 61 minutes")
+	}
+}
+
+func TestBundleClockless(t *testing.T) {
+	fc := clock.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	_, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampTime(fc.Now().Add(-2 * time.Hour)).
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err == nil {
+		t.Fatal("Bundle of an exceeded lifetime was built")
+	}
+
+	SetClockless(true)
+	defer SetClockless(false)
+
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bndl.PrimaryBlock.CreationTimestamp.IsZeroTime() {
+		t.Fatalf("Clockless bundle has creation time %v", bndl.PrimaryBlock.CreationTimestamp)
+	}
+	if !bndl.HasExtensionBlock(ExtBlockTypeBundleAgeBlock) {
+		t.Fatal("Clockless bundle has no Bundle Age Block")
+	}
+
+	fc.Advance(2 * time.Hour)
+	if bndl.IsLifetimeExceeded() {
+		t.Fatal("Lifetime of a young bundle was judged by the clock")
+	}
+
+	bab, _ := bndl.ExtensionBlock(ExtBlockTypeBundleAgeBlock)
+	bab.Value.(*BundleAgeBlock).Increment(uint64((61 * time.Minute).Milliseconds()))
+	if !bndl.IsLifetimeExceeded() {
+		t.Fatal("Lifetime not exceeded for an age of 61 minutes")
+	}
+
+	timedBndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampTime(fc.Now().Add(-2 * time.Hour)).
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatalf("Clockless node rejected a bundle by its creation time: %v", err)
+	}
+	if remaining := timedBndl.RemainingLifetime(); remaining != time.Hour {
+		t.Fatalf("Remaining lifetime of a bundle of an unknown age is %v", remaining)
+	}
+}
+
+func TestBundleRemainingLifetime(t *testing.T) {
+	fc := clock.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc.Advance(15 * time.Minute)
+	if remaining := bndl.RemainingLifetime(); remaining != 45*time.Minute {
+		t.Fatalf("Remaining lifetime is %v after 15 minutes", remaining)
+	}
+
+	fc.Advance(time.Hour)
+	if remaining := bndl.RemainingLifetime(); remaining != 0 {
+		t.Fatalf("Remaining lifetime is %v after an exceeded lifetime", remaining)
+	}
+
+	ageBndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampEpoch().
+		Lifetime(time.Hour).
+		BundleAgeBlock(20 * time.Minute).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining := ageBndl.RemainingLifetime(); remaining != 40*time.Minute {
+		t.Fatalf("Remaining lifetime is %v for an age of 20 minutes", remaining)
+	}
+}
+
 func TestBundleApplyCRC(t *testing.T) {
 	var epPrim, _ = NewEndpointID("dtn://foo/bar/")
 	var creationTs = NewCreationTimestamp(42000000000000, 23)
@@ -212,6 +348,29 @@ func TestBundleCheckValid(t *testing.T) {
 	}
 }
 
+func TestBundleCheckValidInvalidBlock(t *testing.T) {
+	b := createNewBundle(
+		NewPrimaryBlock(MustNotFragmented,
+			MustNewEndpointID("dtn://dst/"), MustNewEndpointID("dtn://src/"),
+			NewCreationTimestamp(42000000000000, 0), 3600),
+		[]CanonicalBlock{
+			NewCanonicalBlock(2, 0, &HopCountBlock{Limit: 1, Count: 2}),
+			NewCanonicalBlock(1, 0, NewPayloadBlock(nil))})
+
+	err := b.CheckValid()
+	if !errors.Is(err, ErrInvalidBlock) {
+		t.Fatalf("Exceeded Hop Count block is not an invalid block: %v", err)
+	}
+
+	var blockErr *InvalidBlockError
+	if !errors.As(err, &blockErr) {
+		t.Fatalf("Error %v is no InvalidBlockError", err)
+	}
+	if blockErr.BlockNumber != 2 || blockErr.BlockTypeCode != ExtBlockTypeHopCountBlock {
+		t.Fatalf("Wrong block %d of type %d", blockErr.BlockNumber, blockErr.BlockTypeCode)
+	}
+}
+
 func TestBundleAddRemoveExtensionBlocks(t *testing.T) {
 	primary := NewPrimaryBlock(0,
 		MustNewEndpointID("dtn://dst/"),
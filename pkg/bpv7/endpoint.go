@@ -45,7 +45,16 @@ type EndpointType interface {
 	fmt.Stringer
 }
 
+var (
+	// uriSchemeRegexp matches an URI scheme name, as defined in RFC 3986, section 3.1.
+	uriSchemeRegexp = regexp.MustCompile("^[[:alpha:]][[:alnum:]+.-]*$")
+
+	// uriRegexp matches an URI and captures its scheme name.
+	uriRegexp = regexp.MustCompile("^([[:alpha:]][[:alnum:]+.-]*):.+$")
+)
+
 type endpointManager struct {
+	mutex   sync.RWMutex
 	typeMap map[uint64]reflect.Type
 	newMap  map[string]func(string) (EndpointType, error)
 }
@@ -68,7 +77,7 @@ func getEndpointManager() *endpointManager {
 		epTypes := []struct {
 			schemeNo   uint64
 			schemeName string
-			impl       interface{}
+			impl       EndpointType
 			newFunc    func(string) (EndpointType, error)
 		}{
 			{DtnEndpointSchemeNo, DtnEndpointSchemeName, DtnEndpoint{}, NewDtnEndpoint},
@@ -76,15 +85,85 @@ func getEndpointManager() *endpointManager {
 		}
 
 		for _, epType := range epTypes {
-			endpointMngr.typeMap[epType.schemeNo] = reflect.TypeOf(epType.impl)
-			endpointMngr.newMap[epType.schemeName] = epType.newFunc
-			gob.Register(epType.impl)
+			if err := endpointMngr.register(epType.schemeNo, epType.schemeName, epType.impl, epType.newFunc); err != nil {
+				panic(err)
+			}
 		}
 	}
 
 	return endpointMngr
 }
 
+// init registers the EndpointTypes for gob as well, which decodes stored EndpointIDs, e.g., of a store's metadata,
+// possibly before any EndpointID was parsed.
+func init() {
+	_ = getEndpointManager()
+}
+
+// register an EndpointType for both its scheme number and name.
+func (mngr *endpointManager) register(schemeNo uint64, schemeName string, impl EndpointType, newFunc func(string) (EndpointType, error)) error {
+	mngr.mutex.Lock()
+	defer mngr.mutex.Unlock()
+
+	implType := reflect.TypeOf(impl)
+	if implType == nil || implType.Kind() == reflect.Ptr {
+		return fmt.Errorf("EndpointType for URI scheme %s must be registered as a value, not %v", schemeName, implType)
+	} else if _, ok := reflect.PointerTo(implType).MethodByName("UnmarshalCbor"); !ok {
+		return fmt.Errorf("EndpointType %v lacks a pointer receiver UnmarshalCbor function", implType)
+	}
+
+	if _, ok := mngr.typeMap[schemeNo]; ok {
+		return fmt.Errorf("URI scheme number %d is already registered", schemeNo)
+	}
+	if _, ok := mngr.newMap[schemeName]; ok {
+		return fmt.Errorf("URI scheme %s is already registered", schemeName)
+	}
+
+	mngr.typeMap[schemeNo] = implType
+	mngr.newMap[schemeName] = newFunc
+	gob.Register(impl)
+
+	return nil
+}
+
+// lookupType returns the registered EndpointType's reflect.Type for a scheme number.
+func (mngr *endpointManager) lookupType(schemeNo uint64) (implType reflect.Type, ok bool) {
+	mngr.mutex.RLock()
+	defer mngr.mutex.RUnlock()
+
+	implType, ok = mngr.typeMap[schemeNo]
+	return
+}
+
+// lookupNew returns the registered EndpointType's constructor for a scheme name.
+func (mngr *endpointManager) lookupNew(schemeName string) (newFunc func(string) (EndpointType, error), ok bool) {
+	mngr.mutex.RLock()
+	defer mngr.mutex.RUnlock()
+
+	newFunc, ok = mngr.newMap[schemeName]
+	return
+}
+
+// RegisterEndpointType makes an additional URI scheme known, next to the built-in "dtn" and "ipn" schemes.
+// Afterwards, EndpointIDs of this scheme can be created by NewEndpointID and are (un)marshalled as CBOR.
+//
+// The impl must be a value of the EndpointType's struct implementation, whose UnmarshalCbor function has a pointer
+// receiver, as described for the EndpointType interface. The newFunc creates an EndpointType from an URI string,
+// e.g., NewDtnEndpoint. Both the scheme number and name must not be registered yet.
+//
+// The scheme numbers are listed in IANA's "Bundle Protocol URI Scheme Types" registry. Experimental schemes should
+// use a number from the private or experimental use range.
+func RegisterEndpointType(schemeNo uint64, schemeName string, impl EndpointType, newFunc func(string) (EndpointType, error)) error {
+	if !uriSchemeRegexp.MatchString(schemeName) {
+		return fmt.Errorf("URI scheme %s is not a valid scheme name", schemeName)
+	}
+	if newFunc == nil {
+		return fmt.Errorf("URI scheme %s has no constructor function", schemeName)
+	}
+
+	return getEndpointManager().register(schemeNo, schemeName, impl, newFunc)
+}
+
 // EndpointID represents an Endpoint ID as defined in section 4.1.5.1.
 // Its form is specified in an EndpointType, e.g., DtnEndpoint.
 type EndpointID struct {
@@ -93,8 +172,7 @@ type EndpointID struct {
 
 // NewEndpointID based on an URI, e.g., "dtn://seven/".
 func NewEndpointID(uri string) (e EndpointID, err error) {
-	re := regexp.MustCompile("^([[:alnum:]]+):.+$")
-	matches := re.FindStringSubmatch(uri)
+	matches := uriRegexp.FindStringSubmatch(uri)
 
 	if len(matches) == 0 {
 		err = fmt.Errorf("given URI does not match URI regexp")
@@ -102,7 +180,7 @@ func NewEndpointID(uri string) (e EndpointID, err error) {
 	}
 
 	scheme := matches[1]
-	if f, ok := getEndpointManager().newMap[scheme]; !ok {
+	if f, ok := getEndpointManager().lookupNew(scheme); !ok {
 		err = fmt.Errorf("no handler registered for URI scheme %s", scheme)
 	} else if et, etErr := f(uri); etErr != nil {
 		err = etErr
@@ -157,7 +235,7 @@ func (eid *EndpointID) UnmarshalCbor(r io.Reader) error {
 	// URI scheme name code
 	if scheme, err := cboring.ReadUInt(r); err != nil {
 		return err
-	} else if ept, ok := getEndpointManager().typeMap[scheme]; !ok {
+	} else if ept, ok := getEndpointManager().lookupType(scheme); !ok {
 		return fmt.Errorf("no URI scheme registered for scheme number %d", scheme)
 	} else {
 		epType = ept
@@ -0,0 +1,164 @@
+package bpv7
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EndpointPattern matches EndpointIDs against a pattern. It is meant to be used wherever a configuration or policy
+// refers to a set of endpoints, e.g., for routing rules, access control, or agent registrations.
+//
+//	Patterns for all endpoints:
+//	"*"
+//
+//	Patterns for the dtn scheme are globs on the whole URI:
+//	- "*" matches any sequence of characters except "/",
+//	- "**" matches any sequence of characters, including "/",
+//	- "?" matches any single character except "/".
+//	"dtn://sensor-*/**" matches all endpoints of nodes whose names start with "sensor-".
+//	"dtn://*/" matches the administrative endpoint of every node.
+//	"dtn:none" only matches the null endpoint.
+//
+//	Patterns for the ipn scheme consist of a node and a service part, each being "*", a number, or an inclusive
+//	range "from-to":
+//	"ipn:1-100.*" matches all services of the nodes 1 to 100.
+//	"ipn:*.42" matches the service 42 of all nodes.
+type EndpointPattern struct {
+	pattern string
+	match   func(EndpointID) bool
+}
+
+// NewEndpointPattern parses a pattern, as described for EndpointPattern.
+func NewEndpointPattern(pattern string) (p EndpointPattern, err error) {
+	p.pattern = pattern
+
+	switch {
+	case pattern == "*":
+		p.match = func(EndpointID) bool { return true }
+
+	case pattern == DtnEndpointDtnNone:
+		p.match = func(eid EndpointID) bool { return eid.String() == DtnEndpointDtnNone }
+
+	case strings.HasPrefix(pattern, DtnEndpointSchemeName+"://"):
+		var re *regexp.Regexp
+		if re, err = dtnGlobToRegexp(pattern); err != nil {
+			return
+		}
+		p.match = func(eid EndpointID) bool {
+			return eid.EndpointType != nil && eid.EndpointType.SchemeName() == DtnEndpointSchemeName &&
+				re.MatchString(eid.String())
+		}
+
+	case strings.HasPrefix(pattern, ipnEndpointSchemeName+":"):
+		nodePart, servicePart, found := strings.Cut(strings.TrimPrefix(pattern, ipnEndpointSchemeName+":"), ".")
+		if !found {
+			err = fmt.Errorf("ipn pattern %q lacks a service part", pattern)
+			return
+		}
+
+		var nodes, services numberRange
+		if nodes, err = parseNumberRange(nodePart); err != nil {
+			return
+		}
+		if services, err = parseNumberRange(servicePart); err != nil {
+			return
+		}
+		p.match = func(eid EndpointID) bool {
+			ipn, ok := eid.EndpointType.(IpnEndpoint)
+			return ok && nodes.contains(ipn.Node) && services.contains(ipn.Service)
+		}
+
+	default:
+		err = fmt.Errorf("pattern %q has no supported scheme", pattern)
+	}
+
+	return
+}
+
+// MustNewEndpointPattern parses a pattern like NewEndpointPattern, but panics on an error.
+func MustNewEndpointPattern(pattern string) EndpointPattern {
+	p, err := NewEndpointPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match checks if the EndpointID is matched by this pattern.
+func (p EndpointPattern) Match(eid EndpointID) bool {
+	return p.match != nil && p.match(eid)
+}
+
+func (p EndpointPattern) String() string {
+	return p.pattern
+}
+
+// MatchAnyPattern checks if the EndpointID is matched by at least one of the patterns.
+func MatchAnyPattern(patterns []EndpointPattern, eid EndpointID) bool {
+	for _, p := range patterns {
+		if p.Match(eid) {
+			return true
+		}
+	}
+	return false
+}
+
+// dtnGlobToRegexp translates a dtn glob pattern into an anchored regular expression.
+func dtnGlobToRegexp(pattern string) (*regexp.Regexp, error) {
+	var bldr strings.Builder
+	bldr.WriteString("^")
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				bldr.WriteString(".*")
+				i++
+			} else {
+				bldr.WriteString("[^/]*")
+			}
+		case '?':
+			bldr.WriteString("[^/]")
+		default:
+			bldr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	bldr.WriteString("$")
+	return regexp.Compile(bldr.String())
+}
+
+// numberRange is an inclusive range of numbers for ipn patterns.
+type numberRange struct {
+	from, to uint64
+}
+
+// parseNumberRange parses "*", a single number, or a range "from-to".
+func parseNumberRange(part string) (r numberRange, err error) {
+	if part == "*" {
+		return numberRange{0, ^uint64(0)}, nil
+	}
+
+	fromStr, toStr, isRange := strings.Cut(part, "-")
+	if r.from, err = strconv.ParseUint(fromStr, 10, 64); err != nil {
+		return
+	}
+	if !isRange {
+		r.to = r.from
+		return
+	}
+	if r.to, err = strconv.ParseUint(toStr, 10, 64); err != nil {
+		return
+	}
+	if r.to < r.from {
+		err = fmt.Errorf("range %q is empty", part)
+	}
+	return
+}
+
+func (r numberRange) contains(n uint64) bool {
+	return r.from <= n && n <= r.to
+}
@@ -0,0 +1,101 @@
+package bpv7
+
+import (
+	"fmt"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestEndpointPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		eid     string
+		match   bool
+	}{
+		{"*", "dtn://foo/bar", true},
+		{"*", "ipn:23.42", true},
+		{"*", "dtn:none", true},
+		{"dtn:none", "dtn:none", true},
+		{"dtn:none", "dtn://none/", false},
+		{"dtn://foo/", "dtn://foo/", true},
+		{"dtn://foo/", "dtn://foo/bar", false},
+		{"dtn://*/", "dtn://foo/", true},
+		{"dtn://*/", "dtn://foo/bar", false},
+		{"dtn://foo/*", "dtn://foo/bar", true},
+		{"dtn://foo/*", "dtn://foo/bar/baz", false},
+		{"dtn://foo/**", "dtn://foo/bar/baz", true},
+		{"dtn://foo/**", "dtn://foobar/", false},
+		{"dtn://sensor-*/**", "dtn://sensor-23/temp", true},
+		{"dtn://sensor-*/**", "dtn://actor-23/temp", false},
+		{"dtn://sensor-?/", "dtn://sensor-1/", true},
+		{"dtn://sensor-?/", "dtn://sensor-12/", false},
+		{"dtn://foo.bar/", "dtn://fooxbar/", false},
+		{"dtn://**", "ipn:1.1", false},
+		{"ipn:23.42", "ipn:23.42", true},
+		{"ipn:23.42", "ipn:23.43", false},
+		{"ipn:1-100.*", "ipn:100.7", true},
+		{"ipn:1-100.*", "ipn:101.7", false},
+		{"ipn:*.42", "ipn:8.42", true},
+		{"ipn:*.42", "dtn://foo/42", false},
+	}
+
+	for _, test := range tests {
+		p, err := NewEndpointPattern(test.pattern)
+		if err != nil {
+			t.Fatalf("Parsing pattern %q failed: %v", test.pattern, err)
+		}
+		if match := p.Match(MustNewEndpointID(test.eid)); match != test.match {
+			t.Fatalf("Pattern %q matching %q is %t, expected %t", test.pattern, test.eid, match, test.match)
+		}
+	}
+}
+
+func TestEndpointPatternInvalid(t *testing.T) {
+	tests := []string{"", "foo", "dtn:foo", "ipn:23", "ipn:a.1", "ipn:5-3.1", "ipn:1.2-", "http://foo/"}
+
+	for _, test := range tests {
+		if _, err := NewEndpointPattern(test); err == nil {
+			t.Fatalf("Invalid pattern %q was accepted", test)
+		}
+	}
+}
+
+func TestEndpointPatternLiteral(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		eid := MustNewEndpointID(rapid.StringMatching(DtnEndpointRegexpNotNone).Draw(t, "eid"))
+		other := MustNewEndpointID(rapid.StringMatching(DtnEndpointRegexpNotNone).Draw(t, "other"))
+
+		// Patterns without wildcards must match exactly their own endpoint
+		literal := eid.String()
+		for _, wildcard := range []string{"*", "?"} {
+			for i := 0; i < len(literal); i++ {
+				if literal[i:i+1] == wildcard {
+					t.Skip("endpoint contains a wildcard character")
+				}
+			}
+		}
+
+		p := MustNewEndpointPattern(literal)
+		if !p.Match(eid) {
+			t.Fatalf("Pattern %q does not match itself", p)
+		}
+		if p.Match(other) != (eid == other) {
+			t.Fatalf("Pattern %q matches %v", p, other)
+		}
+	})
+}
+
+func TestEndpointPatternIpnRange(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		from := rapid.Uint64Range(1, 1000).Draw(t, "from")
+		to := rapid.Uint64Range(from, 2000).Draw(t, "to")
+		node := rapid.Uint64Range(1, 3000).Draw(t, "node")
+
+		p := MustNewEndpointPattern(fmt.Sprintf("ipn:%d-%d.*", from, to))
+		eid := MustNewEndpointID(fmt.Sprintf("ipn:%d.1", node))
+		if p.Match(eid) != (from <= node && node <= to) {
+			t.Fatalf("Pattern %q matching %v is %t", p, eid, p.Match(eid))
+		}
+	})
+}
@@ -7,7 +7,9 @@ package bpv7
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/dtn7/cboring"
@@ -267,3 +269,68 @@ func TestEndpointIDSameNode(t *testing.T) {
 		}
 	}
 }
+
+// testEndpoint is an experimental EndpointType, "test:<name>", to be registered in TestRegisterEndpointType.
+type testEndpoint struct {
+	Name string
+}
+
+func newTestEndpoint(uri string) (EndpointType, error) {
+	name, found := strings.CutPrefix(uri, "test:")
+	if !found || name == "" {
+		return nil, fmt.Errorf("invalid test URI %s", uri)
+	}
+	return testEndpoint{Name: name}, nil
+}
+
+func (e testEndpoint) SchemeName() string            { return "test" }
+func (e testEndpoint) SchemeNo() uint64              { return 0xFFFF }
+func (e testEndpoint) Authority() string             { return e.Name }
+func (e testEndpoint) Path() string                  { return "/" }
+func (e testEndpoint) IsSingleton() bool             { return true }
+func (e testEndpoint) IsNone() bool                  { return false }
+func (e testEndpoint) CheckValid() error             { return nil }
+func (e testEndpoint) String() string                { return "test:" + e.Name }
+func (e testEndpoint) MarshalCbor(w io.Writer) error { return cboring.WriteTextString(e.Name, w) }
+
+func (e *testEndpoint) UnmarshalCbor(r io.Reader) (err error) {
+	e.Name, err = cboring.ReadTextString(r)
+	return
+}
+
+func TestRegisterEndpointType(t *testing.T) {
+	if _, err := NewEndpointID("test:foo"); err == nil {
+		t.Fatal("Unregistered URI scheme was accepted")
+	}
+
+	if err := RegisterEndpointType(DtnEndpointSchemeNo, "test", testEndpoint{}, newTestEndpoint); err == nil {
+		t.Fatal("Registering a known scheme number succeeded")
+	}
+	if err := RegisterEndpointType(0xFFFF, "ipn", testEndpoint{}, newTestEndpoint); err == nil {
+		t.Fatal("Registering a known scheme name succeeded")
+	}
+	if err := RegisterEndpointType(0xFFFF, "test", &testEndpoint{}, newTestEndpoint); err == nil {
+		t.Fatal("Registering a pointer EndpointType succeeded")
+	}
+	if err := RegisterEndpointType(0xFFFF, "test", testEndpoint{}, newTestEndpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	eid, err := NewEndpointID("test:foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&eid, buff); err != nil {
+		t.Fatal(err)
+	}
+
+	var eid2 EndpointID
+	if err := cboring.Unmarshal(&eid2, buff); err != nil {
+		t.Fatal(err)
+	}
+	if eid != eid2 {
+		t.Fatalf("Endpoint ID %v became %v after CBOR round trip", eid, eid2)
+	}
+}
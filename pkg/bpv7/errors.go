@@ -0,0 +1,33 @@
+package bpv7
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidBlock matches each InvalidBlockError by errors.Is, e.g., to drop a bundle instead of retrying it.
+var ErrInvalidBlock = errors.New("invalid block")
+
+// InvalidBlockError is returned by the CheckValid of a block or a Bundle for an invalid block. The primary block has
+// the block number and block type code zero.
+type InvalidBlockError struct {
+	BlockNumber   uint64
+	BlockTypeCode uint64
+	Err           error
+}
+
+func NewInvalidBlockError(blockNumber, blockTypeCode uint64, err error) *InvalidBlockError {
+	return &InvalidBlockError{BlockNumber: blockNumber, BlockTypeCode: blockTypeCode, Err: err}
+}
+
+func (err *InvalidBlockError) Error() string {
+	return fmt.Sprintf("block %d of type %d is invalid: %v", err.BlockNumber, err.BlockTypeCode, err.Err)
+}
+
+func (err *InvalidBlockError) Unwrap() error {
+	return err.Err
+}
+
+func (err *InvalidBlockError) Is(target error) bool {
+	return target == ErrInvalidBlock
+}
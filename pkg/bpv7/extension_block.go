@@ -48,6 +48,25 @@ const (
 
 	// ExtBlockTypeSignatureBlock is the custom block type code for a SignatureBlock, bpv7/extension_block_signature.go
 	ExtBlockTypeSignatureBlock uint64 = 195
+
+	// ExtBlockTypePayloadChecksumBlock is the custom block type code for a PayloadChecksumBlock,
+	// bpv7/extension_block_payload_checksum.go
+	ExtBlockTypePayloadChecksumBlock uint64 = 196
+
+	// ExtBlockTypeTravelHistoryBlock is the custom block type code for a TravelHistoryBlock,
+	// bpv7/extension_block_travel_history.go
+	ExtBlockTypeTravelHistoryBlock uint64 = 197
+
+	// ExtBlockTypePriorityBlock is the custom block type code for a PriorityBlock, bpv7/extension_block_priority.go
+	ExtBlockTypePriorityBlock uint64 = 198
+
+	// ExtBlockTypeSourceRouteBlock is the custom block type code for a SourceRouteBlock,
+	// bpv7/extension_block_source_route.go
+	ExtBlockTypeSourceRouteBlock uint64 = 199
+
+	// ExtBlockTypeContentTypeBlock is the custom block type code for a ContentTypeBlock,
+	// bpv7/extension_block_content_type.go
+	ExtBlockTypeContentTypeBlock uint64 = 200
 )
 
 // ExtensionBlock describes the block-type specific data of any Canonical Block.
@@ -194,7 +213,8 @@ var (
 
 // GetExtensionBlockManager returns the singleton ExtensionBlockManager. If none
 // exists, a new ExtensionBlockManager will be generated with a knowledge of the
-// PayloadBlock, PreviousNodeBlock, BundleAgeBlock and HopCountBlock.
+// PayloadBlock, PreviousNodeBlock, BundleAgeBlock, HopCountBlock, PayloadChecksumBlock, TravelHistoryBlock,
+// PriorityBlock, SourceRouteBlock and ContentTypeBlock.
 func GetExtensionBlockManager() *ExtensionBlockManager {
 	extensionBlockManagerMutex.Lock()
 	defer extensionBlockManagerMutex.Unlock()
@@ -206,6 +226,11 @@ func GetExtensionBlockManager() *ExtensionBlockManager {
 		_ = extensionBlockManager.Register(NewPreviousNodeBlock(DtnNone()))
 		_ = extensionBlockManager.Register(NewBundleAgeBlock(0))
 		_ = extensionBlockManager.Register(NewHopCountBlock(0))
+		_ = extensionBlockManager.Register(&PayloadChecksumBlock{})
+		_ = extensionBlockManager.Register(NewTravelHistoryBlock(0))
+		_ = extensionBlockManager.Register(NewPriorityBlock(PriorityNormal))
+		_ = extensionBlockManager.Register(NewSourceRouteBlock())
+		_ = extensionBlockManager.Register(NewContentTypeBlock("", ""))
 	}
 
 	return extensionBlockManager
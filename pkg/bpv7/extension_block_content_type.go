@@ -0,0 +1,120 @@
+package bpv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/dtn7/cboring"
+)
+
+// ContentTypeBlock is a custom block describing a Bundle's payload by its MIME content type, e.g., "image/jpeg", and
+// an optional filename. Thus, a receiving application can handle arbitrary payloads, e.g., store a transferred file
+// under its name or pick a viewer.
+//
+//	b, bErr := bpv7.Builder()./* ... */.ContentTypeBlock("text/plain; charset=utf-8", "notes.txt").Build()
+//
+// The block-type-specific data in a ContentTypeBlock MUST be represented as a CBOR array comprising two text strings:
+// the content type, followed by the filename, which is empty if unknown.
+//
+// Although this block is present in the bpv7 package, it is NOT specified in RFC 9171.
+type ContentTypeBlock struct {
+	ContentType string
+	Filename    string
+}
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (ctb *ContentTypeBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypeContentTypeBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (ctb *ContentTypeBlock) BlockTypeName() string {
+	return "Content Type Block"
+}
+
+// NewContentTypeBlock creates a new ContentTypeBlock for a content type and an optional filename.
+func NewContentTypeBlock(contentType, filename string) *ContentTypeBlock {
+	return &ContentTypeBlock{
+		ContentType: contentType,
+		Filename:    filename,
+	}
+}
+
+// MediaType returns the content type's lower case media type and its parameters, e.g., "text/plain" and a charset.
+func (ctb *ContentTypeBlock) MediaType() (mediaType string, params map[string]string, err error) {
+	return mime.ParseMediaType(ctb.ContentType)
+}
+
+// MarshalCbor writes a CBOR representation of this Content Type Block.
+func (ctb *ContentTypeBlock) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(2, w); err != nil {
+		return err
+	}
+	if err := cboring.WriteTextString(ctb.ContentType, w); err != nil {
+		return err
+	}
+	return cboring.WriteTextString(ctb.Filename, w)
+}
+
+// UnmarshalCbor reads a CBOR representation of a Content Type Block.
+func (ctb *ContentTypeBlock) UnmarshalCbor(r io.Reader) error {
+	if l, err := cboring.ReadArrayLength(r); err != nil {
+		return err
+	} else if l != 2 {
+		return fmt.Errorf("expected array with length 2, got %d", l)
+	}
+
+	if contentType, err := cboring.ReadTextString(r); err != nil {
+		return err
+	} else {
+		ctb.ContentType = contentType
+	}
+	if filename, err := cboring.ReadTextString(r); err != nil {
+		return err
+	} else {
+		ctb.Filename = filename
+	}
+
+	return nil
+}
+
+// MarshalJSON writes a JSON representation of this Content Type Block.
+func (ctb *ContentTypeBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ContentType string `json:"content_type"`
+		Filename    string `json:"filename,omitempty"`
+	}{ctb.ContentType, ctb.Filename})
+}
+
+// CheckValid checks the content type's syntax and that the filename is a plain name without any directories. The
+// latter allows a receiver to store a payload under its filename without escaping its directory.
+func (ctb *ContentTypeBlock) CheckValid() error {
+	if _, _, err := ctb.MediaType(); err != nil {
+		return fmt.Errorf("ContentTypeBlock has an invalid content type %q: %v", ctb.ContentType, err)
+	}
+
+	switch {
+	case ctb.Filename == "":
+	case ctb.Filename == "." || ctb.Filename == "..":
+		return fmt.Errorf("ContentTypeBlock's filename %q is no file", ctb.Filename)
+	case strings.ContainsAny(ctb.Filename, "/\\\x00"):
+		return fmt.Errorf("ContentTypeBlock's filename %q contains a path", ctb.Filename)
+	}
+	return nil
+}
+
+// CheckContextValid that there is at most one Content Type Block.
+func (ctb *ContentTypeBlock) CheckContextValid(b *Bundle) error {
+	cb, err := b.ExtensionBlock(ExtBlockTypeContentTypeBlock)
+
+	if err != nil {
+		return err
+	} else if cb.Value != ctb {
+		return fmt.Errorf("ContentTypeBlock's pointer differs, %p != %p", cb.Value, ctb)
+	} else {
+		return nil
+	}
+}
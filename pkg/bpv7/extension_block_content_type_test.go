@@ -0,0 +1,88 @@
+package bpv7
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/dtn7/cboring"
+	"pgregory.net/rapid"
+)
+
+func TestContentTypeBlockCbor(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ctb := NewContentTypeBlock(
+			rapid.SampledFrom([]string{"text/plain", "text/plain; charset=utf-8", "image/jpeg", "application/cbor"}).Draw(t, "content type"),
+			rapid.StringMatching(`^([a-zA-Z0-9_-]{1,16}(\.[a-z]{1,4})?)?$`).Draw(t, "filename"))
+		if err := ctb.CheckValid(); err != nil {
+			t.Fatal(err)
+		}
+
+		buff := new(bytes.Buffer)
+		if err := cboring.Marshal(ctb, buff); err != nil {
+			t.Fatal(err)
+		}
+
+		ctb2 := &ContentTypeBlock{}
+		if err := cboring.Unmarshal(ctb2, buff); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ctb, ctb2) {
+			t.Fatalf("Decoded ContentTypeBlock differs: %v became %v", ctb, ctb2)
+		}
+	})
+}
+
+func TestContentTypeBlockInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ctb  *ContentTypeBlock
+	}{
+		{"no content type", NewContentTypeBlock("", "")},
+		{"invalid content type", NewContentTypeBlock("text/plain; charset", "")},
+		{"parent directory", NewContentTypeBlock("text/plain", "..")},
+		{"path", NewContentTypeBlock("text/plain", "../../etc/passwd")},
+		{"windows path", NewContentTypeBlock("text/plain", `C:\notes.txt`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.ctb.CheckValid(); err == nil {
+				t.Fatal("Invalid ContentTypeBlock passed")
+			}
+		})
+	}
+}
+
+func TestContentTypeBlockBuildFromMap(t *testing.T) {
+	args := map[string]interface{}{
+		"destination":            "dtn://dst/",
+		"source":                 "dtn://src/",
+		"creation_timestamp_now": true,
+		"lifetime":               "24h",
+		"content_type_block":     map[string]interface{}{"content_type": "text/plain; charset=utf-8", "filename": "hello.txt"},
+		"payload_block":          "hello world",
+	}
+
+	bndl, err := BuildFromMap(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb, err := bndl.ExtensionBlock(ExtBlockTypeContentTypeBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctb := cb.Value.(*ContentTypeBlock)
+	if mediaType, params, err := ctb.MediaType(); err != nil || mediaType != "text/plain" || params["charset"] != "utf-8" {
+		t.Fatalf("ContentTypeBlock's media type is %q %v, %v", mediaType, params, err)
+	}
+	if ctb.Filename != "hello.txt" {
+		t.Fatalf("ContentTypeBlock's filename is %q", ctb.Filename)
+	}
+
+	args["content_type_block"] = map[string]interface{}{"content_type": "text/plain", "filename": "../hello.txt"}
+	if _, err := BuildFromMap(args); err == nil {
+		t.Fatal("Bundle with a path as its payload's filename was built")
+	}
+}
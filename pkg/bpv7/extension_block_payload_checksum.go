@@ -0,0 +1,121 @@
+package bpv7
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// PayloadChecksumBlock is a custom block carrying an end-to-end SHA-256 checksum of a Bundle's payload.
+//
+// In contrast to the hop-by-hop CRC of each block, this checksum is created once at the Bundle's source and should
+// only be verified at its destination. Thus, corruptions introduced by a forwarding node, e.g., a faulty storage, can
+// be detected. Like the SignatureBlock, fragmented Bundles cannot be verified because their payload is only a part of
+// the original payload.
+//
+//	b, bErr := bpv7.Builder()./* ... */.PayloadChecksumBlock().Build()
+//
+// The block-type-specific data in a PayloadChecksumBlock MUST be represented as a CBOR byte string of the SHA-256
+// digest's 32 bytes.
+//
+// Although this block is present in the bpv7 package, it is NOT specified in RFC 9171.
+type PayloadChecksumBlock struct {
+	Digest []byte
+}
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (pcb *PayloadChecksumBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypePayloadChecksumBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (pcb *PayloadChecksumBlock) BlockTypeName() string {
+	return "Payload Checksum Block"
+}
+
+// payloadChecksum calculates the SHA-256 digest of a Bundle's payload.
+func payloadChecksum(b Bundle) ([]byte, error) {
+	pb, err := b.PayloadBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(pb.Value.(*PayloadBlock).Data())
+	return digest[:], nil
+}
+
+// NewPayloadChecksumBlock for a Bundle's current payload.
+func NewPayloadChecksumBlock(b Bundle) (*PayloadChecksumBlock, error) {
+	if b.PrimaryBlock.BundleControlFlags.Has(IsFragment) {
+		return nil, fmt.Errorf("fragmented Bundles cannot be checksummed")
+	}
+
+	digest, err := payloadChecksum(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PayloadChecksumBlock{Digest: digest}, nil
+}
+
+// Verify the checksum against a Bundle's payload. Fragmented Bundles cannot be verified.
+func (pcb *PayloadChecksumBlock) Verify(b Bundle) bool {
+	if pcb.CheckValid() != nil || b.PrimaryBlock.BundleControlFlags.Has(IsFragment) {
+		return false
+	}
+
+	digest, err := payloadChecksum(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(pcb.Digest, digest)
+}
+
+// MarshalCbor writes the CBOR representation of a PayloadChecksumBlock.
+func (pcb *PayloadChecksumBlock) MarshalCbor(w io.Writer) error {
+	return cboring.WriteByteString(pcb.Digest, w)
+}
+
+// UnmarshalCbor reads a CBOR representation of a PayloadChecksumBlock.
+func (pcb *PayloadChecksumBlock) UnmarshalCbor(r io.Reader) error {
+	if digest, err := cboring.ReadByteString(r); err != nil {
+		return err
+	} else {
+		pcb.Digest = digest
+		return nil
+	}
+}
+
+// MarshalJSON writes the JSON representation of a PayloadChecksumBlock, the hex encoded digest.
+func (pcb *PayloadChecksumBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(pcb.Digest))
+}
+
+// CheckValid checks the digest's length.
+//
+// This DOES NOT verify the checksum. Therefore please use the Verify method.
+func (pcb *PayloadChecksumBlock) CheckValid() error {
+	if l := len(pcb.Digest); l != sha256.Size {
+		return fmt.Errorf("PayloadChecksumBlock: digest's length is %d, not required %d", l, sha256.Size)
+	}
+	return nil
+}
+
+// CheckContextValid that there is at most one Payload Checksum Block.
+func (pcb *PayloadChecksumBlock) CheckContextValid(b *Bundle) error {
+	cb, err := b.ExtensionBlock(ExtBlockTypePayloadChecksumBlock)
+
+	if err != nil {
+		return err
+	} else if cb.Value != pcb {
+		return fmt.Errorf("PayloadChecksumBlock's pointer differs, %p != %p", cb.Value, pcb)
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package bpv7
+
+import (
+	"bytes"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestPayloadChecksumBlockIntegration(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		payload := rapid.SliceOfN(rapid.Byte(), 1, 1024).Draw(t, "payload")
+
+		b1, err := Builder().
+			CRC(CRC32).
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("10m").
+			PayloadBlock(payload).
+			PayloadChecksumBlock().
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buff bytes.Buffer
+		var b2 Bundle
+		if err := b1.MarshalCbor(&buff); err != nil {
+			t.Fatal(err)
+		} else if err := b2.UnmarshalCbor(&buff); err != nil {
+			t.Fatal(err)
+		}
+
+		pcbCan, err := b2.ExtensionBlock(ExtBlockTypePayloadChecksumBlock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcb, ok := pcbCan.Value.(*PayloadChecksumBlock)
+		if !ok {
+			t.Fatalf("Block is not of type *PayloadChecksumBlock, but %T", pcbCan.Value)
+		}
+		if !pcb.Verify(b2) {
+			t.Fatal("PayloadChecksumBlock cannot be verified")
+		}
+
+		pb, err := b2.PayloadBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos := rapid.IntRange(0, len(payload)-1).Draw(t, "altered position")
+		pb.Value.(*PayloadBlock).Data()[pos] ^= 0xFF
+		if pcb.Verify(b2) {
+			t.Fatal("PayloadChecksumBlock with altered payload succeeded")
+		}
+	})
+}
+
+func TestPayloadChecksumBlockCheckValid(t *testing.T) {
+	if err := (&PayloadChecksumBlock{Digest: make([]byte, 16)}).CheckValid(); err == nil {
+		t.Fatal("PayloadChecksumBlock with a short digest is valid")
+	}
+	if err := (&PayloadChecksumBlock{Digest: make([]byte, 32)}).CheckValid(); err != nil {
+		t.Fatal(err)
+	}
+}
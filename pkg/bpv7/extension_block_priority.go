@@ -0,0 +1,133 @@
+package bpv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtn7/cboring"
+)
+
+// Priority is a Bundle's class of service, in the spirit of RFC 5050's bulk, normal and expedited priorities.
+type Priority uint64
+
+const (
+	// PriorityBulk bundles are only forwarded if no other bundles are pending.
+	PriorityBulk Priority = iota
+
+	// PriorityNormal is the default for bundles without a PriorityBlock.
+	PriorityNormal
+
+	// PriorityExpedited bundles are forwarded before all others.
+	PriorityExpedited
+)
+
+// PriorityFromString parses a Priority, either "bulk", "normal" or "expedited".
+func PriorityFromString(s string) (Priority, error) {
+	switch strings.ToLower(s) {
+	case "bulk":
+		return PriorityBulk, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "expedited":
+		return PriorityExpedited, nil
+	default:
+		return 0, fmt.Errorf("invalid priority: %v", s)
+	}
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBulk:
+		return "bulk"
+	case PriorityNormal:
+		return "normal"
+	case PriorityExpedited:
+		return "expedited"
+	default:
+		return "unknown priority"
+	}
+}
+
+// PriorityBlock is a custom block to carry a Bundle's Priority.
+//
+// Derived bundles inherit their original's priority: each fragment carries the Priority Block, regardless of its
+// Replicate Block flag, and status reports are sent with the reported bundle's priority.
+//
+//	b, bErr := bpv7.Builder()./* ... */.PriorityBlock(bpv7.PriorityExpedited).Build()
+//
+// The block-type-specific data in a PriorityBlock MUST be represented as a CBOR unsigned integer of the Priority.
+//
+// Although this block is present in the bpv7 package, it is NOT specified in RFC 9171.
+type PriorityBlock Priority
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (pb *PriorityBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypePriorityBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (pb *PriorityBlock) BlockTypeName() string {
+	return "Priority Block"
+}
+
+// NewPriorityBlock creates a new PriorityBlock for a Priority.
+func NewPriorityBlock(p Priority) *PriorityBlock {
+	pb := PriorityBlock(p)
+	return &pb
+}
+
+// Priority returns this block's Priority.
+func (pb *PriorityBlock) Priority() Priority {
+	return Priority(*pb)
+}
+
+// MarshalCbor writes a CBOR representation of this Priority Block.
+func (pb *PriorityBlock) MarshalCbor(w io.Writer) error {
+	return cboring.WriteUInt(uint64(*pb), w)
+}
+
+// UnmarshalCbor reads a CBOR representation of a Priority Block.
+func (pb *PriorityBlock) UnmarshalCbor(r io.Reader) error {
+	if p, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		*pb = PriorityBlock(p)
+		return nil
+	}
+}
+
+// MarshalJSON writes a JSON representation of this Priority Block, its Priority's name.
+func (pb *PriorityBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pb.Priority().String())
+}
+
+// CheckValid checks for a known Priority.
+func (pb *PriorityBlock) CheckValid() error {
+	if p := pb.Priority(); p > PriorityExpedited {
+		return fmt.Errorf("PriorityBlock has an unknown priority of %d", p)
+	}
+	return nil
+}
+
+// CheckContextValid that there is at most one Priority Block.
+func (pb *PriorityBlock) CheckContextValid(b *Bundle) error {
+	cb, err := b.ExtensionBlock(ExtBlockTypePriorityBlock)
+
+	if err != nil {
+		return err
+	} else if cb.Value != pb {
+		return fmt.Errorf("PriorityBlock's pointer differs, %p != %p", cb.Value, pb)
+	} else {
+		return nil
+	}
+}
+
+// Priority of this Bundle, as stated by its PriorityBlock. Bundles without one have a PriorityNormal.
+func (b Bundle) Priority() Priority {
+	if cb, err := b.ExtensionBlock(ExtBlockTypePriorityBlock); err == nil {
+		return cb.Value.(*PriorityBlock).Priority()
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,74 @@
+package bpv7
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dtn7/cboring"
+	"pgregory.net/rapid"
+)
+
+func TestPriorityBlockCbor(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		p := rapid.SampledFrom([]Priority{PriorityBulk, PriorityNormal, PriorityExpedited}).Draw(t, "priority")
+
+		pb := NewPriorityBlock(p)
+		if err := pb.CheckValid(); err != nil {
+			t.Fatal(err)
+		}
+
+		buff := new(bytes.Buffer)
+		if err := cboring.Marshal(pb, buff); err != nil {
+			t.Fatal(err)
+		}
+
+		pb2 := NewPriorityBlock(PriorityNormal)
+		if err := cboring.Unmarshal(pb2, buff); err != nil {
+			t.Fatal(err)
+		}
+		if pb2.Priority() != p {
+			t.Fatalf("Decoded PriorityBlock differs: %v became %v", p, pb2.Priority())
+		}
+
+		if parsed, err := PriorityFromString(p.String()); err != nil || parsed != p {
+			t.Fatalf("Parsing %q resulted in %v, %v", p.String(), parsed, err)
+		}
+	})
+}
+
+func TestPriorityBlockBuildFromMap(t *testing.T) {
+	args := map[string]interface{}{
+		"destination":            "dtn://dst/",
+		"source":                 "dtn://src/",
+		"creation_timestamp_now": true,
+		"lifetime":               "24h",
+		"priority_block":         "expedited",
+		"payload_block":          "hello world",
+	}
+
+	bndl, err := BuildFromMap(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := bndl.Priority(); p != PriorityExpedited {
+		t.Fatalf("Bundle has priority %v", p)
+	}
+
+	args["priority_block"] = "urgent"
+	if _, err := BuildFromMap(args); err == nil {
+		t.Fatal("Unknown priority was accepted")
+	}
+
+	delete(args, "priority_block")
+	if bndl, err := BuildFromMap(args); err != nil {
+		t.Fatal(err)
+	} else if p := bndl.Priority(); p != PriorityNormal {
+		t.Fatalf("Bundle without PriorityBlock has priority %v", p)
+	}
+}
+
+func TestPriorityBlockCheckValid(t *testing.T) {
+	if err := NewPriorityBlock(PriorityExpedited + 1).CheckValid(); err == nil {
+		t.Fatal("Unknown priority was valid")
+	}
+}
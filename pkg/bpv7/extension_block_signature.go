@@ -97,6 +97,42 @@ func NewSignatureBlock(b Bundle, priv ed25519.PrivateKey) (s *SignatureBlock, er
 	return
 }
 
+// Sign a Bundle by attaching a SignatureBlock, created from a private key. As the signature covers the Payload Block,
+// the Bundle must be complete except for blocks to be altered on its way, e.g., a Hop Count Block.
+func (b *Bundle) Sign(priv ed25519.PrivateKey) error {
+	if b.HasExtensionBlock(ExtBlockTypeSignatureBlock) {
+		return fmt.Errorf("bundle is already signed")
+	}
+
+	sb, err := NewSignatureBlock(*b, priv)
+	if err != nil {
+		return err
+	}
+	return b.AddExtensionBlock(NewCanonicalBlock(0, ReplicateBlock|DeleteBundle, sb))
+}
+
+// SignatureBlock returns the Bundle's SignatureBlock. As the SignatureBlock is not registered by default, see
+// GetExtensionBlockManager, an unregistered block of its type code is decoded from its generic representation.
+func (b Bundle) SignatureBlock() (*SignatureBlock, error) {
+	cb, err := b.ExtensionBlock(ExtBlockTypeSignatureBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block := cb.Value.(type) {
+	case *SignatureBlock:
+		return block, nil
+	case *GenericExtensionBlock:
+		s := &SignatureBlock{}
+		if err := s.UnmarshalCbor(bytes.NewReader(block.data)); err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("block of type code %d is a %T", ExtBlockTypeSignatureBlock, cb.Value)
+	}
+}
+
 // CheckValid checks the field lengths for errors.
 //
 // This DOES NOT verify the signature. Therefore please use the Verify method.
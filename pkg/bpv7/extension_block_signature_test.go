@@ -111,6 +111,103 @@ func TestSignatureBlockVerifySimple(t *testing.T) {
 	}
 }
 
+func TestBundleSign(t *testing.T) {
+	if regErr := GetExtensionBlockManager().Register(&SignatureBlock{}); regErr != nil {
+		t.Fatal(regErr)
+	}
+	defer GetExtensionBlockManager().Unregister(&SignatureBlock{})
+
+	b, bErr := Builder().
+		CRC(CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(30 * time.Minute).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if bErr != nil {
+		t.Fatal(bErr)
+	}
+
+	_, priv, ed25519KeyErr := ed25519.GenerateKey(nil)
+	if ed25519KeyErr != nil {
+		t.Fatal(ed25519KeyErr)
+	}
+
+	if err := b.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Sign(priv); err == nil {
+		t.Fatal("Signed bundle was signed again")
+	}
+
+	buff := new(bytes.Buffer)
+	if err := b.WriteBundle(buff); err != nil {
+		t.Fatal(err)
+	}
+	b2, err := ParseBundle(buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := b2.ExtensionBlock(ExtBlockTypeSignatureBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sb.Value.(*SignatureBlock).Verify(b2) {
+		t.Fatal("Parsed bundle's signature cannot be verified")
+	}
+}
+
+func TestBundleSignatureBlockUnregistered(t *testing.T) {
+	b, bErr := Builder().
+		CRC(CRC32).
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime(30 * time.Minute).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if bErr != nil {
+		t.Fatal(bErr)
+	}
+
+	if _, err := b.SignatureBlock(); err == nil {
+		t.Fatal("Unsigned bundle has a SignatureBlock")
+	}
+
+	_, priv, ed25519KeyErr := ed25519.GenerateKey(nil)
+	if ed25519KeyErr != nil {
+		t.Fatal(ed25519KeyErr)
+	}
+	if err := b.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without being registered, the SignatureBlock is parsed as a GenericExtensionBlock.
+	buff := new(bytes.Buffer)
+	if err := b.WriteBundle(buff); err != nil {
+		t.Fatal(err)
+	}
+	b2, err := ParseBundle(buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := b2.SignatureBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sb.Verify(b2) {
+		t.Fatal("Parsed bundle's signature cannot be verified")
+	}
+
+	b2.PrimaryBlock.Lifetime++
+	if sb.Verify(b2) {
+		t.Fatal("Altered bundle's signature was verified")
+	}
+}
+
 func TestSignatureBlockCborSimple(t *testing.T) {
 	sb1 := &SignatureBlock{
 		PublicKey: testSignatureBlockRandBytes(1, ed25519.PublicKeySize, t),
@@ -0,0 +1,151 @@
+package bpv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// SourceRouteBlock is a custom block listing an ordered set of waypoint EndpointIDs, which a Bundle should traverse
+// before it is routed towards its destination. Visited counts the already reached waypoints.
+//
+// This block allows operators to pin a Bundle's path, e.g., for diagnostics or policy routing. Each node forwards the
+// Bundle only to the next unvisited waypoint. After all waypoints were visited, the Bundle is routed as usual.
+//
+//	b, bErr := bpv7.Builder()./* ... */.SourceRouteBlock([]string{"dtn://a/", "dtn://b/"}).Build()
+//
+// The block-type-specific data in a SourceRouteBlock MUST be represented as a CBOR array comprising two elements:
+// the number of Visited waypoints as an unsigned integer, followed by an array of the waypoints' EndpointIDs.
+//
+// Although this block is present in the bpv7 package, it is NOT specified in RFC 9171.
+type SourceRouteBlock struct {
+	Visited   uint64
+	Waypoints []EndpointID
+}
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (srb *SourceRouteBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypeSourceRouteBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (srb *SourceRouteBlock) BlockTypeName() string {
+	return "Source Route Block"
+}
+
+// NewSourceRouteBlock creates a new SourceRouteBlock for the waypoints, none of them visited.
+func NewSourceRouteBlock(waypoints ...EndpointID) *SourceRouteBlock {
+	return &SourceRouteBlock{
+		Waypoints: append([]EndpointID{}, waypoints...),
+	}
+}
+
+// NextWaypoint returns the first unvisited waypoint, if there is one.
+func (srb *SourceRouteBlock) NextWaypoint() (waypoint EndpointID, ok bool) {
+	if srb.Visited >= uint64(len(srb.Waypoints)) {
+		return
+	}
+	return srb.Waypoints[srb.Visited], true
+}
+
+// Visit marks all leading unvisited waypoints as visited which are matched by isOwn, i.e., which are this node. It
+// returns true if any waypoint was visited.
+func (srb *SourceRouteBlock) Visit(isOwn func(EndpointID) bool) (visited bool) {
+	for waypoint, ok := srb.NextWaypoint(); ok && isOwn(waypoint); waypoint, ok = srb.NextWaypoint() {
+		srb.Visited++
+		visited = true
+	}
+	return
+}
+
+// MarshalCbor writes a CBOR representation of this Source Route Block.
+func (srb *SourceRouteBlock) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(2, w); err != nil {
+		return err
+	}
+
+	if err := cboring.WriteUInt(srb.Visited, w); err != nil {
+		return err
+	}
+
+	if err := cboring.WriteArrayLength(uint64(len(srb.Waypoints)), w); err != nil {
+		return err
+	}
+	for i := range srb.Waypoints {
+		if err := cboring.Marshal(&srb.Waypoints[i], w); err != nil {
+			return fmt.Errorf("marshalling waypoint %d failed: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCbor reads a CBOR representation of a Source Route Block.
+func (srb *SourceRouteBlock) UnmarshalCbor(r io.Reader) error {
+	if l, err := cboring.ReadArrayLength(r); err != nil {
+		return err
+	} else if l != 2 {
+		return fmt.Errorf("expected array with length 2, got %d", l)
+	}
+
+	if visited, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		srb.Visited = visited
+	}
+
+	l, err := cboring.ReadArrayLength(r)
+	if err != nil {
+		return err
+	} else if l < srb.Visited {
+		return fmt.Errorf("SourceRouteBlock has %d waypoints, but %d were visited", l, srb.Visited)
+	}
+
+	srb.Waypoints = make([]EndpointID, l)
+	for i := range srb.Waypoints {
+		if err := cboring.Unmarshal(&srb.Waypoints[i], r); err != nil {
+			return fmt.Errorf("unmarshalling waypoint %d failed: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON writes a JSON representation of this Source Route Block.
+func (srb *SourceRouteBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Visited   uint64       `json:"visited"`
+		Waypoints []EndpointID `json:"waypoints"`
+	}{srb.Visited, srb.Waypoints})
+}
+
+// CheckValid checks for at least one waypoint, valid EndpointIDs and the number of visited waypoints.
+func (srb *SourceRouteBlock) CheckValid() error {
+	if len(srb.Waypoints) == 0 {
+		return fmt.Errorf("SourceRouteBlock has no waypoints")
+	}
+	if l := uint64(len(srb.Waypoints)); srb.Visited > l {
+		return fmt.Errorf("SourceRouteBlock has %d waypoints, but %d were visited", l, srb.Visited)
+	}
+	for i, waypoint := range srb.Waypoints {
+		if err := waypoint.CheckValid(); err != nil {
+			return fmt.Errorf("SourceRouteBlock waypoint %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// CheckContextValid that there is at most one Source Route Block.
+func (srb *SourceRouteBlock) CheckContextValid(b *Bundle) error {
+	cb, err := b.ExtensionBlock(ExtBlockTypeSourceRouteBlock)
+
+	if err != nil {
+		return err
+	} else if cb.Value != srb {
+		return fmt.Errorf("SourceRouteBlock's pointer differs, %p != %p", cb.Value, srb)
+	} else {
+		return nil
+	}
+}
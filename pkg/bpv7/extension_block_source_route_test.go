@@ -0,0 +1,107 @@
+package bpv7
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dtn7/cboring"
+	"pgregory.net/rapid"
+)
+
+func TestSourceRouteBlockCbor(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		waypointsNo := rapid.IntRange(1, 16).Draw(t, "waypoints")
+		visited := rapid.IntRange(0, waypointsNo).Draw(t, "visited")
+
+		waypoints := make([]EndpointID, waypointsNo)
+		for i := range waypoints {
+			waypoints[i] = MustNewEndpointID(fmt.Sprintf("dtn://node%d/", i))
+		}
+
+		srb := NewSourceRouteBlock(waypoints...)
+		srb.Visited = uint64(visited)
+		if err := srb.CheckValid(); err != nil {
+			t.Fatal(err)
+		}
+
+		buff := new(bytes.Buffer)
+		if err := cboring.Marshal(srb, buff); err != nil {
+			t.Fatal(err)
+		}
+
+		srb2 := &SourceRouteBlock{}
+		if err := cboring.Unmarshal(srb2, buff); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(srb, srb2) {
+			t.Fatalf("Decoded SourceRouteBlock differs: %v became %v", srb, srb2)
+		}
+	})
+}
+
+func TestSourceRouteBlockVisit(t *testing.T) {
+	srb := NewSourceRouteBlock(
+		MustNewEndpointID("dtn://a/"), MustNewEndpointID("dtn://b/"), MustNewEndpointID("dtn://c/"))
+	isNode := func(node string) func(EndpointID) bool {
+		return func(eid EndpointID) bool { return eid.SameNode(MustNewEndpointID(node)) }
+	}
+
+	if srb.Visit(isNode("dtn://b/")) {
+		t.Fatal("Visiting a later waypoint succeeded")
+	}
+	if !srb.Visit(isNode("dtn://a/")) || srb.Visited != 1 {
+		t.Fatalf("Visiting the next waypoint failed, %d visited", srb.Visited)
+	}
+	if next, ok := srb.NextWaypoint(); !ok || next != MustNewEndpointID("dtn://b/") {
+		t.Fatalf("Next waypoint is %v, %t", next, ok)
+	}
+
+	srb.Visited = 3
+	if _, ok := srb.NextWaypoint(); ok {
+		t.Fatal("Next waypoint returned after visiting all")
+	}
+}
+
+func TestSourceRouteBlockInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		srb  *SourceRouteBlock
+	}{
+		{"no waypoints", NewSourceRouteBlock()},
+		{"visited exceeds", &SourceRouteBlock{Visited: 2, Waypoints: []EndpointID{MustNewEndpointID("dtn://a/")}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.srb.CheckValid(); err == nil {
+				t.Fatal("Invalid SourceRouteBlock passed")
+			}
+		})
+	}
+}
+
+func TestSourceRouteBlockBuildFromMap(t *testing.T) {
+	args := map[string]interface{}{
+		"destination":            "dtn://dst/",
+		"source":                 "dtn://src/",
+		"creation_timestamp_now": true,
+		"lifetime":               "24h",
+		"source_route_block":     []interface{}{"dtn://a/", "dtn://b/"},
+		"payload_block":          "hello world",
+	}
+
+	bndl, err := BuildFromMap(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb, err := bndl.ExtensionBlock(ExtBlockTypeSourceRouteBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waypoints := cb.Value.(*SourceRouteBlock).Waypoints; len(waypoints) != 2 || waypoints[1] != MustNewEndpointID("dtn://b/") {
+		t.Fatalf("SourceRouteBlock's waypoints are %v", waypoints)
+	}
+}
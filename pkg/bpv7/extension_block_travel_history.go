@@ -0,0 +1,185 @@
+package bpv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// TravelHistoryEntry is a node traversed by a Bundle, together with the time of forwarding.
+type TravelHistoryEntry struct {
+	Node EndpointID
+	Time DtnTime
+}
+
+// TravelHistoryBlock is a custom block accumulating the nodes which have forwarded a Bundle, in order of traversal.
+// Its length is bounded by a Limit. If further nodes are appended, the oldest entries are dropped and counted.
+//
+// This block is intended to debug routing behaviour, e.g., in field tests, and should be requested at the Bundle's
+// source. Each forwarding node appends itself.
+//
+//	b, bErr := bpv7.Builder()./* ... */.TravelHistoryBlock(16).Build()
+//
+// The block-type-specific data in a TravelHistoryBlock MUST be represented as a CBOR array comprising three elements:
+// the Limit and the number of Dropped entries as unsigned integers, followed by an array of entries. Each entry is an
+// array of the node's EndpointID and the DtnTime of forwarding.
+//
+// Although this block is present in the bpv7 package, it is NOT specified in RFC 9171.
+type TravelHistoryBlock struct {
+	Limit   uint64
+	Dropped uint64
+	Entries []TravelHistoryEntry
+}
+
+// BlockTypeCode must return a constant integer, indicating the block type code.
+func (thb *TravelHistoryBlock) BlockTypeCode() uint64 {
+	return ExtBlockTypeTravelHistoryBlock
+}
+
+// BlockTypeName must return a constant string, this block's name.
+func (thb *TravelHistoryBlock) BlockTypeName() string {
+	return "Travel History Block"
+}
+
+// NewTravelHistoryBlock creates a new, empty TravelHistoryBlock holding at most limit entries.
+func NewTravelHistoryBlock(limit uint64) *TravelHistoryBlock {
+	return &TravelHistoryBlock{
+		Limit:   limit,
+		Entries: []TravelHistoryEntry{},
+	}
+}
+
+// Append a traversed node. If the Limit would be exceeded, the oldest entry is dropped.
+func (thb *TravelHistoryBlock) Append(node EndpointID, t DtnTime) {
+	if thb.Limit == 0 {
+		thb.Dropped++
+		return
+	}
+
+	thb.Entries = append(thb.Entries, TravelHistoryEntry{Node: node, Time: t})
+	if excess := uint64(len(thb.Entries)); excess > thb.Limit {
+		drop := excess - thb.Limit
+		thb.Entries = thb.Entries[drop:]
+		thb.Dropped += drop
+	}
+}
+
+// MarshalCbor writes a CBOR representation of this Travel History Block.
+func (thb *TravelHistoryBlock) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(3, w); err != nil {
+		return err
+	}
+
+	for _, f := range []uint64{thb.Limit, thb.Dropped} {
+		if err := cboring.WriteUInt(f, w); err != nil {
+			return err
+		}
+	}
+
+	if err := cboring.WriteArrayLength(uint64(len(thb.Entries)), w); err != nil {
+		return err
+	}
+	for i := range thb.Entries {
+		if err := cboring.WriteArrayLength(2, w); err != nil {
+			return err
+		}
+		if err := cboring.Marshal(&thb.Entries[i].Node, w); err != nil {
+			return fmt.Errorf("marshalling entry %d's node failed: %v", i, err)
+		}
+		if err := cboring.WriteUInt(uint64(thb.Entries[i].Time), w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCbor reads a CBOR representation of a Travel History Block.
+func (thb *TravelHistoryBlock) UnmarshalCbor(r io.Reader) error {
+	if l, err := cboring.ReadArrayLength(r); err != nil {
+		return err
+	} else if l != 3 {
+		return fmt.Errorf("expected array with length 3, got %d", l)
+	}
+
+	for _, f := range []*uint64{&thb.Limit, &thb.Dropped} {
+		if x, err := cboring.ReadUInt(r); err != nil {
+			return err
+		} else {
+			*f = x
+		}
+	}
+
+	l, err := cboring.ReadArrayLength(r)
+	if err != nil {
+		return err
+	} else if l > thb.Limit {
+		return fmt.Errorf("TravelHistoryBlock has %d entries, exceeding its limit of %d", l, thb.Limit)
+	}
+
+	thb.Entries = make([]TravelHistoryEntry, l)
+	for i := range thb.Entries {
+		if n, err := cboring.ReadArrayLength(r); err != nil {
+			return err
+		} else if n != 2 {
+			return fmt.Errorf("expected entry array with length 2, got %d", n)
+		}
+		if err := cboring.Unmarshal(&thb.Entries[i].Node, r); err != nil {
+			return fmt.Errorf("unmarshalling entry %d's node failed: %v", i, err)
+		}
+		if t, err := cboring.ReadUInt(r); err != nil {
+			return err
+		} else {
+			thb.Entries[i].Time = DtnTime(t)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON writes a JSON representation of this Travel History Block.
+func (thb *TravelHistoryBlock) MarshalJSON() ([]byte, error) {
+	type entry struct {
+		Node EndpointID `json:"node"`
+		Time string     `json:"time"`
+	}
+
+	entries := make([]entry, len(thb.Entries))
+	for i, e := range thb.Entries {
+		entries[i] = entry{Node: e.Node, Time: e.Time.String()}
+	}
+
+	return json.Marshal(&struct {
+		Limit   uint64  `json:"limit"`
+		Dropped uint64  `json:"dropped"`
+		Entries []entry `json:"entries"`
+	}{thb.Limit, thb.Dropped, entries})
+}
+
+// CheckValid checks the entries against the limit and for valid EndpointIDs.
+func (thb *TravelHistoryBlock) CheckValid() error {
+	if l := uint64(len(thb.Entries)); l > thb.Limit {
+		return fmt.Errorf("TravelHistoryBlock has %d entries, exceeding its limit of %d", l, thb.Limit)
+	}
+	for i, e := range thb.Entries {
+		if err := e.Node.CheckValid(); err != nil {
+			return fmt.Errorf("TravelHistoryBlock entry %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// CheckContextValid that there is at most one Travel History Block.
+func (thb *TravelHistoryBlock) CheckContextValid(b *Bundle) error {
+	cb, err := b.ExtensionBlock(ExtBlockTypeTravelHistoryBlock)
+
+	if err != nil {
+		return err
+	} else if cb.Value != thb {
+		return fmt.Errorf("TravelHistoryBlock's pointer differs, %p != %p", cb.Value, thb)
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package bpv7
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dtn7/cboring"
+	"pgregory.net/rapid"
+)
+
+func TestTravelHistoryBlockAppend(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		limit := rapid.Uint64Max(16).Draw(t, "limit")
+		hops := rapid.IntRange(0, 32).Draw(t, "hops")
+
+		thb := NewTravelHistoryBlock(limit)
+		for i := 0; i < hops; i++ {
+			thb.Append(MustNewEndpointID(fmt.Sprintf("dtn://node%d/", i)), DtnTime(i))
+		}
+
+		if err := thb.CheckValid(); err != nil {
+			t.Fatal(err)
+		}
+		if l := uint64(len(thb.Entries)); l+thb.Dropped != uint64(hops) {
+			t.Fatalf("%d entries and %d dropped ones do not sum up to %d hops", l, thb.Dropped, hops)
+		}
+		if len(thb.Entries) > 0 {
+			if last := thb.Entries[len(thb.Entries)-1]; last.Time != DtnTime(hops-1) {
+				t.Fatalf("Latest entry %v is not the last hop", last)
+			}
+		}
+
+		buff := new(bytes.Buffer)
+		if err := cboring.Marshal(thb, buff); err != nil {
+			t.Fatal(err)
+		}
+
+		thb2 := &TravelHistoryBlock{}
+		if err := cboring.Unmarshal(thb2, buff); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(thb, thb2) {
+			t.Fatalf("Decoded TravelHistoryBlock differs: %v became %v", thb, thb2)
+		}
+	})
+}
+
+func TestTravelHistoryBlockBuildFromMap(t *testing.T) {
+	args := map[string]interface{}{
+		"destination":            "dtn://dst/",
+		"source":                 "dtn://src/",
+		"creation_timestamp_now": true,
+		"lifetime":               "24h",
+		"travel_history_block":   float64(8),
+		"payload_block":          "hello world",
+	}
+
+	bndl, err := BuildFromMap(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb, err := bndl.ExtensionBlock(ExtBlockTypeTravelHistoryBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := cb.Value.(*TravelHistoryBlock).Limit; limit != 8 {
+		t.Fatalf("TravelHistoryBlock's limit is %d, not 8", limit)
+	}
+}
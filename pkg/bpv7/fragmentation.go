@@ -67,7 +67,7 @@ func (b Bundle) Fragment(mtu int) (bs []Bundle, err error) {
 			if cb.TypeCode() == ExtBlockTypePayloadBlock {
 				continue
 			}
-			if i > 0 && !cb.BlockControlFlags.Has(ReplicateBlock) {
+			if i > 0 && !isReplicatedInFragments(cb) {
 				continue
 			}
 
@@ -102,6 +102,12 @@ func (b Bundle) Fragment(mtu int) (bs []Bundle, err error) {
 	return
 }
 
+// isReplicatedInFragments checks if a Canonical Block must be part of each fragment, not only of the first one.
+// Next to blocks with the Replicate Block flag, this is true for the PriorityBlock, which every fragment inherits.
+func isReplicatedInFragments(cb CanonicalBlock) bool {
+	return cb.BlockControlFlags.Has(ReplicateBlock) || cb.TypeCode() == ExtBlockTypePriorityBlock
+}
+
 // fragmentPrimaryBlock creates a fragment's Primary Block and calculates its length.
 func fragmentPrimaryBlock(pb PrimaryBlock, fragmentOffset, totalDataLength int) (fragPb PrimaryBlock, l int, err error) {
 	fragPb = PrimaryBlock{
@@ -146,7 +152,7 @@ func fragmentExtensionBlocksLen(b Bundle, mtu int) (first int, others int, err e
 
 		cbLen := buff.Len()
 		first += cbLen
-		if cb.BlockControlFlags.Has(ReplicateBlock) {
+		if isReplicatedInFragments(cb) {
 			others += cbLen
 		}
 
@@ -76,6 +76,43 @@ func testBundleFragment(t *testing.T, payloadLen, mtu int) {
 	}
 }
 
+func TestBundleFragmentInheritance(t *testing.T) {
+	// The PriorityBlock is added without the Replicate Block flag, but must be part of each fragment.
+	bndl, err := Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		Canonical(NewPriorityBlock(PriorityExpedited)).
+		PayloadBlock(make([]byte, 1024)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frags, err := bndl.Fragment(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, frag := range frags {
+		if p := frag.Priority(); p != PriorityExpedited {
+			t.Fatalf("Fragment at offset %d has priority %v", frag.PrimaryBlock.FragmentOffset, p)
+		}
+		if frag.PrimaryBlock.CreationTimestamp != bndl.PrimaryBlock.CreationTimestamp ||
+			frag.PrimaryBlock.Lifetime != bndl.PrimaryBlock.Lifetime {
+			t.Fatalf("Fragment at offset %d has a different lifetime", frag.PrimaryBlock.FragmentOffset)
+		}
+
+		var buff bytes.Buffer
+		if err = frag.MarshalCbor(&buff); err != nil {
+			t.Fatal(err)
+		} else if l := buff.Len(); l > 128 {
+			t.Fatalf("Fragment at offset %d exceeds MTU: %d", frag.PrimaryBlock.FragmentOffset, l)
+		}
+	}
+}
+
 func TestBundleFragmentMustNotFragment(t *testing.T) {
 	bndl, err := Builder().
 		Source("dtn://src/").
@@ -8,9 +8,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
 )
 
 // DtnTime is an integer representation of milliseconds since the start of the year 2000 (UTC).
@@ -51,7 +54,23 @@ func DtnTimeFromTime(t time.Time) DtnTime {
 
 // DtnTimeNow returns the current (UTC) time as DtnTime.
 func DtnTimeNow() DtnTime {
-	return DtnTimeFromTime(time.Now())
+	return DtnTimeFromTime(clock.Now())
+}
+
+// clockless is set for nodes without an accurate clock, see SetClockless.
+var clockless atomic.Bool
+
+// SetClockless switches this node to clockless operation, as described in RFC 9171 for nodes lacking an accurate
+// clock, e.g., devices without a real-time clock. Built bundles get a zero creation time and a mandatory Bundle Age
+// Block. The lifetime of all bundles is solely judged by their Bundle Age Block, as the local clock cannot be compared
+// with other nodes' creation times.
+func SetClockless(enabled bool) {
+	clockless.Store(enabled)
+}
+
+// IsClockless returns if this node operates without an accurate clock, see SetClockless.
+func IsClockless() bool {
+	return clockless.Load()
 }
 
 // CreationTimestamp is a tuple of a DtnTime and a sequence number (to differ
@@ -0,0 +1,139 @@
+package cla
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxAcceptBackoff caps the doubling of a listener's accept backoff, unless the configured backoff is even longer.
+const maxAcceptBackoff = 5 * time.Second
+
+// ConnectionLimits configures the admission of inbound connections by the listeners, see SetConnectionLimits.
+type ConnectionLimits struct {
+	// PerListener limits the concurrent connections of each listener, zero for no limit.
+	PerListener int
+	// PerSource limits the concurrent connections of each listener from the same IP address, zero for no limit.
+	PerSource int
+	// Backoff pauses a listener's accepting after it was full. It doubles for each further refused connection.
+	Backoff time.Duration
+}
+
+// CheckValid checks for non-negative limits and backoff.
+func (limits ConnectionLimits) CheckValid() error {
+	if limits.PerListener < 0 || limits.PerSource < 0 {
+		return fmt.Errorf("connection limits must not be negative")
+	}
+	if limits.Backoff < 0 {
+		return fmt.Errorf("accept backoff must not be negative")
+	}
+	return nil
+}
+
+// listenerAdmission counts the admitted connections of a listener.
+type listenerAdmission struct {
+	connections int
+	sources     map[string]int
+	// refusing is set while the listener is full, backoff being the pause after its last refused connection.
+	refusing bool
+	backoff  time.Duration
+}
+
+// SetConnectionLimits limits the concurrent inbound connections of each listener, protecting nodes reachable from
+// the internet against being flooded with connections. Only listeners asking for their connections' admission by
+// AdmitConnection are limited. Nil disables the limits for new connections.
+//
+// This method is thread-safe.
+func (manager *Manager) SetConnectionLimits(limits *ConnectionLimits) {
+	manager.admissionMutex.Lock()
+	defer manager.admissionMutex.Unlock()
+
+	manager.connectionLimits = limits
+}
+
+// sourceOf extracts a remote address' IP address, falling back to the whole address if it has no port.
+func sourceOf(remote net.Addr) string {
+	if host, _, err := net.SplitHostPort(remote.String()); err == nil {
+		return host
+	}
+	return remote.String()
+}
+
+// AdmitConnection decides if a listener, identified by its address, admits a newly accepted connection. An admitted
+// connection must be released when it is closed. A refused connection must be closed right away.
+//
+// If the listener is full, its accepting should be paused for the returned backoff, as further connections would be
+// refused anyway. Connections refused by the per source limit do not pause the listener, such that a single flooding
+// source cannot block the others.
+//
+// This method is thread-safe.
+func (manager *Manager) AdmitConnection(listener string, remote net.Addr) (
+	release func(), backoff time.Duration, ok bool) {
+	manager.admissionMutex.Lock()
+	defer manager.admissionMutex.Unlock()
+
+	if manager.connectionLimits == nil {
+		return func() {}, 0, true
+	}
+	limits := *manager.connectionLimits
+
+	admission, exists := manager.admissions[listener]
+	if !exists {
+		admission = &listenerAdmission{sources: make(map[string]int)}
+		manager.admissions[listener] = admission
+	}
+	source := sourceOf(remote)
+
+	if limits.PerListener > 0 && admission.connections >= limits.PerListener {
+		if !admission.refusing {
+			admission.refusing = true
+			admission.backoff = limits.Backoff
+			log.WithFields(log.Fields{
+				"listener":    listener,
+				"connections": admission.connections,
+				"backoff":     admission.backoff,
+			}).Warn("Listener reached its connection limit, refusing connections")
+		} else if admission.backoff < maxAcceptBackoff {
+			admission.backoff *= 2
+			if admission.backoff > maxAcceptBackoff {
+				admission.backoff = maxAcceptBackoff
+			}
+		}
+		return nil, admission.backoff, false
+	}
+	if limits.PerSource > 0 && admission.sources[source] >= limits.PerSource {
+		log.WithFields(log.Fields{
+			"listener": listener,
+			"source":   source,
+		}).Debug("Source reached its connection limit, refusing connection")
+		return nil, 0, false
+	}
+
+	admission.connections++
+	admission.sources[source]++
+	admission.refusing, admission.backoff = false, 0
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() { manager.releaseConnection(listener, admission, source) })
+	}
+	return release, 0, true
+}
+
+// releaseConnection removes a closed connection from its listener's counts.
+func (manager *Manager) releaseConnection(listener string, admission *listenerAdmission, source string) {
+	manager.admissionMutex.Lock()
+	defer manager.admissionMutex.Unlock()
+
+	admission.connections--
+	admission.sources[source]--
+	if admission.sources[source] <= 0 {
+		delete(admission.sources, source)
+	}
+	if admission.connections <= 0 && manager.admissions[listener] == admission {
+		delete(manager.admissions, listener)
+	}
+}
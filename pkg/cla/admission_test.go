@@ -0,0 +1,58 @@
+package cla
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestAdmitConnection(t *testing.T) {
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+
+	first := &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 40001}
+	second := &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 40002}
+	other := &net.TCPAddr{IP: net.ParseIP("10.0.0.3"), Port: 40001}
+
+	if _, _, ok := manager.AdmitConnection("mtcp://:35037", first); !ok {
+		t.Fatal("Connection was refused without limits")
+	}
+
+	manager.SetConnectionLimits(&ConnectionLimits{PerListener: 2, PerSource: 1, Backoff: 100 * time.Millisecond})
+
+	release, _, ok := manager.AdmitConnection("mtcp://:35037", first)
+	if !ok {
+		t.Fatal("First connection was refused")
+	}
+	if _, backoff, ok := manager.AdmitConnection("mtcp://:35037", second); ok || backoff != 0 {
+		t.Fatalf("Second connection of the same source was admitted or paused the listener for %v", backoff)
+	}
+	if _, _, ok := manager.AdmitConnection("quicl://:35037", second); !ok {
+		t.Fatal("Source was refused by another listener")
+	}
+
+	if _, _, ok := manager.AdmitConnection("mtcp://:35037", other); !ok {
+		t.Fatal("Other source was refused")
+	}
+	for _, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		if _, backoff, ok := manager.AdmitConnection("mtcp://:35037", other); ok || backoff != expected {
+			t.Fatalf("Full listener admitted a connection or paused for %v instead of %v", backoff, expected)
+		}
+	}
+
+	// Releasing twice must not free two slots.
+	release()
+	release()
+	if _, backoff, ok := manager.AdmitConnection("mtcp://:35037", second); !ok || backoff != 0 {
+		t.Fatal("Released connection's slot was not freed")
+	}
+	if _, _, ok := manager.AdmitConnection("mtcp://:35037", first); ok {
+		t.Fatal("Listener admitted more connections than its limit")
+	}
+}
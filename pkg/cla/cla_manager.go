@@ -1,7 +1,13 @@
 package cla
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/util"
@@ -27,13 +33,65 @@ type Manager struct {
 	// This is necessary since we can't directly import either the store or processing module without creating an import loop
 	receiveCallback func(bundle *bpv7.Bundle)
 
-	// connectCallback is called whenever a new peer connects.
-	// This is necessary since we can't import the routing-module without creating an import loop
-	connectCallback func(eid bpv7.EndpointID)
-
-	// disconnectCallback is called whenever a new peer disconnects.
-	// This is necessary since we can't import the routing-module without creating an import loop
-	disconnectCallback func(eid bpv7.EndpointID)
+	// events are published to the processing, routing and others, see events.go
+	// This is necessary since we can't import them without creating an import loop
+	events *EventBus
+
+	// shapingConfig is nil if outgoing traffic is not shaped.
+	// Otherwise, each sender gets its own Shaper, identified by the sender's address.
+	shapingMutex  sync.Mutex
+	shapingConfig *ShapingConfig
+	shapers       map[string]*Shaper
+
+	// liveness of probed peers, see peer_liveness.go
+	livenessMutex sync.Mutex
+	liveness      map[bpv7.EndpointID]PeerLiveness
+
+	// goodput and latency of peers as moving averages, see goodput.go
+	goodputMutex sync.Mutex
+	goodput      map[bpv7.EndpointID]movingAverage
+	latency      map[bpv7.EndpointID]movingAverage
+
+	// syncReceive calls the receiveCallback directly, see SetSynchronousReceive
+	syncReceive atomic.Bool
+
+	// suspension of idle senders, see suspension.go
+	suspensionMutex sync.Mutex
+	suspensionStop  chan struct{}
+	lastActivity    map[string]time.Time
+	suspending      map[string]bool
+	resuming        map[bpv7.EndpointID]bool
+
+	// roaming of peers changing their addresses, see roaming.go
+	roamingMutex   sync.Mutex
+	roamingEnabled bool
+	roamed         map[ConvergenceSender]ConvergenceSender // stale sender -> sender to the peer's new address
+	leaving        map[ConvergenceSender]bool
+
+	// mtuConfig is nil if no MTUs are learned. Otherwise, each sender's MTU is estimated by its address, see mtu.go
+	mtuMutex  sync.Mutex
+	mtuConfig *MTUConfig
+	mtus      map[string]*mtuEstimate
+
+	// emulate wraps each transmission's sender by its emulated link, nil unless links are emulated
+	emulationMutex sync.Mutex
+	emulate        func(ConvergenceSender) ConvergenceSender
+
+	// connectionLimits is nil if listeners accept connections without limits, see admission.go
+	admissionMutex   sync.Mutex
+	connectionLimits *ConnectionLimits
+	admissions       map[string]*listenerAdmission
+
+	// multihoming is nil if the operating system selects outgoing connections' source addresses, see multihoming.go
+	multihomingMutex sync.Mutex
+	multihoming      *MultihomingConfig
+	reachableVia     map[string]net.IP // peer's IP address -> local source address
+
+	// queueConfig is nil unless transmissions are queued per sender, identified by its address, see send_queue.go
+	queueMutex  sync.Mutex
+	queueConfig *SendQueueConfig
+	queues      map[string]*sendQueue
+	queueSeq    uint64
 }
 
 // managerSingleton is the singleton object which should always be used for manager access
@@ -43,20 +101,32 @@ var managerSingleton *Manager
 // InitialiseCLAManager initialises the manager-singleton
 // To access Singleton-instance, use GetManagerSingleton
 // Further calls to this function after initialisation will return a util.AlreadyInitialised-error
-func InitialiseCLAManager(receiveCallback func(bundle *bpv7.Bundle), connectCallback func(eid bpv7.EndpointID), disconnectCallback func(eid bpv7.EndpointID)) error {
+// Connecting and disconnecting peers are published as Events, see Events.
+func InitialiseCLAManager(receiveCallback func(bundle *bpv7.Bundle)) error {
 	if managerSingleton != nil {
 		return util.NewAlreadyInitialisedError("CLA Manager")
 	}
 
 	manager := Manager{
-		receivers:          make([]ConvergenceReceiver, 0, 10),
-		senders:            make([]ConvergenceSender, 0, 10),
-		pendingStart:       make([]Convergence, 0, 10),
-		listeners:          make([]ConvergenceListener, 0, 10),
-		receiveCallback:    receiveCallback,
-		connectCallback:    connectCallback,
-		disconnectCallback: disconnectCallback,
-		pendingRemoval:     make(map[string]bool),
+		receivers:       make([]ConvergenceReceiver, 0, 10),
+		senders:         make([]ConvergenceSender, 0, 10),
+		pendingStart:    make([]Convergence, 0, 10),
+		listeners:       make([]ConvergenceListener, 0, 10),
+		receiveCallback: receiveCallback,
+		events:          newEventBus(),
+		pendingRemoval:  make(map[string]bool),
+		shapers:         make(map[string]*Shaper),
+		liveness:        make(map[bpv7.EndpointID]PeerLiveness),
+		goodput:         make(map[bpv7.EndpointID]movingAverage),
+		latency:         make(map[bpv7.EndpointID]movingAverage),
+		lastActivity:    make(map[string]time.Time),
+		suspending:      make(map[string]bool),
+		resuming:        make(map[bpv7.EndpointID]bool),
+		roamed:          make(map[ConvergenceSender]ConvergenceSender),
+		leaving:         make(map[ConvergenceSender]bool),
+		mtus:            make(map[string]*mtuEstimate),
+		admissions:      make(map[string]*listenerAdmission),
+		queues:          make(map[string]*sendQueue),
 	}
 	managerSingleton = &manager
 	return nil
@@ -195,27 +265,55 @@ func (manager *Manager) registerAsync(cla Convergence) {
 		if sender, ok := cla.(ConvergenceSender); ok {
 			manager.senders = append(manager.senders, sender)
 			log.WithField("cla", cla).Debug("CLA added to senders")
+
+			if stale := manager.staleSenders(sender); len(stale) > 0 {
+				go manager.roam(sender, stale)
+			}
 		}
 	}
 }
 
 // NotifyReceive is to be called by CLAs when they have received (and successfully unmarshalled) a bundle.
-// This method spawns a new goroutine to handle the bundle asynchronously
+// This method spawns a new goroutine to handle the bundle asynchronously, unless SetSynchronousReceive was enabled.
 func (manager *Manager) NotifyReceive(bundle *bpv7.Bundle) {
 	log.WithField("bundle", bundle.ID().String()).Debug("Received bundle")
-	go manager.receiveCallback(bundle)
+	if manager.syncReceive.Load() {
+		manager.receiveCallback(bundle)
+	} else {
+		go manager.receiveCallback(bundle)
+	}
+}
+
+// SetSynchronousReceive lets NotifyReceive call the receive callback on the CLA's goroutine instead of spawning one
+// per bundle. This saves memory, but the callback must not block, e.g., by already handing the bundle over.
+// This method is thread-safe.
+func (manager *Manager) SetSynchronousReceive(sync bool) {
+	manager.syncReceive.Store(sync)
 }
 
 // NotifyConnect is to be called by a CLA if it has successfully stared AND is a sender AND is aware of its neighbours EndpointID
-// THis information is passed on to the routing algorithm asynchronously
+// This information is published asynchronously as a PeerConnected Event
 func (manager *Manager) NotifyConnect(peerID bpv7.EndpointID) {
-	go manager.connectCallback(peerID)
+	if manager.isResuming(peerID) {
+		log.WithField("peer", peerID).Debug("Resumed CLA connected, peer was still known")
+		return
+	}
+	manager.events.Publish(Event{Type: PeerConnected, Peer: peerID})
 }
 
 // NotifyDisconnect is to be called by a CLA if it notices that it has lost its connection
-// Will remove the CLA from either or both of the manager's lists.
+// Will remove the CLA from either or both of the manager's lists and publish a PeerDisconnected Event for a sender.
 // This method is thread-safe.
 func (manager *Manager) NotifyDisconnect(cla Convergence) {
+	if manager.isSuspending(cla) {
+		log.WithField("cla", cla).Debug("Suspended CLA disconnected, peer is still known")
+		return
+	}
+	if manager.isLeaving(cla) {
+		log.WithField("cla", cla).Debug("Stale CLA of a roamed peer disconnected, peer is still known")
+		return
+	}
+	manager.forgetRoamed(cla)
 	log.WithField("cla", cla).Info("CLA disappeared")
 
 	manager.disconnectMutex.Lock()
@@ -250,7 +348,7 @@ func (manager *Manager) NotifyDisconnect(cla Convergence) {
 
 	if sender, ok := cla.(ConvergenceSender); ok {
 		log.WithField("cla", cla).Debug("CLA was sender")
-		go manager.disconnectCallback(sender.GetPeerEndpointID())
+		manager.events.Publish(Event{Type: PeerDisconnected, Peer: sender.GetPeerEndpointID(), Address: sender.Address()})
 
 		newSenders := make([]ConvergenceSender, 0, len(manager.senders))
 		for _, registeredSender := range manager.senders {
@@ -263,6 +361,10 @@ func (manager *Manager) NotifyDisconnect(cla Convergence) {
 			"remaining senders": newSenders,
 		}).Debug("Senders remaining after filter")
 		manager.senders = newSenders
+
+		manager.shapingMutex.Lock()
+		delete(manager.shapers, sender.Address())
+		manager.shapingMutex.Unlock()
 	}
 
 	manager.disconnectMutex.Lock()
@@ -270,18 +372,183 @@ func (manager *Manager) NotifyDisconnect(cla Convergence) {
 	delete(manager.pendingRemoval, cla.Address())
 }
 
+// DisconnectRandomSender closes a randomly chosen sender-type CLA, as if its connection was lost.
+// This is used for fault injection.
+// This method is thread-safe.
+func (manager *Manager) DisconnectRandomSender() {
+	senders := manager.GetSenders()
+	if len(senders) == 0 {
+		log.Debug("No sender to disconnect")
+		return
+	}
+
+	sender := senders[rand.IntN(len(senders))]
+	log.WithField("cla", sender).Info("Disconnecting CLA")
+	if err := sender.Close(); err != nil {
+		log.WithFields(log.Fields{
+			"cla":   sender,
+			"error": err,
+		}).Debug("Error closing CLA")
+	}
+	manager.NotifyDisconnect(sender)
+}
+
+// SetShaping configures the traffic shaping of all senders. A nil config disables shaping.
+// This method is thread-safe.
+func (manager *Manager) SetShaping(config *ShapingConfig) {
+	manager.shapingMutex.Lock()
+	defer manager.shapingMutex.Unlock()
+
+	manager.shapingConfig = config
+	manager.shapers = make(map[string]*Shaper)
+}
+
+// SetLinkEmulation wraps each transmission's sender by emulate, e.g., an emulation.Emulator's Wrap, to emulate the
+// links' delay, loss and bandwidth in lab experiments. A nil function disables the emulation.
+// This method is thread-safe.
+func (manager *Manager) SetLinkEmulation(emulate func(ConvergenceSender) ConvergenceSender) {
+	manager.emulationMutex.Lock()
+	defer manager.emulationMutex.Unlock()
+
+	manager.emulate = emulate
+}
+
+func (manager *Manager) linkEmulation() func(ConvergenceSender) ConvergenceSender {
+	manager.emulationMutex.Lock()
+	defer manager.emulationMutex.Unlock()
+
+	return manager.emulate
+}
+
+// ErrBundleExpired is returned by Send for a bundle whose lifetime was exceeded before its transmission.
+var ErrBundleExpired = errors.New("bundle lifetime exceeded before its transmission")
+
+// ErrPeerUnreachable is wrapped by Send for a CLA's failed transmission, e.g., over a lost connection. Unlike an
+// expired bundle, the bundle might be sent again later.
+var ErrPeerUnreachable = errors.New("peer unreachable")
+
+// Send transmits a bundle over the sender, subject to the configured traffic shaping.
+// Successful transmissions are measured for the peer's goodput, see PeerGoodput, and postpone the sender's suspension.
+// Transmissions over the stale sender of a roamed peer are migrated to its current sender, see SetRoaming.
+// Bundles above the sender's learned MTU are sent as fragments, see SetMTULearning.
+// Transmissions are delayed or lost by an optional link emulation, see SetLinkEmulation.
+// Expired bundles are not sent, neither before nor while being queued by the traffic shaping, see ErrBundleExpired.
+// Each attempt is published as Events, a TransferStarted followed by a TransferCompleted or TransferFailed.
+// This method is thread-safe.
+func (manager *Manager) Send(sender ConvergenceSender, bndl bpv7.Bundle) (err error) {
+	sender = manager.migrated(sender)
+
+	transmission := manager.startTransmission(sender, bndl)
+	defer func() { manager.finishTransmission(transmission, err) }()
+
+	if bndl.IsLifetimeExceeded() {
+		return ErrBundleExpired
+	}
+
+	manager.touch(sender.Address())
+
+	if !manager.mtuLearning() {
+		return manager.transmit(sender, bndl)
+	}
+
+	size := int(BundleSize(bndl))
+	fragments, ok := manager.fragmentForMTU(sender, bndl, size)
+	if !ok {
+		err = manager.transmit(sender, bndl)
+		manager.observeMTU(sender, size, err)
+		return err
+	}
+
+	for _, fragment := range fragments {
+		err = manager.transmit(sender, fragment)
+		manager.observeMTU(sender, int(BundleSize(fragment)), err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transmit a bundle or a fragment over the sender, subject to the configured traffic shaping. A CLA's failure is
+// wrapped as ErrPeerUnreachable.
+func (manager *Manager) transmit(sender ConvergenceSender, bndl bpv7.Bundle) error {
+	err := manager.transmitShaped(sender, bndl)
+	if err != nil && !errors.Is(err, ErrBundleExpired) {
+		return fmt.Errorf("%w: %s: %w", ErrPeerUnreachable, sender.Address(), err)
+	}
+	return err
+}
+
+func (manager *Manager) transmitShaped(sender ConvergenceSender, bndl bpv7.Bundle) error {
+	var inner ConvergenceSender = migratingSender{ConvergenceSender: sender, manager: manager}
+	if emulate := manager.linkEmulation(); emulate != nil {
+		inner = emulate(inner)
+	}
+	measured := measuredSender{ConvergenceSender: inner, manager: manager}
+
+	manager.shapingMutex.Lock()
+	if manager.shapingConfig == nil {
+		manager.shapingMutex.Unlock()
+		return measured.Send(bndl)
+	}
+
+	shaper, ok := manager.shapers[sender.Address()]
+	if !ok {
+		shaper = NewShaper(*manager.shapingConfig)
+		manager.shapers[sender.Address()] = shaper
+	}
+	manager.shapingMutex.Unlock()
+
+	return shaper.Send(measured, bndl)
+}
+
+// RegisterListener starts a listener and keeps it until its UnregisterListener or the Manager's Shutdown.
+// This method is thread-safe.
 func (manager *Manager) RegisterListener(listener ConvergenceListener) error {
 	err := listener.Start()
 	if err != nil {
 		return err
 	}
 
+	manager.stateMutex.Lock()
 	manager.listeners = append(manager.listeners, listener)
+	manager.stateMutex.Unlock()
 
 	return nil
 }
 
+// UnregisterListener closes a listener, identified by its Address, and forgets it, including its registration as a
+// CLA, e.g., of an MTCP server. Connections accepted before are not closed.
+// This method is thread-safe.
+func (manager *Manager) UnregisterListener(address string) error {
+	manager.stateMutex.Lock()
+	var listener ConvergenceListener
+	remaining := make([]ConvergenceListener, 0, len(manager.listeners))
+	for _, registered := range manager.listeners {
+		if registered.Address() == address {
+			listener = registered
+		} else {
+			remaining = append(remaining, registered)
+		}
+	}
+	manager.listeners = remaining
+	manager.stateMutex.Unlock()
+
+	if listener == nil {
+		return fmt.Errorf("no listener registered for %s", address)
+	}
+
+	log.WithField("listener", address).Info("Closing CLA listener")
+	if conv, ok := listener.(Convergence); ok {
+		manager.NotifyDisconnect(conv)
+	}
+	return listener.Close()
+}
+
 func (manager *Manager) Shutdown() {
+	manager.SetSuspension(0)
+	manager.events.close()
+
 	manager.stateMutex.Lock()
 	defer manager.stateMutex.Unlock()
 
@@ -13,10 +13,8 @@ import (
 
 func setup(t *rapid.T) {
 	receive := func(bundle *bpv7.Bundle) {}
-	connect := func(eid bpv7.EndpointID) {}
-	disconnect := func(eid bpv7.EndpointID) {}
 
-	err := InitialiseCLAManager(receive, connect, disconnect)
+	err := InitialiseCLAManager(receive)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -61,6 +59,38 @@ func TestRegisterListener(t *testing.T) {
 	})
 }
 
+func TestUnregisterListener(t *testing.T) {
+	if err := InitialiseCLAManager(func(bundle *bpv7.Bundle) {}); err != nil {
+		t.Fatal(err)
+	}
+	defer GetManagerSingleton().Shutdown()
+
+	for _, address := range []string{"dummy://a", "dummy://b"} {
+		if err := GetManagerSingleton().RegisterListener(dummy_cla.NewDummyListener(address)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := GetManagerSingleton().UnregisterListener("dummy://a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := GetManagerSingleton().UnregisterListener("dummy://a"); err == nil {
+		t.Fatal("Unregistering an unknown listener did not fail")
+	}
+
+	if listeners := GetManagerSingleton().GetListeners(); len(listeners) != 1 || listeners[0].Address() != "dummy://b" {
+		t.Fatalf("Expected only dummy://b to remain, got %v", listeners)
+	}
+
+	// a listener might be registered again for the same address, e.g., when restarted
+	if err := GetManagerSingleton().RegisterListener(dummy_cla.NewDummyListener("dummy://a")); err != nil {
+		t.Fatal(err)
+	}
+	if listeners := GetManagerSingleton().GetListeners(); len(listeners) != 2 {
+		t.Fatalf("Expected two listeners, got %v", listeners)
+	}
+}
+
 func TestRegisterSender(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		setup(t)
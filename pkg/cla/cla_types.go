@@ -28,6 +28,13 @@ const (
 
 	QUICL CLAType = 20
 
+	// UDP multicast, reaching all neighbours on a network segment with a single transmission
+	MUDP CLAType = 40
+
+	// Receive-only TCP Convergence Layer Protocol Version 3 for BPv6 nodes, as specified in RFC 7242
+	// https://datatracker.ietf.org/doc/html/rfc7242
+	TCPCLv3 CLAType = 50
+
 	// Dummy CLA used for testing
 	Dummy CLAType = 8080
 
@@ -43,6 +50,10 @@ func TypeFromString(claType string) (CLAType, error) {
 		return MTCP, nil
 	case "quicl":
 		return QUICL, nil
+	case "mudp":
+		return MUDP, nil
+	case "tcpclv3":
+		return TCPCLv3, nil
 	default:
 		return 0, fmt.Errorf("invalid CLA Type: %v", claType)
 	}
@@ -67,6 +78,12 @@ func (claType CLAType) String() string {
 	case QUICL:
 		return "QUICL"
 
+	case MUDP:
+		return "MUDP"
+
+	case TCPCLv3:
+		return "TCPCLv3"
+
 	default:
 		return unknownClaTypeString
 	}
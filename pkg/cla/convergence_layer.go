@@ -85,3 +85,13 @@ type ConvergenceSender interface {
 	// if it's known. Otherwise, the zero endpoint will be returned.
 	GetPeerEndpointID() bpv7.EndpointID
 }
+
+// Redialer is a ConvergenceSender which can be re-created after being closed, e.g., a client dialing its peer.
+// Only Redialers are suspended by the Manager, see SetSuspension. Their Close must be notified to the Manager, as
+// otherwise the suspension's disconnect cannot be told apart from a real one.
+type Redialer interface {
+	ConvergenceSender
+
+	// Redial returns a new, not yet activated ConvergenceSender to the same peer.
+	Redial() ConvergenceSender
+}
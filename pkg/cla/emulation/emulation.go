@@ -0,0 +1,150 @@
+// Package emulation wraps CLAs to emulate a link's delay, jitter, loss and bandwidth, e.g., for lab experiments with
+// real or in-memory CLAs without an external network emulator like netem.
+//
+// Only outgoing transmissions are emulated. Thus, each node of an experiment emulates its own outgoing links.
+package emulation
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// LinkConfig describes an emulated link. The zero value is a perfect link.
+type LinkConfig struct {
+	// Delay is each transmission's propagation delay.
+	Delay time.Duration
+	// Jitter varies the Delay randomly by up to this duration in both directions, but never below zero.
+	Jitter time.Duration
+	// Loss is the chance of losing a transmission, which is still reported as sent.
+	Loss float64
+	// Bandwidth in bytes per second, unlimited if zero. Transmissions over the same link are serialized.
+	Bandwidth uint64
+}
+
+// CheckValid checks the loss' range and that durations are not negative.
+func (config LinkConfig) CheckValid() error {
+	if config.Delay < 0 || config.Jitter < 0 {
+		return fmt.Errorf("link delay %v and jitter %v must not be negative", config.Delay, config.Jitter)
+	}
+	if config.Loss < 0 || config.Loss > 1 {
+		return fmt.Errorf("link loss %v is not between 0 and 1", config.Loss)
+	}
+	return nil
+}
+
+// delay of a transmission, varied by the jitter.
+func (config LinkConfig) delay() time.Duration {
+	if config.Jitter <= 0 {
+		return config.Delay
+	}
+	delay := config.Delay - config.Jitter + rand.N(2*config.Jitter+1)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// link is the state of an emulated link, shared by all transmissions over it.
+type link struct {
+	config LinkConfig
+	// mutex is held while a transmission occupies the link's bandwidth.
+	mutex sync.Mutex
+}
+
+// Sender wraps a ConvergenceSender, emulating its link for each sent bundle. All other methods are passed through.
+type Sender struct {
+	cla.ConvergenceSender
+	link *link
+}
+
+// NewSender wraps a ConvergenceSender by a new emulated link.
+func NewSender(sender cla.ConvergenceSender, config LinkConfig) *Sender {
+	return &Sender{ConvergenceSender: sender, link: &link{config: config}}
+}
+
+// Send a bundle after the link's transmission and propagation delay, unless it is lost.
+func (sender *Sender) Send(bndl bpv7.Bundle) error {
+	config := sender.link.config
+
+	if config.Bandwidth > 0 {
+		size := cla.BundleSize(bndl)
+		sender.link.mutex.Lock()
+		clock.Sleep(time.Duration(float64(size) / float64(config.Bandwidth) * float64(time.Second)))
+		sender.link.mutex.Unlock()
+	}
+	if delay := config.delay(); delay > 0 {
+		clock.Sleep(delay)
+	}
+
+	if config.Loss > 0 && rand.Float64() < config.Loss {
+		log.WithFields(log.Fields{
+			"cla":    sender.ConvergenceSender,
+			"bundle": bndl.ID(),
+		}).Debug("Emulated link lost a bundle")
+		return nil
+	}
+	return sender.ConvergenceSender.Send(bndl)
+}
+
+// Emulator emulates the links to all peers, either by a peer's own LinkConfig or by the default.
+type Emulator struct {
+	defaultConfig LinkConfig
+	peers         map[string]LinkConfig // node, by scheme and authority -> config
+
+	mutex sync.Mutex
+	links map[string]*link // CLA address -> link
+}
+
+// nodeKey identifies an EndpointID's node, such that all its endpoints share a link.
+func nodeKey(eid bpv7.EndpointID) string {
+	if eid.EndpointType == nil {
+		return ""
+	}
+	return eid.EndpointType.SchemeName() + "://" + eid.Authority()
+}
+
+// NewEmulator for links of the default LinkConfig, unless a peer's node has its own LinkConfig.
+func NewEmulator(defaultConfig LinkConfig, peers map[bpv7.EndpointID]LinkConfig) (*Emulator, error) {
+	if err := defaultConfig.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	emulator := &Emulator{
+		defaultConfig: defaultConfig,
+		peers:         make(map[string]LinkConfig, len(peers)),
+		links:         make(map[string]*link),
+	}
+	for peer, config := range peers {
+		if err := config.CheckValid(); err != nil {
+			return nil, fmt.Errorf("link to %v: %w", peer, err)
+		}
+		emulator.peers[nodeKey(peer)] = config
+	}
+	return emulator, nil
+}
+
+// Wrap a ConvergenceSender by its emulated link. Senders to the same address share their link, and thus its
+// bandwidth. This method can be passed to the cla.Manager's SetLinkEmulation.
+func (emulator *Emulator) Wrap(sender cla.ConvergenceSender) cla.ConvergenceSender {
+	emulator.mutex.Lock()
+	defer emulator.mutex.Unlock()
+
+	l, ok := emulator.links[sender.Address()]
+	if !ok {
+		config, ok := emulator.peers[nodeKey(sender.GetPeerEndpointID())]
+		if !ok {
+			config = emulator.defaultConfig
+		}
+		l = &link{config: config}
+		emulator.links[sender.Address()] = l
+	}
+	return &Sender{ConvergenceSender: sender, link: l}
+}
@@ -0,0 +1,142 @@
+package emulation
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// recordingSender records its sent bundles.
+type recordingSender struct {
+	address string
+	peer    bpv7.EndpointID
+
+	mutex sync.Mutex
+	sent  []bpv7.Bundle
+}
+
+func (s *recordingSender) Send(bndl bpv7.Bundle) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sent = append(s.sent, bndl)
+	return nil
+}
+
+func (s *recordingSender) Sent() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.sent)
+}
+
+func (s *recordingSender) GetPeerEndpointID() bpv7.EndpointID { return s.peer }
+func (s *recordingSender) Close() error                       { return nil }
+func (s *recordingSender) Activate() error                    { return nil }
+func (s *recordingSender) Active() bool                       { return true }
+func (s *recordingSender) Address() string                    { return s.address }
+
+func testBundle(t *testing.T) bpv7.Bundle {
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock(make([]byte, 1000)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+func TestLinkConfigCheckValid(t *testing.T) {
+	tests := []struct {
+		config LinkConfig
+		valid  bool
+	}{
+		{LinkConfig{}, true},
+		{LinkConfig{Delay: time.Second, Jitter: time.Second, Loss: 1, Bandwidth: 1024}, true},
+		{LinkConfig{Delay: -time.Second}, false},
+		{LinkConfig{Jitter: -time.Second}, false},
+		{LinkConfig{Loss: 1.5}, false},
+	}
+	for _, test := range tests {
+		if err := test.config.CheckValid(); (err == nil) != test.valid {
+			t.Fatalf("Config %v has validity %t, but error %v", test.config, test.valid, err)
+		}
+	}
+}
+
+func TestSenderDelay(t *testing.T) {
+	fc := clock.NewFakeClock(time.Unix(0, 0))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	inner := &recordingSender{address: "mtcp://10.0.0.2:35037", peer: bpv7.MustNewEndpointID("dtn://peer/")}
+	bndl := testBundle(t)
+	sender := NewSender(inner, LinkConfig{Delay: time.Second, Bandwidth: cla.BundleSize(bndl)})
+
+	done := make(chan error)
+	go func() { done <- sender.Send(bndl) }()
+
+	// One second to transmit the bundle at the bandwidth, one second of delay.
+	for i := 0; i < 2; i++ {
+		fc.BlockUntil(1)
+		if inner.Sent() != 0 {
+			t.Fatalf("Bundle was sent after %d seconds", i)
+		}
+		fc.Advance(time.Second)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if inner.Sent() != 1 {
+		t.Fatal("Bundle was not sent")
+	}
+}
+
+func TestSenderLoss(t *testing.T) {
+	inner := &recordingSender{address: "mtcp://10.0.0.2:35037", peer: bpv7.MustNewEndpointID("dtn://peer/")}
+	sender := NewSender(inner, LinkConfig{Loss: 1})
+
+	if err := sender.Send(testBundle(t)); err != nil {
+		t.Fatalf("Lost bundle was reported as failed: %v", err)
+	}
+	if inner.Sent() != 0 {
+		t.Fatal("Bundle was not lost")
+	}
+}
+
+func TestEmulatorWrap(t *testing.T) {
+	emulator, err := NewEmulator(LinkConfig{}, map[bpv7.EndpointID]LinkConfig{
+		bpv7.MustNewEndpointID("dtn://lossy/"): {Loss: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lossy := &recordingSender{address: "mtcp://10.0.0.2:35037", peer: bpv7.MustNewEndpointID("dtn://lossy/app")}
+	perfect := &recordingSender{address: "mtcp://10.0.0.3:35037", peer: bpv7.MustNewEndpointID("dtn://other/")}
+
+	for _, inner := range []*recordingSender{lossy, perfect} {
+		if err := emulator.Wrap(inner).Send(testBundle(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lossy.Sent() != 0 || perfect.Sent() != 1 {
+		t.Fatalf("Expected only the perfect link to send, got %d and %d", lossy.Sent(), perfect.Sent())
+	}
+
+	if emulator.Wrap(lossy).(*Sender).link != emulator.Wrap(lossy).(*Sender).link {
+		t.Fatal("Senders to the same address do not share their link")
+	}
+
+	if _, err := NewEmulator(LinkConfig{}, map[bpv7.EndpointID]LinkConfig{
+		bpv7.MustNewEndpointID("dtn://lossy/"): {Loss: 2},
+	}); err == nil {
+		t.Fatal("Invalid peer link was accepted")
+	}
+}
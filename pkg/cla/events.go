@@ -0,0 +1,228 @@
+package cla
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// EventType distinguishes the Events published by the Manager's EventBus.
+type EventType int
+
+const (
+	// PeerConnected is published if a sender to a new peer was started, see Manager.NotifyConnect.
+	PeerConnected EventType = iota
+	// PeerDisconnected is published if a peer's sender was lost, see Manager.NotifyDisconnect.
+	PeerDisconnected
+	// PeerMoved is published if a peer roamed to a new address, see Manager.SetRoaming.
+	PeerMoved
+	// TransferStarted is published before a bundle is sent to a peer, see Manager.Send.
+	TransferStarted
+	// TransferCompleted is published after a bundle was sent to a peer.
+	TransferCompleted
+	// TransferFailed is published if a bundle could not be sent to a peer, e.g., as its lifetime was exceeded.
+	TransferFailed
+	// ListenerError is published if a listener failed to accept connections, see Manager.NotifyListenerError.
+	ListenerError
+	// ProcessingPanic is published if the bundle processing recovered from a panic, see Manager.NotifyProcessingPanic.
+	// Although not caused by a CLA, it reaches the same subscribers, e.g., the admin API.
+	ProcessingPanic
+)
+
+func (eventType EventType) String() string {
+	switch eventType {
+	case PeerConnected:
+		return "peer_connected"
+	case PeerDisconnected:
+		return "peer_disconnected"
+	case PeerMoved:
+		return "peer_moved"
+	case TransferStarted:
+		return "transfer_started"
+	case TransferCompleted:
+		return "transfer_completed"
+	case TransferFailed:
+		return "transfer_failed"
+	case ListenerError:
+		return "listener_error"
+	case ProcessingPanic:
+		return "processing_panic"
+	default:
+		return "unknown"
+	}
+}
+
+// Event of the CLAs, published by the Manager's EventBus.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Peer's node ID, unknown for a ListenerError or a ProcessingPanic.
+	Peer bpv7.EndpointID
+	// Address of the peer's sender or of the failed listener. The new address of a moved peer.
+	Address string
+	// PreviousAddress of a moved peer.
+	PreviousAddress string
+	// Transmission of a transfer. Its Duration and Err are unset while the transfer is started.
+	Transmission *Transmission
+	// Err of a failed transfer or a listener, or the recovered panic.
+	Err error
+}
+
+// subscriber of an EventBus, receiving its Events in order on its own goroutine.
+type subscriber struct {
+	handler func(Event)
+	types   map[EventType]bool
+
+	mutex sync.Mutex
+	queue []Event
+	wake  chan struct{}
+	stop  chan struct{}
+}
+
+// wants checks if the subscriber subscribed to an event type. A subscription without types receives all events.
+func (sub *subscriber) wants(eventType EventType) bool {
+	return len(sub.types) == 0 || sub.types[eventType]
+}
+
+// push enqueues an event without blocking the publisher.
+func (sub *subscriber) push(event Event) {
+	sub.mutex.Lock()
+	sub.queue = append(sub.queue, event)
+	sub.mutex.Unlock()
+
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run passes the queued events to the handler until the subscription ends.
+func (sub *subscriber) run() {
+	for {
+		select {
+		case <-sub.stop:
+			return
+
+		case <-sub.wake:
+			for {
+				sub.mutex.Lock()
+				if len(sub.queue) == 0 {
+					sub.mutex.Unlock()
+					break
+				}
+				event := sub.queue[0]
+				sub.queue = sub.queue[1:]
+				sub.mutex.Unlock()
+
+				sub.handler(event)
+			}
+		}
+	}
+}
+
+// EventBus publishes the Manager's Events to its subscribers, e.g., the processing, routing and the admin API. Thus,
+// the CLAs are not coupled to their consumers.
+//
+// Each subscriber receives its events in the order of their publication on its own goroutine. A slow subscriber
+// neither blocks the CLAs nor other subscribers, as its events are queued.
+type EventBus struct {
+	mutex       sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+// newEventBus creates an EventBus without subscribers.
+func newEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[*subscriber]struct{})}
+}
+
+// Subscribe calls the handler for each published event of the given types, or of all types if none are given. The
+// returned function ends the subscription.
+// This method is thread-safe.
+func (bus *EventBus) Subscribe(handler func(Event), types ...EventType) (unsubscribe func()) {
+	sub := &subscriber{
+		handler: handler,
+		types:   make(map[EventType]bool),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	for _, eventType := range types {
+		sub.types[eventType] = true
+	}
+
+	bus.mutex.Lock()
+	bus.subscribers[sub] = struct{}{}
+	bus.mutex.Unlock()
+
+	go sub.run()
+
+	return func() {
+		bus.mutex.Lock()
+		defer bus.mutex.Unlock()
+
+		if _, ok := bus.subscribers[sub]; ok {
+			delete(bus.subscribers, sub)
+			close(sub.stop)
+		}
+	}
+}
+
+// HasSubscribers checks if any subscriber wants events of this type, e.g., to skip expensive preparations.
+// This method is thread-safe.
+func (bus *EventBus) HasSubscribers(eventType EventType) bool {
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	for sub := range bus.subscribers {
+		if sub.wants(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish an event to all interested subscribers, setting its Time if unset.
+// This method is thread-safe and does not block.
+func (bus *EventBus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = clock.Now()
+	}
+
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	for sub := range bus.subscribers {
+		if sub.wants(event.Type) {
+			sub.push(event)
+		}
+	}
+}
+
+// close ends all subscriptions.
+func (bus *EventBus) close() {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	for sub := range bus.subscribers {
+		close(sub.stop)
+	}
+	bus.subscribers = make(map[*subscriber]struct{})
+}
+
+// Events returns the EventBus of this Manager, publishing peer, transfer and listener events.
+func (manager *Manager) Events() *EventBus {
+	return manager.events
+}
+
+// NotifyListenerError is to be called by a listener if it failed to accept connections, publishing a ListenerError.
+// This method is thread-safe.
+func (manager *Manager) NotifyListenerError(address string, err error) {
+	manager.events.Publish(Event{Type: ListenerError, Address: address, Err: err})
+}
+
+// NotifyProcessingPanic is to be called by the processing if it recovered from a panic, publishing a ProcessingPanic.
+// This method is thread-safe.
+func (manager *Manager) NotifyProcessingPanic(err error) {
+	manager.events.Publish(Event{Type: ProcessingPanic, Err: err})
+}
@@ -0,0 +1,81 @@
+package cla
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestEventBusSubscribe(t *testing.T) {
+	bus := newEventBus()
+	defer bus.close()
+
+	peers := make(chan EventType, 10)
+	all := make(chan EventType, 10)
+	bus.Subscribe(func(ev Event) { peers <- ev.Type }, PeerConnected, PeerDisconnected)
+	bus.Subscribe(func(ev Event) { all <- ev.Type })
+
+	if !bus.HasSubscribers(TransferFailed) {
+		t.Fatal("Subscriber of all events is not reported")
+	}
+
+	published := []EventType{PeerConnected, TransferStarted, TransferCompleted, PeerDisconnected, ListenerError}
+	for _, eventType := range published {
+		bus.Publish(Event{Type: eventType, Peer: bpv7.MustNewEndpointID("dtn://peer/")})
+	}
+
+	receive := func(events chan EventType, n int) (types []EventType) {
+		for i := 0; i < n; i++ {
+			select {
+			case eventType := <-events:
+				types = append(types, eventType)
+			case <-time.After(time.Second):
+				t.Fatalf("Received only %v", types)
+			}
+		}
+		return
+	}
+
+	if types := receive(peers, 2); !reflect.DeepEqual(types, []EventType{PeerConnected, PeerDisconnected}) {
+		t.Fatalf("Filtered subscriber received %v", types)
+	}
+	if types := receive(all, len(published)); !reflect.DeepEqual(types, published) {
+		t.Fatalf("Subscriber received %v instead of %v", types, published)
+	}
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus := newEventBus()
+	defer bus.close()
+
+	events := make(chan Event, 10)
+	unsubscribe := bus.Subscribe(func(ev Event) { events <- ev }, ListenerError)
+	if !bus.HasSubscribers(ListenerError) || bus.HasSubscribers(PeerMoved) {
+		t.Fatal("Subscribed types are not reported")
+	}
+
+	bus.Publish(Event{Type: ListenerError, Address: "[::]:4556"})
+	select {
+	case ev := <-events:
+		if ev.Address != "[::]:4556" || ev.Time.IsZero() {
+			t.Fatalf("Received unexpected event %v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Event was not received")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if bus.HasSubscribers(ListenerError) {
+		t.Fatal("Ended subscription is still reported")
+	}
+
+	bus.Publish(Event{Type: ListenerError})
+	select {
+	case ev := <-events:
+		t.Fatalf("Received event %v after unsubscribing", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,115 @@
+package cla
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+const (
+	// goodputWeight is the weight of a new sample within a peer's exponentially weighted moving average.
+	goodputWeight = 0.25
+
+	// goodputMinSize is the minimum bundle size to be measured. Smaller bundles mostly end in a buffer and would
+	// only measure the latency of a write call. Thus, they are measured for the peer's latency instead.
+	goodputMinSize = 4096
+)
+
+// movingAverage is an exponentially weighted moving average.
+type movingAverage struct {
+	value   float64
+	samples uint64
+}
+
+// update the average by a new sample. The first sample initialises the average.
+func (avg *movingAverage) update(sample float64) {
+	if avg.samples == 0 {
+		avg.value = sample
+	} else {
+		avg.value = goodputWeight*sample + (1-goodputWeight)*avg.value
+	}
+	avg.samples++
+}
+
+// measuredSender wraps a ConvergenceSender to measure the goodput or the latency of each successful transmission.
+type measuredSender struct {
+	ConvergenceSender
+	manager *Manager
+}
+
+func (sender measuredSender) Send(bndl bpv7.Bundle) error {
+	start := clock.Now()
+	if err := sender.ConvergenceSender.Send(bndl); err != nil {
+		return err
+	}
+	duration := clock.Now().Sub(start)
+
+	if size := BundleSize(bndl); size >= goodputMinSize && duration > 0 {
+		sender.manager.recordGoodput(sender.GetPeerEndpointID(), float64(size)/duration.Seconds())
+	} else if size < goodputMinSize {
+		sender.manager.RecordLatency(sender.GetPeerEndpointID(), duration)
+	}
+	return nil
+}
+
+// recordGoodput adds a measured goodput in bytes per second to a peer's moving average.
+// This method is thread-safe.
+func (manager *Manager) recordGoodput(peerID bpv7.EndpointID, bytesPerSecond float64) {
+	manager.goodputMutex.Lock()
+	defer manager.goodputMutex.Unlock()
+
+	avg := manager.goodput[peerID]
+	avg.update(bytesPerSecond)
+	manager.goodput[peerID] = avg
+
+	log.WithFields(log.Fields{
+		"peer":    peerID,
+		"sample":  bytesPerSecond,
+		"average": avg.value,
+	}).Debug("Measured peer goodput")
+}
+
+// PeerGoodput returns the moving average of a peer's goodput in bytes per second, as measured from completed
+// transmissions. Routing algorithms might use this as a hint to prefer fast links. If no transmission was measured
+// yet, known is false.
+// This method is thread-safe.
+func (manager *Manager) PeerGoodput(peerID bpv7.EndpointID) (bytesPerSecond float64, known bool) {
+	manager.goodputMutex.Lock()
+	defer manager.goodputMutex.Unlock()
+
+	avg, known := manager.goodput[peerID]
+	return avg.value, known
+}
+
+// RecordLatency adds a measured latency to a peer's moving average. Besides the transmissions of small bundles, whose
+// duration approximates the round-trip time for CLAs acknowledging each bundle, probes may record latencies, e.g., the
+// duration of a TCP handshake.
+// This method is thread-safe.
+func (manager *Manager) RecordLatency(peerID bpv7.EndpointID, latency time.Duration) {
+	manager.goodputMutex.Lock()
+	defer manager.goodputMutex.Unlock()
+
+	avg := manager.latency[peerID]
+	avg.update(float64(latency))
+	manager.latency[peerID] = avg
+
+	log.WithFields(log.Fields{
+		"peer":    peerID,
+		"sample":  latency,
+		"average": time.Duration(avg.value),
+	}).Debug("Measured peer latency")
+}
+
+// PeerLatency returns the moving average of a peer's latency, see RecordLatency. Routing algorithms might use this as
+// a hint to prefer responsive links for interactive traffic. If no latency was measured yet, known is false.
+// This method is thread-safe.
+func (manager *Manager) PeerLatency(peerID bpv7.EndpointID) (latency time.Duration, known bool) {
+	manager.goodputMutex.Lock()
+	defer manager.goodputMutex.Unlock()
+
+	avg, known := manager.latency[peerID]
+	return time.Duration(avg.value), known
+}
@@ -0,0 +1,91 @@
+package cla
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestMovingAverage(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		samples := rapid.SliceOfN(rapid.Float64Range(1, 1e9), 1, 100).Draw(t, "samples")
+
+		var avg movingAverage
+		lowest, highest := samples[0], samples[0]
+		for i, sample := range samples {
+			avg.update(sample)
+
+			if sample < lowest {
+				lowest = sample
+			}
+			if sample > highest {
+				highest = sample
+			}
+			if avg.value < lowest*(1-1e-9) || avg.value > highest*(1+1e-9) {
+				t.Fatalf("Average %f after sample %d is not within [%f, %f]", avg.value, i, lowest, highest)
+			}
+		}
+
+		if avg.samples != uint64(len(samples)) {
+			t.Fatalf("Average counted %d instead of %d samples", avg.samples, len(samples))
+		}
+	})
+}
+
+func TestMovingAverageConverges(t *testing.T) {
+	var avg movingAverage
+	avg.update(1000)
+	for i := 0; i < 50; i++ {
+		avg.update(10)
+	}
+
+	if s := fmt.Sprintf("%.2f", avg.value); s != "10.00" {
+		t.Fatalf("Average did not converge to 10, but is %s", s)
+	}
+}
+
+func TestPeerLatency(t *testing.T) {
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+
+	peer := bpv7.MustNewEndpointID("dtn://peer/")
+	if _, known := manager.PeerLatency(peer); known {
+		t.Fatal("Unmeasured peer has a latency")
+	}
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := measuredSender{ConvergenceSender: &redialSender{manager: manager, address: "10.0.0.2:35037", peer: peer}, manager: manager}
+	if err := sender.Send(bndl); err != nil {
+		t.Fatal(err)
+	}
+	if _, known := manager.PeerLatency(peer); !known {
+		t.Fatal("Small bundle's transmission was not measured as latency")
+	}
+	if _, known := manager.PeerGoodput(peer); known {
+		t.Fatal("Small bundle's transmission was measured as goodput")
+	}
+
+	for i := 0; i < 50; i++ {
+		manager.RecordLatency(peer, 20*time.Millisecond)
+	}
+	if latency, _ := manager.PeerLatency(peer); latency.Round(time.Millisecond) != 20*time.Millisecond {
+		t.Fatalf("Latency did not converge to 20ms, but is %v", latency)
+	}
+}
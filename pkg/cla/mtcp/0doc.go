@@ -8,4 +8,10 @@
 // Because of the unidirectional design of MTCP, both MTPCServer and MTCPClient
 // exists. The MTPCServer implements the ConvergenceReceiver and the MTCPClient
 // the ConvergenceSender interfaces defined in the parent cla package.
+//
+// For the same reason, an MTCPServer cannot signal a refused bundle, e.g., by
+// the node's congestion policy, back to the MTCPClient. Instead, the refused
+// bundle's report-to endpoint might be informed by a status report. Signalling
+// the refusal would break the compatibility to other MTCP implementations;
+// QUICL and TCPCLv3 are able to refuse bundles.
 package mtcp
@@ -13,7 +13,6 @@ import (
 	"fmt"
 	"net"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -22,20 +21,61 @@ import (
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
 )
 
+// clientState is an MTCPClient's lifecycle state. A client is created, activated once and finally closed.
+// A closed client cannot be activated again; a new MTCPClient must be created instead.
+type clientState int
+
+const (
+	// clientCreated is the initial state, before Activate was called.
+	clientCreated clientState = iota
+	// clientActivating is the state while Activate dials the server.
+	clientActivating
+	// clientActive is the state of a connected client, which might Send bundles.
+	clientActive
+	// clientClosed is the final state after Close or a failed Activate.
+	clientClosed
+)
+
+func (state clientState) String() string {
+	switch state {
+	case clientCreated:
+		return "created"
+	case clientActivating:
+		return "activating"
+	case clientActive:
+		return "active"
+	case clientClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
 // MTCPClient is an implementation of a Minimal TCP Convergence-Layer client
 // which connects to a MTCP server to send bundles. This struct implements
 // a ConvergenceSender.
+//
+// All methods are safe for concurrent use. Close is idempotent and might
+// also be called before Activate.
 type MTCPClient struct {
 	conn  net.Conn
 	peer  bpv7.EndpointID
 	mutex sync.Mutex
 
-	address string
-
-	stopSyn chan struct{}
-	stopped atomic.Bool
+	address  string
+	timeouts Timeouts
+	// writer renews the write deadline for the WriteTimeout
+	writer deadlineWriter
+	// out writes the byte strings to the writer, compressed if negotiated, see compression.go
+	out streamWriter
+
+	// stateMutex protects state and stopSyn, which is closed when leaving clientActive.
+	stateMutex sync.Mutex
+	state      clientState
+	stopSyn    chan struct{}
 }
 
 // NewMTCPClient creates a new MTCPClient, connected to the given address for
@@ -45,6 +85,7 @@ func NewMTCPClient(address string, peer bpv7.EndpointID) *MTCPClient {
 	return &MTCPClient{
 		peer:    peer,
 		address: address,
+		state:   clientCreated,
 	}
 }
 
@@ -55,40 +96,93 @@ func NewAnonymousMTCPClient(address string) *MTCPClient {
 	return NewMTCPClient(address, bpv7.DtnNone())
 }
 
-func (client *MTCPClient) Activate() (err error) {
-	conn, connErr := dial(client.address)
-	if connErr != nil {
-		err = connErr
-		return
+// Activate connects to the server. This is only possible once for a newly created MTCPClient.
+func (client *MTCPClient) Activate() error {
+	client.stateMutex.Lock()
+	if client.state != clientCreated {
+		state := client.state
+		client.stateMutex.Unlock()
+		return fmt.Errorf("MTCPClient cannot be activated in state %v", state)
+	}
+	client.state = clientActivating
+	client.stateMutex.Unlock()
+
+	conn, err := dial(client.address, cla.GetManagerSingleton().SourceAddress(client.address))
+
+	timeouts := currentTimeouts()
+	var writer deadlineWriter
+	var out streamWriter
+	if err == nil {
+		writer = deadlineWriter{conn: conn, timeout: timeouts.WriteTimeout}
+		if out, err = negotiateClientCompression(conn, writer); err != nil {
+			_ = conn.Close()
+		}
 	}
 
-	client.stopSyn = make(chan struct{})
+	client.stateMutex.Lock()
+	defer client.stateMutex.Unlock()
+
+	if err != nil {
+		client.state = clientClosed
+		return err
+	}
+
+	// Close was called while dialing
+	if client.state == clientClosed {
+		_ = conn.Close()
+		return fmt.Errorf("MTCPClient was closed during its activation")
+	}
 
 	client.conn = conn
+	client.timeouts = timeouts
+	client.writer = writer
+	client.out = out
+	client.stopSyn = make(chan struct{})
+	client.state = clientActive
 
-	go client.handler()
-	return
+	go client.handler(client.stopSyn)
+	return nil
 }
 
-func (client *MTCPClient) handler() {
-	var ticker = time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+func (client *MTCPClient) handler(stopSyn <-chan struct{}) {
+	// keepalives stays nil if they are disabled, thus never being selected
+	var keepalives <-chan time.Time
+	if client.timeouts.KeepaliveInterval > 0 {
+		ticker := clock.NewTicker(client.timeouts.KeepaliveInterval)
+		defer ticker.Stop()
+		keepalives = ticker.C()
+	}
 
 	// Introduce ourselves once
 	cla.GetManagerSingleton().NotifyConnect(client.peer)
 
 	for {
 		select {
-		case <-client.stopSyn:
+		case <-stopSyn:
+			if client.out.compressor != nil {
+				client.mutex.Lock()
+				_ = client.out.compressor.Close()
+				client.mutex.Unlock()
+			}
 			_ = client.conn.Close()
 			return
 
-		case <-ticker.C:
+		case <-keepalives:
 			client.mutex.Lock()
-			err := cboring.WriteByteStringLen(0, client.conn)
+			err := cboring.WriteByteStringLen(0, client.out)
+			if err == nil {
+				err = client.out.Flush()
+			}
 			client.mutex.Unlock()
 
-			if err != nil {
+			if isTimeout(err) {
+				log.WithFields(log.Fields{
+					"client":  client.String(),
+					"timeout": client.timeouts.WriteTimeout,
+				}).Warn("MTCPClient: Keepalive timed out, peer seems dead")
+
+				client.Close()
+			} else if err != nil {
 				log.WithFields(log.Fields{
 					"client": client.String(),
 					"error":  err,
@@ -101,15 +195,19 @@ func (client *MTCPClient) handler() {
 }
 
 func (client *MTCPClient) Send(bndl bpv7.Bundle) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("MTCPClient.Send: %v", r)
-		}
-	}()
+	if state := client.getState(); state != clientActive {
+		return fmt.Errorf("MTCPClient cannot send in state %v", state)
+	}
 
 	defer func() {
+		if isTimeout(err) {
+			log.WithFields(log.Fields{
+				"client":  client.String(),
+				"timeout": client.timeouts.WriteTimeout,
+			}).Warn("MTCPClient: Sending timed out, peer seems dead")
+		}
 		if err != nil {
-			client.Close()
+			_ = client.Close()
 		}
 	}()
 
@@ -118,7 +216,7 @@ func (client *MTCPClient) Send(bndl bpv7.Bundle) (err error) {
 
 	log.WithField("bundle", bndl.ID().String()).Debug("mtcp sending bundle")
 
-	connWriter := bufio.NewWriter(client.conn)
+	connWriter := bufio.NewWriter(client.out)
 
 	buff := new(bytes.Buffer)
 	if cborErr := cboring.Marshal(&bndl, buff); cborErr != nil {
@@ -136,33 +234,50 @@ func (client *MTCPClient) Send(bndl bpv7.Bundle) (err error) {
 		return
 	}
 
+	// A lost connection is detected by the write deadline and the dialer's TCP keepalive options, failing this or
+	// a subsequent write. Thus, no additional probe needs to be written.
 	if flushErr := connWriter.Flush(); flushErr != nil {
 		err = flushErr
 		return
 	}
-
-	// Check if the connection is still alive with an empty, unbuffered packet
-	if probeErr := cboring.WriteByteStringLen(0, client.conn); probeErr != nil {
-		err = probeErr
+	if flushErr := client.out.Flush(); flushErr != nil {
+		err = flushErr
 		return
 	}
 
 	return
 }
 
-func (client *MTCPClient) Close() error {
-	closed := client.stopped.Swap(true)
-	if closed {
-		return nil
-	}
+// getState returns the current lifecycle state.
+func (client *MTCPClient) getState() clientState {
+	client.stateMutex.Lock()
+	defer client.stateMutex.Unlock()
 
-	cla.GetManagerSingleton().NotifyDisconnect(client)
+	return client.state
+}
 
-	close(client.stopSyn)
+// Close the connection. Only the closing of an active MTCPClient is notified to the cla.Manager.
+// Calling Close multiple times, concurrently, or before Activate is safe.
+func (client *MTCPClient) Close() error {
+	client.stateMutex.Lock()
+	wasActive := client.state == clientActive
+	if wasActive {
+		close(client.stopSyn)
+	}
+	client.state = clientClosed
+	client.stateMutex.Unlock()
 
+	if wasActive {
+		cla.GetManagerSingleton().NotifyDisconnect(client)
+	}
 	return nil
 }
 
+// Redial creates a new MTCPClient to the same server, allowing the cla.Manager to suspend idle clients.
+func (client *MTCPClient) Redial() cla.ConvergenceSender {
+	return NewMTCPClient(client.address, client.peer)
+}
+
 func (client *MTCPClient) GetPeerEndpointID() bpv7.EndpointID {
 	return client.peer
 }
@@ -180,5 +295,5 @@ func (client *MTCPClient) String() string {
 }
 
 func (client *MTCPClient) Active() bool {
-	return true
+	return client.getState() == clientActive
 }
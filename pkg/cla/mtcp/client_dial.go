@@ -16,11 +16,14 @@ import (
 // file additionally sets specific socket options for a better detection of
 // connection losses.
 
-// dial a new TCP connection with a configured timeout and keepalive.
-func dial(address string) (net.Conn, error) {
+// dial a new TCP connection with a configured timeout and keepalive, bound to the source address unless it is nil.
+func dial(address string, source net.IP) (net.Conn, error) {
 	dialer := &net.Dialer{
 		Timeout:   time.Second,
 		KeepAlive: 5 * time.Second,
 	}
+	if source != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: source}
+	}
 	return dialer.Dial("tcp", address)
 }
@@ -63,11 +63,14 @@ func dialControl(_, _ string, rawConn syscall.RawConn) (err error) {
 	return
 }
 
-// dial a new TCP connection with socket options set.
-func dial(address string) (net.Conn, error) {
+// dial a new TCP connection with socket options set, bound to the source address unless it is nil.
+func dial(address string, source net.IP) (net.Conn, error) {
 	dialer := &net.Dialer{
 		Timeout: time.Second,
 		Control: dialControl,
 	}
+	if source != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: source}
+	}
 	return dialer.Dial("tcp", address)
 }
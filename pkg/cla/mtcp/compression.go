@@ -0,0 +1,177 @@
+package mtcp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/dtn7/cboring"
+)
+
+// Compression is an optional extension of MTCP, compressing the whole stream of a connection with zstd.
+//
+// As MTCP is unidirectional, the negotiation uses the otherwise unused direction. An MTCPServer with enabled
+// compression offers it by sending compressionMagic as a CBOR byte string right after accepting a connection. MTCP
+// clients of other implementations never read it. An MTCPClient with enabled compression waits up to the
+// compressionNegotiationTimeout for this offer. If it was received, the client accepts it by sending compressionMagic
+// as its first byte string, followed by the zstd compressed stream of byte strings. Otherwise, the connection is left
+// uncompressed. Each bundle and keepalive is flushed as a zstd block on its own.
+
+// compressionMagic is both the offer and the acceptance of zstd compression.
+var compressionMagic = []byte("dtn7-mtcp-zstd")
+
+// compressionNegotiationTimeout limits an MTCPClient's wait for the server's compression offer.
+const compressionNegotiationTimeout = 2 * time.Second
+
+var compressionEnabled atomic.Bool
+
+// SetCompression enables or disables the compression for all subsequently activated MTCPClients and accepted
+// connections. Both peers must enable it for a connection to be compressed.
+func SetCompression(enabled bool) {
+	compressionEnabled.Store(enabled)
+}
+
+// CompressionStats sum up the compressed connections of one direction.
+type CompressionStats struct {
+	Sessions          uint64
+	UncompressedBytes uint64
+	CompressedBytes   uint64
+}
+
+// Ratio of uncompressed to compressed bytes, zero if nothing was transferred.
+func (stats CompressionStats) Ratio() float64 {
+	if stats.CompressedBytes == 0 {
+		return 0
+	}
+	return float64(stats.UncompressedBytes) / float64(stats.CompressedBytes)
+}
+
+// compressionCounters are the atomic counterpart of CompressionStats.
+type compressionCounters struct {
+	sessions     atomic.Uint64
+	uncompressed atomic.Uint64
+	compressed   atomic.Uint64
+}
+
+func (counters *compressionCounters) get() CompressionStats {
+	return CompressionStats{
+		Sessions:          counters.sessions.Load(),
+		UncompressedBytes: counters.uncompressed.Load(),
+		CompressedBytes:   counters.compressed.Load(),
+	}
+}
+
+var sentCompression, receivedCompression compressionCounters
+
+// GetCompressionStats returns the statistics of all compressed connections of MTCPClients and MTCPServers.
+// This method is thread-safe.
+func GetCompressionStats() (sent, received CompressionStats) {
+	return sentCompression.get(), receivedCompression.get()
+}
+
+// countingWriter adds the number of written bytes to a counter.
+type countingWriter struct {
+	w       io.Writer
+	counter *atomic.Uint64
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.counter.Add(uint64(n))
+	return n, err
+}
+
+// countingReader adds the number of read bytes to a counter.
+type countingReader struct {
+	r       io.Reader
+	counter *atomic.Uint64
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.counter.Add(uint64(n))
+	return n, err
+}
+
+// streamWriter is an MTCPClient's writer for byte strings, either plain or compressed.
+type streamWriter struct {
+	w          io.Writer
+	compressor *zstd.Encoder
+}
+
+// Write to the stream, which must be followed by a Flush.
+func (sw streamWriter) Write(p []byte) (int, error) {
+	return sw.w.Write(p)
+}
+
+// Flush ends a compressed block, such that everything written so far is transmitted.
+func (sw streamWriter) Flush() error {
+	if sw.compressor == nil {
+		return nil
+	}
+	return sw.compressor.Flush()
+}
+
+// negotiateClientCompression waits for the server's offer and accepts it, returning the connection's streamWriter.
+func negotiateClientCompression(conn net.Conn, writer io.Writer) (streamWriter, error) {
+	plain := streamWriter{w: writer}
+	if !compressionEnabled.Load() || !readCompressionOffer(conn) {
+		return plain, nil
+	}
+
+	if err := cboring.WriteByteString(compressionMagic, writer); err != nil {
+		return plain, err
+	}
+
+	compressor, err := zstd.NewWriter(countingWriter{w: writer, counter: &sentCompression.compressed},
+		zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return plain, err
+	}
+
+	sentCompression.sessions.Add(1)
+	return streamWriter{
+		w:          countingWriter{w: compressor, counter: &sentCompression.uncompressed},
+		compressor: compressor,
+	}, nil
+}
+
+// readCompressionOffer checks for the server's compression offer within the compressionNegotiationTimeout.
+func readCompressionOffer(conn net.Conn) bool {
+	if err := conn.SetReadDeadline(time.Now().Add(compressionNegotiationTimeout)); err != nil {
+		return false
+	}
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+
+	// The server sends nothing else, thus nothing beyond the offer is buffered.
+	offer, err := cboring.ReadByteString(bufio.NewReader(io.LimitReader(conn, int64(len(compressionMagic)+1))))
+	return err == nil && bytes.Equal(offer, compressionMagic)
+}
+
+// offerServerCompression sends the compression offer to a client, if compression is enabled.
+func offerServerCompression(writer io.Writer) (offered bool, err error) {
+	if !compressionEnabled.Load() {
+		return false, nil
+	}
+	if err = cboring.WriteByteString(compressionMagic, writer); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// newDecompressingReader reads the remaining compressed stream of a client, which accepted the compression.
+func newDecompressingReader(r io.Reader) (*bufio.Reader, *zstd.Decoder, error) {
+	decompressor, err := zstd.NewReader(countingReader{r: r, counter: &receivedCompression.compressed},
+		zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	receivedCompression.sessions.Add(1)
+	return bufio.NewReader(countingReader{r: decompressor, counter: &receivedCompression.uncompressed}), decompressor, nil
+}
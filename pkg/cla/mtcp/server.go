@@ -6,9 +6,11 @@ package mtcp
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"net"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -16,6 +18,7 @@ import (
 	"github.com/dtn7/cboring"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
 )
 
 // MTCPServer is an implementation of a Minimal TCP Convergence-Layer server
@@ -30,6 +33,8 @@ type MTCPServer struct {
 
 	stopSyn chan struct{}
 	stopAck chan struct{}
+	// stopOnce allows closing the server both as a ConvergenceReceiver and as a ConvergenceListener
+	stopOnce sync.Once
 }
 
 // NewMTCPServer creates a new MTCPServer for the given listen address. The
@@ -73,10 +78,23 @@ func (serv *MTCPServer) Start() error {
 						"cla":   serv,
 						"error": err,
 					}).Error("MTCPServer failed to set deadline on TCP socket")
+					cla.GetManagerSingleton().NotifyListenerError(serv.listenAddress, err)
 
 					_ = serv.Close()
 				} else if conn, err := ln.Accept(); err == nil {
-					go serv.handleSender(conn)
+					release, backoff, ok := cla.GetManagerSingleton().AdmitConnection(serv.Address(), conn.RemoteAddr())
+					if !ok {
+						_ = conn.Close()
+
+						// Pause accepting while the listener is full, but still react to closing.
+						select {
+						case <-serv.stopSyn:
+						case <-time.After(backoff):
+						}
+						continue
+					}
+					cla.GetManagerSingleton().LearnSourceAddress(conn.RemoteAddr(), conn.LocalAddr())
+					go serv.handleSender(conn, release)
 				}
 			}
 		}
@@ -87,9 +105,10 @@ func (serv *MTCPServer) Start() error {
 	return nil
 }
 
-func (serv *MTCPServer) handleSender(conn net.Conn) {
+func (serv *MTCPServer) handleSender(conn net.Conn, release func()) {
 	defer func() {
 		_ = conn.Close()
+		release()
 
 		if r := recover(); r != nil {
 			log.WithFields(log.Fields{
@@ -105,10 +124,32 @@ func (serv *MTCPServer) handleSender(conn net.Conn) {
 		"conn": conn,
 	}).Debug("MTCP handleServer connection was established")
 
-	connReader := bufio.NewReader(conn)
+	timeouts := currentTimeouts()
+	idleTimeout := timeouts.IdleTimeout
+	connReader := bufio.NewReader(deadlineReader{conn: conn, timeout: idleTimeout})
+
+	// awaitAcceptance checks the first byte string for the acceptance of offered compression, see compression.go
+	awaitAcceptance, err := offerServerCompression(deadlineWriter{conn: conn, timeout: timeouts.WriteTimeout})
+	if err != nil {
+		// Probes of the peer liveness close their connection right away, thus this is no reason for concern.
+		log.WithFields(log.Fields{
+			"cla":   serv,
+			"conn":  conn,
+			"error": err,
+		}).Debug("MTCP handleServer connection failed to offer compression")
+		return
+	}
+
 	for {
-		if n, err := cboring.ReadByteStringLen(connReader); err != nil {
-			if err != io.EOF {
+		n, err := cboring.ReadByteStringLen(connReader)
+		if err != nil {
+			if isTimeout(err) {
+				log.WithFields(log.Fields{
+					"cla":     serv,
+					"conn":    conn,
+					"timeout": idleTimeout,
+				}).Info("MTCP handleServer connection was idle for too long, peer seems dead")
+			} else if err != io.EOF {
 				log.WithFields(log.Fields{
 					"cla":   serv,
 					"conn":  conn,
@@ -118,15 +159,50 @@ func (serv *MTCPServer) handleSender(conn net.Conn) {
 
 			// There is no use in sending an PeerDisappeared Message at this point,
 			// because a MTCPServer might hold multiple clients. Furthermore, there
-			// is no linkage between unknown connections and Endpoint IDs.
+			// is no linkage between unknown connections and Endpoint IDs. Closing
+			// the connection lets the client's next write fail, which then results
+			// in its NotifyDisconnect.
 
 			return
 		} else if n == 0 {
 			continue
 		}
 
+		var bundleReader io.Reader = connReader
+		if awaitAcceptance {
+			awaitAcceptance = false
+
+			if n == uint64(len(compressionMagic)) {
+				first := make([]byte, n)
+				if _, err := io.ReadFull(connReader, first); err != nil {
+					return
+				}
+
+				if bytes.Equal(first, compressionMagic) {
+					decompressingReader, decompressor, err := newDecompressingReader(connReader)
+					if err != nil {
+						log.WithFields(log.Fields{
+							"cla":   serv,
+							"conn":  conn,
+							"error": err,
+						}).Error("MTCP handleServer connection failed to decompress")
+						return
+					}
+					defer decompressor.Close()
+
+					log.WithFields(log.Fields{
+						"cla":  serv,
+						"conn": conn,
+					}).Debug("MTCP handleServer connection is compressed")
+					connReader = decompressingReader
+					continue
+				}
+				bundleReader = bytes.NewReader(first)
+			}
+		}
+
 		bndl := new(bpv7.Bundle)
-		if err := cboring.Unmarshal(bndl, connReader); err != nil {
+		if err := cboring.Unmarshal(bndl, bundleReader); err != nil {
 			log.WithFields(log.Fields{
 				"cla":   serv,
 				"conn":  conn,
@@ -146,7 +222,7 @@ func (serv *MTCPServer) handleSender(conn net.Conn) {
 }
 
 func (serv *MTCPServer) Close() error {
-	close(serv.stopSyn)
+	serv.stopOnce.Do(func() { close(serv.stopSyn) })
 	<-serv.stopAck
 
 	return nil
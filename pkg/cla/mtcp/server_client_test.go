@@ -6,13 +6,18 @@
 package mtcp
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"testing"
+	"time"
 
 	"pgregory.net/rapid"
 
+	"github.com/dtn7/cboring"
+
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
 )
@@ -35,10 +40,8 @@ func getRandomPort(t *rapid.T) int {
 
 func setup(t *rapid.T) {
 	receive := func(bundle *bpv7.Bundle) {}
-	connect := func(eid bpv7.EndpointID) {}
-	disconnect := func(eid bpv7.EndpointID) {}
 
-	err := cla.InitialiseCLAManager(receive, connect, disconnect)
+	err := cla.InitialiseCLAManager(receive)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -113,3 +116,271 @@ func TestSendReceive(t *testing.T) {
 		}
 	})
 }
+
+func TestTimeoutsCheckValid(t *testing.T) {
+	tests := []struct {
+		timeouts Timeouts
+		valid    bool
+	}{
+		{DefaultTimeouts(), true},
+		{Timeouts{KeepaliveInterval: time.Second}, true},
+		{Timeouts{KeepaliveInterval: -time.Second}, false},
+		{Timeouts{KeepaliveInterval: 0, IdleTimeout: time.Second}, true},
+		{Timeouts{KeepaliveInterval: time.Second, WriteTimeout: -time.Second}, false},
+		{Timeouts{KeepaliveInterval: time.Second, IdleTimeout: time.Second}, false},
+		{Timeouts{KeepaliveInterval: time.Second, IdleTimeout: 3 * time.Second}, true},
+	}
+
+	for _, test := range tests {
+		if err := test.timeouts.CheckValid(); (err == nil) != test.valid {
+			t.Fatalf("Timeouts %v resulted in error: %v", test.timeouts, err)
+		}
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	if err := SetTimeouts(Timeouts{KeepaliveInterval: 50 * time.Millisecond, IdleTimeout: 200 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetTimeouts(DefaultTimeouts()) }()
+
+	serv := NewMTCPServer("localhost:0", bpv7.MustNewEndpointID("dtn://mtcpcla/"), func(*bpv7.Bundle) {})
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			serv.handleSender(conn, func() {})
+		}
+	}()
+
+	// A silent peer must be disconnected after the idle timeout
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Idle connection was not closed by the server, but resulted in %v", err)
+	}
+}
+
+func TestClientCloseBeforeActivate(t *testing.T) {
+	client := NewAnonymousMTCPClient("localhost:1")
+
+	for i := 0; i < 2; i++ {
+		if err := client.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := client.Activate(); err == nil {
+		t.Fatal("Closed client was activated")
+	}
+	if err := client.Send(bpv7.MustNewBundle(bpv7.PrimaryBlock{}, nil)); err == nil {
+		t.Fatal("Closed client sent a bundle")
+	}
+	if client.Active() {
+		t.Fatal("Closed client is active")
+	}
+}
+
+func TestClientConcurrentSendClose(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		setup(t)
+		defer teardown()
+
+		port := getRandomPort(t)
+		serv := NewMTCPServer(fmt.Sprintf(":%d", port), bpv7.MustNewEndpointID("dtn://mtcpcla/"), func(*bpv7.Bundle) {})
+		if err := serv.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = serv.Close() }()
+
+		client := NewAnonymousMTCPClient(fmt.Sprintf("localhost:%d", port))
+		if err := client.Activate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Activate(); err == nil {
+			t.Fatal("Active client was activated twice")
+		}
+
+		numberOfSenders := rapid.IntRange(1, 20).Draw(t, "Number of Senders")
+		numberOfClosers := rapid.IntRange(1, 5).Draw(t, "Number of Closers")
+
+		var wg sync.WaitGroup
+		wg.Add(numberOfSenders + numberOfClosers)
+		for i := 0; i < numberOfSenders; i++ {
+			bundle := bpv7.GenerateBundle(t, i)
+			go func() {
+				defer wg.Done()
+				// Sending might fail due to a concurrent Close, but must never panic
+				_ = client.Send(bundle)
+			}()
+		}
+		for i := 0; i < numberOfClosers; i++ {
+			go func() {
+				defer wg.Done()
+				if err := client.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if client.Active() {
+			t.Fatal("Client is still active after Close")
+		}
+		if err := client.Send(bpv7.GenerateBundle(t, numberOfSenders)); err == nil {
+			t.Fatal("Closed client sent a bundle")
+		}
+	})
+}
+
+func TestClientSendsNoProbes(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		setup(t)
+		defer teardown()
+
+		if err := SetTimeouts(Timeouts{WriteTimeout: time.Second}); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = SetTimeouts(DefaultTimeouts()) }()
+
+		ln, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = ln.Close() }()
+
+		received := make(chan []byte)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				close(received)
+				return
+			}
+			defer func() { _ = conn.Close() }()
+
+			data, _ := io.ReadAll(conn)
+			received <- data
+		}()
+
+		client := NewAnonymousMTCPClient(ln.Addr().String())
+		if err := client.Activate(); err != nil {
+			t.Fatal(err)
+		}
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		if err := client.Send(bundle); err != nil {
+			t.Fatal(err)
+		}
+		_ = client.Close()
+
+		// Only the bundle as a CBOR byte string is expected, without any trailing empty byte string
+		bundleBuff := new(bytes.Buffer)
+		if err := cboring.Marshal(&bundle, bundleBuff); err != nil {
+			t.Fatal(err)
+		}
+		expected := new(bytes.Buffer)
+		if err := cboring.WriteByteString(bundleBuff.Bytes(), expected); err != nil {
+			t.Fatal(err)
+		}
+
+		if data := <-received; !bytes.Equal(data, expected.Bytes()) {
+			t.Fatalf("Received %d bytes instead of the expected %d bytes", len(data), expected.Len())
+		}
+	})
+}
+
+func TestCompression(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		setup(t)
+		defer teardown()
+
+		SetCompression(true)
+		defer SetCompression(false)
+
+		received := make(chan *bpv7.Bundle, 10)
+		port := getRandomPort(t)
+		serv := NewMTCPServer(fmt.Sprintf("localhost:%d", port), bpv7.MustNewEndpointID("dtn://mtcpcla/"), func(bundle *bpv7.Bundle) {
+			received <- bundle
+		})
+		if err := serv.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = serv.Close() }()
+
+		sentBefore, receivedBefore := GetCompressionStats()
+
+		// A compressing client, sending a well compressible bundle
+		client := NewAnonymousMTCPClient(fmt.Sprintf("localhost:%d", port))
+		if err := client.Activate(); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = client.Close() }()
+
+		payload := bytes.Repeat([]byte(rapid.StringN(1, 16, -1).Draw(t, "payload")), 1024)
+		bundle, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination("dtn://dst/").
+			CreationTimestampNow().
+			Lifetime("5m").
+			PayloadBlock(payload).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Send(bundle); err != nil {
+			t.Fatal(err)
+		}
+
+		// A client of another implementation, ignoring the offer
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+
+		bundleBuff := new(bytes.Buffer)
+		if err := cboring.Marshal(&bundle, bundleBuff); err != nil {
+			t.Fatal(err)
+		}
+		if err := cboring.WriteByteString(bundleBuff.Bytes(), conn); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			select {
+			case bndl := <-received:
+				if bndl.ID() != bundle.ID() {
+					t.Fatalf("Received %v instead of %v", bndl.ID(), bundle.ID())
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Bundle %d was not received", i)
+			}
+		}
+
+		sentAfter, receivedAfter := GetCompressionStats()
+		if sessions := sentAfter.Sessions - sentBefore.Sessions; sessions != 1 {
+			t.Fatalf("Sent %d compressed sessions instead of one", sessions)
+		}
+		if sessions := receivedAfter.Sessions - receivedBefore.Sessions; sessions != 1 {
+			t.Fatalf("Received %d compressed sessions instead of one", sessions)
+		}
+
+		uncompressed := sentAfter.UncompressedBytes - sentBefore.UncompressedBytes
+		compressed := sentAfter.CompressedBytes - sentBefore.CompressedBytes
+		if uncompressed <= uint64(len(payload)) || compressed >= uncompressed {
+			t.Fatalf("Compressed %d bytes to %d bytes", uncompressed, compressed)
+		}
+		if received := receivedAfter.UncompressedBytes - receivedBefore.UncompressedBytes; received != uncompressed {
+			t.Fatalf("Received %d uncompressed bytes, but sent %d", received, uncompressed)
+		}
+	})
+}
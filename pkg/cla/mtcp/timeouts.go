@@ -0,0 +1,130 @@
+package mtcp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Timeouts configure the keepalives and the dead peer detection of all MTCPClients and MTCPServers.
+//
+// As MTCP is unidirectional, a client detects a dead peer by writes not progressing for the WriteTimeout. A server
+// detects a dead peer by receiving nothing, not even a keepalive, for the IdleTimeout. Both timeouts are reset by
+// each transferred chunk of data, such that large bundles over slow links are not affected.
+type Timeouts struct {
+	// KeepaliveInterval between two empty keepalive messages sent by an MTCPClient. Zero disables them, e.g., for
+	// MTCP receivers of other implementations not expecting empty messages.
+	KeepaliveInterval time.Duration
+	// WriteTimeout is the maximum duration of an MTCPClient's write without progress. Zero disables it.
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum duration of an MTCPServer's connection without any received data. Zero disables it.
+	IdleTimeout time.Duration
+}
+
+// DefaultTimeouts are used unless SetTimeouts was called.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		KeepaliveInterval: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       15 * time.Second,
+	}
+}
+
+// CheckValid checks for negative durations and if keepalives arrive before an idle timeout.
+func (timeouts Timeouts) CheckValid() error {
+	if timeouts.KeepaliveInterval < 0 {
+		return fmt.Errorf("keepalive interval must not be negative, not %v", timeouts.KeepaliveInterval)
+	}
+	if timeouts.WriteTimeout < 0 {
+		return fmt.Errorf("write timeout must not be negative, not %v", timeouts.WriteTimeout)
+	}
+	if timeouts.IdleTimeout < 0 {
+		return fmt.Errorf("idle timeout must not be negative, not %v", timeouts.IdleTimeout)
+	}
+	if timeouts.IdleTimeout != 0 && timeouts.KeepaliveInterval != 0 && timeouts.IdleTimeout <= timeouts.KeepaliveInterval {
+		return fmt.Errorf("idle timeout %v must exceed the keepalive interval %v",
+			timeouts.IdleTimeout, timeouts.KeepaliveInterval)
+	}
+	return nil
+}
+
+var (
+	timeouts      = DefaultTimeouts()
+	timeoutsMutex sync.RWMutex
+)
+
+// SetTimeouts configures the Timeouts for all subsequently activated MTCPClients and accepted connections.
+func SetTimeouts(newTimeouts Timeouts) error {
+	if err := newTimeouts.CheckValid(); err != nil {
+		return err
+	}
+
+	timeoutsMutex.Lock()
+	defer timeoutsMutex.Unlock()
+
+	timeouts = newTimeouts
+	return nil
+}
+
+func currentTimeouts() Timeouts {
+	timeoutsMutex.RLock()
+	defer timeoutsMutex.RUnlock()
+
+	return timeouts
+}
+
+// deadlineChunkSize limits a single write, such that the write deadline is renewed during large transfers.
+const deadlineChunkSize = 64 * 1024
+
+// deadlineWriter renews the connection's write deadline for each chunk written, if a timeout is set.
+type deadlineWriter struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (w deadlineWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > deadlineChunkSize {
+			chunk = chunk[:deadlineChunkSize]
+		}
+
+		if w.timeout > 0 {
+			if err = w.conn.SetWriteDeadline(time.Now().Add(w.timeout)); err != nil {
+				return
+			}
+		}
+
+		var m int
+		m, err = w.conn.Write(chunk)
+		n += m
+		if err != nil {
+			return
+		}
+		p = p[m:]
+	}
+	return
+}
+
+// deadlineReader renews the connection's read deadline for each read, if a timeout is set.
+type deadlineReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (r deadlineReader) Read(p []byte) (int, error) {
+	if r.timeout > 0 {
+		if err := r.conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return r.conn.Read(p)
+}
+
+// isTimeout checks if an error was caused by an exceeded deadline.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,169 @@
+package cla
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// MTUConfig configures learning the effective MTU of each CLA, see SetMTULearning.
+type MTUConfig struct {
+	// Failures of transmissions above a size, after which the CLA's MTU is lowered below this size.
+	Failures int
+	// Minimum of a learned MTU in bytes. Bundles are never fragmented below it.
+	Minimum int
+}
+
+// CheckValid checks for a positive number of failures and a minimum which leaves room for a fragment's payload.
+func (config MTUConfig) CheckValid() error {
+	if config.Failures <= 0 {
+		return fmt.Errorf("MTU learning's failures must be positive")
+	}
+	if config.Minimum < 256 {
+		return fmt.Errorf("MTU learning's minimum of %d bytes is below 256 bytes", config.Minimum)
+	}
+	return nil
+}
+
+// mtuEstimate is the knowledge about a CLA's effective MTU, learned from its transmissions.
+type mtuEstimate struct {
+	// mtu is the learned MTU, zero while unknown.
+	mtu int
+	// largestSent is the size of the largest successfully sent bundle.
+	largestSent int
+	// failures above largestSent since the last success of at least failedSize, failedSize being the smallest.
+	failures   int
+	failedSize int
+}
+
+// observe a transmission's outcome and return a newly learned MTU, or zero if the MTU did not change.
+//
+// Only failures of bundles larger than any bundle sent before count, as smaller bundles prove that the CLA works in
+// general. After enough of those failures, the MTU is set to the largest sent bundle or to half of the smallest failed
+// bundle, whichever is larger. If fragments of this size fail again, the MTU is halved further.
+func (estimate *mtuEstimate) observe(config MTUConfig, size int, err error) (learned int) {
+	if err == nil {
+		if size > estimate.largestSent {
+			estimate.largestSent = size
+		}
+		if estimate.failedSize > 0 && size >= estimate.failedSize {
+			estimate.failures, estimate.failedSize = 0, 0
+		}
+		// An unfragmentable bundle above the MTU got through, which might be outdated.
+		if estimate.mtu > 0 && size > estimate.mtu {
+			estimate.mtu = 0
+		}
+		return 0
+	}
+
+	if size <= estimate.largestSent {
+		return 0
+	}
+	estimate.failures++
+	if estimate.failedSize == 0 || size < estimate.failedSize {
+		estimate.failedSize = size
+	}
+	if estimate.failures < config.Failures {
+		return 0
+	}
+
+	mtu := estimate.failedSize / 2
+	if mtu < estimate.largestSent {
+		mtu = estimate.largestSent
+	}
+	if mtu < config.Minimum {
+		mtu = config.Minimum
+	}
+	estimate.failures, estimate.failedSize = 0, 0
+
+	if mtu >= size || (estimate.mtu > 0 && mtu >= estimate.mtu) {
+		return 0
+	}
+	estimate.mtu = mtu
+	return mtu
+}
+
+// SetMTULearning enables learning an effective MTU for each CLA, e.g., LoRa or UDP links which fail to transmit large
+// bundles, identified by its address. If transmissions of bundles above a certain size fail repeatedly, larger bundles
+// are fragmented proactively below it rather than failing every large transfer forever. Bundles which must not be
+// fragmented are still sent as a whole. A nil config disables MTU learning and forgets all learned MTUs.
+// This method is thread-safe.
+func (manager *Manager) SetMTULearning(config *MTUConfig) {
+	manager.mtuMutex.Lock()
+	defer manager.mtuMutex.Unlock()
+
+	manager.mtuConfig = config
+	manager.mtus = make(map[string]*mtuEstimate)
+}
+
+// LearnedMTU returns the learned MTU of the CLA with this address, if any.
+// This method is thread-safe.
+func (manager *Manager) LearnedMTU(address string) (mtu int, ok bool) {
+	manager.mtuMutex.Lock()
+	defer manager.mtuMutex.Unlock()
+
+	if estimate, known := manager.mtus[address]; known && estimate.mtu > 0 {
+		return estimate.mtu, true
+	}
+	return 0, false
+}
+
+// mtuLearning checks if MTU learning is enabled, see SetMTULearning.
+func (manager *Manager) mtuLearning() bool {
+	manager.mtuMutex.Lock()
+	defer manager.mtuMutex.Unlock()
+
+	return manager.mtuConfig != nil
+}
+
+// observeMTU passes a transmission's outcome to the MTU learning of its sender's address. Expired bundles say nothing
+// about the MTU and are ignored.
+func (manager *Manager) observeMTU(sender ConvergenceSender, size int, err error) {
+	if errors.Is(err, ErrBundleExpired) {
+		return
+	}
+
+	manager.mtuMutex.Lock()
+	defer manager.mtuMutex.Unlock()
+
+	if manager.mtuConfig == nil {
+		return
+	}
+
+	estimate, ok := manager.mtus[sender.Address()]
+	if !ok {
+		estimate = &mtuEstimate{}
+		manager.mtus[sender.Address()] = estimate
+	}
+	if mtu := estimate.observe(*manager.mtuConfig, size, err); mtu > 0 {
+		log.WithFields(log.Fields{
+			"cla":  sender,
+			"peer": sender.GetPeerEndpointID(),
+			"mtu":  mtu,
+		}).Info("Learned a CLA's MTU, larger bundles will be fragmented")
+	}
+}
+
+// fragmentForMTU splits a bundle into fragments fitting the learned MTU of the sender. It returns false if the bundle
+// should be sent as a whole, e.g., as no MTU was learned or the bundle must not be fragmented.
+func (manager *Manager) fragmentForMTU(sender ConvergenceSender, bndl bpv7.Bundle, size int) ([]bpv7.Bundle, bool) {
+	mtu, ok := manager.LearnedMTU(sender.Address())
+	if !ok || size <= mtu || bndl.PrimaryBlock.BundleControlFlags.Has(bpv7.MustNotFragmented) {
+		return nil, false
+	}
+
+	fragments, err := bndl.Fragment(mtu)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bndl.ID(),
+			"cla":    sender,
+			"mtu":    mtu,
+			"error":  err,
+		}).Warn("Failed to fragment bundle for a CLA's MTU, sending it as a whole")
+		return nil, false
+	}
+	return fragments, true
+}
@@ -0,0 +1,117 @@
+package cla
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// mtuSender fails to send bundles above its limit.
+type mtuSender struct {
+	redialSender
+	limit int
+	sent  []bpv7.Bundle
+}
+
+func (s *mtuSender) Send(bndl bpv7.Bundle) error {
+	if size := int(BundleSize(bndl)); size > s.limit {
+		return fmt.Errorf("bundle of %d bytes exceeds %d bytes", size, s.limit)
+	}
+	s.sent = append(s.sent, bndl)
+	return nil
+}
+
+func TestMTUEstimate(t *testing.T) {
+	config := MTUConfig{Failures: 2, Minimum: 256}
+	estimate := &mtuEstimate{}
+	failure := fmt.Errorf("failure")
+
+	estimate.observe(config, 1000, nil)
+	if mtu := estimate.observe(config, 800, failure); mtu != 0 {
+		t.Fatalf("Failure below a sent size lowered the MTU to %d", mtu)
+	}
+
+	// A success in between proves that the failures were not caused by the size.
+	estimate.observe(config, 5000, failure)
+	estimate.observe(config, 6000, nil)
+	if mtu := estimate.observe(config, 7000, failure); mtu != 0 {
+		t.Fatalf("Failures interrupted by a success lowered the MTU to %d", mtu)
+	}
+
+	if mtu := estimate.observe(config, 8000, failure); mtu != 6000 {
+		t.Fatalf("Expected the largest sent size as MTU, got %d", mtu)
+	}
+	estimate.observe(config, 6000, failure)
+	if mtu := estimate.observe(config, 6000, failure); mtu != 0 {
+		t.Fatalf("Failures at the largest sent size lowered the MTU to %d", mtu)
+	}
+
+	estimate = &mtuEstimate{}
+	estimate.observe(config, 400, failure)
+	if mtu := estimate.observe(config, 400, failure); mtu != 256 {
+		t.Fatalf("Expected the minimum as MTU, got %d", mtu)
+	}
+	estimate.observe(config, 300, nil)
+	if estimate.mtu != 0 {
+		t.Fatalf("Success above the MTU kept MTU %d", estimate.mtu)
+	}
+}
+
+func TestMTULearning(t *testing.T) {
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+	manager.SetMTULearning(&MTUConfig{Failures: 2, Minimum: 256})
+
+	payload := bytes.Repeat([]byte("0123456789"), 400)
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock(payload).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &mtuSender{
+		redialSender: redialSender{manager: manager, address: "10.0.0.2:35037", peer: bpv7.MustNewEndpointID("dtn://peer/")},
+		limit:        1500,
+	}
+
+	// The MTU is halved twice, each after two failures.
+	for i := 0; i < 4; i++ {
+		if err := manager.Send(sender, bndl); err == nil {
+			t.Fatalf("Attempt %d succeeded", i)
+		} else if !errors.Is(err, ErrPeerUnreachable) {
+			t.Fatalf("Attempt %d failed without an unreachable peer: %v", i, err)
+		}
+	}
+	mtu, ok := manager.LearnedMTU(sender.Address())
+	if !ok || mtu > sender.limit {
+		t.Fatalf("Learned MTU %d, %t", mtu, ok)
+	}
+
+	if err := manager.Send(sender, bndl); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) < 3 {
+		t.Fatalf("Bundle was sent as %d fragments", len(sender.sent))
+	}
+
+	reassembled, err := bpv7.ReassembleFragments(sender.sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadBlock, _ := reassembled.PayloadBlock()
+	if !bytes.Equal(payloadBlock.Value.(*bpv7.PayloadBlock).Data(), payload) {
+		t.Fatal("Reassembled payload differs")
+	}
+}
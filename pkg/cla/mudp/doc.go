@@ -0,0 +1,20 @@
+/*
+Package mudp implements an experimental UDP multicast convergence layer.
+Note that this convergence layer is not part of the Bundle Protocol or its associated specifications.
+
+Why?
+Epidemic flooding on a wireless LAN sends the same bundle to each neighbour separately, although the medium is shared.
+With multicast, a single transmission reaches all listening neighbours on the segment, saving airtime.
+
+Protocol
+All nodes join the same multicast group. Each datagram carries exactly one CBOR serialised bundle. Thus, a bundle's
+serialisation is limited to MaxDatagramSize; larger bundles are rejected and must be sent by another CLA.
+
+There is neither a handshake nor an acknowledgement. As a multicast group has no single peer, a MulticastEndpoint's
+peer is a non-singleton endpoint identifying the group, e.g., "dtn://mudp-239.0.0.1-35040/~neighbours". Once sent,
+a bundle is marked as sent to this group and not transmitted again.
+
+Since the same bundle might be received multiple times, e.g., from several neighbours flooding it or from the own
+looped-back transmission, a receiver drops recently seen bundle IDs.
+*/
+package mudp
@@ -0,0 +1,230 @@
+package mudp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+const (
+	// MaxDatagramSize is the largest serialised bundle to be sent in a single UDP datagram.
+	MaxDatagramSize = 65507
+
+	// seenCacheSize is the number of recently received bundle IDs to be dropped as duplicates.
+	seenCacheSize = 4096
+)
+
+// MulticastEndpoint is a convergence layer sending bundles to and receiving bundles from a UDP multicast group.
+// This struct implements a ConvergenceListener, ConvergenceReceiver and ConvergenceSender.
+type MulticastEndpoint struct {
+	groupAddress string
+	endpointID   bpv7.EndpointID
+	groupID      bpv7.EndpointID
+
+	receiveCallback func(*bpv7.Bundle)
+	seen            *seenCache
+
+	stateMutex sync.Mutex
+	running    bool
+	closed     bool
+	group      *net.UDPAddr
+	listenConn *net.UDPConn
+	sendConn   *net.UDPConn
+}
+
+// NewMulticastEndpoint creates a new MulticastEndpoint for a multicast group address, e.g., "239.0.0.1:35040".
+func NewMulticastEndpoint(groupAddress string, endpointID bpv7.EndpointID, receiveCallback func(*bpv7.Bundle)) *MulticastEndpoint {
+	return &MulticastEndpoint{
+		groupAddress:    groupAddress,
+		endpointID:      endpointID,
+		groupID:         GroupEndpointID(groupAddress),
+		receiveCallback: receiveCallback,
+		seen:            newSeenCache(seenCacheSize),
+	}
+}
+
+// GroupEndpointID is the non-singleton endpoint identifying all nodes of a multicast group.
+func GroupEndpointID(groupAddress string) bpv7.EndpointID {
+	node := strings.NewReplacer(":", "-", "[", "", "]", "").Replace(groupAddress)
+	eid, err := bpv7.NewEndpointID(fmt.Sprintf("dtn://mudp-%s/~neighbours", node))
+	if err != nil {
+		return bpv7.DtnNone()
+	}
+	return eid
+}
+
+// Start joins the multicast group and starts receiving.
+func (endpoint *MulticastEndpoint) Start() error {
+	endpoint.stateMutex.Lock()
+	defer endpoint.stateMutex.Unlock()
+
+	if endpoint.running || endpoint.closed {
+		return fmt.Errorf("MulticastEndpoint %s was already started", endpoint.groupAddress)
+	}
+
+	group, err := net.ResolveUDPAddr("udp", endpoint.groupAddress)
+	if err != nil {
+		return err
+	}
+	if !group.IP.IsMulticast() {
+		return fmt.Errorf("%s is no multicast address", endpoint.groupAddress)
+	}
+
+	listenConn, err := net.ListenMulticastUDP("udp", nil, group)
+	if err != nil {
+		return err
+	}
+	if err := listenConn.SetReadBuffer(MaxDatagramSize); err != nil {
+		log.WithFields(log.Fields{
+			"cla":   endpoint,
+			"error": err,
+		}).Debug("MulticastEndpoint failed to set read buffer size")
+	}
+
+	sendConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		_ = listenConn.Close()
+		return err
+	}
+
+	endpoint.group = group
+	endpoint.listenConn = listenConn
+	endpoint.sendConn = sendConn
+	endpoint.running = true
+
+	go endpoint.handle(listenConn)
+
+	log.WithFields(log.Fields{
+		"cla":   endpoint,
+		"group": endpoint.groupID,
+	}).Info("MulticastEndpoint joined multicast group")
+	return nil
+}
+
+// handle receives datagrams until the connection is closed.
+func (endpoint *MulticastEndpoint) handle(conn *net.UDPConn) {
+	buff := make([]byte, MaxDatagramSize)
+	for {
+		n, from, err := conn.ReadFromUDP(buff)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.WithFields(log.Fields{
+					"cla":   endpoint,
+					"error": err,
+				}).Warn("MulticastEndpoint failed to receive, stopping")
+			}
+			return
+		}
+
+		bndl, err := bpv7.ParseBundle(bytes.NewReader(buff[:n]))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"cla":   endpoint,
+				"from":  from,
+				"error": err,
+			}).Warn("MulticastEndpoint received an invalid bundle")
+			continue
+		}
+
+		if !endpoint.seen.Add(bndl.ID().String()) {
+			log.WithFields(log.Fields{
+				"cla":    endpoint,
+				"from":   from,
+				"bundle": bndl.ID(),
+			}).Debug("MulticastEndpoint dropped a duplicate bundle")
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"cla":    endpoint,
+			"from":   from,
+			"bundle": bndl.ID(),
+		}).Debug("MulticastEndpoint received a bundle")
+		endpoint.receiveCallback(&bndl)
+	}
+}
+
+// Send a bundle to all nodes of the multicast group.
+// This method is thread-safe.
+func (endpoint *MulticastEndpoint) Send(bndl bpv7.Bundle) error {
+	buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&bndl, buff); err != nil {
+		return err
+	}
+	if buff.Len() > MaxDatagramSize {
+		return fmt.Errorf("bundle of %d bytes exceeds MulticastEndpoint's maximum of %d bytes", buff.Len(), MaxDatagramSize)
+	}
+
+	endpoint.stateMutex.Lock()
+	defer endpoint.stateMutex.Unlock()
+
+	if !endpoint.running {
+		return fmt.Errorf("MulticastEndpoint %s is not running", endpoint.groupAddress)
+	}
+
+	// The own transmission is looped back and must not be received again.
+	endpoint.seen.Add(bndl.ID().String())
+
+	_, err := endpoint.sendConn.WriteToUDP(buff.Bytes(), endpoint.group)
+	return err
+}
+
+// Close leaves the multicast group. Calling Close multiple times is safe.
+func (endpoint *MulticastEndpoint) Close() error {
+	endpoint.stateMutex.Lock()
+	defer endpoint.stateMutex.Unlock()
+
+	if endpoint.closed {
+		return nil
+	}
+	endpoint.closed = true
+
+	if !endpoint.running {
+		return nil
+	}
+	endpoint.running = false
+
+	return errors.Join(endpoint.listenConn.Close(), endpoint.sendConn.Close())
+}
+
+// Activate is a no-op, because the MulticastEndpoint is started as a ConvergenceListener.
+func (endpoint *MulticastEndpoint) Activate() error {
+	return nil
+}
+
+func (endpoint *MulticastEndpoint) Active() bool {
+	return endpoint.Running()
+}
+
+func (endpoint *MulticastEndpoint) Running() bool {
+	endpoint.stateMutex.Lock()
+	defer endpoint.stateMutex.Unlock()
+
+	return endpoint.running
+}
+
+func (endpoint *MulticastEndpoint) Address() string {
+	return fmt.Sprintf("mudp://%s", endpoint.groupAddress)
+}
+
+func (endpoint *MulticastEndpoint) GetEndpointID() bpv7.EndpointID {
+	return endpoint.endpointID
+}
+
+// GetPeerEndpointID returns the multicast group's endpoint, see GroupEndpointID.
+func (endpoint *MulticastEndpoint) GetPeerEndpointID() bpv7.EndpointID {
+	return endpoint.groupID
+}
+
+func (endpoint *MulticastEndpoint) String() string {
+	return endpoint.Address()
+}
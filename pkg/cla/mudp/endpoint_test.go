@@ -0,0 +1,127 @@
+package mudp
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestSeenCache(t *testing.T) {
+	cache := newSeenCache(4)
+	for i := 0; i < 4; i++ {
+		if !cache.Add(fmt.Sprintf("bundle-%d", i)) {
+			t.Fatalf("New bundle %d was already seen", i)
+		}
+	}
+	if cache.Add("bundle-0") {
+		t.Fatal("Seen bundle was not recognised")
+	}
+
+	// Adding a fifth bundle evicts the oldest one
+	cache.Add("bundle-4")
+	if !cache.Add("bundle-0") {
+		t.Fatal("Oldest bundle was not evicted")
+	}
+	if cache.Add("bundle-4") {
+		t.Fatal("Recent bundle was evicted")
+	}
+}
+
+func TestMulticastSendReceive(t *testing.T) {
+	const group = "239.0.0.1:35040"
+
+	var (
+		mutex    sync.Mutex
+		received []bpv7.BundleID
+	)
+	receiveCallback := func(bndl *bpv7.Bundle) {
+		mutex.Lock()
+		received = append(received, bndl.ID())
+		mutex.Unlock()
+	}
+
+	sender := NewMulticastEndpoint(group, bpv7.MustNewEndpointID("dtn://sender/"), func(*bpv7.Bundle) {
+		t.Error("Sender received its own bundle")
+	})
+	receiver := NewMulticastEndpoint(group, bpv7.MustNewEndpointID("dtn://receiver/"), receiveCallback)
+
+	if err := sender.Start(); err != nil {
+		t.Skipf("Multicast is not available: %v", err)
+	}
+	defer sender.Close()
+	if err := receiver.Start(); err != nil {
+		t.Skipf("Multicast is not available: %v", err)
+	}
+	defer receiver.Close()
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sending the same bundle twice must result in a single reception
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(bndl); err != nil {
+			t.Skipf("Multicast is not available: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mutex.Lock()
+		n := len(received)
+		mutex.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(received) == 0 {
+		t.Skip("No multicast datagram was received, multicast might not be routed")
+	}
+	if len(received) != 1 || received[0] != bndl.ID() {
+		t.Fatalf("Received %v instead of a single %v", received, bndl.ID())
+	}
+}
+
+func TestMulticastSendTooLarge(t *testing.T) {
+	endpoint := NewMulticastEndpoint("239.0.0.1:35041", bpv7.MustNewEndpointID("dtn://sender/"), func(*bpv7.Bundle) {})
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://sender/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		PayloadBlock(make([]byte, MaxDatagramSize)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := endpoint.Send(bndl); err == nil {
+		t.Fatal("Oversized bundle was sent")
+	}
+}
+
+func TestGroupEndpointID(t *testing.T) {
+	eid := GroupEndpointID("[ff02::1]:35040")
+	if eid.IsSingleton() {
+		t.Fatalf("Group endpoint %v is a singleton", eid)
+	}
+	if eid.SameNode(bpv7.DtnNone()) {
+		t.Fatal("Group endpoint is dtn:none")
+	}
+}
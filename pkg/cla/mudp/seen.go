@@ -0,0 +1,37 @@
+package mudp
+
+import "sync"
+
+// seenCache remembers a bounded number of recently seen bundle IDs, forgetting the oldest first.
+type seenCache struct {
+	mutex sync.Mutex
+	ids   map[string]struct{}
+	order []string
+	next  int
+}
+
+func newSeenCache(size int) *seenCache {
+	return &seenCache{
+		ids:   make(map[string]struct{}, size),
+		order: make([]string, size),
+	}
+}
+
+// Add a bundle ID and return true if it was not seen before.
+// This method is thread-safe.
+func (cache *seenCache) Add(id string) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, seen := cache.ids[id]; seen {
+		return false
+	}
+
+	if oldest := cache.order[cache.next]; oldest != "" {
+		delete(cache.ids, oldest)
+	}
+	cache.order[cache.next] = id
+	cache.next = (cache.next + 1) % len(cache.order)
+	cache.ids[id] = struct{}{}
+	return true
+}
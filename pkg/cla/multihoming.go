@@ -0,0 +1,128 @@
+package cla
+
+import (
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SourceNetwork binds outgoing connections to peers within the network to a local source address, e.g., the address
+// of an LTE modem's interface for peers only reachable through it.
+type SourceNetwork struct {
+	Network *net.IPNet
+	Source  net.IP
+}
+
+// MultihomingConfig configures the source address selection of outgoing connections for nodes bridging several
+// networks, see SetMultihoming.
+type MultihomingConfig struct {
+	// Networks assign source addresses by the peer's network. The first matching network counts.
+	Networks []SourceNetwork
+	// Interfaces binds connections to peers within a local interface's network to this interface's address.
+	Interfaces bool
+}
+
+// CheckValid checks that all networks and source addresses are set.
+func (config MultihomingConfig) CheckValid() error {
+	for _, network := range config.Networks {
+		if network.Network == nil || network.Source == nil {
+			return fmt.Errorf("multihoming contains an empty network or source address")
+		}
+	}
+	return nil
+}
+
+// SetMultihoming enables the source address selection of outgoing connections by SourceAddress. Nil disables it,
+// which is also the default, leaving the selection to the operating system's routing table.
+//
+// This method is thread-safe.
+func (manager *Manager) SetMultihoming(config *MultihomingConfig) {
+	manager.multihomingMutex.Lock()
+	defer manager.multihomingMutex.Unlock()
+
+	manager.multihoming = config
+	manager.reachableVia = make(map[string]net.IP)
+}
+
+// hostIP extracts the IP address from an address like "10.0.0.2:35037" or "[fe80::1]:35037". It is nil for addresses
+// without an IP address, e.g., a hostname.
+func hostIP(address string) net.IP {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return net.ParseIP(address)
+}
+
+// LearnSourceAddress records the local address through which a peer connected to this node, such that outgoing
+// connections to this peer are bound to the same address. Listeners bound to an unspecified address, e.g., "0.0.0.0",
+// cannot tell their connections' local addresses, which are thus ignored.
+//
+// This method is thread-safe.
+func (manager *Manager) LearnSourceAddress(remote, local net.Addr) {
+	manager.multihomingMutex.Lock()
+	defer manager.multihomingMutex.Unlock()
+
+	if manager.multihoming == nil {
+		return
+	}
+
+	peer, source := hostIP(remote.String()), hostIP(local.String())
+	if peer == nil || source == nil || source.IsUnspecified() {
+		return
+	}
+	if known, ok := manager.reachableVia[peer.String()]; ok && known.Equal(source) {
+		return
+	}
+
+	manager.reachableVia[peer.String()] = source
+	log.WithFields(log.Fields{
+		"peer":   peer,
+		"source": source,
+	}).Debug("Learned the local address a peer is reachable through")
+}
+
+// interfaceSource returns the address of the local interface whose network contains the peer, if one exists.
+func interfaceSource(peer net.IP) net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.WithError(err).Debug("Failed to list the local interfaces' addresses")
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.Contains(peer) && !ipNet.IP.Equal(peer) {
+			return ipNet.IP
+		}
+	}
+	return nil
+}
+
+// SourceAddress returns the local address an outgoing connection to the peer's address should be bound to. The
+// address learned from the peer's inbound connections takes precedence over the configured networks, followed by the
+// local interfaces' networks, if enabled. It is nil if the multihoming is disabled, if the address is no IP address,
+// or if there is no matching source address, leaving the selection to the operating system.
+//
+// This method is thread-safe.
+func (manager *Manager) SourceAddress(address string) net.IP {
+	manager.multihomingMutex.Lock()
+	defer manager.multihomingMutex.Unlock()
+
+	peer := hostIP(address)
+	if manager.multihoming == nil || peer == nil {
+		return nil
+	}
+
+	if source, ok := manager.reachableVia[peer.String()]; ok {
+		return source
+	}
+	for _, network := range manager.multihoming.Networks {
+		if network.Network.Contains(peer) {
+			return network.Source
+		}
+	}
+	if manager.multihoming.Interfaces {
+		return interfaceSource(peer)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package cla
+
+import (
+	"net"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestSourceAddress(t *testing.T) {
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+
+	peer := &net.TCPAddr{IP: net.ParseIP("10.64.0.2"), Port: 40001}
+	lan := &net.TCPAddr{IP: net.ParseIP("192.168.1.2"), Port: 35037}
+
+	manager.LearnSourceAddress(peer, lan)
+	if source := manager.SourceAddress("10.64.0.2:35037"); source != nil {
+		t.Fatalf("Disabled multihoming selected %v", source)
+	}
+
+	_, lte, _ := net.ParseCIDR("10.64.0.0/10")
+	manager.SetMultihoming(&MultihomingConfig{
+		Networks: []SourceNetwork{{Network: lte, Source: net.ParseIP("10.64.1.1")}},
+	})
+
+	if source := manager.SourceAddress("10.64.0.2:35037"); !source.Equal(net.ParseIP("10.64.1.1")) {
+		t.Fatalf("Network's source address was not selected, but %v", source)
+	}
+	if source := manager.SourceAddress("172.16.0.2:35037"); source != nil {
+		t.Fatalf("Peer outside of all networks got source address %v", source)
+	}
+	if source := manager.SourceAddress("peer.example:35037"); source != nil {
+		t.Fatalf("Hostname got source address %v", source)
+	}
+
+	manager.LearnSourceAddress(peer, &net.TCPAddr{IP: net.IPv4zero, Port: 35037})
+	if source := manager.SourceAddress("10.64.0.2:35037"); !source.Equal(net.ParseIP("10.64.1.1")) {
+		t.Fatalf("Unspecified local address was learned as %v", source)
+	}
+
+	manager.LearnSourceAddress(peer, lan)
+	if source := manager.SourceAddress("10.64.0.2:35037"); !source.Equal(lan.IP) {
+		t.Fatalf("Learned source address was not selected, but %v", source)
+	}
+}
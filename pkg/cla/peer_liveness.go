@@ -0,0 +1,77 @@
+package cla
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// PeerLiveness is a peer's reachability, as determined by periodic probes.
+type PeerLiveness struct {
+	Endpoint bpv7.EndpointID
+	Address  string
+	Up       bool
+	// Since is the time of the last change between up and down.
+	Since time.Time
+	// LastProbe is the time of the most recent probe.
+	LastProbe time.Time
+}
+
+// MarkPeer records a probe's result for a peer.
+// This method is thread-safe.
+func (manager *Manager) MarkPeer(peerID bpv7.EndpointID, address string, up bool) {
+	manager.livenessMutex.Lock()
+	defer manager.livenessMutex.Unlock()
+
+	now := clock.Now()
+	liveness, known := manager.liveness[peerID]
+	if !known || liveness.Up != up {
+		log.WithFields(log.Fields{
+			"peer":    peerID,
+			"address": address,
+			"up":      up,
+		}).Info("Peer reachability changed")
+
+		liveness = PeerLiveness{Endpoint: peerID, Up: up, Since: now}
+	}
+	liveness.Address = address
+	liveness.LastProbe = now
+	manager.liveness[peerID] = liveness
+}
+
+// PeerDown checks if a peer was marked as down. Peers without probes are never down.
+// This method is thread-safe.
+func (manager *Manager) PeerDown(peerID bpv7.EndpointID) bool {
+	manager.livenessMutex.Lock()
+	defer manager.livenessMutex.Unlock()
+
+	liveness, known := manager.liveness[peerID]
+	return known && !liveness.Up
+}
+
+// GetPeerLiveness returns the reachability of all probed peers.
+// This method is thread-safe.
+func (manager *Manager) GetPeerLiveness() []PeerLiveness {
+	manager.livenessMutex.Lock()
+	defer manager.livenessMutex.Unlock()
+
+	peers := make([]PeerLiveness, 0, len(manager.liveness))
+	for _, liveness := range manager.liveness {
+		peers = append(peers, liveness)
+	}
+	return peers
+}
+
+// HasSenderFor checks if a sender to the peer is currently registered.
+// This method is thread-safe.
+func (manager *Manager) HasSenderFor(peerID bpv7.EndpointID) bool {
+	for _, sender := range manager.GetSenders() {
+		if sender.GetPeerEndpointID() == peerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package quicl
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// acknowledgementTimeout limits a sender's wait for the receiver's acknowledgement or refusal of a sent bundle.
+const acknowledgementTimeout = 10 * time.Second
+
+// ErrBundleRefused is returned by Send if the peer refused the bundle, e.g., because of its congestion policy.
+var ErrBundleRefused = errors.New("bundle was refused by the peer")
+
+// admission decides on each received bundle, nil to accept all bundles.
+var admission func(*bpv7.Bundle) error
+
+// SetAdmission configures a function to decide on each received bundle before it is acknowledged, e.g., by a
+// congestion policy. A bundle resulting in an error is dropped and refused, such that the peer's Send returns
+// ErrBundleRefused. This must be called before any listener or dialer is started.
+func SetAdmission(admit func(*bpv7.Bundle) error) {
+	admission = admit
+}
+
+// admit decides on a received bundle by the configured admission.
+func admit(bundle *bpv7.Bundle) error {
+	if admission == nil {
+		return nil
+	}
+	return admission(bundle)
+}
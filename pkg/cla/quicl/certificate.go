@@ -0,0 +1,36 @@
+package quicl
+
+import (
+	"crypto/tls"
+
+	"github.com/dtn7/dtn7-go/pkg/cla/quicl/internal"
+)
+
+// certificate is the node's identity certificate, nil for a self-signed certificate generated by each listener.
+var certificate *tls.Certificate
+
+// SetCertificate configures the node's identity certificate, e.g., created by dtn-tool's certgen, binding its node ID
+// to its key, see package identity. Listeners present it instead of a generated one, dialers as client certificate.
+// As peers still do not verify it, this only allows a later inspection. This must be called before any listener or
+// dialer is started.
+func SetCertificate(cert tls.Certificate) {
+	certificate = &cert
+}
+
+// listenerTLSConfig presents the identity certificate, if configured.
+func listenerTLSConfig() *tls.Config {
+	if certificate == nil {
+		return internal.GenerateSimpleListenerTLSConfig()
+	}
+
+	return internal.ListenerTLSConfig(*certificate)
+}
+
+// dialerTLSConfig presents the identity certificate as client certificate, if configured.
+func dialerTLSConfig() *tls.Config {
+	config := internal.GenerateSimpleDialerTLSConfig()
+	if certificate != nil {
+		config.Certificates = []tls.Certificate{*certificate}
+	}
+	return config
+}
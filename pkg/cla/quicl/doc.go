@@ -50,6 +50,16 @@ If a node wants to transmit a bundle, it simply opens a new stream and sends the
 On the receiving side, when a node notices a new stream opening,
 it launches a new handler goroutine which receives and deserialises the bundle and terminates.
 A single stream will always carry exactly one bundle and be closed after the transmission is completed.
+
+
+Bundle acknowledgement and refusal
+
+After sending a bundle, the sender waits for the receiver's decision on the stream's reverse direction.
+The receiver acknowledges an accepted bundle by closing its direction of the stream without sending any data.
+A bundle refused, e.g., by the receiver's congestion policy, is signalled by cancelling its direction of the stream
+with stream error code 3 (BundleRefused) instead.
+Thus, a sender learns about the refusal and keeps the bundle, instead of assuming its successful transmission.
+As older implementations do not acknowledge bundles, both peers must support this.
 */
 
 package quicl
@@ -86,6 +86,10 @@ Methods for Convergable interface
 
 func (endpoint *Endpoint) Close() error {
 	log.WithField("peer", endpoint.peerAddress).Debug("Someone called Close()")
+	// A dialer's connection does not exist before a successful Activate
+	if endpoint.connection == nil {
+		return nil
+	}
 	err := endpoint.connection.CloseWithError(internal.ApplicationShutdown, "Daemon shutting down")
 	return err
 }
@@ -94,6 +98,35 @@ func (endpoint *Endpoint) Close() error {
 Methods for Convergence interface
 */
 
+// dial a QUIC connection to the peer's address, bound to its multihoming source address if there is one.
+func dial(peerAddress string) (quic.Connection, error) {
+	source := cla.GetManagerSingleton().SourceAddress(peerAddress)
+	if source == nil {
+		return quic.DialAddr(context.Background(), peerAddress, dialerTLSConfig(), internal.GenerateQUICConfig())
+	}
+
+	remote, err := net.ResolveUDPAddr("udp", peerAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: source})
+	if err != nil {
+		return nil, err
+	}
+	session, err := quic.Dial(context.Background(), conn, remote, dialerTLSConfig(), internal.GenerateQUICConfig())
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// Unlike DialAddr, Dial leaves closing the UDP socket to its caller.
+	go func() {
+		<-session.Context().Done()
+		_ = conn.Close()
+	}()
+	return session, nil
+}
+
 func (endpoint *Endpoint) Activate() error {
 	log.WithFields(log.Fields{
 		"cla":  endpoint.id,
@@ -102,7 +135,7 @@ func (endpoint *Endpoint) Activate() error {
 
 	// if we are on the dialer-side we need to first initiate the quic-connection
 	if endpoint.dialer {
-		session, err := quic.DialAddr(context.Background(), endpoint.peerAddress, internal.GenerateSimpleDialerTLSConfig(), internal.GenerateQUICConfig())
+		session, err := dial(endpoint.peerAddress)
 		endpoint.connection = session
 		if err != nil {
 			return err
@@ -302,6 +335,15 @@ func (endpoint *Endpoint) Send(bndl bpv7.Bundle) error {
 		}).Debug("Error closing stream (send successful)")
 	}
 
+	if err = awaitAcknowledgement(stream); err != nil {
+		log.WithFields(log.Fields{
+			"peer":   endpoint.peerId,
+			"bundle": bndl.ID(),
+			"error":  err,
+		}).Debug("Bundle was not acknowledged")
+		return err
+	}
+
 	log.WithFields(log.Fields{
 		"peer":   endpoint.peerId,
 		"bundle": bndl.ID(),
@@ -310,6 +352,21 @@ func (endpoint *Endpoint) Send(bndl bpv7.Bundle) error {
 	return nil
 }
 
+// awaitAcknowledgement waits for the receiver closing its direction of the stream, acknowledging the bundle, or
+// cancelling it, refusing the bundle.
+func awaitAcknowledgement(stream quic.Stream) error {
+	if err := stream.SetReadDeadline(time.Now().Add(acknowledgementTimeout)); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(io.Discard, stream)
+	var streamErr *quic.StreamError
+	if errors.As(err, &streamErr) && streamErr.ErrorCode == internal.BundleRefused {
+		return ErrBundleRefused
+	}
+	return err
+}
+
 /*
 Non-interface methods
 */
@@ -381,6 +438,7 @@ func (endpoint *Endpoint) handleStream(stream quic.Stream) {
 		}).Error("quicl failed to read bundle")
 
 		stream.CancelRead(internal.StreamTransmissionError)
+		stream.CancelWrite(internal.StreamTransmissionError)
 
 		var netErr net.Error
 		if errors.As(err, &netErr) {
@@ -388,11 +446,27 @@ func (endpoint *Endpoint) handleStream(stream quic.Stream) {
 				cla.GetManagerSingleton().NotifyDisconnect(endpoint)
 			}
 		}
+	} else if err := admit(bundle); err != nil {
+		log.WithFields(log.Fields{
+			"cla":    endpoint,
+			"bundle": bundle.ID(),
+			"error":  err,
+		}).Info("quicl refused a bundle")
+
+		stream.CancelWrite(internal.BundleRefused)
 	} else {
 		log.WithFields(log.Fields{
 			"cla": endpoint,
 		}).Debug("quicl received a bundle")
 
+		if err := stream.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"cla":    endpoint,
+				"bundle": bundle.ID(),
+				"error":  err,
+			}).Debug("Error closing stream (acknowledgement)")
+		}
+
 		endpoint.receiveCallback(bundle)
 	}
 	log.WithFields(log.Fields{
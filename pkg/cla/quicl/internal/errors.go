@@ -20,9 +20,13 @@ const (
 	PeerError quic.ApplicationErrorCode = 4
 	// ConnectionError designates errors in underlying data transmission
 	ConnectionError quic.ApplicationErrorCode = 5
+	// ConnectionRefused is sent when a listener refuses a connection because of its connection limits
+	ConnectionRefused quic.ApplicationErrorCode = 6
 
 	DataMarshalError        quic.StreamErrorCode = 1
 	StreamTransmissionError quic.StreamErrorCode = 2
+	// BundleRefused is sent by a receiver refusing a bundle, e.g., because of its congestion policy
+	BundleRefused quic.StreamErrorCode = 3
 )
 
 // HandshakeError is thrown by either the listener or dialer if there is any problem during the protocol handshake
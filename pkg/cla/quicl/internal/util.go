@@ -37,8 +37,13 @@ func GenerateSimpleListenerTLSConfig() *tls.Config {
 	if err != nil {
 		log.WithError(err).Fatal("Error generating combined certificate")
 	}
+	return ListenerTLSConfig(tlsCert)
+}
+
+// ListenerTLSConfig generates a bare-bones TLS config for the listener, presenting the certificate
+func ListenerTLSConfig(cert tls.Certificate) *tls.Config {
 	return &tls.Config{
-		Certificates: []tls.Certificate{tlsCert},
+		Certificates: []tls.Certificate{cert},
 		NextProtos:   []string{"bpv7-quicl"},
 		MinVersion:   tls.VersionTLS13,
 	}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"sync/atomic"
+	"time"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
@@ -42,7 +43,7 @@ func (listener *Listener) Close() error {
 
 func (listener *Listener) Start() error {
 	log.WithField("address", listener.listenAddress).Info("Starting QUICL-listener")
-	lst, err := quic.ListenAddr(listener.listenAddress, internal.GenerateSimpleListenerTLSConfig(), internal.GenerateQUICConfig())
+	lst, err := quic.ListenAddr(listener.listenAddress, listenerTLSConfig(), internal.GenerateQUICConfig())
 	if err != nil {
 		log.WithError(err).Error("Error creating QUICL listener")
 		return err
@@ -84,8 +85,23 @@ func (listener *Listener) handle() {
 					"address": listener.listenAddress,
 					"error":   err,
 				}).Error("Unknown error accepting QUIC connection")
+				cla.GetManagerSingleton().NotifyListenerError(listener.listenAddress, err)
 			}
 		} else {
+			release, backoff, ok := cla.GetManagerSingleton().AdmitConnection(listener.Address(), session.RemoteAddr())
+			if !ok {
+				_ = session.CloseWithError(internal.ConnectionRefused, "Connection limit reached")
+				time.Sleep(backoff)
+				continue
+			}
+			// The connection's context ends when it is closed by either side.
+			go func() {
+				<-session.Context().Done()
+				release()
+			}()
+
+			cla.GetManagerSingleton().LearnSourceAddress(session.RemoteAddr(), session.LocalAddr())
+
 			log.WithFields(log.Fields{
 				"address": listener.listenAddress,
 				"peer":    session.RemoteAddr(),
@@ -1,10 +1,12 @@
 package quicl
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"sync"
 	"testing"
+	"time"
 
 	"pgregory.net/rapid"
 
@@ -35,10 +37,8 @@ func getRandomPort(t *rapid.T) int {
 
 func setup(t *rapid.T) {
 	receive := func(bundle *bpv7.Bundle) {}
-	connect := func(eid bpv7.EndpointID) {}
-	disconnect := func(eid bpv7.EndpointID) {}
 
-	err := cla.InitialiseCLAManager(receive, connect, disconnect)
+	err := cla.InitialiseCLAManager(receive)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -113,3 +113,76 @@ func TestSendReceive(t *testing.T) {
 		}
 	})
 }
+
+func TestSendRefused(t *testing.T) {
+	if err := cla.InitialiseCLAManager(func(*bpv7.Bundle) {}); err != nil {
+		t.Fatal(err)
+	}
+	defer cla.GetManagerSingleton().Shutdown()
+
+	refuse := errors.New("congested")
+	SetAdmission(func(bundle *bpv7.Bundle) error {
+		if bundle.PrimaryBlock.Destination == bpv7.MustNewEndpointID("dtn://refused/") {
+			return refuse
+		}
+		return nil
+	})
+	defer SetAdmission(nil)
+
+	l, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.LocalAddr().(*net.UDPAddr).Port
+	_ = l.Close()
+
+	received := make(chan bpv7.Bundle, 2)
+	serv := NewQUICListener(fmt.Sprintf("127.0.0.1:%d", port), bpv7.MustNewEndpointID("dtn://quicl/"),
+		func(bundle *bpv7.Bundle) { received <- *bundle })
+	if err := serv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = serv.Close() }()
+
+	client := NewDialerEndpoint(fmt.Sprintf("127.0.0.1:%d", port), bpv7.MustNewEndpointID("dtn://client/"),
+		func(*bpv7.Bundle) {})
+	if err := client.Activate(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+
+	for _, test := range []struct {
+		destination string
+		err         error
+	}{
+		{"dtn://refused/", ErrBundleRefused},
+		{"dtn://accepted/", nil},
+	} {
+		bundle, err := bpv7.Builder().
+			Source("dtn://client/").
+			Destination(test.destination).
+			CreationTimestampNow().
+			Lifetime("1h").
+			PayloadBlock([]byte("hello")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := client.Send(bundle); !errors.Is(err, test.err) {
+			t.Fatalf("Sending to %s: expected error %v, got %v", test.destination, test.err, err)
+		}
+	}
+
+	select {
+	case bundle := <-received:
+		if dst := bundle.PrimaryBlock.Destination.String(); dst != "dtn://accepted/" {
+			t.Fatalf("Received bundle for %s", dst)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Accepted bundle was not received")
+	}
+	if len(received) != 0 {
+		t.Fatal("Refused bundle was received")
+	}
+}
@@ -0,0 +1,172 @@
+package cla
+
+import (
+	"net"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SetRoaming enables re-associating peers whose IP address changes between contacts, e.g., mobile nodes switching
+// hotspots. If a sender to an already connected peer is registered from another IP address of the same family, the
+// peer is considered to have moved. Its stale senders to the old address are closed without passing on their
+// disconnect, and transmissions queued for them are migrated to the new sender.
+//
+// Each move is published as a PeerMoved Event, e.g., to update a peer database. Otherwise, a peer with multiple
+// addresses would be reconnected and moved over and over again, which is why roaming is disabled by default.
+// This method is thread-safe.
+func (manager *Manager) SetRoaming(enabled bool) {
+	manager.roamingMutex.Lock()
+	defer manager.roamingMutex.Unlock()
+
+	manager.roamingEnabled = enabled
+}
+
+// sameFamilyHosts checks if two CLA addresses are of different hosts of the same IP family. Addresses of another form
+// than host:port are never compared.
+func sameFamilyHosts(a, b string) bool {
+	hostA, _, errA := net.SplitHostPort(a)
+	hostB, _, errB := net.SplitHostPort(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	ipA, ipB := net.ParseIP(hostA), net.ParseIP(hostB)
+	if ipA == nil || ipB == nil || ipA.Equal(ipB) {
+		return false
+	}
+	return (ipA.To4() == nil) == (ipB.To4() == nil)
+}
+
+// staleSenders returns the registered senders to the new sender's peer at another address, if roaming is enabled.
+// The caller must hold the stateMutex.
+func (manager *Manager) staleSenders(sender ConvergenceSender) (stale []ConvergenceSender) {
+	manager.roamingMutex.Lock()
+	enabled := manager.roamingEnabled
+	manager.roamingMutex.Unlock()
+
+	peerID := sender.GetPeerEndpointID()
+	if !enabled || peerID == (bpv7.EndpointID{}) || peerID == bpv7.DtnNone() {
+		return nil
+	}
+
+	for _, registeredSender := range manager.senders {
+		if registeredSender != sender && registeredSender.GetPeerEndpointID() == peerID &&
+			sameFamilyHosts(registeredSender.Address(), sender.Address()) {
+			stale = append(stale, registeredSender)
+		}
+	}
+	return
+}
+
+// roam replaces a moved peer's stale senders by its new sender. Their shapers are taken over, together with the
+// queued transmissions, and their disconnects are not passed on, as the peer is still reachable.
+func (manager *Manager) roam(sender ConvergenceSender, stale []ConvergenceSender) {
+	for _, staleSender := range stale {
+		log.WithFields(log.Fields{
+			"peer":        sender.GetPeerEndpointID(),
+			"old address": staleSender.Address(),
+			"new address": sender.Address(),
+		}).Info("Peer roamed to a new address")
+
+		manager.roamingMutex.Lock()
+		manager.roamed[staleSender] = sender
+		manager.leaving[staleSender] = true
+		manager.roamingMutex.Unlock()
+
+		manager.stateMutex.Lock()
+		senders := make([]ConvergenceSender, 0, len(manager.senders))
+		for _, registeredSender := range manager.senders {
+			if registeredSender != staleSender {
+				senders = append(senders, registeredSender)
+			}
+		}
+		manager.senders = senders
+
+		receivers := make([]ConvergenceReceiver, 0, len(manager.receivers))
+		for _, registeredReceiver := range manager.receivers {
+			if Convergence(registeredReceiver) != Convergence(staleSender) {
+				receivers = append(receivers, registeredReceiver)
+			}
+		}
+		manager.receivers = receivers
+		manager.stateMutex.Unlock()
+
+		manager.shapingMutex.Lock()
+		if shaper, ok := manager.shapers[staleSender.Address()]; ok {
+			if _, exists := manager.shapers[sender.Address()]; !exists {
+				manager.shapers[sender.Address()] = shaper
+			}
+			delete(manager.shapers, staleSender.Address())
+		}
+		manager.shapingMutex.Unlock()
+
+		if err := staleSender.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"cla":   staleSender,
+				"error": err,
+			}).Debug("Error closing stale CLA")
+		}
+
+		manager.events.Publish(Event{
+			Type:            PeerMoved,
+			Peer:            sender.GetPeerEndpointID(),
+			Address:         sender.Address(),
+			PreviousAddress: staleSender.Address(),
+		})
+	}
+}
+
+// isLeaving checks if the CLA's disconnect results from its peer's move and must not be passed on.
+// The CLA is forgotten afterwards, as it disconnects only once.
+func (manager *Manager) isLeaving(cla Convergence) bool {
+	sender, ok := cla.(ConvergenceSender)
+	if !ok {
+		return false
+	}
+
+	manager.roamingMutex.Lock()
+	defer manager.roamingMutex.Unlock()
+
+	leaving := manager.leaving[sender]
+	delete(manager.leaving, sender)
+	return leaving
+}
+
+// forgetRoamed removes a disconnected sender from the migrations, as its queued transmissions cannot be sent anymore.
+func (manager *Manager) forgetRoamed(cla Convergence) {
+	manager.roamingMutex.Lock()
+	defer manager.roamingMutex.Unlock()
+
+	for staleSender, sender := range manager.roamed {
+		if Convergence(staleSender) == cla || Convergence(sender) == cla {
+			delete(manager.roamed, staleSender)
+		}
+	}
+}
+
+// migrated follows the moves of a sender's peer to its current sender.
+func (manager *Manager) migrated(sender ConvergenceSender) ConvergenceSender {
+	manager.roamingMutex.Lock()
+	defer manager.roamingMutex.Unlock()
+
+	for {
+		next, ok := manager.roamed[sender]
+		if !ok {
+			return sender
+		}
+		sender = next
+	}
+}
+
+// migratingSender resolves its sender at the time of transmission, so that a transmission queued for a stale sender,
+// e.g., by the traffic shaping, is sent over the sender to its peer's new address.
+type migratingSender struct {
+	ConvergenceSender
+	manager *Manager
+}
+
+func (sender migratingSender) Send(bndl bpv7.Bundle) error {
+	return sender.manager.migrated(sender.ConvergenceSender).Send(bndl)
+}
@@ -0,0 +1,110 @@
+package cla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestSameFamilyHosts(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"10.0.0.2:35037", "192.168.1.7:41234", true},
+		{"[fe80::1]:35037", "[2001:db8::1]:41234", true},
+		{"10.0.0.2:35037", "10.0.0.2:41234", false},
+		{"10.0.0.2:35037", "[2001:db8::1]:41234", false},
+		{"mtcp://10.0.0.2:35037", "192.168.1.7:41234", false},
+	}
+
+	for _, test := range tests {
+		if same := sameFamilyHosts(test.a, test.b); same != test.same {
+			t.Errorf("sameFamilyHosts(%q, %q) = %t", test.a, test.b, same)
+		}
+	}
+}
+
+func TestRoaming(t *testing.T) {
+	disconnects := make(chan bpv7.EndpointID, 10)
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+	manager.Events().Subscribe(func(ev Event) { disconnects <- ev.Peer }, PeerDisconnected)
+
+	moves := make(chan string, 10)
+	manager.Events().Subscribe(func(ev Event) { moves <- ev.PreviousAddress + " " + ev.Address }, PeerMoved)
+	manager.SetRoaming(true)
+
+	redials := 0
+	peer := bpv7.MustNewEndpointID("dtn://peer/")
+	other := &redialSender{manager: manager, address: "10.0.0.3:35037", peer: bpv7.MustNewEndpointID("dtn://other/"), redials: &redials}
+	sameHost := &redialSender{manager: manager, address: "10.0.0.2:41234", peer: peer, redials: &redials}
+	stale := &redialSender{manager: manager, address: "10.0.0.2:35037", peer: peer, redials: &redials}
+	manager.registerAsync(other)
+	manager.registerAsync(stale)
+	manager.registerAsync(sameHost)
+	if len(manager.GetSenders()) != 3 {
+		t.Fatalf("Senders %v were not registered", manager.GetSenders())
+	}
+
+	moved := &redialSender{manager: manager, address: "192.168.1.7:35037", peer: peer, redials: &redials}
+	manager.registerAsync(moved)
+
+	select {
+	case move := <-moves:
+		if move != "10.0.0.2:35037 192.168.1.7:35037" && move != "10.0.0.2:41234 192.168.1.7:35037" {
+			t.Fatalf("Unexpected move %q", move)
+		}
+		<-moves
+	case <-time.After(time.Second):
+		t.Fatal("Peer's move was not reported")
+	}
+
+	senders := manager.GetSenders()
+	if len(senders) != 2 || senders[0] != ConvergenceSender(other) || senders[1] != ConvergenceSender(moved) {
+		t.Fatalf("Senders %v were not replaced by the moved one", senders)
+	}
+	if !stale.closed || !sameHost.closed {
+		t.Fatal("Stale senders were not closed")
+	}
+
+	// A transmission over a stale sender is migrated
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://peer/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Send(stale, bndl); err != nil {
+		t.Fatal(err)
+	}
+	if stale.sent != 0 || moved.sent != 1 {
+		t.Fatalf("Stale sender sent %d and the moved one %d bundles", stale.sent, moved.sent)
+	}
+
+	select {
+	case eid := <-disconnects:
+		t.Fatalf("Roaming was reported as disconnect of %v", eid)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The moved sender's real disconnect is passed on
+	_ = moved.Close()
+	select {
+	case eid := <-disconnects:
+		if eid != peer {
+			t.Fatalf("Disconnect of %v instead of %v", eid, peer)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Disconnect was not reported")
+	}
+}
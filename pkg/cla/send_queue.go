@@ -0,0 +1,222 @@
+package cla
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SendQueueConfig bounds each sender's queue of transmissions, see SetSendQueues. A zero bound is unlimited.
+type SendQueueConfig struct {
+	MaxBundles int
+	MaxBytes   uint64
+}
+
+// CheckValid checks for a non-negative bundle bound.
+func (config SendQueueConfig) CheckValid() error {
+	if config.MaxBundles < 0 {
+		return fmt.Errorf("send queue bound %d must not be negative", config.MaxBundles)
+	}
+	return nil
+}
+
+// ErrQueueFull is returned by Enqueue, or passed to the callback of an evicted transmission, if a sender's queue has
+// no room for a bundle.
+var ErrQueueFull = errors.New("send queue is full")
+
+// queuedTransmission is a bundle waiting within a sendQueue.
+type queuedTransmission struct {
+	bundle   bpv7.Bundle
+	priority bpv7.Priority
+	size     uint64
+	seq      uint64
+	done     func(error)
+}
+
+// sendQueue orders a sender's pending transmissions by their bundles' priority, the oldest first within a priority.
+type sendQueue struct {
+	sender        ConvergenceSender
+	transmissions []*queuedTransmission
+	bytes         uint64
+	draining      bool
+}
+
+// SetSendQueues queues the transmissions of Enqueue per sender, which are sent one after another by their bundles'
+// priority. The queues are bounded by the config. Nil disables the queues, which is the default. Thus, each
+// transmission is sent on its own goroutine, concurrent to all others.
+//
+// This method is thread-safe.
+func (manager *Manager) SetSendQueues(config *SendQueueConfig) {
+	manager.queueMutex.Lock()
+	defer manager.queueMutex.Unlock()
+
+	manager.queueConfig = config
+}
+
+// Queueing checks if transmissions are queued per sender, see SetSendQueues.
+//
+// This method is thread-safe.
+func (manager *Manager) Queueing() bool {
+	manager.queueMutex.Lock()
+	defer manager.queueMutex.Unlock()
+
+	return manager.queueConfig != nil
+}
+
+// Enqueue hands a bundle over for its transmission by Send, without waiting for it. The result is passed to done,
+// called on another goroutine.
+//
+// If the sender's queue is full, it evicts its newest transmission of the lowest priority below the bundle's. An
+// evicted transmission's done gets ErrQueueFull. Without such a transmission, ErrQueueFull is returned right away and
+// done is never called.
+//
+// This method is thread-safe.
+func (manager *Manager) Enqueue(sender ConvergenceSender, bndl bpv7.Bundle, done func(error)) error {
+	manager.queueMutex.Lock()
+
+	config := manager.queueConfig
+	if config == nil {
+		manager.queueMutex.Unlock()
+		go func() { done(manager.Send(sender, bndl)) }()
+		return nil
+	}
+
+	queue, ok := manager.queues[sender.Address()]
+	if !ok {
+		queue = &sendQueue{sender: sender}
+		manager.queues[sender.Address()] = queue
+	}
+
+	transmission := &queuedTransmission{
+		bundle:   bndl,
+		priority: bndl.Priority(),
+		size:     BundleSize(bndl),
+		seq:      manager.queueSeq,
+		done:     done,
+	}
+	manager.queueSeq++
+
+	var evicted []*queuedTransmission
+	for queue.full(*config, transmission.size) {
+		victim := queue.lowest()
+		if victim == nil || victim.priority >= transmission.priority {
+			manager.queueMutex.Unlock()
+			manager.evict(sender, evicted)
+			return ErrQueueFull
+		}
+		queue.remove(victim)
+		evicted = append(evicted, victim)
+	}
+
+	heap.Push(queue, transmission)
+	queue.bytes += transmission.size
+	if !queue.draining {
+		queue.draining = true
+		go manager.drain(queue)
+	}
+	manager.queueMutex.Unlock()
+
+	manager.evict(sender, evicted)
+	return nil
+}
+
+// evict reports transmissions removed from a full queue.
+func (manager *Manager) evict(sender ConvergenceSender, evicted []*queuedTransmission) {
+	for _, transmission := range evicted {
+		log.WithFields(log.Fields{
+			"cla":    sender,
+			"bundle": transmission.bundle.ID(),
+		}).Debug("Evicted transmission of a lower priority from a full send queue")
+		go transmission.done(ErrQueueFull)
+	}
+}
+
+// drain sends a queue's transmissions one after another until it is empty.
+func (manager *Manager) drain(queue *sendQueue) {
+	for {
+		manager.queueMutex.Lock()
+		if queue.Len() == 0 {
+			queue.draining = false
+			if manager.queues[queue.sender.Address()] == queue {
+				delete(manager.queues, queue.sender.Address())
+			}
+			manager.queueMutex.Unlock()
+			return
+		}
+		transmission := heap.Pop(queue).(*queuedTransmission)
+		queue.bytes -= transmission.size
+		manager.queueMutex.Unlock()
+
+		transmission.done(manager.Send(queue.sender, transmission.bundle))
+	}
+}
+
+// SendQueues returns the number of queued transmissions per sender's address, empty without queues.
+//
+// This method is thread-safe.
+func (manager *Manager) SendQueues() map[string]int {
+	manager.queueMutex.Lock()
+	defer manager.queueMutex.Unlock()
+
+	queues := make(map[string]int, len(manager.queues))
+	for address, queue := range manager.queues {
+		queues[address] = queue.Len()
+	}
+	return queues
+}
+
+// full checks if the queue has no room for a transmission of the given size.
+func (queue *sendQueue) full(config SendQueueConfig, size uint64) bool {
+	return (config.MaxBundles > 0 && queue.Len() >= config.MaxBundles) ||
+		(config.MaxBytes > 0 && queue.bytes+size > config.MaxBytes)
+}
+
+// lowest returns the newest transmission of the lowest priority, nil for an empty queue.
+func (queue *sendQueue) lowest() (lowest *queuedTransmission) {
+	for _, transmission := range queue.transmissions {
+		if lowest == nil || transmission.priority < lowest.priority ||
+			(transmission.priority == lowest.priority && transmission.seq > lowest.seq) {
+			lowest = transmission
+		}
+	}
+	return
+}
+
+// remove a queued transmission without sending it.
+func (queue *sendQueue) remove(transmission *queuedTransmission) {
+	for i, t := range queue.transmissions {
+		if t == transmission {
+			heap.Remove(queue, i)
+			queue.bytes -= transmission.size
+			return
+		}
+	}
+}
+
+// The following methods implement heap.Interface and should not be called directly.
+
+func (queue *sendQueue) Len() int { return len(queue.transmissions) }
+
+func (queue *sendQueue) Less(i, j int) bool {
+	ti, tj := queue.transmissions[i], queue.transmissions[j]
+	return ti.priority > tj.priority || (ti.priority == tj.priority && ti.seq < tj.seq)
+}
+
+func (queue *sendQueue) Swap(i, j int) {
+	queue.transmissions[i], queue.transmissions[j] = queue.transmissions[j], queue.transmissions[i]
+}
+
+func (queue *sendQueue) Push(x any) {
+	queue.transmissions = append(queue.transmissions, x.(*queuedTransmission))
+}
+
+func (queue *sendQueue) Pop() any {
+	n := len(queue.transmissions)
+	transmission := queue.transmissions[n-1]
+	queue.transmissions = queue.transmissions[:n-1]
+	return transmission
+}
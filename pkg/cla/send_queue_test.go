@@ -0,0 +1,122 @@
+package cla
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// blockingSender blocks each transmission until it is released.
+type blockingSender struct {
+	redialSender
+	release chan struct{}
+
+	sentMutex sync.Mutex
+	sent      []bpv7.BundleID
+}
+
+func (s *blockingSender) Send(bndl bpv7.Bundle) error {
+	<-s.release
+
+	s.sentMutex.Lock()
+	defer s.sentMutex.Unlock()
+	s.sent = append(s.sent, bndl.ID())
+	return nil
+}
+
+func queueTestBundle(t *testing.T, source string, priority bpv7.Priority) bpv7.Bundle {
+	bndl, err := bpv7.Builder().
+		Source(source).
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PriorityBlock(priority).
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+func TestSendQueue(t *testing.T) {
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+	manager.SetSendQueues(&SendQueueConfig{MaxBundles: 3})
+
+	sender := &blockingSender{
+		redialSender: redialSender{manager: manager, address: "10.0.0.2:35037", peer: bpv7.MustNewEndpointID("dtn://peer/")},
+		release:      make(chan struct{}),
+	}
+
+	results := make(chan error, 8)
+	done := func(err error) { results <- err }
+
+	// The first bundle blocks the sender, thus all following bundles are queued.
+	first := queueTestBundle(t, "dtn://first/", bpv7.PriorityBulk)
+	if err := manager.Enqueue(sender, first, done); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	bulk := queueTestBundle(t, "dtn://bulk/", bpv7.PriorityBulk)
+	normal := queueTestBundle(t, "dtn://normal/", bpv7.PriorityNormal)
+	newerBulk := queueTestBundle(t, "dtn://newer-bulk/", bpv7.PriorityBulk)
+	for _, bndl := range []bpv7.Bundle{bulk, normal, newerBulk} {
+		if err := manager.Enqueue(sender, bndl, done); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := manager.Enqueue(sender, queueTestBundle(t, "dtn://rejected/", bpv7.PriorityBulk), done); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Bulk bundle was not rejected by a full queue: %v", err)
+	}
+
+	expedited := queueTestBundle(t, "dtn://expedited/", bpv7.PriorityExpedited)
+	if err := manager.Enqueue(sender, expedited, done); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-results:
+		if !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("Expected an evicted transmission, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No transmission was evicted")
+	}
+
+	if queues := manager.SendQueues(); queues[sender.Address()] != 3 {
+		t.Fatalf("Expected three queued transmissions, got %v", queues)
+	}
+
+	close(sender.release)
+	for i := 0; i < 4; i++ {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Transmission %d did not finish", i)
+		}
+	}
+
+	expected := []bpv7.BundleID{first.ID(), expedited.ID(), normal.ID(), bulk.ID()}
+	sender.sentMutex.Lock()
+	defer sender.sentMutex.Unlock()
+	if len(sender.sent) != len(expected) {
+		t.Fatalf("Expected %d sent bundles, got %d", len(expected), len(sender.sent))
+	}
+	for i, id := range expected {
+		if sender.sent[i] != id {
+			t.Fatalf("Bundle %d was %v, expected %v", i, sender.sent[i], id)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package cla
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// TrafficClass categorises outgoing bundles for traffic shaping.
+type TrafficClass uint
+
+const (
+	// AdministrativeTraffic are bundles carrying administrative records, e.g., status reports.
+	AdministrativeTraffic TrafficClass = iota
+
+	// RoutingTraffic are bundles carrying routing protocol information, e.g., DTLSR or PRoPHET blocks.
+	RoutingTraffic
+
+	// UserTraffic are all other bundles.
+	UserTraffic
+
+	numTrafficClasses int = 3
+)
+
+func TrafficClassFromString(class string) (TrafficClass, error) {
+	switch strings.ToLower(class) {
+	case "administrative":
+		return AdministrativeTraffic, nil
+	case "routing":
+		return RoutingTraffic, nil
+	case "user":
+		return UserTraffic, nil
+	default:
+		return 0, fmt.Errorf("invalid traffic class: %v", class)
+	}
+}
+
+func (class TrafficClass) String() string {
+	switch class {
+	case AdministrativeTraffic:
+		return "administrative"
+	case RoutingTraffic:
+		return "routing"
+	case UserTraffic:
+		return "user"
+	default:
+		return "unknown traffic class"
+	}
+}
+
+// ClassifyBundle determines a bundle's TrafficClass.
+func ClassifyBundle(bndl bpv7.Bundle) TrafficClass {
+	switch {
+	case bndl.IsAdministrativeRecord():
+		return AdministrativeTraffic
+	case bndl.HasExtensionBlock(bpv7.ExtBlockTypeDTLSRBlock), bndl.HasExtensionBlock(bpv7.ExtBlockTypeProphetBlock):
+		return RoutingTraffic
+	default:
+		return UserTraffic
+	}
+}
+
+// ShapingConfig configures the traffic shaping, which is applied to each ConvergenceSender individually.
+type ShapingConfig struct {
+	// Rate is a CLA's link rate in bytes per second, to which outgoing transmissions are paced.
+	Rate uint64
+	// Shares are the relative bandwidth shares of each TrafficClass. Classes without a share get a share of 1.
+	Shares map[TrafficClass]float64
+}
+
+// CheckValid checks for a positive rate and positive shares.
+func (config ShapingConfig) CheckValid() error {
+	if config.Rate == 0 {
+		return fmt.Errorf("shaping rate must be positive")
+	}
+	for class, share := range config.Shares {
+		if share <= 0 {
+			return fmt.Errorf("share %v of traffic class %v is not positive", share, class)
+		}
+	}
+	return nil
+}
+
+// share returns the configured share of a TrafficClass or its default.
+func (config ShapingConfig) share(class TrafficClass) float64 {
+	if share, ok := config.Shares[class]; ok {
+		return share
+	}
+	return 1
+}
+
+// Shaper paces transmissions over a ConvergenceSender to the configured rate. If multiple transmissions are pending,
+// the link's capacity is split up between the TrafficClasses according to their shares, based on self-clocked fair
+// queueing. Thus, a class may use the whole link while the others are idle, but cannot starve them otherwise.
+type Shaper struct {
+	mutex  sync.Mutex
+	config ShapingConfig
+	queue  fairQueue
+	busy   bool
+}
+
+// NewShaper creates a Shaper for a single ConvergenceSender.
+func NewShaper(config ShapingConfig) *Shaper {
+	return &Shaper{
+		config: config,
+		queue:  newFairQueue(config),
+	}
+}
+
+// Send waits for the bundle's turn, transmits it over the sender and blocks until the link would be free again.
+// If the bundle's lifetime is exceeded while waiting, its transmission is cancelled and ErrBundleExpired is returned.
+func (shaper *Shaper) Send(sender ConvergenceSender, bndl bpv7.Bundle) error {
+	size := BundleSize(bndl)
+	ready := shaper.acquire(ClassifyBundle(bndl), size)
+
+	expiry := clock.NewTimer(bndl.RemainingLifetime())
+	select {
+	case <-ready:
+		expiry.Stop()
+	case <-expiry.C():
+		if shaper.cancel(ready) {
+			return ErrBundleExpired
+		}
+		// it became the bundle's turn concurrently
+		<-ready
+	}
+	defer shaper.release()
+
+	if bndl.IsLifetimeExceeded() {
+		return ErrBundleExpired
+	}
+
+	start := clock.Now()
+	err := sender.Send(bndl)
+
+	transmissionTime := time.Duration(float64(size) / float64(shaper.config.Rate) * float64(time.Second))
+	if remaining := transmissionTime - clock.Now().Sub(start); remaining > 0 {
+		clock.Sleep(remaining)
+	}
+	return err
+}
+
+// acquire enqueues a transmission. The returned channel is closed when it is its turn.
+func (shaper *Shaper) acquire(class TrafficClass, size uint64) <-chan struct{} {
+	shaper.mutex.Lock()
+	defer shaper.mutex.Unlock()
+
+	ready := shaper.queue.push(class, size)
+	if !shaper.busy {
+		shaper.busy = true
+		close(shaper.queue.pop())
+	}
+	return ready
+}
+
+// cancel removes a transmission which is still waiting for its turn. It returns false if it is its turn already.
+func (shaper *Shaper) cancel(ready <-chan struct{}) bool {
+	shaper.mutex.Lock()
+	defer shaper.mutex.Unlock()
+
+	return shaper.queue.remove(ready)
+}
+
+// release finishes a transmission and starts the next one.
+func (shaper *Shaper) release() {
+	shaper.mutex.Lock()
+	defer shaper.mutex.Unlock()
+
+	if shaper.queue.Len() == 0 {
+		shaper.busy = false
+	} else {
+		close(shaper.queue.pop())
+	}
+}
+
+// Queued returns the number of transmissions waiting for their turn.
+func (shaper *Shaper) Queued() int {
+	shaper.mutex.Lock()
+	defer shaper.mutex.Unlock()
+
+	return shaper.queue.Len()
+}
+
+// ShapingQueues returns the number of transmissions waiting for their turn per sender's address, empty if traffic
+// is not shaped.
+// This method is thread-safe.
+func (manager *Manager) ShapingQueues() map[string]int {
+	manager.shapingMutex.Lock()
+	defer manager.shapingMutex.Unlock()
+
+	queues := make(map[string]int, len(manager.shapers))
+	for address, shaper := range manager.shapers {
+		queues[address] = shaper.Queued()
+	}
+	return queues
+}
+
+// BundleSize is the length of the bundle's CBOR representation.
+func BundleSize(bndl bpv7.Bundle) uint64 {
+	var counter byteCounter
+	_ = bndl.MarshalCbor(&counter)
+	return uint64(counter)
+}
+
+type byteCounter uint64
+
+func (counter *byteCounter) Write(p []byte) (int, error) {
+	*counter += byteCounter(len(p))
+	return len(p), nil
+}
+
+// fairRequest is a pending transmission within a fairQueue.
+type fairRequest struct {
+	class  TrafficClass
+	finish float64
+	seq    uint64
+	ready  chan struct{}
+}
+
+// fairQueue orders pending transmissions by their virtual finish time, which grows by a request's size divided by
+// its class's share. The virtual time is the finish time of the last served request.
+type fairQueue struct {
+	shares      [numTrafficClasses]float64
+	lastFinish  [numTrafficClasses]float64
+	virtualTime float64
+	seq         uint64
+	requests    []*fairRequest
+}
+
+func newFairQueue(config ShapingConfig) (fq fairQueue) {
+	for class := range fq.shares {
+		fq.shares[class] = config.share(TrafficClass(class))
+	}
+	return
+}
+
+// push enqueues a request and returns its channel.
+func (fq *fairQueue) push(class TrafficClass, size uint64) chan struct{} {
+	req := &fairRequest{
+		class:  class,
+		finish: max(fq.virtualTime, fq.lastFinish[class]) + float64(size)/fq.shares[class],
+		seq:    fq.seq,
+		ready:  make(chan struct{}),
+	}
+	fq.seq++
+	fq.lastFinish[class] = req.finish
+	heap.Push(fq, req)
+	return req.ready
+}
+
+// pop dequeues the next request to be served and returns its channel.
+func (fq *fairQueue) pop() chan struct{} {
+	req := heap.Pop(fq).(*fairRequest)
+	fq.virtualTime = req.finish
+	return req.ready
+}
+
+// remove dequeues a request without serving it, e.g., a cancelled one. Its share of the virtual time is not given
+// back to its class. It returns false if the request is not enqueued.
+func (fq *fairQueue) remove(ready <-chan struct{}) bool {
+	for i, req := range fq.requests {
+		if req.ready == ready {
+			heap.Remove(fq, i)
+			return true
+		}
+	}
+	return false
+}
+
+// The following methods implement heap.Interface and should not be called directly.
+
+func (fq *fairQueue) Len() int { return len(fq.requests) }
+
+func (fq *fairQueue) Less(i, j int) bool {
+	ri, rj := fq.requests[i], fq.requests[j]
+	return ri.finish < rj.finish || (ri.finish == rj.finish && ri.seq < rj.seq)
+}
+
+func (fq *fairQueue) Swap(i, j int) { fq.requests[i], fq.requests[j] = fq.requests[j], fq.requests[i] }
+
+func (fq *fairQueue) Push(x any) { fq.requests = append(fq.requests, x.(*fairRequest)) }
+
+func (fq *fairQueue) Pop() any {
+	n := len(fq.requests)
+	req := fq.requests[n-1]
+	fq.requests = fq.requests[:n-1]
+	return req
+}
@@ -0,0 +1,140 @@
+package cla
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+func TestFairQueueShares(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		config := ShapingConfig{Rate: 1, Shares: make(map[TrafficClass]float64)}
+		for class := 0; class < numTrafficClasses; class++ {
+			config.Shares[TrafficClass(class)] = rapid.Float64Range(0.1, 10).Draw(t, fmt.Sprintf("share %v", class))
+		}
+		fq := newFairQueue(config)
+
+		// all classes are backlogged at the beginning
+		const maxSize = 1000
+		pending := [numTrafficClasses]int{}
+		requests := make(map[chan struct{}]struct {
+			class TrafficClass
+			size  uint64
+		})
+		numRequests := rapid.IntRange(1, 50).Draw(t, "number of requests")
+		for i := 0; i < numRequests; i++ {
+			for class := 0; class < numTrafficClasses; class++ {
+				size := rapid.Uint64Range(1, maxSize).Draw(t, fmt.Sprintf("size %v %v", i, class))
+				ready := fq.push(TrafficClass(class), size)
+				requests[ready] = struct {
+					class TrafficClass
+					size  uint64
+				}{TrafficClass(class), size}
+				pending[class]++
+			}
+		}
+
+		served := [numTrafficClasses]float64{}
+		for fq.Len() > 0 {
+			req := requests[fq.pop()]
+			served[req.class] += float64(req.size)
+			pending[req.class]--
+
+			for i := 0; i < numTrafficClasses; i++ {
+				for j := 0; j < numTrafficClasses; j++ {
+					if pending[i] == 0 || pending[j] == 0 {
+						continue
+					}
+
+					shareI, shareJ := config.share(TrafficClass(i)), config.share(TrafficClass(j))
+					diff := math.Abs(served[i]/shareI - served[j]/shareJ)
+					if bound := maxSize/shareI + maxSize/shareJ; diff > bound+1e-6 {
+						t.Fatalf("Normalised service of classes %v and %v differs by %v, more than %v", i, j, diff, bound)
+					}
+				}
+			}
+		}
+	})
+}
+
+func TestClassifyBundle(t *testing.T) {
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class := ClassifyBundle(bndl); class != UserTraffic {
+		t.Fatalf("Bundle classified as %v", class)
+	}
+
+	if err := bndl.AddExtensionBlock(bpv7.NewCanonicalBlock(0, 0, bpv7.NewProphetBlock(nil))); err != nil {
+		t.Fatal(err)
+	}
+	if class := ClassifyBundle(bndl); class != RoutingTraffic {
+		t.Fatalf("Bundle with PRoPHET block classified as %v", class)
+	}
+
+	report, err := bpv7.Builder().
+		Source("dtn://dst/").
+		Destination("dtn://src/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		StatusReport(bndl, bpv7.ReceivedBundle, bpv7.NoInformation).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class := ClassifyBundle(report); class != AdministrativeTraffic {
+		t.Fatalf("Status report classified as %v", class)
+	}
+}
+
+func TestShaperCancelsExpired(t *testing.T) {
+	fc := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another transmission keeps the link busy until the bundle expired
+	shaper := NewShaper(ShapingConfig{Rate: 1})
+	<-shaper.acquire(UserTraffic, 1)
+
+	result := make(chan error)
+	go func() { result <- shaper.Send(nil, bndl) }()
+
+	fc.BlockUntil(1)
+	fc.Advance(time.Hour + time.Second)
+	if err := <-result; !errors.Is(err, ErrBundleExpired) {
+		t.Fatalf("Sending an expired bundle resulted in %v", err)
+	}
+
+	if n := shaper.queue.Len(); n != 0 {
+		t.Fatalf("Cancelled transmission left %d queued requests", n)
+	}
+	shaper.release()
+	if shaper.busy {
+		t.Fatal("Shaper is busy after the last transmission")
+	}
+}
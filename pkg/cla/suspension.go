@@ -0,0 +1,239 @@
+package cla
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// SetSuspension enables suspending senders which have not sent any bundle for the idle duration, saving battery and
+// airtime on mobile nodes. A suspended sender's connection is closed, but its peer stays reachable for the routing.
+// The next bundle to this peer re-dials the connection. A non-positive idle duration disables suspension.
+//
+// Only senders implementing Redialer are suspended.
+// This method is thread-safe.
+func (manager *Manager) SetSuspension(idle time.Duration) {
+	manager.suspensionMutex.Lock()
+	defer manager.suspensionMutex.Unlock()
+
+	if manager.suspensionStop != nil {
+		close(manager.suspensionStop)
+		manager.suspensionStop = nil
+	}
+	if idle <= 0 {
+		return
+	}
+
+	manager.suspensionStop = make(chan struct{})
+	go manager.suspensionLoop(idle, manager.suspensionStop)
+}
+
+func (manager *Manager) suspensionLoop(idle time.Duration, stop <-chan struct{}) {
+	ticker := clock.NewTicker(idle / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C():
+			manager.suspendIdleSenders(idle)
+		}
+	}
+}
+
+// touch records a sender's activity, postponing its suspension.
+func (manager *Manager) touch(address string) {
+	manager.suspensionMutex.Lock()
+	defer manager.suspensionMutex.Unlock()
+
+	manager.lastActivity[address] = clock.Now()
+}
+
+// idleFor checks if a sender was inactive for the idle duration. A sender without any recorded activity becomes
+// active now, as it might have just been registered.
+func (manager *Manager) idleFor(address string, idle time.Duration) bool {
+	manager.suspensionMutex.Lock()
+	defer manager.suspensionMutex.Unlock()
+
+	now := clock.Now()
+	last, ok := manager.lastActivity[address]
+	if !ok {
+		manager.lastActivity[address] = now
+		return false
+	}
+	return now.Sub(last) >= idle
+}
+
+// suspendIdleSenders suspends all Redialers which were idle for too long.
+func (manager *Manager) suspendIdleSenders(idle time.Duration) {
+	for _, sender := range manager.GetSenders() {
+		if redialer, ok := sender.(Redialer); ok && manager.idleFor(sender.Address(), idle) {
+			manager.suspend(redialer)
+		}
+	}
+}
+
+// suspend replaces a sender by a suspendedSender and closes it. The disconnect of the sender is not passed on.
+func (manager *Manager) suspend(sender Redialer) {
+	log.WithField("cla", sender).Info("Suspending idle CLA")
+
+	placeholder := &suspendedSender{manager: manager, redialer: sender}
+
+	manager.stateMutex.Lock()
+	senders := make([]ConvergenceSender, 0, len(manager.senders))
+	for _, registeredSender := range manager.senders {
+		if registeredSender == sender {
+			senders = append(senders, placeholder)
+		} else {
+			senders = append(senders, registeredSender)
+		}
+	}
+	manager.senders = senders
+
+	receivers := make([]ConvergenceReceiver, 0, len(manager.receivers))
+	for _, registeredReceiver := range manager.receivers {
+		if Convergence(registeredReceiver) != Convergence(sender) {
+			receivers = append(receivers, registeredReceiver)
+		}
+	}
+	manager.receivers = receivers
+	manager.stateMutex.Unlock()
+
+	manager.suspensionMutex.Lock()
+	manager.suspending[sender.Address()] = true
+	manager.suspensionMutex.Unlock()
+
+	if err := sender.Close(); err != nil {
+		log.WithFields(log.Fields{
+			"cla":   sender,
+			"error": err,
+		}).Debug("Error closing suspended CLA")
+	}
+
+	manager.suspensionMutex.Lock()
+	delete(manager.suspending, sender.Address())
+	manager.suspensionMutex.Unlock()
+}
+
+// isSuspending checks if the CLA's disconnect results from its suspension and must not be passed on.
+func (manager *Manager) isSuspending(cla Convergence) bool {
+	if _, isPlaceholder := cla.(*suspendedSender); isPlaceholder {
+		return false
+	}
+
+	manager.suspensionMutex.Lock()
+	defer manager.suspensionMutex.Unlock()
+
+	return manager.suspending[cla.Address()]
+}
+
+// isResuming checks if a peer's connect results from resuming a suspended sender and must not be passed on.
+// The peer is forgotten afterwards, as each resumption connects once.
+func (manager *Manager) isResuming(peerID bpv7.EndpointID) bool {
+	manager.suspensionMutex.Lock()
+	defer manager.suspensionMutex.Unlock()
+
+	resuming := manager.resuming[peerID]
+	delete(manager.resuming, peerID)
+	return resuming
+}
+
+// resume a suspendedSender by activating a re-dialed sender in its place.
+func (manager *Manager) resume(placeholder *suspendedSender) (ConvergenceSender, error) {
+	sender := placeholder.redialer.Redial()
+	peerID := sender.GetPeerEndpointID()
+
+	manager.suspensionMutex.Lock()
+	manager.resuming[peerID] = true
+	manager.suspensionMutex.Unlock()
+
+	if err := sender.Activate(); err != nil {
+		manager.suspensionMutex.Lock()
+		delete(manager.resuming, peerID)
+		manager.suspensionMutex.Unlock()
+
+		// The peer is gone for real
+		manager.NotifyDisconnect(placeholder)
+		return nil, err
+	}
+
+	log.WithField("cla", sender).Info("Resumed suspended CLA")
+
+	manager.stateMutex.Lock()
+	senders := make([]ConvergenceSender, 0, len(manager.senders))
+	for _, registeredSender := range manager.senders {
+		if registeredSender == ConvergenceSender(placeholder) {
+			senders = append(senders, sender)
+		} else {
+			senders = append(senders, registeredSender)
+		}
+	}
+	manager.senders = senders
+	if receiver, ok := sender.(ConvergenceReceiver); ok {
+		manager.receivers = append(manager.receivers, receiver)
+	}
+	manager.stateMutex.Unlock()
+
+	manager.touch(sender.Address())
+	return sender, nil
+}
+
+// suspendedSender stands in for a suspended Redialer. Its peer is still reachable and the first bundle sent through
+// it resumes the connection.
+type suspendedSender struct {
+	manager  *Manager
+	redialer Redialer
+
+	mutex   sync.Mutex
+	resumed ConvergenceSender
+}
+
+// Send resumes the suspended connection, only once for concurrent calls, and sends the bundle through it.
+func (s *suspendedSender) Send(bndl bpv7.Bundle) error {
+	s.mutex.Lock()
+	if s.resumed == nil {
+		sender, err := s.manager.resume(s)
+		if err != nil {
+			s.mutex.Unlock()
+			return err
+		}
+		s.resumed = sender
+	}
+	sender := s.resumed
+	s.mutex.Unlock()
+
+	return sender.Send(bndl)
+}
+
+func (s *suspendedSender) GetPeerEndpointID() bpv7.EndpointID {
+	return s.redialer.GetPeerEndpointID()
+}
+
+// Close is a no-op, as the suspended connection was already closed.
+func (s *suspendedSender) Close() error {
+	return nil
+}
+
+// Activate is a no-op, the connection is resumed on demand by Send.
+func (s *suspendedSender) Activate() error {
+	return nil
+}
+
+// Active is true, as a suspended peer is still considered reachable.
+func (s *suspendedSender) Active() bool {
+	return true
+}
+
+func (s *suspendedSender) Address() string {
+	return s.redialer.Address()
+}
+
+func (s *suspendedSender) String() string {
+	return fmt.Sprintf("suspended %v", s.redialer)
+}
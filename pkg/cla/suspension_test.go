@@ -0,0 +1,139 @@
+package cla
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// redialSender is a Redialer which notifies the Manager like a dialing client.
+type redialSender struct {
+	manager *Manager
+	address string
+	peer    bpv7.EndpointID
+
+	mutex   sync.Mutex
+	sent    int
+	closed  bool
+	redials *int
+}
+
+func (s *redialSender) Send(bpv7.Bundle) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sent++
+	return nil
+}
+
+func (s *redialSender) GetPeerEndpointID() bpv7.EndpointID { return s.peer }
+func (s *redialSender) Address() string                    { return s.address }
+func (s *redialSender) Active() bool                       { return !s.closed }
+func (s *redialSender) String() string                     { return s.address }
+
+func (s *redialSender) Activate() error {
+	s.manager.NotifyConnect(s.peer)
+	return nil
+}
+
+func (s *redialSender) Close() error {
+	s.closed = true
+	s.manager.NotifyDisconnect(s)
+	return nil
+}
+
+func (s *redialSender) Redial() ConvergenceSender {
+	*s.redials++
+	return &redialSender{manager: s.manager, address: s.address, peer: s.peer, redials: s.redials}
+}
+
+func TestSuspension(t *testing.T) {
+	fc := clock.NewFakeClock(time.Unix(0, 0))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	connects := make(chan bpv7.EndpointID, 10)
+	disconnects := make(chan bpv7.EndpointID, 10)
+	err := InitialiseCLAManager(func(*bpv7.Bundle) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	manager := GetManagerSingleton()
+	manager.Events().Subscribe(func(ev Event) { connects <- ev.Peer }, PeerConnected)
+	manager.Events().Subscribe(func(ev Event) { disconnects <- ev.Peer }, PeerDisconnected)
+
+	redials := 0
+	sender := &redialSender{
+		manager: manager,
+		address: "redial:1",
+		peer:    bpv7.MustNewEndpointID("dtn://peer/"),
+		redials: &redials,
+	}
+	manager.senders = []ConvergenceSender{sender}
+
+	// The first check only records the new sender
+	manager.suspendIdleSenders(time.Minute)
+	fc.Advance(30 * time.Second)
+	manager.suspendIdleSenders(time.Minute)
+	if sender.closed {
+		t.Fatal("Sender was suspended before being idle")
+	}
+
+	fc.Advance(time.Minute)
+	manager.suspendIdleSenders(time.Minute)
+	if !sender.closed {
+		t.Fatal("Idle sender was not suspended")
+	}
+
+	senders := manager.GetSenders()
+	if len(senders) != 1 {
+		t.Fatalf("Manager has %d instead of one sender", len(senders))
+	}
+	placeholder, ok := senders[0].(*suspendedSender)
+	if !ok {
+		t.Fatalf("Sender %v is no suspended placeholder", senders[0])
+	}
+	if !placeholder.Active() || placeholder.GetPeerEndpointID() != sender.peer {
+		t.Fatalf("Placeholder %v does not represent the reachable peer", placeholder)
+	}
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://peer/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Send(placeholder, bndl); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Send(placeholder, bndl); err != nil {
+		t.Fatal(err)
+	}
+	if redials != 1 {
+		t.Fatalf("Sender was re-dialed %d times instead of once", redials)
+	}
+
+	senders = manager.GetSenders()
+	resumed, ok := senders[0].(*redialSender)
+	if len(senders) != 1 || !ok || resumed == sender {
+		t.Fatalf("Senders %v do not contain the re-dialed sender", senders)
+	}
+	if resumed.sent != 2 {
+		t.Fatalf("Re-dialed sender sent %d instead of two bundles", resumed.sent)
+	}
+
+	select {
+	case eid := <-connects:
+		t.Fatalf("Resumption was reported as connect of %v", eid)
+	case eid := <-disconnects:
+		t.Fatalf("Suspension was reported as disconnect of %v", eid)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,94 @@
+package tcpclv3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv6"
+)
+
+// contactMagic starts each contact header.
+var contactMagic = []byte("dtn!")
+
+const contactVersion = 0x03
+
+// ContactFlags of the contact header, RFC 7242 section 4.1.
+type ContactFlags uint8
+
+const (
+	RequestAcks                  ContactFlags = 0x01
+	RequestReactiveFragmentation ContactFlags = 0x02
+	SupportRefusal               ContactFlags = 0x04
+	RequestLength                ContactFlags = 0x08
+)
+
+// Has returns true if a given flag or mask of flags is set.
+func (cf ContactFlags) Has(flag ContactFlags) bool {
+	return (cf & flag) != 0
+}
+
+// ContactHeader is exchanged by both peers when the connection is established.
+type ContactHeader struct {
+	Flags ContactFlags
+	// KeepaliveInterval in seconds, zero disables keepalives.
+	KeepaliveInterval uint16
+	EndpointID        string
+}
+
+// maxEndpointIDLength limits the peer's endpoint ID to be read.
+const maxEndpointIDLength = 1024
+
+// ReadContactHeader reads a peer's ContactHeader.
+func ReadContactHeader(r *bufio.Reader) (ch ContactHeader, err error) {
+	fixed := make([]byte, len(contactMagic)+4)
+	if _, err = io.ReadFull(r, fixed); err != nil {
+		return
+	}
+
+	if !bytes.Equal(fixed[:len(contactMagic)], contactMagic) {
+		err = fmt.Errorf("contact header starts with %x instead of magic", fixed[:len(contactMagic)])
+		return
+	}
+	if version := fixed[len(contactMagic)]; version != contactVersion {
+		err = fmt.Errorf("contact header has version %d instead of %d", version, contactVersion)
+		return
+	}
+
+	ch.Flags = ContactFlags(fixed[len(contactMagic)+1])
+	ch.KeepaliveInterval = binary.BigEndian.Uint16(fixed[len(contactMagic)+2:])
+
+	eidLength, err := bpv6.ReadSDNV(r)
+	if err != nil {
+		return
+	}
+	if eidLength > maxEndpointIDLength {
+		err = fmt.Errorf("contact header's endpoint ID of %d bytes exceeds %d bytes", eidLength, maxEndpointIDLength)
+		return
+	}
+
+	eid := make([]byte, eidLength)
+	if _, err = io.ReadFull(r, eid); err != nil {
+		return
+	}
+	ch.EndpointID = string(eid)
+	return
+}
+
+// Write this ContactHeader.
+func (ch ContactHeader) Write(w io.Writer) error {
+	buff := new(bytes.Buffer)
+	buff.Write(contactMagic)
+	buff.WriteByte(contactVersion)
+	buff.WriteByte(byte(ch.Flags))
+	_ = binary.Write(buff, binary.BigEndian, ch.KeepaliveInterval)
+	if err := bpv6.WriteSDNV(uint64(len(ch.EndpointID)), buff); err != nil {
+		return err
+	}
+	buff.WriteString(ch.EndpointID)
+
+	_, err := w.Write(buff.Bytes())
+	return err
+}
@@ -0,0 +1,21 @@
+/*
+Package tcpclv3 implements a receive-only TCP Convergence Layer Protocol Version 3, as specified in RFC 7242.
+
+Why?
+Legacy DTN implementations, e.g., DTN2 or IBR-DTN, speak BPv6 over TCPCLv3. A TCPCLv3Server lets those nodes inject
+their traffic into a BPv7 network, e.g., during a migration.
+
+Protocol
+After both contact headers were exchanged, the peer sends bundles as sequences of DATA_SEGMENT messages, which are
+acknowledged if the peer requested so. The bundle protocol version is negotiated per bundle by its first byte:
+BPv6 bundles are converted to BPv7 by the bpv6 package, BPv7 bundles are passed as they are. BPv6 bundles which cannot
+be converted without losing information, e.g., requesting custody transfer, are dropped.
+
+Bundle refusal
+If an admission function is set, e.g., the node's congestion policy, each bundle is checked before its last
+DATA_SEGMENT is acknowledged. A refused bundle is answered by a REFUSE_BUNDLE message with the "receiver's resources
+exhausted" reason instead, if the peer supports bundle refusal. Otherwise, it is acknowledged and dropped silently.
+
+The server never sends bundles, reactive fragmentation is not supported.
+*/
+package tcpclv3
@@ -0,0 +1,60 @@
+package tcpclv3
+
+import (
+	"io"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv6"
+)
+
+// messageType is the upper nibble of each message's first byte, RFC 7242 section 5.
+type messageType uint8
+
+const (
+	dataSegment  messageType = 0x1
+	ackSegment   messageType = 0x2
+	refuseBundle messageType = 0x3
+	keepalive    messageType = 0x4
+	shutdown     messageType = 0x5
+	length       messageType = 0x6
+)
+
+// Flags of a DATA_SEGMENT message, the lower nibble of its first byte.
+const (
+	segmentEnd   uint8 = 0x1
+	segmentStart uint8 = 0x2
+)
+
+// Reason codes of a REFUSE_BUNDLE message, its header's flags, RFC 7242 section 5.3.
+const (
+	refuseUnknown            uint8 = 0x0
+	refuseCompletelyReceived uint8 = 0x1
+	refuseResourcesExhausted uint8 = 0x2
+	refuseRetransmit         uint8 = 0x3
+)
+
+// Flags of a SHUTDOWN message.
+const (
+	shutdownDelay  uint8 = 0x1
+	shutdownReason uint8 = 0x2
+)
+
+func messageHeader(msgType messageType, flags uint8) byte {
+	return byte(msgType)<<4 | flags&0x0f
+}
+
+// writeSDNVMessage writes a message consisting of its header and a single SDNV, e.g., an ACK_SEGMENT.
+func writeSDNVMessage(w io.Writer, msgType messageType, flags uint8, n uint64) error {
+	if _, err := w.Write([]byte{messageHeader(msgType, flags)}); err != nil {
+		return err
+	}
+	return bpv6.WriteSDNV(n, w)
+}
+
+// writeDataSegment writes a DATA_SEGMENT message.
+func writeDataSegment(w io.Writer, flags uint8, data []byte) error {
+	if err := writeSDNVMessage(w, dataSegment, flags, uint64(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
@@ -0,0 +1,407 @@
+package tcpclv3
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv6"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+const (
+	// MaxBundleSize limits the size of a bundle assembled from a peer's DATA_SEGMENTs.
+	MaxBundleSize = 256 << 20
+
+	// keepaliveInterval is offered in the own contact header, in seconds. The smaller of both peers' intervals is used.
+	keepaliveInterval uint16 = 15
+
+	// contactTimeout limits how long a peer might take to send its contact header.
+	contactTimeout = 30 * time.Second
+)
+
+// TCPCLv3Server accepts TCPCLv3 connections from legacy nodes and passes their bundles, converted to BPv7 if
+// necessary, to the receive callback. This struct implements a ConvergenceListener and ConvergenceReceiver.
+type TCPCLv3Server struct {
+	listenAddress string
+	endpointID    bpv7.EndpointID
+
+	receiveCallback func(*bpv7.Bundle)
+	// admission decides on each received bundle before it is acknowledged, see SetAdmission
+	admission func(*bpv7.Bundle) error
+
+	stateMutex sync.Mutex
+	running    bool
+	listener   net.Listener
+	conns      map[net.Conn]struct{}
+}
+
+// NewTCPCLv3Server creates a new TCPCLv3Server for the given listen address, e.g., ":4556".
+func NewTCPCLv3Server(listenAddress string, endpointID bpv7.EndpointID, receiveCallback func(*bpv7.Bundle)) *TCPCLv3Server {
+	return &TCPCLv3Server{
+		listenAddress:   listenAddress,
+		endpointID:      endpointID,
+		receiveCallback: receiveCallback,
+		conns:           make(map[net.Conn]struct{}),
+	}
+}
+
+// SetAdmission configures a function to decide on each received bundle before its last DATA_SEGMENT is acknowledged,
+// e.g., by a congestion policy. A bundle resulting in an error is dropped and, if the peer supports bundle refusal,
+// refused with a REFUSE_BUNDLE message signalling exhausted resources. This must be called before Start.
+func (serv *TCPCLv3Server) SetAdmission(admission func(*bpv7.Bundle) error) {
+	serv.admission = admission
+}
+
+// Start listening for connections.
+func (serv *TCPCLv3Server) Start() error {
+	serv.stateMutex.Lock()
+	defer serv.stateMutex.Unlock()
+
+	if serv.running {
+		return fmt.Errorf("TCPCLv3Server %s was already started", serv.listenAddress)
+	}
+
+	ln, err := net.Listen("tcp", serv.listenAddress)
+	if err != nil {
+		return err
+	}
+
+	serv.listener = ln
+	serv.running = true
+
+	go serv.accept(ln)
+	return nil
+}
+
+func (serv *TCPCLv3Server) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.WithFields(log.Fields{
+					"cla":   serv,
+					"error": err,
+				}).Warn("TCPCLv3Server failed to accept, stopping")
+			}
+			return
+		}
+
+		serv.stateMutex.Lock()
+		if !serv.running {
+			serv.stateMutex.Unlock()
+			_ = conn.Close()
+			return
+		}
+		serv.conns[conn] = struct{}{}
+		serv.stateMutex.Unlock()
+
+		go serv.handleConn(conn)
+	}
+}
+
+// session of a single connection, after the contact headers were exchanged.
+type session struct {
+	serv *TCPCLv3Server
+	conn net.Conn
+	peer string
+
+	acks        bool
+	refusal     bool
+	idleTimeout time.Duration
+
+	writeMutex sync.Mutex
+}
+
+func (serv *TCPCLv3Server) handleConn(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+
+		serv.stateMutex.Lock()
+		delete(serv.conns, conn)
+		serv.stateMutex.Unlock()
+	}()
+
+	s, r, err := serv.contact(conn)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"cla":   serv,
+			"conn":  conn.RemoteAddr(),
+			"error": err,
+		}).Warn("TCPCLv3Server failed to exchange contact headers")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"cla":     serv,
+		"conn":    conn.RemoteAddr(),
+		"peer":    s.peer,
+		"acks":    s.acks,
+		"refusal": s.refusal,
+	}).Info("TCPCLv3Server established a session")
+
+	if s.idleTimeout > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go s.sendKeepalives(s.idleTimeout/2, done)
+	}
+
+	if err := s.receive(r); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+		log.WithFields(log.Fields{
+			"cla":   serv,
+			"peer":  s.peer,
+			"error": err,
+		}).Warn("TCPCLv3Server's session failed")
+	}
+}
+
+// contact exchanges the contact headers and negotiates the session's parameters.
+func (serv *TCPCLv3Server) contact(conn net.Conn) (*session, *bufio.Reader, error) {
+	own := ContactHeader{
+		Flags:             RequestAcks | SupportRefusal,
+		KeepaliveInterval: keepaliveInterval,
+		EndpointID:        serv.endpointID.String(),
+	}
+	if err := own.Write(conn); err != nil {
+		return nil, nil, err
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(contactTimeout)); err != nil {
+		return nil, nil, err
+	}
+	r := bufio.NewReader(conn)
+	peer, err := ReadContactHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, nil, err
+	}
+
+	interval := min(own.KeepaliveInterval, peer.KeepaliveInterval)
+	acks := peer.Flags.Has(RequestAcks)
+	return &session{
+		serv: serv,
+		conn: conn,
+		peer: peer.EndpointID,
+		acks: acks,
+		// Bundle refusal requires both peers to support it and acknowledgements to be enabled, RFC 7242 section 4.1.
+		refusal:     acks && peer.Flags.Has(SupportRefusal),
+		idleTimeout: 2 * time.Duration(interval) * time.Second,
+	}, r, nil
+}
+
+// sendKeepalives until done is closed.
+func (s *session) sendKeepalives(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := s.write(messageHeader(keepalive, 0)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *session) write(data ...byte) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	_, err := s.conn.Write(data)
+	return err
+}
+
+func (s *session) writeAck(acknowledged uint64) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	return writeSDNVMessage(s.conn, ackSegment, 0, acknowledged)
+}
+
+// receive messages until the peer shuts the session down or an error occurs.
+func (s *session) receive(r *bufio.Reader) error {
+	var bundleBuff *bytes.Buffer
+
+	for {
+		if s.idleTimeout > 0 {
+			if err := s.conn.SetReadDeadline(time.Now().Add(s.idleTimeout)); err != nil {
+				return err
+			}
+		}
+
+		header, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		msgType, flags := messageType(header>>4), header&0x0f
+
+		switch msgType {
+		case dataSegment:
+			n, err := bpv6.ReadSDNV(r)
+			if err != nil {
+				return err
+			}
+
+			if flags&segmentStart != 0 {
+				bundleBuff = new(bytes.Buffer)
+			} else if bundleBuff == nil {
+				return fmt.Errorf("DATA_SEGMENT continues no bundle")
+			}
+			if uint64(bundleBuff.Len())+n > MaxBundleSize {
+				return fmt.Errorf("bundle exceeds %d bytes", MaxBundleSize)
+			}
+			if _, err := io.CopyN(bundleBuff, r, int64(n)); err != nil {
+				return err
+			}
+
+			// The last segment is acknowledged after the bundle was admitted, or otherwise refused.
+			refused := false
+			if flags&segmentEnd != 0 {
+				refused = !s.handleBundle(bundleBuff.Bytes())
+			}
+			if refused && s.refusal {
+				if err := s.write(messageHeader(refuseBundle, refuseResourcesExhausted)); err != nil {
+					return err
+				}
+			} else if s.acks {
+				if err := s.writeAck(uint64(bundleBuff.Len())); err != nil {
+					return err
+				}
+			}
+			if flags&segmentEnd != 0 {
+				bundleBuff = nil
+			}
+
+		case ackSegment, length:
+			if _, err := bpv6.ReadSDNV(r); err != nil {
+				return err
+			}
+
+		case refuseBundle, keepalive:
+
+		case shutdown:
+			if flags&shutdownReason != 0 {
+				if _, err := r.ReadByte(); err != nil {
+					return err
+				}
+			}
+			if flags&shutdownDelay != 0 {
+				if _, err := bpv6.ReadSDNV(r); err != nil {
+					return err
+				}
+			}
+
+			log.WithFields(log.Fields{
+				"cla":  s.serv,
+				"peer": s.peer,
+			}).Info("TCPCLv3Server's peer shut down the session")
+			return nil
+
+		default:
+			return fmt.Errorf("unknown message type 0x%x", uint8(msgType))
+		}
+	}
+}
+
+// handleBundle parses a received bundle of either version and passes it on as a BPv7 bundle. It returns false if the
+// bundle was refused by the admission, but true for a bundle dropped because it cannot be ingested at all.
+func (s *session) handleBundle(data []byte) (admitted bool) {
+	var bndl bpv7.Bundle
+	var err error
+
+	if bpv6.IsBPv6(data) {
+		var bndl6 bpv6.Bundle
+		if bndl6, err = bpv6.ParseBundle(bytes.NewReader(data)); err == nil {
+			bndl, err = bndl6.ToBPv7()
+		}
+	} else {
+		bndl, err = bpv7.ParseBundle(bytes.NewReader(data))
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"cla":   s.serv,
+			"peer":  s.peer,
+			"error": err,
+		}).Warn("TCPCLv3Server dropped a bundle which cannot be ingested")
+		return true
+	}
+
+	if s.serv.admission != nil {
+		if err := s.serv.admission(&bndl); err != nil {
+			log.WithFields(log.Fields{
+				"cla":     s.serv,
+				"peer":    s.peer,
+				"bundle":  bndl.ID(),
+				"refusal": s.refusal,
+				"error":   err,
+			}).Info("TCPCLv3Server refused a bundle")
+			return false
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"cla":    s.serv,
+		"peer":   s.peer,
+		"bundle": bndl.ID(),
+	}).Debug("TCPCLv3Server received a bundle")
+	s.serv.receiveCallback(&bndl)
+	return true
+}
+
+// Close stops listening and closes all connections. Calling Close multiple times is safe.
+func (serv *TCPCLv3Server) Close() error {
+	serv.stateMutex.Lock()
+	defer serv.stateMutex.Unlock()
+
+	if !serv.running {
+		return nil
+	}
+	serv.running = false
+
+	errs := []error{serv.listener.Close()}
+	for conn := range serv.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (serv *TCPCLv3Server) GetEndpointID() bpv7.EndpointID {
+	return serv.endpointID
+}
+
+func (serv *TCPCLv3Server) Address() string {
+	return fmt.Sprintf("tcpclv3://%s", serv.listenAddress)
+}
+
+func (serv *TCPCLv3Server) String() string {
+	return serv.Address()
+}
+
+// Activate is a no-op, because the TCPCLv3Server is started as a ConvergenceListener.
+func (serv *TCPCLv3Server) Activate() error {
+	return nil
+}
+
+func (serv *TCPCLv3Server) Active() bool {
+	return serv.Running()
+}
+
+func (serv *TCPCLv3Server) Running() bool {
+	serv.stateMutex.Lock()
+	defer serv.stateMutex.Unlock()
+
+	return serv.running
+}
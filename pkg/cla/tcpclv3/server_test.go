@@ -0,0 +1,258 @@
+package tcpclv3
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv6"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func getRandomPort(t *testing.T) int {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestContactHeaderRoundTrip(t *testing.T) {
+	ch := ContactHeader{
+		Flags:             RequestAcks | RequestLength,
+		KeepaliveInterval: 300,
+		EndpointID:        "dtn://legacy",
+	}
+
+	buff := new(bytes.Buffer)
+	if err := ch.Write(buff); err != nil {
+		t.Fatal(err)
+	}
+
+	if ch2, err := ReadContactHeader(bufio.NewReader(buff)); err != nil {
+		t.Fatal(err)
+	} else if ch != ch2 {
+		t.Fatalf("Contact header changed from %v to %v", ch, ch2)
+	}
+
+	invalid := []byte("dtn!\x04\x00\x00\x00\x00")
+	if _, err := ReadContactHeader(bufio.NewReader(bytes.NewReader(invalid))); err == nil {
+		t.Fatal("Contact header of version 4 was read")
+	}
+}
+
+func legacyBundle(payload string, flags bpv6.ProcessingFlags) []byte {
+	b := bpv6.Bundle{
+		PrimaryBlock: bpv6.PrimaryBlock{
+			Flags:        flags | bpv6.DestinationIsSingleton | 1<<7,
+			Destination:  "dtn://dst/app",
+			Source:       "dtn://legacy/app",
+			ReportTo:     "dtn:none",
+			Custodian:    "dtn:none",
+			CreationTime: uint64(bpv7.DtnTimeNow()) / 1000,
+			Lifetime:     3600,
+		},
+		CanonicalBlocks: []bpv6.CanonicalBlock{{Type: bpv6.PayloadBlockType, Data: []byte(payload)}},
+	}
+
+	buff := new(bytes.Buffer)
+	_ = b.WriteBundle(buff)
+	return buff.Bytes()
+}
+
+func TestServerIngest(t *testing.T) {
+	received := make(chan *bpv7.Bundle, 10)
+	address := fmt.Sprintf("localhost:%d", getRandomPort(t))
+	serv := NewTCPCLv3Server(address, bpv7.MustNewEndpointID("dtn://ingest/"), func(bndl *bpv7.Bundle) {
+		received <- bndl
+	})
+	if err := serv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer serv.Close()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	if err := (ContactHeader{Flags: RequestAcks, KeepaliveInterval: 0, EndpointID: "dtn://legacy"}).Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	if ch, err := ReadContactHeader(r); err != nil {
+		t.Fatal(err)
+	} else if ch.EndpointID != "dtn://ingest/" {
+		t.Fatalf("Server announced %q", ch.EndpointID)
+	}
+
+	bndl7, err := bpv7.Builder().
+		Source("dtn://new/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("5m").
+		PayloadBlock([]byte("bpv7")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bndl7Buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&bndl7, bndl7Buff); err != nil {
+		t.Fatal(err)
+	}
+
+	custody := legacyBundle("custody", bpv6.CustodyTransferRequested)
+
+	for _, data := range [][]byte{legacyBundle("bpv6", 0), custody, bndl7Buff.Bytes()} {
+		// Two segments, each to be acknowledged cumulatively
+		half := len(data) / 2
+		if err := writeDataSegment(conn, segmentStart, data[:half]); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeDataSegment(conn, segmentEnd, data[half:]); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expected := range []int{half, len(data)} {
+			if header, err := r.ReadByte(); err != nil {
+				t.Fatal(err)
+			} else if messageType(header>>4) != ackSegment {
+				t.Fatalf("Expected ACK_SEGMENT, got 0x%x", header)
+			}
+			if n, err := bpv6.ReadSDNV(r); err != nil {
+				t.Fatal(err)
+			} else if n != uint64(expected) {
+				t.Fatalf("Acknowledged %d instead of %d bytes", n, expected)
+			}
+		}
+	}
+
+	// The BPv6 bundle requesting custody transfer must be dropped
+	for _, expected := range []string{"bpv6", "bpv7"} {
+		select {
+		case bndl := <-received:
+			payload, err := bndl.PayloadBlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data := payload.Value.(*bpv7.PayloadBlock).Data(); string(data) != expected {
+				t.Fatalf("Received %q instead of %q", data, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Bundle %q was not received", expected)
+		}
+	}
+
+	if _, err := conn.Write([]byte{messageHeader(shutdown, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadByte(); err == nil {
+		t.Fatal("Server did not close the connection after SHUTDOWN")
+	}
+}
+
+func TestServerRefusal(t *testing.T) {
+	received := make(chan *bpv7.Bundle, 10)
+	address := fmt.Sprintf("localhost:%d", getRandomPort(t))
+	serv := NewTCPCLv3Server(address, bpv7.MustNewEndpointID("dtn://ingest/"), func(bndl *bpv7.Bundle) {
+		received <- bndl
+	})
+	serv.SetAdmission(func(bndl *bpv7.Bundle) error {
+		payload, err := bndl.PayloadBlock()
+		if err != nil {
+			return err
+		}
+		if data := payload.Value.(*bpv7.PayloadBlock).Data(); string(data) == "refuse" {
+			return fmt.Errorf("refused %q", data)
+		}
+		return nil
+	})
+	if err := serv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer serv.Close()
+
+	tests := []struct {
+		name  string
+		flags ContactFlags
+		// refused indicates a REFUSE_BUNDLE instead of an ACK_SEGMENT for the refused bundle
+		refused bool
+	}{
+		{"refusal", RequestAcks | SupportRefusal, true},
+		{"no refusal", RequestAcks, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+
+			if err := (ContactHeader{Flags: test.flags, EndpointID: "dtn://legacy"}).Write(conn); err != nil {
+				t.Fatal(err)
+			}
+			if ch, err := ReadContactHeader(r); err != nil {
+				t.Fatal(err)
+			} else if !ch.Flags.Has(SupportRefusal) {
+				t.Fatalf("Server does not announce bundle refusal: %v", ch.Flags)
+			}
+
+			for _, payload := range []string{"refuse", "accept"} {
+				data := legacyBundle(payload, 0)
+				if err := writeDataSegment(conn, segmentStart|segmentEnd, data); err != nil {
+					t.Fatal(err)
+				}
+
+				header, err := r.ReadByte()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if payload == "refuse" && test.refused {
+					if header != messageHeader(refuseBundle, refuseResourcesExhausted) {
+						t.Fatalf("Expected REFUSE_BUNDLE, got 0x%x", header)
+					}
+					continue
+				}
+
+				if messageType(header>>4) != ackSegment {
+					t.Fatalf("Expected ACK_SEGMENT for %q, got 0x%x", payload, header)
+				}
+				if n, err := bpv6.ReadSDNV(r); err != nil {
+					t.Fatal(err)
+				} else if n != uint64(len(data)) {
+					t.Fatalf("Acknowledged %d instead of %d bytes", n, len(data))
+				}
+			}
+
+			select {
+			case bndl := <-received:
+				payload, err := bndl.PayloadBlock()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if data := payload.Value.(*bpv7.PayloadBlock).Data(); string(data) != "accept" {
+					t.Fatalf("Received %q instead of the accepted bundle", data)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Accepted bundle was not received")
+			}
+			select {
+			case bndl := <-received:
+				t.Fatalf("Received another bundle %v", bndl.ID())
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cla
+
+import (
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// Transmission describes an attempted transmission of a bundle over a sender, published by transfer Events.
+type Transmission struct {
+	Peer    bpv7.EndpointID
+	Address string
+	Bundle  bpv7.BundleID
+	// Bytes is the size of the bundle's CBOR representation, regardless of the transmission's success.
+	Bytes uint64
+	// Start of the transmission, including its waiting time for the traffic shaping, and its Duration.
+	Start    time.Time
+	Duration time.Duration
+	// Err is nil for a successful transmission, ErrBundleExpired for a cancelled one.
+	Err error
+}
+
+// transferEvents checks if any subscriber wants events of transfers, see Send.
+func (manager *Manager) transferEvents() bool {
+	return manager.events.HasSubscribers(TransferStarted) || manager.events.HasSubscribers(TransferCompleted) ||
+		manager.events.HasSubscribers(TransferFailed)
+}
+
+// startTransmission publishes a TransferStarted Event. It returns nil if nobody is interested in transfers, sparing
+// the calculation of the bundle's size.
+func (manager *Manager) startTransmission(sender ConvergenceSender, bndl bpv7.Bundle) *Transmission {
+	if !manager.transferEvents() {
+		return nil
+	}
+
+	transmission := Transmission{
+		Peer:    sender.GetPeerEndpointID(),
+		Address: sender.Address(),
+		Bundle:  bndl.ID(),
+		Bytes:   BundleSize(bndl),
+		Start:   clock.Now(),
+	}
+	started := transmission
+	manager.events.Publish(Event{Type: TransferStarted, Peer: started.Peer, Address: started.Address, Transmission: &started})
+	return &transmission
+}
+
+// finishTransmission publishes a started transmission's end as a TransferCompleted or TransferFailed Event.
+func (manager *Manager) finishTransmission(transmission *Transmission, err error) {
+	if transmission == nil {
+		return
+	}
+
+	transmission.Duration = clock.Now().Sub(transmission.Start)
+	transmission.Err = err
+
+	eventType := TransferCompleted
+	if err != nil {
+		eventType = TransferFailed
+	}
+	manager.events.Publish(Event{
+		Type:         eventType,
+		Peer:         transmission.Peer,
+		Address:      transmission.Address,
+		Transmission: transmission,
+		Err:          err,
+	})
+}
@@ -0,0 +1,266 @@
+// Package client is a Go client of a dtnd node's RESTful Application Agent, see application_agent.RestAgent.
+//
+// Applications register an endpoint, send bundles, receive the bundles delivered to them and query the delivery state
+// of their sent bundles without handling the agent's JSON messages themselves.
+//
+//	c, err := client.Connect("http://localhost:8080", "dtn://alice/chat", nil)
+//	if err != nil {
+//	  // ...
+//	}
+//	defer c.Close()
+//
+//	sent, err := c.Send([]byte("hello world"), client.SendOptions{Destination: "dtn://bob/chat", StatusReports: true})
+//	// ...
+//	state, err := c.Status(sent.BundleID)
+//
+//	bundles, errs := c.Receive(time.Second)
+//	for bndl := range bundles {
+//	  // ...
+//	}
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// Client is a registered client of a node's REST agent. It must be closed to unregister.
+type Client struct {
+	nodeURL    string
+	httpClient *http.Client
+	endpoint   bpv7.EndpointID
+	uuid       string
+
+	// fetchMutex serialises fetches, each one acknowledging the bundles of the previous one
+	fetchMutex     sync.Mutex
+	unacknowledged []string
+
+	stop      chan struct{}
+	receivers sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Connect to a node's REST agent, e.g., "http://localhost:8080", and register for an endpoint. The optional filter
+// restricts the bundles delivered to this client, see application_agent.DeliveryFilter.
+func Connect(nodeURL, endpoint string, filter *application_agent.RestDeliveryFilter) (*Client, error) {
+	return ConnectWith(http.DefaultClient, nodeURL, endpoint, filter)
+}
+
+// ConnectWith is Connect using a specific http.Client, e.g., with a timeout or TLS configuration.
+func ConnectWith(httpClient *http.Client, nodeURL, endpoint string, filter *application_agent.RestDeliveryFilter) (*Client, error) {
+	eid, err := bpv7.NewEndpointID(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		nodeURL:    nodeURL,
+		httpClient: httpClient,
+		endpoint:   eid,
+		stop:       make(chan struct{}),
+	}
+
+	var response application_agent.RestRegisterResponse
+	request := application_agent.RestRegisterRequest{EndpointId: endpoint, Filter: filter, Acknowledge: true}
+	if err := c.post("register", request, &response); err != nil {
+		return nil, err
+	} else if response.Error != "" {
+		return nil, fmt.Errorf("registering %s failed: %s", endpoint, response.Error)
+	}
+
+	c.uuid = response.UUID
+	return c, nil
+}
+
+// Endpoint is the endpoint this client is registered for.
+func (c *Client) Endpoint() bpv7.EndpointID {
+	return c.endpoint
+}
+
+// post a JSON request to one of the REST agent's endpoints and decode its JSON response.
+func (c *Client) post(endpoint string, request, response interface{}) error {
+	target, err := url.JoinPath(c.nodeURL, "rest", endpoint)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Post(target, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("decoding response of /%s failed, HTTP status %s: %w", endpoint, resp.Status, err)
+	}
+	return nil
+}
+
+// Close stops all receiving channels and unregisters this client. Bundles still waiting for a fetch are dropped by the
+// node.
+func (c *Client) Close() (err error) {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		c.receivers.Wait()
+
+		var response application_agent.RestUnregisterResponse
+		if err = c.post("unregister", application_agent.RestUnregisterRequest{UUID: c.uuid}, &response); err != nil {
+			return
+		} else if response.Error != "" {
+			err = fmt.Errorf("unregistering failed: %s", response.Error)
+		}
+	})
+	return
+}
+
+// SendOptions describe a bundle to be sent. Only the Destination is required.
+type SendOptions struct {
+	// Destination is the bundle's destination endpoint or, if name resolution is enabled, a name.
+	Destination string
+	// ReportTo is the endpoint receiving status reports. It defaults to the client's node ID if StatusReports is set.
+	ReportTo string
+	// Lifetime of the bundle, 24 hours by default.
+	Lifetime time.Duration
+	// Priority is either "bulk", "normal" or "expedited". An empty Priority omits the Priority Block.
+	Priority string
+	// StatusReports requests delivery and deletion status reports, which make up the delivery state, see Status.
+	StatusReports bool
+	// ContentType and the optional Filename describe the payload in a Content Type Block.
+	ContentType string
+	Filename    string
+	// PayloadChecksum adds a Payload Checksum Block.
+	PayloadChecksum bool
+}
+
+// Sent describes a sent bundle.
+type Sent struct {
+	BundleID string
+	// Spooled bundles are sent once the node can take them again. The SpoolBacklog of waiting bundles allows clients
+	// to slow down before their submissions fail with a full spool.
+	Spooled      bool
+	SpoolBacklog int
+}
+
+// Send a bundle from this client's endpoint. The payload is passed as a JSON string and therefore must be UTF-8
+// encoded text, e.g., encoded by base64 for binary data.
+func (c *Client) Send(payload []byte, options SendOptions) (Sent, error) {
+	args, err := options.buildArgs(c.endpoint)
+	if err != nil {
+		return Sent{}, err
+	}
+	if !utf8.Valid(payload) {
+		return Sent{}, fmt.Errorf("payload is no UTF-8 encoded text")
+	}
+	args["payload_block"] = string(payload)
+
+	var response application_agent.RestBuildResponse
+	if err := c.post("build", application_agent.RestBuildRequest{UUID: c.uuid, Args: args}, &response); err != nil {
+		return Sent{}, err
+	} else if response.Error != "" {
+		return Sent{}, fmt.Errorf("sending bundle failed: %s", response.Error)
+	}
+	return Sent{BundleID: response.BundleID, Spooled: response.Spooled, SpoolBacklog: response.SpoolBacklog}, nil
+}
+
+// buildArgs converts the options into the arguments of the REST agent's BundleBuilder.
+func (options SendOptions) buildArgs(source bpv7.EndpointID) (map[string]interface{}, error) {
+	if options.Destination == "" {
+		return nil, fmt.Errorf("bundle has no destination")
+	}
+
+	lifetime := options.Lifetime
+	if lifetime == 0 {
+		lifetime = 24 * time.Hour
+	}
+	args := map[string]interface{}{
+		"destination":            options.Destination,
+		"source":                 source.String(),
+		"creation_timestamp_now": 1,
+		"lifetime":               lifetime.String(),
+	}
+
+	reportTo := options.ReportTo
+	if options.StatusReports {
+		if reportTo == "" {
+			nodeID, err := nodeID(source)
+			if err != nil {
+				return nil, err
+			}
+			reportTo = nodeID
+		}
+		args["bundle_ctrl_flags"] = []string{"REQUESTED_DELIVERY_STATUS_REPORT", "REQUESTED_DELETION_STATUS_REPORT"}
+	}
+	if reportTo != "" {
+		args["report_to"] = reportTo
+	}
+
+	if options.Priority != "" {
+		args["priority_block"] = options.Priority
+	}
+	if options.ContentType != "" {
+		args["content_type_block"] = map[string]interface{}{
+			"content_type": options.ContentType,
+			"filename":     options.Filename,
+		}
+	}
+	if options.PayloadChecksum {
+		args["payload_checksum_block"] = true
+	}
+	return args, nil
+}
+
+// nodeID returns the node ID of an endpoint, e.g., "dtn://alice/" for "dtn://alice/chat". Status reports must be
+// reported to the node's administrative endpoint to reach its delivery tracking.
+func nodeID(eid bpv7.EndpointID) (string, error) {
+	switch eid.EndpointType.(type) {
+	case bpv7.DtnEndpoint:
+		return "dtn://" + eid.Authority() + "/", nil
+	case bpv7.IpnEndpoint:
+		return "ipn:" + eid.Authority() + ".0", nil
+	default:
+		return "", fmt.Errorf("unsupported endpoint scheme of %v", eid)
+	}
+}
+
+// DeliveryState of a sent bundle, as reported by status reports.
+type DeliveryState struct {
+	Sent        time.Time
+	ForwardedBy []string
+	Delivered   bool
+	DeliveredAt time.Time
+	DeletedBy   []string
+}
+
+// Status queries the delivery state of a bundle sent by this client. It fails for unknown bundles, e.g., after the
+// bundle's lifetime is exceeded or the node was restarted.
+func (c *Client) Status(bundleID string) (DeliveryState, error) {
+	var response application_agent.RestStatusResponse
+	request := application_agent.RestStatusRequest{UUID: c.uuid, BundleID: bundleID}
+	if err := c.post("status", request, &response); err != nil {
+		return DeliveryState{}, err
+	} else if response.Error != "" {
+		return DeliveryState{}, fmt.Errorf("querying status of %s failed: %s", bundleID, response.Error)
+	}
+
+	return DeliveryState{
+		Sent:        response.Sent,
+		ForwardedBy: response.ForwardedBy,
+		Delivered:   response.Delivered,
+		DeliveredAt: response.DeliveredAt,
+		DeletedBy:   response.DeletedBy,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// fakeAgent serves the REST agent's endpoints, delivering a single bundle until it is acknowledged.
+func fakeAgent(t *testing.T, bndl bpv7.Bundle, built chan<- map[string]interface{}) *httptest.Server {
+	var (
+		mutex        sync.Mutex
+		acknowledged bool
+	)
+
+	mux := http.NewServeMux()
+	respond := func(w http.ResponseWriter, response interface{}) {
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}
+
+	mux.HandleFunc("/rest/register", func(w http.ResponseWriter, r *http.Request) {
+		var request application_agent.RestRegisterRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		} else if !request.Acknowledge {
+			t.Error("Client does not register to acknowledge its bundles")
+		}
+		respond(w, application_agent.RestRegisterResponse{UUID: "23"})
+	})
+	mux.HandleFunc("/rest/unregister", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, application_agent.RestUnregisterResponse{})
+	})
+	mux.HandleFunc("/rest/build", func(w http.ResponseWriter, r *http.Request) {
+		var request application_agent.RestBuildRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		built <- request.Args
+		respond(w, application_agent.RestBuildResponse{BundleID: "dtn://alice/chat-0-0"})
+	})
+	mux.HandleFunc("/rest/fetch", func(w http.ResponseWriter, r *http.Request) {
+		var request application_agent.RestFetchRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, id := range request.Ack {
+			acknowledged = acknowledged || id == bndl.ID().String()
+		}
+		if acknowledged {
+			respond(w, application_agent.RestFetchResponse{Bundles: []bpv7.Bundle{}, BundleIDs: []string{}})
+		} else {
+			respond(w, application_agent.RestFetchResponse{
+				Bundles:   []bpv7.Bundle{bndl},
+				BundleIDs: []string{bndl.ID().String()},
+			})
+		}
+	})
+	mux.HandleFunc("/rest/status", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, application_agent.RestStatusResponse{Error: "unknown bundle"})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient(t *testing.T) {
+	bndl, err := bpv7.Builder().
+		Source("dtn://bob/chat").
+		Destination("dtn://alice/chat").
+		CreationTimestampNow().
+		Lifetime("10m").
+		ContentTypeBlock("text/plain", "hello.txt").
+		PayloadBlock([]byte("hello alice")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built := make(chan map[string]interface{}, 1)
+	server := fakeAgent(t, bndl, built)
+	defer server.Close()
+
+	c, err := Connect(server.URL, "dtn://alice/chat", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := c.Send([]byte("hello bob"), SendOptions{Destination: "dtn://bob/chat", StatusReports: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if sent.BundleID != "dtn://alice/chat-0-0" {
+		t.Fatalf("Unexpected bundle ID %q", sent.BundleID)
+	}
+	if args := <-built; args["payload_block"] != "hello bob" || args["report_to"] != "dtn://alice/" {
+		t.Fatalf("Unexpected build arguments %v", args)
+	}
+
+	if _, err := c.Send([]byte{0xff}, SendOptions{Destination: "dtn://bob/chat"}); err == nil {
+		t.Fatal("Sending a non-UTF-8 payload succeeded")
+	}
+	if _, err := c.Status(sent.BundleID); err == nil {
+		t.Fatal("Querying an unknown bundle's status succeeded")
+	}
+
+	bundles, _ := c.Receive(10 * time.Millisecond)
+	received := <-bundles
+	if received.Source != "dtn://bob/chat" || !bytes.Equal(received.Payload, []byte("hello alice")) ||
+		received.ContentType != "text/plain" || received.Filename != "hello.txt" {
+		t.Fatalf("Unexpected received bundle %v", received)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for range bundles {
+	}
+}
+
+func TestClientFetchAcknowledgement(t *testing.T) {
+	bndl, err := bpv7.Builder().
+		Source("dtn://bob/chat").
+		Destination("dtn://alice/chat").
+		CreationTimestampNow().
+		Lifetime("10m").
+		PayloadBlock([]byte("hello alice")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := fakeAgent(t, bndl, nil)
+	defer server.Close()
+
+	c, err := Connect(server.URL, "dtn://alice/chat", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the first fetch's bundle is acknowledged by the second fetch, thus not returned again by the third one
+	for i, expected := range []int{1, 0, 0} {
+		bundles, _, err := c.Fetch()
+		if err != nil {
+			t.Fatal(err)
+		} else if len(bundles) != expected {
+			t.Fatalf("Fetch %d: expected %d bundles, got %v", i, expected, bundles)
+		} else if expected > 0 && bundles[0].ID != bndl.ID().String() {
+			t.Fatalf("Fetch %d: expected bundle %v, got %q", i, bndl.ID(), bundles[0].ID)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// Bundle is a bundle delivered to this client.
+type Bundle struct {
+	ID          string
+	Source      string
+	Destination string
+	ReportTo    string
+	Payload     []byte
+	// ContentType and Filename of an optional Content Type Block.
+	ContentType string
+	Filename    string
+}
+
+// fetchedBundle is the part of a fetched bundle's JSON representation, see bpv7.Bundle's MarshalJSON.
+type fetchedBundle struct {
+	PrimaryBlock struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		ReportTo    string `json:"reportTo"`
+	} `json:"primaryBlock"`
+	CanonicalBlocks []struct {
+		BlockTypeCode uint64          `json:"blockTypeCode"`
+		Data          json.RawMessage `json:"data"`
+	} `json:"canonicalBlocks"`
+}
+
+// bundle extracts the payload and the optional content type, both encoded in JSON.
+func (fetched fetchedBundle) bundle() (bndl Bundle, err error) {
+	bndl.Source = fetched.PrimaryBlock.Source
+	bndl.Destination = fetched.PrimaryBlock.Destination
+	bndl.ReportTo = fetched.PrimaryBlock.ReportTo
+
+	hasPayload := false
+	for _, cb := range fetched.CanonicalBlocks {
+		switch cb.BlockTypeCode {
+		case bpv7.ExtBlockTypePayloadBlock:
+			// the payload is base64 encoded in JSON
+			if err = json.Unmarshal(cb.Data, &bndl.Payload); err != nil {
+				return
+			}
+			hasPayload = true
+
+		case bpv7.ExtBlockTypeContentTypeBlock:
+			var ctb struct {
+				ContentType string `json:"content_type"`
+				Filename    string `json:"filename"`
+			}
+			if err = json.Unmarshal(cb.Data, &ctb); err != nil {
+				return
+			}
+			bndl.ContentType, bndl.Filename = ctb.ContentType, ctb.Filename
+		}
+	}
+
+	if !hasPayload {
+		err = fmt.Errorf("bundle from %s has no payload block", bndl.Source)
+	}
+	return
+}
+
+// Deletion of a bundle sent by this client by some node, e.g., as its lifetime expired.
+type Deletion = application_agent.RestDeleted
+
+// Fetch all bundles delivered to this client since the last fetch and the deletions of its sent bundles.
+//
+// Each Fetch acknowledges the bundles returned by the previous one, which are removed by the node afterwards. Thus, if
+// a Fetch fails, e.g., as its response got lost, the node returns its bundles again on the next one.
+func (c *Client) Fetch() (bundles []Bundle, deletions []Deletion, err error) {
+	c.fetchMutex.Lock()
+	defer c.fetchMutex.Unlock()
+
+	var response struct {
+		Error     string          `json:"error"`
+		Bundles   []fetchedBundle `json:"bundles"`
+		BundleIDs []string        `json:"bundle_ids"`
+		Deleted   []Deletion      `json:"deleted"`
+	}
+	request := application_agent.RestFetchRequest{UUID: c.uuid, Ack: c.unacknowledged}
+	if err = c.post("fetch", request, &response); err != nil {
+		return
+	} else if response.Error != "" {
+		err = fmt.Errorf("fetching bundles failed: %s", response.Error)
+		return
+	}
+	c.unacknowledged = response.BundleIDs
+
+	for i, fetched := range response.Bundles {
+		bndl, bndlErr := fetched.bundle()
+		if bndlErr != nil {
+			err = bndlErr
+			continue
+		}
+		if i < len(response.BundleIDs) {
+			bndl.ID = response.BundleIDs[i]
+		}
+		bundles = append(bundles, bndl)
+	}
+	return bundles, response.Deleted, err
+}
+
+// Receive fetches delivered bundles periodically until the client is closed, which closes both channels. Fetching
+// errors are dropped if the error channel is not read. Deletions are only available by Fetch or Status.
+func (c *Client) Receive(interval time.Duration) (<-chan Bundle, <-chan error) {
+	bundles := make(chan Bundle)
+	errs := make(chan error, 1)
+
+	c.receivers.Add(1)
+	go func() {
+		defer c.receivers.Done()
+		defer close(bundles)
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				return
+
+			case <-ticker.C:
+				fetched, _, err := c.Fetch()
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+
+				for _, bndl := range fetched {
+					select {
+					case bundles <- bndl:
+					case <-c.stop:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return bundles, errs
+}
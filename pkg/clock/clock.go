@@ -0,0 +1,105 @@
+// Package clock abstracts access to the current time, tickers, and timers.
+//
+// All time-dependent logic, e.g., lifetime expiry, keepalives, and reconnection attempts, should use this package's
+// functions instead of the time package. By default, they are backed by the system's clock. Tests might replace it by
+// a FakeClock through SetClock to advance time manually, resulting in fast and deterministic executions. Simulations
+// might replace it by a ScaledClock, running faster than real time.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time, tickers, and timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the current goroutine for at least the duration.
+	Sleep(d time.Duration)
+	// NewTicker returns a Ticker, sending the current time after each period. The period must be positive.
+	NewTicker(d time.Duration) Ticker
+	// NewTimer returns a Timer, sending the current time once after the duration.
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker is the equivalent of a time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// Timer is the equivalent of a time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+var (
+	currentMutex sync.RWMutex
+	current      Clock = RealClock{}
+)
+
+// SetClock replaces the Clock used by this package's functions. This should only be done by tests.
+func SetClock(c Clock) {
+	currentMutex.Lock()
+	defer currentMutex.Unlock()
+	current = c
+}
+
+// GetClock returns the Clock used by this package's functions.
+func GetClock() Clock {
+	currentMutex.RLock()
+	defer currentMutex.RUnlock()
+	return current
+}
+
+// Now returns the current time of the current Clock.
+func Now() time.Time {
+	return GetClock().Now()
+}
+
+// After waits for the duration to elapse on the current Clock.
+func After(d time.Duration) <-chan time.Time {
+	return GetClock().After(d)
+}
+
+// Sleep pauses the current goroutine for the duration on the current Clock.
+func Sleep(d time.Duration) {
+	GetClock().Sleep(d)
+}
+
+// NewTicker returns a Ticker of the current Clock.
+func NewTicker(d time.Duration) Ticker {
+	return GetClock().NewTicker(d)
+}
+
+// NewTimer returns a Timer of the current Clock.
+func NewTimer(d time.Duration) Timer {
+	return GetClock().NewTimer(d)
+}
+
+// RealClock is a Clock backed by the system's clock and the time package.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                         { return time.Now() }
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (RealClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (RealClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+func (RealClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
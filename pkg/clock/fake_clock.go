@@ -0,0 +1,219 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock whose time only changes by calls to Advance or Set.
+//
+// Timers and tickers fire in order of their deadlines while time is advanced. Like the time package's channels,
+// their channels hold at most one pending value; further values are dropped if it was not received.
+type FakeClock struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// NewFakeClock creates a FakeClock starting at the given time.
+func NewFakeClock(start time.Time) *FakeClock {
+	fc := &FakeClock{now: start}
+	fc.changed = sync.NewCond(&fc.mutex)
+	return fc
+}
+
+// fakeWaiter is the state of a FakeClock's Timer or Ticker.
+type fakeWaiter struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+	// period is only positive for tickers
+	period time.Duration
+	active bool
+}
+
+func (fc *FakeClock) Now() time.Time {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	return fc.now
+}
+
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	return fc.NewTimer(d).C()
+}
+
+// Sleep blocks until the FakeClock was advanced by at least the duration.
+func (fc *FakeClock) Sleep(d time.Duration) {
+	<-fc.After(d)
+}
+
+func (fc *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{fc.addWaiter(d, d)}
+}
+
+func (fc *FakeClock) NewTimer(d time.Duration) Timer {
+	return fakeTimer{fc.addWaiter(d, 0)}
+}
+
+// addWaiter registers a new Timer or Ticker; timers with a non-positive duration fire immediately.
+func (fc *FakeClock) addWaiter(d, period time.Duration) *fakeWaiter {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	w := &fakeWaiter{
+		clock:    fc,
+		c:        make(chan time.Time, 1),
+		deadline: fc.now.Add(d),
+		period:   period,
+		active:   true,
+	}
+	fc.waiters = append(fc.waiters, w)
+
+	if d <= 0 {
+		w.fire(fc.now)
+	}
+	fc.changed.Broadcast()
+	return w
+}
+
+// Advance moves the time forward by the duration, firing all timers and tickers due in the meantime.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mutex.Lock()
+	target := fc.now.Add(d)
+	fc.mutex.Unlock()
+
+	fc.Set(target)
+}
+
+// Set moves the time to t, firing all timers and tickers due until then. Setting a past time fires nothing.
+func (fc *FakeClock) Set(t time.Time) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	for {
+		var next *fakeWaiter
+		for _, w := range fc.waiters {
+			if w.active && !w.deadline.After(t) && (next == nil || w.deadline.Before(next.deadline)) {
+				next = w
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		if next.deadline.After(fc.now) {
+			fc.now = next.deadline
+		}
+		next.fire(fc.now)
+	}
+
+	fc.now = t
+	fc.removeInactive()
+	fc.changed.Broadcast()
+}
+
+// Waiters returns the number of active timers and tickers.
+func (fc *FakeClock) Waiters() int {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	return fc.activeWaiters()
+}
+
+// BlockUntil blocks until at least n timers and tickers are active, e.g., to wait for a goroutine to start waiting.
+func (fc *FakeClock) BlockUntil(n int) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	for fc.activeWaiters() < n {
+		fc.changed.Wait()
+	}
+}
+
+// activeWaiters counts active timers and tickers. The caller must hold the lock.
+func (fc *FakeClock) activeWaiters() (n int) {
+	for _, w := range fc.waiters {
+		if w.active {
+			n++
+		}
+	}
+	return
+}
+
+// removeInactive drops stopped or fired timers. The caller must hold the lock.
+func (fc *FakeClock) removeInactive() {
+	waiters := fc.waiters[:0]
+	for _, w := range fc.waiters {
+		if w.active {
+			waiters = append(waiters, w)
+		}
+	}
+	fc.waiters = waiters
+}
+
+// fire sends the time without blocking and schedules the next tick. The caller must hold the clock's lock.
+func (w *fakeWaiter) fire(now time.Time) {
+	select {
+	case w.c <- now:
+	default:
+	}
+
+	if w.period > 0 {
+		w.deadline = w.deadline.Add(w.period)
+	} else {
+		w.active = false
+	}
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+// stop deactivates this timer or ticker and reports if it was active before.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mutex.Lock()
+	defer w.clock.mutex.Unlock()
+
+	wasActive := w.active
+	w.active = false
+	w.clock.removeInactive()
+	return wasActive
+}
+
+// reset reschedules this timer or ticker after the duration and reports if it was active before.
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	w.clock.mutex.Lock()
+	defer w.clock.mutex.Unlock()
+
+	// remove and re-add this waiter, since an inactive one might or might not have been removed yet
+	wasActive := w.active
+	w.active = false
+	w.clock.removeInactive()
+
+	if w.period > 0 {
+		w.period = d
+	}
+	w.deadline = w.clock.now.Add(d)
+	w.active = true
+	w.clock.waiters = append(w.clock.waiters, w)
+	w.clock.changed.Broadcast()
+	return wasActive
+}
+
+// fakeTicker is a FakeClock's Ticker.
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Stop()                 { t.stop() }
+func (t fakeTicker) Reset(d time.Duration) { t.reset(d) }
+
+// fakeTimer is a FakeClock's Timer.
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+func (t fakeTimer) Stop() bool                 { return t.stop() }
+func (t fakeTimer) Reset(d time.Duration) bool { return t.reset(d) }
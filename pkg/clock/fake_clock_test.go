@@ -0,0 +1,126 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		start := time.Unix(0, 0)
+		fc := NewFakeClock(start)
+
+		durations := rapid.SliceOfN(rapid.Int64Range(1, 1000), 1, 20).Draw(t, "durations")
+		timers := make([]Timer, len(durations))
+		for i, d := range durations {
+			timers[i] = fc.NewTimer(time.Duration(d))
+		}
+
+		advance := time.Duration(rapid.Int64Range(0, 1100).Draw(t, "advance"))
+		fc.Advance(advance)
+
+		if now := fc.Now(); !now.Equal(start.Add(advance)) {
+			t.Fatalf("Clock is at %v, expected %v", now, start.Add(advance))
+		}
+
+		for i, timer := range timers {
+			deadline := start.Add(time.Duration(durations[i]))
+			select {
+			case fired := <-timer.C():
+				if time.Duration(durations[i]) > advance {
+					t.Fatalf("Timer %d fired too early", i)
+				} else if !fired.Equal(deadline) {
+					t.Fatalf("Timer %d fired at %v, expected %v", i, fired, deadline)
+				}
+			default:
+				if time.Duration(durations[i]) <= advance {
+					t.Fatalf("Timer %d did not fire", i)
+				}
+			}
+		}
+	})
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	fc := NewFakeClock(time.Unix(0, 0))
+	ticker := fc.NewTicker(time.Second)
+
+	for i := 1; i <= 5; i++ {
+		fc.Advance(time.Second)
+		if tick := <-ticker.C(); !tick.Equal(time.Unix(int64(i), 0)) {
+			t.Fatalf("Tick %d at %v", i, tick)
+		}
+	}
+
+	// Unreceived ticks are dropped, just like for a time.Ticker
+	fc.Advance(10 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatal("Ticker buffered more than one tick")
+	default:
+	}
+
+	ticker.Stop()
+	fc.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Fatal("Stopped ticker fired")
+	default:
+	}
+	if fc.Waiters() != 0 {
+		t.Fatalf("Stopped ticker is still active")
+	}
+}
+
+func TestFakeClockSleep(t *testing.T) {
+	fc := NewFakeClock(time.Unix(0, 0))
+
+	done := make(chan struct{})
+	go func() {
+		fc.Sleep(time.Hour)
+		close(done)
+	}()
+
+	fc.BlockUntil(1)
+	fc.Advance(59 * time.Minute)
+	select {
+	case <-done:
+		t.Fatal("Sleep returned too early")
+	default:
+	}
+
+	fc.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep did not return")
+	}
+}
+
+func TestFakeClockTimerReset(t *testing.T) {
+	fc := NewFakeClock(time.Unix(0, 0))
+	timer := fc.NewTimer(time.Second)
+
+	if !timer.Stop() {
+		t.Fatal("Active timer was reported as inactive")
+	}
+	if timer.Reset(2 * time.Second) {
+		t.Fatal("Stopped timer was reported as active")
+	}
+
+	fc.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("Timer fired before its reset deadline")
+	default:
+	}
+
+	fc.Advance(time.Second)
+	<-timer.C()
+	if fc.Waiters() != 0 {
+		t.Fatalf("Fired timer is still active")
+	}
+}
@@ -0,0 +1,162 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// ScaledClock is a Clock running a constant factor faster than the system's clock, starting at a given time.
+//
+// Durations of sleeps, timers and tickers are measured in the ScaledClock's time, e.g., an hourly ticker of a
+// ScaledClock with a factor of 100 ticks every 36 seconds of real time. Thus, installed by SetClock, it compresses the
+// time of an in-process simulation, e.g., a day-long contact plan within minutes, while the processing still runs
+// concurrently in real time. Unlike a FakeClock, it needs no manual advancing.
+type ScaledClock struct {
+	start     time.Time
+	realStart time.Time
+	factor    float64
+}
+
+// NewScaledClock creates a ScaledClock starting at the given time, running factor times faster than real time. The
+// factor must be positive.
+func NewScaledClock(start time.Time, factor float64) *ScaledClock {
+	if factor <= 0 {
+		panic("non-positive factor for NewScaledClock")
+	}
+	return &ScaledClock{start: start, realStart: time.Now(), factor: factor}
+}
+
+// Factor by which the ScaledClock runs faster than real time.
+func (sc *ScaledClock) Factor() float64 {
+	return sc.factor
+}
+
+// real converts a duration of the ScaledClock into real time, at least a nanosecond for a positive duration.
+func (sc *ScaledClock) real(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return max(time.Duration(float64(d)/sc.factor), 1)
+}
+
+// scaled converts a real point in time into the ScaledClock's time.
+func (sc *ScaledClock) scaled(t time.Time) time.Time {
+	return sc.start.Add(time.Duration(float64(t.Sub(sc.realStart)) * sc.factor))
+}
+
+func (sc *ScaledClock) Now() time.Time {
+	return sc.scaled(time.Now())
+}
+
+func (sc *ScaledClock) After(d time.Duration) <-chan time.Time {
+	return sc.NewTimer(d).C()
+}
+
+func (sc *ScaledClock) Sleep(d time.Duration) {
+	time.Sleep(sc.real(d))
+}
+
+func (sc *ScaledClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return scaledTicker{sc.addWaiter(d, d)}
+}
+
+func (sc *ScaledClock) NewTimer(d time.Duration) Timer {
+	return scaledTimer{sc.addWaiter(d, 0)}
+}
+
+// scaledWaiter is the state of a ScaledClock's Timer or Ticker, scheduled by a real timer.
+type scaledWaiter struct {
+	clock *ScaledClock
+	c     chan time.Time
+	timer *time.Timer
+
+	mutex sync.Mutex
+	// period is only positive for tickers
+	period time.Duration
+	active bool
+}
+
+// addWaiter starts a new Timer or Ticker.
+func (sc *ScaledClock) addWaiter(d, period time.Duration) *scaledWaiter {
+	w := &scaledWaiter{
+		clock:  sc,
+		c:      make(chan time.Time, 1),
+		period: period,
+		active: true,
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.timer = time.AfterFunc(sc.real(d), w.fire)
+	return w
+}
+
+// fire sends the ScaledClock's time without blocking and schedules the next tick. Like the time package's channels,
+// the channel holds at most one pending value.
+func (w *scaledWaiter) fire() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.active {
+		return
+	}
+
+	select {
+	case w.c <- w.clock.Now():
+	default:
+	}
+
+	if w.period > 0 {
+		w.timer.Reset(w.clock.real(w.period))
+	} else {
+		w.active = false
+	}
+}
+
+func (w *scaledWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+// stop deactivates this timer or ticker and reports if it was active before.
+func (w *scaledWaiter) stop() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	wasActive := w.active
+	w.active = false
+	w.timer.Stop()
+	return wasActive
+}
+
+// reset reschedules this timer or ticker after the duration and reports if it was active before.
+func (w *scaledWaiter) reset(d time.Duration) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	wasActive := w.active
+	if w.period > 0 {
+		w.period = d
+	}
+	w.active = true
+	w.timer.Reset(w.clock.real(d))
+	return wasActive
+}
+
+// scaledTicker is a ScaledClock's Ticker.
+type scaledTicker struct {
+	*scaledWaiter
+}
+
+func (t scaledTicker) Stop()                 { t.stop() }
+func (t scaledTicker) Reset(d time.Duration) { t.reset(d) }
+
+// scaledTimer is a ScaledClock's Timer.
+type scaledTimer struct {
+	*scaledWaiter
+}
+
+func (t scaledTimer) Stop() bool                 { return t.stop() }
+func (t scaledTimer) Reset(d time.Duration) bool { return t.reset(d) }
@@ -0,0 +1,71 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaledClockNow(t *testing.T) {
+	start := time.Unix(0, 0)
+	sc := NewScaledClock(start, 1000)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// ten milliseconds of real time are at least ten seconds of scaled time
+	if elapsed := sc.Now().Sub(start); elapsed < 10*time.Second || elapsed > time.Hour {
+		t.Fatalf("ScaledClock advanced by %v, expected at least 10s", elapsed)
+	}
+}
+
+func TestScaledClockTicker(t *testing.T) {
+	sc := NewScaledClock(time.Unix(0, 0), 3600)
+
+	// an hourly ticker ticks every real second
+	ticker := sc.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	realStart := time.Now()
+	var previous time.Time
+	for i := 0; i < 2; i++ {
+		select {
+		case tick := <-ticker.C():
+			if !tick.After(previous) {
+				t.Fatalf("Tick %d at %v is not after the previous one at %v", i, tick, previous)
+			}
+			previous = tick
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Ticker did not tick %d times", i+1)
+		}
+	}
+
+	if elapsed := time.Since(realStart); elapsed < 1900*time.Millisecond {
+		t.Fatalf("Two hourly ticks took %v of real time, expected about 2s", elapsed)
+	}
+}
+
+func TestScaledClockTimer(t *testing.T) {
+	sc := NewScaledClock(time.Unix(0, 0), 1000)
+
+	timer := sc.NewTimer(time.Hour)
+	if !timer.Stop() {
+		t.Fatal("Stopping an active timer reported an inactive one")
+	}
+	if timer.Stop() {
+		t.Fatal("Stopping a stopped timer reported an active one")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Fatal("Resetting a stopped timer reported an active one")
+	}
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("Reset timer did not fire within a millisecond of real time")
+	}
+
+	realStart := time.Now()
+	sc.Sleep(10 * time.Second)
+	if elapsed := time.Since(realStart); elapsed < 10*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("Sleeping for ten scaled seconds took %v of real time, expected 10ms", elapsed)
+	}
+}
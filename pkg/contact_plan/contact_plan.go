@@ -0,0 +1,185 @@
+// Package contact_plan provides scheduled contacts between nodes, as known in advance for, e.g., space links.
+//
+// A ContactPlan might be loaded from a file in a format compatible with ION's contact graph commands, as described
+// for LoadContactPlan. Routing algorithms and the transmission scheduler query the plan for current and future
+// contacts through the ContactPlan singleton.
+package contact_plan
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+// Contact is a scheduled, unidirectional transmission opportunity from one node to another.
+type Contact struct {
+	Start time.Time
+	End   time.Time
+
+	From bpv7.EndpointID
+	To   bpv7.EndpointID
+
+	// DataRate is the expected transmission rate in bytes per second.
+	DataRate uint64
+	// Confidence in this contact's occurrence, between 0 and 1.
+	Confidence float64
+	// OneWayLightTime is the propagation delay between both nodes, as derived from a matching Range.
+	OneWayLightTime time.Duration
+}
+
+// ActiveAt checks if this contact takes place at the given point in time.
+func (c Contact) ActiveAt(t time.Time) bool {
+	return !t.Before(c.Start) && t.Before(c.End)
+}
+
+// Volume is the number of bytes which might be transmitted during the whole contact.
+func (c Contact) Volume() uint64 {
+	return uint64(c.End.Sub(c.Start).Seconds()) * c.DataRate
+}
+
+// Range is the distance between two nodes during a period of time, expressed as the one-way light time.
+// Unlike Contacts, Ranges are symmetric.
+type Range struct {
+	Start time.Time
+	End   time.Time
+
+	NodeA bpv7.EndpointID
+	NodeB bpv7.EndpointID
+
+	OneWayLightTime time.Duration
+}
+
+// covers checks if this Range applies to a Contact, based on both nodes and the contact's start.
+func (r Range) covers(c Contact) bool {
+	sameNodes := (r.NodeA.SameNode(c.From) && r.NodeB.SameNode(c.To)) ||
+		(r.NodeA.SameNode(c.To) && r.NodeB.SameNode(c.From))
+	return sameNodes && !c.Start.Before(r.Start) && c.Start.Before(r.End)
+}
+
+var contactPlanSingleton *ContactPlan
+
+// ContactPlan is the set of all known Contacts and Ranges.
+type ContactPlan struct {
+	mutex    sync.RWMutex
+	contacts []Contact
+	ranges   []Range
+}
+
+// NewContactPlan creates a ContactPlan. Each Contact's OneWayLightTime is set from the first covering Range.
+func NewContactPlan(contacts []Contact, ranges []Range) *ContactPlan {
+	cp := &ContactPlan{}
+	cp.set(contacts, ranges)
+	return cp
+}
+
+// set replaces the plan's Contacts and Ranges. The caller must hold the write lock, if required.
+func (cp *ContactPlan) set(contacts []Contact, ranges []Range) {
+	cp.contacts = make([]Contact, len(contacts))
+	copy(cp.contacts, contacts)
+	cp.ranges = make([]Range, len(ranges))
+	copy(cp.ranges, ranges)
+
+	for i := range cp.contacts {
+		for _, r := range cp.ranges {
+			if r.covers(cp.contacts[i]) {
+				cp.contacts[i].OneWayLightTime = r.OneWayLightTime
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(cp.contacts, func(i, j int) bool {
+		return cp.contacts[i].Start.Before(cp.contacts[j].Start)
+	})
+}
+
+// InitialiseContactPlan sets the ContactPlan singleton.
+func InitialiseContactPlan(plan *ContactPlan) error {
+	if contactPlanSingleton != nil {
+		return util.NewAlreadyInitialisedError("ContactPlan")
+	}
+
+	contactPlanSingleton = plan
+	return nil
+}
+
+// GetContactPlanSingleton returns the ContactPlan singleton.
+func GetContactPlanSingleton() *ContactPlan {
+	if contactPlanSingleton == nil {
+		log.Fatalf("Attempting to access an uninitialised ContactPlan. This must never happen!")
+	}
+	return contactPlanSingleton
+}
+
+// Replace exchanges this plan's Contacts and Ranges by those of another plan, e.g., after reloading a file.
+func (cp *ContactPlan) Replace(other *ContactPlan) {
+	contacts, ranges := other.Contacts(), other.Ranges()
+
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.set(contacts, ranges)
+}
+
+// Contacts returns all Contacts, ordered by their start.
+func (cp *ContactPlan) Contacts() []Contact {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+
+	contacts := make([]Contact, len(cp.contacts))
+	copy(contacts, cp.contacts)
+	return contacts
+}
+
+// Ranges returns all Ranges.
+func (cp *ContactPlan) Ranges() []Range {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+
+	ranges := make([]Range, len(cp.ranges))
+	copy(ranges, cp.ranges)
+	return ranges
+}
+
+// filter returns all Contacts, ordered by their start, for which the predicate holds.
+func (cp *ContactPlan) filter(predicate func(Contact) bool) []Contact {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+
+	var contacts []Contact
+	for _, c := range cp.contacts {
+		if predicate(c) {
+			contacts = append(contacts, c)
+		}
+	}
+	return contacts
+}
+
+// ActiveContacts returns all Contacts taking place at the given point in time.
+func (cp *ContactPlan) ActiveContacts(t time.Time) []Contact {
+	return cp.filter(func(c Contact) bool {
+		return c.ActiveAt(t)
+	})
+}
+
+// ContactsFrom returns all current and future Contacts from the given node, which have not ended at time t.
+func (cp *ContactPlan) ContactsFrom(node bpv7.EndpointID, t time.Time) []Contact {
+	return cp.filter(func(c Contact) bool {
+		return c.From.SameNode(node) && c.End.After(t)
+	})
+}
+
+// NextContact returns the current or the next upcoming Contact between two nodes at time t, if one exists.
+func (cp *ContactPlan) NextContact(from, to bpv7.EndpointID, t time.Time) (contact Contact, ok bool) {
+	contacts := cp.filter(func(c Contact) bool {
+		return c.From.SameNode(from) && c.To.SameNode(to) && c.End.After(t)
+	})
+	if len(contacts) == 0 {
+		return Contact{}, false
+	}
+	return contacts[0], true
+}
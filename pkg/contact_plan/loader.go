@@ -0,0 +1,287 @@
+package contact_plan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// ionTimeFormat is ION's format for absolute UTC timestamps, e.g., "2024/06/01-12:00:00".
+const ionTimeFormat = "2006/01/02-15:04:05"
+
+// ParseError describes an invalid line within a contact plan.
+type ParseError struct {
+	line    int
+	message string
+	cause   error
+}
+
+func NewParseError(line int, message string, cause error) *ParseError {
+	return &ParseError{line: line, message: message, cause: cause}
+}
+
+func (e *ParseError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("contact plan line %d: %s: %v", e.line, e.message, e.cause)
+	}
+	return fmt.Sprintf("contact plan line %d: %s", e.line, e.message)
+}
+
+func (e *ParseError) Unwrap() error { return e.cause }
+
+// parseTime parses either a relative time in seconds after the epoch, e.g., "+3600", or an absolute UTC time.
+func parseTime(field string, epoch time.Time) (time.Time, error) {
+	if strings.HasPrefix(field, "+") {
+		seconds, err := strconv.ParseUint(field[1:], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return epoch.Add(time.Duration(seconds) * time.Second), nil
+	}
+	return time.ParseInLocation(ionTimeFormat, field, time.UTC)
+}
+
+// parseNode parses either an ION node number or an endpoint URI.
+//
+// ION node numbers result in an "ipn:<node>.1" endpoint, as the ipn service number zero is rejected by bpv7. Contacts
+// are matched by node, not by service, so the service number is irrelevant.
+func parseNode(field string) (bpv7.EndpointID, error) {
+	if _, err := strconv.ParseUint(field, 10, 64); err == nil {
+		return bpv7.NewEndpointID("ipn:" + field + ".1")
+	}
+	return bpv7.NewEndpointID(field)
+}
+
+// parseInterval parses the start and end time, which are the first two fields of both contacts and ranges.
+func parseInterval(fields []string, epoch time.Time) (start, end time.Time, err error) {
+	if start, err = parseTime(fields[0], epoch); err != nil {
+		return
+	}
+	if end, err = parseTime(fields[1], epoch); err != nil {
+		return
+	}
+	if !end.After(start) {
+		err = fmt.Errorf("end %v is not after start %v", end, start)
+	}
+	return
+}
+
+// parseContact parses the fields of "a contact <start> <end> <from> <to> <rate> [<confidence>]".
+func parseContact(fields []string, epoch time.Time) (c Contact, err error) {
+	if len(fields) != 5 && len(fields) != 6 {
+		return c, fmt.Errorf("expected 5 or 6 fields, got %d", len(fields))
+	}
+
+	if c.Start, c.End, err = parseInterval(fields, epoch); err != nil {
+		return
+	}
+	if c.From, err = parseNode(fields[2]); err != nil {
+		return
+	}
+	if c.To, err = parseNode(fields[3]); err != nil {
+		return
+	}
+	if c.DataRate, err = strconv.ParseUint(fields[4], 10, 64); err != nil {
+		return
+	}
+
+	c.Confidence = 1.0
+	if len(fields) == 6 {
+		if c.Confidence, err = strconv.ParseFloat(fields[5], 64); err != nil {
+			return
+		}
+		if c.Confidence < 0 || c.Confidence > 1 {
+			return c, fmt.Errorf("confidence %v is not between 0 and 1", c.Confidence)
+		}
+	}
+	return
+}
+
+// parseRange parses the fields of "a range <start> <end> <node a> <node b> <one-way light time in seconds>".
+func parseRange(fields []string, epoch time.Time) (r Range, err error) {
+	if len(fields) != 5 {
+		return r, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	if r.Start, r.End, err = parseInterval(fields, epoch); err != nil {
+		return
+	}
+	if r.NodeA, err = parseNode(fields[2]); err != nil {
+		return
+	}
+	if r.NodeB, err = parseNode(fields[3]); err != nil {
+		return
+	}
+
+	owlt, err := strconv.ParseUint(fields[4], 10, 64)
+	if err != nil {
+		return
+	}
+	r.OneWayLightTime = time.Duration(owlt) * time.Second
+	return
+}
+
+// parseReference parses the field of "@ <time>", which sets the reference time for relative times. A relative
+// reference time is based on the epoch.
+func parseReference(fields []string, epoch time.Time) (time.Time, error) {
+	if len(fields) != 1 {
+		return time.Time{}, fmt.Errorf("expected 1 field, got %d", len(fields))
+	}
+	return parseTime(fields[0], epoch)
+}
+
+// parseDeletion parses the fields of "d contact|range <start> <node a> <node b>", resulting in a predicate matching the
+// deleted entries' start and nodes. A start of "*" matches every start.
+func parseDeletion(fields []string, epoch time.Time) (
+	matches func(start time.Time, a, b bpv7.EndpointID) bool, err error) {
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected 3 fields, got %d", len(fields))
+	}
+
+	anyStart := fields[0] == "*"
+	var start time.Time
+	if !anyStart {
+		if start, err = parseTime(fields[0], epoch); err != nil {
+			return
+		}
+	}
+	nodeA, err := parseNode(fields[1])
+	if err != nil {
+		return
+	}
+	nodeB, err := parseNode(fields[2])
+	if err != nil {
+		return
+	}
+
+	return func(s time.Time, a, b bpv7.EndpointID) bool {
+		return (anyStart || s.Equal(start)) && a.SameNode(nodeA) && b.SameNode(nodeB)
+	}, nil
+}
+
+// ParseContactPlan reads a contact plan as described for LoadContactPlan. Relative times are based on the epoch, until
+// an "@" command sets another reference time.
+func ParseContactPlan(r io.Reader, epoch time.Time) (*ContactPlan, error) {
+	var (
+		contacts []Contact
+		ranges   []Range
+	)
+	reference := epoch
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "@" {
+			t, err := parseReference(fields[1:], epoch)
+			if err != nil {
+				return nil, NewParseError(lineNo, "invalid reference time", err)
+			}
+			reference = t
+			continue
+		}
+
+		if (fields[0] != "a" && fields[0] != "d") || len(fields) < 2 || (fields[1] != "contact" && fields[1] != "range") {
+			log.WithFields(log.Fields{
+				"line":    lineNo,
+				"command": strings.Join(fields, " "),
+			}).Debug("Ignoring unsupported contact plan command")
+			continue
+		}
+
+		switch fields[0] + " " + fields[1] {
+		case "a contact":
+			c, err := parseContact(fields[2:], reference)
+			if err != nil {
+				return nil, NewParseError(lineNo, "invalid contact", err)
+			}
+			contacts = append(contacts, c)
+
+		case "a range":
+			rng, err := parseRange(fields[2:], reference)
+			if err != nil {
+				return nil, NewParseError(lineNo, "invalid range", err)
+			}
+			ranges = append(ranges, rng)
+
+		case "d contact":
+			matches, err := parseDeletion(fields[2:], reference)
+			if err != nil {
+				return nil, NewParseError(lineNo, "invalid contact deletion", err)
+			}
+			kept := contacts[:0]
+			for _, c := range contacts {
+				if !matches(c.Start, c.From, c.To) {
+					kept = append(kept, c)
+				}
+			}
+			contacts = kept
+
+		case "d range":
+			matches, err := parseDeletion(fields[2:], reference)
+			if err != nil {
+				return nil, NewParseError(lineNo, "invalid range deletion", err)
+			}
+			kept := ranges[:0]
+			for _, rng := range ranges {
+				if !matches(rng.Start, rng.NodeA, rng.NodeB) && !matches(rng.Start, rng.NodeB, rng.NodeA) {
+					kept = append(kept, rng)
+				}
+			}
+			ranges = kept
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewContactPlan(contacts, ranges), nil
+}
+
+// LoadContactPlan reads a contact plan file, using the current time as the epoch for relative times.
+//
+// The format follows ION's ionadmin contact graph commands, such that an ION constellation's contact plan can be used
+// as is. Each line holds one command, "#" starts a comment, and other ION commands are ignored. Times are either
+// relative to the reference time in seconds, e.g., "+60", or absolute UTC times in ION's "yyyy/mm/dd-hh:mm:ss" format.
+// The reference time is the epoch, unless set by an "@" command. Nodes are either ION node numbers, referring to
+// "ipn:<number>.*", or endpoint URIs like "dtn://foo/". The data rate is given in bytes per second and the optional
+// confidence defaults to 1.
+//
+//	# @ <reference time>
+//	@ 2024/06/01-12:00:00
+//
+//	# a contact <start> <end> <from> <to> <data rate> [<confidence>]
+//	a contact +0 +3600 1 2 100000
+//	a contact 2024/06/01-12:00:00 2024/06/01-13:00:00 dtn://alice/ dtn://bob/ 125000 0.8
+//
+//	# a range <start> <end> <node a> <node b> <one-way light time>
+//	a range +0 +3600 1 2 1
+//
+//	# d contact|range <start|*> <node a> <node b>, deleting earlier added entries
+//	d contact * 1 2
+func LoadContactPlan(filename string) (*ContactPlan, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseContactPlan(f, clock.Now())
+}
@@ -0,0 +1,169 @@
+package contact_plan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestParseContactPlan(t *testing.T) {
+	epoch := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	plan := `
+# ION style contact plan
+1 1 ''
+s
+a contact +60 +120 1 2 1000
+a contact 2024/06/01-12:00:00 2024/06/01-12:00:30 dtn://alice/ dtn://bob/ 125000 0.5   # absolute
+a range +0 +3600 2 1 3
+m horizon +0
+`
+
+	cp, err := ParseContactPlan(strings.NewReader(plan), epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contacts := cp.Contacts()
+	if len(contacts) != 2 {
+		t.Fatalf("Expected two contacts, got %d", len(contacts))
+	}
+
+	first, second := contacts[0], contacts[1]
+	if !first.From.SameNode(bpv7.MustNewEndpointID("dtn://alice/")) || first.Confidence != 0.5 {
+		t.Fatalf("Unexpected first contact %v", first)
+	}
+	if !first.Start.Equal(epoch) || first.End.Sub(first.Start) != 30*time.Second || first.OneWayLightTime != 0 {
+		t.Fatalf("Unexpected first contact times %v", first)
+	}
+
+	if !second.To.SameNode(bpv7.MustNewEndpointID("ipn:2.1")) || second.DataRate != 1000 || second.Confidence != 1 {
+		t.Fatalf("Unexpected second contact %v", second)
+	}
+	if second.OneWayLightTime != 3*time.Second {
+		t.Fatalf("Range was not applied symmetrically, OWLT is %v", second.OneWayLightTime)
+	}
+	if second.Volume() != 60*1000 {
+		t.Fatalf("Unexpected volume %d", second.Volume())
+	}
+
+	if nc, ok := cp.NextContact(bpv7.MustNewEndpointID("ipn:1.1"), bpv7.MustNewEndpointID("ipn:2.1"), epoch); !ok || !nc.Start.Equal(second.Start) {
+		t.Fatalf("Unexpected next contact %v, %t", nc, ok)
+	}
+	if _, ok := cp.NextContact(bpv7.MustNewEndpointID("ipn:2.1"), bpv7.MustNewEndpointID("ipn:1.1"), epoch); ok {
+		t.Fatalf("Contacts must be unidirectional")
+	}
+	if active := cp.ActiveContacts(epoch.Add(90 * time.Second)); len(active) != 1 {
+		t.Fatalf("Expected one active contact, got %d", len(active))
+	}
+}
+
+func TestParseContactPlanION(t *testing.T) {
+	epoch := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	plan := `
+## ionadmin contact plan
+@ 2024/06/02-00:00:00
+a contact +0 +600 1 2 1000
+a contact +600 +1200 1 2 1000
+a contact +0 +600 2 1 1000
+a range +0 +1200 1 2 2
+d contact +0 1 2
+d contact * 2 1
+d range * 2 1
+`
+
+	cp, err := ParseContactPlan(strings.NewReader(plan), epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contacts := cp.Contacts()
+	if len(contacts) != 1 {
+		t.Fatalf("Expected one contact, got %d", len(contacts))
+	}
+	if reference := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC); !contacts[0].Start.Equal(reference.Add(600 * time.Second)) {
+		t.Fatalf("Contact starts at %v, not relative to the reference time %v", contacts[0].Start, reference)
+	}
+	if len(cp.Ranges()) != 0 || contacts[0].OneWayLightTime != 0 {
+		t.Fatalf("Range was not deleted")
+	}
+}
+
+func TestParseContactPlanErrors(t *testing.T) {
+	tests := []string{
+		"a contact +0 +60 1 2",
+		"a contact +60 +0 1 2 1000",
+		"a contact +0 +60 1 2 1000 1.5",
+		"a contact +0 +60 1 dtn:foo:bar 1000",
+		"a range +0 +60 1 2",
+		"a contact yesterday +60 1 2 1000",
+		"@ +60 +120",
+		"d contact +0 1",
+	}
+
+	for _, test := range tests {
+		_, err := ParseContactPlan(strings.NewReader("\n"+test), time.Now())
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("Expected a ParseError for %q, got %v", test, err)
+		} else if parseErr.line != 2 {
+			t.Fatalf("Expected error in line 2 for %q, got %d", test, parseErr.line)
+		}
+	}
+}
+
+func TestContactPlanQueries(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		epoch := time.Unix(0, 0).UTC()
+		n := rapid.IntRange(0, 50).Draw(t, "contacts")
+
+		var lines []string
+		for i := 0; i < n; i++ {
+			start := rapid.Uint32Range(0, 10000).Draw(t, "start")
+			duration := rapid.Uint32Range(1, 1000).Draw(t, "duration")
+			from := rapid.IntRange(1, 4).Draw(t, "from")
+			to := rapid.IntRange(1, 4).Draw(t, "to")
+			lines = append(lines, fmt.Sprintf("a contact +%d +%d %d %d 1000", start, start+duration, from, to))
+		}
+
+		cp, err := ParseContactPlan(strings.NewReader(strings.Join(lines, "\n")), epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contacts := cp.Contacts()
+		if len(contacts) != n {
+			t.Fatalf("Expected %d contacts, got %d", n, len(contacts))
+		}
+		for i := 1; i < len(contacts); i++ {
+			if contacts[i].Start.Before(contacts[i-1].Start) {
+				t.Fatalf("Contacts are not ordered by their start")
+			}
+		}
+
+		now := epoch.Add(time.Duration(rapid.Uint32Range(0, 11000).Draw(t, "now")) * time.Second)
+		node := bpv7.MustNewEndpointID(fmt.Sprintf("ipn:%d.1", rapid.IntRange(1, 4).Draw(t, "node")))
+
+		for _, c := range cp.ActiveContacts(now) {
+			if c.Start.After(now) || !c.End.After(now) {
+				t.Fatalf("Contact %v is not active at %v", c, now)
+			}
+		}
+
+		expected := 0
+		for _, c := range contacts {
+			if c.From.SameNode(node) && c.End.After(now) {
+				expected++
+			}
+		}
+		if from := cp.ContactsFrom(node, now); len(from) != expected {
+			t.Fatalf("Expected %d contacts from %v, got %d", expected, node, len(from))
+		}
+	})
+}
@@ -0,0 +1,200 @@
+// Package diagnostics serves runtime diagnostics for debugging a remote node, e.g., a node whose forwarding got
+// stuck. Its Handler exposes Go's pprof profiles, a dump of all goroutines' stacks and a JSON dump of the node's
+// internal State.
+//
+// As these endpoints reveal the node's internals and profiling costs resources, they should only be served on a
+// separate listener bound to a trusted interface, e.g., localhost reached via an SSH tunnel.
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// maxPendingBundles limits the bundles listed in a State, such that a crowded store does not bloat the dump.
+const maxPendingBundles = 100
+
+// Peer is a connected peer, as known by a CLA sender.
+type Peer struct {
+	Endpoint string `json:"endpoint"`
+	Address  string `json:"address"`
+	// Goodput in bytes per second, zero if unknown.
+	Goodput float64 `json:"goodput"`
+	// Latency as a duration string, empty if unknown.
+	Latency string `json:"latency,omitempty"`
+}
+
+// PendingBundle is a stored bundle with retention constraints, e.g., waiting for its forwarding.
+type PendingBundle struct {
+	ID          string   `json:"id"`
+	Destination string   `json:"destination"`
+	Constraints []string `json:"constraints"`
+	SentTo      []string `json:"sent_to"`
+	Expires     string   `json:"expires"`
+}
+
+// Store summarises the store's bundles by their retention constraints.
+type Store struct {
+	Bundles     int            `json:"bundles"`
+	UsedBytes   int64          `json:"used_bytes"`
+	Constraints map[string]int `json:"constraints"`
+	Dispatch    int            `json:"dispatch"`
+	// Pending are the first bundles with constraints, at most maxPendingBundles.
+	Pending []PendingBundle `json:"pending"`
+}
+
+// Queues of the processing and the CLAs.
+type Queues struct {
+	// Workers are the tasks waiting for a processing worker and the capacity, zero without workers.
+	Workers         int `json:"workers"`
+	WorkersCapacity int `json:"workers_capacity"`
+	// Shaping are the transmissions waiting for their turn per CLA address, empty without traffic shaping.
+	Shaping map[string]int `json:"shaping"`
+	// Send are the transmissions queued per CLA address, empty without send queues.
+	Send map[string]int `json:"send"`
+}
+
+// State is a dump of the node's internal state.
+type State struct {
+	Time       string `json:"time"`
+	Goroutines int    `json:"goroutines"`
+	HeapBytes  uint64 `json:"heap_bytes"`
+	// Panics recovered while processing bundles, see processing.Panics.
+	Panics uint64 `json:"panics"`
+	Queues Queues `json:"queues"`
+	Peers  []Peer `json:"peers"`
+	Store  Store  `json:"store"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CollectState dumps the node's internal state. The store and the CLA manager must be initialised.
+func CollectState() (state State) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	state.Time = clock.Now().UTC().Format(time.RFC3339)
+	state.Goroutines = runtime.NumGoroutine()
+	state.HeapBytes = memStats.HeapAlloc
+	state.Panics = processing.Panics()
+
+	state.Queues.Workers, state.Queues.WorkersCapacity = processing.WorkerQueue()
+	state.Queues.Shaping = cla.GetManagerSingleton().ShapingQueues()
+	state.Queues.Send = cla.GetManagerSingleton().SendQueues()
+
+	state.Peers = make([]Peer, 0)
+	for _, sender := range cla.GetManagerSingleton().GetSenders() {
+		goodput, _ := cla.GetManagerSingleton().PeerGoodput(sender.GetPeerEndpointID())
+		peer := Peer{
+			Endpoint: sender.GetPeerEndpointID().String(),
+			Address:  sender.Address(),
+			Goodput:  goodput,
+		}
+		if latency, known := cla.GetManagerSingleton().PeerLatency(sender.GetPeerEndpointID()); known {
+			peer.Latency = latency.String()
+		}
+		state.Peers = append(state.Peers, peer)
+	}
+	sort.Slice(state.Peers, func(i, j int) bool { return state.Peers[i].Address < state.Peers[j].Address })
+
+	bundles, err := store.GetStoreSingleton().GetAll()
+	if err != nil {
+		state.Error = err.Error()
+		return
+	}
+	state.Store = summariseStore(bundles)
+	state.Store.UsedBytes = store.GetStoreSingleton().Occupancy().Used
+	return
+}
+
+// summariseStore counts the bundles by their constraints and lists the first pending ones.
+func summariseStore(bundles []*store.BundleDescriptor) Store {
+	summary := Store{
+		Bundles:     len(bundles),
+		Constraints: make(map[string]int),
+		Pending:     make([]PendingBundle, 0),
+	}
+
+	for _, bd := range bundles {
+		if bd.Dispatch {
+			summary.Dispatch++
+		}
+		if len(bd.RetentionConstraints) == 0 {
+			continue
+		}
+
+		pending := PendingBundle{
+			ID:          bd.IDString,
+			Destination: bd.Destination.String(),
+			Expires:     bd.Expires.UTC().Format(time.RFC3339),
+		}
+		for _, constraint := range bd.RetentionConstraints {
+			summary.Constraints[constraint.String()]++
+			pending.Constraints = append(pending.Constraints, constraint.String())
+		}
+		for _, peer := range bd.GetAlreadySent() {
+			pending.SentTo = append(pending.SentTo, peer.String())
+		}
+		if len(summary.Pending) < maxPendingBundles {
+			summary.Pending = append(summary.Pending, pending)
+		}
+	}
+	return summary
+}
+
+// handleState serves the State as JSON.
+func handleState(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(CollectState()); err != nil {
+		log.WithError(err).Warn("Failed to write diagnostics state")
+	}
+}
+
+// handleGoroutines serves the stacks of all goroutines as text, like an unrecovered panic prints them.
+func handleGoroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := runtimepprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+		log.WithError(err).Warn("Failed to write goroutine dump")
+	}
+}
+
+// Handler serves the diagnostics:
+//
+//	// Go's pprof profiles, e.g., for "go tool pprof http://localhost:6060/debug/pprof/heap"
+//	GET /debug/pprof/
+//
+//	// Stacks of all goroutines as text
+//	GET /debug/goroutines
+//
+//	// Internal state as JSON: queues, connected peers and the store's bundles by their retention constraints
+//	GET /debug/state
+//	<- {"time":"2024-04-12T09:21:33Z","goroutines":42,"heap_bytes":8388608,
+//	     "queues":{"workers":0,"workers_capacity":0,"shaping":{"10.0.0.2:35037":3}},
+//	     "peers":[{"endpoint":"dtn://other/","address":"10.0.0.2:35037","goodput":125000}],
+//	     "store":{"bundles":12,"used_bytes":1048576,"constraints":{"forwarding pending":2},"dispatch":2,
+//	     "pending":[{"id":"dtn://foo/-706871330477-0","destination":"dtn://bar/",
+//	     "constraints":["forwarding pending"],"sent_to":["dtn://foo/"],"expires":"2024-04-13T09:21:33Z"}]}}
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", handleGoroutines)
+	mux.HandleFunc("/debug/state", handleState)
+	return mux
+}
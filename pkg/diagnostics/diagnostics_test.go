@@ -0,0 +1,45 @@
+package diagnostics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+func TestSummariseStore(t *testing.T) {
+	var bundles []*store.BundleDescriptor
+	for i := 0; i < maxPendingBundles+10; i++ {
+		bundles = append(bundles, &store.BundleDescriptor{
+			IDString:             fmt.Sprintf("dtn://foo/-%d-0", i),
+			Destination:          bpv7.MustNewEndpointID("dtn://bar/"),
+			RetentionConstraints: []store.Constraint{store.ForwardPending},
+			Dispatch:             true,
+		})
+	}
+	bundles = append(bundles, &store.BundleDescriptor{IDString: "dtn://foo/-0-1"})
+
+	summary := summariseStore(bundles)
+	if summary.Bundles != len(bundles) || summary.Dispatch != len(bundles)-1 {
+		t.Fatalf("Unexpected counts %d and %d", summary.Bundles, summary.Dispatch)
+	}
+	if n := summary.Constraints[store.ForwardPending.String()]; n != maxPendingBundles+10 {
+		t.Fatalf("Counted %d forwarding pending bundles", n)
+	}
+	if len(summary.Pending) != maxPendingBundles {
+		t.Fatalf("Listed %d pending bundles", len(summary.Pending))
+	}
+}
+
+func TestHandlerGoroutines(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/goroutines", nil))
+
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "TestHandlerGoroutines") {
+		t.Fatalf("Unexpected goroutine dump %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -14,8 +14,7 @@ import (
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
-	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
-	"github.com/dtn7/dtn7-go/pkg/cla/quicl"
+	"github.com/dtn7/dtn7-go/pkg/clock"
 	"github.com/dtn7/dtn7-go/pkg/util"
 )
 
@@ -113,6 +112,11 @@ func InitialiseManager(
 	return nil
 }
 
+// Initialised checks if the Manager singleton was initialised and not closed since.
+func Initialised() bool {
+	return managerSingleton != nil
+}
+
 // GetManagerSingleton returns the manager singleton-instance.
 // Attempting to call this function before manager initialisation will cause the program to panic.
 func GetManagerSingleton() *Manager {
@@ -154,26 +158,32 @@ func (manager *Manager) handleDiscovery(announcement Announcement, addr string)
 		"message": announcement,
 	}).Debug("Peer discovery received a message")
 
-	var conv cla.Convergence
-	switch announcement.Type {
-	case cla.MTCP:
-		conv = mtcp.NewMTCPClient(fmt.Sprintf("%s:%d", addr, announcement.Port), announcement.Endpoint)
-	case cla.QUICL:
-		conv = quicl.NewDialerEndpoint(fmt.Sprintf("%s:%d", addr, announcement.Port), manager.NodeId, manager.receiveCallback)
-	default:
+	address := fmt.Sprintf("%s:%d", addr, announcement.Port)
+	conv, err := newConvergence(manager.NodeId, announcement.Type, address, announcement.Endpoint, manager.receiveCallback)
+	if err != nil {
 		log.WithField("cType", announcement.Type).Error("Invalid cType")
 		return
 	}
 	cla.GetManagerSingleton().Register(conv)
+
+	if PeerExchangeInitialised() {
+		GetPeerExchangeSingleton().AddPeer(PeerInfo{
+			Endpoint: announcement.Endpoint,
+			Type:     announcement.Type,
+			Address:  address,
+			LastSeen: clock.Now(),
+		})
+	}
 }
 
-// Close this Manager.
+// Close this Manager and reset the singleton, such that a new Manager might be initialised later.
 func (manager *Manager) Close() {
 	for _, c := range []chan struct{}{manager.stopChan4, manager.stopChan6} {
 		if c != nil {
 			c <- struct{}{}
 		}
 	}
+	managerSingleton = nil
 }
 
 func (manager *Manager) String() string {
@@ -0,0 +1,329 @@
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/cla/quicl"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+const (
+	// peerExchangeDemux is the demux of a dtn node's peer exchange endpoint, e.g., "dtn://node/peer-exchange".
+	peerExchangeDemux = "peer-exchange"
+
+	// peerExchangeService is the service number of an ipn node's peer exchange endpoint, equal to the discovery port.
+	peerExchangeService = port
+
+	// peerExchangeLifetime is the lifetime of a peer list bundle, which is only meant for a connected neighbour.
+	peerExchangeLifetime = time.Minute
+)
+
+// PeerExchangeEndpoint returns the endpoint for peer exchanges of a node.
+func PeerExchangeEndpoint(node bpv7.EndpointID) (bpv7.EndpointID, error) {
+	switch endpoint := node.EndpointType.(type) {
+	case bpv7.DtnEndpoint:
+		return bpv7.NewEndpointID(fmt.Sprintf("dtn://%s/%s", endpoint.NodeName, peerExchangeDemux))
+	case bpv7.IpnEndpoint:
+		return bpv7.NewEndpointID(fmt.Sprintf("ipn:%d.%d", endpoint.Node, peerExchangeService))
+	default:
+		return bpv7.EndpointID{}, fmt.Errorf("no peer exchange endpoint for %v", node)
+	}
+}
+
+// PeerExchange keeps a book of known peers and how to reach them. When a peer connects, this list is sent to it.
+// Received lists are merged and newly learned peers are connected. Thus, a node joining a disconnected island quickly
+// learns about reachable nodes beyond its direct neighbours.
+//
+// Peer lists are exchanged as bundles between the nodes' PeerExchangeEndpoints. Thus, PeerExchange is also an
+// ApplicationAgent, which must be registered at the application_agent.Manager.
+type PeerExchange struct {
+	nodeID   bpv7.EndpointID
+	endpoint bpv7.EndpointID
+	// floodEndpoint receives peer lists flooded by other nodes, see SetScopedFlood.
+	floodEndpoint bpv7.EndpointID
+	// maxAge limits the LastSeen age of peers to be passed on.
+	maxAge time.Duration
+	// receiveCallback is passed to newly created CLAs.
+	receiveCallback func(*bpv7.Bundle)
+
+	mutex     sync.Mutex
+	peers     map[string]PeerInfo // CLA type and address -> PeerInfo
+	floodHops uint8
+}
+
+var peerExchangeSingleton *PeerExchange
+
+// InitialisePeerExchange initialises the PeerExchange singleton and registers it as an ApplicationAgent.
+func InitialisePeerExchange(nodeID bpv7.EndpointID, maxAge time.Duration, receiveCallback func(*bpv7.Bundle)) error {
+	if peerExchangeSingleton != nil {
+		return util.NewAlreadyInitialisedError("Peer Exchange")
+	}
+
+	endpoint, err := PeerExchangeEndpoint(nodeID)
+	if err != nil {
+		return err
+	}
+	floodEndpoint, err := routing.ScopedFloodEndpoint(peerExchangeDemux)
+	if err != nil {
+		return err
+	}
+
+	pe := &PeerExchange{
+		nodeID:          nodeID,
+		endpoint:        endpoint,
+		floodEndpoint:   floodEndpoint,
+		maxAge:          maxAge,
+		receiveCallback: receiveCallback,
+		peers:           make(map[string]PeerInfo),
+	}
+	if err := application_agent.GetManagerSingleton().RegisterAgent(pe); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"endpoint": endpoint,
+		"max age":  maxAge,
+	}).Info("Starting peer exchange")
+
+	peerExchangeSingleton = pe
+	return nil
+}
+
+// PeerExchangeInitialised checks if the PeerExchange singleton exists, i.e., if peer exchange is enabled.
+func PeerExchangeInitialised() bool {
+	return peerExchangeSingleton != nil
+}
+
+// GetPeerExchangeSingleton returns the PeerExchange singleton-instance.
+// Attempting to call this function before initialisation will cause the program to panic.
+func GetPeerExchangeSingleton() *PeerExchange {
+	if peerExchangeSingleton == nil {
+		log.Fatalf("Attempting to access an uninitialised peer exchange. This must never happen!")
+	}
+	return peerExchangeSingleton
+}
+
+// SetScopedFlood floods the list of known peers up to the given number of hops when a peer connects, instead of only
+// sending it to this peer. Thus, nodes in the vicinity learn about a newly connected peer without the peer lists
+// traversing the whole network. Zero hops, the default, disables flooding.
+// This method is thread-safe.
+func (pe *PeerExchange) SetScopedFlood(hops uint8) {
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	pe.floodHops = hops
+}
+
+func peerKey(peer PeerInfo) string {
+	return fmt.Sprintf("%v %s", peer.Type, peer.Address)
+}
+
+// AddPeer adds or updates a known peer. An existing entry is only replaced by a more recently seen one.
+// It returns true if the peer was not known before.
+func (pe *PeerExchange) AddPeer(peer PeerInfo) (isNew bool) {
+	if pe.nodeID.SameNode(peer.Endpoint) {
+		return false
+	}
+
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	known, ok := pe.peers[peerKey(peer)]
+	if !ok || peer.LastSeen.After(known.LastSeen) {
+		pe.peers[peerKey(peer)] = peer
+	}
+	return !ok
+}
+
+// Peers returns all known peers which were seen within the maximum age.
+func (pe *PeerExchange) Peers() (peers []PeerInfo) {
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	now := clock.Now()
+	for _, peer := range pe.peers {
+		if now.Sub(peer.LastSeen) <= pe.maxAge {
+			peers = append(peers, peer)
+		}
+	}
+	return
+}
+
+// PeerConnected marks all entries of the peer as seen and sends the list of known peers to it.
+func (pe *PeerExchange) PeerConnected(peerID bpv7.EndpointID) {
+	pe.mutex.Lock()
+	now := clock.Now()
+	for key, peer := range pe.peers {
+		if peer.Endpoint.SameNode(peerID) {
+			peer.LastSeen = now
+			pe.peers[key] = peer
+		}
+	}
+	pe.mutex.Unlock()
+
+	if err := pe.sendPeers(peerID); err != nil {
+		log.WithFields(log.Fields{
+			"peer":  peerID,
+			"error": err,
+		}).Warn("Failed to send known peers")
+	}
+}
+
+// PeerMoved updates the entries of a peer which roamed from one address to another, see cla.Manager.SetRoaming. As
+// the new address is the one of a connection, only the host is taken over, keeping the port of each entry.
+func (pe *PeerExchange) PeerMoved(peerID bpv7.EndpointID, oldAddress, newAddress string) {
+	oldHost, _, err := net.SplitHostPort(oldAddress)
+	if err != nil {
+		return
+	}
+	newHost, _, err := net.SplitHostPort(newAddress)
+	if err != nil {
+		return
+	}
+
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	now := clock.Now()
+	for key, peer := range pe.peers {
+		host, port, err := net.SplitHostPort(peer.Address)
+		if err != nil || host != oldHost || !peer.Endpoint.SameNode(peerID) {
+			continue
+		}
+
+		delete(pe.peers, key)
+		peer.Address = net.JoinHostPort(newHost, port)
+		peer.LastSeen = now
+		pe.peers[peerKey(peer)] = peer
+
+		log.WithFields(log.Fields{
+			"peer":    peerID,
+			"type":    peer.Type,
+			"address": peer.Address,
+		}).Debug("Updated address of moved peer")
+	}
+}
+
+// sendPeers sends the list of known peers as a bundle to the peer's PeerExchangeEndpoint, or floods it within the
+// configured hops, see SetScopedFlood.
+func (pe *PeerExchange) sendPeers(peerID bpv7.EndpointID) error {
+	pe.mutex.Lock()
+	floodHops := pe.floodHops
+	pe.mutex.Unlock()
+
+	// the receiving peer does not need to learn about itself, unlike the other nodes reached by a flood
+	var peers []PeerInfo
+	for _, peer := range pe.Peers() {
+		if floodHops > 0 || !peer.Endpoint.SameNode(peerID) {
+			peers = append(peers, peer)
+		}
+	}
+	data, err := MarshalPeerInfos(peers)
+	if err != nil {
+		return err
+	}
+
+	var bndl bpv7.Bundle
+	if floodHops > 0 {
+		bndl, err = routing.NewScopedFlood(pe.endpoint, peerExchangeDemux, floodHops, peerExchangeLifetime, data)
+	} else {
+		destination, destErr := PeerExchangeEndpoint(peerID)
+		if destErr != nil {
+			return destErr
+		}
+		bndl, err = bpv7.Builder().
+			Source(pe.endpoint).
+			Destination(destination).
+			CreationTimestampNow().
+			Lifetime(peerExchangeLifetime).
+			PayloadBlock(data).
+			Build()
+	}
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"peer":  peerID,
+		"peers": len(peers),
+		"hops":  floodHops,
+	}).Debug("Sending known peers")
+	application_agent.GetManagerSingleton().Send(&bndl)
+	return nil
+}
+
+// Endpoints returns the PeerExchangeEndpoint of this node and the endpoint of flooded peer lists.
+func (pe *PeerExchange) Endpoints() []bpv7.EndpointID {
+	return []bpv7.EndpointID{pe.endpoint, pe.floodEndpoint}
+}
+
+// Deliver merges a received list of peers and connects to newly learned ones.
+func (pe *PeerExchange) Deliver(bundleDescriptor *store.BundleDescriptor) error {
+	if bundleDescriptor.Destination != pe.endpoint && bundleDescriptor.Destination != pe.floodEndpoint {
+		return nil
+	}
+
+	bndl, err := bundleDescriptor.Load()
+	if err != nil {
+		return err
+	}
+	payload, err := bndl.PayloadBlock()
+	if err != nil {
+		return err
+	}
+	peers, err := UnmarshalPeerInfos(payload.Value.(*bpv7.PayloadBlock).Data())
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"source": bndl.PrimaryBlock.SourceNode,
+		"peers":  len(peers),
+	}).Debug("Received known peers")
+
+	for _, peer := range peers {
+		if !pe.AddPeer(peer) {
+			continue
+		}
+
+		conv, err := newConvergence(pe.nodeID, peer.Type, peer.Address, peer.Endpoint, pe.receiveCallback)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"peer":  peer,
+				"error": err,
+			}).Debug("Cannot connect to exchanged peer")
+			continue
+		}
+		log.WithField("peer", peer).Info("Connecting to exchanged peer")
+		cla.GetManagerSingleton().Register(conv)
+	}
+	return nil
+}
+
+func (pe *PeerExchange) Shutdown() {
+	peerExchangeSingleton = nil
+}
+
+// newConvergence creates a new, not yet activated, CLA to connect to a peer.
+func newConvergence(nodeID bpv7.EndpointID, claType cla.CLAType, address string, peerID bpv7.EndpointID, receiveCallback func(*bpv7.Bundle)) (cla.Convergence, error) {
+	switch claType {
+	case cla.MTCP:
+		return mtcp.NewMTCPClient(address, peerID), nil
+	case cla.QUICL:
+		return quicl.NewDialerEndpoint(address, nodeID, receiveCallback), nil
+	default:
+		return nil, cla.NewUnsupportedCLATypeError(claType)
+	}
+}
@@ -0,0 +1,121 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// PeerInfo describes how to reach a known peer, as exchanged between nodes.
+type PeerInfo struct {
+	Endpoint bpv7.EndpointID
+	Type     cla.CLAType
+	Address  string
+	LastSeen time.Time
+}
+
+// UnmarshalPeerInfos creates a new array of PeerInfo based on a CBOR byte string.
+func UnmarshalPeerInfos(data []byte) (peers []PeerInfo, err error) {
+	buff := bytes.NewBuffer(data)
+
+	if l, cErr := cboring.ReadArrayLength(buff); cErr != nil {
+		err = cErr
+		return
+	} else {
+		peers = make([]PeerInfo, l)
+	}
+
+	for i := 0; i < len(peers); i++ {
+		if cErr := cboring.Unmarshal(&peers[i], buff); cErr != nil {
+			err = fmt.Errorf("unmarshalling PeerInfo %d failed: %v", i, cErr)
+			return
+		}
+	}
+
+	return
+}
+
+// MarshalPeerInfos into a CBOR byte string.
+func MarshalPeerInfos(peers []PeerInfo) (data []byte, err error) {
+	buff := new(bytes.Buffer)
+
+	if cErr := cboring.WriteArrayLength(uint64(len(peers)), buff); cErr != nil {
+		err = cErr
+		return
+	}
+
+	for i := range peers {
+		peer := peers[i]
+		if cErr := cboring.Marshal(&peer, buff); cErr != nil {
+			err = fmt.Errorf("marshalling PeerInfo %d (%v) failed: %v", i, peer, cErr)
+			return
+		}
+	}
+
+	data = buff.Bytes()
+	return
+}
+
+// MarshalCbor creates a CBOR representation for a PeerInfo. LastSeen is encoded as a DtnTime.
+func (peer *PeerInfo) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(4, w); err != nil {
+		return err
+	}
+
+	if err := cboring.Marshal(&peer.Endpoint, w); err != nil {
+		return fmt.Errorf("marshalling endpoint failed: %v", err)
+	}
+	if err := cboring.WriteUInt(uint64(peer.Type), w); err != nil {
+		return err
+	}
+	if err := cboring.WriteTextString(peer.Address, w); err != nil {
+		return err
+	}
+	if err := cboring.WriteUInt(uint64(bpv7.DtnTimeFromTime(peer.LastSeen)), w); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnmarshalCbor creates a PeerInfo from its CBOR representation.
+func (peer *PeerInfo) UnmarshalCbor(r io.Reader) error {
+	if l, err := cboring.ReadArrayLength(r); err != nil {
+		return err
+	} else if l != 4 {
+		return fmt.Errorf("wrong array length: %d instead of 4", l)
+	}
+
+	if err := cboring.Unmarshal(&peer.Endpoint, r); err != nil {
+		return fmt.Errorf("unmarshalling endpoint failed: %v", err)
+	}
+	if n, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else if claType := cla.CLAType(n); claType.CheckValid() != nil {
+		return claType.CheckValid()
+	} else {
+		peer.Type = claType
+	}
+	if address, err := cboring.ReadTextString(r); err != nil {
+		return err
+	} else {
+		peer.Address = address
+	}
+	if n, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		peer.LastSeen = bpv7.DtnTime(n).Time()
+	}
+
+	return nil
+}
+
+func (peer PeerInfo) String() string {
+	return fmt.Sprintf("PeerInfo(%v,%v,%s,%v)", peer.Endpoint, peer.Type, peer.Address, peer.LastSeen)
+}
@@ -0,0 +1,111 @@
+package discovery
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestPeerInfoCbor(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		numPeers := rapid.IntRange(0, 10).Draw(t, "number of peers")
+		peersIn := make([]PeerInfo, numPeers)
+		for i := range peersIn {
+			endpoint, err := bpv7.NewEndpointID(rapid.StringMatching(bpv7.DtnEndpointRegexpNotNone).Draw(t, fmt.Sprintf("endpoint %v", i)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// DtnTime has a millisecond precision
+			lastSeen := bpv7.DtnTime(rapid.Uint64Max(1<<40).Draw(t, fmt.Sprintf("last seen %v", i))).Time()
+
+			peersIn[i] = PeerInfo{
+				Endpoint: endpoint,
+				Type:     rapid.SampledFrom([]cla.CLAType{cla.MTCP, cla.QUICL, cla.TCPCLv4}).Draw(t, fmt.Sprintf("type %v", i)),
+				Address:  rapid.String().Draw(t, fmt.Sprintf("address %v", i)),
+				LastSeen: lastSeen,
+			}
+		}
+
+		data, err := MarshalPeerInfos(peersIn)
+		if err != nil {
+			t.Fatalf("Encoding failed: %v", err)
+		}
+
+		peersOut, err := UnmarshalPeerInfos(data)
+		if err != nil {
+			t.Fatalf("Decoding failed: %v", err)
+		}
+
+		if !reflect.DeepEqual(peersIn, peersOut) {
+			t.Fatalf("Decoded PeerInfos differ: %v became %v", peersIn, peersOut)
+		}
+	})
+}
+
+func TestPeerExchangeAddPeer(t *testing.T) {
+	pe := &PeerExchange{
+		nodeID: bpv7.MustNewEndpointID("dtn://self/"),
+		maxAge: time.Hour,
+		peers:  make(map[string]PeerInfo),
+	}
+
+	now := time.Now()
+	peer := PeerInfo{Endpoint: bpv7.MustNewEndpointID("dtn://other/"), Type: cla.MTCP, Address: "10.0.0.2:35037", LastSeen: now}
+
+	if !pe.AddPeer(peer) {
+		t.Fatal("New peer was not reported as new")
+	}
+	if pe.AddPeer(PeerInfo{Endpoint: peer.Endpoint, Type: peer.Type, Address: peer.Address, LastSeen: now.Add(-time.Minute)}) {
+		t.Fatal("Known peer was reported as new")
+	}
+	if peers := pe.Peers(); len(peers) != 1 || !peers[0].LastSeen.Equal(now) {
+		t.Fatalf("Older information replaced newer one: %v", peers)
+	}
+
+	if pe.AddPeer(PeerInfo{Endpoint: bpv7.MustNewEndpointID("dtn://self/foo"), Type: cla.MTCP, Address: "10.0.0.1:35037", LastSeen: now}) {
+		t.Fatal("Own node was added as a peer")
+	}
+
+	pe.AddPeer(PeerInfo{Endpoint: bpv7.MustNewEndpointID("dtn://stale/"), Type: cla.MTCP, Address: "10.0.0.3:35037", LastSeen: now.Add(-2 * time.Hour)})
+	if peers := pe.Peers(); len(peers) != 1 {
+		t.Fatalf("Stale peer was passed on: %v", peers)
+	}
+}
+
+func TestPeerExchangePeerMoved(t *testing.T) {
+	pe := &PeerExchange{
+		nodeID: bpv7.MustNewEndpointID("dtn://self/"),
+		maxAge: time.Hour,
+		peers:  make(map[string]PeerInfo),
+	}
+
+	now := time.Now()
+	peer := bpv7.MustNewEndpointID("dtn://mobile/")
+	pe.AddPeer(PeerInfo{Endpoint: peer, Type: cla.QUICL, Address: "10.0.0.2:35037", LastSeen: now})
+	pe.AddPeer(PeerInfo{Endpoint: bpv7.MustNewEndpointID("dtn://other/"), Type: cla.MTCP, Address: "10.0.0.2:35038", LastSeen: now})
+
+	pe.PeerMoved(peer, "10.0.0.2:35037", "192.168.1.7:41234")
+
+	for _, info := range pe.Peers() {
+		switch info.Endpoint {
+		case peer:
+			if info.Address != "192.168.1.7:35037" {
+				t.Fatalf("Moved peer has address %s", info.Address)
+			}
+		default:
+			if info.Address != "10.0.0.2:35038" {
+				t.Fatalf("Other peer on the same host was moved to %s", info.Address)
+			}
+		}
+	}
+	if peers := pe.Peers(); len(peers) != 2 {
+		t.Fatalf("Peers %v", peers)
+	}
+}
@@ -0,0 +1,221 @@
+// Package fault_injection provides optional hooks to deliberately disturb a node, e.g., by dropping outgoing bundles
+// or failing store writes, to systematically test the resilience of the processing pipeline.
+//
+// Faults are only injected after InitialiseInjector was called. Otherwise, all hooks are no-ops.
+package fault_injection
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+// InjectedFaultError is returned by hooks which inject a failure.
+type InjectedFaultError string
+
+func NewInjectedFaultError(operation string) *InjectedFaultError {
+	err := InjectedFaultError(operation)
+	return &err
+}
+
+func (err *InjectedFaultError) Error() string {
+	return fmt.Sprintf("injected fault: %s failed", string(*err))
+}
+
+// Config describes which faults are injected. The zero value disables all faults.
+type Config struct {
+	// DropProbability is the chance of silently discarding an outgoing bundle, which is still reported as sent.
+	DropProbability float64
+	// SendDelay is the maximum random delay before each outgoing transmission.
+	SendDelay time.Duration
+	// StoreFailureProbability is the chance of failing a write to the bundle store.
+	StoreFailureProbability float64
+	// DisconnectInterval is the interval for disconnecting a random CLA; zero disables disconnects.
+	DisconnectInterval time.Duration
+}
+
+// CheckValid checks the probabilities' range and that durations are not negative.
+func (config Config) CheckValid() error {
+	if config.DropProbability < 0 || config.DropProbability > 1 {
+		return fmt.Errorf("drop probability %v is not between 0 and 1", config.DropProbability)
+	}
+	if config.StoreFailureProbability < 0 || config.StoreFailureProbability > 1 {
+		return fmt.Errorf("store failure probability %v is not between 0 and 1", config.StoreFailureProbability)
+	}
+	if config.SendDelay < 0 {
+		return fmt.Errorf("send delay %v is negative", config.SendDelay)
+	}
+	if config.DisconnectInterval < 0 {
+		return fmt.Errorf("disconnect interval %v is negative", config.DisconnectInterval)
+	}
+	return nil
+}
+
+// injectorSingleton is read by the hooks of concurrent transmissions and store writes, while it might be shut down.
+var injectorSingleton atomic.Pointer[Injector]
+
+// Injector decides which faults to inject, based on its Config.
+type Injector struct {
+	mutex  sync.RWMutex
+	config Config
+
+	// disconnectCallback disconnects a random CLA.
+	// This is necessary since we can't import the cla package without creating an import loop
+	disconnectCallback func()
+
+	reconfigured chan struct{}
+	stop         chan struct{}
+}
+
+// InitialiseInjector initialises the Injector singleton and starts injecting faults.
+func InitialiseInjector(config Config, disconnectCallback func()) error {
+	if err := config.CheckValid(); err != nil {
+		return err
+	}
+
+	inj := &Injector{
+		config:             config,
+		disconnectCallback: disconnectCallback,
+		reconfigured:       make(chan struct{}, 1),
+		stop:               make(chan struct{}),
+	}
+	if !injectorSingleton.CompareAndSwap(nil, inj) {
+		return util.NewAlreadyInitialisedError("Fault Injector")
+	}
+	go inj.disconnectLoop()
+
+	log.WithFields(log.Fields{
+		"drop probability":          config.DropProbability,
+		"send delay":                config.SendDelay,
+		"store failure probability": config.StoreFailureProbability,
+		"disconnect interval":       config.DisconnectInterval,
+	}).Warn("Fault injection is active")
+
+	return nil
+}
+
+// Initialised checks if the Injector singleton exists. Otherwise, no faults are injected.
+func Initialised() bool {
+	return injectorSingleton.Load() != nil
+}
+
+// GetInjectorSingleton returns the Injector singleton.
+func GetInjectorSingleton() *Injector {
+	inj := injectorSingleton.Load()
+	if inj == nil {
+		log.Fatalf("Attempting to access an uninitialised Fault Injector. This must never happen!")
+	}
+	return inj
+}
+
+// Config returns the current Config.
+func (inj *Injector) Config() Config {
+	inj.mutex.RLock()
+	defer inj.mutex.RUnlock()
+	return inj.config
+}
+
+// Reconfigure replaces the current Config at runtime.
+func (inj *Injector) Reconfigure(config Config) error {
+	if err := config.CheckValid(); err != nil {
+		return err
+	}
+
+	inj.mutex.Lock()
+	inj.config = config
+	inj.mutex.Unlock()
+
+	select {
+	case inj.reconfigured <- struct{}{}:
+	default:
+	}
+
+	log.WithFields(log.Fields{
+		"drop probability":          config.DropProbability,
+		"send delay":                config.SendDelay,
+		"store failure probability": config.StoreFailureProbability,
+		"disconnect interval":       config.DisconnectInterval,
+	}).Warn("Fault injection reconfigured")
+	return nil
+}
+
+// Shutdown stops injecting faults and resets the singleton.
+func (inj *Injector) Shutdown() {
+	close(inj.stop)
+	injectorSingleton.CompareAndSwap(inj, nil)
+}
+
+// disconnectLoop calls the disconnectCallback every DisconnectInterval until Shutdown.
+func (inj *Injector) disconnectLoop() {
+	for {
+		var (
+			timer   clock.Timer
+			timeout <-chan time.Time
+		)
+		if interval := inj.Config().DisconnectInterval; interval > 0 {
+			timer = clock.NewTimer(interval)
+			timeout = timer.C()
+		}
+
+		select {
+		case <-inj.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case <-inj.reconfigured:
+			if timer != nil {
+				timer.Stop()
+			}
+
+		case <-timeout:
+			log.Info("Injecting random CLA disconnect")
+			inj.disconnectCallback()
+		}
+	}
+}
+
+// chance is true with the given probability.
+func chance(probability float64) bool {
+	return probability > 0 && rand.Float64() < probability
+}
+
+// DropSend decides if an outgoing bundle should be silently discarded.
+func DropSend() bool {
+	inj := injectorSingleton.Load()
+	if inj == nil {
+		return false
+	}
+	return chance(inj.Config().DropProbability)
+}
+
+// DelaySend blocks for a random duration up to the configured SendDelay.
+func DelaySend() {
+	inj := injectorSingleton.Load()
+	if inj == nil {
+		return
+	}
+	if maxDelay := inj.Config().SendDelay; maxDelay > 0 {
+		clock.Sleep(rand.N(maxDelay))
+	}
+}
+
+// StoreWrite returns an InjectedFaultError if the next store write should fail.
+func StoreWrite() error {
+	inj := injectorSingleton.Load()
+	if inj == nil {
+		return nil
+	}
+	if chance(inj.Config().StoreFailureProbability) {
+		return NewInjectedFaultError("store write")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package fault_injection
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+func TestConfigCheckValid(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		config := Config{
+			DropProbability:         rapid.Float64Range(-1, 2).Draw(t, "drop"),
+			StoreFailureProbability: rapid.Float64Range(-1, 2).Draw(t, "store"),
+			SendDelay:               time.Duration(rapid.Int64Range(-10, 10).Draw(t, "delay")),
+			DisconnectInterval:      time.Duration(rapid.Int64Range(-10, 10).Draw(t, "disconnect")),
+		}
+
+		valid := config.DropProbability >= 0 && config.DropProbability <= 1 &&
+			config.StoreFailureProbability >= 0 && config.StoreFailureProbability <= 1 &&
+			config.SendDelay >= 0 && config.DisconnectInterval >= 0
+		if err := config.CheckValid(); valid != (err == nil) {
+			t.Fatalf("Config %v has validity %t, but error %v", config, valid, err)
+		}
+	})
+}
+
+func TestInjector(t *testing.T) {
+	fc := clock.NewFakeClock(time.Unix(0, 0))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	if DropSend() || StoreWrite() != nil {
+		t.Fatal("Uninitialised injector injected a fault")
+	}
+
+	disconnects := make(chan struct{}, 10)
+	if err := InitialiseInjector(Config{}, func() { disconnects <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+	defer GetInjectorSingleton().Shutdown()
+
+	if DropSend() || StoreWrite() != nil {
+		t.Fatal("Zero config injected a fault")
+	}
+
+	err := GetInjectorSingleton().Reconfigure(Config{
+		DropProbability:         1,
+		StoreFailureProbability: 1,
+		DisconnectInterval:      time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !DropSend() {
+		t.Fatal("Bundle was not dropped")
+	}
+	var faultErr *InjectedFaultError
+	if err := StoreWrite(); !errors.As(err, &faultErr) {
+		t.Fatalf("Expected InjectedFaultError, got %v", err)
+	}
+
+	fc.BlockUntil(1)
+	fc.Advance(time.Minute)
+	select {
+	case <-disconnects:
+	case <-time.After(time.Second):
+		t.Fatal("No disconnect was injected")
+	}
+
+	if err := GetInjectorSingleton().Reconfigure(Config{DropProbability: 2}); err == nil {
+		t.Fatal("Invalid config was accepted")
+	}
+}
+
+func TestInjectorConcurrentShutdown(t *testing.T) {
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					DropSend()
+					DelaySend()
+					_ = StoreWrite()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := InitialiseInjector(Config{DropProbability: 0.5}, func() {}); err != nil {
+			t.Fatal(err)
+		}
+		GetInjectorSingleton().Shutdown()
+	}
+
+	close(stop)
+	wg.Wait()
+
+	if Initialised() {
+		t.Fatal("Injector is still initialised after its shutdown")
+	}
+}
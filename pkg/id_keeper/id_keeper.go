@@ -6,6 +6,10 @@
 package id_keeper
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"sync"
 
 	log "github.com/sirupsen/logrus"
@@ -14,42 +18,71 @@ import (
 	"github.com/dtn7/dtn7-go/pkg/util"
 )
 
-// idTuple is a tuple struct for looking up a bundle's ID - based on its source
-// node and DTN time part of the creation timestamp.
-type idTuple struct {
-	source bpv7.EndpointID
-	time   bpv7.DtnTime
-}
-
-// newIdTuple creates an idTuple based on the given bundle.
-func newIdTuple(bndl *bpv7.Bundle) idTuple {
-	return idTuple{
-		source: bndl.PrimaryBlock.SourceNode,
-		time:   bndl.PrimaryBlock.CreationTimestamp.DtnTime(),
-	}
-}
+// reserveBlock is the amount of sequence numbers reserved by each write of a persistent IdKeeper's state file.
+// Thus, the file is only written once per reserveBlock bundles and a restarted node skips at most as many numbers.
+const reserveBlock = 64
 
 var idKeeperSingleton *IdKeeper
 
 // IdKeeper keeps track of the creation timestamp's sequence number for
 // outgoing bundles.
+//
+// Sequence numbers are monotonic for each source node, independent of the
+// creation timestamp's DTN time. Thus, bundle IDs stay unique even for
+// clockless nodes, only using the epoch time, or if the clock is set back.
 type IdKeeper struct {
-	data  map[idTuple]uint64
+	data  map[bpv7.EndpointID]uint64 // source node -> next sequence number
 	mutex sync.Mutex
+
+	// filename of the persisted state, empty for an in-memory IdKeeper.
+	filename string
+	// reserved sequence numbers by source node; all numbers below are persisted as used.
+	reserved map[bpv7.EndpointID]uint64
 }
 
+// InitializeIdKeeper initialises an in-memory IdKeeper, starting all sequence numbers at zero.
 func InitializeIdKeeper() error {
 	if idKeeperSingleton != nil {
 		return util.NewAlreadyInitialisedError("IdKeeper")
 	}
 
 	idKeeperSingleton = &IdKeeper{
-		data: make(map[idTuple]uint64),
+		data: make(map[bpv7.EndpointID]uint64),
 	}
 
 	return nil
 }
 
+// InitializePersistentIdKeeper initialises an IdKeeper whose sequence numbers are persisted in the given file.
+// A restarted node continues the sequence numbers from this file, such that it never reuses a bundle ID.
+func InitializePersistentIdKeeper(filename string) error {
+	if idKeeperSingleton != nil {
+		return util.NewAlreadyInitialisedError("IdKeeper")
+	}
+
+	reserved, err := loadReserved(filename)
+	if err != nil {
+		return err
+	}
+
+	idk := &IdKeeper{
+		data:     make(map[bpv7.EndpointID]uint64, len(reserved)),
+		filename: filename,
+		reserved: reserved,
+	}
+	for source, sequence := range reserved {
+		idk.data[source] = sequence
+	}
+
+	idKeeperSingleton = idk
+	log.WithFields(log.Fields{
+		"file":    filename,
+		"sources": len(reserved),
+	}).Debug("Loaded persisted sequence numbers")
+
+	return nil
+}
+
 func GetIdKeeperSingleton() *IdKeeper {
 	if idKeeperSingleton == nil {
 		log.Fatalf("Attempting to access an uninitialised IdKeeper. This must never happen!")
@@ -57,32 +90,74 @@ func GetIdKeeperSingleton() *IdKeeper {
 	return idKeeperSingleton
 }
 
-// Update updates the IdKeeper's state regarding this bundle and sets this
-// bundle's sequence number.
-func (idk *IdKeeper) Update(bndl *bpv7.Bundle) {
-	var tpl = newIdTuple(bndl)
+// ShutdownIdKeeper resets the singleton, e.g., to simulate a restart.
+func ShutdownIdKeeper() {
+	idKeeperSingleton = nil
+}
 
-	idk.mutex.Lock()
-	defer idk.mutex.Unlock()
-	if state, ok := idk.data[tpl]; ok {
-		idk.data[tpl] = state + 1
-	} else {
-		idk.data[tpl] = 0
+// loadReserved reads the reserved sequence numbers from a state file. A missing file has no reservations yet.
+func loadReserved(filename string) (map[bpv7.EndpointID]uint64, error) {
+	reserved := make(map[bpv7.EndpointID]uint64)
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return reserved, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var state map[string]uint64
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing sequence numbers %s: %w", filename, err)
+	}
+	for source, sequence := range state {
+		eid, err := bpv7.NewEndpointID(source)
+		if err != nil {
+			return nil, fmt.Errorf("parsing sequence numbers %s: %w", filename, err)
+		}
+		reserved[eid] = sequence
+	}
+	return reserved, nil
+}
+
+// persist writes the reserved sequence numbers atomically to the state file.
+func (idk *IdKeeper) persist() error {
+	state := make(map[string]uint64, len(idk.reserved))
+	for source, sequence := range idk.reserved {
+		state[source.String()] = sequence
 	}
 
-	bndl.PrimaryBlock.CreationTimestamp[1] = idk.data[tpl]
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := idk.filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		_ = os.Remove(tmpFile)
+		return err
+	}
+	return os.Rename(tmpFile, idk.filename)
 }
 
-// Clean removes states which are older an hour and aren't the epoch time.
-func (idk *IdKeeper) Clean() {
+// Update updates the IdKeeper's state regarding this bundle and sets this
+// bundle's sequence number.
+func (idk *IdKeeper) Update(bndl *bpv7.Bundle) {
+	var source = bndl.PrimaryBlock.SourceNode
+
 	idk.mutex.Lock()
 	defer idk.mutex.Unlock()
 
-	var threshold = bpv7.DtnTimeNow() - 60
+	sequence := idk.data[source]
+	idk.data[source] = sequence + 1
 
-	for tpl := range idk.data {
-		if tpl.time < threshold {
-			delete(idk.data, tpl)
+	if idk.filename != "" && sequence >= idk.reserved[source] {
+		idk.reserved[source] = sequence + reserveBlock
+		if err := idk.persist(); err != nil {
+			log.WithError(err).WithField("file", idk.filename).Error(
+				"Failed to persist sequence numbers, a restart might reuse bundle IDs")
 		}
 	}
+
+	bndl.PrimaryBlock.CreationTimestamp[1] = sequence
 }
@@ -6,6 +6,8 @@
 package id_keeper
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
@@ -53,3 +55,64 @@ func TestIdKeeper(t *testing.T) {
 		t.Errorf("Second bundle's sequence number is %d", seq)
 	}
 }
+
+func TestIdKeeperPersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "sequence_numbers.json")
+
+	newBundle := func(source string) bpv7.Bundle {
+		bndl, err := bpv7.Builder().
+			Source(source).
+			Destination("dtn://dest/").
+			CreationTimestampEpoch().
+			Lifetime("60s").
+			BundleAgeBlock(0, bpv7.DeleteBundle).
+			PayloadBlock([]byte("hello world!")).
+			Build()
+		if err != nil {
+			t.Fatalf("Creating bundle failed: %v", err)
+		}
+		return bndl
+	}
+
+	ShutdownIdKeeper()
+	defer ShutdownIdKeeper()
+
+	seen := make(map[string]bool)
+	for restart := 0; restart < 3; restart++ {
+		if err := InitializePersistentIdKeeper(filename); err != nil {
+			t.Fatal(err)
+		}
+
+		var last uint64
+		for i := 0; i < reserveBlock+1; i++ {
+			bndl := newBundle("dtn://src/")
+			GetIdKeeperSingleton().Update(&bndl)
+
+			if seen[bndl.ID().String()] {
+				t.Fatalf("Bundle ID %v was reused after %d restarts", bndl.ID(), restart)
+			}
+			seen[bndl.ID().String()] = true
+
+			if seq := bndl.PrimaryBlock.CreationTimestamp.SequenceNumber(); i > 0 && seq != last+1 {
+				t.Fatalf("Sequence number %d does not follow %d", seq, last)
+			} else {
+				last = seq
+			}
+		}
+
+		other := newBundle("dtn://other/")
+		GetIdKeeperSingleton().Update(&other)
+		if restart == 0 && other.PrimaryBlock.CreationTimestamp.SequenceNumber() != 0 {
+			t.Fatalf("Other source's first sequence number is %d", other.PrimaryBlock.CreationTimestamp.SequenceNumber())
+		}
+
+		ShutdownIdKeeper()
+	}
+
+	if err := os.WriteFile(filename, []byte("{\"not an endpoint\": 1}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitializePersistentIdKeeper(filename); err == nil {
+		t.Fatal("Invalid state file was accepted")
+	}
+}
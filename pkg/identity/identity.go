@@ -0,0 +1,189 @@
+// Package identity binds a node ID to an ed25519 key pair by an X.509 certificate. Like TCPCLv4's certificates of
+// RFC 9174 section 4.4.1, the node ID is the certificate's URI subject alternative name.
+//
+// The same key authenticates the node's TLS connections, e.g., of the QUICL, and signs its bundles, see
+// bpv7.SignatureBlock. Thus, a certificate also binds the public key of a bundle's signature to its source node.
+//
+// Certificates are either self-signed or requested from a certificate authority by a certificate signing request.
+// Keys are stored as their hex encoded seeds, certificates and requests PEM encoded.
+package identity
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// GenerateKey creates an ed25519 private key and stores it as its hex encoded seed, readable only by its owner.
+func GenerateKey(filename string) (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filename, []byte(hex.EncodeToString(priv.Seed())+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// LoadKey reads an ed25519 private key created by GenerateKey.
+func LoadKey(filename string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("key has %d instead of %d bytes", len(seed), ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// nodeURI is the subject alternative name of a node ID.
+func nodeURI(nodeID bpv7.EndpointID) (*url.URL, error) {
+	if err := nodeID.CheckValid(); err != nil {
+		return nil, err
+	}
+	if nodeID.SameNode(bpv7.DtnNone()) {
+		return nil, fmt.Errorf("node ID must not be dtn:none")
+	}
+
+	uri, err := url.Parse(nodeID.String())
+	if err != nil {
+		return nil, err
+	} else if uri.String() != nodeID.String() {
+		return nil, fmt.Errorf("node ID %v is no plain URI", nodeID)
+	}
+	return uri, nil
+}
+
+// CreateCertificate creates a PEM encoded certificate, self-signed by the key, binding the node ID to its public key.
+func CreateCertificate(key ed25519.PrivateKey, nodeID bpv7.EndpointID, notBefore, notAfter time.Time) ([]byte, error) {
+	uri, err := nodeURI(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if !notAfter.After(notBefore) {
+		return nil, fmt.Errorf("certificate expires at %v, before it becomes valid at %v", notAfter, notBefore)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: nodeID.String()},
+		URIs:         []*url.URL{uri},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// CreateRequest creates a PEM encoded certificate signing request for a certificate authority, binding the node ID to
+// the key's public key.
+func CreateRequest(key ed25519.PrivateKey, nodeID bpv7.EndpointID) ([]byte, error) {
+	uri, err := nodeURI(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: nodeID.String()},
+		URIs:    []*url.URL{uri},
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// ParseCertificate parses the first PEM encoded certificate.
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			return nil, fmt.Errorf("no PEM encoded certificate found")
+		} else if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// NodeID returns the node ID bound by a certificate, its only URI subject alternative name being an endpoint ID.
+func NodeID(cert *x509.Certificate) (nodeID bpv7.EndpointID, err error) {
+	found := false
+	for _, uri := range cert.URIs {
+		eid, eidErr := bpv7.NewEndpointID(uri.String())
+		if eidErr != nil {
+			continue
+		} else if found {
+			return bpv7.EndpointID{}, fmt.Errorf("certificate binds more than one node ID")
+		}
+
+		nodeID, found = eid, true
+	}
+
+	if !found {
+		return bpv7.EndpointID{}, fmt.Errorf("certificate binds no node ID")
+	}
+	return nodeID, nil
+}
+
+// Matches checks if the certificate was issued for the key.
+func Matches(cert *x509.Certificate, key ed25519.PrivateKey) bool {
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	return ok && bytes.Equal(pub, key.Public().(ed25519.PublicKey))
+}
+
+// LoadCertificate reads a certificate and its key for TLS. The certificate must bind a node ID to the key.
+func LoadCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if _, err := NodeID(cert); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	key, err := LoadKey(keyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if !Matches(cert, key) {
+		return tls.Certificate{}, fmt.Errorf("certificate %s was not issued for key %s", certFile, keyFile)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}, nil
+}
@@ -0,0 +1,90 @@
+package identity
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestCertificate(t *testing.T) {
+	dir := t.TempDir()
+
+	rapid.Check(t, func(t *rapid.T) {
+		nodeID, err := bpv7.NewEndpointID(rapid.StringMatching("^dtn://[a-z0-9-]{1,16}/$").Draw(t, "nodeID"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyFile, certFile := filepath.Join(dir, "node.key"), filepath.Join(dir, "node.crt")
+		key, err := GenerateKey(keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Now()
+		data, err := CreateCertificate(key, nodeID, now, now.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(certFile, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cert, err := LoadCertificate(certFile, keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if certNodeID, err := NodeID(cert.Leaf); err != nil {
+			t.Fatal(err)
+		} else if certNodeID != nodeID {
+			t.Fatalf("Certificate binds %v instead of %v", certNodeID, nodeID)
+		}
+
+		// another key must not be accepted for the certificate
+		if _, err := GenerateKey(keyFile); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCertificate(certFile, keyFile); err == nil {
+			t.Fatal("Certificate was loaded with another key")
+		}
+	})
+}
+
+func TestRequest(t *testing.T) {
+	key, err := GenerateKey(filepath.Join(t.TempDir(), "node.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeID := bpv7.MustNewEndpointID("ipn:23.1")
+	data, err := CreateRequest(key, nodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		t.Fatalf("Request is no PEM encoded certificate request: %s", data)
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.CheckSignature(); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.URIs) != 1 || req.URIs[0].String() != nodeID.String() {
+		t.Fatalf("Request binds %v instead of %v", req.URIs, nodeID)
+	}
+
+	if _, err := CreateRequest(key, bpv7.DtnNone()); err == nil {
+		t.Fatal("Request was created for dtn:none")
+	}
+}
@@ -0,0 +1,199 @@
+// Package journal persists each attempted transmission of a bundle, as reported by the cla.Manager, to an append-only
+// file. Thus, post-experiment analyses and the accounting of forwarded traffic, e.g., for community networks, can be
+// derived from the node itself.
+//
+// The journal is a JSON Lines file, one Entry per line, which can be processed by common tools or summarised per peer
+// by Summarise, e.g., by "dtn-tool journal".
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// Result of an attempted transmission.
+type Result string
+
+const (
+	// Sent bundles were passed to the CLA successfully.
+	Sent Result = "sent"
+
+	// Failed transmissions were refused by the CLA, e.g., due to a lost connection.
+	Failed Result = "failed"
+
+	// Expired bundles exceeded their lifetime before their transmission, which was cancelled.
+	Expired Result = "expired"
+)
+
+// Entry is a journaled transmission.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Peer       string    `json:"peer"`
+	Address    string    `json:"address"`
+	Bundle     string    `json:"bundle"`
+	Result     Result    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	Bytes      uint64    `json:"bytes"`
+	DurationMs float64   `json:"duration_ms"`
+}
+
+// NewEntry describes a cla.Transmission.
+func NewEntry(transmission cla.Transmission) Entry {
+	entry := Entry{
+		Time:       transmission.Start.UTC(),
+		Peer:       transmission.Peer.String(),
+		Address:    transmission.Address,
+		Bundle:     transmission.Bundle.String(),
+		Result:     Sent,
+		Bytes:      transmission.Bytes,
+		DurationMs: float64(transmission.Duration) / float64(time.Millisecond),
+	}
+
+	switch {
+	case errors.Is(transmission.Err, cla.ErrBundleExpired):
+		entry.Result = Expired
+	case transmission.Err != nil:
+		entry.Result = Failed
+		entry.Error = transmission.Err.Error()
+	}
+	return entry
+}
+
+// Journal appends Entries to a file.
+type Journal struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// Open a journal file for appending, creating it if necessary.
+func Open(filename string) (*Journal, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Append an Entry to the journal.
+// This method is thread-safe.
+func (journal *Journal) Append(entry Entry) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.file == nil {
+		return fmt.Errorf("journal is closed")
+	}
+	return journal.encoder.Encode(entry)
+}
+
+// Record journals a transmission, logging failures. It is meant to be subscribed to the cla.Manager's transfer events.
+func (journal *Journal) Record(transmission cla.Transmission) {
+	if err := journal.Append(NewEntry(transmission)); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": transmission.Bundle,
+			"error":  err,
+		}).Warn("Failed to journal transmission")
+	}
+}
+
+// Close the journal after flushing it to the disk.
+func (journal *Journal) Close() error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.file == nil {
+		return nil
+	}
+	syncErr := journal.file.Sync()
+	closeErr := journal.file.Close()
+	journal.file = nil
+
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+// ReadEntries parses a journal. A truncated last line, e.g., after a crash, is ignored.
+func ReadEntries(r io.Reader) (entries []Entry, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var pending error
+	for line := 1; scanner.Scan(); line++ {
+		if pending != nil {
+			return nil, pending
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			pending = fmt.Errorf("line %d: %v", line, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// PeerSummary accounts the transmissions to one peer.
+type PeerSummary struct {
+	Peer     string
+	Attempts uint64
+	Sent     uint64
+	Failed   uint64
+	Expired  uint64
+	// SentBytes is the size of all successfully sent bundles.
+	SentBytes uint64
+	// Duration of all attempts, including their waiting time for the traffic shaping.
+	Duration time.Duration
+}
+
+// Summarise journaled transmissions per peer, ordered by the peers' endpoint IDs. Only entries within [from, to) are
+// considered; zero times are unbounded.
+func Summarise(entries []Entry, from, to time.Time) []PeerSummary {
+	summaries := make(map[string]*PeerSummary)
+	for _, entry := range entries {
+		if (!from.IsZero() && entry.Time.Before(from)) || (!to.IsZero() && !entry.Time.Before(to)) {
+			continue
+		}
+
+		summary, ok := summaries[entry.Peer]
+		if !ok {
+			summary = &PeerSummary{Peer: entry.Peer}
+			summaries[entry.Peer] = summary
+		}
+
+		summary.Attempts++
+		summary.Duration += time.Duration(entry.DurationMs * float64(time.Millisecond))
+		switch entry.Result {
+		case Sent:
+			summary.Sent++
+			summary.SentBytes += entry.Bytes
+		case Failed:
+			summary.Failed++
+		case Expired:
+			summary.Expired++
+		}
+	}
+
+	result := make([]PeerSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Peer < result[j].Peer
+	})
+	return result
+}
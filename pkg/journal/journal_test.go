@@ -0,0 +1,76 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+func TestJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal.jsonl")
+	start := time.Date(2024, 4, 12, 9, 21, 33, 0, time.UTC)
+	alice, bob := bpv7.MustNewEndpointID("dtn://alice/"), bpv7.MustNewEndpointID("dtn://bob/")
+
+	transmissions := []cla.Transmission{
+		{Peer: alice, Address: "10.0.0.2:35037", Bytes: 1000, Start: start, Duration: time.Second},
+		{Peer: alice, Address: "10.0.0.2:35037", Bytes: 500, Start: start.Add(time.Minute), Duration: time.Second, Err: errors.New("broken pipe")},
+		{Peer: bob, Address: "10.0.0.3:35037", Bytes: 200, Start: start.Add(time.Hour), Err: cla.ErrBundleExpired},
+		{Peer: bob, Address: "10.0.0.3:35037", Bytes: 300, Start: start.Add(2 * time.Hour), Duration: 500 * time.Millisecond},
+	}
+
+	// The journal is appended to across restarts
+	for _, part := range [][]cla.Transmission{transmissions[:2], transmissions[2:]} {
+		journal, err := Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, transmission := range part {
+			journal.Record(transmission)
+		}
+		if err := journal.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A truncated line, e.g., after a crash, is ignored
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"time":"2024-04-12T`)
+	_ = f.Close()
+
+	f, err = os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := ReadEntries(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(transmissions) {
+		t.Fatalf("Journal contains %d instead of %d entries", len(entries), len(transmissions))
+	}
+	if entries[1].Result != Failed || entries[1].Error != "broken pipe" || entries[2].Result != Expired {
+		t.Fatalf("Results were not journaled: %v", entries)
+	}
+
+	summaries := Summarise(entries, time.Time{}, time.Time{})
+	expected := []PeerSummary{
+		{Peer: "dtn://alice/", Attempts: 2, Sent: 1, Failed: 1, SentBytes: 1000, Duration: 2 * time.Second},
+		{Peer: "dtn://bob/", Attempts: 2, Sent: 1, Expired: 1, SentBytes: 300, Duration: 500 * time.Millisecond},
+	}
+	if len(summaries) != len(expected) || summaries[0] != expected[0] || summaries[1] != expected[1] {
+		t.Fatalf("Summaries %v instead of %v", summaries, expected)
+	}
+
+	if summaries := Summarise(entries, start.Add(time.Hour), start.Add(2*time.Hour)); len(summaries) != 1 || summaries[0].Attempts != 1 {
+		t.Fatalf("Bounded summaries %v", summaries)
+	}
+}
@@ -0,0 +1,101 @@
+//go:build linux
+// +build linux
+
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// journaldSocket is systemd journal's socket for its native protocol.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldWriter sends each entry as a datagram in journald's native protocol, keeping its fields as journal fields,
+// e.g., "BUNDLE" for the "bundle" field.
+type journaldWriter struct {
+	conn *net.UnixConn
+	tag  string
+}
+
+func newJournaldWriter(config SinkConfig) (writer, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journaldWriter{conn: conn, tag: config.tag()}, nil
+}
+
+// journaldPriority maps a level to a syslog priority.
+func journaldPriority(level log.Level) int {
+	switch level {
+	case log.PanicLevel, log.FatalLevel:
+		return 2
+	case log.ErrorLevel:
+		return 3
+	case log.WarnLevel:
+		return 4
+	case log.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// journaldFieldName converts a field's name into a valid journal field name of upper case letters, digits and
+// underscores, which must not start with an underscore or a digit.
+func journaldFieldName(name string) string {
+	converted := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	converted = strings.TrimLeft(converted, "_")
+	if converted == "" || (converted[0] >= '0' && converted[0] <= '9') {
+		converted = "FIELD_" + converted
+	}
+	return converted
+}
+
+// writeJournaldField appends a field, using the binary format for values spanning multiple lines.
+func writeJournaldField(buff *bytes.Buffer, name, value string) {
+	buff.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buff.WriteByte('=')
+		buff.WriteString(value)
+		buff.WriteByte('\n')
+		return
+	}
+
+	buff.WriteByte('\n')
+	_ = binary.Write(buff, binary.LittleEndian, uint64(len(value)))
+	buff.WriteString(value)
+	buff.WriteByte('\n')
+}
+
+func (jw *journaldWriter) write(entry *log.Entry) error {
+	var buff bytes.Buffer
+	writeJournaldField(&buff, "MESSAGE", entry.Message)
+	writeJournaldField(&buff, "PRIORITY", fmt.Sprint(journaldPriority(entry.Level)))
+	writeJournaldField(&buff, "SYSLOG_IDENTIFIER", jw.tag)
+	for name, value := range entry.Data {
+		writeJournaldField(&buff, journaldFieldName(name), fmt.Sprint(value))
+	}
+
+	_, err := jw.conn.Write(buff.Bytes())
+	return err
+}
+
+func (jw *journaldWriter) Close() error {
+	return jw.conn.Close()
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package logging
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// newJournaldWriter fails, as journald only exists on Linux.
+func newJournaldWriter(SinkConfig) (writer, error) {
+	return nil, fmt.Errorf("journald is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,296 @@
+// Package logging routes the log entries of logrus' standard logger to several sinks: stderr, files rotated by their
+// size or age, syslog and journald. Each sink has its own level, which can be overridden for single components, e.g.,
+// to debug the convergence layers while only logging warnings of the store.
+//
+// A component is the package logging an entry, relative to the module and without its "pkg/" directory, e.g., "cla"
+// for package cla, "cla/mtcp" for its MTCP subpackage or "cmd/dtnd" for the daemon. A component's level also applies to
+// its subpackages, unless they are configured themselves.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SinkType is the kind of a sink.
+type SinkType string
+
+const (
+	// Stderr writes text lines to the standard error.
+	Stderr SinkType = "stderr"
+
+	// File writes text lines to a file, which is optionally rotated.
+	File SinkType = "file"
+
+	// Syslog sends entries to a local or remote syslog daemon.
+	Syslog SinkType = "syslog"
+
+	// Journald sends entries with their fields to the local systemd journal.
+	Journald SinkType = "journald"
+)
+
+// RotationConfig describes when a file sink's file is rotated, i.e., renamed by appending its rotation time and
+// replaced by an empty file.
+type RotationConfig struct {
+	// MaxSize of a file in bytes, zero for unbounded.
+	MaxSize int64
+	// Interval after which a file is rotated, zero for never.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files kept, older ones are deleted. Zero keeps all.
+	MaxBackups int
+}
+
+// SinkConfig describes a sink.
+type SinkConfig struct {
+	Type SinkType
+	// Level of all entries written to this sink.
+	Level log.Level
+	// Components override the Level for entries of these components.
+	Components map[string]log.Level
+
+	// Path and Rotation of a File sink.
+	Path     string
+	Rotation RotationConfig
+
+	// Network and Address of a remote Syslog daemon, e.g., "udp" and "logs.example.org:514", the local one if empty.
+	Network string
+	Address string
+	// Tag identifies this program's entries in syslog and journald, "dtnd" if empty.
+	Tag string
+}
+
+// CheckValid checks for a known type and its required settings.
+func (config SinkConfig) CheckValid() error {
+	switch config.Type {
+	case Stderr, Syslog, Journald:
+	case File:
+		if config.Path == "" {
+			return fmt.Errorf("file log sink requires a path")
+		}
+	default:
+		return fmt.Errorf("unknown log sink type %q", config.Type)
+	}
+	if config.Rotation.MaxSize < 0 || config.Rotation.Interval < 0 || config.Rotation.MaxBackups < 0 {
+		return fmt.Errorf("log rotation must not be negative")
+	}
+	return nil
+}
+
+// tag of the program in syslog and journald.
+func (config SinkConfig) tag() string {
+	if config.Tag == "" {
+		return "dtnd"
+	}
+	return config.Tag
+}
+
+// maxLevel is the most verbose level of this sink, including its components.
+func (config SinkConfig) maxLevel() log.Level {
+	level := config.Level
+	for _, componentLevel := range config.Components {
+		if componentLevel > level {
+			level = componentLevel
+		}
+	}
+	return level
+}
+
+// levelFor returns the level of a component, configured for the component itself or its closest parent.
+func (config SinkConfig) levelFor(component string) log.Level {
+	level, matched := config.Level, -1
+	for name, componentLevel := range config.Components {
+		if (component == name || strings.HasPrefix(component, name+"/")) && len(name) > matched {
+			level, matched = componentLevel, len(name)
+		}
+	}
+	return level
+}
+
+// modulePath prefixes all packages of this module.
+const modulePath = "github.com/dtn7/dtn7-go/"
+
+// component of an entry, derived from its caller. It is empty if the caller is unknown or outside this module.
+func component(entry *log.Entry) string {
+	if entry.Caller == nil {
+		return ""
+	}
+
+	// A function's name is its package path followed by the function, e.g., "github.com/dtn7/dtn7-go/pkg/cla.Send".
+	function := entry.Caller.Function
+	pkgEnd := strings.LastIndex(function, "/") + 1
+	if dot := strings.Index(function[pkgEnd:], "."); dot >= 0 {
+		pkgEnd += dot
+	} else {
+		pkgEnd = len(function)
+	}
+
+	pkg, ok := strings.CutPrefix(function[:pkgEnd], modulePath)
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(pkg, "pkg/")
+}
+
+// writer writes a formatted entry to a sink.
+type writer interface {
+	write(entry *log.Entry) error
+	io.Closer
+}
+
+// sink is a logrus hook passing the entries of its levels to its writer.
+type sink struct {
+	config SinkConfig
+
+	mutex  sync.Mutex
+	writer writer
+}
+
+// Levels of all entries passed to this hook. Entries of components with a lower level are filtered by Fire.
+func (s *sink) Levels() []log.Level {
+	return log.AllLevels[:s.config.maxLevel()+1]
+}
+
+// Fire writes an entry if its component's level allows it.
+func (s *sink) Fire(entry *log.Entry) error {
+	if entry.Level > s.config.levelFor(component(entry)) {
+		return nil
+	}
+
+	// The caller is only reported to determine the component, but not written.
+	written := *entry
+	written.Caller = nil
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.writer.write(&written)
+}
+
+// textWriter writes text lines to a Writer.
+type textWriter struct {
+	w         io.WriteCloser
+	formatter log.Formatter
+}
+
+func newTextWriter(w io.WriteCloser) *textWriter {
+	return &textWriter{
+		w: w,
+		formatter: &log.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02T15:04:05.000",
+			DisableColors:   true,
+		},
+	}
+}
+
+func (tw *textWriter) write(entry *log.Entry) error {
+	line, err := tw.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = tw.w.Write(line)
+	return err
+}
+
+func (tw *textWriter) Close() error {
+	return tw.w.Close()
+}
+
+// nopCloser keeps the standard error open.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// newSink opens a sink's writer.
+func newSink(config SinkConfig) (*sink, error) {
+	if err := config.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	var (
+		w   writer
+		err error
+	)
+	switch config.Type {
+	case Stderr:
+		w = newTextWriter(nopCloser{os.Stderr})
+	case File:
+		var file *rotatingFile
+		if file, err = openRotatingFile(config.Path, config.Rotation); err == nil {
+			w = newTextWriter(file)
+		}
+	case Syslog:
+		w, err = newSyslogWriter(config)
+	case Journald:
+		w, err = newJournaldWriter(config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening %s log sink: %w", config.Type, err)
+	}
+
+	return &sink{config: config, writer: w}, nil
+}
+
+// Sinks are the sinks of the standard logger, set up by Setup.
+type Sinks struct {
+	sinks []*sink
+}
+
+// Setup replaces the output of logrus' standard logger by the sinks. Its level is set to the most verbose level of
+// any sink. If components are configured, each entry's caller is determined, which comes at a cost.
+//
+// The returned Sinks must be closed after the last entry was logged.
+func Setup(configs []SinkConfig) (*Sinks, error) {
+	sinks := &Sinks{}
+	hooks := make(log.LevelHooks)
+	level, components := log.PanicLevel, false
+
+	for _, config := range configs {
+		s, err := newSink(config)
+		if err != nil {
+			_ = sinks.Close()
+			return nil, err
+		}
+		sinks.sinks = append(sinks.sinks, s)
+		hooks.Add(s)
+
+		if config.maxLevel() > level {
+			level = config.maxLevel()
+		}
+		components = components || len(config.Components) > 0
+	}
+
+	log.SetOutput(io.Discard)
+	log.SetLevel(level)
+	log.SetReportCaller(components)
+	log.StandardLogger().ReplaceHooks(hooks)
+
+	return sinks, nil
+}
+
+// Close all sinks, e.g., their files. The standard logger writes to stderr again.
+func (sinks *Sinks) Close() error {
+	log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+	log.SetOutput(os.Stderr)
+	log.SetReportCaller(false)
+
+	var firstErr error
+	for _, s := range sinks.sinks {
+		s.mutex.Lock()
+		if err := s.writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		s.mutex.Unlock()
+	}
+	return firstErr
+}
@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+func TestComponent(t *testing.T) {
+	tests := map[string]string{
+		"github.com/dtn7/dtn7-go/pkg/cla.(*Manager).Send":               "cla",
+		"github.com/dtn7/dtn7-go/pkg/cla/mtcp.(*MTCPClient).handleConn": "cla/mtcp",
+		"github.com/dtn7/dtn7-go/cmd/dtnd.main":                         "cmd/dtnd",
+		"github.com/quic-go/quic-go.(*Conn).Close":                      "",
+	}
+
+	for function, expected := range tests {
+		entry := &log.Entry{Caller: &runtime.Frame{Function: function}}
+		if c := component(entry); c != expected {
+			t.Fatalf("Component of %s is %q instead of %q", function, c, expected)
+		}
+	}
+	if c := component(&log.Entry{}); c != "" {
+		t.Fatalf("Component without caller is %q", c)
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	config := SinkConfig{
+		Level: log.InfoLevel,
+		Components: map[string]log.Level{
+			"cla":      log.DebugLevel,
+			"cla/mtcp": log.WarnLevel,
+		},
+	}
+
+	tests := map[string]log.Level{
+		"":          log.InfoLevel,
+		"store":     log.InfoLevel,
+		"cla":       log.DebugLevel,
+		"cla/quicl": log.DebugLevel,
+		"cla/mtcp":  log.WarnLevel,
+		"classify":  log.InfoLevel,
+	}
+	for c, expected := range tests {
+		if level := config.levelFor(c); level != expected {
+			t.Fatalf("Level of %q is %v instead of %v", c, level, expected)
+		}
+	}
+	if level := config.maxLevel(); level != log.DebugLevel {
+		t.Fatalf("Maximum level is %v", level)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	fc := clock.NewFakeClock(time.Date(2024, 4, 12, 9, 21, 33, 0, time.UTC))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	path := filepath.Join(t.TempDir(), "dtnd.log")
+	rf, err := openRotatingFile(path, RotationConfig{MaxSize: 100, Interval: time.Hour, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	line := []byte(strings.Repeat("x", 39) + "\n")
+	for i := 0; i < 3; i++ {
+		if _, err := rf.Write(line); err != nil {
+			t.Fatal(err)
+		}
+		fc.Advance(time.Second)
+	}
+	// The third line exceeded the size, starting a new file.
+	if backups, _ := rf.backups(); len(backups) != 1 || rf.size != 40 {
+		t.Fatalf("Expected one backup and a new file, got %v and %d bytes", backups, rf.size)
+	}
+
+	// Each rotation by age adds a backup, only the latest two are kept.
+	for i := 0; i < 3; i++ {
+		fc.Advance(time.Hour)
+		if _, err := rf.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, _ := rf.backups()
+	if len(backups) != 2 || !strings.HasSuffix(backups[1], fc.Now().UTC().Format(rotationSuffix)) {
+		t.Fatalf("Expected the latest two backups, got %v", backups)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dtnd.log")
+	sinks, err := Setup([]SinkConfig{{
+		Type:       File,
+		Level:      log.WarnLevel,
+		Components: map[string]log.Level{"logging": log.DebugLevel, "store": log.ErrorLevel},
+		Path:       path,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.WithField("bundle", "dtn://foo/-1-0").Debug("Logged by the logging component")
+	log.Trace("Beyond all levels")
+	if err := sinks.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Logged by the logging component") || strings.Contains(string(data), "Beyond") {
+		t.Fatalf("Unexpected log file %q", data)
+	}
+	if strings.Contains(string(data), "func=") {
+		t.Fatalf("Caller was written: %q", data)
+	}
+
+	if _, err := Setup([]SinkConfig{{Type: File}}); err == nil {
+		t.Fatal("File sink without path was set up")
+	}
+}
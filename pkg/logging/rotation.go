@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// rotationSuffix is the time format appended to rotated files, sorting them by their rotation.
+const rotationSuffix = "20060102T150405.000000"
+
+// rotatingFile is a file which is rotated by its size or age, see RotationConfig. It is not thread-safe.
+type rotatingFile struct {
+	path   string
+	config RotationConfig
+
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// openRotatingFile opens or creates the file, appending to existing contents.
+func openRotatingFile(path string, config RotationConfig) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, config: config}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	rf.file, rf.size, rf.opened = file, info.Size(), clock.Now()
+	return nil
+}
+
+// due checks if writing n further bytes requires a rotation first. An empty file is never rotated.
+func (rf *rotatingFile) due(n int) bool {
+	if rf.size == 0 {
+		return false
+	}
+	return (rf.config.MaxSize > 0 && rf.size+int64(n) > rf.config.MaxSize) ||
+		(rf.config.Interval > 0 && clock.Now().Sub(rf.opened) >= rf.config.Interval)
+}
+
+// rotate renames the current file, opens a new one and deletes the oldest backups.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(rf.path, rf.path+"."+clock.Now().UTC().Format(rotationSuffix)); err != nil {
+		return err
+	}
+	if err := rf.open(); err != nil {
+		return err
+	}
+	return rf.prune()
+}
+
+// backups returns the rotated files, oldest first.
+func (rf *rotatingFile) backups() ([]string, error) {
+	files, err := os.ReadDir(filepath.Dir(rf.path))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(rf.path) + "."
+	var backups []string
+	for _, file := range files {
+		suffix, ok := strings.CutPrefix(file.Name(), prefix)
+		if !ok || file.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(rotationSuffix, suffix); err == nil {
+			backups = append(backups, filepath.Join(filepath.Dir(rf.path), file.Name()))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// prune deletes the oldest backups exceeding MaxBackups.
+func (rf *rotatingFile) prune() error {
+	if rf.config.MaxBackups == 0 {
+		return nil
+	}
+
+	backups, err := rf.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > rf.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Write appends to the file, rotating it before if due.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	if rf.due(len(p)) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) Close() error {
+	return rf.file.Close()
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package logging
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// newSyslogWriter fails, as syslog is not available on this platform.
+func newSyslogWriter(SinkConfig) (writer, error) {
+	return nil, fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}
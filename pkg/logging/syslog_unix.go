@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"log/syslog"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// syslogWriter sends text lines without a timestamp, which is added by syslog, at the entry's severity.
+type syslogWriter struct {
+	w         *syslog.Writer
+	formatter log.Formatter
+}
+
+func newSyslogWriter(config SinkConfig) (writer, error) {
+	w, err := syslog.Dial(config.Network, config.Address, syslog.LOG_DAEMON|syslog.LOG_INFO, config.tag())
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{
+		w:         w,
+		formatter: &log.TextFormatter{DisableTimestamp: true, DisableColors: true},
+	}, nil
+}
+
+func (sw *syslogWriter) write(entry *log.Entry) error {
+	line, err := sw.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	msg := string(line)
+	switch entry.Level {
+	case log.PanicLevel, log.FatalLevel:
+		return sw.w.Crit(msg)
+	case log.ErrorLevel:
+		return sw.w.Err(msg)
+	case log.WarnLevel:
+		return sw.w.Warning(msg)
+	case log.InfoLevel:
+		return sw.w.Info(msg)
+	default:
+		return sw.w.Debug(msg)
+	}
+}
+
+func (sw *syslogWriter) Close() error {
+	return sw.w.Close()
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportTimeout bounds each export, such that an unreachable monitoring system does not stall the pushes.
+const exportTimeout = 10 * time.Second
+
+// GraphiteExporter sends metrics in Graphite's plaintext protocol, one line per metric, to Carbon via TCP. Tags are
+// appended to the metric's path, as supported since Graphite 1.1.
+type GraphiteExporter struct {
+	address string
+}
+
+// NewGraphiteExporter creates a GraphiteExporter for Carbon's plaintext receiver at the address, e.g., "host:2003".
+func NewGraphiteExporter(address string) *GraphiteExporter {
+	return &GraphiteExporter{address: address}
+}
+
+// graphiteEscaper replaces the characters which are invalid in Graphite's paths and tags.
+var graphiteEscaper = strings.NewReplacer(" ", "_", ";", "_", "~", "_", "=", "_")
+
+// graphiteLine formats a metric, e.g., "dtn7.store.used_bytes;node=dtn://foo/ 1024 1712913693\n".
+func graphiteLine(metric Metric, timestamp time.Time) string {
+	var line strings.Builder
+	line.WriteString(graphiteEscaper.Replace(metric.Name))
+
+	keys := make([]string, 0, len(metric.Tags))
+	for key := range metric.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := metric.Tags[key]; value != "" {
+			fmt.Fprintf(&line, ";%s=%s", graphiteEscaper.Replace(key), graphiteEscaper.Replace(value))
+		}
+	}
+
+	fmt.Fprintf(&line, " %s %d\n", strconv.FormatFloat(metric.Value, 'f', -1, 64), timestamp.Unix())
+	return line.String()
+}
+
+// Export sends the metrics over a new connection, which is closed afterwards.
+func (exporter *GraphiteExporter) Export(metrics []Metric, timestamp time.Time) error {
+	var buff bytes.Buffer
+	for _, metric := range metrics {
+		buff.WriteString(graphiteLine(metric, timestamp))
+	}
+
+	conn, err := net.DialTimeout("tcp", exporter.address, exportTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(exportTimeout)); err != nil {
+		return err
+	}
+	_, err = buff.WriteTo(conn)
+	return err
+}
+
+func (exporter *GraphiteExporter) String() string {
+	return fmt.Sprintf("graphite://%s", exporter.address)
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxDBExporter sends metrics in InfluxDB's line protocol to its HTTP write endpoint. Each metric becomes a
+// measurement of its name with its tags and a single "value" field.
+type InfluxDBExporter struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewInfluxDBExporter creates an InfluxDBExporter for the write URL, e.g., "http://host:8086/write?db=dtn" for
+// InfluxDB 1.x or "http://host:8086/api/v2/write?org=dtn&bucket=dtn" for InfluxDB 2.x. An optional token is sent as
+// authorization.
+func NewInfluxDBExporter(url, token string) *InfluxDBExporter {
+	return &InfluxDBExporter{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: exportTimeout},
+	}
+}
+
+// influxEscaper escapes the characters with a special meaning in the line protocol's measurements and tags.
+var influxEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+// influxLine formats a metric, e.g., "dtn7.store.used_bytes,node=dtn://foo/ value=1024 1712913693000000000\n".
+func influxLine(metric Metric, timestamp time.Time) string {
+	var line strings.Builder
+	line.WriteString(influxEscaper.Replace(metric.Name))
+
+	keys := make([]string, 0, len(metric.Tags))
+	for key := range metric.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := metric.Tags[key]; value != "" {
+			fmt.Fprintf(&line, ",%s=%s", influxEscaper.Replace(key), influxEscaper.Replace(value))
+		}
+	}
+
+	fmt.Fprintf(&line, " value=%s %d\n", strconv.FormatFloat(metric.Value, 'f', -1, 64), timestamp.UnixNano())
+	return line.String()
+}
+
+// Export posts the metrics to the write URL.
+func (exporter *InfluxDBExporter) Export(metrics []Metric, timestamp time.Time) error {
+	var buff bytes.Buffer
+	for _, metric := range metrics {
+		buff.WriteString(influxLine(metric, timestamp))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, exporter.url, &buff)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if exporter.token != "" {
+		req.Header.Set("Authorization", "Token "+exporter.token)
+	}
+
+	resp, err := exporter.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (exporter *InfluxDBExporter) String() string {
+	return exporter.url
+}
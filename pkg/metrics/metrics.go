@@ -0,0 +1,144 @@
+// Package metrics periodically pushes the node's metrics, e.g., its store's occupancy and the delivery statistics of
+// local applications, to a monitoring system. Unlike scraped metrics, pushed ones also reach a monitoring system
+// which cannot connect to the node, e.g., a node at the edge of a DTN only reaching the outside from time to time.
+//
+// Metrics are exported either in Graphite's plaintext protocol or in InfluxDB's line protocol, see ExporterType.
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// Metric is a single measured value, identified by its dot-separated name and its tags.
+type Metric struct {
+	Name  string
+	Tags  map[string]string
+	Value float64
+}
+
+// ExporterType selects the protocol of an Exporter.
+type ExporterType string
+
+const (
+	// Graphite exports metrics in Graphite's plaintext protocol via TCP, e.g., to Carbon.
+	Graphite ExporterType = "graphite"
+
+	// InfluxDB exports metrics in InfluxDB's line protocol via HTTP.
+	InfluxDB ExporterType = "influxdb"
+)
+
+// Exporter sends the collected metrics to a monitoring system.
+type Exporter interface {
+	// Export sends metrics, all measured at the same time.
+	Export(metrics []Metric, timestamp time.Time) error
+}
+
+// Config describes where and how often metrics are pushed.
+type Config struct {
+	Exporter ExporterType
+	// Address is the host and port of Carbon for Graphite, or InfluxDB's write URL, e.g.,
+	// "http://localhost:8086/api/v2/write?org=dtn&bucket=dtn".
+	Address string
+	// Token authenticates at InfluxDB, sent if not empty.
+	Token string
+	// Prefix of all metrics' names.
+	Prefix string
+	// Interval between two pushes.
+	Interval time.Duration
+}
+
+// CheckValid checks for a known exporter, an address and a positive interval.
+func (config Config) CheckValid() error {
+	switch config.Exporter {
+	case Graphite, InfluxDB:
+	default:
+		return fmt.Errorf("unknown metrics exporter %q", config.Exporter)
+	}
+	if config.Address == "" {
+		return fmt.Errorf("metrics exporter requires an address")
+	}
+	if config.Interval <= 0 {
+		return fmt.Errorf("metrics interval must be positive")
+	}
+	return nil
+}
+
+// newExporter creates the configured Exporter.
+func newExporter(config Config) Exporter {
+	if config.Exporter == InfluxDB {
+		return NewInfluxDBExporter(config.Address, config.Token)
+	}
+	return NewGraphiteExporter(config.Address)
+}
+
+// Collect the metrics of the node's components. The store, the CLA manager and the application agent manager must be
+// initialised.
+func Collect() (metrics []Metric) {
+	occupancy := store.GetStoreSingleton().Occupancy()
+	metrics = append(metrics,
+		Metric{Name: "store.used_bytes", Value: float64(occupancy.Used)},
+		Metric{Name: "store.capacity_bytes", Value: float64(occupancy.Capacity)},
+		Metric{Name: "store.pressure", Value: occupancy.Pressure()})
+
+	claManager := cla.GetManagerSingleton()
+	metrics = append(metrics,
+		Metric{Name: "cla.senders", Value: float64(len(claManager.GetSenders()))},
+		Metric{Name: "cla.receivers", Value: float64(len(claManager.GetReceivers()))})
+
+	sent, received := mtcp.GetCompressionStats()
+	for direction, stats := range map[string]mtcp.CompressionStats{"sent": sent, "received": received} {
+		tags := map[string]string{"direction": direction}
+		metrics = append(metrics,
+			Metric{Name: "mtcp.compression.sessions", Tags: tags, Value: float64(stats.Sessions)},
+			Metric{Name: "mtcp.compression.uncompressed_bytes", Tags: tags, Value: float64(stats.UncompressedBytes)},
+			Metric{Name: "mtcp.compression.compressed_bytes", Tags: tags, Value: float64(stats.CompressedBytes)})
+	}
+
+	metrics = append(metrics, Metric{Name: "processing.panics", Value: float64(processing.Panics())})
+
+	agentManager := application_agent.GetManagerSingleton()
+	metrics = append(metrics, Metric{Name: "agents.spool_backlog", Value: float64(agentManager.SpoolBacklog())})
+
+	deliveryStats := agentManager.DeliveryStats()
+	for eid, stats := range deliveryStats.Destinations {
+		tags := map[string]string{"destination": eid.String()}
+		metrics = append(metrics,
+			Metric{Name: "delivery.sent", Tags: tags, Value: float64(stats.Sent)},
+			Metric{Name: "delivery.delivered", Tags: tags, Value: float64(stats.Delivered)},
+			Metric{Name: "delivery.ratio", Tags: tags, Value: stats.DeliveryRatio()},
+			Metric{Name: "delivery.latency_seconds", Tags: tags, Value: stats.Latency.Mean().Seconds()})
+	}
+	for eid, stats := range deliveryStats.Endpoints {
+		tags := map[string]string{"endpoint": eid.String()}
+		metrics = append(metrics,
+			Metric{Name: "delivery.local", Tags: tags, Value: float64(stats.Delivered)},
+			Metric{Name: "delivery.local_latency_seconds", Tags: tags, Value: stats.Latency.Mean().Seconds()})
+	}
+
+	return
+}
+
+// prepare prefixes the metrics' names and tags them with the node ID.
+func prepare(metrics []Metric, prefix string, nodeID bpv7.EndpointID) []Metric {
+	prepared := make([]Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		tags := map[string]string{"node": nodeID.String()}
+		for key, value := range metric.Tags {
+			tags[key] = value
+		}
+		if prefix != "" {
+			metric.Name = prefix + "." + metric.Name
+		}
+		metric.Tags = tags
+		prepared = append(prepared, metric)
+	}
+	return prepared
+}
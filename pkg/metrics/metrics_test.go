@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+var testMetrics = []Metric{
+	{Name: "store.used_bytes", Value: 1024},
+	{Name: "delivery.ratio", Tags: map[string]string{"destination": "dtn://other/a b"}, Value: 0.5},
+}
+
+func TestGraphiteLine(t *testing.T) {
+	metrics := prepare(testMetrics, "dtn7", bpv7.MustNewEndpointID("dtn://foo/"))
+	timestamp := time.Unix(1712913693, 0)
+
+	expected := []string{
+		"dtn7.store.used_bytes;node=dtn://foo/ 1024 1712913693\n",
+		"dtn7.delivery.ratio;destination=dtn://other/a_b;node=dtn://foo/ 0.5 1712913693\n",
+	}
+	for i, metric := range metrics {
+		if line := graphiteLine(metric, timestamp); line != expected[i] {
+			t.Fatalf("Expected %q, got %q", expected[i], line)
+		}
+	}
+}
+
+func TestInfluxLine(t *testing.T) {
+	metrics := prepare(testMetrics, "", bpv7.MustNewEndpointID("dtn://foo/"))
+	timestamp := time.Unix(1712913693, 0)
+
+	expected := []string{
+		"store.used_bytes,node=dtn://foo/ value=1024 1712913693000000000\n",
+		`delivery.ratio,destination=dtn://other/a\ b,node=dtn://foo/ value=0.5 1712913693000000000` + "\n",
+	}
+	for i, metric := range metrics {
+		if line := influxLine(metric, timestamp); line != expected[i] {
+			t.Fatalf("Expected %q, got %q", expected[i], line)
+		}
+	}
+}
+
+func TestGraphiteExporter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	lines := make(chan []string)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var received []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			received = append(received, scanner.Text())
+		}
+		lines <- received
+	}()
+
+	exporter := NewGraphiteExporter(listener.Addr().String())
+	if err := exporter.Export(testMetrics, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if received := <-lines; len(received) != len(testMetrics) {
+		t.Fatalf("Expected %d lines, got %v", len(testMetrics), received)
+	}
+}
+
+func TestInfluxDBExporter(t *testing.T) {
+	var body, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, auth = string(data), r.Header.Get("Authorization")
+		if strings.Contains(r.URL.RawQuery, "bucket=missing") {
+			http.Error(w, "bucket not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	exporter := NewInfluxDBExporter(server.URL+"/api/v2/write?bucket=dtn", "secret")
+	if err := exporter.Export(testMetrics, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(body, "\n") != len(testMetrics) || auth != "Token secret" {
+		t.Fatalf("Unexpected request %q with authorization %q", body, auth)
+	}
+
+	exporter = NewInfluxDBExporter(server.URL+"/api/v2/write?bucket=missing", "")
+	if err := exporter.Export(testMetrics, time.Now()); err == nil {
+		t.Fatal("Export to a missing bucket succeeded")
+	}
+}
+
+// chanExporter passes each export to a channel.
+type chanExporter chan []Metric
+
+func (exporter chanExporter) Export(metrics []Metric, _ time.Time) error {
+	exporter <- metrics
+	return nil
+}
+
+func TestPusher(t *testing.T) {
+	exporter := make(chanExporter)
+	config := Config{Exporter: Graphite, Address: "unused", Prefix: "dtn7", Interval: 10 * time.Millisecond}
+	pusher := newPusher(bpv7.MustNewEndpointID("dtn://foo/"), config, exporter, func() []Metric { return testMetrics })
+
+	for i := 0; i < 2; i++ {
+		select {
+		case metrics := <-exporter:
+			if len(metrics) != len(testMetrics) || metrics[0].Name != "dtn7.store.used_bytes" {
+				t.Fatalf("Unexpected metrics %v", metrics)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("No metrics were pushed")
+		}
+	}
+
+	go func() {
+		for range exporter {
+		}
+	}()
+	pusher.Close()
+	close(exporter)
+}
+
+func TestConfigCheckValid(t *testing.T) {
+	tests := []struct {
+		config Config
+		valid  bool
+	}{
+		{Config{Exporter: Graphite, Address: "localhost:2003", Interval: time.Minute}, true},
+		{Config{Exporter: InfluxDB, Address: "http://localhost:8086/write?db=dtn", Interval: time.Minute}, true},
+		{Config{Exporter: "prometheus", Address: "localhost:9090", Interval: time.Minute}, false},
+		{Config{Exporter: Graphite, Interval: time.Minute}, false},
+		{Config{Exporter: Graphite, Address: "localhost:2003"}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.config.CheckValid(); (err == nil) != test.valid {
+			t.Fatalf("Config %v: expected valid %t, got %v", test.config, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// Pusher periodically collects the node's metrics and exports them.
+type Pusher struct {
+	nodeID   bpv7.EndpointID
+	config   Config
+	exporter Exporter
+	collect  func() []Metric
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPusher starts pushing the metrics of the node every interval, as configured. It must be closed by Close.
+func NewPusher(nodeID bpv7.EndpointID, config Config) (*Pusher, error) {
+	if err := config.CheckValid(); err != nil {
+		return nil, err
+	}
+	return newPusher(nodeID, config, newExporter(config), Collect), nil
+}
+
+func newPusher(nodeID bpv7.EndpointID, config Config, exporter Exporter, collect func() []Metric) *Pusher {
+	pusher := &Pusher{
+		nodeID:   nodeID,
+		config:   config,
+		exporter: exporter,
+		collect:  collect,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go pusher.handler()
+
+	log.WithFields(log.Fields{
+		"exporter": exporter,
+		"interval": config.Interval,
+	}).Info("Pushing metrics")
+
+	return pusher
+}
+
+func (pusher *Pusher) handler() {
+	defer close(pusher.done)
+
+	ticker := clock.NewTicker(pusher.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pusher.stop:
+			return
+		case <-ticker.C():
+			pusher.push()
+		}
+	}
+}
+
+// push collects and exports the metrics once. A failed export is logged, its metrics are not retried.
+func (pusher *Pusher) push() {
+	metrics := prepare(pusher.collect(), pusher.config.Prefix, pusher.nodeID)
+	if err := pusher.exporter.Export(metrics, clock.Now()); err != nil {
+		log.WithFields(log.Fields{
+			"exporter": pusher.exporter,
+			"error":    err,
+		}).Warn("Failed to push metrics")
+		return
+	}
+	log.WithField("metrics", len(metrics)).Debug("Pushed metrics")
+}
+
+// Close stops pushing metrics.
+func (pusher *Pusher) Close() {
+	close(pusher.stop)
+	<-pusher.done
+}
@@ -0,0 +1,176 @@
+package naming
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// fatalf is implemented by both *testing.T and *rapid.T.
+type fatalf interface {
+	Fatalf(format string, args ...interface{})
+}
+
+func newKey(t fatalf) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Generating key failed: %v", err)
+	}
+	return key
+}
+
+// newRecord creates a signed record, valid for an hour after being issued.
+func newRecord(t fatalf, key ed25519.PrivateKey, name, endpoint string, issued time.Time) Record {
+	rec := Record{
+		Name:     name,
+		Endpoint: bpv7.MustNewEndpointID(endpoint),
+		Type:     cla.QUICL,
+		Address:  "10.0.0.3:35037",
+		Issued:   issued,
+		Expires:  issued.Add(time.Hour),
+	}
+	if err := rec.Sign(key); err != nil {
+		t.Fatalf("Signing failed: %v", err)
+	}
+	return rec
+}
+
+func TestRecordCbor(t *testing.T) {
+	key := newKey(t)
+
+	rapid.Check(t, func(t *rapid.T) {
+		numRecords := rapid.IntRange(0, 10).Draw(t, "number of records")
+		recordsIn := make([]Record, numRecords)
+		for i := range recordsIn {
+			// DtnTime has a millisecond precision
+			issued := bpv7.DtnTime(rapid.Uint64Max(1<<40).Draw(t, fmt.Sprintf("issued %v", i))).Time()
+			recordsIn[i] = newRecord(t,
+				key,
+				rapid.StringMatching(`^[a-z0-9][a-z0-9._-]{0,20}$`).Draw(t, fmt.Sprintf("name %v", i)),
+				rapid.StringMatching(bpv7.DtnEndpointRegexpNotNone).Draw(t, fmt.Sprintf("endpoint %v", i)),
+				issued)
+		}
+
+		data, err := MarshalRecords(recordsIn)
+		if err != nil {
+			t.Fatalf("Encoding failed: %v", err)
+		}
+
+		recordsOut, err := UnmarshalRecords(data)
+		if err != nil {
+			t.Fatalf("Decoding failed: %v", err)
+		}
+
+		if len(recordsIn) == 0 && len(recordsOut) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(recordsIn, recordsOut) {
+			t.Fatalf("Decoded Records differ: %v became %v", recordsIn, recordsOut)
+		}
+		for _, rec := range recordsOut {
+			if err := rec.CheckValid(); err != nil {
+				t.Fatalf("Decoded Record %v is invalid: %v", rec, err)
+			}
+		}
+	})
+}
+
+func TestRecordTampered(t *testing.T) {
+	rec := newRecord(t, newKey(t), "alice", "dtn://alice-laptop/chat", time.Now())
+	if err := rec.CheckValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := rec
+	tampered.Endpoint = bpv7.MustNewEndpointID("dtn://mallory/chat")
+	if err := tampered.CheckValid(); err == nil {
+		t.Fatal("Record with a changed endpoint is valid")
+	}
+
+	unsigned := rec
+	unsigned.PublicKey, unsigned.Signature = nil, nil
+	if err := unsigned.CheckValid(); err == nil {
+		t.Fatal("Unsigned record is valid")
+	}
+
+	if _, err := NormaliseName("Alice"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", "dtn://alice/", "-alice", "al ice"} {
+		if _, err := NormaliseName(name); err == nil {
+			t.Fatalf("Invalid name %q was accepted", name)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	now := time.Date(2024, 4, 12, 9, 21, 33, 0, time.UTC)
+	fc := clock.NewFakeClock(now)
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	ownKey, aliceKey, malloryKey := newKey(t), newKey(t), newKey(t)
+	resolver, err := newResolver(bpv7.MustNewEndpointID("dtn://self/"), Config{
+		Static:    []Record{{Name: "Gateway", Endpoint: bpv7.MustNewEndpointID("dtn://gateway/")}},
+		Published: []Record{{Name: "self", Endpoint: bpv7.MustNewEndpointID("dtn://self/inbox")}},
+		Key:       ownKey,
+		Lifetime:  time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec, ok := resolver.Resolve("gateway"); !ok || !rec.Static() || rec.Endpoint != bpv7.MustNewEndpointID("dtn://gateway/") {
+		t.Fatalf("Static name resolved to %v, %t", rec, ok)
+	}
+	if rec, ok := resolver.Resolve("self"); !ok || rec.Static() || rec.CheckValid() != nil {
+		t.Fatalf("Published name resolved to %v, %t", rec, ok)
+	}
+
+	// Static names are never overridden
+	if merged, err := resolver.AddRecord(newRecord(t, malloryKey, "gateway", "dtn://mallory/", now)); err != nil || merged {
+		t.Fatalf("Record of a static name was merged: %t, %v", merged, err)
+	}
+
+	// The first record pins its key, later ones must be newer and signed by the same key
+	alice := newRecord(t, aliceKey, "alice", "dtn://alice-laptop/chat", now)
+	if merged, err := resolver.AddRecord(alice); err != nil || !merged {
+		t.Fatalf("Record was not merged: %t, %v", merged, err)
+	}
+	if _, err := resolver.AddRecord(newRecord(t, malloryKey, "alice", "dtn://mallory/", now.Add(time.Minute))); err == nil {
+		t.Fatal("Record signed by another key was merged")
+	}
+	if merged, _ := resolver.AddRecord(newRecord(t, aliceKey, "alice", "dtn://alice-old/", now.Add(-time.Minute))); merged {
+		t.Fatal("Older record was merged")
+	}
+	if merged, err := resolver.AddRecord(newRecord(t, aliceKey, "alice", "dtn://alice-phone/chat", now.Add(time.Minute))); err != nil || !merged {
+		t.Fatalf("Newer record was not merged: %t, %v", merged, err)
+	}
+	if eid, ok := resolver.ResolveEndpoint("ALICE"); !ok || eid != bpv7.MustNewEndpointID("dtn://alice-phone/chat") {
+		t.Fatalf("Name resolved to %v, %t", eid, ok)
+	}
+
+	// Expired records are neither merged nor resolved, own records are signed again
+	if merged, _ := resolver.AddRecord(newRecord(t, malloryKey, "bob", "dtn://mallory/", now.Add(-2*time.Hour))); merged {
+		t.Fatal("Expired record was merged")
+	}
+	fc.Advance(2 * time.Hour)
+	if _, ok := resolver.Resolve("alice"); ok {
+		t.Fatal("Expired name was resolved")
+	}
+	if err := resolver.publish(); err != nil {
+		t.Fatal(err)
+	}
+	if names := resolver.Records(); len(names) != 2 || names[0].Name != "gateway" || names[1].Name != "self" {
+		t.Fatalf("Resolver knows %v", names)
+	}
+}
@@ -0,0 +1,224 @@
+package naming
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// nameRegexp describes valid names. As they contain no colon, names are never mistaken for endpoint IDs.
+var nameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// NormaliseName returns the canonical, lower case form of a name, or an error for an invalid name.
+func NormaliseName(name string) (string, error) {
+	name = strings.ToLower(name)
+	if !nameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid name %q", name)
+	}
+	return name, nil
+}
+
+// Record maps a name to an endpoint ID and optionally to the CLA address of its node. Records are either configured
+// statically or signed by their publishing node and distributed over DTN.
+type Record struct {
+	Name     string
+	Endpoint bpv7.EndpointID
+	// Type and Address of a CLA to reach the endpoint's node, the Address is empty if unknown.
+	Type    cla.CLAType
+	Address string
+	// Issued and Expires bound the record's validity. A newer record replaces an older one of the same name.
+	Issued  time.Time
+	Expires time.Time
+	// PublicKey and Signature of a published record, both empty for a static one.
+	PublicKey ed25519.PublicKey
+	Signature []byte
+}
+
+// Static checks if this record was configured statically, i.e., if it is unsigned.
+func (rec Record) Static() bool {
+	return len(rec.Signature) == 0
+}
+
+// Expired checks if the record's validity ended.
+func (rec Record) Expired(now time.Time) bool {
+	return !rec.Expires.IsZero() && now.After(rec.Expires)
+}
+
+// signedData is the CBOR representation of all fields but the signature.
+func (rec Record) signedData() ([]byte, error) {
+	buff := new(bytes.Buffer)
+	if err := rec.marshalFields(buff); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// Sign sets the record's public key and signs it.
+func (rec *Record) Sign(key ed25519.PrivateKey) error {
+	rec.PublicKey = key.Public().(ed25519.PublicKey)
+	data, err := rec.signedData()
+	if err != nil {
+		return err
+	}
+	rec.Signature = ed25519.Sign(key, data)
+	return nil
+}
+
+// CheckValid checks the name, the validity period and the signature of a published record.
+func (rec Record) CheckValid() error {
+	if name, err := NormaliseName(rec.Name); err != nil {
+		return err
+	} else if name != rec.Name {
+		return fmt.Errorf("name %q is not normalised", rec.Name)
+	}
+	if err := rec.Endpoint.CheckValid(); err != nil {
+		return err
+	}
+	if rec.Expires.Before(rec.Issued) {
+		return fmt.Errorf("record of %q expires before it was issued", rec.Name)
+	}
+
+	if len(rec.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("record of %q has a public key of %d bytes", rec.Name, len(rec.PublicKey))
+	}
+	data, err := rec.signedData()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(rec.PublicKey, data, rec.Signature) {
+		return fmt.Errorf("signature of the record of %q is invalid", rec.Name)
+	}
+	return nil
+}
+
+// marshalFields writes all fields but the signature, the timestamps as DtnTimes.
+func (rec *Record) marshalFields(w io.Writer) error {
+	if err := cboring.WriteTextString(rec.Name, w); err != nil {
+		return err
+	}
+	if err := cboring.Marshal(&rec.Endpoint, w); err != nil {
+		return fmt.Errorf("marshalling endpoint failed: %v", err)
+	}
+	if err := cboring.WriteUInt(uint64(rec.Type), w); err != nil {
+		return err
+	}
+	if err := cboring.WriteTextString(rec.Address, w); err != nil {
+		return err
+	}
+	if err := cboring.WriteUInt(uint64(bpv7.DtnTimeFromTime(rec.Issued)), w); err != nil {
+		return err
+	}
+	if err := cboring.WriteUInt(uint64(bpv7.DtnTimeFromTime(rec.Expires)), w); err != nil {
+		return err
+	}
+	return cboring.WriteByteString(rec.PublicKey, w)
+}
+
+// MarshalCbor creates a CBOR representation of a Record.
+func (rec *Record) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(8, w); err != nil {
+		return err
+	}
+	if err := rec.marshalFields(w); err != nil {
+		return err
+	}
+	return cboring.WriteByteString(rec.Signature, w)
+}
+
+// UnmarshalCbor creates a Record from its CBOR representation.
+func (rec *Record) UnmarshalCbor(r io.Reader) error {
+	if l, err := cboring.ReadArrayLength(r); err != nil {
+		return err
+	} else if l != 8 {
+		return fmt.Errorf("wrong array length: %d instead of 8", l)
+	}
+
+	if name, err := cboring.ReadTextString(r); err != nil {
+		return err
+	} else {
+		rec.Name = name
+	}
+	if err := cboring.Unmarshal(&rec.Endpoint, r); err != nil {
+		return fmt.Errorf("unmarshalling endpoint failed: %v", err)
+	}
+	if n, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		rec.Type = cla.CLAType(n)
+	}
+	if address, err := cboring.ReadTextString(r); err != nil {
+		return err
+	} else {
+		rec.Address = address
+	}
+	if n, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		rec.Issued = bpv7.DtnTime(n).Time()
+	}
+	if n, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		rec.Expires = bpv7.DtnTime(n).Time()
+	}
+	if key, err := cboring.ReadByteString(r); err != nil {
+		return err
+	} else {
+		rec.PublicKey = key
+	}
+	if signature, err := cboring.ReadByteString(r); err != nil {
+		return err
+	} else {
+		rec.Signature = signature
+	}
+
+	return nil
+}
+
+func (rec Record) String() string {
+	return fmt.Sprintf("Record(%s,%v,%s)", rec.Name, rec.Endpoint, rec.Address)
+}
+
+// MarshalRecords into a CBOR byte string.
+func MarshalRecords(records []Record) ([]byte, error) {
+	buff := new(bytes.Buffer)
+	if err := cboring.WriteArrayLength(uint64(len(records)), buff); err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		if err := cboring.Marshal(&records[i], buff); err != nil {
+			return nil, fmt.Errorf("marshalling Record %d (%v) failed: %v", i, records[i], err)
+		}
+	}
+	return buff.Bytes(), nil
+}
+
+// UnmarshalRecords creates a new array of Records based on a CBOR byte string.
+func UnmarshalRecords(data []byte) ([]Record, error) {
+	buff := bytes.NewBuffer(data)
+
+	l, err := cboring.ReadArrayLength(buff)
+	if err != nil {
+		return nil, err
+	} else if l > uint64(len(data)) {
+		return nil, fmt.Errorf("array length of %d exceeds the data", l)
+	}
+
+	records := make([]Record, l)
+	for i := range records {
+		if err := cboring.Unmarshal(&records[i], buff); err != nil {
+			return nil, fmt.Errorf("unmarshalling Record %d failed: %v", i, err)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,369 @@
+// Package naming resolves human-friendly names to endpoint IDs and CLA addresses, allowing applications to address
+// "alice" instead of "dtn://alice-laptop/chat".
+//
+// Names are either configured statically or published by the nodes themselves. A published Record is signed by its
+// node's identity key, see package identity, and exchanged with each connected peer, which passes it on. Thus, records
+// spread through the network like known peers by the discovery's peer exchange.
+//
+// As there is no authority for names, the first valid record of a name pins its public key: later records of this name
+// are only accepted if signed by the same key and issued more recently. Static names always take precedence over
+// published ones, allowing an operator to override a squatted name.
+package naming
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+const (
+	// namingDemux is the demux of a dtn node's naming endpoint, e.g., "dtn://node/names".
+	namingDemux = "names"
+
+	// namingService is the service number of an ipn node's naming endpoint, DNS's well-known port.
+	namingService = 53
+
+	// namingLifetime is the lifetime of a record bundle, which is only meant for a connected neighbour.
+	namingLifetime = time.Minute
+)
+
+// NamingEndpoint returns the endpoint for name record exchanges of a node.
+func NamingEndpoint(node bpv7.EndpointID) (bpv7.EndpointID, error) {
+	switch endpoint := node.EndpointType.(type) {
+	case bpv7.DtnEndpoint:
+		return bpv7.NewEndpointID(fmt.Sprintf("dtn://%s/%s", endpoint.NodeName, namingDemux))
+	case bpv7.IpnEndpoint:
+		return bpv7.NewEndpointID(fmt.Sprintf("ipn:%d.%d", endpoint.Node, namingService))
+	default:
+		return bpv7.EndpointID{}, fmt.Errorf("no naming endpoint for %v", node)
+	}
+}
+
+// Config of the Resolver.
+type Config struct {
+	// Static records, which are neither signed nor distributed.
+	Static []Record
+	// Published records of this node, signed by the Key and valid for the Lifetime from their publication on. Their
+	// Name, Endpoint, Type and Address must be set.
+	Published []Record
+	Key       ed25519.PrivateKey
+	Lifetime  time.Duration
+	// FloodHops floods the records up to this number of hops when a peer connects, instead of only sending them to
+	// this peer. Zero disables flooding.
+	FloodHops uint8
+}
+
+// CheckValid checks the static and published records, which require a key and a positive lifetime.
+func (conf Config) CheckValid() error {
+	for _, rec := range append(conf.Static, conf.Published...) {
+		if _, err := NormaliseName(rec.Name); err != nil {
+			return err
+		}
+		if err := rec.Endpoint.CheckValid(); err != nil {
+			return fmt.Errorf("endpoint of %q: %v", rec.Name, err)
+		}
+	}
+	if len(conf.Published) > 0 && len(conf.Key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("publishing names requires a key")
+	}
+	if len(conf.Published) > 0 && conf.Lifetime <= 0 {
+		return fmt.Errorf("lifetime of published names must be positive")
+	}
+	return nil
+}
+
+// Resolver keeps the known name records and resolves names. When a peer connects, all known valid records are sent
+// to it. Received records are verified and merged.
+//
+// Records are exchanged as bundles between the nodes' NamingEndpoints. Thus, the Resolver is also an
+// ApplicationAgent, which must be registered at the application_agent.Manager.
+type Resolver struct {
+	endpoint bpv7.EndpointID
+	// floodEndpoint receives records flooded by other nodes, see Config.FloodHops.
+	floodEndpoint bpv7.EndpointID
+	conf          Config
+
+	mutex   sync.Mutex
+	records map[string]Record // name -> Record
+}
+
+var resolverSingleton *Resolver
+
+// newResolver creates a Resolver, knowing the static records and its own published ones.
+func newResolver(nodeID bpv7.EndpointID, conf Config) (*Resolver, error) {
+	if err := conf.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := NamingEndpoint(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	floodEndpoint, err := routing.ScopedFloodEndpoint(namingDemux)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &Resolver{
+		endpoint:      endpoint,
+		floodEndpoint: floodEndpoint,
+		conf:          conf,
+		records:       make(map[string]Record),
+	}
+	for _, rec := range conf.Static {
+		rec.Name, _ = NormaliseName(rec.Name)
+		rec.PublicKey, rec.Signature = nil, nil
+		resolver.records[rec.Name] = rec
+	}
+	if err := resolver.publish(); err != nil {
+		return nil, err
+	}
+	return resolver, nil
+}
+
+// InitialiseResolver initialises the Resolver singleton and registers it as an ApplicationAgent.
+func InitialiseResolver(nodeID bpv7.EndpointID, conf Config) error {
+	if resolverSingleton != nil {
+		return util.NewAlreadyInitialisedError("Resolver")
+	}
+
+	resolver, err := newResolver(nodeID, conf)
+	if err != nil {
+		return err
+	}
+	if err := application_agent.GetManagerSingleton().RegisterAgent(resolver); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"endpoint":  resolver.endpoint,
+		"static":    len(conf.Static),
+		"published": len(conf.Published),
+	}).Info("Starting name resolver")
+
+	resolverSingleton = resolver
+	return nil
+}
+
+// ResolverInitialised checks if the Resolver singleton exists, i.e., if name resolution is enabled.
+func ResolverInitialised() bool {
+	return resolverSingleton != nil
+}
+
+// GetResolverSingleton returns the Resolver singleton-instance.
+// Attempting to call this function before initialisation will cause the program to panic.
+func GetResolverSingleton() *Resolver {
+	if resolverSingleton == nil {
+		log.Fatalf("Attempting to access an uninitialised name resolver. This must never happen!")
+	}
+	return resolverSingleton
+}
+
+// publish signs this node's records again if half of their lifetime has passed.
+func (resolver *Resolver) publish() error {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	now := clock.Now()
+	for _, rec := range resolver.conf.Published {
+		rec.Name, _ = NormaliseName(rec.Name)
+		known, ok := resolver.records[rec.Name]
+		if ok && (known.Static() || known.Expires.Sub(now) > resolver.conf.Lifetime/2) {
+			continue
+		}
+
+		rec.Issued = now
+		rec.Expires = now.Add(resolver.conf.Lifetime)
+		if err := rec.Sign(resolver.conf.Key); err != nil {
+			return err
+		}
+		resolver.records[rec.Name] = rec
+	}
+	return nil
+}
+
+// AddRecord merges a published record if it is valid and either names an unknown or expired entry, or replaces an
+// older record signed by the same key. It returns true if the record was merged.
+func (resolver *Resolver) AddRecord(rec Record) (bool, error) {
+	if err := rec.CheckValid(); err != nil {
+		return false, err
+	}
+
+	now := clock.Now()
+	if rec.Expired(now) {
+		return false, nil
+	}
+
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	known, ok := resolver.records[rec.Name]
+	switch {
+	case !ok:
+	case known.Static():
+		return false, nil
+	case !known.PublicKey.Equal(rec.PublicKey):
+		return false, fmt.Errorf("record of %q is signed by %x instead of its pinned key %x",
+			rec.Name, rec.PublicKey, known.PublicKey)
+	case !rec.Issued.After(known.Issued):
+		return false, nil
+	}
+
+	resolver.records[rec.Name] = rec
+	return true, nil
+}
+
+// Resolve returns the record of a name, unless it is unknown or expired.
+func (resolver *Resolver) Resolve(name string) (Record, bool) {
+	name, err := NormaliseName(name)
+	if err != nil {
+		return Record{}, false
+	}
+
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	rec, ok := resolver.records[name]
+	if !ok || rec.Expired(clock.Now()) {
+		return Record{}, false
+	}
+	return rec, true
+}
+
+// ResolveEndpoint returns the endpoint ID of a name, e.g., as an application_agent.NameResolver.
+func (resolver *Resolver) ResolveEndpoint(name string) (bpv7.EndpointID, bool) {
+	rec, ok := resolver.Resolve(name)
+	return rec.Endpoint, ok
+}
+
+// Records returns all known records which are not expired, ordered by their names. Expired records are forgotten, but
+// their names stay pinned to their keys.
+func (resolver *Resolver) Records() (records []Record) {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	now := clock.Now()
+	for _, rec := range resolver.records {
+		if !rec.Expired(now) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+	return
+}
+
+// PeerConnected sends all known published records to the peer, refreshing this node's own records before.
+func (resolver *Resolver) PeerConnected(peerID bpv7.EndpointID) {
+	if err := resolver.publish(); err != nil {
+		log.WithError(err).Warn("Failed to publish names")
+	}
+
+	if err := resolver.sendRecords(peerID); err != nil {
+		log.WithFields(log.Fields{
+			"peer":  peerID,
+			"error": err,
+		}).Warn("Failed to send name records")
+	}
+}
+
+// sendRecords sends all known published records as a bundle to the peer's NamingEndpoint, or floods them within the
+// configured hops.
+func (resolver *Resolver) sendRecords(peerID bpv7.EndpointID) error {
+	var records []Record
+	for _, rec := range resolver.Records() {
+		if !rec.Static() {
+			records = append(records, rec)
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	data, err := MarshalRecords(records)
+	if err != nil {
+		return err
+	}
+
+	var bndl bpv7.Bundle
+	if resolver.conf.FloodHops > 0 {
+		bndl, err = routing.NewScopedFlood(resolver.endpoint, namingDemux, resolver.conf.FloodHops, namingLifetime, data)
+	} else {
+		destination, destErr := NamingEndpoint(peerID)
+		if destErr != nil {
+			return destErr
+		}
+		bndl, err = bpv7.Builder().
+			Source(resolver.endpoint).
+			Destination(destination).
+			CreationTimestampNow().
+			Lifetime(namingLifetime).
+			PayloadBlock(data).
+			Build()
+	}
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"peer":    peerID,
+		"records": len(records),
+		"hops":    resolver.conf.FloodHops,
+	}).Debug("Sending name records")
+	application_agent.GetManagerSingleton().Send(&bndl)
+	return nil
+}
+
+// Endpoints returns the NamingEndpoint of this node and the endpoint of flooded records.
+func (resolver *Resolver) Endpoints() []bpv7.EndpointID {
+	return []bpv7.EndpointID{resolver.endpoint, resolver.floodEndpoint}
+}
+
+// Deliver merges received name records.
+func (resolver *Resolver) Deliver(bundleDescriptor *store.BundleDescriptor) error {
+	if bundleDescriptor.Destination != resolver.endpoint && bundleDescriptor.Destination != resolver.floodEndpoint {
+		return nil
+	}
+
+	bndl, err := bundleDescriptor.Load()
+	if err != nil {
+		return err
+	}
+	payload, err := bndl.PayloadBlock()
+	if err != nil {
+		return err
+	}
+	records, err := UnmarshalRecords(payload.Value.(*bpv7.PayloadBlock).Data())
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if merged, err := resolver.AddRecord(rec); err != nil {
+			log.WithFields(log.Fields{
+				"source": bndl.PrimaryBlock.SourceNode,
+				"record": rec,
+				"error":  err,
+			}).Warn("Rejected received name record")
+		} else if merged {
+			log.WithField("record", rec).Debug("Learned name record")
+		}
+	}
+	return nil
+}
+
+func (resolver *Resolver) Shutdown() {
+	resolverSingleton = nil
+}
@@ -0,0 +1,223 @@
+// Package node_stats keeps cumulative statistics of a node, e.g., its forwarded and delivered bundles and its uptime,
+// across its restarts. Thus, long-running field trials can report their totals without an external monitoring.
+//
+// The totals are persisted to a JSON file, periodically and once the Recorder is shut down. A node which is killed
+// loses at most the statistics of one interval.
+//
+// Statistics are only kept after InitialiseRecorder was called. Otherwise, all hooks are no-ops.
+package node_stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+// Totals are a node's statistics, summed up over all its runs.
+type Totals struct {
+	// Forwarded bundles were passed to a CLA successfully; ForwardedBytes is the size of their CBOR representation.
+	Forwarded      uint64 `json:"forwarded"`
+	ForwardedBytes uint64 `json:"forwarded_bytes"`
+	// Delivered bundles were addressed to one of the node's local endpoints.
+	Delivered uint64 `json:"delivered"`
+	// Restarts are all starts of the node after the first one.
+	Restarts uint64 `json:"restarts"`
+	// Uptime is the summed up running time of all runs.
+	Uptime time.Duration `json:"uptime"`
+	// FirstStart is the time of the node's first run.
+	FirstStart time.Time `json:"first_start"`
+}
+
+// Report are a node's Totals and the start of its current run.
+type Report struct {
+	Totals
+	Started time.Time
+}
+
+// Session is the running time of the node's current run.
+func (report Report) Session() time.Duration {
+	return clock.Now().Sub(report.Started)
+}
+
+var recorderSingleton *Recorder
+
+// Recorder counts a node's statistics and persists their Totals.
+type Recorder struct {
+	mutex    sync.Mutex
+	filename string
+	// totals of the previous runs and the counters of the current run; the uptime of the current run is derived from
+	// started.
+	totals  Totals
+	started time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// InitialiseRecorder initialises the Recorder singleton, continuing the Totals persisted in the given file, which are
+// written every interval.
+func InitialiseRecorder(filename string, interval time.Duration) error {
+	if recorderSingleton != nil {
+		return util.NewAlreadyInitialisedError("Node Statistics Recorder")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("node statistics interval %v is not positive", interval)
+	}
+
+	totals, known, err := loadTotals(filename)
+	if err != nil {
+		return err
+	}
+
+	now := clock.Now()
+	if known {
+		totals.Restarts++
+	} else {
+		totals.FirstStart = now
+	}
+
+	recorder := &Recorder{
+		filename: filename,
+		totals:   totals,
+		started:  now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := recorder.persist(); err != nil {
+		return err
+	}
+
+	recorderSingleton = recorder
+	go recorder.run(interval)
+
+	log.WithFields(log.Fields{
+		"file":     filename,
+		"restarts": totals.Restarts,
+		"uptime":   totals.Uptime,
+	}).Debug("Loaded node statistics")
+
+	return nil
+}
+
+// Initialised checks if the Recorder singleton was initialised.
+func Initialised() bool {
+	return recorderSingleton != nil
+}
+
+// GetRecorderSingleton returns the Recorder singleton.
+func GetRecorderSingleton() *Recorder {
+	if recorderSingleton == nil {
+		log.Fatalf("Attempting to access an uninitialised Node Statistics Recorder. This must never happen!")
+	}
+	return recorderSingleton
+}
+
+// loadTotals reads the Totals from a state file. A missing file is a node's first run, reported as unknown.
+func loadTotals(filename string) (totals Totals, known bool, err error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return Totals{}, false, nil
+	} else if err != nil {
+		return Totals{}, false, err
+	}
+
+	if err := json.Unmarshal(data, &totals); err != nil {
+		return Totals{}, false, fmt.Errorf("parsing node statistics %s: %w", filename, err)
+	}
+	return totals, true, nil
+}
+
+// run persists the Totals every interval until Shutdown.
+func (recorder *Recorder) run(interval time.Duration) {
+	defer close(recorder.done)
+
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-recorder.stop:
+			return
+
+		case <-ticker.C():
+			if err := recorder.persist(); err != nil {
+				log.WithError(err).Warn("Failed to persist node statistics")
+			}
+		}
+	}
+}
+
+// Report returns the current Totals, including the current run's uptime.
+func (recorder *Recorder) Report() Report {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	totals := recorder.totals
+	totals.Uptime += clock.Now().Sub(recorder.started)
+	return Report{Totals: totals, Started: recorder.started}
+}
+
+// persist writes the current Totals atomically to the state file.
+func (recorder *Recorder) persist() error {
+	data, err := json.Marshal(recorder.Report().Totals)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := recorder.filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		_ = os.Remove(tmpFile)
+		return err
+	}
+	return os.Rename(tmpFile, recorder.filename)
+}
+
+// Shutdown persists the Totals a last time and resets the singleton, e.g., to simulate a restart.
+func (recorder *Recorder) Shutdown() {
+	close(recorder.stop)
+	<-recorder.done
+
+	if err := recorder.persist(); err != nil {
+		log.WithError(err).Warn("Failed to persist node statistics")
+	}
+	recorderSingleton = nil
+}
+
+// forwarded counts a bundle passed to a CLA.
+func (recorder *Recorder) forwarded(bytes uint64) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.totals.Forwarded++
+	recorder.totals.ForwardedBytes += bytes
+}
+
+// delivered counts a bundle delivered to a local endpoint.
+func (recorder *Recorder) delivered() {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.totals.Delivered++
+}
+
+// Forwarded counts a bundle, whose CBOR representation has the size of bytes, which was passed to a CLA.
+func Forwarded(bytes uint64) {
+	if recorderSingleton != nil {
+		recorderSingleton.forwarded(bytes)
+	}
+}
+
+// Delivered counts a bundle delivered to a local endpoint.
+func Delivered() {
+	if recorderSingleton != nil {
+		recorderSingleton.delivered()
+	}
+}
@@ -0,0 +1,93 @@
+package node_stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+func TestRecorderRestart(t *testing.T) {
+	start := time.Date(2024, 4, 12, 9, 0, 0, 0, time.UTC)
+	fc := clock.NewFakeClock(start)
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	filename := filepath.Join(t.TempDir(), "node_stats.json")
+
+	// hooks are no-ops without a Recorder
+	Forwarded(1024)
+	Delivered()
+
+	if err := InitialiseRecorder(filename, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	Forwarded(1024)
+	Forwarded(512)
+	Delivered()
+	fc.Advance(time.Hour)
+	GetRecorderSingleton().Shutdown()
+
+	if Initialised() {
+		t.Fatal("Recorder is still initialised after its shutdown")
+	}
+
+	// the node is down for a day, which does not count as uptime
+	fc.Advance(24 * time.Hour)
+	restarted := fc.Now()
+
+	if err := InitialiseRecorder(filename, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	defer GetRecorderSingleton().Shutdown()
+
+	Forwarded(256)
+	fc.Advance(30 * time.Minute)
+
+	report := GetRecorderSingleton().Report()
+	expected := Totals{
+		Forwarded:      3,
+		ForwardedBytes: 1792,
+		Delivered:      1,
+		Restarts:       1,
+		Uptime:         90 * time.Minute,
+		FirstStart:     start,
+	}
+	if report.Totals != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, report.Totals)
+	}
+	if !report.Started.Equal(restarted) || report.Session() != 30*time.Minute {
+		t.Fatalf("Expected a session since %v of 30m, got %v since %v", restarted, report.Session(), report.Started)
+	}
+}
+
+func TestRecorderPersistsPeriodically(t *testing.T) {
+	fc := clock.NewFakeClock(time.Date(2024, 4, 12, 9, 0, 0, 0, time.UTC))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	filename := filepath.Join(t.TempDir(), "node_stats.json")
+	if err := InitialiseRecorder(filename, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	recorder := GetRecorderSingleton()
+	defer recorder.Shutdown()
+
+	Delivered()
+	fc.BlockUntil(1)
+	fc.Advance(time.Minute)
+
+	// without a shutdown, as if the node was killed, the latest write is kept
+	deadline := time.Now().Add(time.Second)
+	for {
+		if totals, known, err := loadTotals(filename); err != nil {
+			t.Fatal(err)
+		} else if known && totals.Delivered == 1 && totals.Uptime == time.Minute {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Persisted totals were not updated, got %+v", totals)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,183 @@
+package processing
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/id_keeper"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// isForOwnAdministrativeEndpoint checks if the bundle carries an administrative record addressed to this node,
+// either to its primary node ID or an alias.
+func isForOwnAdministrativeEndpoint(bundle *bpv7.Bundle) bool {
+	return bundle.IsAdministrativeRecord() && isOwnNodeID(bundle.PrimaryBlock.Destination)
+}
+
+// receiveAdministrativeRecord consumes a bundle addressed to this node's administrative endpoint.
+// Such a bundle is neither delivered to an application agent nor forwarded.
+func receiveAdministrativeRecord(bundleDescriptor *store.BundleDescriptor, bundle *bpv7.Bundle) {
+	// The record is consumed from memory, such that its stored copy is deleted right away instead of piling up until
+	// its expiry. Thus, a later duplicate is processed again, unless the optional replay window rejects it.
+	if err := store.GetStoreSingleton().DeleteBundle(bundleDescriptor); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error deleting administrative record")
+	}
+
+	record, err := bundle.AdministrativeRecord()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Warn("Discarding unsupported or malformed administrative record, e.g., a custody signal")
+		return
+	}
+
+	switch record := record.(type) {
+	case *bpv7.StatusReport:
+		receiveStatusReport(bundle, record)
+
+	default:
+		log.WithFields(log.Fields{
+			"bundle":      bundleDescriptor.ID,
+			"record type": record.RecordTypeCode(),
+		}).Warn("Discarding administrative record without handler")
+	}
+}
+
+// receiveStatusReport correlates a status report with locally sent bundles and annotates the referenced bundle,
+// if it is still stored, with the reported status information.
+func receiveStatusReport(bundle *bpv7.Bundle, report *bpv7.StatusReport) {
+	log.WithFields(log.Fields{
+		"report source": bundle.PrimaryBlock.SourceNode,
+		"bundle":        report.RefBundle,
+		"status":        report.StatusInformations(),
+		"reason":        report.ReportReason,
+	}).Info("Received status report")
+
+	application_agent.GetManagerSingleton().ReceiveStatusReport(bundle.PrimaryBlock.SourceNode, report)
+
+	if !store.GetStoreSingleton().KnownBundle(report.RefBundle) {
+		if routing.IsFailureReport(report) && isOwnNode(report.RefBundle.SourceNode) {
+			notifyFailureReport(nil, bundle.PrimaryBlock.SourceNode, report)
+		}
+		return
+	}
+
+	refDescriptor, err := store.GetStoreSingleton().LoadBundleDescriptor(report.RefBundle)
+	if errors.Is(err, store.ErrNotFound) {
+		// The referenced bundle was deleted after the check above, e.g., as it was delivered.
+		return
+	} else if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": report.RefBundle,
+			"error":  err,
+		}).Error("Error loading bundle referenced by status report")
+		return
+	}
+
+	for _, sip := range report.StatusInformations() {
+		err := refDescriptor.SetMetadata(StatusReportMetadataKey(sip), bundle.PrimaryBlock.SourceNode.String())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"bundle": report.RefBundle,
+				"error":  err,
+			}).Error("Error storing status report in bundle metadata")
+			return
+		}
+	}
+
+	if routing.IsFailureReport(report) {
+		notifyFailureReport(refDescriptor, bundle.PrimaryBlock.SourceNode, report)
+	}
+}
+
+// notifyFailureReport feeds a failure back to the routing Algorithm, if it implements the FailureReportReceiver,
+// and forwards a still stored, dispatchable bundle again to let it be rerouted.
+func notifyFailureReport(refDescriptor *store.BundleDescriptor, reportingNode bpv7.EndpointID, report *bpv7.StatusReport) {
+	receiver, ok := routing.GetAlgorithmSingleton().(routing.FailureReportReceiver)
+	if !ok {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"bundle": report.RefBundle,
+		"node":   reportingNode,
+		"reason": report.ReportReason,
+	}).Info("Notifying routing of failure report")
+	receiver.NotifyFailureReport(refDescriptor, reportingNode, report)
+
+	if refDescriptor != nil && refDescriptor.Dispatch && !hasConstraint(refDescriptor, store.ForwardPending) {
+		BundleForwarding(refDescriptor)
+	}
+}
+
+// StatusReportMetadataKey is the BundleDescriptor's metadata key for a received status report's information,
+// e.g., "status_report_delivered_bundle". Its value is the reporting node.
+func StatusReportMetadataKey(sip bpv7.StatusInformationPos) string {
+	return "status_report_" + strings.ReplaceAll(sip.String(), " ", "_")
+}
+
+// statusReportMinLifetime is the lowest lifetime of a status report, even if the reported bundle is about to expire.
+const statusReportMinLifetime = time.Minute
+
+// reportDeletion notifies the local application which sent a bundle of its deletion by this node.
+func reportDeletion(bundle *bpv7.Bundle, reason bpv7.StatusReportReason) {
+	application_agent.GetManagerSingleton().ReportDeletion(ownNodeID, bundle.ID(), reason)
+}
+
+// sendStatusReport creates a status report about the bundle and sends it to the bundle's report-to endpoint.
+// As described in RFC 9171 section 6.1, no status reports are generated for administrative records.
+//
+// The report inherits the bundle's priority and lives as long as the bundle's remaining lifetime, but at least for
+// statusReportMinLifetime. It is limited to the configured hops, see SetStatusReportHopLimit.
+func sendStatusReport(bundle *bpv7.Bundle, sip bpv7.StatusInformationPos, reason bpv7.StatusReportReason) {
+	if bundle.IsAdministrativeRecord() || bundle.PrimaryBlock.ReportTo.SameNode(bpv7.DtnNone()) {
+		return
+	}
+
+	lifetime := bundle.RemainingLifetime()
+	if lifetime < statusReportMinLifetime {
+		lifetime = statusReportMinLifetime
+	}
+
+	bldr := bpv7.Builder().
+		Source(ownNodeIDFor(bundle.PrimaryBlock.ReportTo)).
+		Destination(bundle.PrimaryBlock.ReportTo).
+		CreationTimestampNow().
+		Lifetime(lifetime).
+		StatusReport(*bundle, sip, reason)
+	if bundle.HasExtensionBlock(bpv7.ExtBlockTypePriorityBlock) {
+		bldr = bldr.PriorityBlock(bundle.Priority())
+	}
+	if statusReportHopLimit > 0 {
+		bldr = bldr.HopCountBlock(int(statusReportHopLimit))
+	}
+
+	report, err := bldr.Build()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundle.ID(),
+			"error":  err,
+		}).Error("Error creating status report")
+		return
+	}
+
+	id_keeper.GetIdKeeperSingleton().Update(&report)
+	log.WithFields(log.Fields{
+		"bundle":    bundle.ID(),
+		"report":    report.ID(),
+		"status":    sip,
+		"reason":    reason,
+		"report to": bundle.PrimaryBlock.ReportTo,
+	}).Info("Sending status report")
+	SendBundle(&report)
+}
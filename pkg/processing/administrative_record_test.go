@@ -0,0 +1,83 @@
+package processing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+	"github.com/dtn7/dtn7-go/pkg/testutil"
+)
+
+func TestReceiveAdministrativeRecord(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm(peer))
+
+	sent, err := bpv7.Builder().
+		Source("dtn://node/app").
+		Destination("dtn://dst/").
+		ReportTo("dtn://node/").
+		BundleCtrlFlags(bpv7.StatusRequestDelivery).
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	application_agent.GetManagerSingleton().Send(&sent)
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bundle was not forwarded")
+	}
+
+	report, err := bpv7.Builder().
+		Source("dtn://dst/").
+		Destination("dtn://node/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		StatusReport(sent, bpv7.DeliveredBundle, bpv7.NoInformation).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	processing.ReceiveBundle(&report)
+
+	waitFor(t, "delivery status report", func() bool {
+		state, ok := application_agent.GetManagerSingleton().DeliveryState(sent.ID())
+		_, delivered := state.DeliveredAt()
+		return ok && delivered
+	})
+
+	if store.GetStoreSingleton().KnownBundle(report.ID()) {
+		t.Fatal("Administrative record is still stored after its processing")
+	}
+	if dispatchable, err := store.GetStoreSingleton().GetDispatchable(store.DispatchQuery{}); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, bd := range dispatchable {
+			if bd.ID == report.ID() {
+				t.Fatal("Administrative record is pending to be dispatched")
+			}
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	for _, bndl := range peer.Sent() {
+		if bndl.ID() == report.ID() {
+			t.Fatal("Administrative record was forwarded")
+		}
+	}
+}
+
+// waitFor polls the condition until it holds, as the processing works asynchronously.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); !condition(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout while waiting for %s", what)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package processing
+
+import (
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// ownNodeAliases are further node IDs of this node, e.g., an ipn node ID next to the primary dtn ownNodeID.
+var ownNodeAliases []bpv7.EndpointID
+
+// SetOwnNodeAliases configures additional node IDs, possibly of other schemes. Administrative records addressed to
+// any of them are received locally and peers of an alias's scheme see the alias as the previous node.
+func SetOwnNodeAliases(aliases []bpv7.EndpointID) {
+	ownNodeAliases = aliases
+}
+
+// isOwnNodeID checks if the endpoint is the primary node ID or one of its aliases.
+func isOwnNodeID(eid bpv7.EndpointID) bool {
+	if eid == ownNodeID {
+		return true
+	}
+	for _, alias := range ownNodeAliases {
+		if eid == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnNode checks if the endpoint belongs to this node, i.e., shares its node with the primary node ID or an alias.
+func isOwnNode(eid bpv7.EndpointID) bool {
+	if ownNodeID.SameNode(eid) {
+		return true
+	}
+	for _, alias := range ownNodeAliases {
+		if alias.SameNode(eid) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnNodeMatching checks if the primary node ID or one of its aliases is matched by the pattern.
+func isOwnNodeMatching(pattern bpv7.EndpointPattern) bool {
+	if pattern.Match(ownNodeID) {
+		return true
+	}
+	for _, alias := range ownNodeAliases {
+		if pattern.Match(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownNodeIDFor selects the own node ID matching the scheme of another endpoint, e.g., a peer or a report-to
+// endpoint. The primary node ID is preferred and used if no alias matches.
+func ownNodeIDFor(eid bpv7.EndpointID) bpv7.EndpointID {
+	if eid.EndpointType == nil || ownNodeID.EndpointType == nil ||
+		ownNodeID.EndpointType.SchemeName() == eid.EndpointType.SchemeName() {
+		return ownNodeID
+	}
+
+	for _, alias := range ownNodeAliases {
+		if alias.EndpointType.SchemeName() == eid.EndpointType.SchemeName() {
+			return alias
+		}
+	}
+	return ownNodeID
+}
+
+// withPreviousNodeFor returns the bundle with a Previous Node Block naming the own node ID matching the peer's
+// scheme, if it names this node at all. The CanonicalBlocks are copied if changed, as the bundle is sent to multiple peers concurrently.
+func withPreviousNodeFor(bundle bpv7.Bundle, peer bpv7.EndpointID) bpv7.Bundle {
+	nodeID := ownNodeIDFor(peer)
+	if nodeID == ownNodeID {
+		return bundle
+	}
+
+	prevNodeBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock)
+	if err != nil || prevNodeBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint() != ownNodeID {
+		// An anonymous Previous Node Block is kept as is
+		return bundle
+	}
+	blockNumber := prevNodeBlock.BlockNumber
+
+	blocks := make([]bpv7.CanonicalBlock, len(bundle.CanonicalBlocks))
+	copy(blocks, bundle.CanonicalBlocks)
+	for i := range blocks {
+		if blocks[i].BlockNumber == blockNumber {
+			blocks[i].Value = bpv7.NewPreviousNodeBlock(nodeID)
+		}
+	}
+	bundle.CanonicalBlocks = blocks
+	return bundle
+}
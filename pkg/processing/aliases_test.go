@@ -0,0 +1,32 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestIsOwnNodeMatching(t *testing.T) {
+	SetOwnNodeID(bpv7.MustNewEndpointID("dtn://gateway-1/"))
+	SetOwnNodeAliases([]bpv7.EndpointID{bpv7.MustNewEndpointID("ipn:5.1")})
+	defer SetOwnNodeID(bpv7.EndpointID{})
+	defer SetOwnNodeAliases(nil)
+
+	tests := []struct {
+		pattern string
+		matches bool
+	}{
+		{"dtn://gateway-1/", true},
+		{"dtn://gateway-*/", true},
+		{"dtn://gateway-2/", false},
+		{"ipn:1-9.*", true},
+		{"ipn:5.2", false},
+		{"*", true},
+	}
+
+	for _, test := range tests {
+		if matches := isOwnNodeMatching(bpv7.MustNewEndpointPattern(test.pattern)); matches != test.matches {
+			t.Errorf("Pattern %s: expected %t, got %t", test.pattern, test.matches, matches)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package processing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// processUnknownBlocks applies the block processing control flags of all extension blocks unknown to this node,
+// as described in RFC 9171 section 5.6, step 3. This happens once for each newly received bundle, before it is
+// stored and thus forwarded.
+//
+// The SignatureBlock is known to the processing, even if not registered, as it is verified by checkSignature.
+//
+// Unknown blocks are reported if requested, removed if flagged as such, or otherwise passed through. If one
+// unknown block demands the bundle's deletion, false is returned and the bundle must not be processed any further.
+func processUnknownBlocks(bundle *bpv7.Bundle) (keep bool) {
+	var removeBlocks []uint64
+
+	for _, block := range bundle.CanonicalBlocks {
+		if bpv7.GetExtensionBlockManager().IsKnown(block.TypeCode()) ||
+			block.TypeCode() == bpv7.ExtBlockTypeSignatureBlock {
+			continue
+		}
+
+		logger := log.WithFields(log.Fields{
+			"bundle":      bundle.ID(),
+			"block type":  block.TypeCode(),
+			"block flags": block.BlockControlFlags,
+		})
+
+		if block.BlockControlFlags.Has(bpv7.StatusReportBlock) {
+			sendStatusReport(bundle, bpv7.ReceivedBundle, bpv7.BlockUnsupported)
+		}
+
+		switch {
+		case block.BlockControlFlags.Has(bpv7.DeleteBundle):
+			logger.Info("Deleting bundle due to an unsupported block")
+			if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDeletion) {
+				sendStatusReport(bundle, bpv7.DeletedBundle, bpv7.BlockUnsupported)
+			}
+			return false
+
+		case block.BlockControlFlags.Has(bpv7.RemoveBlock):
+			logger.Debug("Removing unsupported block")
+			removeBlocks = append(removeBlocks, block.BlockNumber)
+
+		default:
+			logger.Debug("Passing unsupported block through")
+		}
+	}
+
+	for _, blockNumber := range removeBlocks {
+		bundle.RemoveExtensionBlockByBlockNumber(blockNumber)
+	}
+	return true
+}
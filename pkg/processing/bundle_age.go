@@ -0,0 +1,44 @@
+package processing
+
+import (
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// withDwellTime returns the bundle with its optional Bundle Age Block increased by the time the bundle has spent on
+// this node, as described in RFC 9171 section 4.4.2. The stored bundle is left untouched, such that a later dispatch
+// adds the whole dwell time again instead of accumulating it. Without a known reception time, the bundle is returned
+// as it is.
+func withDwellTime(bundle bpv7.Bundle, bundleDescriptor *store.BundleDescriptor) bpv7.Bundle {
+	ageBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypeBundleAgeBlock)
+	if err != nil || bundleDescriptor.Received.IsZero() {
+		return bundle
+	}
+
+	dwellTime := clock.Now().Sub(bundleDescriptor.Received)
+	if dwellTime <= 0 {
+		// A clockless node's clock might have been reset by a restart
+		return bundle
+	}
+	age := bpv7.NewBundleAgeBlock(ageBlock.Value.(*bpv7.BundleAgeBlock).Age() + uint64(dwellTime.Milliseconds()))
+
+	blocks := make([]bpv7.CanonicalBlock, len(bundle.CanonicalBlocks))
+	copy(blocks, bundle.CanonicalBlocks)
+	for i := range blocks {
+		if blocks[i].BlockNumber == ageBlock.BlockNumber {
+			blocks[i].Value = age
+		}
+	}
+	bundle.CanonicalBlocks = blocks
+	return bundle
+}
+
+// isExpired checks if a stored bundle's lifetime is exceeded. As a clockless node cannot judge the age of bundles
+// without a Bundle Age Block, these expire after their lifetime on this node.
+func isExpired(bundle bpv7.Bundle, bundleDescriptor *store.BundleDescriptor) bool {
+	if bundle.IsLifetimeExceeded() {
+		return true
+	}
+	return bpv7.IsClockless() && !bundleDescriptor.Expires.IsZero() && clock.Now().After(bundleDescriptor.Expires)
+}
@@ -0,0 +1,274 @@
+package processing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/reputation"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// errCongested is the reason for a received bundle to be refused by the CongestionPolicy.
+var errCongested = errors.New("bundle was refused due to storage congestion")
+
+// CongestionDecision is a CongestionPolicy's verdict on a received bundle.
+type CongestionDecision int
+
+const (
+	// CongestionAccept stores the received bundle.
+	CongestionAccept CongestionDecision = iota
+	// CongestionAcceptAndEvict deletes stored bundles to make room for the received bundle.
+	CongestionAcceptAndEvict
+	// CongestionRefuse drops the received bundle.
+	CongestionRefuse
+)
+
+func (decision CongestionDecision) String() string {
+	switch decision {
+	case CongestionAccept:
+		return "accept"
+	case CongestionAcceptAndEvict:
+		return "accept and evict"
+	case CongestionRefuse:
+		return "refuse"
+	default:
+		return "unknown"
+	}
+}
+
+// CongestionPolicy is consulted for each newly received bundle while the store's capacity is limited.
+type CongestionPolicy interface {
+	// Decide on a bundle of the serialised size, given the store's current Occupancy. For CongestionAcceptAndEvict,
+	// the returned bundles are deleted before the received one is stored.
+	Decide(bundle *bpv7.Bundle, size int64, occupancy store.Occupancy) (CongestionDecision, []*store.BundleDescriptor)
+}
+
+// CongestionPolicyFromString creates one of the built-in CongestionPolicies, "drop-newest", "drop-oldest" or
+// "priority".
+func CongestionPolicyFromString(s string) (CongestionPolicy, error) {
+	switch s {
+	case "drop-newest":
+		return DropNewest{}, nil
+	case "drop-oldest":
+		return DropOldest{}, nil
+	case "priority":
+		return DropByPriority{}, nil
+	default:
+		return nil, fmt.Errorf("unknown congestion policy %q", s)
+	}
+}
+
+var (
+	congestionPolicyMutex sync.Mutex
+	congestionPolicy      CongestionPolicy
+)
+
+// SetCongestionPolicy configures the CongestionPolicy for received bundles, nil to accept all of them.
+func SetCongestionPolicy(policy CongestionPolicy) {
+	congestionPolicyMutex.Lock()
+	defer congestionPolicyMutex.Unlock()
+
+	congestionPolicy = policy
+}
+
+// fits checks if a bundle of the size fits into the store without evictions.
+func fits(size int64, occupancy store.Occupancy) bool {
+	return occupancy.Capacity <= 0 || occupancy.Used+size <= occupancy.Capacity
+}
+
+// DropNewest refuses every received bundle exceeding the store's capacity, keeping all stored bundles.
+type DropNewest struct{}
+
+func (DropNewest) Decide(_ *bpv7.Bundle, size int64, occupancy store.Occupancy) (CongestionDecision, []*store.BundleDescriptor) {
+	if fits(size, occupancy) {
+		return CongestionAccept, nil
+	}
+	return CongestionRefuse, nil
+}
+
+// DropOldest evicts the stored bundles with the oldest creation timestamps until a received bundle fits.
+type DropOldest struct{}
+
+func (DropOldest) Decide(_ *bpv7.Bundle, size int64, occupancy store.Occupancy) (CongestionDecision, []*store.BundleDescriptor) {
+	if fits(size, occupancy) {
+		return CongestionAccept, nil
+	}
+
+	candidates := evictionCandidates()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].creation < candidates[j].creation
+	})
+	return selectEvictions(candidates, size, occupancy)
+}
+
+// DropByPriority evicts stored bundles of a lower or the same priority as a received bundle until it fits, the lowest
+// priorities and the oldest creation timestamps first. Otherwise, the received bundle is refused.
+type DropByPriority struct{}
+
+func (DropByPriority) Decide(bundle *bpv7.Bundle, size int64, occupancy store.Occupancy) (CongestionDecision, []*store.BundleDescriptor) {
+	if fits(size, occupancy) {
+		return CongestionAccept, nil
+	}
+
+	priority := bundle.Priority()
+	candidates := make([]evictionCandidate, 0)
+	for _, candidate := range evictionCandidates() {
+		if candidate.priority <= priority {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].creation < candidates[j].creation
+	})
+	return selectEvictions(candidates, size, occupancy)
+}
+
+// evictionCandidate is a stored bundle which might be evicted.
+type evictionCandidate struct {
+	descriptor *store.BundleDescriptor
+	size       int64
+	creation   bpv7.DtnTime
+	priority   bpv7.Priority
+}
+
+// evictionCandidates lists all stored bundles except those currently being forwarded. They are chosen by their
+// descriptors' metadata, only bundles of stores created before BundleDescriptor.Size are loaded to get their sizes.
+func evictionCandidates() []evictionCandidate {
+	descriptors, err := store.GetStoreSingleton().GetAll()
+	if err != nil {
+		log.WithError(err).Warn("Error listing stored bundles for eviction")
+		return nil
+	}
+
+	candidates := make([]evictionCandidate, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		if hasConstraint(descriptor, store.ForwardPending) {
+			continue
+		}
+
+		size, priority := descriptor.Size, descriptor.Priority
+		if size == 0 {
+			bundle, err := descriptor.Load()
+			if err != nil {
+				continue
+			}
+			size, priority = int64(cla.BundleSize(bundle)), bundle.Priority()
+			descriptor.Bundle = nil
+		}
+		candidates = append(candidates, evictionCandidate{
+			descriptor: descriptor,
+			size:       size,
+			creation:   descriptor.ID.Timestamp.DtnTime(),
+			priority:   priority,
+		})
+	}
+	return candidates
+}
+
+// selectEvictions takes candidates in their order until a bundle of the size fits, or refuses it if they do not
+// suffice.
+func selectEvictions(candidates []evictionCandidate, size int64, occupancy store.Occupancy) (CongestionDecision, []*store.BundleDescriptor) {
+	if size > occupancy.Capacity {
+		return CongestionRefuse, nil
+	}
+
+	evictions := make([]*store.BundleDescriptor, 0)
+	for _, candidate := range candidates {
+		if fits(size, occupancy) {
+			break
+		}
+		evictions = append(evictions, candidate.descriptor)
+		occupancy.Used -= candidate.size
+	}
+
+	if !fits(size, occupancy) {
+		return CongestionRefuse, nil
+	}
+	return CongestionAcceptAndEvict, evictions
+}
+
+func hasConstraint(bundleDescriptor *store.BundleDescriptor, constraint store.Constraint) bool {
+	for _, c := range bundleDescriptor.RetentionConstraints {
+		if c == constraint {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCongestion consults the CongestionPolicy for a received bundle and evicts stored bundles if demanded. It
+// returns false if the bundle was refused. Refused and evicted bundles are reported as deleted due to depleted storage.
+func checkCongestion(bundle *bpv7.Bundle) bool {
+	congestionPolicyMutex.Lock()
+	policy := congestionPolicy
+	congestionPolicyMutex.Unlock()
+
+	if policy == nil {
+		return true
+	}
+
+	occupancy := store.GetStoreSingleton().Occupancy()
+	if occupancy.Capacity <= 0 {
+		return true
+	}
+
+	decision, evictions := policy.Decide(bundle, int64(cla.BundleSize(*bundle)), occupancy)
+	switch decision {
+	case CongestionRefuse:
+		log.WithFields(log.Fields{
+			"bundle":   bundle.ID(),
+			"used":     occupancy.Used,
+			"capacity": occupancy.Capacity,
+		}).Warn("Congestion policy refused received bundle")
+		sendStatusReport(bundle, bpv7.DeletedBundle, bpv7.DepletedStorage)
+		return false
+
+	case CongestionAcceptAndEvict:
+		for _, bundleDescriptor := range evictions {
+			evictBundle(bundleDescriptor)
+		}
+		log.WithFields(log.Fields{
+			"bundle":  bundle.ID(),
+			"evicted": len(evictions),
+		}).Info("Congestion policy evicted stored bundles for received bundle")
+	}
+	return true
+}
+
+// AdmitBundle decides on a bundle about to be received by the CongestionPolicy, evicting stored bundles if demanded.
+// Convergence layers able to refuse bundles call this before acknowledging one, thus signalling a refusal to their peer.
+func AdmitBundle(bundle *bpv7.Bundle) error {
+	if store.GetStoreSingleton().KnownBundle(bundle.ID()) || checkCongestion(bundle) {
+		return nil
+	}
+	reportPeer(bundle, reputation.Refused)
+	return errCongested
+}
+
+// evictBundle deletes a stored bundle to make room for another one.
+func evictBundle(bundleDescriptor *store.BundleDescriptor) {
+	evicted, loadErr := bundleDescriptor.Load()
+
+	if err := store.GetStoreSingleton().DeleteBundle(bundleDescriptor); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error evicting bundle")
+		return
+	}
+
+	log.WithField("bundle", bundleDescriptor.ID).Debug("Evicted bundle due to storage congestion")
+	if loadErr == nil {
+		reportDeletion(&evicted, bpv7.DepletedStorage)
+		sendStatusReport(&evicted, bpv7.DeletedBundle, bpv7.DepletedStorage)
+	}
+}
@@ -0,0 +1,77 @@
+package processing
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/node_stats"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// deliverLocally hands a bundle over to the local application agents, as described in RFC 9171 section 5.7. If an
+// agent refused it, the delivery is deferred by the DeliveryPending constraint and retried by redeliver.
+func deliverLocally(bundleDescriptor *store.BundleDescriptor, bundle *bpv7.Bundle) {
+	delivered, err := application_agent.GetManagerSingleton().Delivery(bundleDescriptor)
+	if errors.Is(err, application_agent.ErrMailboxFull) {
+		if err := bundleDescriptor.AddConstraint(store.DeliveryPending); err != nil {
+			log.WithFields(log.Fields{
+				"bundle": bundleDescriptor.ID,
+				"error":  err,
+			}).Error("Error adding constraint to bundle")
+		}
+	} else if delivered {
+		bundleDelivered(bundle)
+	}
+}
+
+// redeliver retries the deferred delivery of a bundle refused by a local application agent before, i.e., having the
+// DeliveryPending constraint. It is part of each dispatch of the bundle. A bundle whose lifetime was exceeded meanwhile
+// is deleted instead and false is returned.
+func redeliver(bundleDescriptor *store.BundleDescriptor) bool {
+	if !hasConstraint(bundleDescriptor, store.DeliveryPending) {
+		return true
+	}
+
+	bundle, err := bundleDescriptor.Load()
+	if deletedMeanwhile(bundleDescriptor, err) {
+		return false
+	} else if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error loading bundle pending delivery")
+		return true
+	}
+	if isExpired(withDwellTime(bundle, bundleDescriptor), bundleDescriptor) {
+		deleteExpired(bundleDescriptor, &bundle)
+		return false
+	}
+
+	delivered, err := application_agent.GetManagerSingleton().Redelivery(bundleDescriptor)
+	if errors.Is(err, application_agent.ErrMailboxFull) {
+		return true
+	}
+	if err := bundleDescriptor.RemoveConstraint(store.DeliveryPending); deletedMeanwhile(bundleDescriptor, err) {
+		return false
+	} else if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error removing constraint from bundle")
+	}
+	if delivered {
+		bundleDelivered(&bundle)
+	}
+	return true
+}
+
+// bundleDelivered counts a delivered bundle and sends a delivery status report if requested.
+func bundleDelivered(bundle *bpv7.Bundle) {
+	node_stats.Delivered()
+	if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDelivery) {
+		sendStatusReport(bundle, bpv7.DeliveredBundle, bpv7.NoInformation)
+	}
+}
@@ -0,0 +1,144 @@
+package processing_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+	"github.com/dtn7/dtn7-go/pkg/testutil"
+)
+
+// refusingAgent is an application agent whose mailbox is full until it is emptied.
+type refusingAgent struct {
+	endpoint bpv7.EndpointID
+
+	mutex     sync.Mutex
+	full      bool
+	delivered []bpv7.BundleID
+}
+
+func (agent *refusingAgent) Endpoints() []bpv7.EndpointID {
+	return []bpv7.EndpointID{agent.endpoint}
+}
+
+func (agent *refusingAgent) Deliver(bd *store.BundleDescriptor) error {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	if bd.Destination != agent.endpoint {
+		return nil
+	} else if agent.full {
+		return fmt.Errorf("%w: bundle %v", application_agent.ErrMailboxFull, bd.ID)
+	}
+	agent.delivered = append(agent.delivered, bd.ID)
+	return nil
+}
+
+func (agent *refusingAgent) Shutdown() {}
+
+func (agent *refusingAgent) setFull(full bool) {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	agent.full = full
+}
+
+func (agent *refusingAgent) deliveries() []bpv7.BundleID {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	return append([]bpv7.BundleID(nil), agent.delivered...)
+}
+
+// deliveryReports returns the delivery status reports sent to the peer.
+func deliveryReports(t *testing.T, peer *testutil.FakeSender) (reports []bpv7.BundleID) {
+	t.Helper()
+
+	for _, bndl := range peer.Sent() {
+		if !bndl.IsAdministrativeRecord() {
+			continue
+		}
+		record, err := bndl.AdministrativeRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report, ok := record.(*bpv7.StatusReport); ok && report.StatusInformation[bpv7.DeliveredBundle].Asserted {
+			reports = append(reports, report.RefBundle)
+		}
+	}
+	return
+}
+
+func TestDeferredDelivery(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm(peer))
+
+	agent := &refusingAgent{endpoint: bpv7.MustNewEndpointID("dtn://node/inbox"), full: true}
+	if err := application_agent.GetManagerSingleton().RegisterAgent(agent); err != nil {
+		t.Fatal(err)
+	}
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://node/inbox").
+		ReportTo("dtn://src/").
+		BundleCtrlFlags(bpv7.StatusRequestDelivery).
+		CreationTimestampNow().
+		Lifetime("1h").
+		PreviousNodeBlock("dtn://upstream/").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a refused bundle is neither counted nor reported as delivered, but kept for a later delivery
+	processing.ReceiveBundle(&bndl)
+	waitFor(t, "deferred delivery", func() bool {
+		bd, err := store.GetStoreSingleton().LoadBundleDescriptor(bndl.ID())
+		return err == nil && bd.Retain && len(bd.RetentionConstraints) == 1 &&
+			bd.RetentionConstraints[0] == store.DeliveryPending
+	})
+	if reports := deliveryReports(t, peer); len(reports) > 0 {
+		t.Fatalf("Refused bundle was reported as delivered, %v", reports)
+	}
+	if stats := application_agent.GetManagerSingleton().DeliveryStats().Endpoints[agent.endpoint]; stats.Delivered > 0 {
+		t.Fatalf("Refused bundle was counted as delivered")
+	}
+
+	// each dispatch retries the delivery, which is refused while the mailbox is still full
+	processing.DispatchPending()
+	time.Sleep(50 * time.Millisecond)
+	if delivered := agent.deliveries(); len(delivered) > 0 {
+		t.Fatalf("Bundle was delivered into a full mailbox, %v", delivered)
+	}
+
+	agent.setFull(false)
+	processing.DispatchPending()
+	waitFor(t, "redelivery", func() bool {
+		bd, err := store.GetStoreSingleton().LoadBundleDescriptor(bndl.ID())
+		return err == nil && len(agent.deliveries()) == 1 && !bd.Retain
+	})
+	if delivered := agent.deliveries(); delivered[0] != bndl.ID() {
+		t.Fatalf("Expected the delivery of %v, got %v", bndl.ID(), delivered)
+	}
+	if stats := application_agent.GetManagerSingleton().DeliveryStats().Endpoints[agent.endpoint]; stats.Delivered != 1 {
+		t.Fatalf("Expected 1 delivered bundle, got %d", stats.Delivered)
+	}
+	waitFor(t, "delivery status report", func() bool {
+		reports := deliveryReports(t, peer)
+		return len(reports) == 1 && reports[0] == bndl.ID()
+	})
+
+	// a delivered bundle is not delivered again by the following dispatches
+	processing.DispatchPending()
+	time.Sleep(50 * time.Millisecond)
+	if delivered := agent.deliveries(); len(delivered) != 1 {
+		t.Fatalf("Bundle was delivered again, %v", delivered)
+	}
+}
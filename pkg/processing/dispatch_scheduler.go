@@ -0,0 +1,181 @@
+package processing
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+// DispatchSchedulerConfig specifies when the DispatchScheduler calls DispatchPending.
+type DispatchSchedulerConfig struct {
+	// Interval between two periodic dispatch sweeps
+	Interval time.Duration
+	// Jitter is an upper bound for a random duration added to each Interval.
+	// This prevents multiple nodes which were started at the same time from sweeping in lockstep.
+	Jitter time.Duration
+	// OnNewBundle triggers an additional sweep whenever a new bundle has been stored
+	OnNewBundle bool
+	// OnNewPeer triggers an additional sweep whenever a new peer has appeared
+	OnNewPeer bool
+}
+
+// CheckValid checks if this configuration can be used to set up a periodic job.
+func (conf DispatchSchedulerConfig) CheckValid() error {
+	if conf.Interval <= 0 {
+		return fmt.Errorf("dispatch interval must be positive, not %v", conf.Interval)
+	}
+	if conf.Jitter < 0 {
+		return fmt.Errorf("dispatch jitter must not be negative, not %v", conf.Jitter)
+	}
+	return nil
+}
+
+// jobDefinition creates the gocron.JobDefinition for the configured interval and jitter.
+func (conf DispatchSchedulerConfig) jobDefinition() gocron.JobDefinition {
+	if conf.Jitter == 0 {
+		return gocron.DurationJob(conf.Interval)
+	}
+	return gocron.DurationRandomJob(conf.Interval, conf.Interval+conf.Jitter)
+}
+
+// DispatchScheduler periodically calls DispatchPending and additionally allows event-driven sweeps.
+type DispatchScheduler struct {
+	mutex     sync.Mutex
+	config    DispatchSchedulerConfig
+	scheduler gocron.Scheduler
+	job       gocron.Job
+}
+
+// dispatchSchedulerSingleton is read by the event triggers of the processing goroutines, while being reset by Shutdown.
+var dispatchSchedulerSingleton atomic.Pointer[DispatchScheduler]
+
+// InitialiseDispatchScheduler initialises and starts the dispatch scheduler singleton.
+// To access Singleton-instance, use GetDispatchSchedulerSingleton
+// Further calls to this function after initialisation will return a util.AlreadyInitialised-error
+func InitialiseDispatchScheduler(config DispatchSchedulerConfig) error {
+	if dispatchSchedulerSingleton.Load() != nil {
+		return util.NewAlreadyInitialisedError("Dispatch Scheduler")
+	}
+
+	if err := config.CheckValid(); err != nil {
+		return err
+	}
+
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return err
+	}
+
+	// Reschedule instead of overlapping sweeps if a sweep takes longer than the interval or was triggered by an event
+	job, err := scheduler.NewJob(
+		config.jobDefinition(),
+		gocron.NewTask(DispatchPending),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule))
+	if err != nil {
+		return err
+	}
+
+	scheduler.Start()
+
+	log.WithFields(log.Fields{
+		"interval":      config.Interval,
+		"jitter":        config.Jitter,
+		"on new bundle": config.OnNewBundle,
+		"on new peer":   config.OnNewPeer,
+	}).Info("Started dispatch scheduler")
+
+	dispatchSchedulerSingleton.Store(&DispatchScheduler{
+		config:    config,
+		scheduler: scheduler,
+		job:       job,
+	})
+	return nil
+}
+
+// GetDispatchSchedulerSingleton returns the dispatch scheduler singleton-instance.
+// Attempting to call this function before scheduler initialisation will cause the program to panic.
+func GetDispatchSchedulerSingleton() *DispatchScheduler {
+	ds := dispatchSchedulerSingleton.Load()
+	if ds == nil {
+		log.Fatalf("Attempting to access an uninitialised dispatch scheduler. This must never happen!")
+	}
+	return ds
+}
+
+// Config returns the scheduler's current configuration.
+func (ds *DispatchScheduler) Config() DispatchSchedulerConfig {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	return ds.config
+}
+
+// Reconfigure replaces the scheduler's configuration at runtime.
+func (ds *DispatchScheduler) Reconfigure(config DispatchSchedulerConfig) error {
+	if err := config.CheckValid(); err != nil {
+		return err
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	job, err := ds.scheduler.Update(
+		ds.job.ID(),
+		config.jobDefinition(),
+		gocron.NewTask(DispatchPending),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule))
+	if err != nil {
+		return err
+	}
+
+	ds.job = job
+	ds.config = config
+
+	log.WithFields(log.Fields{
+		"interval":      config.Interval,
+		"jitter":        config.Jitter,
+		"on new bundle": config.OnNewBundle,
+		"on new peer":   config.OnNewPeer,
+	}).Info("Reconfigured dispatch scheduler")
+
+	return nil
+}
+
+// Trigger an immediate dispatch sweep, independent of the periodic schedule.
+func (ds *DispatchScheduler) Trigger() {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if err := ds.job.RunNow(); err != nil {
+		log.WithError(err).Warn("Error triggering dispatch sweep")
+	}
+}
+
+// Shutdown stops the periodic sweeps.
+func (ds *DispatchScheduler) Shutdown() error {
+	dispatchSchedulerSingleton.CompareAndSwap(ds, nil)
+	return ds.scheduler.Shutdown()
+}
+
+// triggerDispatchOnNewBundle starts a dispatch sweep after a new bundle was stored, if configured to do so.
+func triggerDispatchOnNewBundle() {
+	if ds := dispatchSchedulerSingleton.Load(); ds != nil && ds.Config().OnNewBundle {
+		ds.Trigger()
+	}
+}
+
+// triggerDispatchOnNewPeer starts a dispatch sweep after a new peer has appeared, if configured to do so.
+// Without an initialised scheduler, a sweep is always performed.
+func triggerDispatchOnNewPeer() {
+	ds := dispatchSchedulerSingleton.Load()
+	if ds == nil {
+		DispatchPending()
+	} else if ds.Config().OnNewPeer {
+		ds.Trigger()
+	}
+}
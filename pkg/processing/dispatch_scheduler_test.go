@@ -0,0 +1,79 @@
+package processing
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatchSchedulerConfigCheckValid(t *testing.T) {
+	tests := []struct {
+		config DispatchSchedulerConfig
+		valid  bool
+	}{
+		{DispatchSchedulerConfig{Interval: 10 * time.Second}, true},
+		{DispatchSchedulerConfig{Interval: 10 * time.Second, Jitter: time.Second}, true},
+		{DispatchSchedulerConfig{}, false},
+		{DispatchSchedulerConfig{Interval: -time.Second}, false},
+		{DispatchSchedulerConfig{Interval: 10 * time.Second, Jitter: -time.Second}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.config.CheckValid(); (err == nil) != test.valid {
+			t.Errorf("%+v: expected validity %t, got %v", test.config, test.valid, err)
+		}
+	}
+}
+
+func TestDispatchSchedulerLifecycle(t *testing.T) {
+	// an hourly sweep does not run within this test, which has no store to dispatch from
+	config := DispatchSchedulerConfig{Interval: time.Hour, OnNewPeer: true}
+	if err := InitialiseDispatchScheduler(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitialiseDispatchScheduler(config); err == nil {
+		t.Fatal("Initialising the dispatch scheduler twice did not fail")
+	}
+
+	ds := GetDispatchSchedulerSingleton()
+	if ds.Config() != config {
+		t.Fatalf("Expected %+v, got %+v", config, ds.Config())
+	}
+
+	if err := ds.Reconfigure(DispatchSchedulerConfig{}); err == nil {
+		t.Fatal("Reconfiguring by an invalid configuration did not fail")
+	}
+	reconfigured := DispatchSchedulerConfig{Interval: 2 * time.Hour, Jitter: time.Minute}
+	if err := ds.Reconfigure(reconfigured); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Config() != reconfigured {
+		t.Fatalf("Expected %+v, got %+v", reconfigured, ds.Config())
+	}
+
+	// the event triggers might race with the shutdown, but must neither start a sweep nor crash
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				triggerDispatchOnNewBundle()
+			}
+		}()
+	}
+	if err := ds.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if dispatchSchedulerSingleton.Load() != nil {
+		t.Fatal("Dispatch scheduler is still set after its shutdown")
+	}
+	if err := InitialiseDispatchScheduler(config); err != nil {
+		t.Fatalf("Initialising the dispatch scheduler after its shutdown failed: %v", err)
+	}
+	if err := GetDispatchSchedulerSingleton().Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,27 @@
+package processing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// deleteExpired deletes a bundle whose lifetime was exceeded while it was stored, e.g., waiting for a contact or
+// another dispatch after failed transmissions. Its next hop would only drop it. A deletion status report is sent if
+// requested, as described in RFC 9171 section 5.10.
+func deleteExpired(bundleDescriptor *store.BundleDescriptor, bundle *bpv7.Bundle) {
+	if err := store.GetStoreSingleton().DeleteBundle(bundleDescriptor); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error deleting expired bundle")
+		return
+	}
+
+	log.WithField("bundle", bundleDescriptor.ID).Info("Deleted bundle due to its exceeded lifetime")
+	reportDeletion(bundle, bpv7.LifetimeExpired)
+	if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDeletion) {
+		sendStatusReport(bundle, bpv7.DeletedBundle, bpv7.LifetimeExpired)
+	}
+}
@@ -0,0 +1,123 @@
+package processing
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// GatewayRule rewrites an endpoint of one naming domain into another one, e.g., from an ipn numbered network into a
+// dtn named one. A node rule rewrites all endpoints of the From endpoint's node to the To endpoint's node, keeping
+// their demux or service number, which must be numeric for an ipn node. Otherwise, exactly the From endpoint is
+// rewritten.
+type GatewayRule struct {
+	From bpv7.EndpointID
+	To   bpv7.EndpointID
+	Node bool
+}
+
+// CheckValid checks that both endpoints are valid and, for a node rule, of a known scheme.
+func (rule GatewayRule) CheckValid() error {
+	if err := rule.From.CheckValid(); err != nil {
+		return err
+	}
+	if err := rule.To.CheckValid(); err != nil {
+		return err
+	}
+	if rule.Node && (endpointService(rule.From) == nil || endpointService(rule.To) == nil) {
+		return fmt.Errorf("gateway node rule from %v to %v requires dtn or ipn endpoints", rule.From, rule.To)
+	}
+	return nil
+}
+
+// rewrite an endpoint by this rule. It returns false if the rule does not apply.
+func (rule GatewayRule) rewrite(eid bpv7.EndpointID) (bpv7.EndpointID, bool) {
+	if !rule.Node {
+		if eid != rule.From {
+			return eid, false
+		}
+		return rule.To, true
+	}
+	if !rule.From.SameNode(eid) {
+		return eid, false
+	}
+
+	service := endpointService(eid)
+	if service == nil {
+		return eid, false
+	}
+	var uri string
+	switch rule.To.EndpointType.(type) {
+	case bpv7.DtnEndpoint:
+		uri = fmt.Sprintf("dtn://%s/%s", rule.To.Authority(), *service)
+	case bpv7.IpnEndpoint:
+		uri = fmt.Sprintf("ipn:%s.%s", rule.To.Authority(), *service)
+	}
+	rewritten, err := bpv7.NewEndpointID(uri)
+	if err != nil {
+		// e.g., a non-numeric demux for an ipn node
+		return eid, false
+	}
+	return rewritten, true
+}
+
+// endpointService returns the demux of a dtn endpoint or the service number of an ipn endpoint, nil otherwise.
+func endpointService(eid bpv7.EndpointID) *string {
+	var service string
+	switch e := eid.EndpointType.(type) {
+	case bpv7.DtnEndpoint:
+		if e.IsDtnNone {
+			return nil
+		}
+		service = e.Demux
+	case bpv7.IpnEndpoint:
+		service = strconv.FormatUint(e.Service, 10)
+	default:
+		return nil
+	}
+	return &service
+}
+
+var gatewayRules []GatewayRule
+
+// SetGatewayRules turns this node into a gateway between two separately numbered networks, e.g., an ipn and a dtn
+// network. The destination and report-to endpoints of received bundles are rewritten by the first matching rule before
+// they are stored. Thus, both networks address each other's nodes by aliases within their own naming domain. The
+// source endpoint is kept, as it is part of the bundle's ID. Rewritten bundles fail the verification of signatures
+// covering their primary block behind the gateway.
+func SetGatewayRules(rules []GatewayRule) {
+	gatewayRules = rules
+}
+
+// rewriteGatewayEndpoints rewrites a received bundle's destination and report-to endpoints by the gateway rules.
+func rewriteGatewayEndpoints(bundle *bpv7.Bundle) {
+	if len(gatewayRules) == 0 {
+		return
+	}
+
+	rewrite := func(eid bpv7.EndpointID) bpv7.EndpointID {
+		for _, rule := range gatewayRules {
+			if rewritten, ok := rule.rewrite(eid); ok {
+				return rewritten
+			}
+		}
+		return eid
+	}
+
+	primary := &bundle.PrimaryBlock
+	destination, reportTo := rewrite(primary.Destination), rewrite(primary.ReportTo)
+	if destination == primary.Destination && reportTo == primary.ReportTo {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":      bundle.ID(),
+		"destination": destination,
+		"report_to":   reportTo,
+	}).Debug("Gateway rewrote bundle's endpoints")
+	primary.Destination = destination
+	primary.ReportTo = reportTo
+}
@@ -0,0 +1,86 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestGatewayRuleRewrite(t *testing.T) {
+	ipnToDtn := GatewayRule{From: bpv7.MustNewEndpointID("ipn:5.1"), To: bpv7.MustNewEndpointID("dtn://gw/"), Node: true}
+	dtnToIpn := GatewayRule{From: bpv7.MustNewEndpointID("dtn://gw/"), To: bpv7.MustNewEndpointID("ipn:5.1"), Node: true}
+	dtnToDtn := GatewayRule{From: bpv7.MustNewEndpointID("dtn://old/"), To: bpv7.MustNewEndpointID("dtn://new/"), Node: true}
+	exact := GatewayRule{From: bpv7.MustNewEndpointID("dtn://old/inbox"), To: bpv7.MustNewEndpointID("ipn:7.3")}
+
+	tests := []struct {
+		name string
+		rule GatewayRule
+		eid  string
+		// rewritten is the expected endpoint, empty if the rule does not apply
+		rewritten string
+	}{
+		{"ipn to dtn", ipnToDtn, "ipn:5.3", "dtn://gw/3"},
+		{"ipn to dtn of another node", ipnToDtn, "ipn:6.3", ""},
+		{"dtn to ipn", dtnToIpn, "dtn://gw/7", "ipn:5.7"},
+		{"dtn to ipn of the node itself", dtnToIpn, "dtn://gw/", ""},
+		{"dtn to ipn of a non-numeric demux", dtnToIpn, "dtn://gw/inbox", ""},
+		{"dtn to ipn of another node", dtnToIpn, "dtn://other/7", ""},
+		{"dtn to dtn", dtnToDtn, "dtn://old/inbox/mail", "dtn://new/inbox/mail"},
+		{"dtn to dtn of the node itself", dtnToDtn, "dtn://old/", "dtn://new/"},
+		{"dtn none", dtnToDtn, bpv7.DtnEndpointDtnNone, ""},
+		{"exact", exact, "dtn://old/inbox", "ipn:7.3"},
+		{"exact of another demux", exact, "dtn://old/outbox", ""},
+		{"exact of the node", exact, "dtn://old/", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eid := bpv7.MustNewEndpointID(test.eid)
+			rewritten, ok := test.rule.rewrite(eid)
+			if test.rewritten == "" {
+				if ok || rewritten != eid {
+					t.Fatalf("Rule rewrote %v to %v, %t", eid, rewritten, ok)
+				}
+				return
+			}
+
+			if !ok || rewritten.String() != test.rewritten {
+				t.Fatalf("Expected %s, rule rewrote %v to %v, %t", test.rewritten, eid, rewritten, ok)
+			}
+		})
+	}
+}
+
+func TestEndpointService(t *testing.T) {
+	tests := []struct {
+		eid string
+		// service is the expected demux or service number, nil if there is none
+		service *string
+	}{
+		{"dtn://node/", stringPtr("")},
+		{"dtn://node/inbox", stringPtr("inbox")},
+		{"dtn://node/inbox/mail", stringPtr("inbox/mail")},
+		{"ipn:5.42", stringPtr("42")},
+		{bpv7.DtnEndpointDtnNone, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.eid, func(t *testing.T) {
+			service := endpointService(bpv7.MustNewEndpointID(test.eid))
+			if test.service == nil {
+				if service != nil {
+					t.Fatalf("Expected no service, got %q", *service)
+				}
+				return
+			}
+
+			if service == nil || *service != *test.service {
+				t.Fatalf("Expected service %q, got %v", *test.service, service)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,46 @@
+package processing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+var statusReportHopLimit uint8
+
+// SetStatusReportHopLimit limits status reports, e.g., delivery acknowledgements, to the given number of hops by a Hop
+// Count Block. Thus, reports for distant report-to endpoints are dropped instead of traversing the whole network.
+// Zero, the default, sends status reports without a hop limit.
+func SetStatusReportHopLimit(hops uint8) {
+	statusReportHopLimit = hops
+}
+
+// incrementHopCount increments the hop count of a bundle's optional Hop Count Block before it is forwarded, as
+// described in RFC 9171 section 4.4.3. It returns false if the hop limit is exceeded afterwards.
+func incrementHopCount(bundle *bpv7.Bundle) bool {
+	block, err := bundle.ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock)
+	if err != nil {
+		return true
+	}
+	return !block.Value.(*bpv7.HopCountBlock).Increment()
+}
+
+// dropHopLimitExceeded stops forwarding a bundle whose hop limit is exceeded, e.g., a scoped flood at its border. The
+// bundle is not deleted, but kept without any constraint to recognise its duplicates until it expires. A deletion
+// status report is sent if requested.
+func dropHopLimitExceeded(bundleDescriptor *store.BundleDescriptor, bundle *bpv7.Bundle) {
+	if err := bundleDescriptor.RemoveConstraint(store.ForwardPending); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error removing constraint from bundle")
+		return
+	}
+
+	log.WithField("bundle", bundleDescriptor.ID).Debug("Stopped forwarding bundle due to its exceeded hop limit")
+	reportDeletion(bundle, bpv7.HopLimitExceeded)
+	if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDeletion) {
+		sendStatusReport(bundle, bpv7.DeletedBundle, bpv7.HopLimitExceeded)
+	}
+}
@@ -0,0 +1,113 @@
+package processing
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// OffloadConfig configures the storage offload to a depot node. If the local store's pressure exceeds the Threshold,
+// bulk priority bundles are handed over to the Depot and deleted locally after a successful transmission. The depot
+// keeps and routes them on, instead of this node running out of storage.
+type OffloadConfig struct {
+	// Depot is the node ID of the peer taking over bundles.
+	Depot bpv7.EndpointID
+	// Threshold is the store's Pressure, between zero and one, above which bundles are offloaded.
+	Threshold float64
+}
+
+// CheckValid checks for a depot and a threshold within (0, 1].
+func (conf OffloadConfig) CheckValid() error {
+	if err := conf.Depot.CheckValid(); err != nil {
+		return err
+	}
+	if conf.Depot.SameNode(bpv7.DtnNone()) {
+		return fmt.Errorf("offload depot must not be dtn:none")
+	}
+	if conf.Threshold <= 0 || conf.Threshold > 1 {
+		return fmt.Errorf("offload threshold %f is not within (0, 1]", conf.Threshold)
+	}
+	return nil
+}
+
+var (
+	offloadMutex  sync.Mutex
+	offloadConfig *OffloadConfig
+)
+
+// SetOffload enables offloading bundles to a depot node. A zero OffloadConfig disables it again.
+func SetOffload(conf OffloadConfig) {
+	offloadMutex.Lock()
+	defer offloadMutex.Unlock()
+
+	if conf.Depot.EndpointType == nil {
+		offloadConfig = nil
+	} else {
+		offloadConfig = &conf
+	}
+}
+
+// getOffload returns the OffloadConfig, nil if offloading is disabled.
+func getOffload() *OffloadConfig {
+	offloadMutex.Lock()
+	defer offloadMutex.Unlock()
+
+	return offloadConfig
+}
+
+// selectOffloadDepot returns the depot's ConvergenceSender if the bundle should be offloaded to it. This is the case
+// for bulk priority bundles the depot does not already have, while the store's pressure exceeds the threshold.
+func selectOffloadDepot(bundleDescriptor *store.BundleDescriptor) (cla.ConvergenceSender, bool) {
+	conf := getOffload()
+	if conf == nil || sentToDepot(bundleDescriptor) {
+		return nil, false
+	}
+	if store.GetStoreSingleton().Occupancy().Pressure() <= conf.Threshold {
+		return nil, false
+	}
+	if bundle, err := bundleDescriptor.Load(); err != nil || bundle.Priority() != bpv7.PriorityBulk {
+		return nil, false
+	}
+
+	manager := cla.GetManagerSingleton()
+	for _, sender := range manager.GetSenders() {
+		if peer := sender.GetPeerEndpointID(); peer.SameNode(conf.Depot) && !manager.PeerDown(peer) {
+			return sender, true
+		}
+	}
+	return nil, false
+}
+
+// sentToDepot checks if the bundle was transmitted to the depot.
+func sentToDepot(bundleDescriptor *store.BundleDescriptor) bool {
+	conf := getOffload()
+	if conf == nil {
+		return false
+	}
+	for _, sent := range bundleDescriptor.GetAlreadySent() {
+		if sent.SameNode(conf.Depot) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteOffloaded deletes a bundle after the depot took it over.
+func deleteOffloaded(bundleDescriptor *store.BundleDescriptor) {
+	logger := log.WithField("bundle", bundleDescriptor.ID)
+	if conf := getOffload(); conf != nil {
+		logger = logger.WithField("depot", conf.Depot)
+	}
+
+	if err := store.GetStoreSingleton().DeleteBundle(bundleDescriptor); err != nil {
+		logger.WithError(err).Error("Error deleting bundle offloaded to depot")
+		return
+	}
+
+	logger.Info("Bundle was offloaded to depot")
+}
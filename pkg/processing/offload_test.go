@@ -0,0 +1,89 @@
+package processing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+	"github.com/dtn7/dtn7-go/pkg/testutil"
+)
+
+// priorityBlock adds a Priority Block of the given priority.
+func priorityBlock(priority bpv7.Priority) func(*bpv7.BundleBuilder) *bpv7.BundleBuilder {
+	return func(builder *bpv7.BundleBuilder) *bpv7.BundleBuilder {
+		return builder.PriorityBlock(priority)
+	}
+}
+
+func TestOffloadToDepot(t *testing.T) {
+	// the algorithm selects no peers, thus only offloaded bundles are forwarded
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm())
+
+	depot := testutil.NewFakeSender("fake://depot", bpv7.MustNewEndpointID("dtn://depot/"))
+	cla.GetManagerSingleton().Register(depot)
+	waitFor(t, "registered depot", func() bool { return len(cla.GetManagerSingleton().GetSenders()) == 1 })
+
+	processing.SetOffload(processing.OffloadConfig{Depot: bpv7.MustNewEndpointID("dtn://depot/"), Threshold: 0.5})
+	defer processing.SetOffload(processing.OffloadConfig{})
+
+	// below the threshold, bundles are kept
+	kept := receivedBundle(t, "dtn://src/kept", priorityBlock(bpv7.PriorityBulk))
+	processing.ReceiveBundle(&kept)
+	waitForwarded(t, kept.ID())
+	assertNotSent(t, depot, kept.ID())
+
+	// above the threshold, bulk bundles are handed over to the depot and deleted locally
+	store.GetStoreSingleton().SetCapacity(1)
+	bulk := receivedBundle(t, "dtn://src/bulk", priorityBlock(bpv7.PriorityBulk))
+	processing.ReceiveBundle(&bulk)
+	if !depot.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bulk bundle was not offloaded")
+	}
+	if sent := depot.Sent()[0]; sent.ID() != bulk.ID() {
+		t.Fatalf("Expected bundle %v to be offloaded, got %v", bulk.ID(), sent.ID())
+	}
+	waitFor(t, "deleted offloaded bundle", func() bool { return !store.GetStoreSingleton().KnownBundle(bulk.ID()) })
+
+	// bundles of a higher priority are kept
+	expedited := receivedBundle(t, "dtn://src/expedited", priorityBlock(bpv7.PriorityExpedited))
+	processing.ReceiveBundle(&expedited)
+	waitForwarded(t, expedited.ID())
+	assertNotSent(t, depot, expedited.ID())
+
+	// a failed transmission keeps the bundle for the next dispatch
+	depot.FailNext(cla.ErrQueueFull)
+	retried := receivedBundle(t, "dtn://src/retried", priorityBlock(bpv7.PriorityBulk))
+	processing.ReceiveBundle(&retried)
+	waitFor(t, "failed offload", func() bool { return depot.Attempts() == 2 })
+	if bd := waitForwarded(t, retried.ID()); !bd.Dispatch {
+		t.Fatal("Bundle of a failed offload is not dispatchable again")
+	}
+	if !store.GetStoreSingleton().KnownBundle(retried.ID()) {
+		t.Fatal("Bundle of a failed offload was deleted")
+	}
+}
+
+func TestOffloadConfigCheckValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		conf  processing.OffloadConfig
+		valid bool
+	}{
+		{"valid", processing.OffloadConfig{Depot: bpv7.MustNewEndpointID("dtn://depot/"), Threshold: 0.8}, true},
+		{"full store", processing.OffloadConfig{Depot: bpv7.MustNewEndpointID("dtn://depot/"), Threshold: 1}, true},
+		{"dtn none", processing.OffloadConfig{Depot: bpv7.DtnNone(), Threshold: 0.8}, false},
+		{"zero threshold", processing.OffloadConfig{Depot: bpv7.MustNewEndpointID("dtn://depot/"), Threshold: 0}, false},
+		{"threshold above one", processing.OffloadConfig{Depot: bpv7.MustNewEndpointID("dtn://depot/"), Threshold: 1.5}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.conf.CheckValid(); (err == nil) != test.valid {
+				t.Fatalf("Expected valid: %t, got %v", test.valid, err)
+			}
+		})
+	}
+}
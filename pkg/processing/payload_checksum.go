@@ -0,0 +1,92 @@
+package processing
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// PayloadChecksumPolicy describes how to handle a bundle for a local endpoint whose PayloadChecksumBlock mismatches.
+type PayloadChecksumPolicy int
+
+const (
+	// ChecksumMismatchDrop deletes a mismatching bundle without delivering it.
+	ChecksumMismatchDrop PayloadChecksumPolicy = iota
+	// ChecksumMismatchDeliver delivers a mismatching bundle nevertheless and only logs a warning.
+	ChecksumMismatchDeliver
+)
+
+// PayloadChecksumPolicyFromString parses a PayloadChecksumPolicy, either "drop" or "deliver".
+func PayloadChecksumPolicyFromString(s string) (PayloadChecksumPolicy, error) {
+	switch s {
+	case "drop":
+		return ChecksumMismatchDrop, nil
+	case "deliver":
+		return ChecksumMismatchDeliver, nil
+	default:
+		return 0, fmt.Errorf("unknown payload checksum policy %q", s)
+	}
+}
+
+func (policy PayloadChecksumPolicy) String() string {
+	switch policy {
+	case ChecksumMismatchDrop:
+		return "drop"
+	case ChecksumMismatchDeliver:
+		return "deliver"
+	default:
+		return "unknown"
+	}
+}
+
+var payloadChecksumPolicy = ChecksumMismatchDrop
+
+func SetPayloadChecksumPolicy(policy PayloadChecksumPolicy) {
+	payloadChecksumPolicy = policy
+}
+
+// isForLocalEndpoint checks if a bundle's destination is an endpoint registered by a local application agent.
+func isForLocalEndpoint(bundle *bpv7.Bundle) bool {
+	for _, endpoint := range application_agent.GetManagerSingleton().GetEndpoints() {
+		if endpoint == bundle.PrimaryBlock.Destination {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPayloadChecksum verifies a bundle's PayloadChecksumBlock if the bundle is addressed to a local endpoint.
+// Bundles passing through are not verified, as the checksum is end-to-end. Fragments cannot be verified and are
+// always kept.
+//
+// If the checksum mismatches and the PayloadChecksumPolicy is ChecksumMismatchDrop, false is returned and the bundle
+// must not be processed any further.
+func checkPayloadChecksum(bundle *bpv7.Bundle) (keep bool) {
+	block, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePayloadChecksumBlock)
+	if err != nil || bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) {
+		return true
+	}
+
+	if !isForLocalEndpoint(bundle) || block.Value.(*bpv7.PayloadChecksumBlock).Verify(*bundle) {
+		return true
+	}
+
+	logger := log.WithFields(log.Fields{
+		"bundle": bundle.ID(),
+		"policy": payloadChecksumPolicy,
+	})
+
+	if payloadChecksumPolicy == ChecksumMismatchDeliver {
+		logger.Warn("Delivering bundle despite a payload checksum mismatch")
+		return true
+	}
+
+	logger.Warn("Deleting bundle due to a payload checksum mismatch")
+	if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDeletion) {
+		sendStatusReport(bundle, bpv7.DeletedBundle, bpv7.BlockUnintelligible)
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package processing
+
+import (
+	"fmt"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// PreviousNodePolicy describes how the Previous Node Block of a forwarded bundle reveals this node to the next hop.
+type PreviousNodePolicy int
+
+const (
+	// PreviousNodeReveal names this node in the Previous Node Block, as recommended by RFC 9171 section 4.4.1.
+	PreviousNodeReveal PreviousNodePolicy = iota
+	// PreviousNodeAnonymous names the null endpoint dtn:none in the Previous Node Block.
+	PreviousNodeAnonymous
+	// PreviousNodeOmit forwards bundles without a Previous Node Block.
+	PreviousNodeOmit
+)
+
+// PreviousNodePolicyFromString parses a PreviousNodePolicy, either "reveal", "anonymous" or "omit".
+func PreviousNodePolicyFromString(s string) (PreviousNodePolicy, error) {
+	switch s {
+	case "reveal":
+		return PreviousNodeReveal, nil
+	case "anonymous":
+		return PreviousNodeAnonymous, nil
+	case "omit":
+		return PreviousNodeOmit, nil
+	default:
+		return 0, fmt.Errorf("unknown previous node policy %q", s)
+	}
+}
+
+func (policy PreviousNodePolicy) String() string {
+	switch policy {
+	case PreviousNodeReveal:
+		return "reveal"
+	case PreviousNodeAnonymous:
+		return "anonymous"
+	case PreviousNodeOmit:
+		return "omit"
+	default:
+		return "unknown"
+	}
+}
+
+// PreviousNodeRule applies a PreviousNodePolicy to the bundles for some destinations.
+type PreviousNodeRule struct {
+	Policy PreviousNodePolicy
+	// Destinations match the bundles' destinations, e.g., "dtn://partner-*/**". Empty matches all bundles.
+	Destinations []bpv7.EndpointPattern
+}
+
+// matches checks if the rule applies to a bundle's destination.
+func (rule PreviousNodeRule) matches(destination bpv7.EndpointID) bool {
+	return len(rule.Destinations) == 0 || bpv7.MatchAnyPattern(rule.Destinations, destination)
+}
+
+var previousNodeRules []PreviousNodeRule
+
+// SetPreviousNodeRules hides this node's identity from downstream nodes, e.g., in deployments not revealing their
+// intermediate nodes. The first rule matching a forwarded bundle's destination applies, all other bundles reveal this
+// node. Without its Previous Node Block, the next hop neither knows where the bundle came from nor that this node
+// already has it. Thus, it might be forwarded back to this node, which drops it as a known bundle.
+func SetPreviousNodeRules(rules []PreviousNodeRule) {
+	previousNodeRules = rules
+}
+
+// previousNodePolicyFor returns the PreviousNodePolicy of the first rule matching a bundle's destination.
+func previousNodePolicyFor(destination bpv7.EndpointID) PreviousNodePolicy {
+	for _, rule := range previousNodeRules {
+		if rule.matches(destination) {
+			return rule.Policy
+		}
+	}
+	return PreviousNodeReveal
+}
+
+// replacePreviousNodeBlock removes a bundle's Previous Node Block and adds this node's one, according to the
+// PreviousNodePolicy for its destination.
+func replacePreviousNodeBlock(bundle *bpv7.Bundle) error {
+	if prevNodeBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
+		bundle.RemoveExtensionBlockByBlockNumber(prevNodeBlock.BlockNumber)
+	}
+
+	policy := previousNodePolicyFor(bundle.PrimaryBlock.Destination)
+	if policy == PreviousNodeOmit {
+		return nil
+	}
+	previousNode := ownNodeID
+	if policy == PreviousNodeAnonymous {
+		previousNode = bpv7.DtnNone()
+	}
+	return bundle.AddExtensionBlock(bpv7.NewCanonicalBlock(0, 0, bpv7.NewPreviousNodeBlock(previousNode)))
+}
@@ -0,0 +1,71 @@
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestReplacePreviousNodeBlock(t *testing.T) {
+	SetOwnNodeID(bpv7.MustNewEndpointID("dtn://relay/"))
+	defer SetOwnNodeID(bpv7.EndpointID{})
+
+	SetPreviousNodeRules([]PreviousNodeRule{
+		{Policy: PreviousNodeOmit, Destinations: []bpv7.EndpointPattern{
+			bpv7.MustNewEndpointPattern("dtn://partner-*/**"),
+			bpv7.MustNewEndpointPattern("ipn:977.*"),
+		}},
+		{Policy: PreviousNodeReveal, Destinations: []bpv7.EndpointPattern{
+			bpv7.MustNewEndpointPattern("dtn://trusted/**"),
+		}},
+		{Policy: PreviousNodeAnonymous},
+	})
+	defer SetPreviousNodeRules(nil)
+
+	tests := []struct {
+		destination string
+		// previousNode is the expected Previous Node Block's node, empty if the block is omitted
+		previousNode string
+	}{
+		{"dtn://partner-1/inbox", ""},
+		{"ipn:977.3", ""},
+		{"dtn://trusted/inbox", "dtn://relay/"},
+		{"dtn://partner/inbox", bpv7.DtnEndpointDtnNone},
+		{"ipn:978.3", bpv7.DtnEndpointDtnNone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.destination, func(t *testing.T) {
+			bundle, err := bpv7.Builder().
+				Source("dtn://src/").
+				Destination(test.destination).
+				CreationTimestampNow().
+				Lifetime(time.Hour).
+				PreviousNodeBlock("dtn://upstream/").
+				PayloadBlock([]byte("hello")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := replacePreviousNodeBlock(&bundle); err != nil {
+				t.Fatal(err)
+			}
+
+			block, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock)
+			if test.previousNode == "" {
+				if err == nil {
+					t.Fatalf("Previous Node Block was not omitted: %v", block.Value)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if node := block.Value.(*bpv7.PreviousNodeBlock).Endpoint(); node.String() != test.previousNode {
+				t.Fatalf("Expected previous node %s, got %v", test.previousNode, node)
+			}
+		})
+	}
+}
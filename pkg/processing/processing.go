@@ -1,12 +1,15 @@
 package processing
 
 import (
+	"errors"
 	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/fault_injection"
 	"github.com/dtn7/dtn7-go/pkg/routing"
 	"github.com/dtn7/dtn7-go/pkg/store"
 )
@@ -21,9 +24,22 @@ func SetOwnNodeID(nid bpv7.EndpointID) {
 func forwardingAsync(bundleDescriptor *store.BundleDescriptor) {
 	log.WithField("bundle", bundleDescriptor.ID.String()).Debug("Processing bundle")
 
+	// Step 0: quarantine a bundle whose processing failed repeatedly, counting a panicking attempt
+	if !beginAttempt(bundleDescriptor) {
+		return
+	}
+	defer endAttempt(bundleDescriptor, bundleContraindicated)
+
+	// Step 0.1: retry a deferred delivery to a local application agent, dropping a bundle which expired meanwhile
+	if !redeliver(bundleDescriptor) {
+		return
+	}
+
 	// Step 1: add "Forward Pending, remove "Dispatch Pending"
 	err := bundleDescriptor.AddConstraint(store.ForwardPending)
-	if err != nil {
+	if deletedMeanwhile(bundleDescriptor, err) {
+		return
+	} else if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundleDescriptor.ID,
 			"error":  err,
@@ -31,7 +47,9 @@ func forwardingAsync(bundleDescriptor *store.BundleDescriptor) {
 		return
 	}
 	err = bundleDescriptor.RemoveConstraint(store.DispatchPending)
-	if err != nil {
+	if deletedMeanwhile(bundleDescriptor, err) {
+		return
+	} else if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundleDescriptor.ID,
 			"error":  err,
@@ -40,8 +58,26 @@ func forwardingAsync(bundleDescriptor *store.BundleDescriptor) {
 	}
 
 	// Step 2: determine if contraindicated - whatever that means
-	// Step 2.1: Call routing algorithm(?)
-	forwardToPeers := routing.GetAlgorithmSingleton().SelectPeersForForwarding(bundleDescriptor)
+	// Step 2.1: follow an optional source route, call routing algorithm(?) otherwise
+	// Step 2.1.1: scoped floods are sent to all peers, limited by their hop count
+	forwardToPeers, sourceRouted := routing.SelectSourceRoutePeers(bundleDescriptor, isOwnNode)
+	flooded := false
+	if !sourceRouted {
+		forwardToPeers, flooded = routing.SelectScopedFloodPeers(bundleDescriptor)
+	}
+	if !sourceRouted && !flooded {
+		forwardToPeers = routing.GetAlgorithmSingleton().SelectPeersForForwarding(bundleDescriptor)
+		// Step 2.1.2: prefer low-latency peers for interactive bundles and fast peers otherwise
+		forwardToPeers = routing.ApplyPeerLatency(bundleDescriptor, forwardToPeers)
+		// Step 2.1.3: prefer cheap peers and only use expensive ones if necessary
+		forwardToPeers = routing.ApplyPeerCosts(bundleDescriptor, forwardToPeers)
+	}
+	// Step 2.2: hand bulk bundles over to a depot under storage pressure, unless their path is pinned
+	depot, offload := selectOffloadDepot(bundleDescriptor)
+	offload = offload && !sourceRouted && !flooded
+	if offload && !containsSender(forwardToPeers, depot) {
+		forwardToPeers = append(forwardToPeers, depot)
+	}
 
 	// Step 3: if contraindicated, call `contraindicateBundle`, and return
 	if len(forwardToPeers) == 0 {
@@ -51,49 +87,166 @@ func forwardingAsync(bundleDescriptor *store.BundleDescriptor) {
 
 	// Step 4:
 	bundle, err := bundleDescriptor.Load()
-	if err != nil {
+	if deletedMeanwhile(bundleDescriptor, err) {
+		return
+	} else if errors.Is(err, bpv7.ErrInvalidBlock) {
+		// An invalid stored bundle, e.g., altered on disk, would fail again on each dispatch.
+		deleteInvalid(bundleDescriptor, err)
+		return
+	} else if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundleDescriptor.ID,
 			"error":  err,
 		}).Error("Error loading bundle from disk")
 		return
 	}
-	// Step 4.1: remove previous node block
-	if prevNodeBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
-		bundle.RemoveExtensionBlockByBlockNumber(prevNodeBlock.BlockNumber)
+	// Step 4.0: drop a bundle which expired while waiting for its dispatch, including its dwell time from Step 4.3
+	bundle = withDwellTime(bundle, bundleDescriptor)
+	if isExpired(bundle, bundleDescriptor) {
+		deleteExpired(bundleDescriptor, &bundle)
+		return
 	}
-	// Step 4.2: add new previous node block
-	prevNodeBlock := bpv7.NewCanonicalBlock(0, 0, bpv7.NewPreviousNodeBlock(ownNodeID))
-	err = bundle.AddExtensionBlock(prevNodeBlock)
-	if err != nil {
+	// Step 4.0.1: count this hop, stopping at an exceeded hop limit
+	if !incrementHopCount(&bundle) {
+		dropHopLimitExceeded(bundleDescriptor, &bundle)
+		return
+	}
+	// Step 4.1 and 4.2: replace the previous node block, replaced by an alias matching each peer's scheme in Step 4.4,
+	// unless this node is hidden from the bundle's next hop
+	if err := replacePreviousNodeBlock(&bundle); err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundleDescriptor.ID,
 			"error":  err,
 		}).Error("Error adding PreviousNodeBlock to bundle")
 	}
-	// TODO: Step 4.3: update bundle age block
-	// Step 4.4: call CLAs for transmission
+	// Step 4.2.1: record this node in an optional travel history block
+	if travelHistoryBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypeTravelHistoryBlock); err == nil {
+		travelHistoryBlock.Value.(*bpv7.TravelHistoryBlock).Append(ownNodeID, bpv7.DtnTimeNow())
+	}
+	// Step 4.2.2: mark waypoints of an optional source route block as visited, as far as they are this node
+	if sourceRouteBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypeSourceRouteBlock); err == nil {
+		sourceRouteBlock.Value.(*bpv7.SourceRouteBlock).Visit(isOwnNode)
+	}
+	// Step 4.3: the bundle age block was already updated by the dwell time in Step 4.0
+	// Step 4.4: call CLAs for transmission, or only enqueue the transmissions if the CLA manager queues them per sender
+	if cla.GetManagerSingleton().Queueing() {
+		if quarantineAttempts > 0 {
+			resetAttempts(bundleDescriptor)
+		}
+		enqueueForwarding(bundleDescriptor, bundle, forwardToPeers, offload)
+		return
+	}
 	var mutex sync.Mutex
 	var wg sync.WaitGroup
 	wg.Add(len(forwardToPeers))
 	for _, peer := range forwardToPeers {
-		go forwardBundleToPeer(&mutex, bundleDescriptor, bundle, peer, &wg)
+		peerBundle := withPreviousNodeFor(bundle, peer.GetPeerEndpointID())
+		if workers != nil {
+			forwardBundleToPeer(&mutex, bundleDescriptor, peerBundle, peer, &wg)
+		} else {
+			go supervise(func() { forwardBundleToPeer(&mutex, bundleDescriptor, peerBundle, peer, &wg) })
+		}
 	}
 	wg.Wait()
 
+	finishForwarding(bundleDescriptor, bundle, offload)
+}
+
+// enqueueForwarding hands the bundle over to the CLA manager's send queues for all peers, without waiting for the
+// transmissions. The forwarding is finished after the last transmission's result.
+func enqueueForwarding(bundleDescriptor *store.BundleDescriptor, bundle bpv7.Bundle, forwardToPeers []cla.ConvergenceSender, offload bool) {
+	var mutex sync.Mutex
+	pending := int32(len(forwardToPeers))
+	transmitted := func() {
+		if atomic.AddInt32(&pending, -1) == 0 {
+			finishForwarding(bundleDescriptor, bundle, offload)
+		}
+	}
+
+	for _, peer := range forwardToPeers {
+		peerBundle := withPreviousNodeFor(bundle, peer.GetPeerEndpointID())
+		if faultDroppedSend(&mutex, bundleDescriptor, peerBundle, peer) {
+			transmitted()
+			continue
+		}
+
+		err := cla.GetManagerSingleton().Enqueue(peer, peerBundle, func(err error) {
+			handleSendResult(&mutex, bundleDescriptor, peerBundle, peer, err)
+			transmitted()
+		})
+		if err != nil {
+			handleSendResult(&mutex, bundleDescriptor, peerBundle, peer, err)
+			transmitted()
+		}
+	}
+}
+
+// finishForwarding performs the forwarding's steps after all transmissions of Step 4.4.
+func finishForwarding(bundleDescriptor *store.BundleDescriptor, bundle bpv7.Bundle, offload bool) {
+	// Step 5: drop a bundle which expired while its transmissions were queued, instead of dispatching it again
+	if isExpired(bundle, bundleDescriptor) {
+		deleteExpired(bundleDescriptor, &bundle)
+		return
+	}
+
 	// Step 6: remove "Forward Pending"
-	err = bundleDescriptor.RemoveConstraint(store.ForwardPending)
-	if err != nil {
+	err := bundleDescriptor.RemoveConstraint(store.ForwardPending)
+	if deletedMeanwhile(bundleDescriptor, err) {
+		return
+	} else if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundleDescriptor.ID,
 			"error":  err,
 		}).Error("Error removing constraint from bundle")
 		return
 	}
+
+	// Step 7: delete a bundle which was offloaded to the depot
+	if offload && sentToDepot(bundleDescriptor) {
+		deleteOffloaded(bundleDescriptor)
+	}
+}
+
+// deletedMeanwhile checks if a store operation failed because the bundle was deleted meanwhile, e.g., after its
+// delivery by another goroutine. As there is nothing left to do for such a bundle, it is only logged for debugging.
+func deletedMeanwhile(bundleDescriptor *store.BundleDescriptor, err error) bool {
+	if !errors.Is(err, store.ErrNotFound) {
+		return false
+	}
+
+	log.WithFields(log.Fields{
+		"bundle": bundleDescriptor.ID,
+		"error":  err,
+	}).Debug("Bundle was deleted while being forwarded")
+	return true
+}
+
+// deleteInvalid deletes a stored bundle which cannot be loaded as it became invalid.
+func deleteInvalid(bundleDescriptor *store.BundleDescriptor, invalidErr error) {
+	log.WithFields(log.Fields{
+		"bundle": bundleDescriptor.ID,
+		"error":  invalidErr,
+	}).Warn("Deleting invalid stored bundle")
+
+	if err := store.GetStoreSingleton().DeleteBundle(bundleDescriptor); err != nil {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error deleting invalid bundle")
+	}
+}
+
+func containsSender(senders []cla.ConvergenceSender, sender cla.ConvergenceSender) bool {
+	for _, s := range senders {
+		if s == sender {
+			return true
+		}
+	}
+	return false
 }
 
 func BundleForwarding(bundleDescriptor *store.BundleDescriptor) {
-	go forwardingAsync(bundleDescriptor)
+	spawn(func() { forwardingAsync(bundleDescriptor) })
 }
 
 func bundleContraindicated(bundleDescriptor *store.BundleDescriptor) {
@@ -108,12 +261,55 @@ func bundleContraindicated(bundleDescriptor *store.BundleDescriptor) {
 }
 
 func forwardBundleToPeer(mutex *sync.Mutex, bundleDescriptor *store.BundleDescriptor, bundle bpv7.Bundle, peer cla.ConvergenceSender, wg *sync.WaitGroup) {
+	// Even a panicking transmission must not block the forwarding waiting for it
+	defer wg.Done()
+
+	if !faultDroppedSend(mutex, bundleDescriptor, bundle, peer) {
+		handleSendResult(mutex, bundleDescriptor, bundle, peer, cla.GetManagerSingleton().Send(peer, bundle))
+	}
+}
+
+// faultDroppedSend applies the fault injection before a transmission. A dropped bundle counts as sent.
+func faultDroppedSend(mutex *sync.Mutex, bundleDescriptor *store.BundleDescriptor, bundle bpv7.Bundle, peer cla.ConvergenceSender) bool {
 	log.WithFields(log.Fields{
 		"bundle": bundle.ID(),
 		"cla":    peer,
 	}).Info("Sending bundle to a CLA (ConvergenceSender)")
 
-	if err := peer.Send(bundle); err != nil {
+	fault_injection.DelaySend()
+
+	if !fault_injection.DropSend() {
+		return false
+	}
+
+	log.WithFields(log.Fields{
+		"bundle": bundle.ID(),
+		"cla":    peer,
+	}).Warn("Fault injection dropped bundle")
+	markSent(mutex, bundleDescriptor, peer)
+	return true
+}
+
+// handleSendResult marks the peer as already sent after a successful transmission.
+func handleSendResult(mutex *sync.Mutex, bundleDescriptor *store.BundleDescriptor, bundle bpv7.Bundle, peer cla.ConvergenceSender, err error) {
+	if errors.Is(err, cla.ErrBundleExpired) {
+		log.WithFields(log.Fields{
+			"bundle": bundle.ID(),
+			"cla":    peer,
+		}).Info("Cancelled transmission of expired bundle")
+	} else if errors.Is(err, cla.ErrPeerUnreachable) {
+		// The peer is not marked as already sent, thus the bundle is sent again on a later dispatch.
+		log.WithFields(log.Fields{
+			"bundle": bundle.ID(),
+			"cla":    peer,
+			"error":  err,
+		}).Info("Peer was unreachable, keeping bundle for a retry")
+	} else if errors.Is(err, cla.ErrQueueFull) {
+		log.WithFields(log.Fields{
+			"bundle": bundle.ID(),
+			"cla":    peer,
+		}).Info("Send queue was full, keeping bundle for a retry")
+	} else if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundle.ID(),
 			"cla":    peer,
@@ -124,29 +320,39 @@ func forwardBundleToPeer(mutex *sync.Mutex, bundleDescriptor *store.BundleDescri
 			"bundle": bundle.ID(),
 			"cla":    peer,
 		}).Debug("Sending bundle succeeded")
-		mutex.Lock()
-		bundleDescriptor.AddAlreadySent(peer.GetPeerEndpointID())
-		mutex.Unlock()
+		markSent(mutex, bundleDescriptor, peer)
 	}
-	wg.Done()
+}
+
+// markSent marks the peer as already sent. The mutex is released even on a panic, which would otherwise block the
+// transmissions to all other peers after being recovered.
+func markSent(mutex *sync.Mutex, bundleDescriptor *store.BundleDescriptor, peer cla.ConvergenceSender) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	bundleDescriptor.AddAlreadySent(peer.GetPeerEndpointID())
 }
 
 func DispatchPending() {
 	log.Debug("Dispatching bundles")
 
-	bndls, err := store.GetStoreSingleton().GetDispatchable()
-	if err != nil {
-		log.WithError(err).Error("Error dispatching pending bundles")
-		return
-	}
-	log.WithField("bundles", bndls).Debug("Bundles to dispatch")
+	// Forward bundles while the store is still querying the remaining ones, which matters for large backlogs.
+	stream := store.GetStoreSingleton().StreamDispatchable(store.DispatchQuery{})
+	defer stream.Close()
 
-	for _, bndl := range bndls {
+	dispatched := 0
+	for bndl := range stream.Bundles() {
 		BundleForwarding(bndl)
+		dispatched++
+	}
+	if err := stream.Err(); err != nil {
+		log.WithError(err).Error("Error dispatching pending bundles")
 	}
+	log.WithField("bundles", dispatched).Debug("Dispatched pending bundles")
 }
 
 func NewPeer(peerID bpv7.EndpointID) {
+	forgetSentOnReconnect(peerID)
 	routing.GetAlgorithmSingleton().NotifyPeerAppeared(peerID)
-	DispatchPending()
+	triggerDispatchOnNewPeer()
 }
@@ -0,0 +1,510 @@
+package processing_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+	"github.com/dtn7/dtn7-go/pkg/testutil"
+)
+
+// experimentalBlockType is a block type code unknown to the node, out of the range for private and experimental use.
+const experimentalBlockType = 192
+
+// receivedBundle builds a bundle as received from dtn://upstream/, extended by the given blocks. Bundles created
+// within the same millisecond are distinguished by their sources.
+func receivedBundle(t *testing.T, source string, blocks ...func(*bpv7.BundleBuilder) *bpv7.BundleBuilder) bpv7.Bundle {
+	t.Helper()
+
+	builder := bpv7.Builder().
+		Source(source).
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PreviousNodeBlock("dtn://upstream/").
+		PayloadBlock([]byte("hello world"))
+	for _, block := range blocks {
+		builder = block(builder)
+	}
+
+	bndl, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+// unknownBlock adds a block unknown to the node with the given flags.
+func unknownBlock(flags bpv7.BlockControlFlags) func(*bpv7.BundleBuilder) *bpv7.BundleBuilder {
+	return func(builder *bpv7.BundleBuilder) *bpv7.BundleBuilder {
+		return builder.Canonical(bpv7.NewGenericExtensionBlock([]byte("unknown"), experimentalBlockType), flags)
+	}
+}
+
+// hopCountBlock adds a Hop Count Block of the given limit, already counting the given hops.
+func hopCountBlock(limit, count uint8) func(*bpv7.BundleBuilder) *bpv7.BundleBuilder {
+	return func(builder *bpv7.BundleBuilder) *bpv7.BundleBuilder {
+		return builder.Canonical(&bpv7.HopCountBlock{Limit: limit, Count: count}, bpv7.ReplicateBlock)
+	}
+}
+
+// waitForwarded waits until the bundle's forwarding finished, i.e., it has no constraint left.
+func waitForwarded(t *testing.T, id bpv7.BundleID) *store.BundleDescriptor {
+	t.Helper()
+
+	var bd *store.BundleDescriptor
+	waitFor(t, "finished forwarding", func() bool {
+		var err error
+		bd, err = store.GetStoreSingleton().LoadBundleDescriptor(id)
+		return err == nil && len(bd.RetentionConstraints) == 0
+	})
+	return bd
+}
+
+// assertNotSent checks that the bundle was not sent to the peer after a short grace period.
+func assertNotSent(t *testing.T, peer *testutil.FakeSender, id bpv7.BundleID) {
+	t.Helper()
+
+	time.Sleep(50 * time.Millisecond)
+	for _, bndl := range peer.Sent() {
+		if bndl.ID() == id {
+			t.Fatalf("Bundle %v was forwarded", id)
+		}
+	}
+}
+
+func TestReceptionForwarding(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	algorithm := testutil.NewFakeAlgorithm(peer)
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), algorithm)
+
+	received := receivedBundle(t, "dtn://src/", hopCountBlock(5, 1))
+	processing.ReceiveBundle(&received)
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bundle was not forwarded")
+	}
+
+	forwarded := peer.Sent()[0]
+	if forwarded.ID() != received.ID() {
+		t.Fatalf("Expected bundle %v, got %v", received.ID(), forwarded.ID())
+	}
+	if block, err := forwarded.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err != nil {
+		t.Fatal(err)
+	} else if node := block.Value.(*bpv7.PreviousNodeBlock).Endpoint(); node != bpv7.MustNewEndpointID("dtn://node/") {
+		t.Fatalf("Expected this node as previous node, got %v", node)
+	}
+	if block, err := forwarded.ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock); err != nil {
+		t.Fatal(err)
+	} else if count := block.Value.(*bpv7.HopCountBlock).Count; count != 2 {
+		t.Fatalf("Expected a hop count of 2, got %d", count)
+	}
+
+	if bundles := algorithm.NewBundles(); len(bundles) != 1 || bundles[0] != received.ID() {
+		t.Fatalf("Algorithm was not notified of the bundle, got %v", bundles)
+	}
+
+	bd := waitForwarded(t, received.ID())
+	if !bd.Dispatch {
+		t.Fatal("Forwarded bundle is not dispatchable again")
+	}
+	sent := false
+	for _, eid := range bd.GetAlreadySent() {
+		sent = sent || eid == peer.GetPeerEndpointID()
+	}
+	if !sent {
+		t.Fatalf("Peer is not marked as already sent, got %v", bd.GetAlreadySent())
+	}
+}
+
+func TestReceptionUnknownBlocks(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm(peer))
+
+	deleted := receivedBundle(t, "dtn://src/deleted", unknownBlock(bpv7.DeleteBundle))
+	if _, err := processing.ImportBundle(&deleted); err == nil {
+		t.Fatal("Bundle with an unknown block demanding its deletion was accepted")
+	}
+	processing.ReceiveBundle(&deleted)
+
+	tests := []struct {
+		name  string
+		flags bpv7.BlockControlFlags
+		kept  bool
+	}{
+		{"removed", bpv7.RemoveBlock, false},
+		{"passed through", 0, true},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bndl := receivedBundle(t, "dtn://src/"+test.name, unknownBlock(test.flags))
+			processing.ReceiveBundle(&bndl)
+			if !peer.WaitSent(i+1, 5*time.Second) {
+				t.Fatal("Bundle was not forwarded")
+			}
+
+			forwarded := peer.Sent()[i]
+			if forwarded.ID() != bndl.ID() {
+				t.Fatalf("Expected bundle %v, got %v", bndl.ID(), forwarded.ID())
+			}
+			if kept := forwarded.HasExtensionBlock(experimentalBlockType); kept != test.kept {
+				t.Fatalf("Expected unknown block kept %t, got %t", test.kept, kept)
+			}
+		})
+	}
+
+	if store.GetStoreSingleton().KnownBundle(deleted.ID()) {
+		t.Fatal("Bundle demanding its deletion was stored")
+	}
+	assertNotSent(t, peer, deleted.ID())
+}
+
+func TestReceptionHopLimitExceeded(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm(peer))
+
+	bndl := receivedBundle(t, "dtn://src/", hopCountBlock(1, 1))
+	processing.ReceiveBundle(&bndl)
+
+	// the bundle is kept to recognise its duplicates, but not forwarded anymore
+	waitForwarded(t, bndl.ID())
+	assertNotSent(t, peer, bndl.ID())
+}
+
+func TestReceptionReplay(t *testing.T) {
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm())
+
+	// importBundleAgain imports the bundle again after deleting it, as if it was delivered meanwhile
+	importBundleAgain := func(bndl bpv7.Bundle) error {
+		bd, err := processing.ImportBundle(&bndl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.GetStoreSingleton().DeleteBundle(bd); err != nil {
+			t.Fatal(err)
+		}
+		_, err = processing.ImportBundle(&bndl)
+		return err
+	}
+
+	if err := importBundleAgain(receivedBundle(t, "dtn://src/unprotected")); err != nil {
+		t.Fatalf("Bundle was rejected without a replay window: %v", err)
+	}
+
+	processing.SetReplayWindow(time.Hour)
+	defer processing.SetReplayWindow(0)
+
+	bndl := receivedBundle(t, "dtn://src/replayed")
+	if err := importBundleAgain(bndl); err == nil {
+		t.Fatal("Replayed bundle was accepted")
+	}
+	if store.GetStoreSingleton().KnownBundle(bndl.ID()) {
+		t.Fatal("Replayed bundle was stored")
+	}
+}
+
+func TestReceptionCongestion(t *testing.T) {
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm())
+
+	first := receivedBundle(t, "dtn://src/first")
+	second := receivedBundle(t, "dtn://src/second")
+	size := int64(cla.BundleSize(first))
+
+	processing.SetCongestionPolicy(processing.DropNewest{})
+	defer processing.SetCongestionPolicy(nil)
+	store.GetStoreSingleton().SetCapacity(size + size/2)
+
+	if _, err := processing.ImportBundle(&first); err != nil {
+		t.Fatalf("Bundle fitting into the store was refused: %v", err)
+	}
+	if _, err := processing.ImportBundle(&second); err == nil {
+		t.Fatal("Bundle exceeding the store's capacity was accepted")
+	}
+	if err := processing.AdmitBundle(&second); err == nil {
+		t.Fatal("Bundle exceeding the store's capacity was admitted")
+	}
+	if store.GetStoreSingleton().KnownBundle(second.ID()) {
+		t.Fatal("Refused bundle was stored")
+	}
+
+	// the stored bundle is admitted again, e.g., a retransmission
+	if err := processing.AdmitBundle(&first); err != nil {
+		t.Fatalf("Stored bundle was refused: %v", err)
+	}
+}
+
+// failureAlgorithm is a FakeAlgorithm recording the nodes of failure reports.
+type failureAlgorithm struct {
+	*testutil.FakeAlgorithm
+
+	mutex sync.Mutex
+	nodes []bpv7.EndpointID
+}
+
+func (algorithm *failureAlgorithm) NotifyFailureReport(_ *store.BundleDescriptor, reportingNode bpv7.EndpointID, _ *bpv7.StatusReport) {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	algorithm.nodes = append(algorithm.nodes, reportingNode)
+}
+
+func (algorithm *failureAlgorithm) reportingNodes() []bpv7.EndpointID {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	return append([]bpv7.EndpointID(nil), algorithm.nodes...)
+}
+
+func TestFailureReportRerouting(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	algorithm := &failureAlgorithm{FakeAlgorithm: testutil.NewFakeAlgorithm(peer)}
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), algorithm)
+
+	bndl := receivedBundle(t, "dtn://src/")
+	processing.ReceiveBundle(&bndl)
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bundle was not forwarded")
+	}
+	waitForwarded(t, bndl.ID())
+
+	report, err := bpv7.Builder().
+		Source("dtn://peer/").
+		Destination("dtn://node/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		StatusReport(bndl, bpv7.DeletedBundle, bpv7.NoRouteToDestination).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	processing.ReceiveBundle(&report)
+
+	waitFor(t, "failure report", func() bool { return len(algorithm.reportingNodes()) == 1 })
+	if node := algorithm.reportingNodes()[0]; node != peer.GetPeerEndpointID() {
+		t.Fatalf("Expected failure reported by %v, got %v", peer.GetPeerEndpointID(), node)
+	}
+
+	// the FakeAlgorithm selects the same peer again, standing in for a rerouting algorithm
+	if !peer.WaitSent(2, 5*time.Second) {
+		t.Fatal("Bundle was not forwarded again after the failure report")
+	}
+	if rerouted := peer.Sent()[1]; rerouted.ID() != bndl.ID() {
+		t.Fatalf("Expected bundle %v to be forwarded again, got %v", bndl.ID(), rerouted.ID())
+	}
+}
+
+func TestScopedFloodForwarding(t *testing.T) {
+	// the algorithm selects no peers, thus only a scoped flood is forwarded
+	algorithm := testutil.NewFakeAlgorithm()
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), algorithm)
+
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	cla.GetManagerSingleton().Register(peer)
+	waitFor(t, "registered peer", func() bool { return len(cla.GetManagerSingleton().GetSenders()) == 1 })
+
+	flood, err := routing.NewScopedFlood(bpv7.MustNewEndpointID("dtn://src/"), "test", 2, time.Hour, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	processing.ReceiveBundle(&flood)
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Scoped flood was not forwarded")
+	}
+
+	unicast := receivedBundle(t, "dtn://src/unicast")
+	processing.ReceiveBundle(&unicast)
+	waitForwarded(t, unicast.ID())
+	assertNotSent(t, peer, unicast.ID())
+
+	// the flood's border is one hop away
+	border, err := routing.NewScopedFlood(bpv7.MustNewEndpointID("dtn://src/"), "test", 1, time.Hour, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := border.ExtensionBlock(bpv7.ExtBlockTypeHopCountBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Value.(*bpv7.HopCountBlock).Increment()
+	processing.ReceiveBundle(&border)
+	waitFor(t, "dropped scoped flood", func() bool { return store.GetStoreSingleton().KnownBundle(border.ID()) })
+	assertNotSent(t, peer, border.ID())
+}
+
+func TestDispatchSchedulerRetry(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm(peer))
+
+	peer.FailNext(errors.New("transmission failed"))
+	bndl := receivedBundle(t, "dtn://src/")
+	processing.ReceiveBundle(&bndl)
+	waitFor(t, "failed transmission", func() bool { return peer.Attempts() == 1 })
+	if bd := waitForwarded(t, bndl.ID()); !bd.Dispatch {
+		t.Fatal("Bundle of a failed transmission is not dispatchable again")
+	}
+
+	if err := processing.InitialiseDispatchScheduler(processing.DispatchSchedulerConfig{Interval: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = processing.GetDispatchSchedulerSingleton().Shutdown() }()
+
+	processing.GetDispatchSchedulerSingleton().Trigger()
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bundle was not sent again by the triggered dispatch sweep")
+	}
+	if sent := peer.Sent()[0]; sent.ID() != bndl.ID() {
+		t.Fatalf("Expected bundle %v, got %v", bndl.ID(), sent.ID())
+	}
+}
+
+// panickingAlgorithm is a FakeAlgorithm panicking while selecting peers until it is healed, standing in for a "bundle
+// of death".
+type panickingAlgorithm struct {
+	*testutil.FakeAlgorithm
+
+	healed atomic.Bool
+}
+
+func (algorithm *panickingAlgorithm) SelectPeersForForwarding(bd *store.BundleDescriptor) []cla.ConvergenceSender {
+	if algorithm.healed.Load() {
+		return algorithm.FakeAlgorithm.SelectPeersForForwarding(bd)
+	}
+	panic(fmt.Sprintf("bundle %v of death", bd.ID))
+}
+
+func TestQuarantineAttempts(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), &panickingAlgorithm{FakeAlgorithm: testutil.NewFakeAlgorithm(peer)})
+
+	processing.SetQuarantineAttempts(2)
+	defer processing.SetQuarantineAttempts(0)
+	panics := processing.Panics()
+
+	// each failed attempt is counted and the bundle is dispatched again
+	bndl := receivedBundle(t, "dtn://src/")
+	processing.ReceiveBundle(&bndl)
+	for attempts := uint(1); attempts <= 2; attempts++ {
+		waitFor(t, fmt.Sprintf("failed attempt %d", attempts), func() bool {
+			bd, err := store.GetStoreSingleton().LoadBundleDescriptor(bndl.ID())
+			return err == nil && bd.Attempts == attempts && bd.Dispatch && len(bd.RetentionConstraints) == 0
+		})
+		processing.DispatchPending()
+	}
+
+	waitFor(t, "quarantined bundle", func() bool {
+		bd, err := store.GetStoreSingleton().LoadBundleDescriptor(bndl.ID())
+		return err == nil && bd.Quarantined
+	})
+	if n := processing.Panics() - panics; n != 2 {
+		t.Fatalf("Expected 2 recovered panics, got %d", n)
+	}
+
+	// a quarantined bundle is not dispatched anymore
+	processing.DispatchPending()
+	assertNotSent(t, peer, bndl.ID())
+	if n := processing.Panics() - panics; n != 2 {
+		t.Fatalf("Quarantined bundle was processed again, %d recovered panics", n)
+	}
+}
+
+// panickingSender is a FakeSender panicking on each transmission.
+type panickingSender struct {
+	*testutil.FakeSender
+}
+
+func (sender *panickingSender) Send(bpv7.Bundle) error {
+	panic(fmt.Sprintf("transmission to %v", sender.GetPeerEndpointID()))
+}
+
+func TestPanicSupervision(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	broken := &panickingSender{testutil.NewFakeSender("fake://broken", bpv7.MustNewEndpointID("dtn://broken/"))}
+	algorithm := &panickingAlgorithm{FakeAlgorithm: testutil.NewFakeAlgorithm(broken, peer)}
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), algorithm)
+	panics := processing.Panics()
+
+	// without a quarantine, a panicking bundle is dispatched again instead of being stuck while forwarding
+	bndl := receivedBundle(t, "dtn://src/")
+	processing.ReceiveBundle(&bndl)
+	if bd := waitForwarded(t, bndl.ID()); !bd.Dispatch || bd.Attempts != 0 {
+		t.Fatalf("Panicking bundle is dispatchable: %t, counted %d attempts", bd.Dispatch, bd.Attempts)
+	}
+	if n := processing.Panics() - panics; n != 1 {
+		t.Fatalf("Expected 1 recovered panic, got %d", n)
+	}
+
+	// a panicking transmission neither blocks the other ones nor the forwarding waiting for them
+	algorithm.healed.Store(true)
+	processing.DispatchPending()
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bundle was not forwarded to the other peer")
+	}
+	bd := waitForwarded(t, bndl.ID())
+	for _, eid := range bd.GetAlreadySent() {
+		if eid == broken.GetPeerEndpointID() {
+			t.Fatal("Panicking transmission was marked as sent")
+		}
+	}
+	if n := processing.Panics() - panics; n != 2 {
+		t.Fatalf("Expected 2 recovered panics, got %d", n)
+	}
+}
+
+// unsignedBundle builds an unsigned bundle, with an optional Previous Node Block.
+func unsignedBundle(t *testing.T, source, previousNode string) bpv7.Bundle {
+	t.Helper()
+
+	builder := bpv7.Builder().
+		Source(source).
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world"))
+	if previousNode != "" {
+		builder = builder.PreviousNodeBlock(previousNode)
+	}
+	bndl, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+func TestSignatureOfHiddenPeer(t *testing.T) {
+	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), testutil.NewFakeAlgorithm(peer))
+
+	processing.SetSignatureVerification(processing.SignatureConfig{Role: processing.SignatureVerifyEveryHop, Required: true})
+	defer processing.SetSignatureVerification(processing.SignatureConfig{})
+
+	// a peer hiding itself by an omitted or anonymous Previous Node Block is verified as any other one
+	hidden := []bpv7.Bundle{
+		unsignedBundle(t, "dtn://src/omitted", ""),
+		unsignedBundle(t, "dtn://src/anonymous", bpv7.DtnEndpointDtnNone),
+	}
+	for i := range hidden {
+		processing.ReceiveBundle(&hidden[i])
+	}
+
+	// an unsigned bundle created on this node is not verified
+	local := unsignedBundle(t, "dtn://node/app", "")
+	processing.SendBundle(&local)
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Local bundle was not forwarded")
+	}
+
+	for _, bndl := range hidden {
+		assertNotSent(t, peer, bndl.ID())
+		if _, err := store.GetStoreSingleton().LoadBundleDescriptor(bndl.ID()); err == nil {
+			t.Fatalf("Unsigned bundle %v of a hidden peer was stored", bndl.ID())
+		}
+	}
+}
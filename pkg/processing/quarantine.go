@@ -0,0 +1,97 @@
+package processing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+var quarantineAttempts uint
+
+// SetQuarantineAttempts protects the node against a "bundle of death", i.e., a bundle which panics its processing each
+// time, e.g., by a decoding error or a failing block handler. A panicking processing attempt of a stored bundle is
+// always recovered and the bundle is dispatched again. With this protection, the attempt is also counted in its
+// descriptor and, after the given number of failed attempts, the bundle is quarantined instead of looping forever.
+// Only failed attempts are written to the store, thus a crash of the whole node is not counted. Zero, the default,
+// disables the protection.
+func SetQuarantineAttempts(attempts uint) {
+	quarantineAttempts = attempts
+}
+
+// beginAttempt starts a processing attempt of a stored bundle. It returns false if the bundle must not be processed
+// as it is or was just quarantined.
+func beginAttempt(bundleDescriptor *store.BundleDescriptor) bool {
+	if bundleDescriptor.Quarantined {
+		log.WithField("bundle", bundleDescriptor.ID).Debug("Skipping quarantined bundle")
+		return false
+	}
+	if quarantineAttempts == 0 || bundleDescriptor.Attempts < quarantineAttempts {
+		return true
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":   bundleDescriptor.ID,
+		"attempts": bundleDescriptor.Attempts,
+	}).Warn("Quarantining bundle whose processing failed repeatedly")
+	if err := bundleDescriptor.Quarantine(); err != nil && !deletedMeanwhile(bundleDescriptor, err) {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error quarantining bundle")
+	}
+	return false
+}
+
+// endAttempt finishes a processing attempt started by beginAttempt, which must be deferred. A panic is recovered and
+// counted as a failed attempt, if enabled by SetQuarantineAttempts. The optional retry is called afterwards, e.g., to
+// dispatch the bundle again.
+func endAttempt(bundleDescriptor *store.BundleDescriptor, retry func(*store.BundleDescriptor)) {
+	r := recover()
+	if r == nil {
+		if quarantineAttempts > 0 {
+			resetAttempts(bundleDescriptor)
+		}
+		return
+	}
+
+	fields := log.Fields{"bundle": bundleDescriptor.ID}
+	if quarantineAttempts > 0 {
+		attempts, err := bundleDescriptor.FailAttempt()
+		fields["attempts"] = attempts
+		if deletedMeanwhile(bundleDescriptor, err) {
+			retry = nil
+		} else if err != nil {
+			log.WithFields(log.Fields{
+				"bundle": bundleDescriptor.ID,
+				"error":  err,
+			}).Error("Error counting bundle's failed processing attempt")
+		}
+	}
+	recordPanic("processing", fields, r)
+
+	if retry != nil {
+		retry(bundleDescriptor)
+	}
+}
+
+// resetAttempts finishes the current processing attempt successfully, which only writes to the store after failed
+// ones. It might be called before endAttempt, e.g., before the bundle is handed over to other goroutines.
+func resetAttempts(bundleDescriptor *store.BundleDescriptor) {
+	if err := bundleDescriptor.EndAttempt(); err != nil && !deletedMeanwhile(bundleDescriptor, err) {
+		log.WithFields(log.Fields{
+			"bundle": bundleDescriptor.ID,
+			"error":  err,
+		}).Error("Error resetting bundle's processing attempts")
+	}
+}
+
+// processStoredBundleAttempt calls processStoredBundle as a counted processing attempt.
+func processStoredBundleAttempt(bundleDescriptor *store.BundleDescriptor, bundle *bpv7.Bundle) (forward bool) {
+	if !beginAttempt(bundleDescriptor) {
+		return false
+	}
+	defer endAttempt(bundleDescriptor, nil)
+
+	return processStoredBundle(bundleDescriptor, bundle)
+}
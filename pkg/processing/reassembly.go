@@ -0,0 +1,63 @@
+package processing
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// reassembly collects the fragments of bundles for this node, e.g., fragmented by a CLA's learned MTU, until their
+// payload is complete. Fragments are kept in memory and dropped once their lifetime is exceeded.
+var reassembly = struct {
+	sync.Mutex
+	fragments map[string][]bpv7.Bundle
+}{fragments: make(map[string][]bpv7.Bundle)}
+
+// isFragmentForOwnNode checks if a bundle is a fragment to be reassembled by this node.
+func isFragmentForOwnNode(bundle *bpv7.Bundle) bool {
+	return bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) && isOwnNode(bundle.PrimaryBlock.Destination)
+}
+
+// reassembleFragment adds a fragment to its bundle's collected fragments. It returns the reassembled bundle once the
+// last fragment arrived.
+func reassembleFragment(fragment *bpv7.Bundle) (bpv7.Bundle, bool) {
+	reassembly.Lock()
+	defer reassembly.Unlock()
+
+	for id, fragments := range reassembly.fragments {
+		if fragments[0].IsLifetimeExceeded() {
+			delete(reassembly.fragments, id)
+		}
+	}
+
+	id := fragment.ID().Scrub().String()
+	for _, known := range reassembly.fragments[id] {
+		if known.PrimaryBlock.FragmentOffset == fragment.PrimaryBlock.FragmentOffset {
+			return bpv7.Bundle{}, false
+		}
+	}
+
+	fragments := append(reassembly.fragments[id], *fragment)
+	if !bpv7.IsBundleReassemblable(fragments) {
+		reassembly.fragments[id] = fragments
+		return bpv7.Bundle{}, false
+	}
+	delete(reassembly.fragments, id)
+
+	bundle, err := bpv7.ReassembleFragments(fragments)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": id,
+			"error":  err,
+		}).Warn("Failed to reassemble bundle from its fragments")
+		return bpv7.Bundle{}, false
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":    id,
+		"fragments": len(fragments),
+	}).Info("Reassembled bundle from its fragments")
+	return bundle, true
+}
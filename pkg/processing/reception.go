@@ -1,27 +1,129 @@
 package processing
 
 import (
+	"errors"
+
 	log "github.com/sirupsen/logrus"
 
-	"github.com/dtn7/dtn7-go/pkg/application_agent"
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/reputation"
 	"github.com/dtn7/dtn7-go/pkg/routing"
 	"github.com/dtn7/dtn7-go/pkg/store"
 )
 
-func receiveAsync(bundle *bpv7.Bundle) {
+// Reasons for a received bundle to be dropped, already logged by the respective check.
+var (
+	errReplayed        = errors.New("bundle is a replay or was created before the replay window")
+	errUnknownBlock    = errors.New("an unsupported block demands the bundle's deletion")
+	errPayloadChecksum = errors.New("payload checksum mismatch")
+	errSignature       = errors.New("signature verification failed")
+	errAlreadyStored   = errors.New("bundle is already stored")
+	errQuarantined     = errors.New("bundle was received from a quarantined peer")
+)
+
+// previousPeer returns the peer named by the bundle's Previous Node Block. Bundles created locally have none, as well
+// as bundles of a peer hiding itself by an anonymous or omitted block, see SetPreviousNodeRules. Thus, it must not be
+// used to tell local bundles apart from received ones.
+func previousPeer(bundle *bpv7.Bundle) (peer bpv7.EndpointID, ok bool) {
+	block, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock)
+	if err != nil {
+		return
+	}
+	peer = block.Value.(*bpv7.PreviousNodeBlock).Endpoint()
+	return peer, peer != bpv7.DtnNone() && !isOwnNode(peer)
+}
+
+// reportPeer counts a misbehaviour for the bundle's previous peer, if there is one.
+func reportPeer(bundle *bpv7.Bundle, misbehaviour reputation.Misbehaviour) {
+	if peer, ok := previousPeer(bundle); ok {
+		reputation.Report(peer, misbehaviour)
+	}
+}
+
+// storeReceivedBundle checks a received bundle and inserts it into the store. A local bundle was created on this node,
+// e.g., by an application agent, instead of being received from a peer. The peer's reputation can only be tracked if
+// it did not hide itself.
+func storeReceivedBundle(bundle *bpv7.Bundle, local bool) (*store.BundleDescriptor, error) {
+	peer, fromPeer := previousPeer(bundle)
+	fromPeer = fromPeer && !local
+	if fromPeer && reputation.Quarantined(peer) {
+		log.WithFields(log.Fields{
+			"bundle": bundle.ID(),
+			"peer":   peer,
+		}).Debug("Dropping bundle of quarantined peer")
+		return nil, errQuarantined
+	}
+
+	if !checkReplay(bundle) {
+		return nil, errReplayed
+	}
+
+	if !store.GetStoreSingleton().KnownBundle(bundle.ID()) {
+		if !processUnknownBlocks(bundle) {
+			reportPeer(bundle, reputation.Malformed)
+			return nil, errUnknownBlock
+		}
+		if !checkPayloadChecksum(bundle) {
+			reportPeer(bundle, reputation.Malformed)
+			return nil, errPayloadChecksum
+		}
+		if !local && !checkSignature(bundle) {
+			reportPeer(bundle, reputation.Malformed)
+			return nil, errSignature
+		}
+		if !checkCongestion(bundle) {
+			reportPeer(bundle, reputation.Refused)
+			return nil, errCongested
+		}
+		if fromPeer {
+			reputation.Accept(peer)
+		}
+	} else {
+		reportPeer(bundle, reputation.Duplicate)
+	}
+
+	rewriteGatewayEndpoints(bundle)
+
 	bundleDescriptor, err := store.GetStoreSingleton().InsertBundle(bundle)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundle.ID(),
 			"error":  err,
 		}).Error("Error storing new bundle")
-		return
+		return nil, err
 	}
+	return bundleDescriptor, nil
+}
 
-	application_agent.GetManagerSingleton().Delivery(bundleDescriptor)
+// processStoredBundle hands a stored bundle over to the local endpoints and the routing algorithm. Fragments for this
+// node are reassembled first. It returns false for an administrative record for this node, which was processed and
+// must not be forwarded.
+func processStoredBundle(bundleDescriptor *store.BundleDescriptor, bundle *bpv7.Bundle) (forward bool) {
+	if isFragmentForOwnNode(bundle) {
+		// Only the reassembled bundle is processed, being received as if it arrived as a whole.
+		if reassembled, ok := reassembleFragment(bundle); ok {
+			ReceiveBundle(&reassembled)
+		}
+	} else if isForOwnAdministrativeEndpoint(bundle) {
+		receiveAdministrativeRecord(bundleDescriptor, bundle)
+		return false
+	} else {
+		deliverLocally(bundleDescriptor, bundle)
+	}
 
 	routing.GetAlgorithmSingleton().NotifyNewBundle(bundleDescriptor)
+	return true
+}
+
+func receiveAsync(bundle *bpv7.Bundle, local bool) {
+	bundleDescriptor, err := storeReceivedBundle(bundle, local)
+	if err != nil {
+		return
+	}
+
+	if !processStoredBundleAttempt(bundleDescriptor, bundle) {
+		return
+	}
 
 	for _, constraint := range bundleDescriptor.RetentionConstraints {
 		if constraint == store.DispatchPending {
@@ -29,8 +131,40 @@ func receiveAsync(bundle *bpv7.Bundle) {
 			BundleForwarding(bundleDescriptor)
 		}
 	}
+
+	triggerDispatchOnNewBundle()
 }
 
+// ReceiveBundle processes a bundle received from a peer, e.g., by a CLA.
 func ReceiveBundle(bundle *bpv7.Bundle) {
-	go receiveAsync(bundle)
+	spawn(func() { receiveAsync(bundle, false) })
+}
+
+// SendBundle processes a bundle created on this node, e.g., by an application agent or as a status report. Unlike a
+// received bundle, its signature is not verified.
+func SendBundle(bundle *bpv7.Bundle) {
+	spawn(func() { receiveAsync(bundle, true) })
+}
+
+// ImportBundle inserts a bundle, e.g., read from a file, into the store after the same checks as a received bundle.
+// Unlike ReceiveBundle, it returns once the bundle is stored and reports why a bundle was rejected. The stored bundle
+// is not forwarded immediately, but keeps its DispatchPending constraint for the next dispatch sweep.
+func ImportBundle(bundle *bpv7.Bundle) (*store.BundleDescriptor, error) {
+	if err := bundle.CheckValid(); err != nil {
+		return nil, err
+	}
+	if store.GetStoreSingleton().KnownBundle(bundle.ID()) {
+		return nil, errAlreadyStored
+	}
+
+	bundleDescriptor, err := storeReceivedBundle(bundle, false)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("bundle", bundleDescriptor.IDString).Info("Imported bundle")
+	if processStoredBundleAttempt(bundleDescriptor, bundle) {
+		triggerDispatchOnNewBundle()
+	}
+	return bundleDescriptor, nil
 }
@@ -0,0 +1,91 @@
+package processing
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// replayWindow remembers the IDs, i.e., source, creation timestamp and sequence number, of received bundles for a
+// configured duration. Unlike the store, it still knows bundles which were delivered and deleted.
+type replayWindow struct {
+	mutex  sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+	// order holds the IDs in the order they were seen, allowing to prune the oldest ones first.
+	order []string
+}
+
+var replay *replayWindow
+
+// SetReplayWindow enables rejecting replayed bundles. A bundle is rejected if its ID was seen within the window,
+// even if it is no longer stored, or if it was created before the window and thus cannot be checked. Bundles without
+// a creation time, i.e., from nodes without accurate clocks, are only checked against their ID.
+//
+// Seen IDs are not persisted. Thus, after a restart, bundles created within the window might be replayed once.
+func SetReplayWindow(window time.Duration) {
+	if window <= 0 {
+		replay = nil
+		return
+	}
+
+	replay = &replayWindow{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// prune forgets all IDs seen before the window.
+func (rw *replayWindow) prune(now time.Time) {
+	i := 0
+	for ; i < len(rw.order); i++ {
+		if now.Sub(rw.seen[rw.order[i]]) < rw.window {
+			break
+		}
+		delete(rw.seen, rw.order[i])
+	}
+	rw.order = rw.order[i:]
+}
+
+// check records a new bundle and returns false for a replay.
+func (rw *replayWindow) check(bundle *bpv7.Bundle, now time.Time) bool {
+	creationTime := bundle.PrimaryBlock.CreationTimestamp.DtnTime()
+	if creationTime != bpv7.DtnTimeEpoch && now.Sub(creationTime.Time()) > rw.window {
+		return false
+	}
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	rw.prune(now)
+
+	id := bundle.ID().String()
+	if _, ok := rw.seen[id]; ok {
+		return false
+	}
+	rw.seen[id] = now
+	rw.order = append(rw.order, id)
+	return true
+}
+
+// checkReplay returns false if a received bundle is a replay and must be dropped. Duplicates of stored bundles are
+// no replays, as they are still needed, e.g., to learn about another node having them.
+func checkReplay(bundle *bpv7.Bundle) bool {
+	if replay == nil || store.GetStoreSingleton().KnownBundle(bundle.ID()) {
+		return true
+	}
+
+	if !replay.check(bundle, clock.Now()) {
+		log.WithFields(log.Fields{
+			"bundle": bundle.ID(),
+			"window": replay.window,
+		}).Warn("Dropping replayed bundle or bundle created before the replay window")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package processing
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+var resendOnReconnect bool
+
+// SetResendOnReconnect enables forgetting earlier transmissions to a peer which connects again.
+// A reconnecting peer might have crashed and lost bundles it had received before.
+func SetResendOnReconnect(enabled bool) {
+	resendOnReconnect = enabled
+}
+
+var (
+	seenPeersMutex sync.Mutex
+	seenPeers      = make(map[string]bool)
+)
+
+// forgetSentOnReconnect removes a reconnecting peer from all bundles' already sent lists.
+// A peer's first connection since startup is left untouched.
+func forgetSentOnReconnect(peerID bpv7.EndpointID) {
+	if !resendOnReconnect {
+		return
+	}
+
+	seenPeersMutex.Lock()
+	node := peerID.Authority()
+	reconnect := seenPeers[node]
+	seenPeers[node] = true
+	seenPeersMutex.Unlock()
+
+	if !reconnect {
+		return
+	}
+
+	changed, err := store.GetStoreSingleton().ForgetSent(peerID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"peer":  peerID,
+			"error": err,
+		}).Error("Error forgetting bundles sent to reconnected peer")
+	} else {
+		log.WithFields(log.Fields{
+			"peer":    peerID,
+			"bundles": changed,
+		}).Debug("Forgot bundles sent to reconnected peer")
+	}
+}
@@ -0,0 +1,130 @@
+package processing
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// SignatureRole describes at which nodes a bundle's SignatureBlock is verified, following the roles of a security
+// verifier and acceptor of RFC 9172. A bundle failing its verification is deleted.
+type SignatureRole int
+
+const (
+	// SignatureVerifyNever forwards and delivers bundles without verifying their signatures.
+	SignatureVerifyNever SignatureRole = iota
+	// SignatureVerifyEveryHop verifies each received bundle, dropping forged bundles as early as possible.
+	SignatureVerifyEveryHop
+	// SignatureVerifyDestination only verifies bundles for local endpoints, i.e., this node acts as the acceptor.
+	SignatureVerifyDestination
+	// SignatureVerifyVerifiers verifies each received bundle at the designated security verifiers, e.g., gateways
+	// between trusted and untrusted parts of a network, and at the destination.
+	SignatureVerifyVerifiers
+)
+
+// SignatureRoleFromString parses a SignatureRole, either "never", "every-hop", "destination" or "verifiers".
+func SignatureRoleFromString(s string) (SignatureRole, error) {
+	switch s {
+	case "never":
+		return SignatureVerifyNever, nil
+	case "every-hop":
+		return SignatureVerifyEveryHop, nil
+	case "destination":
+		return SignatureVerifyDestination, nil
+	case "verifiers":
+		return SignatureVerifyVerifiers, nil
+	default:
+		return 0, fmt.Errorf("unknown signature verification %q", s)
+	}
+}
+
+func (role SignatureRole) String() string {
+	switch role {
+	case SignatureVerifyNever:
+		return "never"
+	case SignatureVerifyEveryHop:
+		return "every-hop"
+	case SignatureVerifyDestination:
+		return "destination"
+	case SignatureVerifyVerifiers:
+		return "verifiers"
+	default:
+		return "unknown"
+	}
+}
+
+// SignatureConfig configures the verification of SignatureBlocks, see SetSignatureVerification.
+type SignatureConfig struct {
+	Role SignatureRole
+	// Verifiers match the node IDs of the nodes acting as security verifiers for SignatureVerifyVerifiers, e.g.,
+	// "dtn://gateway-*/". Thus, all nodes can share the same configuration.
+	Verifiers []bpv7.EndpointPattern
+	// Required deletes unsigned bundles wherever a signature would be verified.
+	Required bool
+}
+
+var signatureConfig SignatureConfig
+
+// SetSignatureVerification configures at which nodes the SignatureBlocks of received bundles are verified. By
+// default, signatures are never verified, but signed bundles are forwarded with their SignatureBlock.
+func SetSignatureVerification(config SignatureConfig) {
+	signatureConfig = config
+}
+
+// verifiesSignature checks if this node verifies the bundle's signature.
+func verifiesSignature(bundle *bpv7.Bundle) bool {
+	switch signatureConfig.Role {
+	case SignatureVerifyEveryHop:
+		return true
+	case SignatureVerifyDestination:
+		return isForLocalEndpoint(bundle)
+	case SignatureVerifyVerifiers:
+		for _, verifier := range signatureConfig.Verifiers {
+			if isOwnNodeMatching(verifier) {
+				return true
+			}
+		}
+		return isForLocalEndpoint(bundle)
+	default:
+		return false
+	}
+}
+
+// checkSignature verifies a bundle's SignatureBlock, received from a peer, if this node's SignatureRole demands it.
+// Fragments cannot be verified and are always kept. A peer hiding itself by omitting its Previous Node Block is
+// verified as any other one.
+//
+// False is returned for a forged bundle, or an unsigned one if signatures are required. It must not be processed any
+// further.
+func checkSignature(bundle *bpv7.Bundle) (keep bool) {
+	if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.IsFragment) || !verifiesSignature(bundle) {
+		return true
+	}
+	logger := log.WithFields(log.Fields{
+		"bundle": bundle.ID(),
+		"role":   signatureConfig.Role,
+	})
+
+	var reason bpv7.StatusReportReason
+	if !bundle.HasExtensionBlock(bpv7.ExtBlockTypeSignatureBlock) {
+		if !signatureConfig.Required {
+			return true
+		}
+		logger.Warn("Deleting unsigned bundle")
+		reason = bpv7.MissingSecurityOperation
+	} else if sb, err := bundle.SignatureBlock(); err != nil || !sb.Verify(*bundle) {
+		logger.WithError(err).Warn("Deleting bundle due to a failed signature verification")
+		reason = bpv7.FailedSecurityOperation
+	} else {
+		logger.Debug("Verified bundle's signature")
+		return true
+	}
+
+	reportDeletion(bundle, reason)
+	if bundle.PrimaryBlock.BundleControlFlags.Has(bpv7.StatusRequestDeletion) {
+		sendStatusReport(bundle, bpv7.DeletedBundle, reason)
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package processing
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// panics counts the panics recovered while processing bundles.
+var panics atomic.Uint64
+
+// Panics returns the number of panics recovered while processing bundles since the node started. Each of them was
+// logged and published as a cla.ProcessingPanic event.
+func Panics() uint64 {
+	return panics.Load()
+}
+
+// recordPanic counts, logs and publishes a recovered panic of a task, e.g., "forwarding".
+func recordPanic(task string, fields log.Fields, r interface{}) {
+	panics.Add(1)
+
+	err := fmt.Errorf("%s panicked: %v", task, r)
+	if bundle, ok := fields["bundle"]; ok {
+		err = fmt.Errorf("%s of bundle %v panicked: %v", task, bundle, r)
+	}
+
+	entry := log.WithFields(fields).WithField("panic", fmt.Sprint(r))
+	entry.Errorf("Recovered from panicking %s", task)
+	entry.Debug(string(debug.Stack()))
+
+	cla.GetManagerSingleton().NotifyProcessingPanic(err)
+}
+
+// supervise runs a task, recovering from its panic. Thus, a single malformed bundle neither kills the node nor one of
+// the workers, which continue with their next task.
+func supervise(task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			recordPanic("processing", log.Fields{}, r)
+		}
+	}()
+
+	task()
+}
@@ -0,0 +1,81 @@
+package processing
+
+import "sync"
+
+// workerPool processes bundles on a fixed number of goroutines instead of a goroutine per bundle, see SetWorkers.
+type workerPool struct {
+	tasks chan func()
+}
+
+var workers *workerPool
+
+// running counts the spawned tasks until they are finished, see Wait.
+var running sync.WaitGroup
+
+// SetWorkers limits the processing of received and forwarded bundles to a fixed number of goroutines, bounding the
+// memory used under load, e.g., on constrained devices. Each worker queues at most one further task. If all workers
+// are busy and the queue is full, the caller processes the bundle itself. Thus, a CLA delivering bundles faster than
+// they can be processed is slowed down. Furthermore, a bundle is forwarded to one peer after another.
+//
+// A non-positive number restores the default of a goroutine per bundle. This should only be called once, before
+// any bundle is processed.
+func SetWorkers(n int) {
+	if n <= 0 {
+		workers = nil
+		return
+	}
+
+	pool := &workerPool{tasks: make(chan func(), n)}
+	for i := 0; i < n; i++ {
+		go pool.run()
+	}
+	workers = pool
+}
+
+// run the queued tasks. A panicking task is recovered and the worker continues with the next one.
+func (pool *workerPool) run() {
+	for task := range pool.tasks {
+		supervise(task)
+	}
+}
+
+// WorkerQueue returns the number of tasks queued for the workers and the queue's capacity, both zero without workers.
+func WorkerQueue() (queued, capacity int) {
+	if workers == nil {
+		return 0, 0
+	}
+	return len(workers.tasks), cap(workers.tasks)
+}
+
+// spawn runs a task asynchronously, either on its own goroutine or by the workers. Its panic is recovered, see
+// supervise.
+func spawn(task func()) {
+	running.Add(1)
+	task = withDone(task)
+
+	if workers == nil {
+		go supervise(task)
+		return
+	}
+
+	select {
+	case workers.tasks <- task:
+	default:
+		supervise(task)
+	}
+}
+
+// withDone marks a spawned task as finished after it returned or panicked.
+func withDone(task func()) func() {
+	return func() {
+		defer running.Done()
+		task()
+	}
+}
+
+// Wait blocks until all spawned tasks are finished, i.e., the bundles being received or forwarded, including the
+// bundles spawned by those, e.g., status reports. Thus, the store might be closed afterwards. Bundles received
+// meanwhile are waited for as well.
+func Wait() {
+	running.Wait()
+}
@@ -0,0 +1,298 @@
+// Package reputation scores peers by their behaviour, e.g., sending malformed bundles or flooding duplicates, to
+// deprioritize or quarantine misbehaving neighbours.
+//
+// The processing attributes each received bundle to its previous node, as named by its Previous Node Block. Bundles
+// failing to be decoded by a convergence layer cannot be attributed and are not counted.
+//
+// Reputations are only tracked after InitialiseTracker was called. Otherwise, all hooks are no-ops and every peer has
+// a perfect Score.
+package reputation
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/util"
+)
+
+// Misbehaviour is a kind of a peer's misbehaviour, observed for a received bundle.
+type Misbehaviour int
+
+const (
+	// Malformed bundles were invalid, e.g., failed their payload checksum or demanded their deletion.
+	Malformed Misbehaviour = iota
+	// Refused bundles were not accepted by this node, e.g., due to storage congestion.
+	Refused
+	// Duplicate bundles were already stored at this node.
+	Duplicate
+)
+
+func (m Misbehaviour) String() string {
+	switch m {
+	case Malformed:
+		return "malformed"
+	case Refused:
+		return "refused"
+	case Duplicate:
+		return "duplicate"
+	default:
+		return "unknown"
+	}
+}
+
+// weight of a Misbehaviour, compared to an accepted bundle. Duplicates are common for flooding routing algorithms and
+// refusals are mostly caused by this node, thus both weigh less than malformed bundles.
+func (m Misbehaviour) weight() float64 {
+	switch m {
+	case Malformed:
+		return 1
+	case Refused:
+		return 0.25
+	case Duplicate:
+		return 0.1
+	default:
+		return 0
+	}
+}
+
+// minQuarantineEvents is the least number of received bundles before a peer might be quarantined, such that a single
+// malformed bundle of a new peer is no reason for its quarantine.
+const minQuarantineEvents = 10
+
+// Config describes when peers are quarantined.
+type Config struct {
+	// QuarantineThreshold is the Score below which a peer is quarantined, zero disables quarantines.
+	QuarantineThreshold float64
+	// QuarantineDuration is the time of a quarantine. Afterwards, the peer's statistics are reset.
+	QuarantineDuration time.Duration
+}
+
+// CheckValid checks the threshold's range and a positive duration for an enabled quarantine.
+func (config Config) CheckValid() error {
+	if config.QuarantineThreshold < 0 || config.QuarantineThreshold > 1 {
+		return fmt.Errorf("quarantine threshold %v is not between 0 and 1", config.QuarantineThreshold)
+	}
+	if config.QuarantineThreshold > 0 && config.QuarantineDuration <= 0 {
+		return fmt.Errorf("quarantine duration %v is not positive", config.QuarantineDuration)
+	}
+	return nil
+}
+
+// PeerStats are the observations of a peer's received bundles.
+type PeerStats struct {
+	Accepted   uint64
+	Malformed  uint64
+	Refused    uint64
+	Duplicates uint64
+	// QuarantinedUntil is the end of the current quarantine, or the zero time.
+	QuarantinedUntil time.Time
+}
+
+// events is the number of all received bundles.
+func (stats PeerStats) events() uint64 {
+	return stats.Accepted + stats.Malformed + stats.Refused + stats.Duplicates
+}
+
+// expired checks if a quarantine has ended, which resets the PeerStats.
+func (stats *PeerStats) expired() bool {
+	return !stats.QuarantinedUntil.IsZero() && clock.Now().After(stats.QuarantinedUntil)
+}
+
+// Score between 0 and 1 as the share of accepted bundles, with weighted misbehaviours. It starts at 1 for a peer
+// without any observations.
+func (stats PeerStats) Score() float64 {
+	good := float64(stats.Accepted) + 1
+	bad := float64(stats.Malformed)*Malformed.weight() +
+		float64(stats.Refused)*Refused.weight() +
+		float64(stats.Duplicates)*Duplicate.weight()
+	return good / (good + bad)
+}
+
+// PeerReputation is a peer's PeerStats.
+type PeerReputation struct {
+	Peer bpv7.EndpointID
+	PeerStats
+}
+
+var trackerSingleton *Tracker
+
+// Tracker collects the PeerStats of all peers and quarantines them, based on its Config.
+type Tracker struct {
+	mutex  sync.Mutex
+	config Config
+	peers  map[bpv7.EndpointID]*PeerStats
+}
+
+// InitialiseTracker initialises the Tracker singleton and starts tracking reputations.
+func InitialiseTracker(config Config) error {
+	if trackerSingleton != nil {
+		return util.NewAlreadyInitialisedError("Reputation Tracker")
+	}
+	if err := config.CheckValid(); err != nil {
+		return err
+	}
+
+	trackerSingleton = &Tracker{
+		config: config,
+		peers:  make(map[bpv7.EndpointID]*PeerStats),
+	}
+
+	log.WithFields(log.Fields{
+		"quarantine threshold": config.QuarantineThreshold,
+		"quarantine duration":  config.QuarantineDuration,
+	}).Info("Tracking peer reputations")
+
+	return nil
+}
+
+// Initialised checks if the Tracker singleton exists. Otherwise, no reputations are tracked.
+func Initialised() bool {
+	return trackerSingleton != nil
+}
+
+// GetTrackerSingleton returns the Tracker singleton.
+func GetTrackerSingleton() *Tracker {
+	if trackerSingleton == nil {
+		log.Fatalf("Attempting to access an uninitialised Reputation Tracker. This must never happen!")
+	}
+	return trackerSingleton
+}
+
+// Config returns the Config.
+func (tracker *Tracker) Config() Config {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	return tracker.config
+}
+
+// Shutdown stops tracking reputations and resets the singleton.
+func (tracker *Tracker) Shutdown() {
+	trackerSingleton = nil
+}
+
+// stats returns the peer's PeerStats for an update, created if unknown or reset after an expired quarantine. The
+// caller must hold the mutex.
+func (tracker *Tracker) stats(peer bpv7.EndpointID) *PeerStats {
+	stats, ok := tracker.peers[peer]
+	if !ok || stats.expired() {
+		stats = &PeerStats{}
+		tracker.peers[peer] = stats
+	}
+	return stats
+}
+
+// accept counts an accepted bundle of the peer.
+func (tracker *Tracker) accept(peer bpv7.EndpointID) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.stats(peer).Accepted++
+}
+
+// report counts a peer's Misbehaviour and quarantines it if its Score falls below the threshold.
+func (tracker *Tracker) report(peer bpv7.EndpointID, misbehaviour Misbehaviour) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	stats := tracker.stats(peer)
+	switch misbehaviour {
+	case Malformed:
+		stats.Malformed++
+	case Refused:
+		stats.Refused++
+	case Duplicate:
+		stats.Duplicates++
+	}
+
+	if tracker.config.QuarantineThreshold <= 0 || !stats.QuarantinedUntil.IsZero() ||
+		stats.events() < minQuarantineEvents || stats.Score() >= tracker.config.QuarantineThreshold {
+		return
+	}
+
+	stats.QuarantinedUntil = clock.Now().Add(tracker.config.QuarantineDuration)
+	log.WithFields(log.Fields{
+		"peer":         peer,
+		"score":        stats.Score(),
+		"misbehaviour": misbehaviour,
+		"until":        stats.QuarantinedUntil,
+	}).Warn("Quarantining misbehaving peer")
+}
+
+// get returns a copy of the peer's PeerStats, the zero value for an unknown peer or after an expired quarantine.
+func (tracker *Tracker) get(peer bpv7.EndpointID) PeerStats {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	stats, ok := tracker.peers[peer]
+	if !ok {
+		return PeerStats{}
+	} else if stats.expired() {
+		delete(tracker.peers, peer)
+		return PeerStats{}
+	}
+	return *stats
+}
+
+// Peers returns the reputations of all observed peers, ordered by their EndpointIDs.
+func (tracker *Tracker) Peers() []PeerReputation {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	reputations := make([]PeerReputation, 0, len(tracker.peers))
+	for peer, stats := range tracker.peers {
+		if stats.expired() {
+			delete(tracker.peers, peer)
+			continue
+		}
+		reputations = append(reputations, PeerReputation{Peer: peer, PeerStats: *stats})
+	}
+	sort.Slice(reputations, func(i, j int) bool {
+		return reputations[i].Peer.String() < reputations[j].Peer.String()
+	})
+	return reputations
+}
+
+// Accept counts an accepted bundle of the peer.
+func Accept(peer bpv7.EndpointID) {
+	if trackerSingleton == nil {
+		return
+	}
+	trackerSingleton.accept(peer)
+}
+
+// Report a peer's Misbehaviour, which might quarantine it.
+func Report(peer bpv7.EndpointID, misbehaviour Misbehaviour) {
+	if trackerSingleton == nil {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"peer":         peer,
+		"misbehaviour": misbehaviour,
+	}).Debug("Peer misbehaved")
+	trackerSingleton.report(peer, misbehaviour)
+}
+
+// Score of a peer between 0 and 1, where 1 is a perfect reputation. Routing algorithms might prefer peers of a better
+// Score.
+func Score(peer bpv7.EndpointID) float64 {
+	if trackerSingleton == nil {
+		return 1
+	}
+	return trackerSingleton.get(peer).Score()
+}
+
+// Quarantined checks if the peer is currently quarantined. Bundles of a quarantined peer are dropped without any
+// further processing and no bundles are forwarded to it.
+func Quarantined(peer bpv7.EndpointID) bool {
+	if trackerSingleton == nil {
+		return false
+	}
+	return !trackerSingleton.get(peer).QuarantinedUntil.IsZero()
+}
@@ -0,0 +1,99 @@
+package reputation
+
+import (
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+func TestConfigCheckValid(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		config := Config{
+			QuarantineThreshold: rapid.Float64Range(-1, 2).Draw(t, "threshold"),
+			QuarantineDuration:  time.Duration(rapid.Int64Range(-10, 10).Draw(t, "duration")),
+		}
+
+		valid := config.QuarantineThreshold >= 0 && config.QuarantineThreshold <= 1 &&
+			(config.QuarantineThreshold == 0 || config.QuarantineDuration > 0)
+		if err := config.CheckValid(); valid != (err == nil) {
+			t.Fatalf("Config %v has validity %t, but error %v", config, valid, err)
+		}
+	})
+}
+
+func TestPeerStatsScore(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		stats := PeerStats{
+			Accepted:   rapid.Uint64Max(1000).Draw(t, "accepted"),
+			Malformed:  rapid.Uint64Max(1000).Draw(t, "malformed"),
+			Refused:    rapid.Uint64Max(1000).Draw(t, "refused"),
+			Duplicates: rapid.Uint64Max(1000).Draw(t, "duplicates"),
+		}
+
+		score := stats.Score()
+		if score <= 0 || score > 1 {
+			t.Fatalf("Score %v of %v is out of range", score, stats)
+		}
+
+		worse := stats
+		worse.Malformed++
+		if worse.Score() >= score {
+			t.Fatalf("Malformed bundle did not lower score %v", score)
+		}
+	})
+}
+
+func TestTracker(t *testing.T) {
+	fc := clock.NewFakeClock(time.Unix(0, 0))
+	clock.SetClock(fc)
+	defer clock.SetClock(clock.RealClock{})
+
+	good := bpv7.MustNewEndpointID("dtn://good/")
+	bad := bpv7.MustNewEndpointID("dtn://bad/")
+
+	Report(bad, Malformed)
+	if Score(bad) != 1 || Quarantined(bad) {
+		t.Fatal("Uninitialised tracker tracked a misbehaviour")
+	}
+
+	if err := InitialiseTracker(Config{QuarantineThreshold: 0.5, QuarantineDuration: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	defer GetTrackerSingleton().Shutdown()
+
+	for i := 0; i < 20; i++ {
+		Accept(good)
+		Report(good, Duplicate)
+	}
+	for i := 0; i < minQuarantineEvents-1; i++ {
+		Report(bad, Malformed)
+	}
+
+	if Quarantined(good) {
+		t.Fatalf("Peer of score %v was quarantined", Score(good))
+	}
+	if Quarantined(bad) {
+		t.Fatal("Peer was quarantined before enough bundles were observed")
+	}
+
+	Report(bad, Malformed)
+	if !Quarantined(bad) {
+		t.Fatalf("Peer of score %v was not quarantined", Score(bad))
+	}
+
+	if peers := GetTrackerSingleton().Peers(); len(peers) != 2 || peers[0].Peer != bad || peers[1].Peer != good {
+		t.Fatalf("Peers are %v", peers)
+	}
+
+	fc.Advance(time.Hour + time.Second)
+	if Quarantined(bad) || Score(bad) != 1 {
+		t.Fatal("Peer's statistics were not reset after its quarantine")
+	}
+	if peers := GetTrackerSingleton().Peers(); len(peers) != 1 || peers[0].Peer != good {
+		t.Fatalf("Peers after quarantine are %v", peers)
+	}
+}
@@ -0,0 +1,362 @@
+// Package request_response offers request/response semantics on top of bundles, which many applications would
+// otherwise reimplement on top of sending and receiving raw bundles.
+//
+// An Endpoint is an ApplicationAgent for one endpoint ID. Its Request method sends a request bundle to another
+// Endpoint and waits for the response. Requests and responses are correlated by an ID within their payload. If no
+// response arrives within the timeout, the request is sent again with the same ID, up to the configured number of
+// retries. As a retry might cross an earlier response, the responding Endpoint remembers its responses and answers a
+// retried request again without calling its Handler twice.
+//
+// All of this happens at the level of application data units: each request and response is a bundle of its own.
+// Delivery is still neither guaranteed nor ordered, thus Request's timeouts should account for the network's delays.
+package request_response
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// DefaultTimeout of a single attempt of a request, if none is configured.
+const DefaultTimeout = 30 * time.Second
+
+// ErrShutdown is returned by Request if its Endpoint was shut down.
+var ErrShutdown = errors.New("endpoint was shut down")
+
+// TimeoutError is returned by Request if no attempt was answered within its timeout.
+type TimeoutError struct {
+	Destination bpv7.EndpointID
+	Attempts    int
+}
+
+func (err *TimeoutError) Error() string {
+	return fmt.Sprintf("request to %v was not answered after %d attempts", err.Destination, err.Attempts)
+}
+
+// RemoteError is returned by Request if the responder's Handler failed, containing its error message.
+type RemoteError string
+
+func (err RemoteError) Error() string {
+	return fmt.Sprintf("responder failed: %s", string(err))
+}
+
+// Handler answers a request from the source endpoint. A returned error is passed to the requester as a RemoteError.
+type Handler func(source bpv7.EndpointID, request []byte) (response []byte, err error)
+
+// Config of an Endpoint.
+type Config struct {
+	// Timeout of each attempt of a request, DefaultTimeout if zero.
+	Timeout time.Duration
+	// Retries is the number of attempts after the first one.
+	Retries int
+	// Lifetime of the sent bundles and of the remembered responses. If zero, it covers all attempts of a request.
+	Lifetime time.Duration
+}
+
+// withDefaults returns a copy of the Config with its zero values replaced by their defaults.
+func (conf Config) withDefaults() (Config, error) {
+	if conf.Timeout < 0 || conf.Retries < 0 || conf.Lifetime < 0 {
+		return conf, fmt.Errorf("timeout, retries and lifetime must not be negative")
+	}
+
+	if conf.Timeout == 0 {
+		conf.Timeout = DefaultTimeout
+	}
+	if conf.Lifetime == 0 {
+		conf.Lifetime = conf.Timeout * time.Duration(conf.Retries+1)
+	}
+	return conf, nil
+}
+
+// answer to a received request, remembered to answer its retries. Its response is nil while the Handler is running.
+type answer struct {
+	response *message
+	expires  time.Time
+}
+
+// answerKey identifies a received request.
+type answerKey struct {
+	source bpv7.EndpointID
+	id     uint64
+}
+
+// Endpoint sends requests and, if it has a Handler, answers requests.
+type Endpoint struct {
+	endpoint bpv7.EndpointID
+	conf     Config
+	handler  Handler
+	send     func(bndl *bpv7.Bundle)
+
+	mutex    sync.Mutex
+	closed   bool
+	pending  map[uint64]chan message
+	answered map[answerKey]*answer
+}
+
+// NewEndpoint creates an Endpoint for the endpoint ID, sending its bundles by the send function. The handler might be
+// nil for an Endpoint which only sends requests.
+//
+// Received bundles must be passed to the Endpoint as an ApplicationAgent, see RegisterEndpoint.
+func NewEndpoint(endpoint bpv7.EndpointID, conf Config, handler Handler, send func(bndl *bpv7.Bundle)) (*Endpoint, error) {
+	if err := endpoint.CheckValid(); err != nil {
+		return nil, err
+	}
+	conf, err := conf.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Endpoint{
+		endpoint: endpoint,
+		conf:     conf,
+		handler:  handler,
+		send:     send,
+		pending:  make(map[uint64]chan message),
+		answered: make(map[answerKey]*answer),
+	}, nil
+}
+
+// RegisterEndpoint creates an Endpoint which sends its bundles through the application_agent.Manager and registers
+// it there.
+func RegisterEndpoint(endpoint bpv7.EndpointID, conf Config, handler Handler) (*Endpoint, error) {
+	manager := application_agent.GetManagerSingleton()
+
+	ep, err := NewEndpoint(endpoint, conf, handler, manager.Send)
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.RegisterAgent(ep); err != nil {
+		return nil, err
+	}
+	return ep, nil
+}
+
+// Request sends the request to the destination's Endpoint and returns its response. Unanswered requests are retried
+// after each timeout.
+func (ep *Endpoint) Request(destination bpv7.EndpointID, request []byte) ([]byte, error) {
+	id, responses, err := ep.newRequest()
+	if err != nil {
+		return nil, err
+	}
+	defer ep.forgetRequest(id)
+
+	timer := clock.NewTimer(ep.conf.Timeout)
+	defer timer.Stop()
+
+	attempts := ep.conf.Retries + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := ep.sendMessage(destination, message{Kind: kindRequest, ID: id, Data: request}); err != nil {
+			return nil, err
+		}
+
+		if attempt > 1 {
+			timer.Reset(ep.conf.Timeout)
+		}
+
+		select {
+		case msg, ok := <-responses:
+			if !ok {
+				return nil, ErrShutdown
+			} else if msg.Kind == kindError {
+				return nil, RemoteError(msg.Data)
+			}
+			return msg.Data, nil
+
+		case <-timer.C():
+			log.WithFields(log.Fields{
+				"endpoint":    ep.endpoint,
+				"destination": destination,
+				"id":          id,
+				"attempt":     attempt,
+			}).Debug("Request timed out")
+		}
+	}
+
+	return nil, &TimeoutError{Destination: destination, Attempts: attempts}
+}
+
+// newRequest creates a random ID for a request and a channel for its response.
+func (ep *Endpoint) newRequest() (uint64, chan message, error) {
+	var buff [8]byte
+	if _, err := rand.Read(buff[:]); err != nil {
+		return 0, nil, err
+	}
+	id := binary.BigEndian.Uint64(buff[:])
+
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	if ep.closed {
+		return 0, nil, ErrShutdown
+	} else if _, ok := ep.pending[id]; ok {
+		return 0, nil, fmt.Errorf("request ID %d is already pending", id)
+	}
+
+	responses := make(chan message, 1)
+	ep.pending[id] = responses
+	return id, responses, nil
+}
+
+// forgetRequest stops waiting for a response to the request.
+func (ep *Endpoint) forgetRequest(id uint64) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	delete(ep.pending, id)
+}
+
+// sendMessage as a bundle to the destination.
+func (ep *Endpoint) sendMessage(destination bpv7.EndpointID, msg message) error {
+	data, err := marshalMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	bndl, err := bpv7.Builder().
+		Source(ep.endpoint).
+		Destination(destination).
+		CreationTimestampNow().
+		Lifetime(ep.conf.Lifetime).
+		PayloadBlock(data).
+		Build()
+	if err != nil {
+		return err
+	}
+
+	ep.send(&bndl)
+	return nil
+}
+
+// receive a request or a response.
+func (ep *Endpoint) receive(bndl *bpv7.Bundle) error {
+	payload, err := bndl.PayloadBlock()
+	if err != nil {
+		return err
+	}
+	msg, err := unmarshalMessage(payload.Value.(*bpv7.PayloadBlock).Data())
+	if err != nil {
+		return err
+	}
+
+	if msg.Kind == kindRequest {
+		return ep.answerRequest(bndl.PrimaryBlock.SourceNode, msg)
+	}
+
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	if responses, ok := ep.pending[msg.ID]; !ok {
+		log.WithFields(log.Fields{
+			"endpoint": ep.endpoint,
+			"message":  msg,
+		}).Debug("Dropping response to an unknown or answered request")
+	} else {
+		select {
+		case responses <- msg:
+		default:
+		}
+	}
+	return nil
+}
+
+// answerRequest by the Handler, unless the request is a retry. Then, the remembered response is sent again.
+func (ep *Endpoint) answerRequest(source bpv7.EndpointID, msg message) error {
+	if ep.handler == nil {
+		return fmt.Errorf("endpoint %v does not answer requests", ep.endpoint)
+	}
+
+	key := answerKey{source: source, id: msg.ID}
+	now := clock.Now()
+
+	ep.mutex.Lock()
+	for k, known := range ep.answered {
+		if now.After(known.expires) {
+			delete(ep.answered, k)
+		}
+	}
+
+	if known, ok := ep.answered[key]; ok {
+		response := known.response
+		ep.mutex.Unlock()
+
+		// The Handler is still running for the first attempt, which will be answered.
+		if response == nil {
+			return nil
+		}
+		return ep.sendMessage(source, *response)
+	}
+
+	ep.answered[key] = &answer{expires: now.Add(ep.conf.Lifetime)}
+	ep.mutex.Unlock()
+
+	go ep.handleRequest(key, msg.Data)
+	return nil
+}
+
+// handleRequest calls the Handler, remembers its response and sends it.
+func (ep *Endpoint) handleRequest(key answerKey, request []byte) {
+	response := message{Kind: kindResponse, ID: key.id}
+	if data, err := ep.handler(key.source, request); err != nil {
+		response.Kind, response.Data = kindError, []byte(err.Error())
+	} else {
+		response.Data = data
+	}
+
+	ep.mutex.Lock()
+	if known, ok := ep.answered[key]; ok {
+		known.response = &response
+	}
+	ep.mutex.Unlock()
+
+	if err := ep.sendMessage(key.source, response); err != nil {
+		log.WithFields(log.Fields{
+			"endpoint":    ep.endpoint,
+			"destination": key.source,
+			"error":       err,
+		}).Warn("Failed to send response")
+	}
+}
+
+// Endpoints returns the Endpoint's endpoint ID.
+func (ep *Endpoint) Endpoints() []bpv7.EndpointID {
+	return []bpv7.EndpointID{ep.endpoint}
+}
+
+// Deliver passes a received request or response to the Endpoint.
+func (ep *Endpoint) Deliver(bundleDescriptor *store.BundleDescriptor) error {
+	if bundleDescriptor.Destination != ep.endpoint {
+		return nil
+	}
+
+	bndl, err := bundleDescriptor.Load()
+	if err != nil {
+		return err
+	}
+	return ep.receive(&bndl)
+}
+
+// Shutdown lets all pending requests fail with ErrShutdown.
+func (ep *Endpoint) Shutdown() {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	ep.closed = true
+	for id, responses := range ep.pending {
+		close(responses)
+		delete(ep.pending, id)
+	}
+}
+
+func (ep *Endpoint) String() string {
+	return fmt.Sprintf("RequestResponseEndpoint(%v)", ep.endpoint)
+}
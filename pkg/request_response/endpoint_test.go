@@ -0,0 +1,211 @@
+package request_response
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+func TestMessageCbor(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		msgIn := message{
+			Kind: messageKind(rapid.IntRange(int(kindRequest), int(kindError)).Draw(t, "kind")),
+			ID:   rapid.Uint64().Draw(t, "id"),
+			Data: rapid.SliceOf(rapid.Byte()).Draw(t, "data"),
+		}
+
+		data, err := marshalMessage(msgIn)
+		if err != nil {
+			t.Fatalf("Encoding failed: %v", err)
+		}
+		msgOut, err := unmarshalMessage(data)
+		if err != nil {
+			t.Fatalf("Decoding failed: %v", err)
+		}
+
+		if msgIn.Kind != msgOut.Kind || msgIn.ID != msgOut.ID || !bytes.Equal(msgIn.Data, msgOut.Data) {
+			t.Fatalf("Decoded message differs: %v became %v", msgIn, msgOut)
+		}
+	})
+}
+
+// testNetwork passes the bundles between its Endpoints, unless its drop function says otherwise.
+type testNetwork struct {
+	mutex     sync.Mutex
+	endpoints map[bpv7.EndpointID]*Endpoint
+	sent      []message
+	drop      func(n int, msg message) bool
+}
+
+func (network *testNetwork) add(t *testing.T, eid string, conf Config, handler Handler) *Endpoint {
+	ep, err := NewEndpoint(bpv7.MustNewEndpointID(eid), conf, handler, network.send)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network.mutex.Lock()
+	defer network.mutex.Unlock()
+	if network.endpoints == nil {
+		network.endpoints = make(map[bpv7.EndpointID]*Endpoint)
+	}
+	network.endpoints[ep.endpoint] = ep
+	return ep
+}
+
+func (network *testNetwork) send(bndl *bpv7.Bundle) {
+	payload, _ := bndl.PayloadBlock()
+	msg, _ := unmarshalMessage(payload.Value.(*bpv7.PayloadBlock).Data())
+
+	network.mutex.Lock()
+	network.sent = append(network.sent, msg)
+	n := len(network.sent)
+	ep, ok := network.endpoints[bndl.PrimaryBlock.Destination]
+	network.mutex.Unlock()
+
+	if ok && (network.drop == nil || !network.drop(n, msg)) {
+		go func() { _ = ep.receive(bndl) }()
+	}
+}
+
+func (network *testNetwork) sentKinds() (kinds []messageKind) {
+	network.mutex.Lock()
+	defer network.mutex.Unlock()
+
+	for _, msg := range network.sent {
+		kinds = append(kinds, msg.Kind)
+	}
+	return
+}
+
+func TestRequest(t *testing.T) {
+	network := &testNetwork{}
+	client := network.add(t, "dtn://client/rpc", Config{}, nil)
+	network.add(t, "dtn://server/rpc", Config{}, func(source bpv7.EndpointID, request []byte) ([]byte, error) {
+		if len(request) == 0 {
+			return nil, fmt.Errorf("empty request")
+		}
+		return []byte(fmt.Sprintf("%s from %v", request, source)), nil
+	})
+
+	server := bpv7.MustNewEndpointID("dtn://server/rpc")
+	if response, err := client.Request(server, []byte("hello")); err != nil {
+		t.Fatal(err)
+	} else if string(response) != "hello from dtn://client/rpc" {
+		t.Fatalf("Unexpected response %q", response)
+	}
+
+	var remoteErr RemoteError
+	if _, err := client.Request(server, nil); !errors.As(err, &remoteErr) || string(remoteErr) != "empty request" {
+		t.Fatalf("Expected a RemoteError, got %v", err)
+	}
+}
+
+func TestRequestRetry(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	clock.SetClock(fakeClock)
+	defer clock.SetClock(clock.RealClock{})
+
+	tests := []struct {
+		name  string
+		drop  int
+		kinds []messageKind
+	}{
+		{"lost request", 1, []messageKind{kindRequest, kindRequest, kindResponse}},
+		{"lost response", 2, []messageKind{kindRequest, kindResponse, kindRequest, kindResponse}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			network := &testNetwork{drop: func(n int, _ message) bool { return n == test.drop }}
+			client := network.add(t, "dtn://client/rpc", Config{Timeout: time.Minute, Retries: 2}, nil)
+			network.add(t, "dtn://server/rpc", Config{Timeout: time.Minute}, func(_ bpv7.EndpointID, request []byte) ([]byte, error) {
+				calls++
+				return request, nil
+			})
+
+			done := make(chan error)
+			go func() {
+				_, err := client.Request(bpv7.MustNewEndpointID("dtn://server/rpc"), []byte("hello"))
+				done <- err
+			}()
+
+			// Wait for the first attempt's timer and the lost message.
+			fakeClock.BlockUntil(1)
+			for len(network.sentKinds()) < test.drop {
+				time.Sleep(time.Millisecond)
+			}
+			fakeClock.Advance(time.Minute)
+
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+			if calls != 1 {
+				t.Fatalf("Handler was called %d times", calls)
+			}
+			if kinds := network.sentKinds(); !reflect.DeepEqual(kinds, test.kinds) {
+				t.Fatalf("Sent messages %v instead of %v", kinds, test.kinds)
+			}
+		})
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	clock.SetClock(fakeClock)
+	defer clock.SetClock(clock.RealClock{})
+
+	network := &testNetwork{drop: func(int, message) bool { return true }}
+	client := network.add(t, "dtn://client/rpc", Config{Timeout: time.Minute, Retries: 2}, nil)
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Request(bpv7.MustNewEndpointID("dtn://server/rpc"), []byte("hello"))
+		done <- err
+	}()
+
+	for i := 0; i < 3; i++ {
+		fakeClock.BlockUntil(1)
+		fakeClock.Advance(time.Minute)
+	}
+
+	var timeoutErr *TimeoutError
+	if err := <-done; !errors.As(err, &timeoutErr) || timeoutErr.Attempts != 3 {
+		t.Fatalf("Expected a TimeoutError after 3 attempts, got %v", err)
+	}
+	if n := len(network.sentKinds()); n != 3 {
+		t.Fatalf("Sent %d instead of 3 requests", n)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	network := &testNetwork{drop: func(int, message) bool { return true }}
+	client := network.add(t, "dtn://client/rpc", Config{Timeout: time.Hour}, nil)
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Request(bpv7.MustNewEndpointID("dtn://server/rpc"), []byte("hello"))
+		done <- err
+	}()
+
+	for len(network.sentKinds()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	client.Shutdown()
+
+	if err := <-done; err != ErrShutdown {
+		t.Fatalf("Expected ErrShutdown, got %v", err)
+	}
+	if _, err := client.Request(bpv7.MustNewEndpointID("dtn://server/rpc"), nil); err != ErrShutdown {
+		t.Fatalf("Expected ErrShutdown, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package request_response
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/dtn7/cboring"
+)
+
+// messageKind distinguishes requests from their responses.
+type messageKind uint64
+
+const (
+	kindRequest messageKind = iota
+	kindResponse
+	// kindError is a response whose data is the error message of the responder's Handler.
+	kindError
+)
+
+func (kind messageKind) String() string {
+	switch kind {
+	case kindRequest:
+		return "request"
+	case kindResponse:
+		return "response"
+	case kindError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// message is the payload of a request or response bundle. The ID correlates a response with its request and stays the
+// same for all retries of a request.
+type message struct {
+	Kind messageKind
+	ID   uint64
+	Data []byte
+}
+
+// MarshalCbor creates a CBOR representation of a message.
+func (msg *message) MarshalCbor(w io.Writer) error {
+	if err := cboring.WriteArrayLength(3, w); err != nil {
+		return err
+	}
+	if err := cboring.WriteUInt(uint64(msg.Kind), w); err != nil {
+		return err
+	}
+	if err := cboring.WriteUInt(msg.ID, w); err != nil {
+		return err
+	}
+	return cboring.WriteByteString(msg.Data, w)
+}
+
+// UnmarshalCbor creates a message from its CBOR representation.
+func (msg *message) UnmarshalCbor(r io.Reader) error {
+	if l, err := cboring.ReadArrayLength(r); err != nil {
+		return err
+	} else if l != 3 {
+		return fmt.Errorf("wrong array length: %d instead of 3", l)
+	}
+
+	if n, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else if kind := messageKind(n); kind > kindError {
+		return fmt.Errorf("unknown message kind %d", n)
+	} else {
+		msg.Kind = kind
+	}
+	if id, err := cboring.ReadUInt(r); err != nil {
+		return err
+	} else {
+		msg.ID = id
+	}
+	if data, err := cboring.ReadByteString(r); err != nil {
+		return err
+	} else {
+		msg.Data = data
+	}
+
+	return nil
+}
+
+func (msg message) String() string {
+	return fmt.Sprintf("%v(%d,%d bytes)", msg.Kind, msg.ID, len(msg.Data))
+}
+
+// marshalMessage into a CBOR byte string.
+func marshalMessage(msg message) ([]byte, error) {
+	buff := new(bytes.Buffer)
+	if err := cboring.Marshal(&msg, buff); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// unmarshalMessage from a CBOR byte string.
+func unmarshalMessage(data []byte) (msg message, err error) {
+	err = cboring.Unmarshal(&msg, bytes.NewBuffer(data))
+	return
+}
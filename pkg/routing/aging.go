@@ -0,0 +1,85 @@
+package routing
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// AgingConfig throttles the replication of aging bundles, see SetAgingThrottle. Bundles unlikely to be delivered in
+// time get fewer copies, saving bandwidth for younger ones.
+type AgingConfig struct {
+	// Threshold is the fraction of a bundle's lifetime remaining, between zero and one, below which its copies are
+	// reduced, e.g., 0.5 for bundles past half of their lifetime.
+	Threshold float64
+	// Exponent shapes the curve from all copies at the threshold down to a single copy at the bundle's expiry: one
+	// decreases the copies linearly, a greater exponent decreases them early, a smaller one late.
+	Exponent float64
+}
+
+// CheckValid checks the threshold's range and that the exponent is positive.
+func (config AgingConfig) CheckValid() error {
+	if config.Threshold <= 0 || config.Threshold > 1 {
+		return fmt.Errorf("aging threshold %v must be within (0, 1]", config.Threshold)
+	}
+	if config.Exponent <= 0 {
+		return fmt.Errorf("aging exponent %v must be positive", config.Exponent)
+	}
+	return nil
+}
+
+// Copies of a bundle, with the remaining fraction of its lifetime, according to the curve.
+func (config AgingConfig) Copies(copies uint64, remaining float64) uint64 {
+	if copies <= 1 || remaining >= config.Threshold {
+		return copies
+	}
+
+	factor := math.Pow(math.Max(0, remaining)/config.Threshold, config.Exponent)
+	return uint64(math.Max(1, math.Round(float64(copies)*factor)))
+}
+
+var (
+	agingThrottleMutex sync.Mutex
+	agingThrottle      *AgingConfig
+)
+
+// SetAgingThrottle configures ThrottleCopiesByAge. Nil disables the throttle, which is also the default.
+func SetAgingThrottle(config *AgingConfig) {
+	agingThrottleMutex.Lock()
+	defer agingThrottleMutex.Unlock()
+
+	agingThrottle = config
+}
+
+// ThrottleCopiesByAge adapts a replication-based algorithm's number of copies to the bundle's remaining lifetime, as
+// configured by SetAgingThrottle. Like ScaleCopies, at least a single copy is kept. Thus, a bundle close to its expiry
+// is still forwarded, but no longer sprayed to many peers.
+func ThrottleCopiesByAge(descriptor *store.BundleDescriptor, copies uint64) uint64 {
+	agingThrottleMutex.Lock()
+	config := agingThrottle
+	agingThrottleMutex.Unlock()
+
+	if config == nil || copies <= 1 {
+		return copies
+	}
+
+	bundle, err := descriptor.Load()
+	if err != nil {
+		log.WithError(err).WithField("bundle", descriptor.ID).Warn("Failed to load bundle to throttle its copies")
+		return copies
+	}
+	lifetime := time.Duration(bundle.PrimaryBlock.Lifetime) * time.Millisecond
+	if lifetime <= 0 {
+		return 1
+	}
+
+	// the store's expiry also covers bundles without a creation time, e.g., of clockless nodes
+	remaining := descriptor.Expires.Sub(clock.Now())
+	return config.Copies(copies, float64(remaining)/float64(lifetime))
+}
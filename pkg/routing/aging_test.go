@@ -0,0 +1,33 @@
+package routing
+
+import "testing"
+
+func TestAgingConfigCopies(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    AgingConfig
+		copies    uint64
+		remaining float64
+		expected  uint64
+	}{
+		{"young bundle", AgingConfig{Threshold: 0.5, Exponent: 1}, 8, 0.9, 8},
+		{"at the threshold", AgingConfig{Threshold: 0.5, Exponent: 1}, 8, 0.5, 8},
+		{"linear", AgingConfig{Threshold: 0.5, Exponent: 1}, 8, 0.25, 4},
+		{"whole lifetime", AgingConfig{Threshold: 1, Exponent: 1}, 8, 0.75, 6},
+		{"early decrease", AgingConfig{Threshold: 0.5, Exponent: 2}, 8, 0.25, 2},
+		{"late decrease", AgingConfig{Threshold: 0.5, Exponent: 0.5}, 8, 0.125, 4},
+		{"rounded to zero", AgingConfig{Threshold: 0.5, Exponent: 1}, 8, 0.01, 1},
+		{"expiry", AgingConfig{Threshold: 0.5, Exponent: 1}, 8, 0, 1},
+		{"expired", AgingConfig{Threshold: 0.5, Exponent: 1}, 8, -0.1, 1},
+		{"single copy", AgingConfig{Threshold: 0.5, Exponent: 1}, 1, 0.1, 1},
+		{"no copies", AgingConfig{Threshold: 0.5, Exponent: 1}, 0, 0.1, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if copies := test.config.Copies(test.copies, test.remaining); copies != test.expected {
+				t.Fatalf("Expected %d copies, got %d", test.expected, copies)
+			}
+		})
+	}
+}
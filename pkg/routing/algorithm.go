@@ -2,12 +2,15 @@ package routing
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
 	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/contact_plan"
+	"github.com/dtn7/dtn7-go/pkg/reputation"
 	"github.com/dtn7/dtn7-go/pkg/store"
 	"github.com/dtn7/dtn7-go/pkg/util"
 )
@@ -16,12 +19,18 @@ type AlgorithmEnum int
 
 const (
 	Epidemic AlgorithmEnum = iota
+	ContactGraph
+	SprayAndWait
 )
 
 func AlgorithmEnumFromString(name string) (AlgorithmEnum, error) {
 	switch name = strings.ToLower(name); name {
 	case "epidemic":
 		return Epidemic, nil
+	case "contact_graph":
+		return ContactGraph, nil
+	case "spray_and_wait":
+		return SprayAndWait, nil
 	default:
 		return 0, fmt.Errorf("%s is not a valid algorithm name", name)
 	}
@@ -59,17 +68,41 @@ func NewNoSuchAlgorithmError(algorithm AlgorithmEnum) *NoSuchAlgorithmError {
 	return &err
 }
 
-func InitialiseAlgorithm(algorithm AlgorithmEnum) error {
+// InitialiseAlgorithm initialises the routing algorithm singleton for this node. The ContactGraph algorithm requires
+// the contact plan singleton to be initialised. The SprayAndWait algorithm creates this number of copies.
+func InitialiseAlgorithm(algorithm AlgorithmEnum, nodeID bpv7.EndpointID, copies uint64) error {
 	if algorithmSingleton != nil {
 		return util.NewAlreadyInitialisedError("Routing Algorithm")
 	}
 
-	if algorithm == Epidemic {
+	switch algorithm {
+	case Epidemic:
 		algorithmSingleton = NewEpidemicRouting()
-		return nil
+	case ContactGraph:
+		algorithmSingleton = NewContactGraphRouting(nodeID, contact_plan.GetContactPlanSingleton())
+	case SprayAndWait:
+		algorithmSingleton = NewSprayAndWaitRouting(nodeID, copies)
+	default:
+		return NewNoSuchAlgorithmError(algorithm)
 	}
+	return nil
+}
 
-	return NewNoSuchAlgorithmError(algorithm)
+// InitialiseCustomAlgorithm initialises the routing algorithm singleton by an Algorithm implemented outside this
+// package, e.g., a test double or an experimental algorithm.
+func InitialiseCustomAlgorithm(algorithm Algorithm) error {
+	if algorithmSingleton != nil {
+		return util.NewAlreadyInitialisedError("Routing Algorithm")
+	}
+
+	algorithmSingleton = algorithm
+	return nil
+}
+
+// ShutdownAlgorithm resets the routing algorithm singleton, such that another one can be initialised, e.g., by the
+// next test.
+func ShutdownAlgorithm() {
+	algorithmSingleton = nil
 }
 
 // GetAlgorithmSingleton returns the routing algorithm singleton-instance.
@@ -81,15 +114,15 @@ func GetAlgorithmSingleton() Algorithm {
 	return algorithmSingleton
 }
 
-// filterCLAs filters the nodes which already received a Bundle.
-// It returns a list of unused ConvergenceSenders.
+// filterCLAs filters the nodes which already received a Bundle, were marked as down by liveness probes or are
+// quarantined due to their reputation. It returns a list of unused ConvergenceSenders.
 func filterCLAs(bundleDescriptor *store.BundleDescriptor, clas []cla.ConvergenceSender) (filtered []cla.ConvergenceSender) {
 	filtered = make([]cla.ConvergenceSender, 0, len(clas))
 
 	sentEids := bundleDescriptor.GetAlreadySent()
 
 	for _, cs := range clas {
-		skip := false
+		skip := cla.GetManagerSingleton().PeerDown(cs.GetPeerEndpointID()) || reputation.Quarantined(cs.GetPeerEndpointID())
 
 		for _, eid := range sentEids {
 			if cs.GetPeerEndpointID() == eid {
@@ -105,3 +138,34 @@ func filterCLAs(bundleDescriptor *store.BundleDescriptor, clas []cla.Convergence
 
 	return
 }
+
+// uniquePeers keeps only the first ConvergenceSender of each peer.
+func uniquePeers(clas []cla.ConvergenceSender) []cla.ConvergenceSender {
+	endpoints := make(map[bpv7.EndpointID]bool)
+	unique := make([]cla.ConvergenceSender, 0, len(clas))
+	for _, sender := range clas {
+		if !endpoints[sender.GetPeerEndpointID()] {
+			endpoints[sender.GetPeerEndpointID()] = true
+			unique = append(unique, sender)
+		}
+	}
+	return unique
+}
+
+// SortByGoodput orders ConvergenceSenders by their peers' measured goodput, the fastest first. Peers without any
+// measurement are placed last, keeping their order. Quota-based algorithms might use this to prefer fast links when
+// only some copies are to be replicated.
+func SortByGoodput(clas []cla.ConvergenceSender) {
+	goodput := make(map[bpv7.EndpointID]float64, len(clas))
+	for _, cs := range clas {
+		if bytesPerSecond, known := cla.GetManagerSingleton().PeerGoodput(cs.GetPeerEndpointID()); known {
+			goodput[cs.GetPeerEndpointID()] = bytesPerSecond
+		} else {
+			goodput[cs.GetPeerEndpointID()] = -1
+		}
+	}
+
+	sort.SliceStable(clas, func(i, j int) bool {
+		return goodput[clas[i].GetPeerEndpointID()] > goodput[clas[j].GetPeerEndpointID()]
+	})
+}
@@ -0,0 +1,144 @@
+package routing
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/contact_plan"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// ContactGraphRouting is an Algorithm forwarding bundles along the route of the earliest arrival through the
+// ContactPlan, a simplified contact graph routing. Contacts' volumes and confidences are not considered.
+//
+// A bundle with a planned route is only forwarded to the route's first hop during its contact; otherwise, it is kept
+// until a later dispatch sweep. A bundle without any route arriving before its expiry is flooded epidemically, e.g.,
+// to peers not covered by the plan.
+type ContactGraphRouting struct {
+	nodeID   bpv7.EndpointID
+	plan     *contact_plan.ContactPlan
+	epidemic *EpidemicRouting
+}
+
+// NewContactGraphRouting creates a ContactGraphRouting for this node, querying the ContactPlan.
+func NewContactGraphRouting(nodeID bpv7.EndpointID, plan *contact_plan.ContactPlan) *ContactGraphRouting {
+	log.WithField("contacts", len(plan.Contacts())).Debug("Initialised contact graph routing")
+
+	return &ContactGraphRouting{
+		nodeID:   nodeID,
+		plan:     plan,
+		epidemic: NewEpidemicRouting(),
+	}
+}
+
+// route returns the first Contact of the earliest arriving route from one node to the destination's node, starting at
+// the given time and arriving before the deadline.
+func route(plan *contact_plan.ContactPlan, from, destination bpv7.EndpointID, now, deadline time.Time) (
+	first contact_plan.Contact, ok bool) {
+
+	type hop struct {
+		arrival time.Time
+		first   contact_plan.Contact
+		visited bool
+	}
+
+	contacts := plan.Contacts()
+	hops := map[string]*hop{penaltyKey(from): {arrival: now}}
+	nodes := map[string]bpv7.EndpointID{penaltyKey(from): from}
+
+	for {
+		// Dijkstra's algorithm on the nodes, ordered by their earliest arrival
+		var (
+			currentKey string
+			current    *hop
+		)
+		for key, h := range hops {
+			if !h.visited && (current == nil || h.arrival.Before(current.arrival)) {
+				currentKey, current = key, h
+			}
+		}
+		if current == nil {
+			return contact_plan.Contact{}, false
+		}
+		current.visited = true
+
+		if nodes[currentKey].SameNode(destination) {
+			return current.first, currentKey != penaltyKey(from)
+		}
+
+		for _, c := range contacts {
+			if penaltyKey(c.From) != currentKey || !c.End.After(current.arrival) {
+				continue
+			}
+
+			start := current.arrival
+			if c.Start.After(start) {
+				start = c.Start
+			}
+			arrival := start.Add(c.OneWayLightTime)
+			if arrival.After(deadline) {
+				continue
+			}
+
+			toKey := penaltyKey(c.To)
+			if next, known := hops[toKey]; known && (next.visited || !arrival.Before(next.arrival)) {
+				continue
+			}
+
+			firstContact := current.first
+			if currentKey == penaltyKey(from) {
+				firstContact = c
+			}
+			hops[toKey] = &hop{arrival: arrival, first: firstContact}
+			nodes[toKey] = c.To
+		}
+	}
+}
+
+// NotifyNewBundle is ignored, as routes are computed for each forwarding.
+func (cgr *ContactGraphRouting) NotifyNewBundle(_ *store.BundleDescriptor) {}
+
+func (cgr *ContactGraphRouting) SelectPeersForForwarding(bp *store.BundleDescriptor) (css []cla.ConvergenceSender) {
+	now := clock.Now()
+
+	first, ok := route(cgr.plan, cgr.nodeID, bp.Destination, now, bp.Expires)
+	if !ok {
+		log.WithField("bundle", bp.ID).Debug("ContactGraphRouting found no planned route, flooding the bundle")
+		return cgr.epidemic.SelectPeersForForwarding(bp)
+	}
+
+	if first.ActiveAt(now) {
+		for _, cs := range filterCLAs(bp, cla.GetManagerSingleton().GetSenders()) {
+			if cs.GetPeerEndpointID().SameNode(first.To) {
+				css = append(css, cs)
+				break
+			}
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":        bp.ID,
+		"next hop":      first.To,
+		"contact start": first.Start,
+		"new receivers": css,
+	}).Debug("ContactGraphRouting selected Convergence Senders for an outgoing bundle")
+
+	return
+}
+
+// NotifyFailureReport penalizes the reporting node for bundles flooded without a planned route.
+func (cgr *ContactGraphRouting) NotifyFailureReport(descriptor *store.BundleDescriptor, reportingNode bpv7.EndpointID, report *bpv7.StatusReport) {
+	cgr.epidemic.NotifyFailureReport(descriptor, reportingNode, report)
+}
+
+func (_ *ContactGraphRouting) NotifyPeerAppeared(_ bpv7.EndpointID) {}
+
+func (_ *ContactGraphRouting) NotifyPeerDisappeared(_ bpv7.EndpointID) {}
+
+func (_ *ContactGraphRouting) String() string {
+	return "contact graph"
+}
@@ -0,0 +1,59 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/contact_plan"
+)
+
+func TestContactGraphRoute(t *testing.T) {
+	epoch := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	plan, err := contact_plan.ParseContactPlan(strings.NewReader(`
+a contact +0 +100 1 2 1000
+a contact +200 +300 2 4 1000
+a contact +50 +100 1 3 1000
+a contact +100 +150 3 4 1000
+a range +0 +3600 3 4 10
+`), epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(seconds int) time.Time {
+		return epoch.Add(time.Duration(seconds) * time.Second)
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		now         time.Time
+		deadline    time.Time
+		ok          bool
+		firstHop    string
+	}{
+		{"direct contact", "ipn:2.1", at(0), at(1000), true, "ipn:2.1"},
+		{"earliest arrival wins", "ipn:4.1", at(0), at(1000), true, "ipn:3.1"},
+		{"other service of the node", "ipn:4.7", at(0), at(1000), true, "ipn:3.1"},
+		{"deadline excludes later routes", "ipn:4.1", at(0), at(150), true, "ipn:3.1"},
+		{"light time exceeds deadline", "ipn:4.1", at(0), at(105), false, ""},
+		{"contacts already over", "ipn:4.1", at(120), at(1000), false, ""},
+		{"unknown node", "ipn:5.1", at(0), at(1000), false, ""},
+		{"own node", "ipn:1.1", at(0), at(1000), false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first, ok := route(plan, bpv7.MustNewEndpointID("ipn:1.1"), bpv7.MustNewEndpointID(test.destination),
+				test.now, test.deadline)
+			if ok != test.ok {
+				t.Fatalf("Expected route %t, got %t with %v", test.ok, ok, first)
+			}
+			if ok && !first.To.SameNode(bpv7.MustNewEndpointID(test.firstHop)) {
+				t.Fatalf("Expected first hop %s, got %v", test.firstHop, first.To)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// NetworkCost assigns a cost to all CLAs whose peer address lies within the network.
+type NetworkCost struct {
+	Network *net.IPNet
+	Cost    uint
+}
+
+// CostConfig assigns administrative costs to peers, e.g., high costs to a metered cellular link and low costs to a
+// LAN, see SetPeerCosts.
+type CostConfig struct {
+	// Default is the cost of peers without any other assigned cost.
+	Default uint
+	// Peers assigns costs to nodes, which take precedence over their CLAs' networks.
+	Peers map[bpv7.EndpointID]uint
+	// Networks assigns costs to CLAs by their peer address. The first matching network counts.
+	Networks []NetworkCost
+	// Expensive is the cost from which on peers are only used if necessary, zero if no peer is considered expensive.
+	Expensive uint
+	// Priority is the bundles' priority from which on expensive peers are used anyway, e.g., PriorityExpedited.
+	// As all bundles are at least of PriorityBulk, its zero value always allows expensive peers.
+	Priority bpv7.Priority
+}
+
+// CheckValid checks that there are costs beyond the default and that all networks are set.
+func (config CostConfig) CheckValid() error {
+	if len(config.Peers) == 0 && len(config.Networks) == 0 {
+		return fmt.Errorf("peer costs neither assign costs to peers nor to networks")
+	}
+	for _, network := range config.Networks {
+		if network.Network == nil {
+			return fmt.Errorf("peer costs contain an empty network")
+		}
+	}
+	return nil
+}
+
+var (
+	peerCostsMutex sync.Mutex
+	peerCosts      *CostConfig
+)
+
+// SetPeerCosts configures the peers' costs which ApplyPeerCosts weighs the Algorithm's selection with. Nil disables
+// peer costs, which is also the default.
+func SetPeerCosts(config *CostConfig) {
+	peerCostsMutex.Lock()
+	defer peerCostsMutex.Unlock()
+
+	peerCosts = config
+}
+
+// claHost extracts the IP address from a CLA's address, e.g., "mtcp://10.0.0.2:35037" or "10.0.0.2:35037". It is nil
+// for addresses without an IP address, e.g., a hostname.
+func claHost(address string) net.IP {
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	address = strings.TrimSuffix(address, "/")
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return net.ParseIP(address)
+}
+
+// cost of a ConvergenceSender, either by its peer, by its address' network or the default.
+func (config CostConfig) cost(cs cla.ConvergenceSender) uint {
+	key := penaltyKey(cs.GetPeerEndpointID())
+	for peer, cost := range config.Peers {
+		if penaltyKey(peer) == key {
+			return cost
+		}
+	}
+
+	if host := claHost(cs.Address()); host != nil {
+		for _, network := range config.Networks {
+			if network.Network.Contains(host) {
+				return network.Cost
+			}
+		}
+	}
+	return config.Default
+}
+
+// ApplyPeerCosts orders the selected ConvergenceSenders by their costs, the cheapest first, and drops expensive peers
+// unless they are necessary. An expensive peer is necessary if there is no cheaper peer selected, if it is the bundle's
+// destination node, or if the bundle's priority is high enough.
+func ApplyPeerCosts(descriptor *store.BundleDescriptor, clas []cla.ConvergenceSender) []cla.ConvergenceSender {
+	peerCostsMutex.Lock()
+	config := peerCosts
+	peerCostsMutex.Unlock()
+
+	if config == nil || len(clas) == 0 {
+		return clas
+	}
+
+	type costedSender struct {
+		cs   cla.ConvergenceSender
+		cost uint
+	}
+	sorted := make([]costedSender, 0, len(clas))
+	for _, cs := range clas {
+		sorted = append(sorted, costedSender{cs: cs, cost: config.cost(cs)})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].cost < sorted[j].cost })
+
+	// Unless the cheapest peer is expensive as well or expensive peers are allowed for this bundle, they are dropped.
+	allowExpensive := config.Expensive == 0 || sorted[0].cost >= config.Expensive ||
+		descriptor.Priority >= config.Priority
+	destination := penaltyKey(descriptor.Destination)
+
+	selected := make([]cla.ConvergenceSender, 0, len(sorted))
+	for _, s := range sorted {
+		if allowExpensive || s.cost < config.Expensive || penaltyKey(s.cs.GetPeerEndpointID()) == destination {
+			selected = append(selected, s.cs)
+		}
+	}
+	if len(selected) == len(sorted) {
+		return selected
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":  descriptor.ID,
+		"avoided": len(sorted) - len(selected),
+	}).Debug("Peer costs avoided expensive peers")
+	return selected
+}
@@ -0,0 +1,138 @@
+package routing
+
+import (
+	"net"
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// addressSender is a ConvergenceSender only knowing its peer and address.
+type addressSender struct {
+	peerSender
+	address string
+}
+
+func (sender addressSender) Address() string {
+	return sender.address
+}
+
+func TestClaHost(t *testing.T) {
+	tests := []struct {
+		address string
+		// host is the expected IP address, empty if there is none
+		host string
+	}{
+		{"mtcp://10.0.0.2:35037", "10.0.0.2"},
+		{"10.0.0.2:35037", "10.0.0.2"},
+		{"quicl://[fe80::1]:35037/", "fe80::1"},
+		{"10.0.0.2", "10.0.0.2"},
+		{"mtcp://example.org:35037", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			host := claHost(test.address)
+			if test.host == "" {
+				if host != nil {
+					t.Fatalf("Expected no host, got %v", host)
+				}
+				return
+			}
+
+			if !host.Equal(net.ParseIP(test.host)) {
+				t.Fatalf("Expected host %s, got %v", test.host, host)
+			}
+		})
+	}
+}
+
+func TestCostConfigCheckValid(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.168.0.0/16")
+
+	tests := []struct {
+		name   string
+		config CostConfig
+		valid  bool
+	}{
+		{"only a default", CostConfig{Default: 1}, false},
+		{"peers", CostConfig{Peers: map[bpv7.EndpointID]uint{bpv7.MustNewEndpointID("dtn://a/"): 1}}, true},
+		{"networks", CostConfig{Networks: []NetworkCost{{Network: lan, Cost: 1}}}, true},
+		{"empty network", CostConfig{Networks: []NetworkCost{{Cost: 1}}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.CheckValid(); (err == nil) != test.valid {
+				t.Fatalf("Expected validity %t, got %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestApplyPeerCosts(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.168.0.0/16")
+	_, cellular, _ := net.ParseCIDR("10.0.0.0/8")
+
+	sender := func(peer, address string) cla.ConvergenceSender {
+		return addressSender{peerSender: peerSender{peer: bpv7.MustNewEndpointID(peer)}, address: address}
+	}
+	lanPeer := sender("dtn://lan/", "mtcp://192.168.1.2:35037")
+	cellularPeer := sender("dtn://cellular/", "mtcp://10.0.0.2:35037")
+	knownPeer := sender("dtn://known/", "mtcp://10.0.0.3:35037")
+	otherPeer := sender("dtn://other/", "mtcp://172.16.0.2:35037")
+
+	config := &CostConfig{
+		Default:   5,
+		Peers:     map[bpv7.EndpointID]uint{bpv7.MustNewEndpointID("dtn://known/inbox"): 2},
+		Networks:  []NetworkCost{{Network: lan, Cost: 1}, {Network: cellular, Cost: 10}},
+		Expensive: 10,
+		Priority:  bpv7.PriorityExpedited,
+	}
+
+	tests := []struct {
+		name        string
+		config      *CostConfig
+		clas        []cla.ConvergenceSender
+		destination string
+		priority    bpv7.Priority
+		peers       []string
+	}{
+		{"disabled", nil, []cla.ConvergenceSender{cellularPeer, lanPeer}, "dtn://dst/", bpv7.PriorityBulk,
+			[]string{"dtn://cellular/", "dtn://lan/"}},
+		{"cheapest first", config, []cla.ConvergenceSender{otherPeer, knownPeer, lanPeer}, "dtn://dst/",
+			bpv7.PriorityBulk, []string{"dtn://lan/", "dtn://known/", "dtn://other/"}},
+		{"expensive avoided", config, []cla.ConvergenceSender{cellularPeer, lanPeer}, "dtn://dst/",
+			bpv7.PriorityBulk, []string{"dtn://lan/"}},
+		{"expensive destination", config, []cla.ConvergenceSender{cellularPeer, lanPeer}, "dtn://cellular/inbox",
+			bpv7.PriorityBulk, []string{"dtn://lan/", "dtn://cellular/"}},
+		{"expensive priority", config, []cla.ConvergenceSender{cellularPeer, lanPeer}, "dtn://dst/",
+			bpv7.PriorityExpedited, []string{"dtn://lan/", "dtn://cellular/"}},
+		{"only expensive", config, []cla.ConvergenceSender{cellularPeer}, "dtn://dst/", bpv7.PriorityBulk,
+			[]string{"dtn://cellular/"}},
+		{"none", config, nil, "dtn://dst/", bpv7.PriorityBulk, nil},
+	}
+
+	t.Cleanup(func() { SetPeerCosts(nil) })
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetPeerCosts(test.config)
+
+			descriptor := &store.BundleDescriptor{
+				Destination: bpv7.MustNewEndpointID(test.destination),
+				Priority:    test.priority,
+			}
+			selected := ApplyPeerCosts(descriptor, test.clas)
+			if len(selected) != len(test.peers) {
+				t.Fatalf("Expected peers %v, got %v", test.peers, selected)
+			}
+			for i, cs := range selected {
+				if peer := cs.GetPeerEndpointID().String(); peer != test.peers[i] {
+					t.Fatalf("Expected peers %v, got %s at %d", test.peers, peer, i)
+				}
+			}
+		})
+	}
+}
@@ -14,15 +14,18 @@ import (
 )
 
 // EpidemicRouting is an implementation of an Algorithm and behaves in a
-// flooding-based epidemic way.
-type EpidemicRouting struct{}
+// flooding-based epidemic way. Peers which reported failures are avoided
+// for a while, as long as there are other peers.
+type EpidemicRouting struct {
+	penalties *peerPenalties
+}
 
 // NewEpidemicRouting creates a new EpidemicRouting Algorithm interacting
 // with the given Core.
 func NewEpidemicRouting() *EpidemicRouting {
 	log.Debug("Initialised epidemic routing")
 
-	return &EpidemicRouting{}
+	return &EpidemicRouting{penalties: newPeerPenalties()}
 }
 
 // NotifyNewBundle tells the EpidemicRouting about new bundles.
@@ -31,19 +34,7 @@ func NewEpidemicRouting() *EpidemicRouting {
 func (er *EpidemicRouting) NotifyNewBundle(_ *store.BundleDescriptor) {}
 
 func (er *EpidemicRouting) SelectPeersForForwarding(bp *store.BundleDescriptor) (css []cla.ConvergenceSender) {
-	css = filterCLAs(bp, cla.GetManagerSingleton().GetSenders())
-
-	endpoints := make(map[bpv7.EndpointID]bool)
-	unique := make([]cla.ConvergenceSender, 0, len(css))
-	for _, sender := range css {
-		_, present := endpoints[sender.GetPeerEndpointID()]
-		if !present {
-			endpoints[sender.GetPeerEndpointID()] = true
-			unique = append(unique, sender)
-		}
-	}
-
-	css = unique
+	css = er.penalties.avoid(uniquePeers(filterCLAs(bp, cla.GetManagerSingleton().GetSenders())))
 
 	log.WithFields(log.Fields{
 		"bundle":        bp.ID,
@@ -53,6 +44,12 @@ func (er *EpidemicRouting) SelectPeersForForwarding(bp *store.BundleDescriptor)
 	return
 }
 
+// NotifyFailureReport penalizes the reporting node, such that subsequent bundles prefer other peers.
+func (er *EpidemicRouting) NotifyFailureReport(_ *store.BundleDescriptor, reportingNode bpv7.EndpointID, _ *bpv7.StatusReport) {
+	log.WithField("node", reportingNode).Debug("EpidemicRouting penalizes node after failure report")
+	er.penalties.penalize(reportingNode)
+}
+
 func (_ *EpidemicRouting) NotifyPeerAppeared(_ bpv7.EndpointID) {}
 
 func (_ *EpidemicRouting) NotifyPeerDisappeared(_ bpv7.EndpointID) {}
@@ -0,0 +1,91 @@
+package routing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// FailureReportReceiver is an optional interface for Algorithms, which are notified of status reports indicating a
+// failed forwarding or deletion of a bundle still stored at or sourced by this node. The descriptor is nil if the
+// bundle is no longer stored. Afterwards, a stored bundle is forwarded again, allowing the Algorithm to reroute it.
+type FailureReportReceiver interface {
+	NotifyFailureReport(descriptor *store.BundleDescriptor, reportingNode bpv7.EndpointID, report *bpv7.StatusReport)
+}
+
+// IsFailureReport checks if a status report indicates a failure, i.e., a deletion or a forwarding failure reason.
+func IsFailureReport(report *bpv7.StatusReport) bool {
+	if report.StatusInformation[bpv7.DeletedBundle].Asserted {
+		return true
+	}
+
+	switch report.ReportReason {
+	case bpv7.TransmissionCanceled, bpv7.NoRouteToDestination, bpv7.NoNextNodeContact:
+		return true
+	default:
+		return false
+	}
+}
+
+// failurePenaltyDuration is how long a node is avoided after it reported a failure.
+const failurePenaltyDuration = 10 * time.Minute
+
+// peerPenalties tracks nodes which reported failures, until their penalty expires.
+type peerPenalties struct {
+	mutex sync.Mutex
+	until map[string]time.Time // node, by scheme and authority -> end of penalty
+}
+
+func newPeerPenalties() *peerPenalties {
+	return &peerPenalties{until: make(map[string]time.Time)}
+}
+
+// penaltyKey identifies an EndpointID's node, such that all its endpoints share a penalty.
+func penaltyKey(eid bpv7.EndpointID) string {
+	if eid.EndpointType == nil {
+		return ""
+	}
+	return eid.EndpointType.SchemeName() + "://" + eid.Authority()
+}
+
+// penalize the node of the EndpointID for the failurePenaltyDuration, prolonging an existing penalty.
+func (penalties *peerPenalties) penalize(eid bpv7.EndpointID) {
+	penalties.mutex.Lock()
+	defer penalties.mutex.Unlock()
+
+	penalties.until[penaltyKey(eid)] = clock.Now().Add(failurePenaltyDuration)
+}
+
+// penalized checks if the node of the EndpointID is currently penalized and forgets expired penalties.
+func (penalties *peerPenalties) penalized(eid bpv7.EndpointID) bool {
+	penalties.mutex.Lock()
+	defer penalties.mutex.Unlock()
+
+	key := penaltyKey(eid)
+	until, ok := penalties.until[key]
+	if ok && clock.Now().After(until) {
+		delete(penalties.until, key)
+		return false
+	}
+	return ok
+}
+
+// avoid removes ConvergenceSenders of penalized peers, unless all of them are penalized. Thus, a failing path is
+// only used if there is no alternative.
+func (penalties *peerPenalties) avoid(clas []cla.ConvergenceSender) []cla.ConvergenceSender {
+	preferred := make([]cla.ConvergenceSender, 0, len(clas))
+	for _, cs := range clas {
+		if !penalties.penalized(cs.GetPeerEndpointID()) {
+			preferred = append(preferred, cs)
+		}
+	}
+
+	if len(preferred) == 0 {
+		return clas
+	}
+	return preferred
+}
@@ -0,0 +1,122 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// LatencyConfig orders the peers by their measured links, see SetLatencyRouting. Interactive bundles prefer the peers
+// of the lowest latency, all other bundles the peers of the highest goodput.
+type LatencyConfig struct {
+	// Priority is the bundles' priority from which on they are interactive, e.g., PriorityExpedited.
+	Priority bpv7.Priority
+	// Spread drops peers whose latency exceeds the lowest latency by this factor for interactive bundles, unless they
+	// are the bundle's destination node. Zero keeps all peers.
+	Spread float64
+}
+
+// CheckValid checks that the spread, if set, does not drop the peer of the lowest latency itself.
+func (config LatencyConfig) CheckValid() error {
+	if config.Spread != 0 && config.Spread < 1 {
+		return fmt.Errorf("latency spread %v must be at least 1", config.Spread)
+	}
+	return nil
+}
+
+var (
+	latencyRoutingMutex sync.Mutex
+	latencyRouting      *LatencyConfig
+)
+
+// SetLatencyRouting configures ApplyPeerLatency. Nil disables the latency-aware routing, which is also the default.
+func SetLatencyRouting(config *LatencyConfig) {
+	latencyRoutingMutex.Lock()
+	defer latencyRoutingMutex.Unlock()
+
+	latencyRouting = config
+}
+
+// ApplyPeerLatency orders the selected ConvergenceSenders by their links, as measured by the CLA manager. Interactive
+// bundles are sent to the peers of the lowest latency first and skip slow peers as configured by the spread. All other
+// bundles are sent to the peers of the highest goodput first. Unmeasured peers are kept last in their given order.
+func ApplyPeerLatency(descriptor *store.BundleDescriptor, clas []cla.ConvergenceSender) []cla.ConvergenceSender {
+	latencyRoutingMutex.Lock()
+	config := latencyRouting
+	latencyRoutingMutex.Unlock()
+
+	if config == nil || len(clas) < 2 {
+		return clas
+	}
+
+	if descriptor.Priority < config.Priority {
+		return orderByGoodput(clas)
+	}
+
+	type measuredSender struct {
+		cs      cla.ConvergenceSender
+		latency time.Duration
+		known   bool
+	}
+	sorted := make([]measuredSender, 0, len(clas))
+	for _, cs := range clas {
+		latency, known := cla.GetManagerSingleton().PeerLatency(cs.GetPeerEndpointID())
+		sorted = append(sorted, measuredSender{cs: cs, latency: latency, known: known})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].known != sorted[j].known {
+			return sorted[i].known
+		}
+		return sorted[i].latency < sorted[j].latency
+	})
+
+	destination := penaltyKey(descriptor.Destination)
+	selected := make([]cla.ConvergenceSender, 0, len(sorted))
+	for _, s := range sorted {
+		slow := config.Spread != 0 && s.known && sorted[0].known &&
+			float64(s.latency) > float64(sorted[0].latency)*config.Spread
+		if !slow || penaltyKey(s.cs.GetPeerEndpointID()) == destination {
+			selected = append(selected, s.cs)
+		}
+	}
+	if len(selected) < len(sorted) {
+		log.WithFields(log.Fields{
+			"bundle":  descriptor.ID,
+			"avoided": len(sorted) - len(selected),
+		}).Debug("Latency-aware routing avoided slow peers for an interactive bundle")
+	}
+	return selected
+}
+
+// orderByGoodput orders ConvergenceSenders by their peers' goodput, the highest first.
+func orderByGoodput(clas []cla.ConvergenceSender) []cla.ConvergenceSender {
+	type measuredSender struct {
+		cs      cla.ConvergenceSender
+		goodput float64
+		known   bool
+	}
+	sorted := make([]measuredSender, 0, len(clas))
+	for _, cs := range clas {
+		goodput, known := cla.GetManagerSingleton().PeerGoodput(cs.GetPeerEndpointID())
+		sorted = append(sorted, measuredSender{cs: cs, goodput: goodput, known: known})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].known != sorted[j].known {
+			return sorted[i].known
+		}
+		return sorted[i].goodput > sorted[j].goodput
+	})
+
+	ordered := make([]cla.ConvergenceSender, 0, len(sorted))
+	for _, s := range sorted {
+		ordered = append(ordered, s.cs)
+	}
+	return ordered
+}
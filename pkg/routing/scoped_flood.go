@@ -0,0 +1,78 @@
+package routing
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// scopedFloodNode is the node name of all ScopedFloodEndpoints.
+const scopedFloodNode = "scoped-flood"
+
+// ScopedFloodEndpoint returns the non-singleton endpoint of a service's scoped floods, e.g.,
+// "dtn://scoped-flood/~naming". Each node's agent of this service registers this endpoint next to its own.
+func ScopedFloodEndpoint(service string) (bpv7.EndpointID, error) {
+	return bpv7.NewEndpointID(fmt.Sprintf("dtn://%s/~%s", scopedFloodNode, service))
+}
+
+// NewScopedFlood creates a bundle which is flooded to all nodes within the given number of hops, e.g., control
+// traffic of discovery or name resolution which is only relevant in a node's vicinity. Its Hop Count Block limits
+// the flood, as each forwarding node increments the hop count and drops the bundle once its limit is exceeded.
+//
+// A scoped flood is forwarded to all peers, regardless of the configured routing Algorithm. Thus, it reaches nodes
+// without knowing them, but never traverses the whole network.
+func NewScopedFlood(source bpv7.EndpointID, service string, hops uint8, lifetime time.Duration, payload []byte) (bpv7.Bundle, error) {
+	if hops == 0 {
+		return bpv7.Bundle{}, fmt.Errorf("scoped flood requires at least one hop")
+	}
+
+	destination, err := ScopedFloodEndpoint(service)
+	if err != nil {
+		return bpv7.Bundle{}, err
+	}
+
+	// A bundle sent to a neighbour has a hop count of one. Thus, the limit equals the hops.
+	return bpv7.Builder().
+		Source(source).
+		Destination(destination).
+		CreationTimestampNow().
+		Lifetime(lifetime).
+		HopCountBlock(int(hops)).
+		PayloadBlock(payload).
+		Build()
+}
+
+// IsScopedFlood checks if a bundle is a scoped flood, i.e., it is addressed to a non-singleton endpoint and carries a
+// Hop Count Block.
+func IsScopedFlood(bundle *bpv7.Bundle) bool {
+	return !bundle.PrimaryBlock.Destination.IsSingleton() && bundle.HasExtensionBlock(bpv7.ExtBlockTypeHopCountBlock)
+}
+
+// SelectScopedFloodPeers selects all peers for a scoped flood, except those it was already sent to or received from.
+// Without a scoped flood, flooded is false and the Algorithm should decide.
+func SelectScopedFloodPeers(descriptor *store.BundleDescriptor) (peers []cla.ConvergenceSender, flooded bool) {
+	bundle, err := descriptor.Load()
+	if err != nil || !IsScopedFlood(&bundle) {
+		return nil, false
+	}
+
+	seen := make(map[bpv7.EndpointID]bool)
+	for _, cs := range filterCLAs(descriptor, cla.GetManagerSingleton().GetSenders()) {
+		if !seen[cs.GetPeerEndpointID()] {
+			seen[cs.GetPeerEndpointID()] = true
+			peers = append(peers, cs)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"bundle": descriptor.ID,
+		"peers":  peers,
+	}).Debug("Scoped flood selected all peers")
+
+	return peers, true
+}
@@ -0,0 +1,46 @@
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// SelectSourceRoutePeers honours a bundle's optional SourceRouteBlock. If there is an unvisited waypoint, which is
+// not this node as identified by isOwnNode, routed is true and the peers are limited to those of the waypoint's node.
+// Until such a peer becomes available, no peers are returned and the bundle is kept. Without a SourceRouteBlock or
+// remaining waypoints, routed is false and the Algorithm should decide.
+func SelectSourceRoutePeers(descriptor *store.BundleDescriptor, isOwnNode func(bpv7.EndpointID) bool) (peers []cla.ConvergenceSender, routed bool) {
+	bundle, err := descriptor.Load()
+	if err != nil {
+		return nil, false
+	}
+
+	block, err := bundle.ExtensionBlock(bpv7.ExtBlockTypeSourceRouteBlock)
+	if err != nil {
+		return nil, false
+	}
+
+	sourceRoute := *block.Value.(*bpv7.SourceRouteBlock)
+	sourceRoute.Visit(isOwnNode)
+	waypoint, ok := sourceRoute.NextWaypoint()
+	if !ok {
+		return nil, false
+	}
+
+	for _, cs := range filterCLAs(descriptor, cla.GetManagerSingleton().GetSenders()) {
+		if cs.GetPeerEndpointID().SameNode(waypoint) {
+			peers = append(peers, cs)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"bundle":   descriptor.ID,
+		"waypoint": waypoint,
+		"peers":    peers,
+	}).Debug("Source route selected peers for the next waypoint")
+
+	return peers, true
+}
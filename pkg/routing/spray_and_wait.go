@@ -0,0 +1,92 @@
+package routing
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// DefaultSprayCopies is the number of copies of SprayAndWaitRouting, unless configured otherwise.
+const DefaultSprayCopies = 8
+
+// SprayAndWaitRouting is an Algorithm implementing the source variant of Spray and Wait. Out of a bundle's copies, the
+// source node keeps one and sprays the others to distinct peers, the ones of the highest goodput first. Afterwards, the
+// source and all relays wait until they meet the bundle's destination node, to which the bundle is forwarded directly.
+//
+// The copies are scaled down by the local storage pressure, see ScaleCopies, and by the bundle's age, see
+// ThrottleCopiesByAge. Thus, a nearly full store or an aging bundle sprays fewer copies, down to a single one, i.e.,
+// direct delivery.
+type SprayAndWaitRouting struct {
+	nodeID bpv7.EndpointID
+	copies uint64
+}
+
+// NewSprayAndWaitRouting creates a SprayAndWaitRouting for this node, creating this number of copies of its bundles.
+func NewSprayAndWaitRouting(nodeID bpv7.EndpointID, copies uint64) *SprayAndWaitRouting {
+	log.WithField("copies", copies).Debug("Initialised spray and wait routing")
+
+	return &SprayAndWaitRouting{
+		nodeID: nodeID,
+		copies: copies,
+	}
+}
+
+func (_ *SprayAndWaitRouting) NotifyNewBundle(_ *store.BundleDescriptor) {}
+
+func (sw *SprayAndWaitRouting) SelectPeersForForwarding(descriptor *store.BundleDescriptor) (css []cla.ConvergenceSender) {
+	senders := uniquePeers(filterCLAs(descriptor, cla.GetManagerSingleton().GetSenders()))
+	SortByGoodput(senders)
+
+	// relays only hold a single copy and wait for the destination
+	copies := uint64(1)
+	if descriptor.Source.SameNode(sw.nodeID) {
+		copies = ThrottleCopiesByAge(descriptor, ScaleCopies(sw.copies))
+	}
+
+	sprayed := uint64(0)
+	for _, eid := range descriptor.GetAlreadySent() {
+		if !eid.SameNode(sw.nodeID) {
+			sprayed++
+		}
+	}
+
+	css = sprayPeers(senders, descriptor.Destination, copies, sprayed)
+
+	log.WithFields(log.Fields{
+		"bundle":        descriptor.ID,
+		"copies":        copies,
+		"sprayed":       sprayed,
+		"new receivers": css,
+	}).Debug("SprayAndWaitRouting selected Convergence Senders for an outgoing bundle")
+
+	return
+}
+
+// sprayPeers selects the senders for a bundle of this number of copies, of which some were already sprayed. The
+// destination node's sender is selected exclusively. Otherwise, the first senders receive the remaining copies, except
+// for the one kept.
+func sprayPeers(senders []cla.ConvergenceSender, destination bpv7.EndpointID, copies, sprayed uint64) []cla.ConvergenceSender {
+	for _, sender := range senders {
+		if sender.GetPeerEndpointID().SameNode(destination) {
+			return []cla.ConvergenceSender{sender}
+		}
+	}
+
+	if copies <= sprayed+1 {
+		return nil
+	}
+	if remaining := copies - sprayed - 1; uint64(len(senders)) > remaining {
+		senders = senders[:remaining]
+	}
+	return senders
+}
+
+func (_ *SprayAndWaitRouting) NotifyPeerAppeared(_ bpv7.EndpointID) {}
+
+func (_ *SprayAndWaitRouting) NotifyPeerDisappeared(_ bpv7.EndpointID) {}
+
+func (_ *SprayAndWaitRouting) String() string {
+	return "spray_and_wait"
+}
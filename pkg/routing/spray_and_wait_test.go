@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+)
+
+// peerSender is a ConvergenceSender only knowing its peer.
+type peerSender struct {
+	cla.ConvergenceSender
+	peer bpv7.EndpointID
+}
+
+func (sender peerSender) GetPeerEndpointID() bpv7.EndpointID {
+	return sender.peer
+}
+
+func TestSprayPeers(t *testing.T) {
+	senders := []cla.ConvergenceSender{
+		peerSender{peer: bpv7.MustNewEndpointID("dtn://a/")},
+		peerSender{peer: bpv7.MustNewEndpointID("dtn://b/")},
+		peerSender{peer: bpv7.MustNewEndpointID("dtn://c/")},
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		copies      uint64
+		sprayed     uint64
+		peers       []string
+	}{
+		{"spray to all", "dtn://dst/", 8, 0, []string{"dtn://a/", "dtn://b/", "dtn://c/"}},
+		{"spray remaining copies", "dtn://dst/", 8, 5, []string{"dtn://a/", "dtn://b/"}},
+		{"all copies sprayed", "dtn://dst/", 8, 7, nil},
+		{"single copy waits", "dtn://dst/", 1, 0, nil},
+		{"destination exclusively", "dtn://b/inbox", 8, 0, []string{"dtn://b/"}},
+		{"destination after spraying", "dtn://c/inbox", 8, 7, []string{"dtn://c/"}},
+		{"destination of a single copy", "dtn://a/", 1, 0, []string{"dtn://a/"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected := sprayPeers(senders, bpv7.MustNewEndpointID(test.destination), test.copies, test.sprayed)
+			if len(selected) != len(test.peers) {
+				t.Fatalf("Expected peers %v, got %v", test.peers, selected)
+			}
+			for i, sender := range selected {
+				if peer := sender.GetPeerEndpointID().String(); peer != test.peers[i] {
+					t.Fatalf("Expected peers %v, got %s at %d", test.peers, peer, i)
+				}
+			}
+		})
+	}
+}
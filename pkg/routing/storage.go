@@ -0,0 +1,28 @@
+package routing
+
+import (
+	"math"
+
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// storagePressureThreshold is the store's Pressure above which ScaleCopies reduces replication.
+const storagePressureThreshold = 0.8
+
+// StoragePressure is the fraction of the local store's capacity in use, between zero and one.
+func StoragePressure() float64 {
+	return store.GetStoreSingleton().Occupancy().Pressure()
+}
+
+// ScaleCopies adapts a replication-based algorithm's number of copies to the local storage pressure. Below the
+// storagePressureThreshold, all copies are kept. Above, they decrease linearly down to a single copy for a full store.
+// Thus, an algorithm replicates less instead of filling up its own store until bundles have to be evicted.
+func ScaleCopies(copies uint64) uint64 {
+	pressure := StoragePressure()
+	if copies <= 1 || pressure <= storagePressureThreshold {
+		return copies
+	}
+
+	factor := (1 - pressure) / (1 - storagePressureThreshold)
+	return uint64(math.Max(1, math.Round(float64(copies)*factor)))
+}
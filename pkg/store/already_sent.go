@@ -0,0 +1,144 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// The already sent list of a BundleDescriptor grows with each peer a bundle was forwarded to. In long-lived epidemic
+// scenarios with many peers, this list might be bounded by SetAlreadySentLimit, pruning entries by a SentPruning
+// policy. The own node is never pruned.
+
+// SentPruning is the policy to bound an already sent list exceeding its limit.
+type SentPruning int
+
+const (
+	// PruneOldest evicts the peers recorded first, which might receive the bundle again.
+	PruneOldest SentPruning = iota
+	// PruneNewest stops recording further peers, which might receive the bundle again.
+	PruneNewest
+)
+
+// SentPruningFromString parses a SentPruning, "oldest" or "newest".
+func SentPruningFromString(s string) (SentPruning, error) {
+	switch s {
+	case "oldest":
+		return PruneOldest, nil
+	case "newest":
+		return PruneNewest, nil
+	default:
+		return PruneOldest, fmt.Errorf("unknown already sent pruning policy %q", s)
+	}
+}
+
+func (pruning SentPruning) String() string {
+	switch pruning {
+	case PruneOldest:
+		return "oldest"
+	case PruneNewest:
+		return "newest"
+	default:
+		return fmt.Sprintf("unknown SentPruning %d", int(pruning))
+	}
+}
+
+var (
+	alreadySentLimit   = 0
+	alreadySentPruning = PruneOldest
+)
+
+// SetAlreadySentLimit bounds the entries of each already sent list, including the own node, unbounded if zero.
+// Exceeding lists are pruned by the SentPruning policy.
+func SetAlreadySentLimit(limit int, pruning SentPruning) error {
+	if limit < 0 {
+		return fmt.Errorf("already sent limit %d is negative", limit)
+	}
+	alreadySentLimit = limit
+	alreadySentPruning = pruning
+	return nil
+}
+
+// AlreadySentLimit returns the configured bound of each already sent list, zero if unbounded.
+func AlreadySentLimit() int {
+	return alreadySentLimit
+}
+
+// SentList is an already sent list of EndpointIDs. It is stored compactly as a CBOR array of the EndpointIDs' CBOR
+// representation instead of gob's self-describing one.
+type SentList []bpv7.EndpointID
+
+// GobEncode serialises the SentList as CBOR.
+func (list SentList) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := cboring.WriteArrayLength(uint64(len(list)), &buf); err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if err := cboring.Marshal(&list[i], &buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode deserialises a SentList from its CBOR representation.
+func (list *SentList) GobDecode(data []byte) error {
+	buf := bytes.NewBuffer(data)
+	l, err := cboring.ReadArrayLength(buf)
+	if err != nil {
+		return err
+	}
+
+	decoded := make(SentList, l)
+	for i := range decoded {
+		if err := cboring.Unmarshal(&decoded[i], buf); err != nil {
+			return err
+		}
+	}
+	*list = decoded
+	return nil
+}
+
+// contains checks if the EndpointID is part of the SentList.
+func (list SentList) contains(eid bpv7.EndpointID) bool {
+	for _, sent := range list {
+		if sent == eid {
+			return true
+		}
+	}
+	return false
+}
+
+// add peers not yet part of the SentList and prune it to the limit, keeping the own node.
+func (list SentList) add(nodeID bpv7.EndpointID, limit int, pruning SentPruning, peers ...bpv7.EndpointID) SentList {
+	for _, peer := range peers {
+		if list.contains(peer) {
+			continue
+		}
+		if limit > 0 && len(list) >= limit {
+			if pruning == PruneNewest {
+				break
+			}
+			list = list.pruneOldest(nodeID)
+			if len(list) >= limit {
+				break
+			}
+		}
+		list = append(list, peer)
+	}
+	return list
+}
+
+// pruneOldest removes the first entry which is not the own node.
+func (list SentList) pruneOldest(nodeID bpv7.EndpointID) SentList {
+	for i, sent := range list {
+		if !sent.SameNode(nodeID) {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return list
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestAlreadySentLimit(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		limit := rapid.IntRange(1, 5).Draw(t, "Limit")
+		pruning := rapid.SampledFrom([]SentPruning{PruneOldest, PruneNewest}).Draw(t, "Pruning")
+		if err := SetAlreadySentLimit(limit, pruning); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = SetAlreadySentLimit(0, PruneOldest) }()
+
+		initTest(t)
+		defer cleanupTest(t)
+
+		nodeID := GetStoreSingleton().nodeID
+		bundle := bpv7.GenerateBundle(t, 0)
+		bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		peers := make([]bpv7.EndpointID, rapid.IntRange(1, 8).Draw(t, "Number of peers"))
+		for i := range peers {
+			peers[i] = bpv7.MustNewEndpointID(fmt.Sprintf("dtn://peer-%d/", i))
+			bd.AddAlreadySent(peers[i])
+			bd.AddAlreadySent(peers[i])
+		}
+
+		bdLoad, err := GetStoreSingleton().LoadBundleDescriptor(bundle.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent := bdLoad.GetAlreadySent()
+
+		expected := append([]bpv7.EndpointID{nodeID}, peers...)
+		if len(expected) > limit {
+			if pruning == PruneOldest {
+				expected = append([]bpv7.EndpointID{nodeID}, peers[len(peers)-limit+1:]...)
+			} else {
+				expected = expected[:limit]
+			}
+		}
+		if fmt.Sprint(sent) != fmt.Sprint(expected) {
+			t.Fatalf("Already sent list %v, expected %v", sent, expected)
+		}
+	})
+}
+
+func TestAlreadySentLegacy(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		nodeID := GetStoreSingleton().nodeID
+		peerA := bpv7.MustNewEndpointID("dtn://peer-a/")
+		peerB := bpv7.MustNewEndpointID("dtn://peer-b/")
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Store the list as a store created before SentTo did
+		bd.SentTo = nil
+		bd.AlreadySentTo = []bpv7.EndpointID{nodeID, peerA}
+		if err := GetStoreSingleton().updateBundleMetadata(bd); err != nil {
+			t.Fatal(err)
+		}
+
+		bdLoad, err := GetStoreSingleton().LoadBundleDescriptor(bundle.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		bdLoad.AddAlreadySent(peerA, peerB)
+
+		bdLoad, err = GetStoreSingleton().LoadBundleDescriptor(bundle.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bdLoad.AlreadySentTo) != 0 {
+			t.Fatalf("Legacy already sent list %v was not merged", bdLoad.AlreadySentTo)
+		}
+		if sent := bdLoad.GetAlreadySent(); fmt.Sprint(sent) != fmt.Sprint([]bpv7.EndpointID{nodeID, peerA, peerB}) {
+			t.Fatalf("Already sent list %v misses entries", sent)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// bloomFilter is a simple, thread-safe Bloom filter for bundle ID strings.
+//
+// It is used by the BundleStore to quickly decide whether a bundle is definitely unknown, which is the case for
+// most received bundles. Only on a probable hit does the store need to consult its metadata database.
+type bloomFilter struct {
+	mutex  sync.RWMutex
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// newBloomFilter creates a bloomFilter dimensioned for the expected number of elements and false positive rate.
+func newBloomFilter(expectedElements uint64, falsePositiveRate float64) *bloomFilter {
+	if expectedElements == 0 {
+		expectedElements = 1
+	}
+
+	// optimal number of bits m = -(n * ln p) / (ln 2)^2, optimal number of hash functions k = (m / n) * ln 2
+	size := uint64(math.Ceil(-float64(expectedElements) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Max(1, math.Round(float64(size)/float64(expectedElements)*math.Ln2)))
+
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// locations calculates the bit positions for some key by using double hashing of two FNV variants.
+func (bf *bloomFilter) locations(key string) []uint64 {
+	h1 := fnv.New64()
+	_, _ = h1.Write([]byte(key))
+	a := h1.Sum64()
+
+	h2 := fnv.New64a()
+	_, _ = h2.Write([]byte(key))
+	b := h2.Sum64()
+
+	locations := make([]uint64, bf.hashes)
+	for i := uint64(0); i < bf.hashes; i++ {
+		locations[i] = (a + i*b) % bf.size
+	}
+	return locations
+}
+
+// Add a key to this bloomFilter.
+func (bf *bloomFilter) Add(key string) {
+	locations := bf.locations(key)
+
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+
+	for _, location := range locations {
+		bf.bits[location/64] |= 1 << (location % 64)
+	}
+}
+
+// MayContain returns false if the key was definitely never added, true if it might have been added.
+func (bf *bloomFilter) MayContain(key string) bool {
+	locations := bf.locations(key)
+
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+
+	for _, location := range locations {
+		if bf.bits[location/64]&(1<<(location%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
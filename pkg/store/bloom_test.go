@@ -0,0 +1,44 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestBloomFilterNoFalseNegatives(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		filter := newBloomFilter(1000, 0.01)
+
+		keys := rapid.SliceOfN(rapid.String(), 1, 1000).Draw(t, "keys")
+		for _, key := range keys {
+			filter.Add(key)
+		}
+
+		for _, key := range keys {
+			if !filter.MayContain(key) {
+				t.Fatalf("Key %q was added but is not contained", key)
+			}
+		}
+	})
+}
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	filter := newBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add(fmt.Sprintf("added %d", i))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.MayContain(fmt.Sprintf("absent %d", i)) {
+			falsePositives++
+		}
+	}
+
+	// allow some slack above the configured rate of 1%
+	if rate := float64(falsePositives) / 10000; rate > 0.03 {
+		t.Fatalf("False positive rate %f is too high", rate)
+	}
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"errors"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -13,10 +14,17 @@ type BundleDescriptor struct {
 	Source      bpv7.EndpointID
 	Destination bpv7.EndpointID
 	ReportTo    bpv7.EndpointID
+	// Priority of the bundle, see DispatchQuery; stores created before this field list their bundles as bulk
+	Priority bpv7.Priority
+	// Size of the serialised bundle in bytes, e.g., to choose evictions without loading the bundles; stores created
+	// before this field list their bundles with a zero size. The creation time is part of the ID.
+	Size int64
 
 	Bundle *bpv7.Bundle
 
-	// node IDs of peers which already have this bundle
+	// node IDs of peers which already have this bundle, bounded by SetAlreadySentLimit
+	SentTo SentList
+	// AlreadySentTo is the uncompact already sent list of stores created before SentTo, merged into it when changed
 	AlreadySentTo []bpv7.EndpointID
 
 	// RetentionConstraints as defined by RFC9171 Section 5, see constraints.go for possible types
@@ -30,15 +38,25 @@ type BundleDescriptor struct {
 	Dispatch bool
 	// TTL after which the bundle will be deleted - assuming Retain == false
 	Expires time.Time
+	// Received is the local time of the bundle's insertion, i.e., the start of its dwell time on this node
+	Received time.Time
 	// filename of the serialised bundle on-disk
 	SerialisedFileName string
+	// arbitrary annotations, e.g., copy counts of routing algorithms or classifications of policies
+	// nil until the first value is set
+	Metadata map[string]string
+	// Attempts counts the processing attempts of this bundle which failed since the last finished one, e.g., because
+	// they panicked, see FailAttempt
+	Attempts uint
+	// Quarantined bundles are neither processed nor dispatched anymore, see Quarantine
+	Quarantined bool
 }
 
 func (bd *BundleDescriptor) Load() (bpv7.Bundle, error) {
 	if bd.Bundle != nil {
 		return *bd.Bundle, nil
 	}
-	bndle, err := GetStoreSingleton().loadEntireBundle(bd.SerialisedFileName)
+	bndle, err := GetStoreSingleton().loadEntireBundle(bd)
 	if err != nil {
 		return bpv7.Bundle{}, err
 	}
@@ -46,13 +64,29 @@ func (bd *BundleDescriptor) Load() (bpv7.Bundle, error) {
 	return *bndle, nil
 }
 
+// sentList returns the already sent list, including the entries of a store created before SentTo.
+func (bd *BundleDescriptor) sentList() SentList {
+	if len(bd.AlreadySentTo) == 0 {
+		return bd.SentTo
+	}
+	return SentList(bd.AlreadySentTo).add(bpv7.EndpointID{}, 0, PruneOldest, bd.SentTo...)
+}
+
+// GetAlreadySent returns the peers which already have this bundle.
 func (bd *BundleDescriptor) GetAlreadySent() []bpv7.EndpointID {
 	// TODO: refresh current state from db
-	return bd.AlreadySentTo
+	return bd.sentList()
 }
 
+// addSent adds peers to the already sent list without persisting it, pruned to the limit of SetAlreadySentLimit.
+func (bd *BundleDescriptor) addSent(nodeID bpv7.EndpointID, peers ...bpv7.EndpointID) {
+	bd.SentTo = bd.sentList().add(nodeID, alreadySentLimit, alreadySentPruning, peers...)
+	bd.AlreadySentTo = nil
+}
+
+// AddAlreadySent adds peers to the already sent list and persists it. Peers already part of the list are skipped.
 func (bd *BundleDescriptor) AddAlreadySent(peers ...bpv7.EndpointID) {
-	bd.AlreadySentTo = append(bd.AlreadySentTo, peers...)
+	bd.addSent(GetStoreSingleton().nodeID, peers...)
 	err := GetStoreSingleton().updateBundleMetadata(bd)
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -67,16 +101,57 @@ func (bd *BundleDescriptor) AddAlreadySent(peers ...bpv7.EndpointID) {
 	}
 }
 
+// RemoveAlreadySent removes peers from the already sent list, allowing the bundle to be forwarded to them again.
+// Without any peers, all entries are removed. The own node is never removed.
+// It returns true if the list was changed.
+func (bd *BundleDescriptor) RemoveAlreadySent(peers ...bpv7.EndpointID) (bool, error) {
+	nodeID := GetStoreSingleton().nodeID
+
+	sent := bd.sentList()
+	kept := make(SentList, 0, len(sent))
+	for _, eid := range sent {
+		if eid.SameNode(nodeID) || (len(peers) > 0 && !containsNode(peers, eid)) {
+			kept = append(kept, eid)
+		}
+	}
+	if len(kept) == len(sent) {
+		return false, nil
+	}
+
+	bd.SentTo = kept
+	bd.AlreadySentTo = nil
+	if err := GetStoreSingleton().updateBundleMetadata(bd); err != nil {
+		return false, err
+	}
+
+	log.WithFields(log.Fields{
+		"bundle": bd.IDString,
+		"peers":  peers,
+	}).Debug("Peers removed from already sent")
+	return true, nil
+}
+
+func containsNode(peers []bpv7.EndpointID, node bpv7.EndpointID) bool {
+	for _, peer := range peers {
+		if peer.SameNode(node) {
+			return true
+		}
+	}
+	return false
+}
+
 func (bd *BundleDescriptor) AddConstraint(constraint Constraint) error {
 	// check if value is valid constraint
-	if constraint < DispatchPending || constraint > ReassemblyPending {
+	if !constraint.Valid() {
 		return NewInvalidConstraint(constraint)
 	}
 
 	bd.RetentionConstraints = append(bd.RetentionConstraints, constraint)
 	bd.Retain = true
-	bd.Dispatch = constraint != ForwardPending
-	return GetStoreSingleton().updateBundleMetadata(bd)
+	if constraint != DeliveryPending {
+		bd.Dispatch = constraint != ForwardPending
+	}
+	return bd.updateConstraint(constraint)
 }
 
 func (bd *BundleDescriptor) RemoveConstraint(constraint Constraint) error {
@@ -88,14 +163,92 @@ func (bd *BundleDescriptor) RemoveConstraint(constraint Constraint) error {
 	}
 	bd.RetentionConstraints = constraints
 	bd.Retain = len(bd.RetentionConstraints) > 0
-	bd.Dispatch = constraint == ForwardPending
-	return GetStoreSingleton().updateBundleMetadata(bd)
+	if constraint != DeliveryPending {
+		bd.Dispatch = constraint == ForwardPending
+	}
+	return bd.updateConstraint(constraint)
+}
+
+// updateConstraint stores a changed constraint, which conflicts with the bundle's deletion in the meantime.
+func (bd *BundleDescriptor) updateConstraint(constraint Constraint) error {
+	err := GetStoreSingleton().updateBundleMetadata(bd)
+	if errors.Is(err, ErrNotFound) {
+		return NewConstraintConflictError(bd.IDString, constraint, err)
+	}
+	return err
 }
 
+// ResetConstraints removes all constraints of the bundle's forwarding. A pending delivery is kept.
 func (bd *BundleDescriptor) ResetConstraints() error {
+	constraints := make([]Constraint, 0)
+	for _, constraint := range bd.RetentionConstraints {
+		if constraint == DeliveryPending {
+			constraints = append(constraints, constraint)
+		}
+	}
+	bd.RetentionConstraints = constraints
+	bd.Retain = len(constraints) > 0
+	bd.Dispatch = true
+	return GetStoreSingleton().updateBundleMetadata(bd)
+}
+
+// GetMetadata returns the metadata value for this key, if it exists.
+func (bd *BundleDescriptor) GetMetadata(key string) (value string, ok bool) {
+	value, ok = bd.Metadata[key]
+	return
+}
+
+// GetAllMetadata returns a copy of all metadata annotations.
+func (bd *BundleDescriptor) GetAllMetadata() map[string]string {
+	metadata := make(map[string]string, len(bd.Metadata))
+	for key, value := range bd.Metadata {
+		metadata[key] = value
+	}
+	return metadata
+}
+
+// SetMetadata sets a metadata annotation and persists it in the store.
+func (bd *BundleDescriptor) SetMetadata(key, value string) error {
+	if bd.Metadata == nil {
+		bd.Metadata = make(map[string]string)
+	}
+	bd.Metadata[key] = value
+	return GetStoreSingleton().updateBundleMetadata(bd)
+}
+
+// DeleteMetadata removes a metadata annotation and persists this change in the store.
+func (bd *BundleDescriptor) DeleteMetadata(key string) error {
+	if _, ok := bd.Metadata[key]; !ok {
+		return nil
+	}
+	delete(bd.Metadata, key)
+	return GetStoreSingleton().updateBundleMetadata(bd)
+}
+
+// FailAttempt counts and persists a failed processing attempt, e.g., one which panicked. It returns the number of
+// failed attempts since the last finished one. A successful attempt does not touch the store.
+func (bd *BundleDescriptor) FailAttempt() (uint, error) {
+	bd.Attempts++
+	return bd.Attempts, GetStoreSingleton().updateBundleMetadata(bd)
+}
+
+// EndAttempt resets the counted failed processing attempts after one finished.
+func (bd *BundleDescriptor) EndAttempt() error {
+	if bd.Attempts == 0 {
+		return nil
+	}
+	bd.Attempts = 0
+	return GetStoreSingleton().updateBundleMetadata(bd)
+}
+
+// Quarantine keeps a bundle, e.g., one whose processing failed repeatedly, without processing or dispatching it
+// again. Its constraints are removed, but the bundle stays in the store for an inspection until it expires and is
+// deleted by the next Compact.
+func (bd *BundleDescriptor) Quarantine() error {
+	bd.Quarantined = true
 	bd.RetentionConstraints = make([]Constraint, 0)
 	bd.Retain = false
-	bd.Dispatch = true
+	bd.Dispatch = false
 	return GetStoreSingleton().updateBundleMetadata(bd)
 }
 
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+	"github.com/timshannon/badgerhold/v4"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// compactionDiscardRatio is the fraction of a value log file which must be garbage to be rewritten.
+const compactionDiscardRatio = 0.5
+
+// CompactionReport describes the outcome of Compact.
+type CompactionReport struct {
+	// ExpiredQuarantined is the number of deleted quarantined bundles whose lifetime was exceeded.
+	ExpiredQuarantined int
+	// OrphanedFiles is the number of removed serialised bundles without a BundleDescriptor, e.g., left by a crash.
+	OrphanedFiles int
+	// RewrittenLogs is the number of the metadata database's value log files rewritten without their garbage.
+	RewrittenLogs int
+	// KnownBundles is the number of stored bundles the Bloom filter of known bundles was rebuilt from.
+	KnownBundles int
+	// ReclaimedBytes is the decrease of the store's size on disk. It might be zero if bundles were inserted meanwhile.
+	ReclaimedBytes int64
+	// Duration of the compaction.
+	Duration time.Duration
+}
+
+// Compact reclaims the store's disk space. It deletes expired quarantined bundles, removes orphaned serialised bundles, compacts the metadata database's
+// tree, dropping the tombstones of deleted BundleDescriptors, and rewrites its value log files without garbage.
+// The serialised bundles themselves are one file each, whose layout is left to the file system. Furthermore, the
+// Bloom filter of known bundles is rebuilt without the deleted ones.
+//
+// Compaction runs alongside the regular operation, but only once at a time.
+// This method is thread-safe.
+func (bst *BundleStore) Compact() (report CompactionReport, err error) {
+	bst.compactionMutex.Lock()
+	defer bst.compactionMutex.Unlock()
+
+	start := clock.Now()
+
+	sizeBefore, err := bundleDirectorySize(bst.path)
+	if err != nil {
+		return
+	}
+
+	var errs error
+	expired, err := bst.deleteExpiredQuarantined()
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	report.ExpiredQuarantined = expired
+
+	knownBundles, err := bst.rebuildKnownBundles()
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	report.KnownBundles = knownBundles
+
+	for _, shard := range bst.shards {
+		orphanedFiles, err := bst.removeOrphanedFiles(shard)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		report.OrphanedFiles += orphanedFiles
+
+		rewrittenLogs, err := compactMetadata(shard)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		report.RewrittenLogs += rewrittenLogs
+	}
+
+	if sizeAfter, sizeErr := bundleDirectorySize(bst.path); sizeErr != nil {
+		errs = multierror.Append(errs, sizeErr)
+	} else if sizeAfter < sizeBefore {
+		report.ReclaimedBytes = sizeBefore - sizeAfter
+	}
+	report.Duration = clock.Now().Sub(start)
+
+	logger := log.WithFields(log.Fields{
+		"expired quarantined": report.ExpiredQuarantined,
+		"orphaned files":      report.OrphanedFiles,
+		"rewritten logs":      report.RewrittenLogs,
+		"known bundles":       report.KnownBundles,
+		"reclaimed bytes":     report.ReclaimedBytes,
+		"duration":            report.Duration,
+	})
+	if errs != nil {
+		logger.WithError(errs).Warn("Compacted store with errors")
+	} else {
+		logger.Info("Compacted store")
+	}
+
+	err = errs
+	return
+}
+
+// deleteExpiredQuarantined deletes all quarantined bundles whose lifetime was exceeded. Other bundles are deleted when
+// being dispatched after their expiry, which never happens to a quarantined one.
+func (bst *BundleStore) deleteExpiredQuarantined() (deleted int, err error) {
+	now := clock.Now()
+	bds, err := bst.find(func() *badgerhold.Query {
+		return badgerhold.Where("Quarantined").Eq(true).And("Expires").Lt(now)
+	})
+	if err != nil {
+		return
+	}
+
+	var errs error
+	for _, bd := range bds {
+		if delErr := bst.DeleteBundle(bd); delErr != nil {
+			errs = multierror.Append(errs, delErr)
+			continue
+		}
+		deleted++
+
+		log.WithField("bundle", bd.ID).Debug("Deleted expired quarantined bundle")
+	}
+
+	err = errs
+	return
+}
+
+// compactMetadata compacts a shard's metadata database's tree and rewrites its value log files without garbage.
+func compactMetadata(shard *storeShard) (rewrittenLogs int, err error) {
+	db := shard.metadataStore.Badger()
+	if flattenErr := db.Flatten(2); flattenErr != nil {
+		err = multierror.Append(err, flattenErr)
+	}
+	for {
+		if gcErr := db.RunValueLogGC(compactionDiscardRatio); gcErr != nil {
+			if !errors.Is(gcErr, badger.ErrNoRewrite) {
+				err = multierror.Append(err, gcErr)
+			}
+			break
+		}
+		rewrittenLogs++
+	}
+	return
+}
+
+// removeOrphanedFiles deletes all serialised bundles of a shard without a BundleDescriptor.
+//
+// The files are listed before the BundleDescriptors are loaded. As a BundleDescriptor is always inserted before its
+// serialised bundle is written, a concurrently inserted bundle is never mistaken for an orphan.
+func (bst *BundleStore) removeOrphanedFiles(shard *storeShard) (removed int, err error) {
+	entries, err := os.ReadDir(shard.bundleDirectory)
+	if err != nil {
+		return
+	}
+
+	bundles := make([]BundleDescriptor, 0)
+	if err = shard.metadataStore.Find(&bundles, nil); err != nil {
+		return
+	}
+	referenced := make(map[string]bool, len(bundles))
+	for _, bd := range bundles {
+		referenced[bd.SerialisedFileName] = true
+	}
+
+	var errs error
+	for _, entry := range entries {
+		if entry.IsDir() || referenced[entry.Name()] {
+			continue
+		}
+
+		info, infoErr := entry.Info()
+		if rmErr := os.Remove(filepath.Join(shard.bundleDirectory, entry.Name())); rmErr != nil {
+			errs = multierror.Append(errs, rmErr)
+			continue
+		}
+		if infoErr == nil {
+			bst.usedBytes.Add(-info.Size())
+		}
+		removed++
+
+		log.WithField("file", entry.Name()).Debug("Removed orphaned serialised bundle")
+	}
+
+	err = errs
+	return
+}
+
+// SetCompactionInterval schedules Compact periodically, disabled by a non-positive interval.
+// This method is thread-safe.
+func (bst *BundleStore) SetCompactionInterval(interval time.Duration) {
+	bst.compactionScheduleMutex.Lock()
+	defer bst.compactionScheduleMutex.Unlock()
+
+	if bst.compactionStop != nil {
+		close(bst.compactionStop)
+		bst.compactionStop = nil
+	}
+	if interval <= 0 {
+		return
+	}
+
+	bst.compactionStop = make(chan struct{})
+	go bst.compactionLoop(interval, bst.compactionStop)
+}
+
+func (bst *BundleStore) compactionLoop(interval time.Duration, stop <-chan struct{}) {
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C():
+			if _, err := bst.Compact(); err != nil {
+				log.WithError(err).Warn("Scheduled store compaction failed")
+			}
+		}
+	}
+}
@@ -19,6 +19,10 @@ const (
 	// ReassemblyPending is assigned to a fragmented bundle if its reassembly is
 	// pending.
 	ReassemblyPending Constraint = iota
+
+	// DeliveryPending is assigned to a bundle whose delivery to a local application agent was deferred, e.g., because
+	// a client's mailbox was full. It is not defined by RFC9171 and independent of the bundle's dispatch.
+	DeliveryPending Constraint = iota
 )
 
 func (c Constraint) String() string {
@@ -32,13 +36,16 @@ func (c Constraint) String() string {
 	case ReassemblyPending:
 		return "reassembly pending"
 
+	case DeliveryPending:
+		return "delivery pending"
+
 	default:
 		return "unknown"
 	}
 }
 
 func (c Constraint) Valid() bool {
-	return c >= DispatchPending && c <= ReassemblyPending
+	return c >= DispatchPending && c <= DeliveryPending
 }
 
 type InvalidConstraint Constraint
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timshannon/badgerhold/v4"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// dispatchStreamBuffer is the number of descriptors a DispatchStream produces ahead of its consumer.
+const dispatchStreamBuffer = 64
+
+// errDispatchStreamStopped ends a DispatchStream's shard query after its limit was reached or it was closed.
+var errDispatchStreamStopped = errors.New("dispatch stream stopped")
+
+// DispatchQuery selects and orders the bundles returned by GetDispatchable. Its zero value selects all bundles to be
+// dispatched.
+type DispatchQuery struct {
+	// MinPriority excludes bundles of a lower priority.
+	MinPriority bpv7.Priority
+	// MinLifetime excludes bundles expiring within this remaining lifetime.
+	MinLifetime time.Duration
+	// Destination excludes bundles whose destination it does not match, if set, e.g., "dtn://sensor-*/**".
+	Destination *bpv7.EndpointPattern
+	// Limit is the maximum number of returned bundles, the most urgent ones. Zero means no limit.
+	Limit int
+}
+
+// badgerholdQuery for the metadata of the bundles to be dispatched, matching this query at the given time.
+func (query DispatchQuery) badgerholdQuery(now time.Time) *badgerhold.Query {
+	q := badgerhold.Where("Dispatch").Eq(true)
+
+	if query.MinPriority > bpv7.PriorityBulk {
+		q = q.And("Priority").MatchFunc(func(ra *badgerhold.RecordAccess) (bool, error) {
+			priority, ok := ra.Field().(bpv7.Priority)
+			return ok && priority >= query.MinPriority, nil
+		})
+	}
+	if query.MinLifetime > 0 {
+		q = q.And("Expires").Ge(now.Add(query.MinLifetime))
+	}
+	if query.Destination != nil {
+		q = q.And("Destination").MatchFunc(func(ra *badgerhold.RecordAccess) (bool, error) {
+			destination, ok := ra.Field().(bpv7.EndpointID)
+			return ok && query.Destination.Match(destination), nil
+		})
+	}
+
+	return q
+}
+
+// dispatchBefore orders bundles by their urgency: higher priorities first, then the earliest expiring ones.
+func dispatchBefore(a, b *BundleDescriptor) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if !a.Expires.Equal(b.Expires) {
+		return a.Expires.Before(b.Expires)
+	}
+	return a.IDString < b.IDString
+}
+
+// GetDispatchable returns the descriptors of the bundles to be dispatched which match the query, ordered by their
+// urgency. The query is evaluated within the shards, which are queried concurrently.
+func (bst *BundleStore) GetDispatchable(query DispatchQuery) ([]*BundleDescriptor, error) {
+	now := clock.Now()
+	bds, err := bst.find(func() *badgerhold.Query { return query.badgerholdQuery(now) })
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(bds, func(i, j int) bool {
+		return dispatchBefore(bds[i], bds[j])
+	})
+	if query.Limit > 0 && len(bds) > query.Limit {
+		bds = bds[:query.Limit]
+	}
+	return bds, nil
+}
+
+// DispatchStream passes the descriptors of the bundles to be dispatched on while the store is still querying them, see
+// StreamDispatchable. Like a bufio.Scanner, it reports a failed query by Err after its Bundles channel was closed.
+type DispatchStream struct {
+	bundles   chan *BundleDescriptor
+	closed    chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// StreamDispatchable is like GetDispatchable, but returns a DispatchStream instead of waiting for the whole result.
+// Thus, a consumer might start forwarding the first bundles of a large backlog while the shards are still queried.
+//
+// The bundles are streamed by descending priority. Unlike GetDispatchable, bundles of the same priority are not
+// ordered by their expiry, as this would require the whole result. The stream's Limit applies to all priorities.
+func (bst *BundleStore) StreamDispatchable(query DispatchQuery) *DispatchStream {
+	stream := &DispatchStream{
+		bundles: make(chan *BundleDescriptor, dispatchStreamBuffer),
+		closed:  make(chan struct{}),
+	}
+	go stream.produce(bst, query)
+	return stream
+}
+
+// produce queries each priority within all shards, passing each descriptor on until the query's Limit is reached or
+// the stream is closed.
+func (stream *DispatchStream) produce(bst *BundleStore, query DispatchQuery) {
+	defer close(stream.bundles)
+
+	var produced int64
+	limitReached := func() bool {
+		return query.Limit > 0 && atomic.LoadInt64(&produced) >= int64(query.Limit)
+	}
+
+	now := clock.Now()
+	for _, priority := range []bpv7.Priority{bpv7.PriorityExpedited, bpv7.PriorityNormal, bpv7.PriorityBulk} {
+		if priority < query.MinPriority {
+			break
+		}
+
+		priority := priority
+		err := bst.forEachShard(func(shard *storeShard) error {
+			// badgerhold modifies a query while running it, thus each shard needs its own.
+			q := query.badgerholdQuery(now).And("Priority").MatchFunc(func(ra *badgerhold.RecordAccess) (bool, error) {
+				p, ok := ra.Field().(bpv7.Priority)
+				return ok && p == priority, nil
+			})
+
+			return shard.metadataStore.ForEach(q, func(bd *BundleDescriptor) error {
+				if query.Limit > 0 && atomic.AddInt64(&produced, 1) > int64(query.Limit) {
+					return errDispatchStreamStopped
+				}
+
+				select {
+				case stream.bundles <- bd:
+					return nil
+				case <-stream.closed:
+					return errDispatchStreamStopped
+				}
+			})
+		})
+
+		select {
+		case <-stream.closed:
+			return
+		default:
+		}
+		if limitReached() {
+			return
+		} else if err != nil {
+			stream.err = err
+			return
+		}
+	}
+}
+
+// Bundles returns the channel of the streamed descriptors, which is closed after the last one.
+func (stream *DispatchStream) Bundles() <-chan *BundleDescriptor {
+	return stream.bundles
+}
+
+// Err returns the error of a failed query. It must only be called after the Bundles channel was closed.
+func (stream *DispatchStream) Err() error {
+	return stream.err
+}
+
+// Close stops the stream before its end and waits for its queries to finish. Closing an ended stream is harmless.
+func (stream *DispatchStream) Close() {
+	stream.closeOnce.Do(func() {
+		close(stream.closed)
+	})
+
+	for range stream.bundles {
+	}
+}
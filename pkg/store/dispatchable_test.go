@@ -0,0 +1,182 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestGetDispatchableQuery(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		priorities := []bpv7.Priority{bpv7.PriorityBulk, bpv7.PriorityNormal, bpv7.PriorityExpedited}
+		query := DispatchQuery{
+			MinPriority: rapid.SampledFrom(priorities).Draw(t, "Minimum priority"),
+			MinLifetime: time.Duration(rapid.IntRange(0, 4).Draw(t, "Minimum lifetime")) * time.Hour,
+			Limit:       rapid.IntRange(0, 5).Draw(t, "Limit"),
+		}
+		if pattern := rapid.SampledFrom([]string{"", "*", "dtn://a-*/", "dtn://b-?/**"}).Draw(t, "Destination"); pattern != "" {
+			destination := bpv7.MustNewEndpointPattern(pattern)
+			query.Destination = &destination
+		}
+		matchesDestination := func(destination bpv7.EndpointID) bool {
+			return query.Destination == nil || query.Destination.Match(destination)
+		}
+
+		expected := 0
+		for i := rapid.IntRange(1, 10).Draw(t, "Number of bundles"); i > 0; i-- {
+			priority := rapid.SampledFrom(priorities).Draw(t, "Priority")
+			lifetime := time.Duration(rapid.IntRange(1, 5).Draw(t, "Lifetime")) * time.Hour
+			destination := fmt.Sprintf("dtn://%s-%d/", rapid.SampledFrom([]string{"a", "b"}).Draw(t, "Destination"), i)
+
+			bundle, err := bpv7.Builder().
+				Source(fmt.Sprintf("dtn://src-%d/", i)).
+				Destination(destination).
+				CreationTimestampNow().
+				Lifetime(lifetime).
+				PriorityBlock(priority).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := GetStoreSingleton().InsertBundle(&bundle); err != nil {
+				t.Fatal(err)
+			}
+
+			if priority >= query.MinPriority && lifetime >= query.MinLifetime+time.Minute &&
+				matchesDestination(bpv7.MustNewEndpointID(destination)) {
+				expected++
+			}
+		}
+		if query.Limit > 0 && expected > query.Limit {
+			expected = query.Limit
+		}
+
+		bds, err := GetStoreSingleton().GetDispatchable(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bds) != expected {
+			t.Fatalf("Query %+v returned %d instead of %d bundles", query, len(bds), expected)
+		}
+
+		for i, bd := range bds {
+			if bd.Priority < query.MinPriority {
+				t.Fatalf("Bundle %v has priority %v, below %v", bd.ID, bd.Priority, query.MinPriority)
+			}
+			if !matchesDestination(bd.Destination) {
+				t.Fatalf("Bundle %v's destination does not match %v", bd.ID, query.Destination)
+			}
+			if i > 0 && dispatchBefore(bd, bds[i-1]) {
+				t.Fatalf("Bundle %v is ordered after the less urgent %v", bd.ID, bds[i-1].ID)
+			}
+		}
+	})
+}
+
+func TestStreamDispatchable(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		priorities := []bpv7.Priority{bpv7.PriorityBulk, bpv7.PriorityNormal, bpv7.PriorityExpedited}
+		query := DispatchQuery{
+			MinPriority: rapid.SampledFrom(priorities).Draw(t, "Minimum priority"),
+			Limit:       rapid.IntRange(0, 5).Draw(t, "Limit"),
+		}
+
+		for i := rapid.IntRange(0, 10).Draw(t, "Number of bundles"); i > 0; i-- {
+			bundle, err := bpv7.Builder().
+				Source(fmt.Sprintf("dtn://src-%d/", i)).
+				Destination("dtn://dst/").
+				CreationTimestampNow().
+				Lifetime("1h").
+				PriorityBlock(rapid.SampledFrom(priorities).Draw(t, "Priority")).
+				PayloadBlock([]byte("hello world")).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := GetStoreSingleton().InsertBundle(&bundle); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		expected, err := GetStoreSingleton().GetDispatchable(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stream := GetStoreSingleton().StreamDispatchable(query)
+		var streamed []*BundleDescriptor
+		for bd := range stream.Bundles() {
+			streamed = append(streamed, bd)
+		}
+		if err := stream.Err(); err != nil {
+			t.Fatal(err)
+		}
+		stream.Close()
+
+		if len(streamed) != len(expected) {
+			t.Fatalf("Query %+v streamed %d instead of %d bundles", query, len(streamed), len(expected))
+		}
+		for i, bd := range streamed {
+			// Bundles of the same priority are streamed in any order, thus the limit might select others.
+			if bd.Priority != expected[i].Priority {
+				t.Fatalf("Streamed bundle %d has priority %v instead of %v", i, bd.Priority, expected[i].Priority)
+			}
+		}
+	})
+}
+
+func TestStreamDispatchableClose(t *testing.T) {
+	if err := SetShards(4); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetShards(1) }()
+
+	if err := InitialiseStore(bpv7.MustNewEndpointID("dtn://node/"), "/tmp/dtn7-test"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = GetStoreSingleton().Close()
+		_ = os.RemoveAll("/tmp/dtn7-test")
+	}()
+
+	for i := 0; i < 2*dispatchStreamBuffer; i++ {
+		bundle, err := bpv7.Builder().
+			Source("dtn://src/").
+			Destination(fmt.Sprintf("dtn://dst-%d/", i)).
+			CreationTimestampNow().
+			Lifetime("1h").
+			PayloadBlock([]byte("hello world")).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := GetStoreSingleton().InsertBundle(&bundle); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stream := GetStoreSingleton().StreamDispatchable(DispatchQuery{})
+	if _, ok := <-stream.Bundles(); !ok {
+		t.Fatal("Stream ended before its first bundle")
+	}
+	stream.Close()
+
+	if _, ok := <-stream.Bundles(); ok {
+		t.Fatal("Closed stream passed on another bundle")
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/timshannon/badgerhold/v4"
+)
+
+// ErrNotFound matches errors about bundles which are not stored, e.g., as they were deleted meanwhile, by errors.Is.
+var ErrNotFound = errors.New("bundle not found")
+
+// notFound wraps an error of the metadata database or the file system about a missing bundle as ErrNotFound.
+// Other errors are returned unchanged.
+func notFound(idString string, err error) error {
+	if errors.Is(err, badgerhold.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s: %w", ErrNotFound, idString, err)
+	}
+	return err
+}
+
+// ConstraintConflictError is returned if a bundle's retention constraint cannot be changed, because the bundle was
+// deleted meanwhile, e.g., after its delivery by another goroutine. It wraps ErrNotFound.
+type ConstraintConflictError struct {
+	Bundle     string
+	Constraint Constraint
+	Err        error
+}
+
+func NewConstraintConflictError(bundle string, constraint Constraint, err error) *ConstraintConflictError {
+	return &ConstraintConflictError{Bundle: bundle, Constraint: constraint, Err: err}
+}
+
+func (err *ConstraintConflictError) Error() string {
+	return fmt.Sprintf("constraint %v of bundle %s conflicts: %v", err.Constraint, err.Bundle, err.Err)
+}
+
+func (err *ConstraintConflictError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,28 @@
+package store
+
+import "github.com/timshannon/badgerhold/v4"
+
+var smallFootprint bool
+
+// SetSmallFootprint configures the metadata database for devices with little memory, e.g., routers with 64 MB of RAM.
+// Badger's defaults use hundreds of megabytes for its memtables and block cache. The small configuration flushes and
+// compacts more often instead, trading throughput for memory.
+//
+// This must be called before InitialiseStore.
+func SetSmallFootprint(small bool) {
+	smallFootprint = small
+}
+
+// smallFootprintOptions reduces badger's memtables, caches and mmapped value log files.
+func smallFootprintOptions(opts badgerhold.Options) badgerhold.Options {
+	opts.Options = opts.Options.
+		WithMemTableSize(8 << 20).
+		WithNumMemtables(2).
+		WithNumLevelZeroTables(2).
+		WithNumLevelZeroTablesStall(4).
+		WithBaseTableSize(1 << 20).
+		WithBlockCacheSize(4 << 20).
+		WithValueLogFileSize(16 << 20).
+		WithNumCompactors(2)
+	return opts
+}
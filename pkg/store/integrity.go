@@ -0,0 +1,69 @@
+package store
+
+import (
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// IntegrityFailure is a stored bundle which failed the integrity check and was quarantined.
+type IntegrityFailure struct {
+	IDString string
+	Err      error
+}
+
+// IntegrityReport describes the outcome of CheckIntegrity.
+type IntegrityReport struct {
+	// Bundles is the number of checked bundles, excluding those quarantined before.
+	Bundles int
+	// Failures are the bundles quarantined by this check as their serialised bundle was missing or corrupt.
+	Failures []IntegrityFailure
+	// Duration of the check.
+	Duration time.Duration
+}
+
+// CheckIntegrity verifies all stored bundles, e.g., on startup. Each serialised bundle must be parsed, including its
+// blocks' CRC values, and match its metadata, as in Migrate. Bundles failing this, e.g., due to a corrupted flash
+// memory, are quarantined instead of failing later while being forwarded. Bundles quarantined before are skipped.
+//
+// Reading each bundle takes its time for a large store. Thus, it should be called before bundles are processed.
+func (bst *BundleStore) CheckIntegrity() (report IntegrityReport, err error) {
+	start := clock.Now()
+
+	bds, err := bst.GetAll()
+	if err != nil {
+		return
+	}
+	for _, bd := range bds {
+		if bd.Quarantined {
+			continue
+		}
+		report.Bundles++
+
+		path := filepath.Join(bst.shardFor(bd.Destination).bundleDirectory, bd.SerialisedFileName)
+		verifyErr := verifyBundleFile(path, bd)
+		if verifyErr == nil {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"bundle": bd.IDString,
+			"error":  verifyErr,
+		}).Warn("Quarantining corrupt stored bundle")
+		if err = bd.Quarantine(); err != nil {
+			return
+		}
+		report.Failures = append(report.Failures, IntegrityFailure{IDString: bd.IDString, Err: verifyErr})
+	}
+	report.Duration = clock.Now().Sub(start)
+
+	log.WithFields(log.Fields{
+		"bundles":  report.Bundles,
+		"failures": len(report.Failures),
+		"duration": report.Duration,
+	}).Info("Checked store's integrity")
+	return
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		corrupt := make(map[string]bool)
+		numBundles := rapid.IntRange(1, 5).Draw(t, "Number of bundles")
+		for i := 1; i <= numBundles; i++ {
+			bundle := bpv7.GenerateBundle(t, i)
+			bd, err := GetStoreSingleton().InsertBundle(&bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !rapid.Bool().Draw(t, "Corrupt bundle") {
+				continue
+			}
+
+			corrupt[bd.IDString] = true
+			path := filepath.Join(GetStoreSingleton().shardFor(bd.Destination).bundleDirectory, bd.SerialisedFileName)
+			if rapid.Bool().Draw(t, "Remove bundle") {
+				err = os.Remove(path)
+			} else if data, readErr := os.ReadFile(path); readErr != nil {
+				err = readErr
+			} else {
+				err = os.WriteFile(path, data[:len(data)/2], 0600)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		report, err := GetStoreSingleton().CheckIntegrity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Bundles != numBundles || len(report.Failures) != len(corrupt) {
+			t.Fatalf("Check of %d bundles, %d corrupt, reports %v", numBundles, len(corrupt), report)
+		}
+		for _, failure := range report.Failures {
+			if !corrupt[failure.IDString] {
+				t.Fatalf("Intact bundle %s was reported as %v", failure.IDString, failure.Err)
+			}
+		}
+
+		bds, err := GetStoreSingleton().GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, bd := range bds {
+			if bd.Quarantined != corrupt[bd.IDString] {
+				t.Fatalf("Bundle %s is quarantined: %t, corrupt: %t", bd.IDString, bd.Quarantined, corrupt[bd.IDString])
+			}
+		}
+
+		if report, err = GetStoreSingleton().CheckIntegrity(); err != nil {
+			t.Fatal(err)
+		} else if report.Bundles != numBundles-len(corrupt) || len(report.Failures) != 0 {
+			t.Fatalf("Second check reports %v", report)
+		}
+	})
+}
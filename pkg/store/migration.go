@@ -0,0 +1,163 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// A store is migrated into a new layout, e.g., another number of shards, by copying it to a new path. The source is
+// only read, either the store of a stopped node or a Snapshot of a running one. Thus, a node keeps running during the
+// migration of its snapshot and is only restarted on the migrated store afterwards.
+
+// MigrationFailure is a bundle which was left out of a migration.
+type MigrationFailure struct {
+	IDString string
+	Err      error
+}
+
+// MigrationReport describes the outcome of Migrate.
+type MigrationReport struct {
+	// Bundles is the number of migrated bundles.
+	Bundles int
+	// Bytes is the migrated store's size on disk.
+	Bytes int64
+	// Failures are the bundles left out as their serialised bundle was missing or failed the verification.
+	Failures []MigrationFailure
+	// Duration of the migration.
+	Duration time.Duration
+}
+
+// Migrate copies the store at the source path to a new store at the target path with the given number of shards.
+// Each serialised bundle is verified while being copied, i.e., it must be parsed, including its blocks' CRC values,
+// and match its metadata. Bundles failing this are left out and listed as the report's Failures.
+//
+// The source is opened read-only, like a Replica, and must not be in use by a running node.
+func Migrate(source, target string, shards int) (report MigrationReport, err error) {
+	start := clock.Now()
+
+	if shards < 1 {
+		err = fmt.Errorf("number of shards %d is not positive", shards)
+		return
+	}
+	if _, statErr := os.Stat(target); statErr == nil {
+		err = fmt.Errorf("migration target %s already exists", target)
+		return
+	} else if !os.IsNotExist(statErr) {
+		err = statErr
+		return
+	}
+
+	replica, err := OpenReplica(source)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := replica.Close(); closeErr != nil {
+			err = multierror.Append(err, closeErr).ErrorOrNil()
+		}
+	}()
+
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(target)
+		}
+	}()
+
+	if shards > 1 {
+		if err = writeShards(target, shards); err != nil {
+			return
+		}
+	}
+	bst := &BundleStore{path: target}
+	defer func() {
+		if closeErr := bst.closeShards(); closeErr != nil {
+			err = multierror.Append(err, closeErr).ErrorOrNil()
+		}
+	}()
+	for _, directory := range shardPaths(target, shards) {
+		shard, shardErr := openShard(directory, false)
+		if shardErr != nil {
+			err = shardErr
+			return
+		}
+		bst.shards = append(bst.shards, shard)
+	}
+
+	bds, err := replica.GetAll()
+	if err != nil {
+		return
+	}
+	for _, bd := range bds {
+		if migrateErr := migrateBundle(replica.store, bst, bd); migrateErr != nil {
+			log.WithFields(log.Fields{
+				"bundle": bd.IDString,
+				"error":  migrateErr,
+			}).Warn("Leaving bundle out of the migration")
+			report.Failures = append(report.Failures, MigrationFailure{IDString: bd.IDString, Err: migrateErr})
+			continue
+		}
+		report.Bundles++
+	}
+
+	if report.Bytes, err = bundleDirectorySize(target); err != nil {
+		return
+	}
+	report.Duration = clock.Now().Sub(start)
+
+	log.WithFields(log.Fields{
+		"source":   source,
+		"target":   target,
+		"shards":   shards,
+		"bundles":  report.Bundles,
+		"failures": len(report.Failures),
+		"duration": report.Duration,
+	}).Info("Migrated store")
+	return
+}
+
+// migrateBundle copies a bundle from one store to another, verifying the copied serialised bundle.
+func migrateBundle(source, target *BundleStore, bd *BundleDescriptor) error {
+	sourceShard, targetShard := source.shardFor(bd.Destination), target.shardFor(bd.Destination)
+	targetPath := filepath.Join(targetShard.bundleDirectory, bd.SerialisedFileName)
+
+	if err := linkOrCopy(filepath.Join(sourceShard.bundleDirectory, bd.SerialisedFileName), targetPath); err != nil {
+		return err
+	}
+	if err := verifyBundleFile(targetPath, bd); err != nil {
+		_ = os.Remove(targetPath)
+		return err
+	}
+
+	bd.Bundle = nil
+	if err := targetShard.metadataStore.Insert(bd.IDString, bd); err != nil {
+		_ = os.Remove(targetPath)
+		return err
+	}
+	return nil
+}
+
+// verifyBundleFile parses a serialised bundle, which checks its CRC values, and compares it with its metadata.
+func verifyBundleFile(path string, bd *BundleDescriptor) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bundle, err := bpv7.ParseBundle(f)
+	if err != nil {
+		return err
+	}
+	if id := bundle.ID().String(); id != bd.IDString {
+		return fmt.Errorf("serialised bundle %s does not match its metadata", id)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestMigrate(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		sourceShards := rapid.IntRange(1, 4).Draw(t, "Number of source shards")
+		targetShards := rapid.IntRange(1, 4).Draw(t, "Number of target shards")
+		if err := SetShards(sourceShards); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = SetShards(1) }()
+
+		initTest(t)
+		defer func() { _ = os.RemoveAll("/tmp/dtn7-test") }()
+		defer func() { _ = os.RemoveAll("/tmp/dtn7-test-migration") }()
+
+		bundles := make(map[string]bpv7.Bundle)
+		var corrupt *BundleDescriptor
+		for i := rapid.IntRange(1, 5).Draw(t, "Number of bundles"); i > 0; i-- {
+			bundle := bpv7.GenerateBundle(t, i)
+			bd, err := GetStoreSingleton().InsertBundle(&bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if corrupt == nil && rapid.Bool().Draw(t, "Corrupt bundle") {
+				corrupt = bd
+				continue
+			}
+			bundles[bundle.ID().String()] = bundle
+		}
+
+		if corrupt != nil {
+			path := filepath.Join(GetStoreSingleton().shardFor(corrupt.Destination).bundleDirectory, corrupt.SerialisedFileName)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data[:len(data)/2], 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err := Migrate("/tmp/dtn7-test", "/tmp/dtn7-test-migration", targetShards); err == nil {
+			t.Fatal("Store of a running node was migrated")
+		}
+		if err := GetStoreSingleton().Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := Migrate("/tmp/dtn7-test", "/tmp/dtn7-test-migration", targetShards)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Bundles != len(bundles) || report.Bytes == 0 {
+			t.Fatalf("Migration of %d bundles reports %v", len(bundles), report)
+		}
+		if corrupt == nil && len(report.Failures) != 0 {
+			t.Fatalf("Migration reports failures %v", report.Failures)
+		} else if corrupt != nil && (len(report.Failures) != 1 || report.Failures[0].IDString != corrupt.IDString) {
+			t.Fatalf("Migration does not report the corrupt bundle, but %v", report.Failures)
+		}
+		if _, err := Migrate("/tmp/dtn7-test", "/tmp/dtn7-test-migration", targetShards); err == nil {
+			t.Fatal("Migration overwrote an existing path")
+		}
+
+		if shards, err := storedShards("/tmp/dtn7-test-migration"); err != nil {
+			t.Fatal(err)
+		} else if targetShards > 1 && shards != targetShards {
+			t.Fatalf("Migrated store has %d instead of %d shards", shards, targetShards)
+		}
+
+		replica, err := OpenReplica("/tmp/dtn7-test-migration")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = replica.Close() }()
+
+		if bds, err := replica.GetAll(); err != nil {
+			t.Fatal(err)
+		} else if len(bds) != len(bundles) {
+			t.Fatalf("Migrated store lists %d instead of %d bundles", len(bds), len(bundles))
+		}
+		for _, bundle := range bundles {
+			bd, err := replica.LoadBundleDescriptor(bundle.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bundleLoad, err := replica.Load(bd); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(bundle, bundleLoad) {
+				t.Fatal("Retrieved Bundle not equal")
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// Occupancy describes how much of the BundleStore's storage is in use.
+type Occupancy struct {
+	// Used is the number of bytes of all serialised bundles.
+	Used int64
+	// Capacity is the configured maximum of bytes, zero if unlimited.
+	Capacity int64
+}
+
+// Pressure is the fraction of the Capacity in use, between zero and one. An unlimited store is never under pressure.
+func (o Occupancy) Pressure() float64 {
+	if o.Capacity <= 0 {
+		return 0
+	}
+	if o.Used >= o.Capacity {
+		return 1
+	}
+	return float64(o.Used) / float64(o.Capacity)
+}
+
+// SetCapacity configures the BundleStore's capacity in bytes, zero for unlimited.
+//
+// The capacity is not enforced on insertion, but reported through Occupancy. Thus, routing algorithms might replicate
+// less when local storage is nearly full, and the processing's congestion policy might refuse received bundles or evict
+// stored ones.
+func (bst *BundleStore) SetCapacity(capacity int64) {
+	bst.capacity.Store(capacity)
+}
+
+// Occupancy returns the current usage of the BundleStore's storage.
+// This method is thread-safe.
+func (bst *BundleStore) Occupancy() Occupancy {
+	return Occupancy{
+		Used:     bst.usedBytes.Load(),
+		Capacity: bst.capacity.Load(),
+	}
+}
+
+// bundleDirectorySize sums up the sizes of all serialised bundles in the bundle directory.
+func bundleDirectorySize(bundleDirectory string) (size int64, err error) {
+	err = filepath.WalkDir(bundleDirectory, func(_ string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return infoErr
+		}
+		size += info.Size()
+		return nil
+	})
+	return
+}
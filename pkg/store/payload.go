@@ -0,0 +1,119 @@
+package store
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dtn7/cboring"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// PayloadReader reads byte ranges of a stored bundle's payload without loading the whole bundle, e.g., to read only
+// the unsent remainder of a partially transferred payload or the payload of a reactive fragment. It must be closed.
+//
+// Unlike loading the bundle, reading a range does not verify the payload block's CRC value.
+type PayloadReader struct {
+	*io.SectionReader
+	file *os.File
+}
+
+// Close the underlying serialised bundle.
+func (pr *PayloadReader) Close() error {
+	return pr.file.Close()
+}
+
+// OpenPayload opens a stored bundle's payload for reading byte ranges, see PayloadReader.
+func (bst *BundleStore) OpenPayload(bd *BundleDescriptor) (*PayloadReader, error) {
+	path := filepath.Join(bst.shardFor(bd.Destination).bundleDirectory, bd.SerialisedFileName)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, notFound(bd.IDString, err)
+	}
+
+	offset, size, err := locatePayload(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("locating payload of bundle %s failed: %w", bd.IDString, err)
+	}
+	return &PayloadReader{SectionReader: io.NewSectionReader(f, offset, size), file: f}, nil
+}
+
+// OpenPayload opens a replicated bundle's payload for reading byte ranges, see PayloadReader.
+func (replica *Replica) OpenPayload(bd *BundleDescriptor) (*PayloadReader, error) {
+	return replica.store.OpenPayload(bd)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// locatePayload scans a serialised bundle for its payload block and returns the offset and size of the payload's
+// data. The primary block is parsed and checked, while the other canonical blocks are only skipped.
+func locatePayload(r io.Reader) (offset, size int64, err error) {
+	cr := &countingReader{r: bufio.NewReader(r)}
+
+	if err = cboring.ReadExpect(cboring.IndefiniteArray, cr); err != nil {
+		return
+	}
+	if err = cboring.Unmarshal(&bpv7.PrimaryBlock{}, cr); err != nil {
+		return
+	}
+
+	for {
+		blockLen, lenErr := cboring.ReadArrayLength(cr)
+		if errors.Is(lenErr, cboring.FlagBreakCode) {
+			err = fmt.Errorf("bundle has no payload block")
+			return
+		} else if lenErr != nil {
+			err = lenErr
+			return
+		} else if blockLen != 5 && blockLen != 6 {
+			err = fmt.Errorf("expected array with length 5 or 6, got %d", blockLen)
+			return
+		}
+
+		// block type code, block number, block processing control flags and CRC type
+		var blockType uint64
+		for i := 0; i < 4; i++ {
+			field, fieldErr := cboring.ReadUInt(cr)
+			if fieldErr != nil {
+				err = fieldErr
+				return
+			}
+			if i == 0 {
+				blockType = field
+			}
+		}
+
+		if blockType == bpv7.ExtBlockTypePayloadBlock {
+			dataLen, dataErr := cboring.ReadByteStringLen(cr)
+			if dataErr != nil {
+				err = dataErr
+				return
+			}
+			return cr.n, int64(dataLen), nil
+		}
+
+		if _, err = cboring.ReadByteString(cr); err != nil {
+			return
+		}
+		if blockLen == 6 {
+			if _, err = cboring.ReadByteString(cr); err != nil {
+				return
+			}
+		}
+	}
+}
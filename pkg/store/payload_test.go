@@ -0,0 +1,56 @@
+package store
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestOpenPayload(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		bd, err := GetStoreSingleton().InsertBundle(&bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloadBlock, err := bundle.PayloadBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload := payloadBlock.Value.(*bpv7.PayloadBlock).Data()
+
+		pr, err := GetStoreSingleton().OpenPayload(bd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = pr.Close() }()
+
+		if pr.Size() != int64(len(payload)) {
+			t.Fatalf("Payload size is %d instead of %d", pr.Size(), len(payload))
+		}
+		if data, err := io.ReadAll(pr); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(data, payload) {
+			t.Fatal("Read payload differs")
+		}
+
+		if len(payload) == 0 {
+			return
+		}
+		off := rapid.IntRange(0, len(payload)-1).Draw(t, "Offset")
+		n := rapid.IntRange(1, len(payload)-off).Draw(t, "Length")
+		data := make([]byte, n)
+		if _, err := pr.ReadAt(data, int64(off)); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(data, payload[off:off+n]) {
+			t.Fatalf("Range [%d, %d) of the payload differs", off, off+n)
+		}
+	})
+}
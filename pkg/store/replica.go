@@ -0,0 +1,223 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+)
+
+// The metadata database of a running BundleStore is locked exclusively by its process. Thus, other processes, e.g.,
+// monitoring or analytics tools, inspect the bundles of a Snapshot, which is opened as a Replica. A Replica might also
+// be opened on the path of a store whose node is not running.
+
+// SnapshotReport describes the outcome of Snapshot.
+type SnapshotReport struct {
+	// Bundles is the number of bundles within the snapshot.
+	Bundles int
+	// Bytes is the snapshot's size on disk.
+	Bytes int64
+	// Duration of the snapshot.
+	Duration time.Duration
+}
+
+// Snapshot creates a copy of the store at a new path, which can be opened as a Replica. The metadata is copied at one
+// point in time; the serialised bundles are hard linked, or copied across file systems. Bundles deleted meanwhile are
+// left out of the snapshot.
+//
+// A snapshot runs alongside the regular operation, but not alongside a compaction.
+// This method is thread-safe.
+func (bst *BundleStore) Snapshot(path string) (report SnapshotReport, err error) {
+	bst.compactionMutex.Lock()
+	defer bst.compactionMutex.Unlock()
+
+	start := clock.Now()
+
+	if _, statErr := os.Stat(path); statErr == nil {
+		err = fmt.Errorf("snapshot path %s already exists", path)
+		return
+	} else if !os.IsNotExist(statErr) {
+		err = statErr
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(path)
+		}
+	}()
+
+	if len(bst.shards) > 1 {
+		if err = writeShards(path, len(bst.shards)); err != nil {
+			return
+		}
+	}
+
+	for i, directory := range shardPaths(path, len(bst.shards)) {
+		bundles, shardErr := snapshotShard(bst.shards[i], directory)
+		if shardErr != nil {
+			err = shardErr
+			return
+		}
+		report.Bundles += bundles
+	}
+
+	if report.Bytes, err = bundleDirectorySize(path); err != nil {
+		return
+	}
+	report.Duration = clock.Now().Sub(start)
+
+	log.WithFields(log.Fields{
+		"path":     path,
+		"bundles":  report.Bundles,
+		"bytes":    report.Bytes,
+		"duration": report.Duration,
+	}).Info("Created store snapshot")
+	return
+}
+
+// snapshotShard copies a shard's metadata database into a new shard at the directory and links its serialised
+// bundles, returning the number of bundles.
+func snapshotShard(shard *storeShard, directory string) (bundles int, err error) {
+	target, err := openShard(directory, false)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := target.metadataStore.Close(); closeErr != nil {
+			err = multierror.Append(err, closeErr).ErrorOrNil()
+		}
+	}()
+
+	backupReader, backupWriter := io.Pipe()
+	go func() {
+		_, backupErr := shard.metadataStore.Badger().Backup(backupWriter, 0)
+		_ = backupWriter.CloseWithError(backupErr)
+	}()
+	if err = target.metadataStore.Badger().Load(backupReader, 16); err != nil {
+		_ = backupReader.CloseWithError(err)
+		return
+	}
+
+	descriptors := make([]BundleDescriptor, 0)
+	if err = target.metadataStore.Find(&descriptors, nil); err != nil {
+		return
+	}
+	for i := range descriptors {
+		bd := &descriptors[i]
+		linkErr := linkOrCopy(
+			filepath.Join(shard.bundleDirectory, bd.SerialisedFileName),
+			filepath.Join(target.bundleDirectory, bd.SerialisedFileName))
+		if os.IsNotExist(linkErr) {
+			log.WithField("bundle", bd.IDString).Debug("Bundle was deleted during snapshot, leaving it out")
+			if err = target.metadataStore.Delete(bd.IDString, bd); err != nil {
+				return
+			}
+			continue
+		} else if linkErr != nil {
+			err = linkErr
+			return
+		}
+		bundles++
+	}
+	return
+}
+
+// linkOrCopy creates a hard link of a file, or copies it if this fails, e.g., across file systems.
+func linkOrCopy(source, destination string) error {
+	if err := os.Link(source, destination); err == nil || os.IsNotExist(err) {
+		return err
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Replica is a read-only view of a store, e.g., a Snapshot, for inspecting its bundles without altering them.
+//
+// The BundleDescriptors' own methods refer to the store singleton. Thus, a Replica's bundles are loaded by its Load.
+type Replica struct {
+	store *BundleStore
+}
+
+// OpenReplica opens the store at the path read-only. Its number of shards is read from the store itself.
+//
+// The metadata database of a store whose node was not shut down cleanly must be recovered by opening it writable first,
+// e.g., by starting the node again. Snapshots are always closed cleanly.
+func OpenReplica(path string) (*Replica, error) {
+	shards, err := storedShards(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bst := &BundleStore{path: path}
+	for _, directory := range shardPaths(path, shards) {
+		shard, err := openShard(directory, true)
+		if err != nil {
+			return nil, multierror.Append(err, bst.closeShards())
+		}
+		bst.shards = append(bst.shards, shard)
+	}
+	return &Replica{store: bst}, nil
+}
+
+// Close the Replica.
+func (replica *Replica) Close() error {
+	return replica.store.closeShards()
+}
+
+// GetAll returns the descriptors of all bundles.
+func (replica *Replica) GetAll() ([]*BundleDescriptor, error) {
+	return replica.store.GetAll()
+}
+
+// GetWithConstraint returns the descriptors of all bundles with the retention constraint.
+func (replica *Replica) GetWithConstraint(constraint Constraint) ([]*BundleDescriptor, error) {
+	return replica.store.GetWithConstraint(constraint)
+}
+
+// LoadBundleDescriptor loads a bundle's BundleDescriptor by its ID.
+func (replica *Replica) LoadBundleDescriptor(bundleId bpv7.BundleID) (*BundleDescriptor, error) {
+	return replica.store.LoadBundleDescriptor(bundleId)
+}
+
+// LoadBundleDescriptorByIDString loads a bundle's BundleDescriptor by its IDString.
+func (replica *Replica) LoadBundleDescriptorByIDString(idString string) (*BundleDescriptor, error) {
+	return replica.store.LoadBundleDescriptorByIDString(idString)
+}
+
+// LoadBundleByIDString loads a bundle's BundleDescriptor by its IDString, and its Bundle if withBundle is set.
+func (replica *Replica) LoadBundleByIDString(idString string, withBundle bool) (*BundleDescriptor, error) {
+	return replica.store.LoadBundleByIDString(idString, withBundle)
+}
+
+// Load the bundle of a BundleDescriptor.
+func (replica *Replica) Load(bd *BundleDescriptor) (bpv7.Bundle, error) {
+	if bd.Bundle != nil {
+		return *bd.Bundle, nil
+	}
+	bundle, err := replica.store.loadEntireBundle(bd)
+	if err != nil {
+		return bpv7.Bundle{}, err
+	}
+	return *bundle, nil
+}
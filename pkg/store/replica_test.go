@@ -0,0 +1,89 @@
+package store
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestSnapshotReplica(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		shards := rapid.IntRange(1, 4).Draw(t, "Number of shards")
+		if err := SetShards(shards); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = SetShards(1) }()
+
+		initTest(t)
+		defer cleanupTest(t)
+		defer func() { _ = os.RemoveAll("/tmp/dtn7-test-snapshot") }()
+
+		bundles := make(map[string]bpv7.Bundle)
+		for i := rapid.IntRange(1, 5).Draw(t, "Number of bundles"); i > 0; i-- {
+			bundle := bpv7.GenerateBundle(t, i)
+			if _, err := GetStoreSingleton().InsertBundle(&bundle); err != nil {
+				t.Fatal(err)
+			}
+			bundles[bundle.ID().String()] = bundle
+		}
+
+		if _, err := OpenReplica("/tmp/dtn7-test"); err == nil {
+			t.Fatal("Replica of a running store was opened")
+		}
+
+		report, err := GetStoreSingleton().Snapshot("/tmp/dtn7-test-snapshot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Bundles != len(bundles) || report.Bytes == 0 {
+			t.Fatalf("Snapshot of %d bundles reports %v", len(bundles), report)
+		}
+		if _, err := GetStoreSingleton().Snapshot("/tmp/dtn7-test-snapshot"); err == nil {
+			t.Fatal("Snapshot overwrote an existing path")
+		}
+
+		// Bundles deleted from the store afterwards are still part of the snapshot
+		all, err := GetStoreSingleton().GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, bd := range all {
+			if err := GetStoreSingleton().DeleteBundle(bd); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		replica, err := OpenReplica("/tmp/dtn7-test-snapshot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = replica.Close() }()
+
+		if bds, err := replica.GetAll(); err != nil {
+			t.Fatal(err)
+		} else if len(bds) != len(bundles) {
+			t.Fatalf("Replica lists %d instead of %d bundles", len(bds), len(bundles))
+		}
+		if bds, err := replica.GetWithConstraint(DispatchPending); err != nil {
+			t.Fatal(err)
+		} else if len(bds) != len(bundles) {
+			t.Fatalf("Replica lists %d instead of %d pending bundles", len(bds), len(bundles))
+		}
+
+		for _, bundle := range bundles {
+			bd, err := replica.LoadBundleDescriptor(bundle.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bundleLoad, err := replica.Load(bd); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(bundle, bundleLoad) {
+				t.Fatal("Retrieved Bundle not equal")
+			}
+		}
+	})
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/timshannon/badgerhold/v4"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// Sharding partitions the BundleStore by the hash of the bundles' destination nodes. Each shard has its own metadata
+// database and bundle directory, keeping the directory sizes bounded and allowing queries across all shards, e.g., for
+// dispatchable bundles, to run concurrently.
+//
+// An unsharded store, the default, keeps its metadata and the bundle directory directly within the store's path. A
+// sharded store places them in a "shard-N" subdirectory for each shard and records the number of shards in the
+// shardsFileName. As bundles cannot be moved between shards, a store must always be opened with the same number of
+// shards.
+
+// shardsFileName within the store's path contains the number of shards of a sharded store.
+const shardsFileName = "shards"
+
+var shardCount = 1
+
+// SetShards configures the number of shards, one for an unsharded store.
+//
+// This must be called before InitialiseStore.
+func SetShards(shards int) error {
+	if shards < 1 {
+		return fmt.Errorf("number of shards %d is not positive", shards)
+	}
+	shardCount = shards
+	return nil
+}
+
+// storeShard is a partition of the BundleStore, holding the bundles of some destination nodes.
+type storeShard struct {
+	metadataStore   *badgerhold.Store
+	bundleDirectory string
+}
+
+// storedShards reads the number of shards of an existing sharded store, zero for an unsharded or a new one.
+func storedShards(path string) (int, error) {
+	shardsFile := filepath.Join(path, shardsFileName)
+	data, err := os.ReadFile(shardsFile)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	shards, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of shards in %s: %v", shardsFile, err)
+	}
+	return shards, nil
+}
+
+// shardDirectories lists the directory of each shard within the store's path after checking that an existing store
+// was sharded alike.
+func shardDirectories(path string, shards int) ([]string, error) {
+	existing, err := storedShards(path)
+	switch {
+	case err != nil:
+		return nil, err
+
+	case existing != 0 && existing != shards:
+		return nil, fmt.Errorf("store at %s has %d shards, not %d", path, existing, shards)
+
+	case existing == 0 && shards > 1:
+		if _, statErr := os.Stat(filepath.Join(path, "bundles")); statErr == nil {
+			return nil, fmt.Errorf("store at %s is not sharded", path)
+		}
+		if err := writeShards(path, shards); err != nil {
+			return nil, err
+		}
+	}
+
+	return shardPaths(path, shards), nil
+}
+
+// writeShards records the number of shards of a new sharded store.
+func writeShards(path string, shards int) error {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, shardsFileName), []byte(strconv.Itoa(shards)+"\n"), 0600)
+}
+
+// shardPaths lists the directory of each shard within the store's path.
+func shardPaths(path string, shards int) []string {
+	if shards <= 1 {
+		return []string{path}
+	}
+
+	directories := make([]string, shards)
+	for i := range directories {
+		directories[i] = filepath.Join(path, fmt.Sprintf("shard-%d", i))
+	}
+	return directories
+}
+
+// openShard opens or creates the metadata database and bundle directory of a shard. A read-only shard must exist.
+func openShard(directory string, readOnly bool) (*storeShard, error) {
+	opts := badgerhold.DefaultOptions
+	opts.Dir = directory
+	opts.ValueDir = directory
+	opts.ReadOnly = readOnly
+	if smallFootprint {
+		opts = smallFootprintOptions(opts)
+	}
+
+	bundleDirectory := filepath.Join(directory, "bundles")
+	if readOnly {
+		if _, err := os.Stat(bundleDirectory); err != nil {
+			return nil, err
+		}
+	} else if err := os.MkdirAll(bundleDirectory, 0700); err != nil {
+		return nil, err
+	}
+
+	badgerStore, err := badgerhold.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storeShard{metadataStore: badgerStore, bundleDirectory: bundleDirectory}, nil
+}
+
+// shardFor returns the shard of a destination, based on the FNV-1a hash of its node, i.e., scheme and authority.
+// Thus, all bundles for the same node share one shard.
+func (bst *BundleStore) shardFor(destination bpv7.EndpointID) *storeShard {
+	if len(bst.shards) == 1 || destination.EndpointType == nil {
+		return bst.shards[0]
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(destination.EndpointType.SchemeName() + ":" + destination.Authority()))
+	return bst.shards[hash.Sum32()%uint32(len(bst.shards))]
+}
+
+// forEachShard calls f for all shards concurrently and collects their errors.
+func (bst *BundleStore) forEachShard(f func(shard *storeShard) error) error {
+	if len(bst.shards) == 1 {
+		return f(bst.shards[0])
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  error
+	)
+	wg.Add(len(bst.shards))
+	for _, shard := range bst.shards {
+		go func(shard *storeShard) {
+			defer wg.Done()
+			if err := f(shard); err != nil {
+				mutex.Lock()
+				errs = multierror.Append(errs, err)
+				mutex.Unlock()
+			}
+		}(shard)
+	}
+	wg.Wait()
+	return errs
+}
+
+// find returns the BundleDescriptors of all shards matching the query, all of them for a nil newQuery. badgerhold
+// modifies a query while running it, thus newQuery creates one for each shard.
+func (bst *BundleStore) find(newQuery func() *badgerhold.Query) ([]*BundleDescriptor, error) {
+	var (
+		mutex sync.Mutex
+		ptrs  = make([]*BundleDescriptor, 0)
+	)
+	err := bst.forEachShard(func(shard *storeShard) error {
+		var query *badgerhold.Query
+		if newQuery != nil {
+			query = newQuery()
+		}
+
+		bundles := make([]BundleDescriptor, 0)
+		if err := shard.metadataStore.Find(&bundles, query); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for i := range bundles {
+			ptrs = append(ptrs, &bundles[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ptrs, nil
+}
+
+// get looks up a BundleDescriptor by its IDString in all shards, as its destination is unknown.
+func (bst *BundleStore) get(idString string, bd *BundleDescriptor) (err error) {
+	for _, shard := range bst.shards {
+		if err = shard.metadataStore.Get(idString, bd); err == nil {
+			return nil
+		}
+	}
+	return notFound(idString, err)
+}
+
+// close all shards' metadata databases.
+func (bst *BundleStore) closeShards() (errs error) {
+	for _, shard := range bst.shards {
+		if err := shard.metadataStore.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return
+}
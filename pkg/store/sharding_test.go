@@ -0,0 +1,158 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+func TestShardedStore(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		shards := rapid.IntRange(2, 8).Draw(t, "Number of shards")
+		if err := SetShards(shards); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = SetShards(1) }()
+
+		initTest(t)
+		defer cleanupTest(t)
+
+		if n := len(GetStoreSingleton().shards); n != shards {
+			t.Fatalf("Store has %d instead of %d shards", n, shards)
+		}
+
+		// Generated bundles might share their ID, which are stored only once
+		bundles := make(map[string]bpv7.Bundle)
+		for i := rapid.IntRange(1, 10).Draw(t, "Number of bundles"); i > 0; i-- {
+			bundle := bpv7.GenerateBundle(t, i)
+			if _, err := GetStoreSingleton().InsertBundle(&bundle); err != nil {
+				t.Fatal(err)
+			}
+			bundles[bundle.ID().String()] = bundle
+		}
+		numBundles := len(bundles)
+
+		if bds, err := GetStoreSingleton().GetAll(); err != nil {
+			t.Fatal(err)
+		} else if len(bds) != numBundles {
+			t.Fatalf("Store lists %d instead of %d bundles", len(bds), numBundles)
+		}
+		if bds, err := GetStoreSingleton().GetDispatchable(DispatchQuery{}); err != nil {
+			t.Fatal(err)
+		} else if len(bds) != numBundles {
+			t.Fatalf("Store lists %d instead of %d dispatchable bundles", len(bds), numBundles)
+		}
+
+		var used int64
+		for _, shard := range GetStoreSingleton().shards {
+			size, err := bundleDirectorySize(shard.bundleDirectory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			used += size
+		}
+		if occupied := GetStoreSingleton().Occupancy().Used; occupied != used {
+			t.Fatalf("Store reports %d used bytes, shards have %d", occupied, used)
+		}
+
+		// The store can only be reopened with its initial number of shards
+		nodeID := GetStoreSingleton().nodeID
+		if err := GetStoreSingleton().Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetShards(shards + 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := InitialiseStore(nodeID, "/tmp/dtn7-test"); err == nil {
+			t.Fatal("Store was opened with a different number of shards")
+		}
+		if err := SetShards(shards); err != nil {
+			t.Fatal(err)
+		}
+		if err := InitialiseStore(nodeID, "/tmp/dtn7-test"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, bundle := range bundles {
+			if !GetStoreSingleton().KnownBundle(bundle.ID()) {
+				t.Fatalf("Bundle %v is unknown after reopening", bundle.ID())
+			}
+
+			bd, err := GetStoreSingleton().LoadBundleDescriptor(bundle.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bundleLoad, err := bd.Load(); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(bundle, bundleLoad) {
+				t.Fatal("Retrieved Bundle not equal")
+			}
+
+			if err := GetStoreSingleton().DeleteBundle(bd); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if used := GetStoreSingleton().Occupancy().Used; used != 0 {
+			t.Fatalf("Empty store uses %d bytes", used)
+		}
+	})
+}
+
+func TestShardFor(t *testing.T) {
+	if err := SetShards(4); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetShards(1) }()
+
+	if err := InitialiseStore(bpv7.MustNewEndpointID("dtn://node/"), "/tmp/dtn7-test"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = GetStoreSingleton().Close()
+		_ = os.RemoveAll("/tmp/dtn7-test")
+	}()
+
+	bst := GetStoreSingleton()
+	if bst.shardFor(bpv7.MustNewEndpointID("dtn://dst/a")) != bst.shardFor(bpv7.MustNewEndpointID("dtn://dst/b")) {
+		t.Fatal("Endpoints of the same node are in different shards")
+	}
+
+	used := make(map[*storeShard]bool)
+	for i := 0; i < 64; i++ {
+		used[bst.shardFor(bpv7.MustNewEndpointID(fmt.Sprintf("dtn://dst%d/", i)))] = true
+	}
+	if len(used) != 4 {
+		t.Fatalf("Destinations spread over %d instead of 4 shards", len(used))
+	}
+
+	if err := SetShards(0); err == nil {
+		t.Fatal("Zero shards were accepted")
+	}
+}
+
+func TestShardingUnshardedStore(t *testing.T) {
+	nodeID := bpv7.MustNewEndpointID("dtn://node/")
+	if err := InitialiseStore(nodeID, "/tmp/dtn7-test"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll("/tmp/dtn7-test") }()
+	if err := GetStoreSingleton().Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetShards(2); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetShards(1) }()
+
+	if err := InitialiseStore(nodeID, "/tmp/dtn7-test"); err == nil {
+		_ = GetStoreSingleton().Close()
+		t.Fatal("Unsharded store was opened with two shards")
+	}
+}
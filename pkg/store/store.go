@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -15,13 +17,36 @@ import (
 	"github.com/dtn7/cboring"
 
 	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/fault_injection"
 	"github.com/dtn7/dtn7-go/pkg/util"
 )
 
+// knownBundlesExpected and knownBundlesFalsePositive dimension the BundleStore's Bloom filter of known bundle IDs. It
+// is rebuilt for at least twice the stored bundles, see rebuildKnownBundles.
+const (
+	knownBundlesExpected      = 100000
+	knownBundlesFalsePositive = 0.01
+)
+
 type BundleStore struct {
-	nodeID          bpv7.EndpointID
-	metadataStore   *badgerhold.Store
-	bundleDirectory string
+	nodeID bpv7.EndpointID
+	path   string
+	// shards partition the metadata and serialised bundles by their destinations, see sharding.go
+	shards []*storeShard
+	// knownBundles contains the IDString of every bundle inserted into this store since its last rebuild, which drops
+	// the deleted ones, see rebuildKnownBundles. It allows us to skip the metadata lookup for the common case of a new
+	// bundle. While being rebuilt, inserted bundles are added to the rebuilt filter as well.
+	knownBundles        atomic.Pointer[bloomFilter]
+	rebuiltKnownBundles atomic.Pointer[bloomFilter]
+	rebuildMutex        sync.Mutex
+	// usedBytes and capacity describe the Occupancy, see occupancy.go.
+	usedBytes atomic.Int64
+	capacity  atomic.Int64
+	// compaction of the disk space, see compaction.go
+	compactionMutex         sync.Mutex
+	compactionScheduleMutex sync.Mutex
+	compactionStop          chan struct{}
 }
 
 var storeSingleton *BundleStore
@@ -34,26 +59,40 @@ func InitialiseStore(nodeID bpv7.EndpointID, path string) error {
 		return util.NewAlreadyInitialisedError("BundleStore")
 	}
 
-	opts := badgerhold.DefaultOptions
-	opts.Dir = path
-	opts.ValueDir = path
-
-	if err := os.MkdirAll(path, 0700); err != nil {
-		return err
-	}
-
-	badgerStore, err := badgerhold.Open(opts)
+	directories, err := shardDirectories(path, shardCount)
 	if err != nil {
 		return err
 	}
 
-	bundleDirectory := filepath.Join(path, "bundles")
-	if err := os.MkdirAll(bundleDirectory, 0700); err != nil {
-		return err
+	bst := &BundleStore{
+		nodeID: nodeID,
+		path:   path,
+		shards: make([]*storeShard, 0, len(directories)),
+	}
+	for _, directory := range directories {
+		shard, err := openShard(directory, false)
+		if err != nil {
+			return multierror.Append(err, bst.closeShards())
+		}
+		bst.shards = append(bst.shards, shard)
 	}
 
-	storeSingleton = &BundleStore{nodeID: nodeID, metadataStore: badgerStore, bundleDirectory: bundleDirectory}
+	if _, err := bst.rebuildKnownBundles(); err != nil {
+		return multierror.Append(err, bst.closeShards())
+	}
+	err = bst.forEachShard(func(shard *storeShard) error {
+		usedBytes, err := bundleDirectorySize(shard.bundleDirectory)
+		if err != nil {
+			return err
+		}
+		bst.usedBytes.Add(usedBytes)
+		return nil
+	})
+	if err != nil {
+		return multierror.Append(err, bst.closeShards())
+	}
 
+	storeSingleton = bst
 	return nil
 }
 
@@ -67,64 +106,148 @@ func GetStoreSingleton() *BundleStore {
 }
 
 func (bst *BundleStore) Close() error {
-	err := bst.metadataStore.Close()
+	bst.SetCompactionInterval(0)
+	bst.compactionMutex.Lock()
+	defer bst.compactionMutex.Unlock()
+
+	err := bst.closeShards()
 	storeSingleton = nil
 	return err
 }
 
 func (bst *BundleStore) LoadBundleDescriptor(bundleId bpv7.BundleID) (*BundleDescriptor, error) {
-	idString := bundleId.String()
+	return bst.LoadBundleDescriptorByIDString(bundleId.String())
+}
+
+// LoadBundleDescriptorByIDString loads a BundleDescriptor by its IDString, e.g., as supplied through an external API.
+func (bst *BundleStore) LoadBundleDescriptorByIDString(idString string) (*BundleDescriptor, error) {
 	bd := BundleDescriptor{}
-	err := bst.metadataStore.Get(idString, &bd)
+	err := bst.get(idString, &bd)
 	return &bd, err
 }
 
-func (bst *BundleStore) GetWithConstraint(constraint Constraint) ([]*BundleDescriptor, error) {
-	bundles := make([]BundleDescriptor, 0)
-	err := bst.metadataStore.Find(&bundles, badgerhold.Where("RetentionConstraints").Contains(constraint))
-	if err != nil {
-		return nil, err
+// LoadBundleByIDString fetches a bundle by its IDString, e.g., for an external API or a retransmission. The returned
+// BundleDescriptor carries the bundle's metadata. Its Bundle, including the payload, is only loaded from disk if
+// withBundle is set. An unknown bundle results in an ErrNotFound.
+func (bst *BundleStore) LoadBundleByIDString(idString string, withBundle bool) (*BundleDescriptor, error) {
+	bd, err := bst.LoadBundleDescriptorByIDString(idString)
+	if err != nil || !withBundle {
+		return bd, err
+	}
+	bd.Bundle, err = bst.loadEntireBundle(bd)
+	return bd, err
+}
+
+// addKnownBundle adds an inserted bundle to the Bloom filter of known bundles and to the one being rebuilt.
+func (bst *BundleStore) addKnownBundle(idString string) {
+	bst.knownBundles.Load().Add(idString)
+	if rebuilt := bst.rebuiltKnownBundles.Load(); rebuilt != nil {
+		rebuilt.Add(idString)
+	}
+}
+
+// rebuildKnownBundles replaces the Bloom filter of known bundles by one of the stored bundles, dropping the deleted
+// ones. It is dimensioned for at least twice the stored bundles, keeping its false positive rate as the store grows.
+// The rebuilt filter gets the inserted bundles from its creation on, thus not missing those inserted while the
+// metadata is read. It returns the number of stored bundles.
+func (bst *BundleStore) rebuildKnownBundles() (int, error) {
+	bst.rebuildMutex.Lock()
+	defer bst.rebuildMutex.Unlock()
+
+	var count atomic.Int64
+	if err := bst.forEachShard(func(shard *storeShard) error {
+		n, err := shard.metadataStore.Count(&BundleDescriptor{}, nil)
+		count.Add(int64(n))
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	rebuilt := newBloomFilter(max(knownBundlesExpected, 2*uint64(count.Load())), knownBundlesFalsePositive)
+	bst.rebuiltKnownBundles.Store(rebuilt)
+	defer bst.rebuiltKnownBundles.Store(nil)
+
+	count.Store(0)
+	if err := bst.forEachShard(func(shard *storeShard) error {
+		return shard.metadataStore.ForEach(nil, func(bd *BundleDescriptor) error {
+			rebuilt.Add(bd.IDString)
+			count.Add(1)
+			return nil
+		})
+	}); err != nil {
+		return 0, err
 	}
 
-	ptrs := make([]*BundleDescriptor, len(bundles))
-	for i, bndl := range bundles {
-		ptrs[i] = &bndl
+	bst.knownBundles.Store(rebuilt)
+	return int(count.Load()), nil
+}
+
+// KnownBundle checks whether a bundle with this ID is present in the store.
+// The Bloom filter answers most negative requests, only probable hits result in a metadata lookup.
+func (bst *BundleStore) KnownBundle(bundleId bpv7.BundleID) bool {
+	idString := bundleId.String()
+	if !bst.knownBundles.Load().MayContain(idString) {
+		return false
 	}
 
-	return ptrs, nil
+	bd := BundleDescriptor{}
+	return bst.get(idString, &bd) == nil
+}
+
+func (bst *BundleStore) GetWithConstraint(constraint Constraint) ([]*BundleDescriptor, error) {
+	return bst.find(func() *badgerhold.Query { return badgerhold.Where("RetentionConstraints").Contains(constraint) })
+}
+
+// GetAll returns the descriptors of all stored bundles.
+func (bst *BundleStore) GetAll() ([]*BundleDescriptor, error) {
+	return bst.find(nil)
 }
 
-func (bst *BundleStore) GetDispatchable() ([]*BundleDescriptor, error) {
-	bundles := make([]BundleDescriptor, 0)
-	err := bst.metadataStore.Find(&bundles, badgerhold.Where("Dispatch").Eq(true))
+// ForgetSent removes peers from the already sent lists of all bundles, e.g., if previous transmissions are suspected
+// to be lost. Without any peers, the lists are cleared entirely. It returns the number of changed bundles.
+func (bst *BundleStore) ForgetSent(peers ...bpv7.EndpointID) (int, error) {
+	bundles, err := bst.GetAll()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	ptrs := make([]*BundleDescriptor, len(bundles))
+	changed := 0
+	var errs error
 	for i := range bundles {
-		ptrs[i] = &bundles[i]
+		if ok, err := bundles[i].RemoveAlreadySent(peers...); err != nil {
+			errs = multierror.Append(errs, err)
+		} else if ok {
+			changed++
+		}
 	}
 
-	return ptrs, nil
+	return changed, errs
 }
 
-func (bst *BundleStore) loadEntireBundle(filename string) (*bpv7.Bundle, error) {
-	path := filepath.Join(bst.bundleDirectory, filename)
+func (bst *BundleStore) loadEntireBundle(bundleDescriptor *BundleDescriptor) (*bpv7.Bundle, error) {
+	path := filepath.Join(bst.shardFor(bundleDescriptor.Destination).bundleDirectory, bundleDescriptor.SerialisedFileName)
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, notFound(bundleDescriptor.IDString, err)
 	}
 	defer f.Close()
 
 	bundle, err := bpv7.ParseBundle(f)
-
+	if err != nil {
+		return nil, err
+	}
 	return &bundle, nil
 }
 
 func (bst *BundleStore) insertNewBundle(bundle *bpv7.Bundle) (*BundleDescriptor, error) {
 	log.WithField("bundle", bundle.ID().String()).Debug("Inserting new bundle")
-	lifetimeDuration := time.Millisecond * time.Duration(bundle.PrimaryBlock.Lifetime)
+	now := clock.Now().UTC().Round(0)
+	expires := bundle.PrimaryBlock.CreationTimestamp.DtnTime().Time().Add(
+		time.Millisecond * time.Duration(bundle.PrimaryBlock.Lifetime))
+	if bundle.PrimaryBlock.CreationTimestamp.IsZeroTime() || bpv7.IsClockless() {
+		// Without a creation time to compare with, the expiry is derived from the bundle's age at its reception
+		expires = now.Add(bundle.RemainingLifetime())
+	}
 	serialisedFileName := fmt.Sprintf("%x", sha256.Sum256([]byte(bundle.ID().String())))
 	bd := BundleDescriptor{
 		ID:                   bundle.ID(),
@@ -132,30 +255,39 @@ func (bst *BundleStore) insertNewBundle(bundle *bpv7.Bundle) (*BundleDescriptor,
 		Source:               bundle.PrimaryBlock.SourceNode,
 		Destination:          bundle.PrimaryBlock.Destination,
 		ReportTo:             bundle.PrimaryBlock.ReportTo,
-		AlreadySentTo:        []bpv7.EndpointID{bst.nodeID},
+		Priority:             bundle.Priority(),
+		Size:                 serialisedSize(bundle),
+		SentTo:               SentList{bst.nodeID},
 		RetentionConstraints: []Constraint{DispatchPending},
 		Retain:               false,
 		Dispatch:             true,
-		Expires:              bundle.PrimaryBlock.CreationTimestamp.DtnTime().Time().Add(lifetimeDuration),
+		Expires:              expires,
+		Received:             now,
 		SerialisedFileName:   serialisedFileName,
 		Bundle:               nil,
 	}
 
 	if previousNodeBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
 		previousNode := previousNodeBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
-		bd.AlreadySentTo = append(bd.AlreadySentTo, previousNode)
+		bd.addSent(bst.nodeID, previousNode)
 		log.WithFields(log.Fields{
 			"bundle": bd.ID,
 			"sender": previousNode,
-		}).Debug("Added sender to AlreadySentTo")
+		}).Debug("Added sender to already sent")
 	}
 
-	err := storeSingleton.metadataStore.Insert(bd.IDString, bd)
+	if err := fault_injection.StoreWrite(); err != nil {
+		return nil, err
+	}
+
+	shard := bst.shardFor(bd.Destination)
+	err := shard.metadataStore.Insert(bd.IDString, bd)
 	if err != nil {
 		return nil, err
 	}
+	bst.addKnownBundle(bd.IDString)
 
-	serialisedPath := filepath.Join(bst.bundleDirectory, serialisedFileName)
+	serialisedPath := filepath.Join(shard.bundleDirectory, serialisedFileName)
 	f, err := os.Create(serialisedPath)
 	defer f.Close()
 	if err != nil {
@@ -179,14 +311,39 @@ func (bst *BundleStore) insertNewBundle(bundle *bpv7.Bundle) (*BundleDescriptor,
 	if err != nil {
 		return nil, err
 	}
-	err = w.Flush()
+	if err = w.Flush(); err != nil {
+		return &bd, err
+	}
 
-	return &bd, err
+	if info, statErr := f.Stat(); statErr == nil {
+		bst.usedBytes.Add(info.Size())
+	}
+
+	return &bd, nil
+}
+
+// serialisedSize counts a bundle's bytes when serialised, without buffering them.
+func serialisedSize(bundle *bpv7.Bundle) int64 {
+	var counter byteCounter
+	_ = cboring.Marshal(bundle, &counter)
+	return int64(counter)
+}
+
+type byteCounter int64
+
+func (counter *byteCounter) Write(p []byte) (int, error) {
+	*counter += byteCounter(len(p))
+	return len(p), nil
 }
 
 func (bst *BundleStore) InsertBundle(bundle *bpv7.Bundle) (*BundleDescriptor, error) {
+	if !bst.knownBundles.Load().MayContain(bundle.ID().String()) {
+		log.WithField("bundle", bundle.ID().String()).Debug("Bundle is definitely new")
+		return bst.insertNewBundle(bundle)
+	}
+
 	bd := BundleDescriptor{}
-	err := bst.metadataStore.Get(bundle.ID().String(), &bd)
+	err := bst.shardFor(bundle.PrimaryBlock.Destination).metadataStore.Get(bundle.ID().String(), &bd)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"bundle": bundle.ID().String(),
@@ -200,7 +357,7 @@ func (bst *BundleStore) InsertBundle(bundle *bpv7.Bundle) (*BundleDescriptor, er
 	var uerr error
 	if previousNodeBlock, err := bundle.ExtensionBlock(bpv7.ExtBlockTypePreviousNodeBlock); err == nil {
 		previousNode := previousNodeBlock.Value.(*bpv7.PreviousNodeBlock).Endpoint()
-		bd.AlreadySentTo = append(bd.AlreadySentTo, previousNode)
+		bd.addSent(bst.nodeID, previousNode)
 		uerr = bst.updateBundleMetadata(&bd)
 	}
 
@@ -208,15 +365,27 @@ func (bst *BundleStore) InsertBundle(bundle *bpv7.Bundle) (*BundleDescriptor, er
 }
 
 func (bst *BundleStore) updateBundleMetadata(bundleDescriptor *BundleDescriptor) error {
+	if err := fault_injection.StoreWrite(); err != nil {
+		return err
+	}
+
 	bndl := bundleDescriptor.Bundle
 	bundleDescriptor.Bundle = nil
-	err := bst.metadataStore.Update(bundleDescriptor.IDString, bundleDescriptor)
+	err := bst.shardFor(bundleDescriptor.Destination).metadataStore.Update(bundleDescriptor.IDString, bundleDescriptor)
 	bundleDescriptor.Bundle = bndl
-	return err
+	return notFound(bundleDescriptor.IDString, err)
 }
 
 func (bst *BundleStore) DeleteBundle(bundleDescriptor *BundleDescriptor) error {
-	err := bst.metadataStore.Delete(bundleDescriptor.IDString, bundleDescriptor)
-	err = multierror.Append(os.Remove(bundleDescriptor.SerialisedFileName))
+	shard := bst.shardFor(bundleDescriptor.Destination)
+	err := shard.metadataStore.Delete(bundleDescriptor.IDString, bundleDescriptor)
+
+	serialisedPath := filepath.Join(shard.bundleDirectory, bundleDescriptor.SerialisedFileName)
+	info, statErr := os.Stat(serialisedPath)
+	if rmErr := os.Remove(serialisedPath); rmErr != nil {
+		return multierror.Append(err, rmErr).ErrorOrNil()
+	} else if statErr == nil {
+		bst.usedBytes.Add(-info.Size())
+	}
 	return err
 }
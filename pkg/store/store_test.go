@@ -1,10 +1,13 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"pgregory.net/rapid"
 
@@ -62,6 +65,20 @@ func TestBundleInsertion(t *testing.T) {
 		if !reflect.DeepEqual(bundle, bundleLoad) {
 			t.Fatal("Retrieved Bundle not equal")
 		}
+
+		bdMeta, err := GetStoreSingleton().LoadBundleByIDString(bundle.ID().String(), false)
+		if err != nil {
+			t.Fatal(err)
+		} else if bdMeta.Bundle != nil {
+			t.Fatal("Bundle was loaded without being requested")
+		}
+
+		bdFull, err := GetStoreSingleton().LoadBundleByIDString(bundle.ID().String(), true)
+		if err != nil {
+			t.Fatal(err)
+		} else if bdFull.Bundle == nil || !reflect.DeepEqual(bundle, *bdFull.Bundle) {
+			t.Fatal("Bundle retrieved by its ID not equal")
+		}
 	})
 }
 
@@ -104,6 +121,37 @@ func TestConstraints(t *testing.T) {
 	})
 }
 
+func TestNotFound(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = GetStoreSingleton().DeleteBundle(bd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = GetStoreSingleton().LoadBundleDescriptor(bundle.ID())
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Deleted bundle was not reported as missing: %v", err)
+		}
+
+		err = bd.AddConstraint(DispatchPending)
+		var conflictErr *ConstraintConflictError
+		if !errors.As(err, &conflictErr) || !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Constraint of deleted bundle did not conflict: %v", err)
+		}
+		if conflictErr.Constraint != DispatchPending {
+			t.Fatalf("Conflict names constraint %v", conflictErr.Constraint)
+		}
+	})
+}
+
 func addConstraints(t *rapid.T, bd *BundleDescriptor, constraints []Constraint) {
 	for _, constraint := range constraints {
 		err := bd.AddConstraint(constraint)
@@ -148,3 +196,316 @@ func removeConstraints(t *rapid.T, bd *BundleDescriptor, constraints []Constrain
 		}
 	}
 }
+
+func TestMetadata(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := make(map[string]string)
+		numOps := rapid.IntRange(1, 20).Draw(t, "Number of operations")
+		for i := 0; i < numOps; i++ {
+			key := rapid.SampledFrom([]string{"copies", "class", "spray"}).Draw(t, fmt.Sprintf("key %v", i))
+
+			if rapid.Bool().Draw(t, fmt.Sprintf("delete %v", i)) {
+				delete(expected, key)
+				err = bd.DeleteMetadata(key)
+			} else {
+				value := rapid.String().Draw(t, fmt.Sprintf("value %v", i))
+				expected[key] = value
+				err = bd.SetMetadata(key, value)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bdLoad, err := GetStoreSingleton().LoadBundleDescriptor(bd.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata := bdLoad.GetAllMetadata(); !reflect.DeepEqual(metadata, expected) {
+				t.Fatalf("Loaded metadata %v differs from expected %v", metadata, expected)
+			}
+			for key, value := range expected {
+				if loadValue, ok := bdLoad.GetMetadata(key); !ok || loadValue != value {
+					t.Fatalf("Metadata %q is %q, %t, expected %q", key, loadValue, ok, value)
+				}
+			}
+		}
+	})
+}
+
+func TestQuarantine(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		attempts := rapid.UintRange(1, 5).Draw(t, "Number of failed attempts")
+		for i := uint(1); i <= attempts; i++ {
+			if n, err := bd.FailAttempt(); err != nil {
+				t.Fatal(err)
+			} else if n != i {
+				t.Fatalf("Attempt %d was counted as %d", i, n)
+			}
+		}
+		if bdLoad, err := GetStoreSingleton().LoadBundleDescriptor(bd.ID); err != nil {
+			t.Fatal(err)
+		} else if bdLoad.Attempts != attempts {
+			t.Fatalf("Loaded %d instead of %d attempts", bdLoad.Attempts, attempts)
+		}
+
+		if rapid.Bool().Draw(t, "Finish attempt") {
+			if err := bd.EndAttempt(); err != nil {
+				t.Fatal(err)
+			}
+			if bdLoad, err := GetStoreSingleton().LoadBundleDescriptor(bd.ID); err != nil {
+				t.Fatal(err)
+			} else if bdLoad.Attempts != 0 {
+				t.Fatalf("Finished attempt left %d attempts", bdLoad.Attempts)
+			}
+			return
+		}
+
+		if err := bd.Quarantine(); err != nil {
+			t.Fatal(err)
+		}
+		if bdLoad, err := GetStoreSingleton().LoadBundleDescriptor(bd.ID); err != nil {
+			t.Fatal(err)
+		} else if !bdLoad.Quarantined || len(bdLoad.RetentionConstraints) != 0 {
+			t.Fatalf("Quarantined bundle was loaded as %v with constraints %v", bdLoad.Quarantined, bdLoad.RetentionConstraints)
+		}
+		if bds, err := GetStoreSingleton().GetDispatchable(DispatchQuery{}); err != nil {
+			t.Fatal(err)
+		} else if len(bds) != 0 {
+			t.Fatal("Quarantined bundle is dispatchable")
+		}
+
+		// the quarantined bundle is kept by compaction until its expiry
+		expired := rapid.Bool().Draw(t, "Expired")
+		bd.Expires = time.Now().Add(time.Hour)
+		if expired {
+			bd.Expires = time.Now().Add(-time.Second)
+		}
+		if err := GetStoreSingleton().updateBundleMetadata(bd); err != nil {
+			t.Fatal(err)
+		}
+		report, err := GetStoreSingleton().Compact()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, loadErr := GetStoreSingleton().LoadBundleDescriptor(bd.ID)
+		if expired && (report.ExpiredQuarantined != 1 || !errors.Is(loadErr, ErrNotFound)) {
+			t.Fatalf("Compaction deleted %d bundles, expired quarantined bundle is loaded: %v", report.ExpiredQuarantined, loadErr)
+		} else if !expired && (report.ExpiredQuarantined != 0 || loadErr != nil) {
+			t.Fatalf("Compaction deleted %d bundles, quarantined bundle is loaded: %v", report.ExpiredQuarantined, loadErr)
+		}
+	})
+}
+
+func TestForgetSent(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		nodeID := GetStoreSingleton().nodeID
+		peerA := bpv7.MustNewEndpointID("dtn://peer-a/")
+		peerB := bpv7.MustNewEndpointID("dtn://peer-b/")
+
+		numBundles := rapid.IntRange(1, 5).Draw(t, "Number of bundles")
+		sentToA := 0
+		for i := 0; i < numBundles; i++ {
+			bundle := bpv7.GenerateBundle(t, i)
+			bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bd.AddAlreadySent(peerB)
+			if rapid.Bool().Draw(t, fmt.Sprintf("sent to A %v", i)) {
+				bd.AddAlreadySent(peerA)
+				sentToA++
+			}
+		}
+
+		if changed, err := GetStoreSingleton().ForgetSent(bpv7.MustNewEndpointID("dtn://peer-a/foo")); err != nil {
+			t.Fatal(err)
+		} else if changed != sentToA {
+			t.Fatalf("Forgetting peer A changed %d bundles, expected %d", changed, sentToA)
+		}
+
+		if changed, err := GetStoreSingleton().ForgetSent(); err != nil {
+			t.Fatal(err)
+		} else if changed != numBundles {
+			t.Fatalf("Forgetting all peers changed %d bundles, expected %d", changed, numBundles)
+		}
+
+		bds, err := GetStoreSingleton().GetDispatchable(DispatchQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bds) != numBundles {
+			t.Fatalf("Store contains %d dispatchable bundles, expected %d", len(bds), numBundles)
+		}
+		for _, bd := range bds {
+			if sent := bd.GetAlreadySent(); len(sent) != 1 || sent[0] != nodeID {
+				t.Fatalf("Already sent list %v does not only contain the own node %v", sent, nodeID)
+			}
+		}
+	})
+}
+
+func TestOccupancy(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		if used := GetStoreSingleton().Occupancy().Used; used != 0 {
+			t.Fatalf("Empty store uses %d bytes", used)
+		}
+
+		numBundles := rapid.IntRange(1, 5).Draw(t, "Number of bundles")
+		var descriptorSizes int64
+		for i := 0; i < numBundles; i++ {
+			bundle := bpv7.GenerateBundle(t, i)
+			bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			descriptorSizes += bd.Size
+		}
+
+		occupancy := GetStoreSingleton().Occupancy()
+		if size, err := bundleDirectorySize(GetStoreSingleton().shards[0].bundleDirectory); err != nil {
+			t.Fatal(err)
+		} else if occupancy.Used != size || size == 0 {
+			t.Fatalf("Store reports %d used bytes, bundle directory has %d", occupancy.Used, size)
+		} else if descriptorSizes != size {
+			t.Fatalf("Descriptors list %d bytes, bundle directory has %d", descriptorSizes, size)
+		}
+		if pressure := occupancy.Pressure(); pressure != 0 {
+			t.Fatalf("Unlimited store is under pressure of %f", pressure)
+		}
+
+		GetStoreSingleton().SetCapacity(2 * occupancy.Used)
+		if pressure := GetStoreSingleton().Occupancy().Pressure(); pressure != 0.5 {
+			t.Fatalf("Half full store is under pressure of %f", pressure)
+		}
+		GetStoreSingleton().SetCapacity(occupancy.Used / 2)
+		if pressure := GetStoreSingleton().Occupancy().Pressure(); pressure != 1 {
+			t.Fatalf("Overfull store is under pressure of %f", pressure)
+		}
+
+		// The occupancy is restored from the bundle directory
+		nodeID := GetStoreSingleton().nodeID
+		if err := GetStoreSingleton().Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := InitialiseStore(nodeID, "/tmp/dtn7-test"); err != nil {
+			t.Fatal(err)
+		}
+		if used := GetStoreSingleton().Occupancy().Used; used != occupancy.Used {
+			t.Fatalf("Reopened store uses %d bytes instead of %d", used, occupancy.Used)
+		}
+	})
+}
+
+func TestSmallFootprint(t *testing.T) {
+	SetSmallFootprint(true)
+	defer SetSmallFootprint(false)
+
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		bundle := bpv7.GenerateBundle(t, 0)
+		if _, err := GetStoreSingleton().insertNewBundle(&bundle); err != nil {
+			t.Fatal(err)
+		}
+
+		bd, err := GetStoreSingleton().LoadBundleDescriptor(bundle.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bundleLoad, err := bd.Load(); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(bundle, bundleLoad) {
+			t.Fatal("Retrieved Bundle not equal")
+		}
+	})
+}
+
+func TestCompact(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		initTest(t)
+		defer cleanupTest(t)
+
+		numBundles := rapid.IntRange(1, 5).Draw(t, "Number of bundles")
+		bds := make([]*BundleDescriptor, numBundles)
+		for i := 0; i < numBundles; i++ {
+			bundle := bpv7.GenerateBundle(t, i)
+			bd, err := GetStoreSingleton().insertNewBundle(&bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bds[i] = bd
+		}
+
+		numDeleted := rapid.IntRange(0, numBundles).Draw(t, "Number of deleted bundles")
+		for _, bd := range bds[:numDeleted] {
+			if err := GetStoreSingleton().DeleteBundle(bd); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		orphan := []byte("left behind by a crash")
+		orphanPath := filepath.Join(GetStoreSingleton().shards[0].bundleDirectory, "orphan")
+		if err := os.WriteFile(orphanPath, orphan, 0600); err != nil {
+			t.Fatal(err)
+		}
+		GetStoreSingleton().usedBytes.Add(int64(len(orphan)))
+
+		report, err := GetStoreSingleton().Compact()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.OrphanedFiles != 1 {
+			t.Fatalf("Compaction removed %d instead of one orphaned file", report.OrphanedFiles)
+		}
+		if report.ReclaimedBytes < int64(len(orphan)) {
+			t.Fatalf("Compaction reclaimed %d bytes, less than the orphan's %d", report.ReclaimedBytes, len(orphan))
+		}
+		if _, err := os.Stat(orphanPath); !os.IsNotExist(err) {
+			t.Fatalf("Orphaned file still exists: %v", err)
+		}
+
+		if report.KnownBundles != numBundles-numDeleted {
+			t.Fatalf("Bloom filter was rebuilt from %d instead of %d bundles", report.KnownBundles, numBundles-numDeleted)
+		}
+
+		for _, bd := range bds[numDeleted:] {
+			if _, err := bd.Load(); err != nil {
+				t.Fatalf("Bundle %v is lost after compaction: %v", bd.ID, err)
+			}
+			if !GetStoreSingleton().KnownBundle(bd.ID) {
+				t.Fatalf("Bundle %v is unknown after rebuilding the Bloom filter", bd.ID)
+			}
+		}
+
+		if size, err := bundleDirectorySize(GetStoreSingleton().shards[0].bundleDirectory); err != nil {
+			t.Fatal(err)
+		} else if used := GetStoreSingleton().Occupancy().Used; used != size {
+			t.Fatalf("Store reports %d used bytes after compaction, bundle directory has %d", used, size)
+		}
+	})
+}
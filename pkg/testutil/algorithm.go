@@ -0,0 +1,84 @@
+package testutil
+
+import (
+	"sync"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// FakeAlgorithm is a routing.Algorithm forwarding each bundle to the peers set by SetPeers. Unlike the real
+// algorithms, it does not skip peers which already received a bundle. All notifications are recorded.
+type FakeAlgorithm struct {
+	mutex       sync.Mutex
+	peers       []cla.ConvergenceSender
+	bundles     []bpv7.BundleID
+	appeared    []bpv7.EndpointID
+	disappeared []bpv7.EndpointID
+}
+
+// NewFakeAlgorithm creates a FakeAlgorithm forwarding to the given peers.
+func NewFakeAlgorithm(peers ...cla.ConvergenceSender) *FakeAlgorithm {
+	return &FakeAlgorithm{peers: peers}
+}
+
+// SetPeers replaces the peers selected for all following bundles. No peers contraindicate the forwarding.
+func (algorithm *FakeAlgorithm) SetPeers(peers ...cla.ConvergenceSender) {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	algorithm.peers = peers
+}
+
+func (algorithm *FakeAlgorithm) NotifyNewBundle(descriptor *store.BundleDescriptor) {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	algorithm.bundles = append(algorithm.bundles, descriptor.ID)
+}
+
+func (algorithm *FakeAlgorithm) SelectPeersForForwarding(*store.BundleDescriptor) []cla.ConvergenceSender {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	return append([]cla.ConvergenceSender(nil), algorithm.peers...)
+}
+
+func (algorithm *FakeAlgorithm) NotifyPeerAppeared(peer bpv7.EndpointID) {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	algorithm.appeared = append(algorithm.appeared, peer)
+}
+
+func (algorithm *FakeAlgorithm) NotifyPeerDisappeared(peer bpv7.EndpointID) {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	algorithm.disappeared = append(algorithm.disappeared, peer)
+}
+
+// NewBundles returns the IDs of all bundles the algorithm was notified about.
+func (algorithm *FakeAlgorithm) NewBundles() []bpv7.BundleID {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	return append([]bpv7.BundleID(nil), algorithm.bundles...)
+}
+
+// AppearedPeers returns all peers the algorithm was notified of appearing.
+func (algorithm *FakeAlgorithm) AppearedPeers() []bpv7.EndpointID {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	return append([]bpv7.EndpointID(nil), algorithm.appeared...)
+}
+
+// DisappearedPeers returns all peers the algorithm was notified of disappearing.
+func (algorithm *FakeAlgorithm) DisappearedPeers() []bpv7.EndpointID {
+	algorithm.mutex.Lock()
+	defer algorithm.mutex.Unlock()
+
+	return append([]bpv7.EndpointID(nil), algorithm.disappeared...)
+}
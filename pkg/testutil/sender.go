@@ -0,0 +1,121 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+)
+
+// FakeSender is a cla.ConvergenceSender recording its sent bundles. Its transmissions fail as scripted by FailNext.
+type FakeSender struct {
+	address string
+	peer    bpv7.EndpointID
+
+	mutex    sync.Mutex
+	active   bool
+	failures []error
+	sent     []bpv7.Bundle
+	attempts int
+}
+
+// NewFakeSender creates an active FakeSender to the peer, identified by its unique address.
+func NewFakeSender(address string, peer bpv7.EndpointID) *FakeSender {
+	return &FakeSender{address: address, peer: peer, active: true}
+}
+
+// FailNext scripts the results of the next transmissions, one error per Send. A nil error lets its transmission
+// succeed. Afterwards, all transmissions succeed again.
+func (sender *FakeSender) FailNext(errs ...error) {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	sender.failures = append(sender.failures, errs...)
+}
+
+// Send records the bundle, unless a scripted failure is next.
+func (sender *FakeSender) Send(bndl bpv7.Bundle) error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	sender.attempts++
+	if len(sender.failures) > 0 {
+		err := sender.failures[0]
+		sender.failures = sender.failures[1:]
+		if err != nil {
+			return err
+		}
+	}
+
+	sender.sent = append(sender.sent, bndl)
+	return nil
+}
+
+// Sent returns the successfully sent bundles in their order.
+func (sender *FakeSender) Sent() []bpv7.Bundle {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	return append([]bpv7.Bundle(nil), sender.sent...)
+}
+
+// Attempts returns the number of transmissions, including the failed ones.
+func (sender *FakeSender) Attempts() int {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	return sender.attempts
+}
+
+// WaitSent waits until at least n bundles were sent, as the processing forwards bundles asynchronously. It returns
+// false if the timeout passed before.
+func (sender *FakeSender) WaitSent(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		sender.mutex.Lock()
+		sent := len(sender.sent)
+		sender.mutex.Unlock()
+
+		if sent >= n {
+			return true
+		} else if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (sender *FakeSender) GetPeerEndpointID() bpv7.EndpointID {
+	return sender.peer
+}
+
+func (sender *FakeSender) Address() string {
+	return sender.address
+}
+
+func (sender *FakeSender) Activate() error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	sender.active = true
+	return nil
+}
+
+func (sender *FakeSender) Active() bool {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	return sender.active
+}
+
+func (sender *FakeSender) Close() error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	sender.active = false
+	return nil
+}
+
+func (sender *FakeSender) String() string {
+	return sender.address
+}
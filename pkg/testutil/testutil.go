@@ -0,0 +1,87 @@
+// Package testutil provides test doubles for the bundle processing, such that routing algorithms, application agents
+// and other extensions can be unit tested without standing up a full node.
+//
+// StartNode initialises all singletons the processing relies on: a store within the test's temporary directory, the
+// CLA and application agent managers, the IdKeeper and the routing algorithm, e.g., a FakeAlgorithm. Peers are
+// FakeSenders, which record the bundles sent to them and fail as scripted.
+//
+//	algorithm := testutil.NewFakeAlgorithm()
+//	testutil.StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), algorithm)
+//
+//	peer := testutil.NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+//	algorithm.SetPeers(peer)
+//
+//	processing.ReceiveBundle(&bndl)
+//	if !peer.WaitSent(1, time.Second) {
+//	  t.Fatal("Bundle was not forwarded")
+//	}
+//
+// Simulations of long scenarios, e.g., a day-long contact plan, might compress the node's time by CompressTime, such
+// that lifetimes, tickers and timers pass a constant factor faster than real time.
+//
+//	testutil.CompressTime(t, 100) // a day passes within 14.4 minutes
+//
+// As the processing uses singletons, tests using StartNode must not run in parallel.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/dtn7/dtn7-go/pkg/application_agent"
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/clock"
+	"github.com/dtn7/dtn7-go/pkg/id_keeper"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+	"github.com/dtn7/dtn7-go/pkg/routing"
+	"github.com/dtn7/dtn7-go/pkg/store"
+)
+
+// StartNode initialises the processing's singletons for a node of the given ID, routing by the algorithm. Everything
+// is shut down again by the test's cleanup.
+func StartNode(t testing.TB, nodeID bpv7.EndpointID, algorithm routing.Algorithm) {
+	t.Helper()
+
+	processing.SetOwnNodeID(nodeID)
+
+	if err := store.InitialiseStore(nodeID, t.TempDir()); err != nil {
+		t.Fatalf("Initialising store failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.GetStoreSingleton().Close() })
+
+	if err := id_keeper.InitializeIdKeeper(); err != nil {
+		t.Fatalf("Initialising IdKeeper failed: %v", err)
+	}
+	t.Cleanup(id_keeper.ShutdownIdKeeper)
+
+	if err := routing.InitialiseCustomAlgorithm(algorithm); err != nil {
+		t.Fatalf("Initialising routing algorithm failed: %v", err)
+	}
+	t.Cleanup(routing.ShutdownAlgorithm)
+
+	if err := cla.InitialiseCLAManager(processing.ReceiveBundle); err != nil {
+		t.Fatalf("Initialising CLA manager failed: %v", err)
+	}
+	t.Cleanup(func() { cla.GetManagerSingleton().Shutdown() })
+
+	if err := application_agent.InitialiseApplicationAgentManager(processing.SendBundle); err != nil {
+		t.Fatalf("Initialising application agent manager failed: %v", err)
+	}
+	t.Cleanup(func() { application_agent.GetManagerSingleton().Shutdown() })
+
+	// Cleanups run in reverse order. Thus, the bundles still being processed are finished before the shutdown.
+	t.Cleanup(processing.Wait)
+}
+
+// CompressTime runs the clock factor times faster than real time, starting at the current time, until the test's
+// cleanup restores the previous clock. Real-time timeouts of the test itself, e.g., of FakeSender.WaitSent, are not
+// compressed.
+func CompressTime(t testing.TB, factor float64) *clock.ScaledClock {
+	t.Helper()
+
+	previous := clock.GetClock()
+	scaled := clock.NewScaledClock(previous.Now(), factor)
+	clock.SetClock(scaled)
+	t.Cleanup(func() { clock.SetClock(previous) })
+	return scaled
+}
@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/processing"
+)
+
+func TestForwarding(t *testing.T) {
+	algorithm := NewFakeAlgorithm()
+	StartNode(t, bpv7.MustNewEndpointID("dtn://node/"), algorithm)
+
+	peer := NewFakeSender("fake://peer", bpv7.MustNewEndpointID("dtn://peer/"))
+	unreachable := NewFakeSender("fake://unreachable", bpv7.MustNewEndpointID("dtn://unreachable/"))
+	unreachable.FailNext(errors.New("link down"))
+	algorithm.SetPeers(peer, unreachable)
+
+	bndl, err := bpv7.Builder().
+		Source("dtn://src/").
+		Destination("dtn://dst/").
+		CreationTimestampNow().
+		Lifetime("1h").
+		PayloadBlock([]byte("hello world")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processing.ReceiveBundle(&bndl)
+	if !peer.WaitSent(1, 5*time.Second) {
+		t.Fatal("Bundle was not forwarded")
+	}
+	if sent := peer.Sent(); sent[0].ID() != bndl.ID() {
+		t.Fatalf("Peer got bundle %v instead of %v", sent[0].ID(), bndl.ID())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for unreachable.Attempts() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if attempts, sent := unreachable.Attempts(), len(unreachable.Sent()); attempts != 1 || sent != 0 {
+		t.Fatalf("Failing peer had %d attempts and %d sent bundles", attempts, sent)
+	}
+
+	if bundles := algorithm.NewBundles(); len(bundles) != 1 || bundles[0] != bndl.ID() {
+		t.Fatalf("Algorithm was notified about %v", bundles)
+	}
+}
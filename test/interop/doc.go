@@ -0,0 +1,16 @@
+// Package interop contains integration tests exchanging bundles between dtn7-go and other Bundle Protocol Version 7
+// implementations, each running inside a Docker container.
+//
+// The tests are guarded by the "interop" build tag and require a local Docker daemon. Each implementation's image is
+// taken from an environment variable; implementations without a configured image are skipped.
+//
+//	DTN7RS_IMAGE=<dtn7-rs image> go test -tags interop -v ./test/interop/
+//
+// dtn7-go's side is a running dtnd, built from this tree or taken from DTND_BINARY. Its applications are driven
+// through its REST agent on DTN7GO_REST_ADDRESS (default "127.0.0.1:18080"), and it connects to the other
+// implementation through its MTCP listener on DTN7GO_MTCP_ADDRESS (default "127.0.0.1:16162"). Both share the host's
+// network. TestGoNode checks this side on its own, without Docker.
+//
+// There is no interoperability with ION and DTNME yet: among the convergence layers, they only speak TCPCLv4, which
+// is not implemented by dtnd. Their tests are skipped until it is.
+package interop
@@ -0,0 +1,330 @@
+//go:build interop
+
+package interop
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/cla"
+	"github.com/dtn7/dtn7-go/pkg/cla/mtcp"
+	"github.com/dtn7/dtn7-go/pkg/client"
+)
+
+const (
+	// interopTimeout limits each wait for a bundle or a peer.
+	interopTimeout = 30 * time.Second
+	// pollInterval between attempts to connect or to fetch bundles.
+	pollInterval = 500 * time.Millisecond
+)
+
+// goNodeID is dtn7-go's node ID within all tests.
+var goNodeID = bpv7.MustNewEndpointID("dtn://go-node/")
+
+// goMTCPAddress is the address of dtnd's MTCP listener.
+func goMTCPAddress() string {
+	if address, ok := os.LookupEnv("DTN7GO_MTCP_ADDRESS"); ok {
+		return address
+	}
+	return "127.0.0.1:16162"
+}
+
+// goRESTAddress is the address of dtnd's REST agent.
+func goRESTAddress() string {
+	if address, ok := os.LookupEnv("DTN7GO_REST_ADDRESS"); ok {
+		return address
+	}
+	return "127.0.0.1:18080"
+}
+
+// peerApplication is the application interface of another implementation, used to register endpoints and to send
+// and receive bundles.
+type peerApplication interface {
+	Register(endpoint string) error
+	Send(destination bpv7.EndpointID, payload []byte) error
+	// Receive returns the next bundle for an endpoint; ok is false if there was none.
+	Receive(endpoint string) (bndl bpv7.Bundle, ok bool, err error)
+}
+
+// peerImplementation describes another BPv7 implementation running within a container.
+type peerImplementation struct {
+	name string
+	// imageEnv is the environment variable holding this implementation's Docker image.
+	imageEnv string
+	// unsupported explains why this implementation cannot be tested, if non-empty.
+	unsupported string
+
+	nodeID      bpv7.EndpointID
+	mtcpAddress string
+	// args are passed to the container, which should connect to dtnd's MTCP listener at the given address.
+	args func(goAddress string) []string
+	app  peerApplication
+}
+
+// startContainer runs the implementation's image on the host's network until the test ends.
+func (impl peerImplementation) startContainer(t *testing.T) {
+	image := os.Getenv(impl.imageEnv)
+	if image == "" {
+		t.Skipf("%s is not set", impl.imageEnv)
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not available")
+	}
+
+	args := append([]string{"run", "--detach", "--rm", "--network", "host", image}, impl.args(goMTCPAddress())...)
+	out, err := exec.Command("docker", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Starting %s container failed: %v\n%s", impl.name, err, out)
+	}
+
+	container := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		if t.Failed() {
+			logs, _ := exec.Command("docker", "logs", container).CombinedOutput()
+			t.Logf("%s container logs:\n%s", impl.name, logs)
+		}
+		_ = exec.Command("docker", "rm", "--force", container).Run()
+	})
+}
+
+// eventually calls f until it succeeds or the interopTimeout expires.
+func eventually(t *testing.T, what string, f func() (bool, error)) {
+	t.Helper()
+
+	var lastErr error
+	for deadline := time.Now().Add(interopTimeout); time.Now().Before(deadline); time.Sleep(pollInterval) {
+		ok, err := f()
+		if ok {
+			return
+		}
+		lastErr = err
+	}
+	t.Fatalf("Timeout while waiting for %s, last error: %v", what, lastErr)
+}
+
+// goNode is dtn7-go's side of a test, a running dtnd. Applications on it are driven through its REST agent.
+type goNode struct {
+	restURL string
+	output  bytes.Buffer
+}
+
+// dtndBinary returns the dtnd binary from DTND_BINARY or builds it from this tree.
+func dtndBinary(t *testing.T) string {
+	if binary, ok := os.LookupEnv("DTND_BINARY"); ok {
+		return binary
+	}
+
+	binary := filepath.Join(t.TempDir(), "dtnd")
+	if out, err := exec.Command("go", "build", "-o", binary, "github.com/dtn7/dtn7-go/cmd/dtnd").CombinedOutput(); err != nil {
+		t.Fatalf("Building dtnd failed: %v\n%s", err, out)
+	}
+	return binary
+}
+
+// dtndConfig is dtnd's configuration, connecting to the peer implementation as a static MTCP peer.
+func dtndConfig(storePath string, impl peerImplementation) string {
+	return fmt.Sprintf(`
+node_id = "%s"
+log_level = "Debug"
+
+[Store]
+path = "%s"
+
+[Routing]
+algorithm = "epidemic"
+
+[Agents.REST]
+address = "%s"
+
+[[Listener]]
+type = "MTCP"
+address = "%s"
+
+[[Peer]]
+type = "MTCP"
+address = "%s"
+endpoint_id = "%s"
+
+[PeerLiveness]
+probe_interval = "1s"
+down_after = "1m"
+
+[Discovery]
+ipv4 = false
+ipv6 = false
+
+[Dispatch]
+interval = "1s"
+`, goNodeID, storePath, goRESTAddress(), goMTCPAddress(), impl.mtcpAddress, impl.nodeID)
+}
+
+// startGoNode runs dtnd until the test ends. Its output is logged if the test fails.
+func startGoNode(t *testing.T, impl peerImplementation) *goNode {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "dtnd.toml")
+	if err := os.WriteFile(configFile, []byte(dtndConfig(filepath.Join(dir, "store"), impl)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	node := &goNode{restURL: "http://" + goRESTAddress()}
+
+	cmd := exec.Command(dtndBinary(t), configFile)
+	cmd.Stdout = &node.output
+	cmd.Stderr = &node.output
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Starting dtnd failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		_ = cmd.Wait()
+		if t.Failed() {
+			t.Logf("dtnd output:\n%s", node.output.String())
+		}
+	})
+
+	return node
+}
+
+// connect registers a client for an endpoint at dtnd's REST agent, waiting for dtnd to come up.
+func (node *goNode) connect(t *testing.T, endpoint string) (c *client.Client) {
+	t.Helper()
+
+	eventually(t, "dtnd's REST agent", func() (ok bool, err error) {
+		c, err = client.Connect(node.restURL, endpoint, nil)
+		return err == nil, err
+	})
+	t.Cleanup(func() { _ = c.Close() })
+	return
+}
+
+// inject transmits bundles to dtnd through its MTCP listener, as if they were received from another node.
+func (node *goNode) inject(t *testing.T, bndls ...bpv7.Bundle) {
+	t.Helper()
+
+	// the MTCP client reports to a CLA manager, which is local to this test process
+	if err := cla.InitialiseCLAManager(func(*bpv7.Bundle) {}); err != nil {
+		t.Fatal(err)
+	}
+	defer cla.GetManagerSingleton().Shutdown()
+
+	sender := mtcp.NewMTCPClient(goMTCPAddress(), goNodeID)
+	if err := sender.Activate(); err != nil {
+		t.Fatalf("Connecting to dtnd's MTCP listener failed: %v", err)
+	}
+	defer sender.Close()
+
+	for _, bndl := range bndls {
+		if err := sender.Send(bndl); err != nil {
+			t.Fatalf("Injecting bundle %v failed: %v", bndl.ID(), err)
+		}
+	}
+}
+
+// awaitBundle waits for a bundle delivered to the client, discarding all others. As each fetch acknowledges the
+// bundles of the previous one, the awaited bundle is acknowledged by the client's next fetch.
+func awaitBundle(t *testing.T, c *client.Client, what string, predicate func(client.Bundle) bool) (bndl client.Bundle) {
+	t.Helper()
+
+	eventually(t, what, func() (bool, error) {
+		bundles, _, err := c.Fetch()
+		for _, fetched := range bundles {
+			if predicate(fetched) {
+				bndl = fetched
+				return true, nil
+			}
+			t.Logf("Ignoring received bundle from %s", fetched.Source)
+		}
+		return false, err
+	})
+	return
+}
+
+// awaitPeerBundle polls the peer's application interface for a bundle on the given endpoint.
+func awaitPeerBundle(t *testing.T, impl peerImplementation, endpoint string) (bndl bpv7.Bundle) {
+	t.Helper()
+
+	eventually(t, "bundle at "+impl.name+"'s endpoint "+endpoint, func() (ok bool, err error) {
+		bndl, ok, err = impl.app.Receive(endpoint)
+		return
+	})
+	return
+}
+
+// payload returns a bundle's payload data.
+func payload(t *testing.T, bndl bpv7.Bundle) []byte {
+	t.Helper()
+
+	pb, err := bndl.PayloadBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb.Value.(*bpv7.PayloadBlock).Data()
+}
+
+// dtn7rsApplication uses dtn7-rs' HTTP interface.
+type dtn7rsApplication struct {
+	address string
+}
+
+func (app dtn7rsApplication) get(path, query string) ([]byte, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s/%s?%s", app.address, path, query))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s: %s", path, resp.Status, body)
+	}
+	return body, nil
+}
+
+func (app dtn7rsApplication) Register(endpoint string) error {
+	_, err := app.get("register", endpoint)
+	return err
+}
+
+func (app dtn7rsApplication) Send(destination bpv7.EndpointID, payload []byte) error {
+	query := url.Values{"dst": {destination.String()}, "lifetime": {"1h"}}
+	resp, err := http.Post(
+		fmt.Sprintf("http://%s/send?%s", app.address, query.Encode()),
+		"application/octet-stream", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("send returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (app dtn7rsApplication) Receive(endpoint string) (bndl bpv7.Bundle, ok bool, err error) {
+	body, err := app.get("endpoint", endpoint)
+	if err != nil {
+		return
+	}
+	if bytes.HasPrefix(body, []byte("Nothing to receive")) {
+		return
+	}
+
+	bndl, err = bpv7.ParseBundle(bytes.NewReader(body))
+	ok = err == nil
+	return
+}
@@ -0,0 +1,224 @@
+//go:build interop
+
+package interop
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/dtn7/dtn7-go/pkg/bpv7"
+	"github.com/dtn7/dtn7-go/pkg/client"
+)
+
+// tcpclv4Unsupported is the reason for skipping implementations which only share TCPCLv4 with other stacks. Until
+// dtnd implements TCPCLv4, there is no interoperability with ION and DTNME at all.
+const tcpclv4Unsupported = "not interoperable: ION and DTNME require TCPCLv4, which dtnd does not implement"
+
+var implementations = []peerImplementation{
+	{
+		name:        "dtn7-rs",
+		imageEnv:    "DTN7RS_IMAGE",
+		nodeID:      bpv7.MustNewEndpointID("dtn://rs-node/"),
+		mtcpAddress: "127.0.0.1:16163",
+		args: func(goAddress string) []string {
+			return []string{
+				"dtnd", "-n", "rs-node", "-r", "epidemic", "-w", "3000",
+				"-C", "mtcp:port=16163",
+				"-s", "mtcp://" + goAddress + "/go-node",
+			}
+		},
+		app: dtn7rsApplication{address: "127.0.0.1:3000"},
+	},
+	{
+		name:        "ION",
+		imageEnv:    "ION_IMAGE",
+		unsupported: tcpclv4Unsupported,
+	},
+	{
+		name:        "DTNME",
+		imageEnv:    "DTNME_IMAGE",
+		unsupported: tcpclv4Unsupported,
+	},
+}
+
+// injectorNodeID is the source of bundles injected into dtnd, which are forwarded as if received from another node.
+var injectorNodeID = bpv7.MustNewEndpointID("dtn://injector/")
+
+// newBundle creates a bundle from the injector to the given destination.
+func newBundle(t *testing.T, destination bpv7.EndpointID, data []byte) bpv7.Bundle {
+	t.Helper()
+
+	bndl, err := bpv7.Builder().
+		CRC(bpv7.CRC32).
+		Source(injectorNodeID).
+		Destination(destination).
+		ReportTo(injectorNodeID).
+		CreationTimestampNow().
+		Lifetime(time.Hour).
+		HopCountBlock(64).
+		PayloadBlock(data).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bndl
+}
+
+// randomPayload creates a payload of the given length. It is hex encoded, as the REST agent only takes text payloads.
+func randomPayload(t *testing.T, length int) []byte {
+	t.Helper()
+
+	data := make([]byte, length/2)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return []byte(hex.EncodeToString(data))
+}
+
+func TestInterop(t *testing.T) {
+	for _, impl := range implementations {
+		impl := impl
+		t.Run(impl.name, func(t *testing.T) {
+			if impl.unsupported != "" {
+				t.Skip(impl.unsupported)
+			}
+
+			impl.startContainer(t)
+			node := startGoNode(t, impl)
+
+			t.Run("Delivery", func(t *testing.T) { testDelivery(t, impl, node) })
+			t.Run("Reception", func(t *testing.T) { testReception(t, impl, node) })
+			t.Run("Fragmentation", func(t *testing.T) { testFragmentation(t, impl, node) })
+			t.Run("StatusReports", func(t *testing.T) { testStatusReports(t, impl, node) })
+		})
+	}
+}
+
+// testDelivery sends a bundle from a dtnd application to an endpoint of the other implementation.
+func testDelivery(t *testing.T, impl peerImplementation, node *goNode) {
+	const endpoint = "delivery"
+	if err := impl.app.Register(endpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	c := node.connect(t, goNodeID.String()+endpoint)
+	data := randomPayload(t, 1024)
+	if _, err := c.Send(data, client.SendOptions{Destination: impl.nodeID.String() + endpoint}); err != nil {
+		t.Fatal(err)
+	}
+
+	if received := payload(t, awaitPeerBundle(t, impl, endpoint)); !bytes.Equal(received, data) {
+		t.Fatalf("Delivered payload differs")
+	}
+}
+
+// testReception sends a bundle from the other implementation to a dtnd application.
+func testReception(t *testing.T, impl peerImplementation, node *goNode) {
+	destination := bpv7.MustNewEndpointID(goNodeID.String() + "reception")
+	c := node.connect(t, destination.String())
+
+	data := randomPayload(t, 1024)
+	if err := impl.app.Send(destination, data); err != nil {
+		t.Fatal(err)
+	}
+
+	bndl := awaitBundle(t, c, "bundle from "+impl.name, func(bndl client.Bundle) bool {
+		return bndl.Destination == destination.String()
+	})
+	if source, err := bpv7.NewEndpointID(bndl.Source); err != nil || !source.SameNode(impl.nodeID) {
+		t.Fatalf("Bundle's source is %s, not %v", bndl.Source, impl.nodeID)
+	}
+	if !bytes.Equal(bndl.Payload, data) {
+		t.Fatalf("Received payload differs")
+	}
+}
+
+// testFragmentation injects a bundle split into fragments into dtnd, which forwards the fragments. They must be
+// reassembled by the other implementation before delivery.
+func testFragmentation(t *testing.T, impl peerImplementation, node *goNode) {
+	const endpoint = "fragmentation"
+	if err := impl.app.Register(endpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	data := randomPayload(t, 8*1024)
+	fragments, err := newBundle(t, bpv7.MustNewEndpointID(impl.nodeID.String()+endpoint), data).Fragment(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fragments) < 2 {
+		t.Fatalf("Bundle was not fragmented")
+	}
+
+	node.inject(t, fragments...)
+
+	if received := payload(t, awaitPeerBundle(t, impl, endpoint)); !bytes.Equal(received, data) {
+		t.Fatalf("Reassembled payload differs")
+	}
+}
+
+// testStatusReports requests status reports for a bundle sent by a dtnd application. The other implementation's
+// delivery report must reach dtnd's delivery tracking.
+func testStatusReports(t *testing.T, impl peerImplementation, node *goNode) {
+	const endpoint = "reports"
+	if err := impl.app.Register(endpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	c := node.connect(t, goNodeID.String()+endpoint)
+	sent, err := c.Send(randomPayload(t, 64), client.SendOptions{
+		Destination:   impl.nodeID.String() + endpoint,
+		StatusReports: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	awaitPeerBundle(t, impl, endpoint)
+
+	eventually(t, "delivery status report from "+impl.name, func() (bool, error) {
+		state, err := c.Status(sent.BundleID)
+		return err == nil && state.Delivered, err
+	})
+}
+
+// TestGoNode checks dtn7-go's side of the harness on its own: a dtnd delivers bundles between its applications and
+// reassembles injected fragments, without any other implementation.
+func TestGoNode(t *testing.T) {
+	impl := peerImplementation{
+		name:        "none",
+		nodeID:      bpv7.MustNewEndpointID("dtn://unreachable/"),
+		mtcpAddress: "127.0.0.1:9",
+	}
+	node := startGoNode(t, impl)
+
+	sender := node.connect(t, goNodeID.String()+"sender")
+	receiver := node.connect(t, goNodeID.String()+"receiver")
+
+	data := randomPayload(t, 1024)
+	if _, err := sender.Send(data, client.SendOptions{Destination: receiver.Endpoint().String()}); err != nil {
+		t.Fatal(err)
+	}
+	awaitBundle(t, receiver, "locally sent bundle", func(bndl client.Bundle) bool {
+		return bytes.Equal(bndl.Payload, data)
+	})
+
+	data = randomPayload(t, 8*1024)
+	fragments, err := newBundle(t, receiver.Endpoint(), data).Fragment(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.inject(t, fragments...)
+	awaitBundle(t, receiver, "reassembled bundle", func(bndl client.Bundle) bool {
+		return bytes.Equal(bndl.Payload, data)
+	})
+
+	// each fetch acknowledged the bundles of the previous one, which are not fetched again
+	if bundles, _, err := receiver.Fetch(); err != nil {
+		t.Fatal(err)
+	} else if len(bundles) != 0 {
+		t.Fatalf("Acknowledged bundles were fetched again: %v", bundles)
+	}
+}